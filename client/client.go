@@ -0,0 +1,228 @@
+// Package client provides typed access to the radio API for Go programs such as an FMS, using the same request and
+// status types as the server so that they can't drift apart.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Number of times a request is attempted by default before giving up.
+	defaultMaxAttempts = 3
+
+	// How long to wait after the first failed attempt before retrying by default; doubled after each further failure.
+	defaultRetryBackoff = 500 * time.Millisecond
+
+	// How long to wait for a response to a single attempt by default.
+	defaultRequestTimeout = 10 * time.Second
+)
+
+// Client makes requests to the API of a single radio. Its fields may be changed before it is first used.
+type Client struct {
+	// Base URL of the API (e.g. "http://10.0.100.2").
+	BaseUrl string
+
+	// Password or API token sent as the bearer token of each request, or blank if the API doesn't require one.
+	Password string
+
+	// HTTP client through which requests are made.
+	HttpClient *http.Client
+
+	// Number of times a request is attempted before giving up, including the first.
+	MaxAttempts int
+
+	// How long to wait after the first failed attempt before retrying; doubled after each further failure.
+	RetryBackoff time.Duration
+}
+
+// HttpError represents a response from the API with an unsuccessful status code.
+type HttpError struct {
+	// HTTP status code of the response.
+	StatusCode int
+
+	// Body of the response, which describes the error.
+	Message string
+}
+
+func (err *HttpError) Error() string {
+	return err.Message
+}
+
+// StatusChanges represents the stations whose status has changed between two polls of the status.
+type StatusChanges struct {
+	// Revision of the status as of which the changes are given.
+	Revision uint64 `json:"revision"`
+
+	// Current status of each station that has changed, keyed by station name (e.g. "red1"). A station whose team has
+	// been removed is given as nil.
+	StationStatuses map[string]*radio.NetworkStatus `json:"stationStatuses"`
+}
+
+// NewClient returns a client for the radio API at the given base URL, authenticating with the given password.
+func NewClient(baseUrl, password string) *Client {
+	return &Client{
+		BaseUrl:      strings.TrimSuffix(baseUrl, "/"),
+		Password:     password,
+		HttpClient:   &http.Client{Timeout: defaultRequestTimeout},
+		MaxAttempts:  defaultMaxAttempts,
+		RetryBackoff: defaultRetryBackoff,
+	}
+}
+
+// GetStatus returns the current status of the radio.
+func (client *Client) GetStatus(ctx context.Context) (*radio.Radio, error) {
+	var status radio.Radio
+	if err := client.doJson(ctx, http.MethodGet, "/status", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// Configure queues the given configuration request on the radio, returning the ID under which its outcome can be
+// followed. The request is applied asynchronously.
+func (client *Client) Configure(ctx context.Context, request radio.ConfigurationRequest) (int, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return 0, err
+	}
+	return client.ApplyProfile(ctx, body)
+}
+
+// ApplyProfile queues the given configuration profile on the radio, returning the ID under which its outcome can be
+// followed. The profile is the JSON body of a configuration request, which may contain template variables (e.g.
+// {{.EventCode}}) for the radio to resolve against its event variables, so that one profile can be applied across
+// fields and events.
+func (client *Client) ApplyProfile(ctx context.Context, profile []byte) (int, error) {
+	response, err := client.do(ctx, http.MethodPost, "/configuration", profile)
+	if err != nil {
+		return 0, err
+	}
+	location, err := url.Parse(response.Header.Get("Location"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid Location header in configuration response: %v", err)
+	}
+	id, err := strconv.Atoi(path.Base(location.Path))
+	if err != nil {
+		return 0, fmt.Errorf("invalid Location header in configuration response: %q", location.Path)
+	}
+	return id, nil
+}
+
+// StreamStatus polls the status of the radio at the given interval until the context is done, calling the given
+// function with the stations whose status has changed since the previous poll. The first call includes every station.
+// Returns the error from the context, from the function, or from polling once its retries are exhausted.
+func (client *Client) StreamStatus(
+	ctx context.Context, interval time.Duration, handleChanges func(changes StatusChanges) error,
+) error {
+	var revision uint64
+	for {
+		var changes StatusChanges
+		statusPath := "/status?changedSince=" + strconv.FormatUint(revision, 10)
+		if err := client.doJson(ctx, http.MethodGet, statusPath, nil, &changes); err != nil {
+			return err
+		}
+		if revision == 0 || len(changes.StationStatuses) > 0 {
+			if err := handleChanges(changes); err != nil {
+				return err
+			}
+		}
+		revision = changes.Revision
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// doJson makes the given request and decodes the JSON response into the given value.
+func (client *Client) doJson(ctx context.Context, method, urlPath string, body []byte, value any) error {
+	response, err := client.do(ctx, method, urlPath, body)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(response.body, value); err != nil {
+		return fmt.Errorf("invalid JSON in response to %s %s: %v", method, urlPath, err)
+	}
+	return nil
+}
+
+// response holds a successful response along with its body, which has already been read.
+type response struct {
+	*http.Response
+	body []byte
+}
+
+// do makes the given request, retrying it with a backoff if it fails in a way that may be transient. Requests that
+// change the radio are only retried if the API definitely didn't act on them.
+func (client *Client) do(ctx context.Context, method, urlPath string, body []byte) (*response, error) {
+	backoff := client.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		response, isRetryable, err := client.attempt(ctx, method, urlPath, body)
+		if err == nil || !isRetryable || attempt >= client.MaxAttempts {
+			return response, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// attempt makes the given request once, returning whether it may succeed if retried if it fails.
+func (client *Client) attempt(ctx context.Context, method, urlPath string, body []byte) (*response, bool, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, client.BaseUrl+urlPath, bodyReader)
+	if err != nil {
+		return nil, false, err
+	}
+	if client.Password != "" {
+		request.Header.Set("Authorization", "Bearer "+client.Password)
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	request.Header.Set("Accept", "application/json")
+
+	isIdempotent := method == http.MethodGet
+	httpResponse, err := client.HttpClient.Do(request)
+	if err != nil {
+		// A failure to connect at all means the API never saw the request.
+		var opErr *net.OpError
+		isDialError := errors.As(err, &opErr) && opErr.Op == "dial"
+		return nil, ctx.Err() == nil && (isIdempotent || isDialError), err
+	}
+	defer httpResponse.Body.Close()
+	responseBody, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, isIdempotent && ctx.Err() == nil, err
+	}
+	if httpResponse.StatusCode >= 300 {
+		httpErr := &HttpError{StatusCode: httpResponse.StatusCode, Message: strings.TrimSpace(string(responseBody))}
+		// The API rejects requests with a 503 status without acting on them while it is unavailable (e.g. because it
+		// is in maintenance mode).
+		isRetryable := httpResponse.StatusCode == http.StatusServiceUnavailable ||
+			isIdempotent && httpResponse.StatusCode >= 500
+		return nil, isRetryable, httpErr
+	}
+	return &response{Response: httpResponse, body: responseBody}, false, nil
+}
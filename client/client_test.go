@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client for a fake API served by the given handler, with retries that don't slow down tests.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	client := NewClient(server.URL+"/", "mypassword")
+	client.RetryBackoff = time.Millisecond
+	return client
+}
+
+func TestClient_GetStatus(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/status", r.URL.Path)
+		assert.Equal(t, "Bearer mypassword", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"version": "1.2.3", "configurationRevision": 12}`))
+	})
+	status, err := client.GetStatus(context.Background())
+	if assert.Nil(t, err) {
+		assert.Equal(t, "1.2.3", status.Version)
+		assert.Equal(t, 12, status.ConfigurationRevision)
+	}
+}
+
+func TestClient_Configure(t *testing.T) {
+	var receivedBody []byte
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/configuration", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Location", "/configuration/requests/7")
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte("New configuration received as request 7 and will be applied asynchronously.\n"))
+	})
+
+	id, err := client.Configure(context.Background(), radio.ConfigurationRequest{Channel: 149})
+	assert.Nil(t, err)
+	assert.Equal(t, 7, id)
+	var request radio.ConfigurationRequest
+	assert.Nil(t, json.Unmarshal(receivedBody, &request))
+	assert.Equal(t, radio.ConfigurationRequest{Channel: 149}, request)
+
+	// A profile is sent verbatim, leaving its template variables for the radio to resolve.
+	profile := []byte(`{"channel": 149, "syslogIpAddress": "10.0.10{{.FieldNumber}}.40"}`)
+	id, err = client.ApplyProfile(context.Background(), profile)
+	assert.Nil(t, err)
+	assert.Equal(t, 7, id)
+	assert.Equal(t, profile, receivedBody)
+}
+
+func TestClient_Errors(t *testing.T) {
+	var attemptCount int
+	statusCode := http.StatusInternalServerError
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attemptCount++
+		http.Error(w, fmt.Sprintf("HTTP request error %d: oops", statusCode), statusCode)
+	})
+
+	// Reads are retried on server errors.
+	_, err := client.GetStatus(context.Background())
+	var httpErr *HttpError
+	if assert.True(t, errors.As(err, &httpErr)) {
+		assert.Equal(t, http.StatusInternalServerError, httpErr.StatusCode)
+		assert.Equal(t, "HTTP request error 500: oops", httpErr.Error())
+	}
+	assert.Equal(t, 3, attemptCount)
+
+	// Changes are only retried if the API definitely didn't act on them.
+	attemptCount = 0
+	_, err = client.Configure(context.Background(), radio.ConfigurationRequest{})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, attemptCount)
+	attemptCount = 0
+	statusCode = http.StatusServiceUnavailable
+	_, err = client.Configure(context.Background(), radio.ConfigurationRequest{})
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, attemptCount)
+
+	// Client errors aren't retried at all.
+	attemptCount = 0
+	statusCode = http.StatusUnauthorized
+	_, err = client.GetStatus(context.Background())
+	if assert.True(t, errors.As(err, &httpErr)) {
+		assert.Equal(t, http.StatusUnauthorized, httpErr.StatusCode)
+	}
+	assert.Equal(t, 1, attemptCount)
+
+	// Retries stop once the context is done.
+	attemptCount = 0
+	statusCode = http.StatusInternalServerError
+	client.RetryBackoff = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = client.GetStatus(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 1, attemptCount)
+}
+
+func TestClient_StreamStatus(t *testing.T) {
+	var sinceValues []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		sinceValues = append(sinceValues, r.URL.Query().Get("changedSince"))
+		switch len(sinceValues) {
+		case 1:
+			_, _ = w.Write([]byte(`{"revision": 4, "stationStatuses": {"red1": {"ssid": "1111"}, "red2": null}}`))
+		case 2:
+			_, _ = w.Write([]byte(`{"revision": 4, "stationStatuses": {}}`))
+		default:
+			_, _ = w.Write([]byte(`{"revision": 6, "stationStatuses": {"red2": {"ssid": "2222"}}}`))
+		}
+	})
+
+	// Polls that find no changes aren't passed on, and an error from the function ends the stream.
+	var allChanges []StatusChanges
+	err := client.StreamStatus(context.Background(), time.Millisecond, func(changes StatusChanges) error {
+		allChanges = append(allChanges, changes)
+		if len(allChanges) == 2 {
+			return errors.New("done")
+		}
+		return nil
+	})
+	assert.EqualError(t, err, "done")
+	assert.Equal(t, []string{"0", "4", "4"}, sinceValues)
+	if assert.Equal(t, 2, len(allChanges)) {
+		assert.Equal(t, uint64(4), allChanges[0].Revision)
+		assert.Equal(t, "1111", allChanges[0].StationStatuses["red1"].Ssid)
+		assert.Nil(t, allChanges[0].StationStatuses["red2"])
+		assert.Equal(t, uint64(6), allChanges[1].Revision)
+		assert.Equal(t, "2222", allChanges[1].StationStatuses["red2"].Ssid)
+	}
+
+	// The stream ends once the context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	err = client.StreamStatus(ctx, time.Hour, func(changes StatusChanges) error {
+		cancel()
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fleetTarget represents a radio that a configuration is pushed to.
+type fleetTarget struct {
+	// Base URL of the radio's API.
+	url string
+
+	// Password or token required by the radio's API, or an empty string if none is required.
+	token string
+}
+
+// fleetPushOptions represents the parameters controlling how configurations are pushed to a fleet of radios.
+type fleetPushOptions struct {
+	// Maximum number of radios to configure at the same time.
+	parallelism int
+
+	// Maximum number of attempts to make per radio before giving up.
+	attempts int
+
+	// Time to wait between attempts to configure the same radio.
+	retryDelay time.Duration
+
+	// Maximum time to wait for a radio to respond to each attempt.
+	timeout time.Duration
+}
+
+// fleetPushResult represents the outcome of pushing a configuration to a single radio.
+type fleetPushResult struct {
+	target    fleetTarget
+	attempts  int
+	succeeded bool
+	message   string
+}
+
+// runFleetCommand implements the 'fleet' subcommand, which pushes a configuration to each radio in a list
+// concurrently, printing the outcome for each one. Returns the process exit code.
+func runFleetCommand(args []string, output io.Writer) int {
+	flags := flag.NewFlagSet("fleet", flag.ContinueOnError)
+	flags.SetOutput(output)
+	radiosPath := flags.String("radios", "", "file listing one radio API URL or address per line, optionally followed "+
+		"by the password or token it requires")
+	configPath := flags.String("config", "", "file containing the JSON configuration request to push to each radio")
+	parallelism := flags.Int("parallel", 8, "maximum number of radios to configure at the same time")
+	attempts := flags.Int("attempts", 3, "maximum number of attempts per radio")
+	retryDelay := flags.Duration("retry-delay", 2*time.Second, "time to wait between attempts on the same radio")
+	timeout := flags.Duration("timeout", 10*time.Second, "maximum time to wait for each radio to respond")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *radiosPath == "" || *configPath == "" {
+		_, _ = fmt.Fprintln(output, "Both -radios and -config must be given.")
+		flags.Usage()
+		return 2
+	}
+	if *parallelism < 1 || *attempts < 1 {
+		_, _ = fmt.Fprintln(output, "-parallel and -attempts must be at least 1.")
+		return 2
+	}
+
+	targets, err := readFleetTargetsFile(*radiosPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(output, "Error reading radio list: %v\n", err)
+		return 1
+	}
+	configuration, err := os.ReadFile(*configPath)
+	if err != nil {
+		_, _ = fmt.Fprintf(output, "Error reading configuration: %v\n", err)
+		return 1
+	}
+	if !json.Valid(configuration) {
+		_, _ = fmt.Fprintf(output, "Error reading configuration: %s does not contain valid JSON\n", *configPath)
+		return 1
+	}
+
+	options := fleetPushOptions{
+		parallelism: *parallelism, attempts: *attempts, retryDelay: *retryDelay, timeout: *timeout,
+	}
+	results := pushFleetConfiguration(targets, configuration, options, func(result fleetPushResult) {
+		_, _ = fmt.Fprintln(output, result)
+	})
+
+	succeededCount := 0
+	for _, result := range results {
+		if result.succeeded {
+			succeededCount++
+		}
+	}
+	_, _ = fmt.Fprintf(output, "Configured %d of %d radios.\n", succeededCount, len(results))
+	if succeededCount < len(results) {
+		return 1
+	}
+	return 0
+}
+
+// readFleetTargetsFile reads the list of radios to configure from the file at the given path.
+func readFleetTargetsFile(path string) ([]fleetTarget, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseFleetTargets(file)
+}
+
+// parseFleetTargets parses a list of radios with one per line, each given as the base URL or address of its API,
+// optionally followed by whitespace and the password or token it requires. Blank lines and lines beginning with '#' are
+// ignored.
+func parseFleetTargets(reader io.Reader) ([]fleetTarget, error) {
+	var targets []fleetTarget
+	seenUrls := make(map[string]struct{})
+	scanner := bufio.NewScanner(reader)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("line %d: expected an address and an optional token but got %q", lineNumber, line)
+		}
+		target := fleetTarget{url: strings.TrimSuffix(fields[0], "/")}
+		if !strings.HasPrefix(target.url, "http://") && !strings.HasPrefix(target.url, "https://") {
+			target.url = "http://" + target.url
+		}
+		if len(fields) == 2 {
+			target.token = fields[1]
+		}
+		if _, ok := seenUrls[target.url]; ok {
+			return nil, fmt.Errorf("line %d: duplicate radio %s", lineNumber, target.url)
+		}
+		seenUrls[target.url] = struct{}{}
+		targets = append(targets, target)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("no radios listed")
+	}
+	return targets, nil
+}
+
+// pushFleetConfiguration pushes the given configuration request to each of the given radios, configuring up to the
+// given number at a time. The given callback is invoked as each radio finishes. Returns the results in the same order
+// as the radios.
+func pushFleetConfiguration(
+	targets []fleetTarget, configuration []byte, options fleetPushOptions, onResult func(fleetPushResult),
+) []fleetPushResult {
+	results := make([]fleetPushResult, len(targets))
+	semaphore := make(chan struct{}, options.parallelism)
+	var waitGroup sync.WaitGroup
+	var callbackMutex sync.Mutex
+	for i, target := range targets {
+		waitGroup.Add(1)
+		go func(i int, target fleetTarget) {
+			defer waitGroup.Done()
+			semaphore <- struct{}{}
+			results[i] = pushConfigurationWithRetries(target, configuration, options)
+			<-semaphore
+
+			callbackMutex.Lock()
+			onResult(results[i])
+			callbackMutex.Unlock()
+		}(i, target)
+	}
+	waitGroup.Wait()
+	return results
+}
+
+// pushConfigurationWithRetries pushes the given configuration request to the given radio, retrying failures that may
+// be transient.
+func pushConfigurationWithRetries(
+	target fleetTarget, configuration []byte, options fleetPushOptions,
+) fleetPushResult {
+	result := fleetPushResult{target: target}
+	client := &http.Client{Timeout: options.timeout}
+	for result.attempts < options.attempts {
+		if result.attempts > 0 {
+			time.Sleep(options.retryDelay)
+		}
+		result.attempts++
+		message, retryable, err := pushConfiguration(client, target, configuration)
+		if err == nil {
+			result.succeeded = true
+			result.message = message
+			return result
+		}
+		result.message = err.Error()
+		if !retryable {
+			break
+		}
+	}
+	return result
+}
+
+// pushConfiguration makes a single attempt to push the given configuration request to the given radio. Returns the
+// radio's response message on success, or an error along with whether it is worth trying again.
+func pushConfiguration(client *http.Client, target fleetTarget, configuration []byte) (string, bool, error) {
+	request, err := http.NewRequest("POST", target.url+"/configuration", bytes.NewReader(configuration))
+	if err != nil {
+		return "", false, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if target.token != "" {
+		request.Header.Set("Authorization", "Bearer "+target.token)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		// The radio may still be booting or its API restarting.
+		return "", true, err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", true, err
+	}
+	message := strings.TrimSpace(string(body))
+	if response.StatusCode != http.StatusAccepted && response.StatusCode != http.StatusOK {
+		// Client errors such as a rejected configuration or a wrong password won't go away by retrying.
+		retryable := response.StatusCode >= 500 || response.StatusCode == http.StatusTooManyRequests
+		return "", retryable, fmt.Errorf("unexpected response %d: %s", response.StatusCode, message)
+	}
+	return message, false, nil
+}
+
+// String returns a one-line summary of the result suitable for printing.
+func (result fleetPushResult) String() string {
+	outcome := "FAILED"
+	if result.succeeded {
+		outcome = "OK"
+	}
+	attempts := "attempt"
+	if result.attempts != 1 {
+		attempts += "s"
+	}
+	return fmt.Sprintf("%-6s %s (%d %s): %s", outcome, result.target.url, result.attempts, attempts, result.message)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeFleetRadio is a stand-in for a radio's API that records the configuration requests it receives and responds
+// with a scripted sequence of status codes.
+type fakeFleetRadio struct {
+	mutex       sync.Mutex
+	statusCodes []int
+	requests    []string
+	tokens      []string
+	server      *httptest.Server
+}
+
+func newFakeFleetRadio(t *testing.T, statusCodes ...int) *fakeFleetRadio {
+	radio := &fakeFleetRadio{statusCodes: statusCodes}
+	radio.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/configuration", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ := io.ReadAll(r.Body)
+
+		radio.mutex.Lock()
+		defer radio.mutex.Unlock()
+		radio.requests = append(radio.requests, string(body))
+		radio.tokens = append(radio.tokens, r.Header.Get("Authorization"))
+		statusCode := http.StatusAccepted
+		if len(radio.statusCodes) > 0 {
+			statusCode, radio.statusCodes = radio.statusCodes[0], radio.statusCodes[1:]
+		}
+		if statusCode == http.StatusAccepted {
+			w.WriteHeader(statusCode)
+			_, _ = fmt.Fprintln(w, "New configuration received and will be applied asynchronously.")
+		} else {
+			http.Error(w, "oops", statusCode)
+		}
+	}))
+	t.Cleanup(radio.server.Close)
+	return radio
+}
+
+func TestParseFleetTargets(t *testing.T) {
+	targets, err := parseFleetTargets(
+		strings.NewReader("# Field access points\n10.0.100.2 mypassword\n\nhttps://10.0.100.3/\n  10.12.34.1  \n"),
+	)
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		[]fleetTarget{
+			{url: "http://10.0.100.2", token: "mypassword"},
+			{url: "https://10.0.100.3"},
+			{url: "http://10.12.34.1"},
+		},
+		targets,
+	)
+
+	_, err = parseFleetTargets(strings.NewReader("10.0.100.2 mypassword extra\n"))
+	assert.EqualError(
+		t, err, "line 1: expected an address and an optional token but got \"10.0.100.2 mypassword extra\"",
+	)
+	_, err = parseFleetTargets(strings.NewReader("10.0.100.2\nhttp://10.0.100.2/\n"))
+	assert.EqualError(t, err, "line 2: duplicate radio http://10.0.100.2")
+	_, err = parseFleetTargets(strings.NewReader("# Nothing here\n"))
+	assert.EqualError(t, err, "no radios listed")
+}
+
+func TestPushFleetConfiguration(t *testing.T) {
+	healthyRadio := newFakeFleetRadio(t)
+	flakyRadio := newFakeFleetRadio(t, http.StatusInternalServerError, http.StatusAccepted)
+	rejectingRadio := newFakeFleetRadio(t, http.StatusBadRequest)
+	downRadio := newFakeFleetRadio(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	targets := []fleetTarget{
+		{url: healthyRadio.server.URL, token: "mypassword"},
+		{url: flakyRadio.server.URL},
+		{url: rejectingRadio.server.URL},
+		{url: downRadio.server.URL},
+	}
+	options := fleetPushOptions{parallelism: 2, attempts: 2, retryDelay: time.Millisecond, timeout: time.Second}
+	var callbackCount int
+	results := pushFleetConfiguration(targets, []byte(`{"channel":149}`), options, func(fleetPushResult) {
+		callbackCount++
+	})
+
+	assert.Equal(t, 4, callbackCount)
+	if assert.Equal(t, 4, len(results)) {
+		assert.True(t, results[0].succeeded)
+		assert.Equal(t, 1, results[0].attempts)
+		assert.Equal(t, "New configuration received and will be applied asynchronously.", results[0].message)
+		assert.Equal(t, []string{`{"channel":149}`}, healthyRadio.requests)
+		assert.Equal(t, []string{"Bearer mypassword"}, healthyRadio.tokens)
+
+		assert.True(t, results[1].succeeded)
+		assert.Equal(t, 2, results[1].attempts)
+		assert.Equal(t, 2, len(flakyRadio.requests))
+
+		// Rejected configurations aren't retried.
+		assert.False(t, results[2].succeeded)
+		assert.Equal(t, 1, results[2].attempts)
+		assert.Equal(t, "unexpected response 400: oops", results[2].message)
+
+		assert.False(t, results[3].succeeded)
+		assert.Equal(t, 2, results[3].attempts)
+		assert.Equal(t, "unexpected response 503: oops", results[3].message)
+		assert.Equal(
+			t,
+			fmt.Sprintf("FAILED %s (2 attempts): unexpected response 503: oops", downRadio.server.URL),
+			results[3].String(),
+		)
+	}
+}
+
+func TestPushFleetConfiguration_unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	options := fleetPushOptions{parallelism: 1, attempts: 3, retryDelay: time.Millisecond, timeout: time.Second}
+	results := pushFleetConfiguration([]fleetTarget{{url: url}}, []byte("{}"), options, func(fleetPushResult) {})
+	assert.False(t, results[0].succeeded)
+	assert.Equal(t, 3, results[0].attempts)
+	assert.Contains(t, results[0].message, "connection refused")
+}
+
+func TestRunFleetCommand(t *testing.T) {
+	radio1 := newFakeFleetRadio(t)
+	radio2 := newFakeFleetRadio(t)
+	directory := t.TempDir()
+	radiosPath := filepath.Join(directory, "radios.txt")
+	configPath := filepath.Join(directory, "config.json")
+	assert.Nil(t, os.WriteFile(radiosPath, []byte(radio1.server.URL+"\n"+radio2.server.URL+" mytoken\n"), 0644))
+	assert.Nil(t, os.WriteFile(configPath, []byte(`{"channel":149}`), 0644))
+
+	var output bytes.Buffer
+	assert.Equal(t, 0, runFleetCommand([]string{"-radios", radiosPath, "-config", configPath}, &output))
+	assert.Contains(t, output.String(), fmt.Sprintf("OK     %s (1 attempt): New configuration", radio1.server.URL))
+	assert.Contains(t, output.String(), "Configured 2 of 2 radios.")
+	assert.Equal(t, []string{"Bearer mytoken"}, radio2.tokens)
+
+	// A failed radio is reflected in the exit code.
+	radio2.statusCodes = []int{http.StatusUnauthorized}
+	output.Reset()
+	assert.Equal(t, 1, runFleetCommand([]string{"-radios", radiosPath, "-config", configPath}, &output))
+	assert.Contains(t, output.String(), "Configured 1 of 2 radios.")
+
+	// Invalid arguments are rejected before anything is pushed.
+	output.Reset()
+	assert.Equal(t, 2, runFleetCommand([]string{"-radios", radiosPath}, &output))
+	assert.Contains(t, output.String(), "Both -radios and -config must be given.")
+	assert.Nil(t, os.WriteFile(configPath, []byte(`{"channel":`), 0644))
+	output.Reset()
+	assert.Equal(t, 1, runFleetCommand([]string{"-radios", radiosPath, "-config", configPath}, &output))
+	assert.Contains(t, output.String(), "does not contain valid JSON")
+	assert.Equal(t, 2, len(radio1.requests))
+}
@@ -6,40 +6,81 @@ import (
 	"github.com/patfair/frc-radio-api/web"
 	"log"
 	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
 )
 
-const (
-	// Path of the current log file.
-	logFilePath = "/root/frc-radio-api.log"
+// Maximum size of the current log file in bytes.
+const logFileMaxSizeBytes = 3 * 1 << 19 // 1.5 MB
 
-	// Path of the old log file, which is rotated when the current log file gets too big.
-	oldLogFilePath = "/root/frc-radio-api.log.old"
+func main() {
+	// A new binary is run with the self-check flag before an in-place upgrade, while the current one still owns the
+	// radio and the listening port, so it must exit before reading the settings or creating the radio.
+	if len(os.Args) > 1 && os.Args[1] == web.SelfCheckFlag {
+		fmt.Printf("FRC Radio API self-check passed (%s %s/%s)\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+		os.Exit(0)
+	}
 
-	// Maximum size of the current log file in bytes.
-	logFileMaxSizeBytes = 3 * 1 << 19 // 1.5 MB
-)
+	// The fleet subcommand runs as a standalone client on a laptop rather than as the API server on a radio.
+	if len(os.Args) > 1 && os.Args[1] == "fleet" {
+		os.Exit(runFleetCommand(os.Args[2:], os.Stdout))
+	}
 
-func main() {
-	logFile := setupLogging()
+	// The settings are read first since they determine where the log file is stored.
+	settings, settingsErr := radio.ReadSettings()
+	logFile := setupLogging(settings)
 	log.Println("Starting FRC Radio API...")
 	if logFile != nil {
 		defer logFile.Close()
 	}
+	if settingsErr != nil {
+		log.Printf("Error loading settings file; using defaults: %v", settingsErr)
+	}
 
 	radio := radio.NewRadio()
+	radio.SetSettings(settings)
 	fmt.Println("created radio")
 
 	// Launch the web server in a separate thread.
 	webServer := web.NewWebServer(radio)
 	fmt.Println("created webserver")
 	go webServer.Run()
+	go reloadSettingsOnHangup(webServer)
+	go recordShutdownOnTermination()
 
 	// Run the radio event loop in the main thread.
 	radio.Run()
 }
 
+// reloadSettingsOnHangup re-reads the API settings whenever the process receives a SIGHUP.
+func reloadSettingsOnHangup(webServer *web.WebServer) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		log.Println("Received SIGHUP; reloading settings.")
+		if err := webServer.ReloadSettings(); err != nil {
+			log.Printf("Error reloading settings; keeping current settings: %v", err)
+		}
+	}
+}
+
+// recordShutdownOnTermination exits when the process is asked to terminate, as it is when the radio shuts down or
+// reboots in an orderly way, noting the clean shutdown so that the next boot isn't taken to follow a power loss.
+func recordShutdownOnTermination() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	received := <-signals
+	log.Printf("Received %v; shutting down.", received)
+	radio.RecordCleanShutdown()
+	os.Exit(0)
+}
+
 // setupLogging sets up logging to a file, or to stdout if the file can't be opened.
-func setupLogging() *os.File {
+func setupLogging(settings radio.Settings) *os.File {
+	logFilePath := settings.StateFilePath(radio.LogFileName)
+	oldLogFilePath := settings.StateFilePath(radio.OldLogFileName)
+
 	// Rotate the log file if the current one is too big.
 	if fileInfo, err := os.Stat(logFilePath); err == nil {
 		if fileInfo.Size() >= logFileMaxSizeBytes {
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"github.com/patfair/frc-radio-api/web"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// Environment variable that makes the test binary run main() instead of the tests, so that the API's command-line
+// handling can be exercised in a child process.
+const runMainEnvVar = "FRC_RADIO_API_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnvVar) == "1" {
+		os.Args = append([]string{os.Args[0]}, os.Args[2:]...)
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestMain_selfCheck(t *testing.T) {
+	command := exec.Command(os.Args[0], "--", web.SelfCheckFlag)
+	command.Env = append(os.Environ(), runMainEnvVar+"=1")
+	output, err := command.CombinedOutput()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, command.ProcessState.ExitCode())
+	assert.Contains(t, string(output), "FRC Radio API self-check passed")
+
+	// The radio isn't touched, and no server is started.
+	assert.NotContains(t, string(output), "Starting FRC Radio API")
+	assert.NotContains(t, string(output), "created radio")
+}
@@ -0,0 +1,394 @@
+package radio
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// How long to wait for the SMS gateway to respond before giving up.
+	smsGatewayTimeout = 10 * time.Second
+
+	// Maximum number of alerts waiting to be forwarded; further alerts are only recorded locally until the backlog
+	// clears.
+	maxQueuedForwardedAlerts = 20
+
+	// Maximum number of alerts held during quiet hours that are listed individually once they end; any further ones
+	// are only counted.
+	maxHeldForwardedAlerts = 20
+
+	// How often to check whether quiet hours have ended so that the alerts held during them can be sent.
+	alertForwarderFlushInterval = time.Minute
+)
+
+// HTTP client used to deliver forwarded alerts to the SMS gateway.
+var smsGatewayClient = &http.Client{Timeout: smsGatewayTimeout}
+
+// Function used to hand forwarded alerts to the SMTP relay, which is replaced in tests.
+var sendMail = smtp.SendMail
+
+// AlertForwardingSettings holds the parameters for forwarding critical alerts by email or text message, so that the
+// radio can be monitored while nobody is at the venue, such as between event days. Forwarding is enabled by
+// configuring an SMTP relay, an SMS gateway, or both.
+type AlertForwardingSettings struct {
+	// Types of alerts to forward (e.g. "RADIO_ERROR"). Empty forwards every alert.
+	AlertTypes []string `json:"alertTypes"`
+
+	// SMTP relay through which alerts are emailed.
+	Smtp SmtpRelaySettings `json:"smtp"`
+
+	// HTTP gateway through which alerts are sent as text messages.
+	SmsGateway SmsGatewaySettings `json:"smsGateway"`
+
+	// Minimum time between forwarded alerts of the same type about the same station, in minutes. Zero disables the
+	// limit.
+	MinIntervalMin int `json:"minIntervalMin"`
+
+	// Maximum number of messages sent in any hour. Zero disables the limit.
+	MaxPerHour int `json:"maxPerHour"`
+
+	// Daily window during which alerts are held rather than sent, and then sent together once it ends.
+	QuietHours AlertQuietHours `json:"quietHours"`
+}
+
+// SmtpRelaySettings holds the parameters for emailing alerts through an SMTP relay.
+type SmtpRelaySettings struct {
+	// Host and port of the relay (e.g. "smtp.example.org:587"). Blank disables email.
+	Address string `json:"address"`
+
+	// Credentials with which to authenticate to the relay, if it requires them. They are only sent once the connection
+	// has been secured with STARTTLS, unless the relay is on localhost.
+	Username string `json:"username"`
+	Password string `json:"password"`
+
+	// Address from which the alerts are sent.
+	From string `json:"from"`
+
+	// Addresses to which the alerts are sent.
+	To []string `json:"to"`
+}
+
+// SmsGatewaySettings holds the parameters for sending alerts as text messages through an HTTP SMS gateway.
+type SmsGatewaySettings struct {
+	// URL to which each text message is POSTed as JSON of the form {"to": "+15555550100", "message": "..."}. Blank
+	// disables text messages.
+	Url string `json:"url"`
+
+	// Token sent in the Authorization header as a bearer token, if the gateway requires one.
+	Token string `json:"token"`
+
+	// Phone numbers to which the alerts are sent.
+	Recipients []string `json:"recipients"`
+}
+
+// AlertQuietHours represents a daily window during which forwarded alerts are held rather than sent. Both times are
+// blank if there are no quiet hours.
+type AlertQuietHours struct {
+	// Local time of day at which quiet hours begin, in 24-hour "HH:MM" format.
+	StartTime string `json:"startTime"`
+
+	// Local time of day at which quiet hours end, in 24-hour "HH:MM" format. May be earlier than the start time for a
+	// window that spans midnight.
+	EndTime string `json:"endTime"`
+}
+
+// alertForwarder sends alerts to the SMTP relay and SMS gateway from a single worker goroutine, so that a slow relay
+// can't hold up the radio, and applies the rate limits and quiet hours.
+type alertForwarder struct {
+	// Queue of alerts awaiting the worker goroutine, which is started on first use.
+	queue     chan Alert
+	queueOnce sync.Once
+
+	// Time at which an alert of each type about each station was last forwarded. Only accessed by the worker
+	// goroutine, as are the remaining fields.
+	lastForwardedAt map[string]time.Time
+
+	// Times at which the messages sent within the last hour were sent.
+	sentAt []time.Time
+
+	// Number of alerts not forwarded because of the rate limits since a message was last sent.
+	suppressedCount int
+
+	// Alerts held during the current quiet hours, and how many there were in total.
+	heldAlerts []Alert
+	heldCount  int
+}
+
+// validate checks that the alert forwarding settings have valid values.
+func (settings AlertForwardingSettings) validate() error {
+	for _, alertType := range settings.AlertTypes {
+		if alertType == "" {
+			return errors.New("alertForwarding.alertTypes cannot contain a blank type")
+		}
+	}
+	if relay := settings.Smtp; relay.Address != "" {
+		if _, _, err := net.SplitHostPort(relay.Address); err != nil {
+			return fmt.Errorf("invalid alertForwarding.smtp.address: %q (expecting host:port)", relay.Address)
+		}
+		if relay.From == "" {
+			return errors.New("alertForwarding.smtp.from cannot be blank")
+		}
+		if len(relay.To) == 0 {
+			return errors.New("alertForwarding.smtp.to cannot be empty")
+		}
+	}
+	if gateway := settings.SmsGateway; gateway.Url != "" {
+		if !isValidHttpUrl(gateway.Url) {
+			return fmt.Errorf("invalid alertForwarding.smsGateway.url: %q (expecting an HTTP URL)", gateway.Url)
+		}
+		if len(gateway.Recipients) == 0 {
+			return errors.New("alertForwarding.smsGateway.recipients cannot be empty")
+		}
+	}
+	if settings.MinIntervalMin < 0 {
+		return fmt.Errorf("invalid alertForwarding.minIntervalMin: %d", settings.MinIntervalMin)
+	}
+	if settings.MaxPerHour < 0 {
+		return fmt.Errorf("invalid alertForwarding.maxPerHour: %d", settings.MaxPerHour)
+	}
+	if quietHours := settings.QuietHours; quietHours.StartTime != "" || quietHours.EndTime != "" {
+		start, err := parseTimeOfDay(quietHours.StartTime)
+		if err != nil {
+			return fmt.Errorf(
+				"invalid alertForwarding.quietHours.startTime: %q (expecting HH:MM)", quietHours.StartTime,
+			)
+		}
+		end, err := parseTimeOfDay(quietHours.EndTime)
+		if err != nil {
+			return fmt.Errorf("invalid alertForwarding.quietHours.endTime: %q (expecting HH:MM)", quietHours.EndTime)
+		}
+		if start == end {
+			return errors.New("alertForwarding.quietHours.startTime and endTime cannot be the same")
+		}
+	}
+	return nil
+}
+
+// isEnabled returns true if an SMTP relay or SMS gateway is configured.
+func (settings AlertForwardingSettings) isEnabled() bool {
+	return settings.Smtp.Address != "" || settings.SmsGateway.Url != ""
+}
+
+// forwards returns true if alerts of the given type are to be forwarded.
+func (settings AlertForwardingSettings) forwards(alertType string) bool {
+	if len(settings.AlertTypes) == 0 {
+		return true
+	}
+	for _, forwardedType := range settings.AlertTypes {
+		if forwardedType == alertType {
+			return true
+		}
+	}
+	return false
+}
+
+// isQuietAt returns true if the given time falls within quiet hours. The quiet hours must be valid.
+func (quietHours AlertQuietHours) isQuietAt(now time.Time) bool {
+	if quietHours.StartTime == "" {
+		return false
+	}
+	start, _ := parseTimeOfDay(quietHours.StartTime)
+	end, _ := parseTimeOfDay(quietHours.EndTime)
+	return isWithinTimesOfDay(now, start, end)
+}
+
+// forwardAlert hands the given alert to the forwarder worker if forwarding is enabled for its type.
+func (radio *Radio) forwardAlert(alert Alert) {
+	if settings := radio.GetSettings().AlertForwarding; !settings.isEnabled() || !settings.forwards(alert.Type) {
+		return
+	}
+	radio.alerts.forwarder.queueAlert(alert, func() AlertForwardingSettings {
+		return radio.GetSettings().AlertForwarding
+	})
+}
+
+// queueAlert hands the given alert to the worker goroutine, which applies the settings returned by the given function
+// at the time it gets to the alert. The alert is dropped if the worker has fallen too far behind.
+func (forwarder *alertForwarder) queueAlert(alert Alert, getSettings func() AlertForwardingSettings) {
+	forwarder.queueOnce.Do(func() {
+		forwarder.queue = make(chan Alert, maxQueuedForwardedAlerts)
+		go forwarder.run(getSettings)
+	})
+	select {
+	case forwarder.queue <- alert:
+	default:
+		log.Printf("Dropping alert %s for forwarding since too many are already queued", alert.Type)
+	}
+}
+
+// run is the body of the worker goroutine, which forwards queued alerts as they arrive and periodically sends the
+// alerts held during quiet hours once they are over.
+func (forwarder *alertForwarder) run(getSettings func() AlertForwardingSettings) {
+	ticker := time.NewTicker(alertForwarderFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case alert := <-forwarder.queue:
+			forwarder.handle(getSettings(), alert, time.Now())
+		case now := <-ticker.C:
+			forwarder.flushHeld(getSettings(), now)
+		}
+	}
+}
+
+// handle forwards the given alert, holds it if it was raised during quiet hours, or suppresses it if forwarding it
+// would exceed the rate limits.
+func (forwarder *alertForwarder) handle(settings AlertForwardingSettings, alert Alert, now time.Time) {
+	if !settings.isEnabled() {
+		return
+	}
+	if settings.QuietHours.isQuietAt(now) {
+		forwarder.heldCount++
+		if len(forwarder.heldAlerts) < maxHeldForwardedAlerts {
+			forwarder.heldAlerts = append(forwarder.heldAlerts, alert)
+		}
+		return
+	}
+	forwarder.flushHeld(settings, now)
+
+	key := alert.Type + " " + alert.Station
+	minInterval := time.Duration(settings.MinIntervalMin) * time.Minute
+	if lastForwardedAt, ok := forwarder.lastForwardedAt[key]; ok && now.Sub(lastForwardedAt) < minInterval {
+		forwarder.suppressedCount++
+		return
+	}
+	if !forwarder.isWithinHourlyLimit(settings, now) {
+		log.Printf(
+			"Not forwarding alert %s since %d messages were already sent in the last hour",
+			alert.Type,
+			settings.MaxPerHour,
+		)
+		forwarder.suppressedCount++
+		return
+	}
+	if forwarder.lastForwardedAt == nil {
+		forwarder.lastForwardedAt = make(map[string]time.Time)
+	}
+	forwarder.lastForwardedAt[key] = now
+	forwarder.sentAt = append(forwarder.sentAt, now)
+
+	message := describeForwardedAlert(alert)
+	if forwarder.suppressedCount > 0 {
+		message += fmt.Sprintf(" (%d other alerts were not forwarded due to rate limiting)", forwarder.suppressedCount)
+		forwarder.suppressedCount = 0
+	}
+	forwarder.send(settings, alert.Type, message, now)
+}
+
+// flushHeld sends the alerts held during quiet hours together in a single message, if there are any and quiet hours
+// are over. The message is exempt from the rate limits and doesn't count towards them, since it stands in for all of
+// the held alerts.
+func (forwarder *alertForwarder) flushHeld(settings AlertForwardingSettings, now time.Time) {
+	if forwarder.heldCount == 0 || settings.QuietHours.isQuietAt(now) {
+		return
+	}
+	lines := []string{fmt.Sprintf("%d alerts were raised during quiet hours:", forwarder.heldCount)}
+	for _, alert := range forwarder.heldAlerts {
+		lines = append(lines, describeForwardedAlert(alert))
+	}
+	if omittedCount := forwarder.heldCount - len(forwarder.heldAlerts); omittedCount > 0 {
+		lines = append(lines, fmt.Sprintf("...and %d more.", omittedCount))
+	}
+	forwarder.heldAlerts = nil
+	forwarder.heldCount = 0
+	if settings.isEnabled() {
+		forwarder.send(settings, "QUIET_HOURS_SUMMARY", strings.Join(lines, "\n"), now)
+	}
+}
+
+// isWithinHourlyLimit returns true if another message can be sent at the given time without exceeding the maximum
+// number of messages per hour.
+func (forwarder *alertForwarder) isWithinHourlyLimit(settings AlertForwardingSettings, now time.Time) bool {
+	sentAt := forwarder.sentAt[:0]
+	for _, sentTime := range forwarder.sentAt {
+		if now.Sub(sentTime) < time.Hour {
+			sentAt = append(sentAt, sentTime)
+		}
+	}
+	forwarder.sentAt = sentAt
+	return settings.MaxPerHour == 0 || len(forwarder.sentAt) < settings.MaxPerHour
+}
+
+// send delivers the given message, about alerts of the given type, to each configured relay and gateway.
+func (forwarder *alertForwarder) send(
+	settings AlertForwardingSettings, alertType string, message string, now time.Time,
+) {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "radio"
+	}
+	if settings.Smtp.Address != "" {
+		subject := fmt.Sprintf("Alert from %s: %s", hostname, alertType)
+		if err = emailAlert(settings.Smtp, subject, message, now); err != nil {
+			log.Printf("Error forwarding alert %s to SMTP relay %s: %v", alertType, settings.Smtp.Address, err)
+		}
+	}
+	if settings.SmsGateway.Url != "" {
+		for _, recipient := range settings.SmsGateway.Recipients {
+			if err = postAlertToSmsGateway(settings.SmsGateway, recipient, hostname+": "+message); err != nil {
+				log.Printf("Error forwarding alert %s to SMS gateway for %s: %v", alertType, recipient, err)
+			}
+		}
+	}
+}
+
+// describeForwardedAlert returns a single-line description of the given alert.
+func describeForwardedAlert(alert Alert) string {
+	if alert.Station != "" {
+		return fmt.Sprintf("%s (%s): %s", alert.Type, alert.Station, alert.Message)
+	}
+	return fmt.Sprintf("%s: %s", alert.Type, alert.Message)
+}
+
+// emailAlert sends an email with the given subject and body through the given SMTP relay.
+func emailAlert(relay SmtpRelaySettings, subject string, body string, now time.Time) error {
+	var auth smtp.Auth
+	if relay.Username != "" {
+		host, _, _ := net.SplitHostPort(relay.Address)
+		auth = smtp.PlainAuth("", relay.Username, relay.Password, host)
+	}
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", relay.From)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(relay.To, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&message, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	message.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	message.WriteString("\r\n")
+	return sendMail(relay.Address, auth, relay.From, relay.To, message.Bytes())
+}
+
+// postAlertToSmsGateway sends the given text message to the given phone number through the SMS gateway.
+func postAlertToSmsGateway(gateway SmsGatewaySettings, recipient string, message string) error {
+	requestJson, err := json.Marshal(map[string]string{"to": recipient, "message": message})
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest("POST", gateway.Url, bytes.NewReader(requestJson))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if gateway.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+gateway.Token)
+	}
+	response, err := smsGatewayClient.Do(request)
+	if err != nil {
+		return err
+	}
+	_ = response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("gateway returned status %d", response.StatusCode)
+	}
+	return nil
+}
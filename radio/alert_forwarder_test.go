@@ -0,0 +1,167 @@
+package radio
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sentMail represents an email handed to the SMTP relay.
+type sentMail struct {
+	address string
+	from    string
+	to      []string
+	message string
+}
+
+// fakeSendMail replaces the function used to send email for the duration of the test with one that records each email
+// on the returned channel rather than sending it.
+func fakeSendMail(t *testing.T) chan sentMail {
+	mails := make(chan sentMail, 10)
+	originalSendMail := sendMail
+	t.Cleanup(func() { sendMail = originalSendMail })
+	sendMail = func(address string, auth smtp.Auth, from string, to []string, message []byte) error {
+		mails <- sentMail{address: address, from: from, to: to, message: string(message)}
+		return nil
+	}
+	return mails
+}
+
+func TestRadio_forwardAlert(t *testing.T) {
+	mails := fakeSendMail(t)
+	type textMessage struct {
+		To      string `json:"to"`
+		Message string `json:"message"`
+	}
+	textMessages := make(chan textMessage, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message textMessage
+		assert.Equal(t, "Bearer gateway-token", r.Header.Get("Authorization"))
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&message))
+		textMessages <- message
+	}))
+	defer server.Close()
+
+	var radio Radio
+	settings := defaultSettings()
+	settings.AlertForwarding.Smtp = SmtpRelaySettings{
+		Address: "smtp.example.org:587", From: "radio@example.org", To: []string{"fta@example.org"},
+	}
+	settings.AlertForwarding.SmsGateway = SmsGatewaySettings{
+		Url: server.URL, Token: "gateway-token", Recipients: []string{"+15555550100"},
+	}
+	radio.SetSettings(settings)
+
+	// Alerts that aren't critical aren't forwarded, so the first message received is for the critical one.
+	radio.raiseAlert("CONFIG_DRIFT", "UCI configuration wireless was changed outside the API.")
+	radio.raiseStationAlert("blue2", "STATION_FAILURE", "Station blue2 has failed.")
+	select {
+	case mail := <-mails:
+		assert.Equal(t, "smtp.example.org:587", mail.address)
+		assert.Equal(t, "radio@example.org", mail.from)
+		assert.Equal(t, []string{"fta@example.org"}, mail.to)
+		assert.Contains(t, mail.message, "To: fta@example.org\r\n")
+		assert.Contains(t, mail.message, ": STATION_FAILURE\r\n")
+		assert.True(
+			t, strings.HasSuffix(mail.message, "\r\n\r\nSTATION_FAILURE (blue2): Station blue2 has failed.\r\n"),
+		)
+	case <-time.After(time.Second):
+		assert.Fail(t, "alert was not emailed")
+	}
+	select {
+	case message := <-textMessages:
+		assert.Equal(t, "+15555550100", message.To)
+		assert.True(t, strings.HasSuffix(message.Message, ": STATION_FAILURE (blue2): Station blue2 has failed."))
+	case <-time.After(time.Second):
+		assert.Fail(t, "alert was not sent to the SMS gateway")
+	}
+}
+
+func TestAlertForwarder_handleRateLimits(t *testing.T) {
+	mails := fakeSendMail(t)
+	settings := AlertForwardingSettings{
+		Smtp:           SmtpRelaySettings{Address: "localhost:25", From: "radio@example.org", To: []string{"fta"}},
+		MinIntervalMin: 30,
+		MaxPerHour:     3,
+	}
+	var forwarder alertForwarder
+	now := time.Date(2026, 3, 14, 12, 0, 0, 0, time.Local)
+	receivedMessages := func() []string {
+		var messages []string
+		for len(mails) > 0 {
+			mail := <-mails
+			messages = append(messages, strings.TrimSpace(mail.message[strings.Index(mail.message, "\r\n\r\n"):]))
+		}
+		return messages
+	}
+
+	forwarder.handle(settings, Alert{Type: "STATION_FAILURE", Station: "red1", Message: "failed"}, now)
+	forwarder.handle(settings, Alert{Type: "STATION_FAILURE", Station: "red1", Message: "failed again"}, now)
+	forwarder.handle(settings, Alert{Type: "STATION_FAILURE", Station: "red2", Message: "failed"}, now)
+	assert.Equal(
+		t,
+		[]string{
+			"STATION_FAILURE (red1): failed",
+			"STATION_FAILURE (red2): failed (1 other alerts were not forwarded due to rate limiting)",
+		},
+		receivedMessages(),
+	)
+
+	// Alerts of the same type about the same station are forwarded again once the minimum interval has passed.
+	now = now.Add(30 * time.Minute)
+	forwarder.handle(settings, Alert{Type: "STATION_FAILURE", Station: "red1", Message: "still failed"}, now)
+	assert.Equal(t, []string{"STATION_FAILURE (red1): still failed"}, receivedMessages())
+
+	// No more than the maximum number of messages are sent in any hour.
+	forwarder.handle(settings, Alert{Type: "OVERHEATING", Message: "too hot"}, now)
+	assert.Empty(t, receivedMessages())
+	now = now.Add(31 * time.Minute)
+	forwarder.handle(settings, Alert{Type: "OVERHEATING", Message: "still too hot"}, now)
+	assert.Equal(
+		t,
+		[]string{"OVERHEATING: still too hot (1 other alerts were not forwarded due to rate limiting)"},
+		receivedMessages(),
+	)
+
+	// Nothing is sent once forwarding is disabled.
+	forwarder.handle(AlertForwardingSettings{}, Alert{Type: "RADIO_ERROR", Message: "error"}, now)
+	assert.Empty(t, receivedMessages())
+}
+
+func TestAlertForwarder_handleQuietHours(t *testing.T) {
+	mails := fakeSendMail(t)
+	settings := AlertForwardingSettings{
+		Smtp:       SmtpRelaySettings{Address: "localhost:25", From: "radio@example.org", To: []string{"fta"}},
+		MaxPerHour: 1,
+		QuietHours: AlertQuietHours{StartTime: "22:00", EndTime: "07:00"},
+	}
+	var forwarder alertForwarder
+
+	night := time.Date(2026, 3, 14, 23, 0, 0, 0, time.Local)
+	for i := 0; i < maxHeldForwardedAlerts+2; i++ {
+		forwarder.handle(settings, Alert{Type: "RADIO_ERROR", Message: "error"}, night)
+	}
+	forwarder.flushHeld(settings, night.Add(7*time.Hour))
+	assert.Empty(t, mails)
+
+	// The held alerts are sent together once quiet hours are over, regardless of the rate limits.
+	morning := time.Date(2026, 3, 15, 7, 0, 0, 0, time.Local)
+	forwarder.handle(settings, Alert{Type: "OVERHEATING", Message: "too hot"}, morning)
+	forwarder.handle(settings, Alert{Type: "RADIO_ERROR", Message: "another error"}, morning)
+	if assert.Equal(t, 2, len(mails)) {
+		mail := <-mails
+		assert.Contains(t, mail.message, ": QUIET_HOURS_SUMMARY\r\n")
+		assert.Contains(t, mail.message, "\r\n\r\n22 alerts were raised during quiet hours:\r\nRADIO_ERROR: error\r\n")
+		assert.Equal(t, maxHeldForwardedAlerts, strings.Count(mail.message, "RADIO_ERROR: error\r\n"))
+		assert.True(t, strings.HasSuffix(mail.message, "\r\n...and 2 more.\r\n"))
+		mail = <-mails
+		assert.Contains(t, mail.message, ": OVERHEATING\r\n")
+	}
+	forwarder.flushHeld(settings, morning)
+	assert.Empty(t, mails)
+}
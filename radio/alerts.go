@@ -0,0 +1,148 @@
+package radio
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// Maximum number of alerts to retain; older ones are discarded first.
+	maxAlerts = 100
+
+	// How long to wait for the alert webhook to respond before giving up.
+	alertWebhookTimeout = 5 * time.Second
+
+	// Maximum number of alerts waiting to be delivered to the webhook; further alerts are only recorded locally until
+	// the backlog clears.
+	maxQueuedWebhookAlerts = 20
+)
+
+// HTTP client used to deliver alerts to the webhook.
+var alertWebhookClient = &http.Client{Timeout: alertWebhookTimeout}
+
+// Alert represents a noteworthy condition detected by the radio that may require attention from the FTA.
+type Alert struct {
+	// Time at which the alert was raised.
+	Time Timestamp `json:"time"`
+
+	// Machine-readable category of the alert (e.g. "ROGUE_NETWORK").
+	Type string `json:"type"`
+
+	// Team station that the alert concerns (e.g. "red1"), or blank if it concerns the radio as a whole.
+	Station string `json:"station"`
+
+	// Human-readable description of the alert.
+	Message string `json:"message"`
+
+	// RF conditions captured when the alert was raised, or null if the alert isn't about a link.
+	RfSnapshot *RfSnapshot `json:"rfSnapshot"`
+}
+
+// alertLog holds the most recent alerts; it is shared between the radio and web goroutines.
+type alertLog struct {
+	mutex  sync.Mutex
+	alerts []Alert
+
+	// Queue of alerts awaiting delivery by the single webhook worker goroutine, which is started on first use.
+	webhookQueue     chan webhookDelivery
+	webhookQueueOnce sync.Once
+
+	// Worker that forwards critical alerts by email or text message.
+	forwarder alertForwarder
+}
+
+// webhookDelivery represents an alert to be posted to the webhook URL that was configured when it was raised.
+type webhookDelivery struct {
+	webhookUrl string
+	alert      Alert
+}
+
+// raiseAlert logs the given alert and records it for retrieval via the API.
+func (radio *Radio) raiseAlert(alertType string, format string, args ...any) {
+	radio.recordAlert(Alert{Type: alertType, Message: fmt.Sprintf(format, args...)})
+}
+
+// raiseStationAlert logs the given alert about the team station with the given name and records it for retrieval via
+// the API.
+func (radio *Radio) raiseStationAlert(stationName string, alertType string, format string, args ...any) {
+	radio.recordAlert(Alert{Type: alertType, Station: stationName, Message: fmt.Sprintf(format, args...)})
+}
+
+// RecordApiPanic raises an alert about a panic that the web server recovered from while serving the given request.
+func (radio *Radio) RecordApiPanic(method, path string, recovered any) {
+	radio.raiseAlert("API_PANIC", "Panic serving %s %s: %v", method, path, recovered)
+}
+
+// recordAlert timestamps the given alert, captures any RF snapshot that goes with it, records it, and passes it on to
+// the webhook and alert forwarder.
+func (radio *Radio) recordAlert(alert Alert) {
+	alert.Time = newTimestamp()
+	log.Printf("Alert %s: %s", alert.Type, alert.Message)
+	alert.RfSnapshot = radio.captureRfSnapshot(alert.Type)
+
+	radio.alerts.mutex.Lock()
+	radio.alerts.alerts = append(radio.alerts.alerts, alert)
+	if len(radio.alerts.alerts) > maxAlerts {
+		radio.alerts.alerts = radio.alerts.alerts[len(radio.alerts.alerts)-maxAlerts:]
+	}
+	radio.alerts.mutex.Unlock()
+
+	if webhookUrl := radio.GetSettings().AlertWebhookUrl; webhookUrl != "" {
+		radio.alerts.queueWebhookDelivery(webhookDelivery{webhookUrl: webhookUrl, alert: alert})
+	}
+	radio.forwardAlert(alert)
+}
+
+// queueWebhookDelivery hands the given alert to the webhook worker goroutine, dropping it if the worker has fallen too
+// far behind so that an unresponsive webhook can't hold up the caller or pile up goroutines.
+func (alerts *alertLog) queueWebhookDelivery(delivery webhookDelivery) {
+	alerts.webhookQueueOnce.Do(func() {
+		alerts.webhookQueue = make(chan webhookDelivery, maxQueuedWebhookAlerts)
+		go func() {
+			for delivery := range alerts.webhookQueue {
+				postAlertToWebhook(delivery.webhookUrl, delivery.alert)
+			}
+		}()
+	})
+	select {
+	case alerts.webhookQueue <- delivery:
+	default:
+		log.Printf(
+			"Dropping alert %s for webhook %s since too many are already queued",
+			delivery.alert.Type,
+			delivery.webhookUrl,
+		)
+	}
+}
+
+// postAlertToWebhook delivers the given alert as a JSON POST request to the given URL.
+func postAlertToWebhook(webhookUrl string, alert Alert) {
+	alertJson, err := json.Marshal(alert)
+	if err != nil {
+		log.Printf("Error marshalling alert for webhook: %v", err)
+		return
+	}
+	response, err := alertWebhookClient.Post(webhookUrl, "application/json", bytes.NewReader(alertJson))
+	if err != nil {
+		log.Printf("Error posting alert to webhook %s: %v", webhookUrl, err)
+		return
+	}
+	_ = response.Body.Close()
+	if response.StatusCode >= 300 {
+		log.Printf("Alert webhook %s returned status %d", webhookUrl, response.StatusCode)
+	}
+}
+
+// GetAlerts returns the most recent alerts, oldest first.
+func (radio *Radio) GetAlerts() []Alert {
+	radio.alerts.mutex.Lock()
+	defer radio.alerts.mutex.Unlock()
+	alerts := make([]Alert, len(radio.alerts.alerts))
+	copy(alerts, radio.alerts.alerts)
+	return alerts
+}
@@ -0,0 +1,94 @@
+package radio
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRadio_raiseAlert(t *testing.T) {
+	var radio Radio
+	assert.Equal(t, []Alert{}, radio.GetAlerts())
+
+	radio.raiseAlert("TEST", "something happened on %s", "red1")
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "TEST", alerts[0].Type)
+		assert.Equal(t, "something happened on red1", alerts[0].Message)
+		assert.False(t, alerts[0].Time.Wallclock.IsZero())
+	}
+
+	// Only the most recent alerts are retained.
+	for i := 0; i < maxAlerts+5; i++ {
+		radio.raiseAlert("TEST", "alert %d", i)
+	}
+	alerts = radio.GetAlerts()
+	assert.Equal(t, maxAlerts, len(alerts))
+	assert.Equal(t, "alert 5", alerts[0].Message)
+	assert.Equal(t, fmt.Sprintf("alert %d", maxAlerts+4), alerts[maxAlerts-1].Message)
+}
+
+func TestRadio_raiseAlertWebhook(t *testing.T) {
+	receivedAlerts := make(chan Alert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert Alert
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&alert))
+		receivedAlerts <- alert
+	}))
+	defer server.Close()
+
+	var radio Radio
+	settings := radio.GetSettings()
+	settings.AlertWebhookUrl = server.URL
+	radio.SetSettings(settings)
+	radio.raiseAlert("TEST", "something happened on %s", "blue2")
+	select {
+	case alert := <-receivedAlerts:
+		assert.Equal(t, "TEST", alert.Type)
+		assert.Equal(t, "something happened on blue2", alert.Message)
+	case <-time.After(time.Second):
+		assert.Fail(t, "alert was not delivered to the webhook")
+	}
+}
+
+func TestRadio_raiseAlertWebhookQueueFull(t *testing.T) {
+	inFlight := make(chan struct{}, 1)
+	release := make(chan struct{})
+	delivered := make(chan struct{}, 2*maxQueuedWebhookAlerts)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case inFlight <- struct{}{}:
+		default:
+		}
+		<-release
+		delivered <- struct{}{}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	var radio Radio
+	settings := radio.GetSettings()
+	settings.AlertWebhookUrl = server.URL
+	radio.SetSettings(settings)
+
+	// Wait for the worker to be stuck delivering the first alert.
+	radio.raiseAlert("TEST", "alert 0")
+	select {
+	case <-inFlight:
+	case <-time.After(time.Second):
+		assert.Fail(t, "alert was not delivered to the webhook")
+		return
+	}
+
+	// Raising alerts never blocks on the webhook, even once more are waiting than can be queued.
+	for i := 1; i < 2*maxQueuedWebhookAlerts; i++ {
+		radio.raiseAlert("TEST", "alert %d", i)
+	}
+	assert.Equal(t, 2*maxQueuedWebhookAlerts, len(radio.GetAlerts()))
+	assert.Equal(t, maxQueuedWebhookAlerts, len(radio.alerts.webhookQueue))
+}
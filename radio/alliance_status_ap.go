@@ -0,0 +1,68 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import "math"
+
+// AllianceStatus summarizes the networks of the three stations of one alliance at a glance, so that field displays
+// don't have to aggregate the individual station statuses themselves.
+type AllianceStatus struct {
+	// Number of the alliance's stations with a team network configured.
+	ConfiguredStationCount int `json:"configuredStationCount"`
+
+	// Number of the alliance's stations whose network is associated with a robot.
+	LinkedStationCount int `json:"linkedStationCount"`
+
+	// Combined five-second average bandwidth used by the alliance's stations, in megabits per second.
+	TotalBandwidthMbps float64 `json:"totalBandwidthMbps"`
+
+	// Lowest signal-to-noise ratio among the alliance's linked stations, in decibels. Zero if none is linked.
+	WorstSignalNoiseRatio int `json:"worstSignalNoiseRatio"`
+
+	// Station with the lowest signal-to-noise ratio (e.g. "red2"), or blank if none is linked.
+	WorstSignalNoiseRatioStation string `json:"worstSignalNoiseRatioStation"`
+
+	// Lowest connection quality score among the alliance's linked stations. Zero if none is linked.
+	WorstQualityScore int `json:"worstQualityScore"`
+
+	// Station with the lowest connection quality score (e.g. "red2"), or blank if none is linked.
+	WorstQualityScoreStation string `json:"worstQualityScoreStation"`
+}
+
+// updateAllianceStatuses recomputes the per-alliance summaries from the current station statuses. Values that
+// couldn't be measured are left out rather than skewing the summary.
+func (radio *Radio) updateAllianceStatuses() {
+	allianceStatuses := map[string]AllianceStatus{"red": {}, "blue": {}}
+	for station := red1; station <= blue3; station++ {
+		stationStatus := radio.StationStatuses[station.String()]
+		if stationStatus == nil {
+			continue
+		}
+		alliance := "red"
+		if station >= blue1 {
+			alliance = "blue"
+		}
+		allianceStatus := allianceStatuses[alliance]
+		allianceStatus.ConfiguredStationCount++
+		if stationStatus.BandwidthUsedMbps > 0 {
+			allianceStatus.TotalBandwidthMbps += stationStatus.BandwidthUsedMbps
+		}
+		if stationStatus.IsLinked {
+			allianceStatus.LinkedStationCount++
+			if snr := stationStatus.SignalNoiseRatio; snr != monitoringErrorCode &&
+				(allianceStatus.WorstSignalNoiseRatioStation == "" || snr < allianceStatus.WorstSignalNoiseRatio) {
+				allianceStatus.WorstSignalNoiseRatio = snr
+				allianceStatus.WorstSignalNoiseRatioStation = station.String()
+			}
+			if allianceStatus.WorstQualityScoreStation == "" ||
+				stationStatus.QualityScore < allianceStatus.WorstQualityScore {
+				allianceStatus.WorstQualityScore = stationStatus.QualityScore
+				allianceStatus.WorstQualityScoreStation = station.String()
+			}
+		}
+		allianceStatus.TotalBandwidthMbps = math.Round(1000*allianceStatus.TotalBandwidthMbps) / 1000
+		allianceStatuses[alliance] = allianceStatus
+	}
+	radio.AllianceStatuses = allianceStatuses
+}
@@ -0,0 +1,53 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRadio_updateAllianceStatuses(t *testing.T) {
+	radio := &Radio{StationStatuses: map[string]*NetworkStatus{}}
+	radio.updateAllianceStatuses()
+	assert.Equal(t, map[string]AllianceStatus{"red": {}, "blue": {}}, radio.AllianceStatuses)
+
+	radio.StationStatuses = map[string]*NetworkStatus{
+		"red1":  {IsLinked: true, SignalNoiseRatio: 40, BandwidthUsedMbps: 4.102, QualityScore: 62},
+		"red2":  {IsLinked: true, SignalNoiseRatio: 18, BandwidthUsedMbps: 2.25, QualityScore: 71},
+		"red3":  {IsLinked: false, BandwidthUsedMbps: 0},
+		"blue1": nil,
+		"blue2": {IsLinked: true, SignalNoiseRatio: monitoringErrorCode, BandwidthUsedMbps: monitoringErrorCode},
+		"blue3": {IsLinked: true, SignalNoiseRatio: 25, BandwidthUsedMbps: 0.1, QualityScore: 80},
+	}
+	radio.updateAllianceStatuses()
+	assert.Equal(
+		t,
+		AllianceStatus{
+			ConfiguredStationCount:       3,
+			LinkedStationCount:           2,
+			TotalBandwidthMbps:           6.352,
+			WorstSignalNoiseRatio:        18,
+			WorstSignalNoiseRatioStation: "red2",
+			WorstQualityScore:            62,
+			WorstQualityScoreStation:     "red1",
+		},
+		radio.AllianceStatuses["red"],
+	)
+
+	// Values that couldn't be measured are left out of the summary.
+	assert.Equal(
+		t,
+		AllianceStatus{
+			ConfiguredStationCount:       2,
+			LinkedStationCount:           2,
+			TotalBandwidthMbps:           0.1,
+			WorstSignalNoiseRatio:        25,
+			WorstSignalNoiseRatioStation: "blue3",
+			WorstQualityScore:            0,
+			WorstQualityScoreStation:     "blue2",
+		},
+		radio.AllianceStatuses["blue"],
+	)
+}
@@ -0,0 +1,110 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import "time"
+
+// How far back link drops are counted against the stability of the association.
+const linkDropWindow = 10 * time.Minute
+
+// associationHistory tracks the uptime and link drops of a single station's network since its team was configured.
+type associationHistory struct {
+	ssid string
+
+	// MAC address of the device linked as of the last poll, or blank if none was.
+	macAddress string
+
+	// Signal-to-noise ratio of the link as of the last poll, or zero if none was linked.
+	signalNoiseRatio int
+
+	// Time of the last poll.
+	polledAt time.Time
+
+	// Times of the link drops within the drop window, oldest first.
+	dropTimes []time.Time
+
+	// Total time for which a device has been linked.
+	associatedTime time.Duration
+
+	// Number of times the linked device has disconnected or been replaced by another.
+	disconnectCount int
+
+	// Time at which the linked device disconnected, or zero if a device is linked or none has been yet.
+	disconnectedAt time.Time
+
+	// Longest completed period without a linked device after one first connected.
+	longestGap time.Duration
+
+	// Connections and disconnections logged by hostapd within the association storm window, oldest first.
+	associationEvents []AssociationEvent
+}
+
+// updateAssociationHistories records the uptime and any link drops of each station since the last poll as of the
+// given time, raising an alert if a link has collapsed or been lost. A station's history starts over whenever a
+// different team is configured on it.
+func (radio *Radio) updateAssociationHistories(now time.Time) {
+	if radio.associationHistories == nil {
+		radio.associationHistories = make(map[station]*associationHistory)
+	}
+	for station := red1; station <= blue3; station++ {
+		stationStatus := radio.StationStatuses[station.String()]
+		history := radio.associationHistories[station]
+		if stationStatus == nil || history == nil || history.ssid != stationStatus.Ssid {
+			delete(radio.associationHistories, station)
+			if stationStatus == nil {
+				continue
+			}
+			history = &associationHistory{ssid: stationStatus.Ssid}
+			radio.associationHistories[station] = history
+		}
+		previousMacAddress, previousSnr := history.macAddress, history.signalNoiseRatio
+		history.update(stationStatus, now)
+		radio.checkLinkEvents(station, stationStatus, previousMacAddress, previousSnr)
+		stationStatus.RecentLinkDropCount = len(history.dropTimes)
+		stationStatus.AssociatedTimeSec = int(history.associatedTime / time.Second)
+		stationStatus.DisconnectCount = history.disconnectCount
+		stationStatus.LongestGapSec = int(history.longestGapAt(now) / time.Second)
+	}
+}
+
+// update records a link drop if the device linked as of the last poll is no longer linked, and forgets drops that are
+// too old to count. The time between polls only counts towards the uptime if the same device was linked throughout.
+func (history *associationHistory) update(status *NetworkStatus, now time.Time) {
+	macAddress := ""
+	if status.IsLinked {
+		macAddress = status.MacAddress
+	}
+	if history.macAddress != "" {
+		if macAddress == history.macAddress {
+			history.associatedTime += now.Sub(history.polledAt)
+		} else {
+			history.dropTimes = append(history.dropTimes, now)
+			history.disconnectCount++
+			if macAddress == "" {
+				history.disconnectedAt = now
+			}
+		}
+	} else if macAddress != "" && !history.disconnectedAt.IsZero() {
+		history.longestGap = history.longestGapAt(now)
+		history.disconnectedAt = time.Time{}
+	}
+	history.macAddress = macAddress
+	history.signalNoiseRatio = 0
+	if macAddress != "" {
+		history.signalNoiseRatio = status.SignalNoiseRatio
+	}
+	history.polledAt = now
+
+	for len(history.dropTimes) > 0 && now.Sub(history.dropTimes[0]) >= linkDropWindow {
+		history.dropTimes = history.dropTimes[1:]
+	}
+}
+
+// longestGapAt returns the longest period without a linked device as of the given time, including any ongoing one.
+func (history *associationHistory) longestGapAt(now time.Time) time.Duration {
+	if !history.disconnectedAt.IsZero() && now.Sub(history.disconnectedAt) > history.longestGap {
+		return now.Sub(history.disconnectedAt)
+	}
+	return history.longestGap
+}
@@ -0,0 +1,82 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRadio_updateAssociationHistories(t *testing.T) {
+	radio := &Radio{StationStatuses: map[string]*NetworkStatus{}}
+	red1Status := &NetworkStatus{Ssid: "1111", IsLinked: true, MacAddress: "48:DA:35:B0:01:CF"}
+	radio.StationStatuses["red1"] = red1Status
+	radio.StationStatuses["blue2"] = &NetworkStatus{Ssid: "5555"}
+	startTime := time.Now()
+
+	radio.updateAssociationHistories(startTime)
+	assert.Equal(t, 0, red1Status.RecentLinkDropCount)
+	assert.Equal(t, 0, red1Status.AssociatedTimeSec)
+	assert.Equal(t, 0, red1Status.DisconnectCount)
+	assert.Equal(t, 0, red1Status.LongestGapSec)
+
+	// A station with no robot connected accrues neither uptime nor gaps.
+	radio.updateAssociationHistories(startTime.Add(time.Minute))
+	assert.Equal(t, 60, red1Status.AssociatedTimeSec)
+	assert.Equal(t, 0, radio.StationStatuses["blue2"].AssociatedTimeSec)
+	assert.Equal(t, 0, radio.StationStatuses["blue2"].LongestGapSec)
+
+	// The robot drops off, then reconnects.
+	red1Status.IsLinked = false
+	radio.updateAssociationHistories(startTime.Add(2 * time.Minute))
+	assert.Equal(t, 1, red1Status.RecentLinkDropCount)
+	assert.Equal(t, 1, red1Status.DisconnectCount)
+	assert.Equal(t, 60, red1Status.AssociatedTimeSec)
+	assert.Equal(t, 0, red1Status.LongestGapSec)
+	radio.updateAssociationHistories(startTime.Add(2*time.Minute + 30*time.Second))
+	assert.Equal(t, 30, red1Status.LongestGapSec)
+	red1Status.IsLinked = true
+	radio.updateAssociationHistories(startTime.Add(3 * time.Minute))
+	assert.Equal(t, 1, red1Status.RecentLinkDropCount)
+	assert.Equal(t, 60, red1Status.AssociatedTimeSec)
+	assert.Equal(t, 60, red1Status.LongestGapSec)
+
+	// A different device taking over the station also counts as a drop, but not as a gap.
+	red1Status.MacAddress = "48:DA:35:B0:01:D0"
+	radio.updateAssociationHistories(startTime.Add(4 * time.Minute))
+	assert.Equal(t, 2, red1Status.RecentLinkDropCount)
+	assert.Equal(t, 2, red1Status.DisconnectCount)
+	assert.Equal(t, 60, red1Status.AssociatedTimeSec)
+	assert.Equal(t, 60, red1Status.LongestGapSec)
+	radio.updateAssociationHistories(startTime.Add(5 * time.Minute))
+	assert.Equal(t, 120, red1Status.AssociatedTimeSec)
+
+	// Drops are forgotten once they are old enough, but still count towards the total.
+	radio.updateAssociationHistories(startTime.Add(12 * time.Minute))
+	assert.Equal(t, 1, red1Status.RecentLinkDropCount)
+	radio.updateAssociationHistories(startTime.Add(14 * time.Minute))
+	assert.Equal(t, 0, red1Status.RecentLinkDropCount)
+	assert.Equal(t, 2, red1Status.DisconnectCount)
+	assert.Equal(t, 660, red1Status.AssociatedTimeSec)
+
+	// A shorter gap doesn't replace the longest one.
+	red1Status.IsLinked = false
+	radio.updateAssociationHistories(startTime.Add(15 * time.Minute))
+	red1Status.IsLinked = true
+	radio.updateAssociationHistories(startTime.Add(15*time.Minute + 10*time.Second))
+	assert.Equal(t, 3, red1Status.DisconnectCount)
+	assert.Equal(t, 60, red1Status.LongestGapSec)
+
+	// A newly assigned team starts with a clean history.
+	radio.StationStatuses["red1"] = &NetworkStatus{Ssid: "9999", IsLinked: true, MacAddress: "48:DA:35:B0:01:CF"}
+	radio.updateAssociationHistories(startTime.Add(16 * time.Minute))
+	assert.Equal(t, 0, radio.StationStatuses["red1"].RecentLinkDropCount)
+	assert.Equal(t, 0, radio.StationStatuses["red1"].AssociatedTimeSec)
+	assert.Equal(t, 0, radio.StationStatuses["red1"].DisconnectCount)
+	assert.Equal(t, 0, radio.StationStatuses["red1"].LongestGapSec)
+	radio.StationStatuses["red1"] = nil
+	radio.updateAssociationHistories(startTime.Add(17 * time.Minute))
+	assert.NotContains(t, radio.associationHistories, red1)
+}
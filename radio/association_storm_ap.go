@@ -0,0 +1,131 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// How far back connections are counted towards a station's association rate.
+const associationStormWindow = time.Minute
+
+// Regex matching the hostapd messages logged when a client completes or loses its connection to a network, capturing
+// the kind of event and the client's MAC address.
+var associationEventRe = regexp.MustCompile(`^AP-STA-(CONNECTED|DISCONNECTED) ([0-9A-Fa-f:]{17})`)
+
+// AssociationEvent represents a client connecting to or disconnecting from a team network, as logged by hostapd.
+type AssociationEvent struct {
+	// Time at which the event was logged.
+	Time time.Time `json:"time"`
+
+	// Kind of event: "CONNECTED" or "DISCONNECTED".
+	Type string `json:"type"`
+
+	// MAC address of the client.
+	MacAddress string `json:"macAddress"`
+}
+
+// AssociationStorm represents a team network whose clients are connecting and disconnecting in a loop, as published to
+// the event socket when the loop is detected.
+type AssociationStorm struct {
+	// Team station whose network the loop is on (e.g. "red1").
+	Station string `json:"station"`
+
+	// SSID of the network.
+	Ssid string `json:"ssid"`
+
+	// Number of connections within the last minute.
+	ConnectionsPerMin int `json:"connectionsPerMin"`
+
+	// Threshold of connections per minute that was exceeded.
+	ThresholdPerMin int `json:"thresholdPerMin"`
+
+	// Connections and disconnections within the last minute, oldest first, showing the pattern of the loop.
+	Events []AssociationEvent `json:"events"`
+}
+
+// parseAssociationEvent parses the given hostapd message logged at the given time, returning false if it isn't about a
+// client connecting or disconnecting.
+func parseAssociationEvent(message string, eventTime time.Time) (AssociationEvent, bool) {
+	match := associationEventRe.FindStringSubmatch(message)
+	if match == nil {
+		return AssociationEvent{}, false
+	}
+	return AssociationEvent{Time: eventTime, Type: match[1], MacAddress: strings.ToUpper(match[2])}, true
+}
+
+// recordAssociationEvent adds the given event to the history of the given station. Events are discarded for stations
+// whose history hasn't started yet, i.e. whose team was only just configured.
+func (radio *Radio) recordAssociationEvent(station station, event AssociationEvent) {
+	if history := radio.associationHistories[station]; history != nil {
+		history.associationEvents = append(history.associationEvents, event)
+	}
+}
+
+// checkAssociationStorms updates the association rate of each station as of the given time, raising an alert and
+// publishing the pattern to the event socket when a station's clients start connecting more often than the configured
+// threshold. Since each connection attempt disrupts the channel for everyone, a robot radio stuck in a reconnection
+// loop degrades the whole field.
+func (radio *Radio) checkAssociationStorms(now time.Time) {
+	threshold := radio.GetSettings().AssociationStormThresholdPerMin
+	for station := red1; station <= blue3; station++ {
+		stationStatus := radio.StationStatuses[station.String()]
+		history := radio.associationHistories[station]
+		if stationStatus == nil || history == nil {
+			continue
+		}
+
+		events := history.associationEvents
+		for len(events) > 0 && now.Sub(events[0].Time) >= associationStormWindow {
+			events = events[1:]
+		}
+		history.associationEvents = events
+		connections := 0
+		for _, event := range events {
+			if event.Type == "CONNECTED" {
+				connections++
+			}
+		}
+		stationStatus.ConnectionsPerMin = connections
+
+		wasStorm := stationStatus.IsAssociationStorm
+		stationStatus.IsAssociationStorm = threshold > 0 && connections > threshold
+		if stationStatus.IsAssociationStorm && !wasStorm {
+			storm := AssociationStorm{
+				Station:           station.String(),
+				Ssid:              stationStatus.Ssid,
+				ConnectionsPerMin: connections,
+				ThresholdPerMin:   threshold,
+				Events:            append([]AssociationEvent(nil), events...),
+			}
+			radio.raiseStationAlert(
+				station.String(),
+				"ASSOCIATION_STORM",
+				"Station %s (SSID \"%s\") has had %d connections in the last minute from %s, above the threshold "+
+					"of %d; the robot radio is likely stuck in a reconnection loop.",
+				station,
+				stationStatus.Ssid,
+				connections,
+				strings.Join(storm.macAddresses(), ", "),
+				threshold,
+			)
+			radio.publishEvent(eventTypeAssociationStorm, storm)
+		}
+	}
+}
+
+// macAddresses returns the distinct MAC addresses of the clients involved in the storm, in order of first appearance.
+func (storm AssociationStorm) macAddresses() []string {
+	var macAddresses []string
+	seen := make(map[string]bool)
+	for _, event := range storm.Events {
+		if !seen[event.MacAddress] {
+			seen[event.MacAddress] = true
+			macAddresses = append(macAddresses, event.MacAddress)
+		}
+	}
+	return macAddresses
+}
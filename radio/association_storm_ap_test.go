@@ -0,0 +1,98 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseAssociationEvent(t *testing.T) {
+	eventTime := time.Date(2024, 3, 2, 10, 15, 4, 0, time.Local)
+	event, ok := parseAssociationEvent("AP-STA-CONNECTED 48:da:35:b0:01:cf", eventTime)
+	assert.True(t, ok)
+	assert.Equal(t, AssociationEvent{Time: eventTime, Type: "CONNECTED", MacAddress: "48:DA:35:B0:01:CF"}, event)
+	event, ok = parseAssociationEvent("AP-STA-DISCONNECTED 48:da:35:b0:01:cf", eventTime)
+	assert.True(t, ok)
+	assert.Equal(t, "DISCONNECTED", event.Type)
+
+	_, ok = parseAssociationEvent("AP-STA-POSSIBLE-PSK-MISMATCH 48:da:35:b0:01:cf", eventTime)
+	assert.False(t, ok)
+	_, ok = parseAssociationEvent("STA 48:da:35:b0:01:cf IEEE 802.11: associated", eventTime)
+	assert.False(t, ok)
+}
+
+func TestRadio_checkAssociationStorms(t *testing.T) {
+	uciTree = newFakeUciTree()
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+	fakeShell.reset()
+	radio.SetSettings(defaultSettings())
+	radio.StationStatuses["red1"] = &NetworkStatus{Ssid: "254"}
+	radio.StationStatuses["blue2"] = &NetworkStatus{Ssid: "1678"}
+	start := time.Date(2024, 3, 2, 10, 15, 0, 0, time.Local)
+	radio.updateAssociationHistories(start)
+	output := "Sat Mar  2 10:15:00 2024 daemon.info hostapd: wlan0: AP-STA-CONNECTED 48:da:35:b0:01:cf\n"
+	fakeShell.commandOutput["logread -l 200 -e hostapd"] = output
+	radio.updateHandshakeFailures()
+
+	// A steady connection doesn't count as a storm.
+	output += "Sat Mar  2 10:15:01 2024 daemon.info hostapd: wlan0: AP-STA-CONNECTED 48:da:35:b0:01:cf\n" +
+		"Sat Mar  2 10:15:02 2024 daemon.info hostapd: wlan0-4: AP-STA-CONNECTED 48:da:35:b0:05:cf\n"
+	fakeShell.commandOutput["logread -l 200 -e hostapd"] = output
+	radio.updateHandshakeFailures()
+	radio.checkAssociationStorms(start.Add(5 * time.Second))
+	assert.Equal(t, 1, radio.StationStatuses["red1"].ConnectionsPerMin)
+	assert.False(t, radio.StationStatuses["red1"].IsAssociationStorm)
+	assert.Equal(t, 1, radio.StationStatuses["blue2"].ConnectionsPerMin)
+	assert.Empty(t, radio.GetAlerts())
+
+	// Connecting more often than the threshold raises a single alert.
+	for i := 0; i < 6; i++ {
+		output += fmt.Sprintf(
+			"Sat Mar  2 10:15:%02d 2024 daemon.info hostapd: wlan0: AP-STA-DISCONNECTED 48:da:35:b0:01:cf\n"+
+				"Sat Mar  2 10:15:%02d 2024 daemon.info hostapd: wlan0: AP-STA-CONNECTED 48:da:35:b0:01:cf\n",
+			10+5*i,
+			12+5*i,
+		)
+	}
+	fakeShell.commandOutput["logread -l 200 -e hostapd"] = output
+	radio.updateHandshakeFailures()
+	for i := 0; i < 2; i++ {
+		radio.checkAssociationStorms(start.Add(45 * time.Second))
+		assert.Equal(t, 7, radio.StationStatuses["red1"].ConnectionsPerMin)
+		assert.True(t, radio.StationStatuses["red1"].IsAssociationStorm)
+		assert.False(t, radio.StationStatuses["blue2"].IsAssociationStorm)
+	}
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "ASSOCIATION_STORM", alerts[0].Type)
+		assert.Equal(t, "red1", alerts[0].Station)
+		assert.Equal(
+			t,
+			"Station red1 (SSID \"254\") has had 7 connections in the last minute from 48:DA:35:B0:01:CF, above the "+
+				"threshold of 6; the robot radio is likely stuck in a reconnection loop.",
+			alerts[0].Message,
+		)
+	}
+
+	// The storm is over once older connections fall out of the window.
+	radio.checkAssociationStorms(start.Add(75 * time.Second))
+	assert.Equal(t, 5, radio.StationStatuses["red1"].ConnectionsPerMin)
+	assert.False(t, radio.StationStatuses["red1"].IsAssociationStorm)
+	assert.Equal(t, 0, radio.StationStatuses["blue2"].ConnectionsPerMin)
+	assert.Equal(t, 9, len(radio.associationHistories[red1].associationEvents))
+
+	// A threshold of zero disables the flag.
+	settings := defaultSettings()
+	settings.AssociationStormThresholdPerMin = 0
+	radio.SetSettings(settings)
+	radio.checkAssociationStorms(start.Add(45 * time.Second))
+	assert.False(t, radio.StationStatuses["red1"].IsAssociationStorm)
+	assert.Equal(t, 1, len(radio.GetAlerts()))
+}
@@ -0,0 +1,97 @@
+package radio
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// expectedWifiIface describes a wifi-iface section that the API relies on being present in the wireless configuration.
+type expectedWifiIface struct {
+	// Index of the section among the wifi-iface sections.
+	index int
+
+	// Name of the Wi-Fi device that the section must be bound to.
+	device string
+
+	// Operating modes that the section may be in.
+	modes []string
+}
+
+// checkBaseline checks that the wireless configuration has the layout of wifi-iface sections that the API expects to
+// configure, returning a description of each problem found.
+func (radio *Radio) checkBaseline() []string {
+	problems := []string{}
+	expectedIfaces := radio.expectedWifiIfaces()
+	requiredCount := 0
+	for _, expected := range expectedIfaces {
+		if expected.index >= requiredCount {
+			requiredCount = expected.index + 1
+		}
+	}
+	sections, _ := uciTree.GetSections("wireless", "wifi-iface")
+	if len(sections) < requiredCount {
+		problems = append(
+			problems,
+			fmt.Sprintf("expected at least %d wifi-iface sections but found %d", requiredCount, len(sections)),
+		)
+	}
+	for _, expected := range expectedIfaces {
+		if expected.index >= len(sections) {
+			continue
+		}
+		section := fmt.Sprintf("@wifi-iface[%d]", expected.index)
+		if device, _ := uciTree.GetLast("wireless", section, "device"); device != expected.device {
+			problems = append(
+				problems, fmt.Sprintf("%s is bound to device %q instead of %q", section, device, expected.device),
+			)
+		}
+		mode, _ := uciTree.GetLast("wireless", section, "mode")
+		validMode := false
+		for _, expectedMode := range expected.modes {
+			if mode == expectedMode {
+				validMode = true
+				break
+			}
+		}
+		if !validMode {
+			problems = append(
+				problems,
+				fmt.Sprintf("%s has mode %q instead of %s", section, mode, strings.Join(expected.modes, " or ")),
+			)
+		}
+	}
+	return problems
+}
+
+// waitForValidBaseline blocks until the wireless configuration has the expected layout, reporting the problems in the
+// status in the meantime so that configuration requests are rejected rather than written to nonexistent sections.
+func (radio *Radio) waitForValidBaseline() {
+	for {
+		radio.BaselineProblems = radio.checkBaseline()
+		if len(radio.BaselineProblems) == 0 {
+			if radio.Status == statusMisconfiguredBaseline {
+				log.Println("Wireless configuration now has the expected layout.")
+				radio.setStatus(statusBooting)
+				radio.markStatusChanged()
+			}
+			return
+		}
+		if radio.Status != statusMisconfiguredBaseline {
+			radio.raiseAlert(
+				"MISCONFIGURED_BASELINE",
+				"Wireless configuration doesn't have the expected layout: %s.",
+				strings.Join(radio.BaselineProblems, "; "),
+			)
+			radio.setStatus(statusMisconfiguredBaseline)
+			radio.markStatusChanged()
+		}
+		time.Sleep(bootPollIntervalSec * time.Second)
+
+		// Pick up any fixes made to the configuration file in the meantime.
+		if err := uciTree.LoadConfig("wireless", true); err != nil {
+			log.Printf("Error reloading wireless configuration: %v", err)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+// expectedWifiIfaces returns the wifi-iface sections that the access point configures, which are one per team station
+// following the section for the admin network.
+func (radio *Radio) expectedWifiIfaces() []expectedWifiIface {
+	var expectedIfaces []expectedWifiIface
+	for station := red1; station <= blue3; station++ {
+		expectedIfaces = append(
+			expectedIfaces, expectedWifiIface{index: int(station) + 1, device: radio.device, modes: []string{"ap"}},
+		)
+	}
+	return expectedIfaces
+}
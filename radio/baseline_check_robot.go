@@ -0,0 +1,13 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package radio
+
+// expectedWifiIfaces returns the wifi-iface sections that the robot radio configures: the 2.4GHz network, which is
+// always an access point, and the 6GHz network, which connects to the field as a client in robot radio mode.
+func (radio *Radio) expectedWifiIfaces() []expectedWifiIface {
+	return []expectedWifiIface{
+		{index: radioInterfaceIndex24, device: radioDevice24, modes: []string{"ap"}},
+		{index: radioInterfaceIndex6, device: radioDevice6, modes: []string{"ap", "sta"}},
+	}
+}
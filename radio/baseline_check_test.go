@@ -0,0 +1,81 @@
+package radio
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// setValidBaseline populates the given fake UCI tree with the wifi-iface layout expected by the given radio.
+func setValidBaseline(radio *Radio, fakeTree *fakeUciTree) {
+	var sections []string
+	for _, expected := range radio.expectedWifiIfaces() {
+		for len(sections) <= expected.index {
+			sections = append(sections, fmt.Sprintf("cfg%02d", len(sections)))
+		}
+		section := fmt.Sprintf("wireless.@wifi-iface[%d]", expected.index)
+		fakeTree.valuesForGet[section+".device"] = expected.device
+		fakeTree.valuesForGet[section+".mode"] = expected.modes[len(expected.modes)-1]
+	}
+	fakeTree.sectionsForGet["wireless.wifi-iface"] = sections
+}
+
+func TestRadio_checkBaseline(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+	expectedIfaces := radio.expectedWifiIfaces()
+	lastIface := expectedIfaces[len(expectedIfaces)-1]
+	lastSection := fmt.Sprintf("@wifi-iface[%d]", lastIface.index)
+
+	setValidBaseline(radio, fakeTree)
+	assert.Equal(t, []string{}, radio.checkBaseline())
+
+	// Sections bound to the wrong device or in the wrong mode are reported.
+	fakeTree.valuesForGet["wireless."+lastSection+".device"] = "wifi9"
+	fakeTree.valuesForGet["wireless."+lastSection+".mode"] = "mesh"
+	assert.Equal(
+		t,
+		[]string{
+			fmt.Sprintf("%s is bound to device \"wifi9\" instead of %q", lastSection, lastIface.device),
+			fmt.Sprintf("%s has mode \"mesh\" instead of %s", lastSection, strings.Join(lastIface.modes, " or ")),
+		},
+		radio.checkBaseline(),
+	)
+
+	// Missing sections are reported without checking their options.
+	setValidBaseline(radio, fakeTree)
+	sections := fakeTree.sectionsForGet["wireless.wifi-iface"]
+	fakeTree.sectionsForGet["wireless.wifi-iface"] = sections[:len(sections)-1]
+	assert.Equal(
+		t,
+		[]string{fmt.Sprintf("expected at least %d wifi-iface sections but found %d", len(sections), len(sections)-1)},
+		radio.checkBaseline(),
+	)
+	delete(fakeTree.sectionsForGet, "wireless.wifi-iface")
+	assert.Equal(
+		t,
+		[]string{fmt.Sprintf("expected at least %d wifi-iface sections but found 0", len(sections))},
+		radio.checkBaseline(),
+	)
+}
+
+func TestRadio_waitForValidBaseline(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+
+	setValidBaseline(radio, fakeTree)
+	radio.waitForValidBaseline()
+	assert.Equal(t, statusBooting, radio.Status)
+	assert.Empty(t, radio.BaselineProblems)
+	assert.Empty(t, radio.GetAlerts())
+	assert.Empty(t, fakeTree.loadedConfigs)
+}
@@ -0,0 +1,51 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/digineo/go-uci"
+	"strconv"
+)
+
+const (
+	// Beacon interval used by hostapd if none is configured, in time units (TU) of 1.024 ms.
+	defaultBeaconIntervalTu = 100
+
+	// DTIM period used by hostapd if none is configured, in beacons.
+	defaultDtimPeriod = 2
+)
+
+// readBeaconTiming returns the beacon interval configured on the given device and the DTIM period configured on the
+// team stations, or the values hostapd uses by default if they aren't configured.
+func readBeaconTiming(device string) (int, int) {
+	beaconIntervalTu, dtimPeriod := defaultBeaconIntervalTu, defaultDtimPeriod
+	if value, ok := uciTree.GetLast("wireless", device, "beacon_int"); ok {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			beaconIntervalTu = parsed
+		}
+	}
+	if value, ok := uciTree.GetLast("wireless", wifiIfaceSection(red1), "dtim_period"); ok {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			dtimPeriod = parsed
+		}
+	}
+	return beaconIntervalTu, dtimPeriod
+}
+
+// configureBeaconTiming sets the beacon interval of the device and the DTIM period of every team station, leaving
+// either unchanged if it is zero. The new values take effect once the stations are next reloaded.
+func (radio *Radio) configureBeaconTiming(beaconIntervalTu, dtimPeriod int) {
+	if beaconIntervalTu > 0 {
+		uciTree.SetType("wireless", radio.device, "beacon_int", uci.TypeOption, strconv.Itoa(beaconIntervalTu))
+		radio.BeaconIntervalTu = beaconIntervalTu
+	}
+	if dtimPeriod > 0 {
+		for station := red1; station <= blue3; station++ {
+			uciTree.SetType(
+				"wireless", wifiIfaceSection(station), "dtim_period", uci.TypeOption, strconv.Itoa(dtimPeriod),
+			)
+		}
+		radio.DtimPeriod = dtimPeriod
+	}
+}
@@ -0,0 +1,65 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestReadBeaconTiming(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+
+	// The hostapd defaults apply if nothing is configured.
+	beaconIntervalTu, dtimPeriod := readBeaconTiming("radio0")
+	assert.Equal(t, 100, beaconIntervalTu)
+	assert.Equal(t, 2, dtimPeriod)
+
+	fakeTree.valuesForGet["wireless.radio0.beacon_int"] = "200"
+	fakeTree.valuesForGet["wireless.@wifi-iface[1].dtim_period"] = "1"
+	beaconIntervalTu, dtimPeriod = readBeaconTiming("radio0")
+	assert.Equal(t, 200, beaconIntervalTu)
+	assert.Equal(t, 1, dtimPeriod)
+
+	fakeTree.valuesForGet["wireless.radio0.beacon_int"] = "fast"
+	beaconIntervalTu, _ = readBeaconTiming("radio0")
+	assert.Equal(t, 100, beaconIntervalTu)
+}
+
+func TestRadio_handleConfigurationRequestBeaconTiming(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeTree.valuesForGet["system.@system[0].model"] = "VH-109(AP)"
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	wifiReloadBackoffDuration = 10 * time.Millisecond
+	fakeShell.commandOutput["cat /etc/vh_firmware"] = ""
+	fakeShell.commandOutput["wifi reload wifi1"] = ""
+	for _, iface := range []string{"ath1", "ath11", "ath12", "ath13", "ath14", "ath15"} {
+		fakeShell.commandOutput["iwinfo "+iface+" info"] = iface + "\nESSID: \"no-team-1\"\n"
+	}
+	radio := NewRadio()
+	radio.setInitialState()
+	assert.Equal(t, 100, radio.BeaconIntervalTu)
+	assert.Equal(t, 2, radio.DtimPeriod)
+
+	assert.Nil(t, radio.handleConfigurationRequest(ConfigurationRequest{BeaconIntervalTu: 200, DtimPeriod: 1}))
+	assert.Equal(t, "200", fakeTree.valuesFromSet["wireless.wifi1.beacon_int"])
+	for _, position := range []string{"1", "2", "3", "4", "5", "6"} {
+		assert.Equal(t, "1", fakeTree.valuesFromSet["wireless.@wifi-iface["+position+"].dtim_period"])
+	}
+	assert.Equal(t, 200, radio.BeaconIntervalTu)
+	assert.Equal(t, 1, radio.DtimPeriod)
+
+	// Either value can be changed on its own.
+	fakeTree.valuesFromSet = make(map[string]string)
+	assert.Nil(t, radio.handleConfigurationRequest(ConfigurationRequest{DtimPeriod: 3}))
+	_, ok := fakeTree.valuesFromSet["wireless.wifi1.beacon_int"]
+	assert.False(t, ok)
+	assert.Equal(t, "3", fakeTree.valuesFromSet["wireless.@wifi-iface[1].dtim_period"])
+	assert.Equal(t, 200, radio.BeaconIntervalTu)
+	assert.Equal(t, 3, radio.DtimPeriod)
+}
@@ -0,0 +1,148 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"fmt"
+	"github.com/digineo/go-uci"
+	"io/fs"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// Path to a file on the RAM-backed filesystem that marks the baseline configuration as having been asserted since the
+// radio booted, so that an API restart in the middle of an event doesn't knock the teams off; variable to facilitate
+// testing.
+var bootConfigurationMarkerPath = "/tmp/frc-radio-api-boot-configured"
+
+// baselineNetworks describes the networks of a given hardware type that aren't team stations.
+type baselineNetworks struct {
+	// Name of the Wi-Fi device that the admin network is broadcast on.
+	adminDevice string
+
+	// Name of the network interface that the admin network is bridged to.
+	adminNetwork string
+
+	// Value of the encryption option of each team station network.
+	stationEncryption string
+}
+
+// Table of the networks other than team stations in the baseline configuration of each hardware type.
+var baselineNetworkTable = map[RadioType]baselineNetworks{
+	TypeLinksys:      {adminDevice: "radio1", adminNetwork: "vlan100", stationEncryption: "psk2+ccmp"},
+	TypeVividHosting: {adminDevice: "wifi0", adminNetwork: "lan", stationEncryption: "psk2+ccmp"},
+}
+
+// baselineOption describes an option of the wireless configuration that must have a given value on boot.
+type baselineOption struct {
+	section string
+	option  string
+
+	// Value that the option must have. Blank means that the option is a flag such as "disabled" that must be either
+	// absent or "0", and is removed if it isn't.
+	value string
+}
+
+// expectedBaselineOptions returns the options that the wireless configuration must have for the radio to come up
+// with its admin network and every team station broadcasting its placeholder network.
+func (radio *Radio) expectedBaselineOptions() []baselineOption {
+	networks := baselineNetworkTable[radio.Type]
+	adminSection := "@wifi-iface[0]"
+	options := []baselineOption{
+		{section: radio.device, option: "disabled"},
+		{section: adminSection, option: "device", value: networks.adminDevice},
+		{section: adminSection, option: "mode", value: "ap"},
+		{section: adminSection, option: "network", value: networks.adminNetwork},
+		{section: adminSection, option: "disabled"},
+	}
+	for station := red1; station <= blue3; station++ {
+		section := wifiIfaceSection(station)
+		ssid := radio.placeholderSsid(station)
+		options = append(
+			options,
+			baselineOption{section: section, option: "device", value: radio.device},
+			baselineOption{section: section, option: "mode", value: "ap"},
+			baselineOption{section: section, option: "ssid", value: ssid},
+			baselineOption{section: section, option: "key", value: ssid},
+			baselineOption{section: section, option: "encryption", value: networks.stationEncryption},
+			baselineOption{
+				section: section, option: "network", value: fmt.Sprintf("vlan%d", radio.getStationVlan(station)),
+			},
+		)
+		if radio.driverQuirks().duplicateSaePassword {
+			options = append(options, baselineOption{section: section, option: "sae_password", value: ssid})
+		}
+		if radio.GetSettings().UnassignedStationMode != unassignedStationModeDisabled {
+			options = append(options, baselineOption{section: section, option: "disabled"})
+		}
+	}
+	return options
+}
+
+// stageBaselineFixes sets each option of the wireless configuration that has drifted from the baseline back to its
+// expected value without committing it, returning a description of each fix.
+func (radio *Radio) stageBaselineFixes() []string {
+	fixes := []string{}
+	for _, expected := range radio.expectedBaselineOptions() {
+		current, _ := uciTree.GetLast("wireless", expected.section, expected.option)
+		if current == expected.value || expected.value == "" && current == "0" {
+			continue
+		}
+		if expected.option == "key" || expected.option == "sae_password" {
+			// Leftover WPA keys shouldn't end up in the status.
+			fixes = append(fixes, fmt.Sprintf("%s.%s was reset", expected.section, expected.option))
+		} else {
+			fixes = append(
+				fixes,
+				fmt.Sprintf(
+					"%s.%s was %q instead of %q", expected.section, expected.option, current, expected.value,
+				),
+			)
+		}
+		if expected.value == "" {
+			uciTree.Del("wireless", expected.section, expected.option)
+		} else {
+			uciTree.SetType("wireless", expected.section, expected.option, uci.TypeOption, expected.value)
+		}
+	}
+	return fixes
+}
+
+// assertBootConfiguration puts the wireless configuration back to the baseline the first time the API starts after the
+// radio boots, rather than assuming that the flashed image's configuration is correct, since radios can come back from
+// teams with leftover configuration. It is skipped when the API is merely restarted, so as not to disrupt teams that
+// are already connected.
+func (radio *Radio) assertBootConfiguration() {
+	if _, err := os.Stat(bootConfigurationMarkerPath); err == nil {
+		return
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Error checking for %s: %v", bootConfigurationMarkerPath, err)
+	}
+
+	radio.BootConfigurationFixes = radio.stageBaselineFixes()
+	if len(radio.BootConfigurationFixes) > 0 {
+		radio.raiseAlert(
+			"BOOT_CONFIGURATION_DRIFT",
+			"Wireless configuration had drifted from the baseline at boot and was corrected: %s.",
+			strings.Join(radio.BootConfigurationFixes, "; "),
+		)
+		if err := radio.commitUci("wireless"); err != nil {
+			log.Printf("Error committing baseline wireless configuration: %v", err)
+			uciTree.Revert()
+			return
+		}
+		if _, err := shell.runCommand("wifi", "reload"); err != nil {
+			log.Printf("Error reloading baseline wireless configuration: %v", err)
+			return
+		}
+		radio.markStatusChanged()
+		time.Sleep(radio.reloadBackoffDuration())
+	}
+	if err := os.WriteFile(bootConfigurationMarkerPath, nil, 0600); err != nil {
+		log.Printf("Error writing %s: %v", bootConfigurationMarkerPath, err)
+	}
+}
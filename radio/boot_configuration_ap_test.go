@@ -0,0 +1,87 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setBaselineConfiguration sets every option that the baseline requires of the given radio to its expected value.
+func setBaselineConfiguration(radio *Radio, fakeTree *fakeUciTree) {
+	for _, expected := range radio.expectedBaselineOptions() {
+		fakeTree.valuesForGet["wireless."+expected.section+"."+expected.option] = expected.value
+	}
+}
+
+func TestRadio_assertBootConfiguration(t *testing.T) {
+	radio, fakeTree, fakeShell := newVerificationTestRadio(t)
+	bootConfigurationMarkerPath = filepath.Join(t.TempDir(), "boot-configured")
+	setBaselineConfiguration(radio, fakeTree)
+	assert.Equal(t, "lan", fakeTree.valuesForGet["wireless.@wifi-iface[0].network"])
+	assert.Equal(t, "no-team-2", fakeTree.valuesForGet["wireless.@wifi-iface[2].sae_password"])
+
+	// Nothing is changed if the configuration matches the baseline.
+	radio.assertBootConfiguration()
+	assert.Empty(t, radio.BootConfigurationFixes)
+	assert.Equal(t, 0, fakeTree.setCount)
+	assert.Equal(t, 0, fakeTree.commitCount)
+	assert.Empty(t, radio.GetAlerts())
+	_, err := os.Stat(bootConfigurationMarkerPath)
+	assert.Nil(t, err)
+
+	// Leftover configuration is put back the next time the radio boots.
+	assert.Nil(t, os.Remove(bootConfigurationMarkerPath))
+	fakeTree.valuesForGet["wireless.wifi1.disabled"] = "1"
+	fakeTree.valuesForGet["wireless.@wifi-iface[0].network"] = "vlan10"
+	fakeTree.valuesForGet["wireless.@wifi-iface[2].ssid"] = "254"
+	fakeTree.valuesForGet["wireless.@wifi-iface[2].key"] = "leftover-key"
+	fakeTree.valuesForGet["wireless.@wifi-iface[5].disabled"] = "0"
+	fakeShell.commandOutput["wifi reload"] = ""
+	radio.assertBootConfiguration()
+	assert.Equal(
+		t,
+		[]string{
+			`wifi1.disabled was "1" instead of ""`,
+			`@wifi-iface[0].network was "vlan10" instead of "lan"`,
+			`@wifi-iface[2].ssid was "254" instead of "no-team-2"`,
+			"@wifi-iface[2].key was reset",
+		},
+		radio.BootConfigurationFixes,
+	)
+	assert.Equal(t, "***DELETED***", fakeTree.valuesFromSet["wireless.wifi1.disabled"])
+	assert.Equal(t, "lan", fakeTree.valuesFromSet["wireless.@wifi-iface[0].network"])
+	assert.Equal(t, "no-team-2", fakeTree.valuesFromSet["wireless.@wifi-iface[2].ssid"])
+	assert.Equal(t, "no-team-2", fakeTree.valuesFromSet["wireless.@wifi-iface[2].key"])
+	assert.Equal(t, 4, fakeTree.setCount)
+	assert.Equal(t, 1, fakeTree.commitCount)
+	assert.Contains(t, fakeShell.commandsRun, "wifi reload")
+	if alerts := radio.GetAlerts(); assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "BOOT_CONFIGURATION_DRIFT", alerts[0].Type)
+		assert.NotContains(t, alerts[0].Message, "leftover-key")
+	}
+
+	// The configuration is left alone when the API is restarted without the radio having rebooted.
+	radio.BootConfigurationFixes = nil
+	fakeTree.valuesForGet["wireless.@wifi-iface[3].ssid"] = "1114"
+	radio.assertBootConfiguration()
+	assert.Empty(t, radio.BootConfigurationFixes)
+	assert.Equal(t, 1, fakeTree.commitCount)
+}
+
+func TestRadio_expectedBaselineOptionsDisabledStations(t *testing.T) {
+	radio, _, _ := newVerificationTestRadio(t)
+	settings := radio.GetSettings()
+	settings.UnassignedStationMode = unassignedStationModeDisabled
+	radio.SetSettings(settings)
+
+	// Stations left disabled by the DISABLED mode aren't re-enabled.
+	for _, expected := range radio.expectedBaselineOptions() {
+		if expected.option == "disabled" {
+			assert.NotContains(t, expected.section, "@wifi-iface[1]")
+		}
+	}
+}
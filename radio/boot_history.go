@@ -0,0 +1,274 @@
+package radio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Path to the file in which the boots of the radio are tracked across reboots, so that the cause of each reboot can be
+// worked out once the radio is back up; variable to facilitate testing.
+var bootHistoryFilePath = "/root/frc-radio-api-boot-history.json"
+
+// Paths from which the kernel reports an identifier unique to the current boot and the time since it, and pattern
+// matching the crash records that pstore keeps across a reboot; variables to facilitate testing.
+var (
+	bootIdPath = "/proc/sys/kernel/random/boot_id"
+	uptimePath = "/proc/uptime"
+	pstoreGlob = "/sys/fs/pstore/*"
+)
+
+// Serializes access to the boot history file, which is also written from the signal handler and web server goroutines.
+var bootHistoryMutex sync.Mutex
+
+// Regex matching the lines that some watchdog drivers and bootloaders log at startup when the watchdog reset the radio.
+var watchdogResetRe = regexp.MustCompile(
+	`(?i)(last (reset|reboot)|reset (reason|cause)|boot reason|caused by).*(watchdog|wdt)`,
+)
+
+// rebootCause represents the best-effort explanation of why the radio last rebooted.
+type rebootCause string
+
+const (
+	// A hardware watchdog reset the radio after the system stopped responding.
+	rebootCauseWatchdog rebootCause = "WATCHDOG"
+
+	// The kernel crashed.
+	rebootCauseKernelPanic rebootCause = "KERNEL_PANIC"
+
+	// The radio lost power, or was reset without shutting down cleanly.
+	rebootCausePowerLoss rebootCause = "POWER_LOSS"
+
+	// The radio rebooted to complete a firmware upgrade started through the API.
+	rebootCauseSysupgrade rebootCause = "SYSUPGRADE"
+
+	// The boot watchdog rebooted the radio since it hadn't finished starting up.
+	rebootCauseBootRecovery rebootCause = "BOOT_RECOVERY"
+
+	// The radio shut down cleanly without the API having asked it to (e.g. because someone ran 'reboot').
+	rebootCauseUserCommanded rebootCause = "USER_COMMANDED"
+
+	// There is no record of the previous boot to go by (e.g. the first time the API has run on the radio).
+	rebootCauseUnknown rebootCause = "UNKNOWN"
+)
+
+// BootStatus represents how long the radio has been up and why it last rebooted, for telling problems with the power
+// infrastructure apart from software crashes.
+type BootStatus struct {
+	// Time since the radio booted as of the most recent monitoring poll, in seconds.
+	UptimeSec int `json:"uptimeSec"`
+
+	// Time at which the radio booted.
+	BootedAt time.Time `json:"bootedAt"`
+
+	// Number of boots of the radio that the API has seen, including the current one.
+	BootCount int `json:"bootCount"`
+
+	// Best-effort cause of the reboot that preceded the current boot.
+	RebootCause rebootCause `json:"rebootCause"`
+
+	// Evidence from which the reboot cause was inferred.
+	RebootCauseDetail string `json:"rebootCauseDetail"`
+}
+
+// bootHistoryRecord is the on-disk record of the current boot, carried over into the next one.
+type bootHistoryRecord struct {
+	BootStatus
+
+	// Unique identifier that the kernel assigned to the boot.
+	BootId string `json:"bootId"`
+
+	// Cause that the API recorded just before rebooting the radio itself, if it did.
+	ShutdownIntent rebootCause `json:"shutdownIntent,omitempty"`
+
+	// Whether the API was stopped cleanly during the boot, as happens when the radio shuts down in an orderly way.
+	IsCleanShutdown bool `json:"isCleanShutdown,omitempty"`
+}
+
+// recordBoot works out whether the radio has rebooted since the API last ran and why, reports the result in the
+// status, and publishes it to the event socket if the boot is a new one.
+func (radio *Radio) recordBoot() {
+	bootHistoryMutex.Lock()
+	defer bootHistoryMutex.Unlock()
+	bootIdBytes, err := os.ReadFile(bootIdPath)
+	if err != nil {
+		log.Printf("Error reading boot ID; not tracking reboots: %v", err)
+		return
+	}
+	bootId := strings.TrimSpace(string(bootIdBytes))
+	uptime, err := readUptime()
+	if err != nil {
+		log.Printf("Error reading uptime; not tracking reboots: %v", err)
+		return
+	}
+
+	previous := readBootHistoryFile()
+	record := bootHistoryRecord{BootId: bootId}
+	isNewBoot := previous == nil || previous.BootId != bootId
+	if isNewBoot {
+		record.BootedAt = time.Now().Add(-uptime).Truncate(time.Second)
+		record.RebootCause, record.RebootCauseDetail = inferRebootCause(previous)
+		if previous != nil {
+			record.BootCount = previous.BootCount
+		}
+		record.BootCount++
+	} else {
+		// Only the API restarted; the reboot that preceded the current boot was already worked out.
+		record.BootStatus = previous.BootStatus
+	}
+	if err = saveBootHistoryFile(&record); err != nil {
+		log.Printf("Error saving boot history: %v", err)
+	}
+
+	status := record.BootStatus
+	status.UptimeSec = int(uptime / time.Second)
+	radio.Boot = &status
+	if isNewBoot {
+		log.Printf(
+			"Radio booted at %s after reboot cause %s (%s).",
+			status.BootedAt,
+			status.RebootCause,
+			status.RebootCauseDetail,
+		)
+		radio.publishEvent(eventTypeBoot, status)
+	}
+}
+
+// updateUptime refreshes the uptime reported in the status.
+func (radio *Radio) updateUptime() {
+	if radio.Boot == nil {
+		return
+	}
+	if uptime, err := readUptime(); err == nil {
+		radio.Boot.UptimeSec = int(uptime / time.Second)
+	}
+}
+
+// inferRebootCause returns the most likely cause of the reboot that followed the given boot and the evidence for it,
+// checking the crash records and kernel log that the hardware leaves behind before falling back to what the API
+// recorded as the previous boot ended.
+func inferRebootCause(previous *bootHistoryRecord) (rebootCause, string) {
+	var cause rebootCause
+	var detail string
+	paths, _ := filepath.Glob(pstoreGlob)
+	sort.Strings(paths)
+	for _, path := range paths {
+		// Console records are kept for every boot, whereas dmesg records are only written when the kernel crashes.
+		name := filepath.Base(path)
+		if !strings.HasPrefix(name, "dmesg-") {
+			continue
+		}
+		if cause == "" {
+			contents, _ := os.ReadFile(path)
+			if strings.Contains(strings.ToLower(string(contents)), "watchdog") {
+				cause, detail = rebootCauseWatchdog, fmt.Sprintf("pstore crash record %s mentions the watchdog", name)
+			} else {
+				cause, detail = rebootCauseKernelPanic, fmt.Sprintf("pstore crash record %s", name)
+			}
+		}
+
+		// Crash records are kept until they are removed, so they mustn't be counted again after the next reboot.
+		if err := os.Remove(path); err != nil {
+			log.Printf("Error removing %s: %v", path, err)
+		}
+	}
+	if cause != "" {
+		return cause, detail
+	}
+
+	if kernelLog, err := shell.runCommand("dmesg"); err == nil {
+		if line := watchdogResetRe.FindString(kernelLog); line != "" {
+			return rebootCauseWatchdog, fmt.Sprintf("kernel log: %s", strings.TrimSpace(line))
+		}
+	}
+
+	switch {
+	case previous == nil:
+		return rebootCauseUnknown, "no record of the previous boot"
+	case previous.ShutdownIntent != "":
+		return previous.ShutdownIntent, "recorded by the API before rebooting"
+	case previous.IsCleanShutdown:
+		return rebootCauseUserCommanded, "the API was stopped cleanly before the reboot"
+	default:
+		return rebootCausePowerLoss, "the previous boot ended without a clean shutdown"
+	}
+}
+
+// recordShutdownIntent notes that the API is about to reboot the radio for the given reason, so that the reboot isn't
+// mistaken for a power loss once the radio is back up.
+func recordShutdownIntent(cause rebootCause) {
+	bootHistoryMutex.Lock()
+	defer bootHistoryMutex.Unlock()
+	if record := readBootHistoryFile(); record != nil {
+		record.ShutdownIntent = cause
+		if err := saveBootHistoryFile(record); err != nil {
+			log.Printf("Error saving boot history: %v", err)
+		}
+	}
+}
+
+// RecordCleanShutdown notes that the API is being stopped in an orderly way, which it is when the radio is shut down
+// or rebooted without losing power.
+func RecordCleanShutdown() {
+	bootHistoryMutex.Lock()
+	defer bootHistoryMutex.Unlock()
+	if record := readBootHistoryFile(); record != nil {
+		record.IsCleanShutdown = true
+		if err := saveBootHistoryFile(record); err != nil {
+			log.Printf("Error saving boot history: %v", err)
+		}
+	}
+}
+
+// readUptime returns the time since the radio booted, as reported by the kernel.
+func readUptime() (time.Duration, error) {
+	contents, err := os.ReadFile(uptimePath)
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(contents))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("invalid uptime: %q", string(contents))
+	}
+	seconds, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid uptime: %q", fields[0])
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// readBootHistoryFile returns the record of the most recent boot that the API has seen, or nil if there is none.
+func readBootHistoryFile() *bootHistoryRecord {
+	recordJson, err := os.ReadFile(bootHistoryFilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	var record bootHistoryRecord
+	if err == nil {
+		err = json.Unmarshal(recordJson, &record)
+	}
+	if err != nil {
+		log.Printf("Error reading boot history; starting over: %v", err)
+		return nil
+	}
+	return &record
+}
+
+// saveBootHistoryFile records the given boot so that it survives a reboot.
+func saveBootHistoryFile(record *bootHistoryRecord) error {
+	recordJson, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(bootHistoryFilePath, recordJson, 0600)
+}
@@ -0,0 +1,131 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// setUpBootHistory points the boot history at temporary files, with the kernel reporting the given boot ID and an
+// uptime of an hour.
+func setUpBootHistory(t *testing.T, bootId string) string {
+	directory := t.TempDir()
+	originalPaths := []string{bootHistoryFilePath, bootIdPath, uptimePath, pstoreGlob}
+	t.Cleanup(func() {
+		bootHistoryFilePath, bootIdPath, uptimePath, pstoreGlob =
+			originalPaths[0], originalPaths[1], originalPaths[2], originalPaths[3]
+	})
+	bootHistoryFilePath = filepath.Join(directory, "boot-history.json")
+	bootIdPath = filepath.Join(directory, "boot_id")
+	uptimePath = filepath.Join(directory, "uptime")
+	assert.Nil(t, os.Mkdir(filepath.Join(directory, "pstore"), 0755))
+	pstoreGlob = filepath.Join(directory, "pstore", "*")
+	assert.Nil(t, os.WriteFile(bootIdPath, []byte(bootId+"\n"), 0644))
+	assert.Nil(t, os.WriteFile(uptimePath, []byte("3600.25 7000.50\n"), 0644))
+	return directory
+}
+
+// reboot simulates the radio rebooting and the API starting up again, returning the resulting boot status.
+func reboot(t *testing.T, bootId string) *BootStatus {
+	assert.Nil(t, os.WriteFile(bootIdPath, []byte(bootId+"\n"), 0644))
+	radio := &Radio{settings: defaultSettings()}
+	radio.recordBoot()
+	return radio.Boot
+}
+
+func TestRadio_recordBoot(t *testing.T) {
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["dmesg"] = "[    0.000000] Booting Linux on physical CPU 0x0\n"
+	directory := setUpBootHistory(t, "boot-1")
+
+	// Nothing is known about the reboot that preceded the first boot seen.
+	boot := reboot(t, "boot-1")
+	if assert.NotNil(t, boot) {
+		assert.Equal(t, 3600, boot.UptimeSec)
+		assert.WithinDuration(t, time.Now().Add(-time.Hour), boot.BootedAt, 2*time.Second)
+		assert.Equal(t, 1, boot.BootCount)
+		assert.Equal(t, rebootCauseUnknown, boot.RebootCause)
+	}
+
+	// Restarting the API alone doesn't count as a boot.
+	restartedBoot := reboot(t, "boot-1")
+	assert.Equal(t, 1, restartedBoot.BootCount)
+	assert.True(t, boot.BootedAt.Equal(restartedBoot.BootedAt))
+	assert.Equal(t, rebootCauseUnknown, restartedBoot.RebootCause)
+
+	// A boot that ended without the API being stopped followed a power loss.
+	boot = reboot(t, "boot-2")
+	assert.Equal(t, 2, boot.BootCount)
+	assert.Equal(t, rebootCausePowerLoss, boot.RebootCause)
+
+	// An orderly shutdown stops the API first.
+	RecordCleanShutdown()
+	boot = reboot(t, "boot-3")
+	assert.Equal(t, rebootCauseUserCommanded, boot.RebootCause)
+
+	// The API records its own reboots before rebooting.
+	RecordCleanShutdown()
+	recordShutdownIntent(rebootCauseSysupgrade)
+	boot = reboot(t, "boot-4")
+	assert.Equal(t, rebootCauseSysupgrade, boot.RebootCause)
+	assert.Equal(t, "recorded by the API before rebooting", boot.RebootCauseDetail)
+
+	// The kernel log names a watchdog reset.
+	fakeShell.commandOutput["dmesg"] = "[    0.000000] Booting Linux on physical CPU 0x0\n" +
+		"[    0.512345] qcom_wdt 2000.watchdog: Last reset was caused by watchdog\n" +
+		"[    0.600000] NET: Registered protocol family 16\n"
+	boot = reboot(t, "boot-5")
+	assert.Equal(t, rebootCauseWatchdog, boot.RebootCause)
+	assert.Equal(t, "kernel log: Last reset was caused by watchdog", boot.RebootCauseDetail)
+	fakeShell.commandOutput["dmesg"] = ""
+
+	// Crash records left in pstore take precedence and are only counted once.
+	setPstoreRecord := func(name, contents string) {
+		assert.Nil(t, os.WriteFile(filepath.Join(directory, "pstore", name), []byte(contents), 0644))
+	}
+	setPstoreRecord("console-ramoops-0", "reboot: Restarting system\n")
+	setPstoreRecord("dmesg-ramoops-0", "Kernel panic - not syncing: Fatal exception\n")
+	RecordCleanShutdown()
+	boot = reboot(t, "boot-6")
+	assert.Equal(t, rebootCauseKernelPanic, boot.RebootCause)
+	assert.Equal(t, "pstore crash record dmesg-ramoops-0", boot.RebootCauseDetail)
+	assert.NoFileExists(t, filepath.Join(directory, "pstore", "dmesg-ramoops-0"))
+	assert.FileExists(t, filepath.Join(directory, "pstore", "console-ramoops-0"))
+	setPstoreRecord("dmesg-ramoops-0", "Kernel panic - not syncing: Software Watchdog Timer expired\n")
+	boot = reboot(t, "boot-7")
+	assert.Equal(t, rebootCauseWatchdog, boot.RebootCause)
+	boot = reboot(t, "boot-8")
+	assert.Equal(t, rebootCausePowerLoss, boot.RebootCause)
+	assert.Equal(t, 8, boot.BootCount)
+
+	// The uptime is refreshed at each monitoring poll.
+	radio := &Radio{settings: defaultSettings()}
+	radio.updateUptime()
+	assert.Nil(t, radio.Boot)
+	radio.recordBoot()
+	assert.Nil(t, os.WriteFile(uptimePath, []byte("3700.99 7100.00\n"), 0644))
+	radio.updateUptime()
+	assert.Equal(t, 3700, radio.Boot.UptimeSec)
+}
+
+func TestRadio_recordBootErrors(t *testing.T) {
+	setUpBootHistory(t, "boot-1")
+
+	// An unreadable or corrupt history starts over.
+	assert.Nil(t, os.WriteFile(bootHistoryFilePath, []byte("not JSON"), 0644))
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["dmesg"] = ""
+	boot := reboot(t, "boot-1")
+	assert.Equal(t, 1, boot.BootCount)
+	assert.Equal(t, rebootCauseUnknown, boot.RebootCause)
+
+	// Nothing is reported if the kernel doesn't report the uptime.
+	assert.Nil(t, os.WriteFile(uptimePath, []byte("garbage"), 0644))
+	assert.Nil(t, reboot(t, "boot-2"))
+	assert.Nil(t, os.Remove(uptimePath))
+	assert.Nil(t, reboot(t, "boot-2"))
+}
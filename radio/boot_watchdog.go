@@ -0,0 +1,169 @@
+package radio
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"time"
+)
+
+// How long to give the radio to start up after each recovery step before moving on to the next one.
+const bootRecoveryStepWaitSec = 60
+
+// Path to the file in which the progress of the boot recovery is kept across the reboot that is its last step, so that
+// the radio is only ever rebooted once; variable to facilitate testing.
+var bootRecoveryFilePath = "/root/frc-radio-api-boot-recovery.json"
+
+// bootRecoveryStep represents an action taken to get a radio that hasn't finished starting up going again.
+type bootRecoveryStep string
+
+const (
+	// Reload the Wi-Fi configuration of all devices.
+	bootRecoveryStepWifiReload bootRecoveryStep = "WIFI_RELOAD"
+
+	// Restart the network service, which also brings the Wi-Fi devices back up.
+	bootRecoveryStepNetworkRestart bootRecoveryStep = "NETWORK_RESTART"
+
+	// Reboot the radio; only attempted once until the radio next starts up successfully.
+	bootRecoveryStepReboot bootRecoveryStep = "REBOOT"
+)
+
+// Recovery steps in the order in which they are attempted.
+var bootRecoverySteps = []bootRecoveryStep{
+	bootRecoveryStepWifiReload, bootRecoveryStepNetworkRestart, bootRecoveryStepReboot,
+}
+
+// BootRecoveryStatus represents the progress of the boot watchdog's attempts to recover a radio that didn't finish
+// starting up within the configured time.
+type BootRecoveryStatus struct {
+	// Recovery steps attempted so far, in order.
+	StepsAttempted []bootRecoveryStep `json:"stepsAttempted"`
+
+	// Time at which the most recent step was attempted.
+	LastAttemptedAt Timestamp `json:"lastAttemptedAt"`
+
+	// Step after which the radio finished starting up. Blank if it hasn't yet.
+	SucceededStep bootRecoveryStep `json:"succeededStep"`
+
+	// Whether every step has been attempted without the radio starting up, leaving it waiting indefinitely.
+	IsExhausted bool `json:"isExhausted"`
+}
+
+// waitForStartup blocks until the radio has finished starting up. If the boot watchdog is enabled and the radio hasn't
+// started within its timeout, recovery steps are attempted one at a time until it does.
+func (radio *Radio) waitForStartup() {
+	radio.BootRecovery = readBootRecoveryFile()
+	waitingSince := time.Now()
+	for !radio.isStarted() {
+		log.Println("Waiting for radio to finish starting up...")
+		radio.checkBootWatchdog(waitingSince, time.Now())
+		time.Sleep(bootPollIntervalSec * time.Second)
+	}
+	radio.finishBootRecovery()
+}
+
+// checkBootWatchdog attempts the next recovery step if the radio has been waiting to start up since the given time for
+// longer than the boot watchdog timeout, or for longer than the step wait since the previous step.
+func (radio *Radio) checkBootWatchdog(waitingSince, now time.Time) {
+	timeoutMin := radio.GetSettings().BootWatchdogTimeoutMin
+	if timeoutMin == 0 || now.Sub(waitingSince) < time.Duration(timeoutMin)*time.Minute {
+		return
+	}
+	if radio.BootRecovery == nil {
+		radio.BootRecovery = &BootRecoveryStatus{StepsAttempted: []bootRecoveryStep{}}
+	}
+	recovery := radio.BootRecovery
+	if recovery.IsExhausted {
+		return
+	}
+	if len(recovery.StepsAttempted) == len(bootRecoverySteps) {
+		recovery.IsExhausted = true
+		radio.raiseAlert(
+			"BOOT_RECOVERY",
+			"Radio still hasn't started up after all recovery steps; waiting for manual intervention.",
+		)
+		radio.markStatusChanged()
+		return
+	}
+	if len(recovery.StepsAttempted) > 0 &&
+		now.Sub(recovery.LastAttemptedAt.Wallclock) < bootRecoveryStepWaitSec*time.Second {
+		return
+	}
+
+	step := bootRecoverySteps[len(recovery.StepsAttempted)]
+	recovery.StepsAttempted = append(recovery.StepsAttempted, step)
+	recovery.LastAttemptedAt = newTimestamp()
+	radio.raiseAlert(
+		"BOOT_RECOVERY",
+		"Radio hasn't started up after %d minutes; attempting recovery step %s.",
+		int(now.Sub(waitingSince).Minutes()),
+		step,
+	)
+	radio.markStatusChanged()
+
+	var err error
+	switch step {
+	case bootRecoveryStepWifiReload:
+		_, err = shell.runCommand("wifi", "reload")
+	case bootRecoveryStepNetworkRestart:
+		_, err = shell.runCommand("/etc/init.d/network", "restart")
+	case bootRecoveryStepReboot:
+		// Record the attempt first so that the radio doesn't reboot again if it still fails to start afterwards.
+		if err = saveBootRecoveryFile(recovery); err == nil {
+			recordShutdownIntent(rebootCauseBootRecovery)
+			_, err = shell.runCommand("reboot")
+		}
+	}
+	if err != nil {
+		log.Printf("Error attempting boot recovery step %s: %v", step, err)
+	}
+}
+
+// finishBootRecovery records the step after which the radio started up, if any were attempted, and discards the
+// progress kept across reboots.
+func (radio *Radio) finishBootRecovery() {
+	recovery := radio.BootRecovery
+	if recovery == nil || len(recovery.StepsAttempted) == 0 {
+		removeBootRecoveryFile()
+		return
+	}
+	recovery.SucceededStep = recovery.StepsAttempted[len(recovery.StepsAttempted)-1]
+	recovery.IsExhausted = false
+	radio.raiseAlert("BOOT_RECOVERY", "Radio started up after recovery step %s.", recovery.SucceededStep)
+	removeBootRecoveryFile()
+}
+
+// readBootRecoveryFile returns the boot recovery progress kept across the previous reboot, or nil if there is none.
+func readBootRecoveryFile() *BootRecoveryStatus {
+	recoveryJson, err := os.ReadFile(bootRecoveryFilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	var recovery BootRecoveryStatus
+	if err == nil {
+		err = json.Unmarshal(recoveryJson, &recovery)
+	}
+	if err != nil {
+		log.Printf("Error reading boot recovery progress; starting over: %v", err)
+		return nil
+	}
+	return &recovery
+}
+
+// saveBootRecoveryFile records the given boot recovery progress so that it survives a reboot.
+func saveBootRecoveryFile(recovery *BootRecoveryStatus) error {
+	recoveryJson, err := json.Marshal(recovery)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(bootRecoveryFilePath, recoveryJson, 0600)
+}
+
+// removeBootRecoveryFile discards the boot recovery progress recorded by saveBootRecoveryFile.
+func removeBootRecoveryFile() {
+	if err := os.Remove(bootRecoveryFilePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Error removing %s: %v", bootRecoveryFilePath, err)
+	}
+}
@@ -0,0 +1,101 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRadio_checkBootWatchdog(t *testing.T) {
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	bootRecoveryFilePath = filepath.Join(t.TempDir(), "boot-recovery.json")
+	setUpBootHistory(t, "boot-1")
+	assert.Nil(t, saveBootHistoryFile(&bootHistoryRecord{BootId: "boot-1"}))
+	radio := &Radio{settings: defaultSettings()}
+	waitingSince := time.Now().Add(-time.Hour)
+
+	// Nothing is attempted within the timeout.
+	radio.checkBootWatchdog(time.Now(), time.Now().Add(4*time.Minute))
+	assert.Nil(t, radio.BootRecovery)
+	assert.Empty(t, fakeShell.commandsRun)
+
+	fakeShell.commandOutput["wifi reload"] = ""
+	radio.checkBootWatchdog(waitingSince, time.Now())
+	assert.Equal(t, []bootRecoveryStep{bootRecoveryStepWifiReload}, radio.BootRecovery.StepsAttempted)
+	if alerts := radio.GetAlerts(); assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "BOOT_RECOVERY", alerts[0].Type)
+		assert.Equal(
+			t, "Radio hasn't started up after 60 minutes; attempting recovery step WIFI_RELOAD.", alerts[0].Message,
+		)
+	}
+
+	// The next step waits until the previous one has had time to take effect.
+	radio.checkBootWatchdog(waitingSince, time.Now())
+	assert.Equal(t, 1, len(radio.BootRecovery.StepsAttempted))
+	fakeShell.commandOutput["/etc/init.d/network restart"] = ""
+	radio.checkBootWatchdog(waitingSince, time.Now().Add(bootRecoveryStepWaitSec*time.Second))
+	assert.Equal(t, bootRecoveryStepNetworkRestart, radio.BootRecovery.StepsAttempted[1])
+
+	// The reboot is recorded on disk before it is attempted.
+	fakeShell.commandOutput["reboot"] = ""
+	radio.checkBootWatchdog(waitingSince, time.Now().Add(bootRecoveryStepWaitSec*time.Second))
+	assert.Equal(t, bootRecoveryStepReboot, radio.BootRecovery.StepsAttempted[2])
+	assert.Equal(t, 3, len(fakeShell.commandsRun))
+	recovery := readBootRecoveryFile()
+	if assert.NotNil(t, recovery) {
+		assert.Equal(t, bootRecoverySteps, recovery.StepsAttempted)
+	}
+	if record := readBootHistoryFile(); assert.NotNil(t, record) {
+		assert.Equal(t, rebootCauseBootRecovery, record.ShutdownIntent)
+	}
+
+	// After coming back from the reboot, the radio isn't rebooted again.
+	radio = &Radio{settings: defaultSettings(), BootRecovery: recovery}
+	radio.checkBootWatchdog(waitingSince, time.Now())
+	assert.True(t, radio.BootRecovery.IsExhausted)
+	radio.checkBootWatchdog(waitingSince, time.Now().Add(time.Hour))
+	assert.Equal(t, 3, len(fakeShell.commandsRun))
+	if alerts := radio.GetAlerts(); assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(
+			t,
+			"Radio still hasn't started up after all recovery steps; waiting for manual intervention.",
+			alerts[0].Message,
+		)
+	}
+
+	// A zero timeout disables the watchdog.
+	radio = &Radio{settings: defaultSettings()}
+	radio.settings.BootWatchdogTimeoutMin = 0
+	radio.checkBootWatchdog(waitingSince, time.Now())
+	assert.Nil(t, radio.BootRecovery)
+}
+
+func TestRadio_finishBootRecovery(t *testing.T) {
+	bootRecoveryFilePath = filepath.Join(t.TempDir(), "boot-recovery.json")
+
+	// Nothing is recorded if no recovery was needed.
+	radio := &Radio{}
+	radio.finishBootRecovery()
+	assert.Nil(t, radio.BootRecovery)
+	assert.Empty(t, radio.GetAlerts())
+
+	recovery := &BootRecoveryStatus{StepsAttempted: bootRecoverySteps, IsExhausted: true}
+	assert.Nil(t, saveBootRecoveryFile(recovery))
+	radio.BootRecovery = readBootRecoveryFile()
+	radio.finishBootRecovery()
+	assert.Equal(t, bootRecoveryStepReboot, radio.BootRecovery.SucceededStep)
+	assert.False(t, radio.BootRecovery.IsExhausted)
+	if alerts := radio.GetAlerts(); assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "Radio started up after recovery step REBOOT.", alerts[0].Message)
+	}
+	_, err := os.Stat(bootRecoveryFilePath)
+	assert.True(t, os.IsNotExist(err))
+	assert.Nil(t, readBootRecoveryFile())
+
+	// An unreadable file is discarded.
+	assert.Nil(t, os.WriteFile(bootRecoveryFilePath, []byte("{"), 0600))
+	assert.Nil(t, readBootRecoveryFile())
+}
@@ -0,0 +1,102 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+// hardwareCapabilities describes the optional features supported by a given hardware type.
+type hardwareCapabilities struct {
+	// Whether the radio supports configuring 802.11ax-specific options (BSS color, HE guard interval, and target wake
+	// time).
+	supportsHeOptions bool
+
+	// Whether the radio's driver supports 802.11w protected management frames.
+	supportsManagementFrameProtection bool
+
+	// Whether the radio supports configuring the beacon interval and DTIM period.
+	supportsBeaconTiming bool
+}
+
+// Table of the optional features supported by each hardware type.
+var hardwareCapabilityTable = map[RadioType]hardwareCapabilities{
+	TypeLinksys: {supportsHeOptions: false, supportsManagementFrameProtection: false, supportsBeaconTiming: true},
+	TypeVividHosting: {
+		supportsHeOptions: true, supportsManagementFrameProtection: true, supportsBeaconTiming: true,
+	},
+}
+
+// Valid values for the 802.11ax guard interval.
+var validHeGuardIntervals = []string{"0.8us", "1.6us", "3.2us"}
+
+// Capabilities describes which configuration values the access point supports given its hardware type and active
+// regulatory domain.
+type Capabilities struct {
+	// Hardware type of the radio.
+	Type string `json:"type"`
+
+	// ISO 3166-1 alpha-2 code of the active regulatory domain. Blank if not set.
+	Country string `json:"country"`
+
+	// Country codes that may be set via the configuration endpoint.
+	SupportedCountries []string `json:"supportedCountries"`
+
+	// Channels that may be set via the configuration endpoint under the active regulatory domain.
+	Channels []int `json:"channels"`
+
+	// Channel bandwidths that may be set via the configuration endpoint. Empty if the bandwidth can't be changed.
+	ChannelBandwidths []string `json:"channelBandwidths"`
+
+	// Whether the 802.11ax BSS color and target wake time options may be set via the configuration endpoint.
+	SupportsHeOptions bool `json:"supportsHeOptions"`
+
+	// 802.11ax guard intervals that may be set via the configuration endpoint. Empty if not supported.
+	HeGuardIntervals []string `json:"heGuardIntervals"`
+
+	// 802.11w management frame protection modes that may be set via the configuration endpoint. Empty if not
+	// supported.
+	ManagementFrameProtectionModes []string `json:"managementFrameProtectionModes"`
+
+	// Whether the beacon interval and DTIM period may be set via the configuration endpoint.
+	SupportsBeaconTiming bool `json:"supportsBeaconTiming"`
+}
+
+// GetCapabilities returns the configuration capabilities of the access point.
+func (radio *Radio) GetCapabilities() Capabilities {
+	capabilities := Capabilities{
+		Type:               radio.Type.String(),
+		Country:            radio.Country,
+		SupportedCountries: supportedCountries(),
+		Channels:           []int{},
+		ChannelBandwidths:  []string{},
+		HeGuardIntervals:   []string{},
+
+		ManagementFrameProtectionModes: []string{},
+	}
+	if hardwareCapabilityTable[radio.Type].supportsHeOptions {
+		capabilities.SupportsHeOptions = true
+		capabilities.HeGuardIntervals = validHeGuardIntervals
+	}
+	if hardwareCapabilityTable[radio.Type].supportsManagementFrameProtection {
+		capabilities.ManagementFrameProtectionModes = validManagementFrameProtectionModes
+	}
+	capabilities.SupportsBeaconTiming = hardwareCapabilityTable[radio.Type].supportsBeaconTiming
+
+	var candidateChannels []int
+	switch radio.Type {
+	case TypeLinksys:
+		candidateChannels = validLinksysChannels
+	case TypeVividHosting:
+		for channel := 1; channel <= 233; channel++ {
+			if isValid6GhzChannel(channel) {
+				candidateChannels = append(candidateChannels, channel)
+			}
+		}
+		capabilities.ChannelBandwidths = []string{"20MHz", "40MHz"}
+	}
+	for _, channel := range candidateChannels {
+		if isChannelPermitted(radio.Country, radio.Type, channel) {
+			capabilities.Channels = append(capabilities.Channels, channel)
+		}
+	}
+
+	return capabilities
+}
@@ -0,0 +1,44 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRadio_GetCapabilities(t *testing.T) {
+	// Linksys without a regulatory domain set.
+	radio := Radio{Type: TypeLinksys}
+	capabilities := radio.GetCapabilities()
+	assert.Equal(t, "TypeLinksys", capabilities.Type)
+	assert.Equal(t, "", capabilities.Country)
+	assert.Equal(t, supportedCountries(), capabilities.SupportedCountries)
+	assert.Equal(t, []int{36, 40, 44, 48, 149, 153, 157, 161, 165}, capabilities.Channels)
+	assert.Equal(t, []string{}, capabilities.ChannelBandwidths)
+	assert.False(t, capabilities.SupportsHeOptions)
+	assert.Equal(t, []string{}, capabilities.HeGuardIntervals)
+	assert.Equal(t, []string{}, capabilities.ManagementFrameProtectionModes)
+	assert.True(t, capabilities.SupportsBeaconTiming)
+
+	// Linksys with a restrictive regulatory domain.
+	radio.Country = "GB"
+	capabilities = radio.GetCapabilities()
+	assert.Equal(t, "GB", capabilities.Country)
+	assert.Equal(t, []int{36, 40, 44, 48}, capabilities.Channels)
+
+	// Vivid-Hosting with the lower 6GHz band only.
+	radio = Radio{Type: TypeVividHosting, Country: "DE"}
+	capabilities = radio.GetCapabilities()
+	assert.Equal(t, []int{5, 13, 21, 29, 37, 45, 53, 61, 69, 77, 85, 93}, capabilities.Channels)
+	assert.Equal(t, []string{"20MHz", "40MHz"}, capabilities.ChannelBandwidths)
+	assert.True(t, capabilities.SupportsHeOptions)
+	assert.Equal(t, []string{"0.8us", "1.6us", "3.2us"}, capabilities.HeGuardIntervals)
+	assert.Equal(t, []string{"REQUIRED", "OPTIONAL", "DISABLED"}, capabilities.ManagementFrameProtectionModes)
+
+	// Vivid-Hosting where 6GHz isn't permitted.
+	radio.Country = "CN"
+	capabilities = radio.GetCapabilities()
+	assert.Equal(t, []int{}, capabilities.Channels)
+}
@@ -0,0 +1,143 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"fmt"
+	"github.com/digineo/go-uci"
+	"log"
+	"strconv"
+	"time"
+)
+
+// Number of beacons to announce a channel switch in before it takes effect, giving clients time to follow.
+const channelSwitchBeaconCount = 5
+
+// ChannelFailoverStatus represents the state of the policy for switching away from a channel suffering sustained
+// interference.
+type ChannelFailoverStatus struct {
+	// Whether any backup channels are configured in the settings.
+	IsEnabled bool `json:"isEnabled"`
+
+	// Number of consecutive polls in which the current channel has exceeded the interference thresholds.
+	ConsecutiveBadPolls int `json:"consecutiveBadPolls"`
+
+	// Number of times the radio has failed over to a backup channel since the API started.
+	FailoverCount int `json:"failoverCount"`
+
+	// Time at which the radio last failed over to a backup channel. Zero if it never has.
+	LastFailoverAt time.Time `json:"lastFailoverAt"`
+}
+
+// checkChannelFailover samples the interference on the current channel and, if it has exceeded the thresholds for too
+// many consecutive polls while no match is in progress, switches to a backup channel without disconnecting clients.
+func (radio *Radio) checkChannelFailover() {
+	settings := radio.GetSettings().ChannelFailover
+	status := &radio.ChannelFailover
+	status.IsEnabled = len(settings.BackupChannels) > 0
+	if !status.IsEnabled {
+		status.ConsecutiveBadPolls = 0
+		return
+	}
+
+	surveyInterface := radio.stationInterfaces[blue3]
+	output, err := shell.runCommand("iw", "dev", surveyInterface, "survey", "dump")
+	if err != nil {
+		log.Printf("Error running 'iw dev %s survey dump': %v", surveyInterface, err)
+		return
+	}
+	sample, ok := parseSurveyDump(output)[radio.Channel]
+	if !ok {
+		return
+	}
+	if sample.busyPercent < settings.BusyPercentThreshold && sample.noiseDbm <= settings.NoiseThresholdDbm {
+		status.ConsecutiveBadPolls = 0
+		return
+	}
+	status.ConsecutiveBadPolls++
+	if status.ConsecutiveBadPolls < settings.ConsecutivePolls || radio.isMatchLockHeld() || radio.isInMaintenance() {
+		// If a match or maintenance is in progress, the failover happens as soon as it is over.
+		return
+	}
+
+	previousChannel := radio.Channel
+	backupChannel, ok := radio.nextBackupChannel()
+	if !ok {
+		log.Printf("Channel %d has sustained interference but no usable backup channel is configured.", radio.Channel)
+		return
+	}
+	if err = radio.switchChannel(backupChannel); err != nil {
+		log.Printf("Error switching to backup channel %d: %v", backupChannel, err)
+		return
+	}
+	status.ConsecutiveBadPolls = 0
+	status.FailoverCount++
+	status.LastFailoverAt = time.Now()
+	radio.raiseAlert(
+		"CHANNEL_FAILOVER",
+		"Switched from channel %d to backup channel %d after %d polls of interference (%.1f%% busy, noise %d dBm).",
+		previousChannel,
+		backupChannel,
+		settings.ConsecutivePolls,
+		sample.busyPercent,
+		sample.noiseDbm,
+	)
+}
+
+// nextBackupChannel returns the first usable backup channel following the current one in the configured order,
+// wrapping around, or false if there is none.
+func (radio *Radio) nextBackupChannel() (int, bool) {
+	backupChannels := radio.GetSettings().ChannelFailover.BackupChannels
+	start := 0
+	for i, channel := range backupChannels {
+		if channel == radio.Channel {
+			start = i + 1
+			break
+		}
+	}
+	for i := range backupChannels {
+		channel := backupChannels[(start+i)%len(backupChannels)]
+		if channel == radio.Channel {
+			continue
+		}
+		request := ConfigurationRequest{Channel: channel, OverrideChannelGuard: true}
+		if err := request.Validate(radio); err != nil {
+			log.Printf("Skipping backup channel %d: %v", channel, err)
+			continue
+		}
+		return channel, true
+	}
+	return 0, false
+}
+
+// switchChannel moves the radio to the given channel using a channel switch announcement, which lets associated
+// clients follow without reconnecting, and persists the new channel in the configuration.
+func (radio *Radio) switchChannel(channel int) error {
+	frequency, mode := 5000+5*channel, "ht"
+	if radio.Type == TypeVividHosting {
+		frequency, mode = 5950+5*channel, "he"
+	}
+	wifiInterface := radio.stationInterfaces[red1]
+	_, err := shell.runCommand(
+		"hostapd_cli",
+		"-i",
+		wifiInterface,
+		"chan_switch",
+		strconv.Itoa(channelSwitchBeaconCount),
+		strconv.Itoa(frequency),
+		mode,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to announce channel switch on %s: %v", wifiInterface, err)
+	}
+
+	uciTree.SetType("wireless", radio.device, "channel", uci.TypeOption, strconv.Itoa(channel))
+	if err = radio.commitUci("wireless"); err != nil {
+		return fmt.Errorf("failed to commit wireless configuration: %v", err)
+	}
+	radio.regulatoryMutex.Lock()
+	radio.Channel = channel
+	radio.regulatoryMutex.Unlock()
+	return nil
+}
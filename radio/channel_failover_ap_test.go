@@ -0,0 +1,183 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const testNoisySurveyDump = "Survey data from wlan0-5\n" +
+	"\tfrequency:\t\t\t5180 MHz [in use]\n" +
+	"\tnoise:\t\t\t\t-60 dBm\n" +
+	"\tchannel active time:\t\t1000 ms\n" +
+	"\tchannel busy time:\t\t500 ms\n"
+
+func newFailoverTestRadio(t *testing.T) (*Radio, *fakeShell, *fakeUciTree) {
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+	radio.Channel = 36
+	settings := radio.GetSettings()
+	settings.ChannelFailover.ConsecutivePolls = 2
+	radio.SetSettings(settings)
+	fakeShell.reset()
+	return radio, fakeShell, fakeTree
+}
+
+func TestRadio_checkChannelFailoverDisabled(t *testing.T) {
+	radio, fakeShell, _ := newFailoverTestRadio(t)
+
+	radio.checkChannelFailover()
+	assert.False(t, radio.ChannelFailover.IsEnabled)
+	assert.Equal(t, 0, len(fakeShell.commandsRun))
+}
+
+func TestRadio_checkChannelFailover(t *testing.T) {
+	radio, fakeShell, fakeTree := newFailoverTestRadio(t)
+	settings := radio.GetSettings()
+	settings.ChannelFailover.BackupChannels = []int{149, 157}
+	radio.SetSettings(settings)
+
+	// Quiet channel.
+	fakeShell.commandOutput["iw dev wlan0-5 survey dump"] = testSurveyDump
+	radio.checkChannelFailover()
+	assert.True(t, radio.ChannelFailover.IsEnabled)
+	assert.Equal(t, 0, radio.ChannelFailover.ConsecutiveBadPolls)
+
+	// Interference must be sustained for the configured number of polls.
+	fakeShell.commandOutput["iw dev wlan0-5 survey dump"] = testNoisySurveyDump
+	radio.checkChannelFailover()
+	assert.Equal(t, 1, radio.ChannelFailover.ConsecutiveBadPolls)
+	assert.Equal(t, 36, radio.Channel)
+
+	// A quiet poll resets the count.
+	fakeShell.commandOutput["iw dev wlan0-5 survey dump"] = testSurveyDump
+	radio.checkChannelFailover()
+	assert.Equal(t, 0, radio.ChannelFailover.ConsecutiveBadPolls)
+
+	// Sustained interference triggers a switch to the first backup channel.
+	fakeShell.commandOutput["iw dev wlan0-5 survey dump"] = testNoisySurveyDump
+	fakeShell.commandOutput["hostapd_cli -i wlan0 chan_switch 5 5745 ht"] = "OK"
+	radio.checkChannelFailover()
+	radio.checkChannelFailover()
+	assert.Contains(t, fakeShell.commandsRun, "hostapd_cli -i wlan0 chan_switch 5 5745 ht")
+	assert.Equal(t, 149, radio.Channel)
+	assert.Equal(t, "149", fakeTree.valuesFromSet["wireless.radio0.channel"])
+	assert.Equal(t, 1, fakeTree.commitCount)
+	assert.Equal(t, 0, radio.ChannelFailover.ConsecutiveBadPolls)
+	assert.Equal(t, 1, radio.ChannelFailover.FailoverCount)
+	assert.False(t, radio.ChannelFailover.LastFailoverAt.IsZero())
+	if alerts := radio.GetAlerts(); assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "CHANNEL_FAILOVER", alerts[0].Type)
+		assert.Equal(
+			t,
+			"Switched from channel 36 to backup channel 149 after 2 polls of interference (50.0% busy, noise -60 dBm).",
+			alerts[0].Message,
+		)
+	}
+}
+
+func TestRadio_checkChannelFailoverMatchLock(t *testing.T) {
+	radio, fakeShell, _ := newFailoverTestRadio(t)
+	settings := radio.GetSettings()
+	settings.ChannelFailover.BackupChannels = []int{149}
+	radio.SetSettings(settings)
+	fakeShell.commandOutput["iw dev wlan0-5 survey dump"] = testNoisySurveyDump
+	_, _ = radio.AcquireMatchLock(60)
+
+	// Failover is deferred while a match is in progress.
+	for i := 0; i < 3; i++ {
+		radio.checkChannelFailover()
+	}
+	assert.Equal(t, 3, radio.ChannelFailover.ConsecutiveBadPolls)
+	assert.Equal(t, 36, radio.Channel)
+	assert.Equal(t, 0, radio.ChannelFailover.FailoverCount)
+
+	// And happens on the next bad poll after the lock is released.
+	radio.ReleaseMatchLock()
+	fakeShell.commandOutput["hostapd_cli -i wlan0 chan_switch 5 5745 ht"] = "OK"
+	radio.checkChannelFailover()
+	assert.Equal(t, 149, radio.Channel)
+	assert.Equal(t, 1, radio.ChannelFailover.FailoverCount)
+}
+
+func TestRadio_checkChannelFailoverErrors(t *testing.T) {
+	radio, fakeShell, fakeTree := newFailoverTestRadio(t)
+	settings := radio.GetSettings()
+	settings.ChannelFailover.BackupChannels = []int{149}
+	settings.ChannelFailover.ConsecutivePolls = 1
+	radio.SetSettings(settings)
+
+	// Survey failure.
+	fakeShell.commandErrors["iw dev wlan0-5 survey dump"] = errors.New("oops")
+	radio.checkChannelFailover()
+	assert.Equal(t, 0, radio.ChannelFailover.ConsecutiveBadPolls)
+
+	// Channel switch failure leaves the configuration untouched.
+	delete(fakeShell.commandErrors, "iw dev wlan0-5 survey dump")
+	fakeShell.commandOutput["iw dev wlan0-5 survey dump"] = testNoisySurveyDump
+	fakeShell.commandErrors["hostapd_cli -i wlan0 chan_switch 5 5745 ht"] = errors.New("oops")
+	radio.checkChannelFailover()
+	assert.Equal(t, 36, radio.Channel)
+	assert.Equal(t, 0, fakeTree.setCount)
+	assert.Equal(t, 0, radio.ChannelFailover.FailoverCount)
+}
+
+func TestRadio_nextBackupChannel(t *testing.T) {
+	radio, _, _ := newFailoverTestRadio(t)
+
+	settings := radio.GetSettings()
+	settings.ChannelFailover.BackupChannels = []int{149, 157, 36}
+	radio.SetSettings(settings)
+	channel, ok := radio.nextBackupChannel()
+	assert.True(t, ok)
+	assert.Equal(t, 149, channel)
+
+	// Continues from the current channel's position in the list, wrapping around.
+	radio.Channel = 157
+	channel, _ = radio.nextBackupChannel()
+	assert.Equal(t, 36, channel)
+
+	// Skips channels that aren't permitted.
+	radio.Channel = 36
+	radio.Country = "GB"
+	settings = radio.GetSettings()
+	settings.ChannelFailover.BackupChannels = []int{149, 40}
+	radio.SetSettings(settings)
+	channel, _ = radio.nextBackupChannel()
+	assert.Equal(t, 40, channel)
+
+	settings = radio.GetSettings()
+	settings.ChannelFailover.BackupChannels = []int{149, 36}
+	radio.SetSettings(settings)
+	_, ok = radio.nextBackupChannel()
+	assert.False(t, ok)
+}
+
+func TestRadio_switchChannelWhileValidating(t *testing.T) {
+	radio, fakeShell, _ := newFailoverTestRadio(t)
+	fakeShell.commandOutput["hostapd_cli -i wlan0 chan_switch 5 5180 ht"] = "OK"
+	fakeShell.commandOutput["hostapd_cli -i wlan0 chan_switch 5 5745 ht"] = "OK"
+
+	// Requests are validated from the web server goroutine while the radio goroutine changes the channel.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			assert.Nil(t, radio.switchChannel(149))
+			assert.Nil(t, radio.switchChannel(36))
+		}
+	}()
+	request := ConfigurationRequest{Country: "US"}
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, request.Validate(radio))
+	}
+	<-done
+	assert.Equal(t, 36, radio.Channel)
+}
@@ -0,0 +1,71 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"fmt"
+	"log"
+)
+
+// Amount by which the target channel's busy percentage must exceed the current channel's for it to be considered
+// busier, to avoid reacting to measurement noise.
+const channelGuardMarginPercent = 5.0
+
+// checkChannelChange performs a quick utilization check on the given target channel when the channel change guard is
+// enabled, returning an error if the change should be rejected because the target channel is busier than the current
+// one. Measurement failures never block the change.
+func (radio *Radio) checkChannelChange(targetChannel int) error {
+	guard := radio.GetSettings().ChannelChangeGuard
+	if guard == channelChangeGuardOff || guard == "" || targetChannel == radio.Channel {
+		return nil
+	}
+
+	currentBusyPercent, targetBusyPercent, err := radio.measureChannelUtilization(radio.Channel, targetChannel)
+	if err != nil {
+		log.Printf("Unable to check utilization of channel %d; allowing the change: %v", targetChannel, err)
+		return nil
+	}
+	if targetBusyPercent <= currentBusyPercent+channelGuardMarginPercent {
+		return nil
+	}
+
+	message := fmt.Sprintf(
+		"channel %d is busier than current channel %d (%.1f%% vs. %.1f%% busy)",
+		targetChannel,
+		radio.Channel,
+		targetBusyPercent,
+		currentBusyPercent,
+	)
+	if guard == channelChangeGuardReject {
+		radio.raiseAlert("CHANNEL_CHANGE_REJECTED", "Rejected channel change: %s.", message)
+		return fmt.Errorf("%s; set overrideChannelGuard to change anyway", message)
+	}
+	radio.raiseAlert("CHANNEL_CHANGE_BUSIER", "Changing channel despite utilization: %s.", message)
+	return nil
+}
+
+// measureChannelUtilization scans all channels and returns the busy percentage of the current and target channels.
+func (radio *Radio) measureChannelUtilization(currentChannel, targetChannel int) (float64, float64, error) {
+	surveyInterface := radio.stationInterfaces[blue3]
+
+	// Scanning causes the radio to visit each channel, refreshing the survey data for non-operating channels.
+	if _, err := shell.runCommand("iwinfo", surveyInterface, "scan"); err != nil {
+		return 0, 0, fmt.Errorf("error running 'iwinfo %s scan': %v", surveyInterface, err)
+	}
+	output, err := shell.runCommand("iw", "dev", surveyInterface, "survey", "dump")
+	if err != nil {
+		return 0, 0, fmt.Errorf("error running 'iw dev %s survey dump': %v", surveyInterface, err)
+	}
+
+	samples := parseSurveyDump(output)
+	currentSample, ok := samples[currentChannel]
+	if !ok {
+		return 0, 0, fmt.Errorf("no survey data for channel %d", currentChannel)
+	}
+	targetSample, ok := samples[targetChannel]
+	if !ok {
+		return 0, 0, fmt.Errorf("no survey data for channel %d", targetChannel)
+	}
+	return currentSample.busyPercent, targetSample.busyPercent, nil
+}
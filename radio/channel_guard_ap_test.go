@@ -0,0 +1,100 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRadio_checkChannelChange(t *testing.T) {
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+	radio.Channel = 36
+	fakeShell.reset()
+
+	// Guard disabled.
+	assert.Nil(t, radio.checkChannelChange(149))
+	assert.Equal(t, 0, len(fakeShell.commandsRun))
+
+	// Target channel is quieter.
+	settings := radio.GetSettings()
+	settings.ChannelChangeGuard = channelChangeGuardReject
+	radio.SetSettings(settings)
+	fakeShell.commandOutput["iwinfo wlan0-5 scan"] = ""
+	fakeShell.commandOutput["iw dev wlan0-5 survey dump"] = testSurveyDump
+	radio.Channel = 149
+	assert.Nil(t, radio.checkChannelChange(36))
+	assert.Nil(t, radio.checkChannelChange(149))
+	assert.Equal(t, 0, len(radio.GetAlerts()))
+
+	// Target channel is busier and the change is rejected.
+	radio.Channel = 36
+	assert.EqualError(
+		t,
+		radio.checkChannelChange(149),
+		"channel 149 is busier than current channel 36 (60.0% vs. 25.0% busy); set overrideChannelGuard to change "+
+			"anyway",
+	)
+	if alerts := radio.GetAlerts(); assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "CHANNEL_CHANGE_REJECTED", alerts[0].Type)
+	}
+
+	// Target channel is busier but the change is only warned about.
+	settings = radio.GetSettings()
+	settings.ChannelChangeGuard = channelChangeGuardWarn
+	radio.SetSettings(settings)
+	assert.Nil(t, radio.checkChannelChange(149))
+	if alerts := radio.GetAlerts(); assert.Equal(t, 2, len(alerts)) {
+		assert.Equal(t, "CHANNEL_CHANGE_BUSIER", alerts[1].Type)
+	}
+
+	// Measurement failures don't block the change.
+	settings = radio.GetSettings()
+	settings.ChannelChangeGuard = channelChangeGuardReject
+	radio.SetSettings(settings)
+	assert.Nil(t, radio.checkChannelChange(44))
+	delete(fakeShell.commandOutput, "iw dev wlan0-5 survey dump")
+	fakeShell.commandErrors["iw dev wlan0-5 survey dump"] = errors.New("oops")
+	assert.Nil(t, radio.checkChannelChange(149))
+}
+
+func TestRadio_handleConfigurationRequestChannelGuard(t *testing.T) {
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+	radio.Channel = 36
+	settings := radio.GetSettings()
+	settings.ChannelChangeGuard = channelChangeGuardReject
+	radio.SetSettings(settings)
+	fakeShell.reset()
+	fakeShell.commandOutput["iwinfo wlan0-5 scan"] = ""
+	fakeShell.commandOutput["iw dev wlan0-5 survey dump"] = testSurveyDump
+
+	// Validation doesn't measure the spectrum; the guard is only checked when the request is applied.
+	request := ConfigurationRequest{Channel: 149}
+	assert.Nil(t, request.Validate(radio))
+	assert.Equal(t, 0, len(fakeShell.commandsRun))
+
+	id, err := radio.EnqueueConfigurationRequest(request)
+	assert.Nil(t, err)
+	err = radio.handleConfigurationRequest(<-radio.ConfigurationRequestChannel)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "channel 149 is busier than current channel 36")
+	}
+	record, _ := radio.GetConfigurationRequest(id)
+	assert.Equal(t, requestStateFailed, record.State)
+	assert.Contains(t, record.Error, "set overrideChannelGuard to change anyway")
+	assert.Equal(t, 36, radio.Channel)
+	assert.NotContains(t, fakeShell.commandsRun, "wifi reload radio0")
+
+	fakeShell.reset()
+	request.OverrideChannelGuard = true
+	assert.Nil(t, request.checkBeforeApplying(radio))
+	assert.Equal(t, 0, len(fakeShell.commandsRun))
+}
@@ -0,0 +1,311 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// How long to wait between channel surveys, since scanning is comparatively expensive.
+	surveyInterval = time.Minute
+
+	// Maximum number of survey samples to retain per channel.
+	maxSurveySamplesPerChannel = 60
+
+	// Average noise floor above which a channel is considered to have persistent interference.
+	persistentNoiseThresholdDbm = -85
+
+	// Noise standard deviation above which a channel is considered to have bursty interference.
+	burstyNoiseThresholdDb = 6.0
+
+	// Number of foreign BSSes at or above which a channel is considered crowded.
+	crowdedBssCountThreshold = 3
+)
+
+var (
+	surveyFrequencyRe = regexp.MustCompile(`frequency:\s+(\d+) MHz`)
+	surveyNoiseRe     = regexp.MustCompile(`noise:\s+(-?\d+) dBm`)
+	surveyActiveRe    = regexp.MustCompile(`channel active time:\s+(\d+) ms`)
+	surveyBusyRe      = regexp.MustCompile(`channel busy time:\s+(\d+) ms`)
+	scanAddressRe     = regexp.MustCompile(`Address: ((?:[0-9A-Fa-f]{2}:){5}[0-9A-Fa-f]{2})`)
+	scanSsidRe        = regexp.MustCompile(`ESSID: "(.*)"`)
+	scanChannelRe     = regexp.MustCompile(`Channel: (\d+)`)
+	scanSignalRe      = regexp.MustCompile(`Signal: (-?\d+) dBm`)
+	scanWidthRe       = regexp.MustCompile(`Channel Width: (\d+) MHz`)
+)
+
+// channelSample represents the RF conditions observed on a single channel during one survey.
+type channelSample struct {
+	time        time.Time
+	noiseDbm    int
+	busyPercent float64
+}
+
+// neighborBss represents a foreign basic service set (i.e. another access point's network) seen during a scan.
+type neighborBss struct {
+	ssid      string
+	bssid     string
+	channel   int
+	signalDbm int
+	widthMhz  int
+}
+
+// channelSurvey accumulates survey and scan data over time; it is shared between the radio and web goroutines.
+type channelSurvey struct {
+	mutex            sync.Mutex
+	lastSurveyAt     time.Time
+	samples          map[int][]channelSample
+	neighborBsss     []neighborBss
+	neighborNetworks map[string]*NeighborNetwork
+	rogueNetworks    map[string]*RogueNetwork
+}
+
+// ChannelReport summarizes the interference observed on a single channel.
+type ChannelReport struct {
+	// Channel number.
+	Channel int `json:"channel"`
+
+	// Whether this is the channel the access point is currently operating on.
+	IsCurrent bool `json:"isCurrent"`
+
+	// Number of survey samples the report is based on.
+	SampleCount int `json:"sampleCount"`
+
+	// Mean noise floor across all samples, in decibel-milliwatts.
+	AverageNoiseDbm float64 `json:"averageNoiseDbm"`
+
+	// Highest noise floor across all samples, in decibel-milliwatts.
+	MaxNoiseDbm int `json:"maxNoiseDbm"`
+
+	// Standard deviation of the noise floor across all samples, in decibels.
+	NoiseStdDevDb float64 `json:"noiseStdDevDb"`
+
+	// Mean percentage of time the channel was sensed as busy.
+	AverageBusyPercent float64 `json:"averageBusyPercent"`
+
+	// Number of foreign BSSes seen on the channel in the most recent scan.
+	ForeignBssCount int `json:"foreignBssCount"`
+
+	// Types of interference detected on the channel: "PERSISTENT_NOISE", "BURSTY", and/or "CROWDED".
+	Classifications []string `json:"classifications"`
+
+	// Foreign networks seen on the channel within the last ten minutes, flagged relative to the field channel.
+	Neighbors []NeighborNetwork `json:"neighbors"`
+
+	// Relative badness of the channel; lower is better. Reports are ranked by this value.
+	Score float64 `json:"score"`
+}
+
+// updateChannelSurvey surveys the noise floor and scans for foreign networks if the survey interval has elapsed as of
+// the given time, accumulating the results. Since scanning takes the radio off its channel for a moment, the survey is
+// skipped entirely while a match is in progress or any robot is linked.
+func (radio *Radio) updateChannelSurvey(now time.Time) {
+	if radio.isMatchLockHeld() || radio.hasLinkedClients() {
+		return
+	}
+	radio.survey.mutex.Lock()
+	if radio.survey.lastSurveyAt.IsZero() {
+		// Wait a full interval after startup before the first survey.
+		radio.survey.lastSurveyAt = now
+	}
+	isDue := now.Sub(radio.survey.lastSurveyAt) >= surveyInterval
+	if isDue {
+		radio.survey.lastSurveyAt = now
+	}
+	radio.survey.mutex.Unlock()
+	if !isDue {
+		return
+	}
+
+	surveyInterface := radio.stationInterfaces[blue3]
+	surveyOutput, err := shell.runCommand("iw", "dev", surveyInterface, "survey", "dump")
+	if err != nil {
+		log.Printf("Error running 'iw dev %s survey dump': %v", surveyInterface, err)
+	}
+	scanOutput, scanErr := shell.runCommand("iwinfo", surveyInterface, "scan")
+	if scanErr != nil {
+		log.Printf("Error running 'iwinfo %s scan': %v", surveyInterface, scanErr)
+	}
+
+	radio.survey.mutex.Lock()
+	defer radio.survey.mutex.Unlock()
+	if radio.survey.samples == nil {
+		radio.survey.samples = make(map[int][]channelSample)
+	}
+	if err == nil {
+		for channel, sample := range parseSurveyDump(surveyOutput) {
+			samples := append(radio.survey.samples[channel], sample)
+			if len(samples) > maxSurveySamplesPerChannel {
+				samples = samples[len(samples)-maxSurveySamplesPerChannel:]
+			}
+			radio.survey.samples[channel] = samples
+		}
+	}
+	if scanErr == nil {
+		radio.survey.neighborBsss = parseScan(scanOutput)
+		radio.updateNeighborNetworks(radio.survey.neighborBsss)
+		radio.detectRogueNetworks(radio.survey.neighborBsss)
+	}
+}
+
+// GetChannelReport returns the accumulated interference data for each channel, ranked from best to worst.
+func (radio *Radio) GetChannelReport() []ChannelReport {
+	radio.survey.mutex.Lock()
+	defer radio.survey.mutex.Unlock()
+
+	bssCounts := make(map[int]int)
+	for _, bss := range radio.survey.neighborBsss {
+		bssCounts[bss.channel]++
+	}
+	channels := make(map[int]struct{})
+	for channel := range radio.survey.samples {
+		channels[channel] = struct{}{}
+	}
+	for channel := range bssCounts {
+		channels[channel] = struct{}{}
+	}
+
+	reports := make([]ChannelReport, 0, len(channels))
+	for channel := range channels {
+		report := ChannelReport{
+			Channel:         channel,
+			IsCurrent:       channel == radio.Channel,
+			ForeignBssCount: bssCounts[channel],
+			Classifications: []string{},
+			Neighbors:       radio.neighborNetworksOnChannel(channel),
+		}
+		report.summarizeSamples(radio.survey.samples[channel])
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Score == reports[j].Score {
+			return reports[i].Channel < reports[j].Channel
+		}
+		return reports[i].Score < reports[j].Score
+	})
+	return reports
+}
+
+// summarizeSamples computes the statistics, classifications, and score of the report from the given samples.
+func (report *ChannelReport) summarizeSamples(samples []channelSample) {
+	report.SampleCount = len(samples)
+	if len(samples) > 0 {
+		report.MaxNoiseDbm = samples[0].noiseDbm
+		var noiseSum, busySum float64
+		for _, sample := range samples {
+			noiseSum += float64(sample.noiseDbm)
+			busySum += sample.busyPercent
+			if sample.noiseDbm > report.MaxNoiseDbm {
+				report.MaxNoiseDbm = sample.noiseDbm
+			}
+		}
+		report.AverageNoiseDbm = noiseSum / float64(len(samples))
+		report.AverageBusyPercent = busySum / float64(len(samples))
+		var varianceSum float64
+		for _, sample := range samples {
+			varianceSum += math.Pow(float64(sample.noiseDbm)-report.AverageNoiseDbm, 2)
+		}
+		report.NoiseStdDevDb = math.Sqrt(varianceSum / float64(len(samples)))
+
+		if report.AverageNoiseDbm > persistentNoiseThresholdDbm {
+			report.Classifications = append(report.Classifications, "PERSISTENT_NOISE")
+		}
+		if report.NoiseStdDevDb > burstyNoiseThresholdDb {
+			report.Classifications = append(report.Classifications, "BURSTY")
+		}
+	}
+	if report.ForeignBssCount >= crowdedBssCountThreshold {
+		report.Classifications = append(report.Classifications, "CROWDED")
+	}
+
+	// Weight each factor so that a roughly equal degradation in any of them contributes similarly to the score.
+	if len(samples) > 0 {
+		report.Score += report.AverageNoiseDbm + 100
+		report.Score += report.NoiseStdDevDb
+		report.Score += report.AverageBusyPercent / 2
+	}
+	report.Score += 5 * float64(report.ForeignBssCount)
+	report.AverageNoiseDbm = math.Round(report.AverageNoiseDbm*10) / 10
+	report.NoiseStdDevDb = math.Round(report.NoiseStdDevDb*10) / 10
+	report.AverageBusyPercent = math.Round(report.AverageBusyPercent*10) / 10
+	report.Score = math.Round(report.Score*10) / 10
+}
+
+// parseSurveyDump parses the output of 'iw dev [interface] survey dump' into a sample for each channel.
+func parseSurveyDump(response string) map[int]channelSample {
+	samples := make(map[int]channelSample)
+	now := time.Now()
+	for _, block := range strings.Split(response, "Survey data from")[1:] {
+		frequencyMatch := surveyFrequencyRe.FindStringSubmatch(block)
+		noiseMatch := surveyNoiseRe.FindStringSubmatch(block)
+		if frequencyMatch == nil || noiseMatch == nil {
+			continue
+		}
+		frequency, _ := strconv.Atoi(frequencyMatch[1])
+		sample := channelSample{time: now}
+		sample.noiseDbm, _ = strconv.Atoi(noiseMatch[1])
+		activeMatch := surveyActiveRe.FindStringSubmatch(block)
+		busyMatch := surveyBusyRe.FindStringSubmatch(block)
+		if activeMatch != nil && busyMatch != nil {
+			activeMs, _ := strconv.Atoi(activeMatch[1])
+			busyMs, _ := strconv.Atoi(busyMatch[1])
+			if activeMs > 0 {
+				sample.busyPercent = 100 * float64(busyMs) / float64(activeMs)
+			}
+		}
+		samples[frequencyToChannel(frequency)] = sample
+	}
+	return samples
+}
+
+// parseScan parses the output of 'iwinfo [interface] scan' into a list of the networks seen.
+func parseScan(response string) []neighborBss {
+	var bsss []neighborBss
+	for _, cell := range strings.Split(response, "Cell ")[1:] {
+		addressMatch := scanAddressRe.FindStringSubmatch(cell)
+		if addressMatch == nil {
+			continue
+		}
+		bss := neighborBss{bssid: strings.ToUpper(addressMatch[1])}
+		if ssidMatch := scanSsidRe.FindStringSubmatch(cell); ssidMatch != nil {
+			bss.ssid = ssidMatch[1]
+		}
+		if channelMatch := scanChannelRe.FindStringSubmatch(cell); channelMatch != nil {
+			bss.channel, _ = strconv.Atoi(channelMatch[1])
+		}
+		if signalMatch := scanSignalRe.FindStringSubmatch(cell); signalMatch != nil {
+			bss.signalDbm, _ = strconv.Atoi(signalMatch[1])
+		}
+		// Wider channels are reported in a separate section per standard (e.g. HT and VHT), so take the widest.
+		for _, widthMatch := range scanWidthRe.FindAllStringSubmatch(cell, -1) {
+			if width, _ := strconv.Atoi(widthMatch[1]); width > bss.widthMhz {
+				bss.widthMhz = width
+			}
+		}
+		bsss = append(bsss, bss)
+	}
+	return bsss
+}
+
+// frequencyToChannel converts the given center frequency in MHz to a 2.4GHz, 5GHz, or 6GHz channel number.
+func frequencyToChannel(frequencyMhz int) int {
+	switch {
+	case frequencyMhz == 2484:
+		return 14
+	case frequencyMhz < 2484:
+		return (frequencyMhz - 2407) / 5
+	case frequencyMhz > 5950:
+		return (frequencyMhz - 5950) / 5
+	default:
+		return (frequencyMhz - 5000) / 5
+	}
+}
@@ -0,0 +1,152 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+const testSurveyDump = "Survey data from wlan0-5\n" +
+	"\tfrequency:\t\t\t5180 MHz [in use]\n" +
+	"\tnoise:\t\t\t\t-95 dBm\n" +
+	"\tchannel active time:\t\t1000 ms\n" +
+	"\tchannel busy time:\t\t250 ms\n" +
+	"Survey data from wlan0-5\n" +
+	"\tfrequency:\t\t\t5745 MHz\n" +
+	"\tnoise:\t\t\t\t-80 dBm\n" +
+	"\tchannel active time:\t\t1000 ms\n" +
+	"\tchannel busy time:\t\t600 ms\n" +
+	"Survey data from wlan0-5\n" +
+	"\tfrequency:\t\t\t5200 MHz\n"
+
+const testScan = "Cell 01 - Address: 00:11:22:33:44:55\n" +
+	"          ESSID: \"VenueWiFi\"\n" +
+	"          Mode: Master  Channel: 149\n" +
+	"          Signal: -60 dBm  Quality: 50/70\n" +
+	"          HT Operation:\n" +
+	"                    Channel Width: 40 MHz or higher\n" +
+	"          VHT Operation:\n" +
+	"                    Channel Width: 80 MHz\n" +
+	"Cell 02 - Address: aa:bb:cc:dd:ee:ff\n" +
+	"          ESSID: \"254\"\n" +
+	"          Mode: Master  Channel: 36\n" +
+	"          Signal: -71 dBm  Quality: 39/70\n"
+
+func TestParseSurveyDump(t *testing.T) {
+	samples := parseSurveyDump(testSurveyDump)
+	if assert.Equal(t, 2, len(samples)) {
+		assert.Equal(t, -95, samples[36].noiseDbm)
+		assert.Equal(t, 25.0, samples[36].busyPercent)
+		assert.Equal(t, -80, samples[149].noiseDbm)
+		assert.Equal(t, 60.0, samples[149].busyPercent)
+	}
+	assert.Equal(t, 0, len(parseSurveyDump("")))
+}
+
+func TestParseScan(t *testing.T) {
+	bsss := parseScan(testScan)
+	assert.Equal(
+		t,
+		[]neighborBss{
+			{ssid: "VenueWiFi", bssid: "00:11:22:33:44:55", channel: 149, signalDbm: -60, widthMhz: 80},
+			{ssid: "254", bssid: "AA:BB:CC:DD:EE:FF", channel: 36, signalDbm: -71},
+		},
+		bsss,
+	)
+	assert.Equal(t, 0, len(parseScan("No scan results")))
+}
+
+func TestFrequencyToChannel(t *testing.T) {
+	assert.Equal(t, 1, frequencyToChannel(2412))
+	assert.Equal(t, 14, frequencyToChannel(2484))
+	assert.Equal(t, 36, frequencyToChannel(5180))
+	assert.Equal(t, 165, frequencyToChannel(5825))
+	assert.Equal(t, 5, frequencyToChannel(5975))
+	assert.Equal(t, 229, frequencyToChannel(7095))
+}
+
+func TestRadio_updateChannelSurvey(t *testing.T) {
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+	radio.Channel = 36
+	fakeShell.reset()
+
+	// The first survey happens a full interval after startup.
+	startTime := time.Now()
+	radio.updateChannelSurvey(startTime)
+	radio.updateChannelSurvey(startTime.Add(59 * time.Second))
+	assert.Equal(t, 0, len(fakeShell.commandsRun))
+	assert.Equal(t, []ChannelReport{}, radio.GetChannelReport())
+
+	fakeShell.commandOutput["iw dev wlan0-5 survey dump"] = testSurveyDump
+	fakeShell.commandOutput["iwinfo wlan0-5 scan"] = testScan
+	now := startTime.Add(time.Minute)
+	radio.updateChannelSurvey(now)
+	assert.Equal(t, 2, len(fakeShell.commandsRun))
+	reports := radio.GetChannelReport()
+	if assert.Equal(t, 2, len(reports)) {
+		assert.Equal(t, 36, reports[0].Channel)
+		assert.True(t, reports[0].IsCurrent)
+		assert.Equal(t, 1, reports[0].SampleCount)
+		assert.Equal(t, -95.0, reports[0].AverageNoiseDbm)
+		assert.Equal(t, 1, reports[0].ForeignBssCount)
+		assert.Equal(t, []string{}, reports[0].Classifications)
+		if assert.Equal(t, 1, len(reports[0].Neighbors)) {
+			assert.Equal(t, "AA:BB:CC:DD:EE:FF", reports[0].Neighbors[0].Bssid)
+			assert.True(t, reports[0].Neighbors[0].IsCoChannel)
+		}
+		assert.Equal(t, 22.5, reports[0].Score)
+		assert.Equal(t, 149, reports[1].Channel)
+		assert.False(t, reports[1].IsCurrent)
+		assert.Equal(t, []string{"PERSISTENT_NOISE"}, reports[1].Classifications)
+	}
+
+	// Survey is skipped until the interval elapses, regardless of how often it is polled.
+	fakeShell.reset()
+	for i := 1; i < 60; i++ {
+		radio.updateChannelSurvey(now.Add(time.Duration(i) * time.Second))
+	}
+	assert.Equal(t, 0, len(fakeShell.commandsRun))
+
+	// Survey is skipped while a robot is linked or a match is in progress.
+	radio.StationStatuses["red1"] = &NetworkStatus{IsLinked: true}
+	radio.updateChannelSurvey(now.Add(time.Minute))
+	assert.Equal(t, 0, len(fakeShell.commandsRun))
+	radio.StationStatuses["red1"] = nil
+	radio.MatchLock = MatchLockStatus{IsHeld: true, ExpiresAt: now.Add(time.Hour)}
+	radio.updateChannelSurvey(now.Add(time.Minute))
+	assert.Equal(t, 0, len(fakeShell.commandsRun))
+	radio.MatchLock = MatchLockStatus{}
+
+	// Errors leave the accumulated data intact.
+	fakeShell.commandErrors["iw dev wlan0-5 survey dump"] = errors.New("oops")
+	fakeShell.commandErrors["iwinfo wlan0-5 scan"] = errors.New("oops")
+	radio.updateChannelSurvey(now.Add(time.Minute))
+	assert.Equal(t, 2, len(fakeShell.commandsRun))
+	assert.Equal(t, reports, radio.GetChannelReport())
+}
+
+func TestChannelReport_summarizeSamples(t *testing.T) {
+	// Bursty noise.
+	report := ChannelReport{Classifications: []string{}}
+	report.summarizeSamples(
+		[]channelSample{{noiseDbm: -95}, {noiseDbm: -75}, {noiseDbm: -95}, {noiseDbm: -75}},
+	)
+	assert.Equal(t, 4, report.SampleCount)
+	assert.Equal(t, -85.0, report.AverageNoiseDbm)
+	assert.Equal(t, -75, report.MaxNoiseDbm)
+	assert.Equal(t, 10.0, report.NoiseStdDevDb)
+	assert.Equal(t, []string{"BURSTY"}, report.Classifications)
+
+	// Crowded channel without survey data.
+	report = ChannelReport{ForeignBssCount: 4, Classifications: []string{}}
+	report.summarizeSamples(nil)
+	assert.Equal(t, []string{"CROWDED"}, report.Classifications)
+	assert.Equal(t, 20.0, report.Score)
+}
@@ -0,0 +1,28 @@
+package radio
+
+// ClientCapabilities represents the capabilities that an associated device advertised when it associated, which reveal
+// a device stuck in a legacy mode (e.g. a misconfigured robot radio) that will never achieve adequate throughput no
+// matter how good the signal is.
+type ClientCapabilities struct {
+	// Whether the device supports 802.11n.
+	SupportsHt bool `json:"supportsHt"`
+
+	// Whether the device supports 802.11ac.
+	SupportsVht bool `json:"supportsVht"`
+
+	// Whether the device supports 802.11ax.
+	SupportsHe bool `json:"supportsHe"`
+
+	// Most capable 802.11 generation the device supports: "LEGACY" (pre-802.11n), "HT", "VHT", or "HE".
+	BestMode string `json:"bestMode"`
+
+	// Channel bandwidths the device advertises support for in its HT and VHT capabilities, in megahertz.
+	ChannelWidthsMhz []int `json:"channelWidthsMhz"`
+
+	// Spatial multiplexing power save mode the device uses: "STATIC", "DYNAMIC", or "DISABLED". Blank for legacy
+	// devices, which don't advertise one.
+	PowerSaveMode string `json:"powerSaveMode"`
+
+	// MAC address of the device the capabilities were read for, so that they are only re-read when it changes.
+	macAddress string
+}
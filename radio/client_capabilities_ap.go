@@ -0,0 +1,89 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"log"
+	"strconv"
+	"strings"
+)
+
+// Spatial multiplexing power save modes, indexed by the corresponding bits of the HT capabilities.
+var htPowerSaveModes = []string{"STATIC", "DYNAMIC", "", "DISABLED"}
+
+// updateClientCapabilities reads the advertised capabilities of the device linked to each team station from hostapd.
+// Since they can't change without the device reassociating, they are only read when a new device links.
+func (radio *Radio) updateClientCapabilities() {
+	for station := red1; station <= blue3; station++ {
+		stationStatus := radio.StationStatuses[station.String()]
+		if stationStatus == nil {
+			continue
+		}
+		if !stationStatus.IsLinked {
+			stationStatus.ClientCapabilities = nil
+			continue
+		}
+		if capabilities := stationStatus.ClientCapabilities; capabilities != nil &&
+			capabilities.macAddress == stationStatus.MacAddress {
+			continue
+		}
+
+		wifiInterface := radio.stationInterfaces[station]
+		output, err := shell.runCommand(
+			"hostapd_cli", "-i", wifiInterface, "sta", strings.ToLower(stationStatus.MacAddress),
+		)
+		if err != nil {
+			log.Printf("Error reading capabilities of %s from %s: %v", stationStatus.MacAddress, wifiInterface, err)
+			stationStatus.ClientCapabilities = nil
+			continue
+		}
+		stationStatus.ClientCapabilities = parseClientCapabilities(output, stationStatus.MacAddress)
+	}
+}
+
+// parseClientCapabilities parses the output of 'hostapd_cli sta [MAC address]' and returns the capabilities of the
+// device with the given MAC address, or nil if it isn't associated.
+func parseClientCapabilities(response string, macAddress string) *ClientCapabilities {
+	lines := strings.Split(strings.TrimSpace(response), "\n")
+	if len(lines) == 0 || !strings.EqualFold(strings.TrimSpace(lines[0]), macAddress) {
+		return nil
+	}
+	capabilities := ClientCapabilities{BestMode: "LEGACY", ChannelWidthsMhz: []int{20}, macAddress: macAddress}
+	var htCapsInfo, vhtCapsInfo int64
+	for _, line := range lines[1:] {
+		name, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch name {
+		case "flags":
+			capabilities.SupportsHt = strings.Contains(value, "[HT]")
+			capabilities.SupportsVht = strings.Contains(value, "[VHT]")
+			capabilities.SupportsHe = strings.Contains(value, "[HE]")
+		case "ht_caps_info":
+			htCapsInfo, _ = strconv.ParseInt(strings.TrimPrefix(value, "0x"), 16, 64)
+		case "vht_caps_info":
+			vhtCapsInfo, _ = strconv.ParseInt(strings.TrimPrefix(value, "0x"), 16, 64)
+		}
+	}
+
+	if capabilities.SupportsHt {
+		capabilities.BestMode = "HT"
+		capabilities.PowerSaveMode = htPowerSaveModes[htCapsInfo>>2&0x3]
+		if htCapsInfo&0x2 != 0 {
+			capabilities.ChannelWidthsMhz = append(capabilities.ChannelWidthsMhz, 40)
+		}
+	}
+	if capabilities.SupportsVht {
+		capabilities.BestMode = "VHT"
+		capabilities.ChannelWidthsMhz = []int{20, 40, 80}
+		if vhtCapsInfo>>2&0x3 != 0 {
+			capabilities.ChannelWidthsMhz = append(capabilities.ChannelWidthsMhz, 160)
+		}
+	}
+	if capabilities.SupportsHe {
+		capabilities.BestMode = "HE"
+	}
+	return &capabilities
+}
@@ -0,0 +1,92 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseClientCapabilities(t *testing.T) {
+	// 802.11ac device supporting 160MHz channels.
+	response := "48:da:35:b0:00:cf\nflags=[AUTH][ASSOC][AUTHORIZED][WMM][HT][VHT]\naid=1\n" +
+		"ht_caps_info=0x09ef\nvht_caps_info=0x338b79b6\n"
+	assert.Equal(
+		t,
+		&ClientCapabilities{
+			SupportsHt:       true,
+			SupportsVht:      true,
+			BestMode:         "VHT",
+			ChannelWidthsMhz: []int{20, 40, 80, 160},
+			PowerSaveMode:    "DISABLED",
+			macAddress:       "48:DA:35:B0:00:CF",
+		},
+		parseClientCapabilities(response, "48:DA:35:B0:00:CF"),
+	)
+
+	// 802.11ax device.
+	response = "48:da:35:b0:00:cf\nflags=[AUTH][ASSOC][AUTHORIZED][WMM][HT][VHT][HE]\nht_caps_info=0x01ee\n" +
+		"vht_caps_info=0x338b79b2\n"
+	capabilities := parseClientCapabilities(response, "48:DA:35:B0:00:CF")
+	assert.Equal(t, "HE", capabilities.BestMode)
+	assert.Equal(t, []int{20, 40, 80}, capabilities.ChannelWidthsMhz)
+
+	// 802.11n device limited to 20MHz channels with dynamic power save.
+	response = "48:da:35:b0:00:cf\nflags=[AUTH][ASSOC][AUTHORIZED][WMM][HT]\nht_caps_info=0x0004\n"
+	capabilities = parseClientCapabilities(response, "48:DA:35:B0:00:CF")
+	assert.Equal(t, "HT", capabilities.BestMode)
+	assert.Equal(t, []int{20}, capabilities.ChannelWidthsMhz)
+	assert.Equal(t, "DYNAMIC", capabilities.PowerSaveMode)
+
+	// Legacy device.
+	response = "48:da:35:b0:00:cf\nflags=[AUTH][ASSOC][AUTHORIZED]\n"
+	assert.Equal(
+		t,
+		&ClientCapabilities{BestMode: "LEGACY", ChannelWidthsMhz: []int{20}, macAddress: "48:DA:35:B0:00:CF"},
+		parseClientCapabilities(response, "48:DA:35:B0:00:CF"),
+	)
+
+	// Device that isn't associated.
+	assert.Nil(t, parseClientCapabilities("FAIL\n", "48:DA:35:B0:00:CF"))
+	assert.Nil(t, parseClientCapabilities("", "48:DA:35:B0:00:CF"))
+}
+
+func TestRadio_updateClientCapabilities(t *testing.T) {
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+	radio.StationStatuses["red1"] = &NetworkStatus{IsLinked: true, MacAddress: "48:DA:35:B0:00:CF"}
+	radio.StationStatuses["blue1"] = &NetworkStatus{}
+
+	fakeShell.reset()
+	fakeShell.commandOutput["hostapd_cli -i wlan0 sta 48:da:35:b0:00:cf"] = "48:da:35:b0:00:cf\nflags=[AUTH][HT]\n"
+	radio.updateClientCapabilities()
+	assert.Equal(t, 1, len(fakeShell.commandsRun))
+	assert.Contains(t, fakeShell.commandsRun, "hostapd_cli -i wlan0 sta 48:da:35:b0:00:cf")
+	assert.Equal(t, "HT", radio.StationStatuses["red1"].ClientCapabilities.BestMode)
+	assert.Nil(t, radio.StationStatuses["blue1"].ClientCapabilities)
+
+	// The capabilities aren't re-read while the same device stays linked.
+	fakeShell.reset()
+	radio.updateClientCapabilities()
+	assert.Empty(t, fakeShell.commandsRun)
+	assert.Equal(t, "HT", radio.StationStatuses["red1"].ClientCapabilities.BestMode)
+
+	// A new device has its capabilities read afresh.
+	fakeShell.reset()
+	radio.StationStatuses["red1"].MacAddress = "12:34:56:78:9A:BC"
+	fakeShell.commandErrors["hostapd_cli -i wlan0 sta 12:34:56:78:9a:bc"] = errors.New("oops")
+	radio.updateClientCapabilities()
+	assert.Nil(t, radio.StationStatuses["red1"].ClientCapabilities)
+
+	// The capabilities are cleared when the device unlinks.
+	fakeShell.reset()
+	radio.StationStatuses["red1"].ClientCapabilities = &ClientCapabilities{}
+	radio.StationStatuses["red1"].IsLinked = false
+	radio.updateClientCapabilities()
+	assert.Empty(t, fakeShell.commandsRun)
+	assert.Nil(t, radio.StationStatuses["red1"].ClientCapabilities)
+}
@@ -0,0 +1,72 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"fmt"
+	"github.com/digineo/go-uci"
+)
+
+// Name of the firewall rule section managed by the API to block traffic forwarded between the team VLANs.
+const clientIsolationFirewallRule = "frc_client_isolation"
+
+// ClientIsolationStatus represents which layers of isolation between team clients are currently in place.
+type ClientIsolationStatus struct {
+	// Whether clients are prevented from talking to each other directly through the access point, i.e. the isolate
+	// option is set on every team network.
+	Wireless bool `json:"wireless"`
+
+	// Whether the firewall blocks traffic forwarded between the team VLANs.
+	Firewall bool `json:"firewall"`
+}
+
+// isEnforced returns true if both layers of isolation are in place.
+func (status ClientIsolationStatus) isEnforced() bool {
+	return status.Wireless && status.Firewall
+}
+
+// readClientIsolation infers from the wireless and firewall configuration which layers of isolation are in place.
+func readClientIsolation() ClientIsolationStatus {
+	status := ClientIsolationStatus{Wireless: true}
+	for station := red1; station <= blue3; station++ {
+		if isolate, _ := uciTree.GetLast("wireless", wifiIfaceSection(station), "isolate"); isolate != "1" {
+			status.Wireless = false
+			break
+		}
+	}
+	target, _ := uciTree.GetLast("firewall", clientIsolationFirewallRule, "target")
+	enabled, _ := uciTree.GetLast("firewall", clientIsolationFirewallRule, "enabled")
+	status.Firewall = target == "REJECT" && enabled != "0"
+	return status
+}
+
+// configureClientIsolation enables or disables both layers of isolation between team clients. The firewall rule takes
+// effect immediately, while the wireless option only takes effect once the stations are next reloaded.
+func (radio *Radio) configureClientIsolation(enabled bool) error {
+	for station := red1; station <= blue3; station++ {
+		setUciFlag(wifiIfaceSection(station), "isolate", enabled)
+	}
+
+	if err := uciTree.AddSection("firewall", clientIsolationFirewallRule, "rule"); err != nil {
+		return fmt.Errorf("failed to add firewall rule: %v", err)
+	}
+	ruleEnabled := "0"
+	if enabled {
+		ruleEnabled = "1"
+	}
+	uciTree.SetType("firewall", clientIsolationFirewallRule, "name", uci.TypeOption, "Isolate-Team-VLANs")
+	uciTree.SetType("firewall", clientIsolationFirewallRule, "src", uci.TypeOption, "*")
+	uciTree.SetType("firewall", clientIsolationFirewallRule, "dest", uci.TypeOption, "*")
+	uciTree.SetType("firewall", clientIsolationFirewallRule, "proto", uci.TypeOption, "all")
+	uciTree.SetType("firewall", clientIsolationFirewallRule, "target", uci.TypeOption, "REJECT")
+	uciTree.SetType("firewall", clientIsolationFirewallRule, "enabled", uci.TypeOption, ruleEnabled)
+	if err := radio.commitUci("firewall"); err != nil {
+		return fmt.Errorf("failed to commit firewall configuration: %w", err)
+	}
+	if _, err := shell.runCommand("/etc/init.d/firewall", "reload"); err != nil {
+		return fmt.Errorf("failed to reload firewall: %w", err)
+	}
+	radio.ClientIsolation.Firewall = enabled
+	return nil
+}
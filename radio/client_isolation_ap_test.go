@@ -0,0 +1,89 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRadio_handleConfigurationRequestClientIsolation(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeTree.valuesForGet["system.@system[0].model"] = "VH-109(AP)"
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	wifiReloadBackoffDuration = 10 * time.Millisecond
+	fakeShell.commandOutput["cat /etc/vh_firmware"] = ""
+	radio := NewRadio()
+	assert.Equal(t, ClientIsolationStatus{}, radio.ClientIsolation)
+
+	fakeShell.commandOutput["/etc/init.d/firewall reload"] = ""
+	fakeShell.commandOutput["wifi reload wifi1"] = ""
+	for _, iface := range []string{"ath1", "ath11", "ath12", "ath13", "ath14", "ath15"} {
+		fakeShell.commandOutput["iwinfo "+iface+" info"] = iface + "\nESSID: \"no-team-1\"\n"
+	}
+	enabled := true
+	assert.Nil(t, radio.handleConfigurationRequest(ConfigurationRequest{ClientIsolation: &enabled}))
+	for _, position := range []string{"1", "2", "3", "4", "5", "6"} {
+		assert.Equal(t, "1", fakeTree.valuesFromSet["wireless.@wifi-iface["+position+"].isolate"])
+	}
+	assert.Equal(t, "***ADDED***", fakeTree.valuesFromSet["firewall.frc_client_isolation"])
+	assert.Equal(t, "*", fakeTree.valuesFromSet["firewall.frc_client_isolation.src"])
+	assert.Equal(t, "*", fakeTree.valuesFromSet["firewall.frc_client_isolation.dest"])
+	assert.Equal(t, "REJECT", fakeTree.valuesFromSet["firewall.frc_client_isolation.target"])
+	assert.Equal(t, "1", fakeTree.valuesFromSet["firewall.frc_client_isolation.enabled"])
+	assert.Contains(t, fakeShell.commandsRun, "/etc/init.d/firewall reload")
+	assert.Equal(t, ClientIsolationStatus{Wireless: true, Firewall: true}, radio.ClientIsolation)
+
+	// Disabling isolation removes the isolate options and disables the firewall rule.
+	for _, position := range []string{"1", "2", "3", "4", "5", "6"} {
+		fakeTree.valuesForGet["wireless.@wifi-iface["+position+"].isolate"] = "1"
+	}
+	fakeTree.valuesFromSet = make(map[string]string)
+	enabled = false
+	assert.Nil(t, radio.handleConfigurationRequest(ConfigurationRequest{ClientIsolation: &enabled}))
+	for _, position := range []string{"1", "2", "3", "4", "5", "6"} {
+		assert.Equal(t, "***DELETED***", fakeTree.valuesFromSet["wireless.@wifi-iface["+position+"].isolate"])
+	}
+	assert.Equal(t, "0", fakeTree.valuesFromSet["firewall.frc_client_isolation.enabled"])
+	assert.Equal(t, ClientIsolationStatus{}, radio.ClientIsolation)
+
+	// Requests that omit the option leave isolation untouched.
+	fakeTree.valuesFromSet = make(map[string]string)
+	fakeShell.commandsRun = make(map[string]struct{})
+	assert.Nil(t, radio.handleConfigurationRequest(ConfigurationRequest{Channel: 5}))
+	_, ok := fakeTree.valuesFromSet["firewall.frc_client_isolation.enabled"]
+	assert.False(t, ok)
+	assert.NotContains(t, fakeShell.commandsRun, "/etc/init.d/firewall reload")
+
+	// A firewall reload failure is reported.
+	delete(fakeShell.commandOutput, "/etc/init.d/firewall reload")
+	fakeShell.commandErrors["/etc/init.d/firewall reload"] = errors.New("oops")
+	enabled = true
+	assert.NotNil(t, radio.handleConfigurationRequest(ConfigurationRequest{ClientIsolation: &enabled}))
+	assert.False(t, radio.ClientIsolation.Firewall)
+}
+
+func TestReadClientIsolation(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	assert.Equal(t, ClientIsolationStatus{}, readClientIsolation())
+
+	// The wireless layer requires the isolate option on every team network.
+	for _, position := range []string{"1", "2", "3", "4", "5"} {
+		fakeTree.valuesForGet["wireless.@wifi-iface["+position+"].isolate"] = "1"
+	}
+	assert.Equal(t, ClientIsolationStatus{}, readClientIsolation())
+	fakeTree.valuesForGet["wireless.@wifi-iface[6].isolate"] = "1"
+	assert.Equal(t, ClientIsolationStatus{Wireless: true}, readClientIsolation())
+
+	// The firewall rule counts unless explicitly disabled.
+	fakeTree.valuesForGet["firewall.frc_client_isolation.target"] = "REJECT"
+	assert.Equal(t, ClientIsolationStatus{Wireless: true, Firewall: true}, readClientIsolation())
+	fakeTree.valuesForGet["firewall.frc_client_isolation.enabled"] = "0"
+	assert.Equal(t, ClientIsolationStatus{Wireless: true}, readClientIsolation())
+}
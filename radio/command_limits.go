@@ -0,0 +1,103 @@
+package radio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Highest niceness accepted by the kernel, giving a process the lowest scheduling priority.
+const maxCommandNiceness = 19
+
+// Limits applied to the monitoring commands run by execShell, kept in step with the settings in effect.
+var shellCommandLimits struct {
+	mutex  sync.Mutex
+	limits CommandLimits
+}
+
+// CommandLimits holds the scheduling priority and resource limits applied to the shell commands run to monitor the
+// radio, so that heavy invocations can't starve hostapd or the data plane on the access point's weak CPU.
+type CommandLimits struct {
+	// Names of the commands that the limits apply to (e.g. "luci-bwc"). Other commands run unrestricted.
+	Commands []string `json:"commands"`
+
+	// Niceness at which the commands run, from 0 (normal priority) to 19 (lowest priority).
+	Niceness int `json:"niceness"`
+
+	// Maximum CPU time that each command may use before it is killed, in seconds. Zero disables the limit.
+	CpuTimeLimitSec int `json:"cpuTimeLimitSec"`
+
+	// Maximum virtual memory that each command may allocate, in megabytes. Zero disables the limit.
+	MemoryLimitMb int `json:"memoryLimitMb"`
+}
+
+// validate checks that all parameters within the command limits have valid values.
+func (limits CommandLimits) validate() error {
+	for _, command := range limits.Commands {
+		if command == "" || strings.ContainsAny(command, "/ ") {
+			return fmt.Errorf("invalid monitoringCommandLimits.commands entry: %q (expecting a command name)", command)
+		}
+	}
+	if limits.Niceness < 0 || limits.Niceness > maxCommandNiceness {
+		return fmt.Errorf(
+			"invalid monitoringCommandLimits.niceness: %d (expecting 0-%d)", limits.Niceness, maxCommandNiceness,
+		)
+	}
+	if limits.CpuTimeLimitSec < 0 {
+		return fmt.Errorf("invalid monitoringCommandLimits.cpuTimeLimitSec: %d", limits.CpuTimeLimitSec)
+	}
+	if limits.MemoryLimitMb < 0 {
+		return fmt.Errorf("invalid monitoringCommandLimits.memoryLimitMb: %d", limits.MemoryLimitMb)
+	}
+	return nil
+}
+
+// appliesTo returns true if the given command is subject to the limits.
+func (limits CommandLimits) appliesTo(command string) bool {
+	if limits.Niceness == 0 && limits.CpuTimeLimitSec == 0 && limits.MemoryLimitMb == 0 {
+		return false
+	}
+	for _, limitedCommand := range limits.Commands {
+		if command == limitedCommand {
+			return true
+		}
+	}
+	return false
+}
+
+// wrap returns the command and arguments that run the given command under the limits, by way of a shell that sets the
+// resource limits before replacing itself with the command at reduced priority. Returns the command unchanged if the
+// limits don't apply to it.
+func (limits CommandLimits) wrap(command string, args []string) (string, []string) {
+	if !limits.appliesTo(command) {
+		return command, args
+	}
+	var script []string
+	if limits.CpuTimeLimitSec > 0 {
+		script = append(script, fmt.Sprintf("ulimit -t %d", limits.CpuTimeLimitSec))
+	}
+	if limits.MemoryLimitMb > 0 {
+		script = append(script, fmt.Sprintf("ulimit -v %d", limits.MemoryLimitMb*1024))
+	}
+	execCommand := `exec "$0" "$@"`
+	if limits.Niceness > 0 {
+		execCommand = `exec nice -n ` + strconv.Itoa(limits.Niceness) + ` "$0" "$@"`
+	}
+	script = append(script, execCommand)
+	return "sh", append([]string{"-c", strings.Join(script, "; "), command}, args...)
+}
+
+// setCommandLimits replaces the limits applied to monitoring commands with the given ones.
+func setCommandLimits(limits CommandLimits) {
+	shellCommandLimits.mutex.Lock()
+	defer shellCommandLimits.mutex.Unlock()
+	shellCommandLimits.limits = limits
+}
+
+// limitCommand returns the command and arguments that run the given command under the limits currently in effect.
+func limitCommand(command string, args []string) (string, []string) {
+	shellCommandLimits.mutex.Lock()
+	defer shellCommandLimits.mutex.Unlock()
+	return shellCommandLimits.limits.wrap(command, args)
+}
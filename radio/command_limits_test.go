@@ -0,0 +1,52 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCommandLimits_wrap(t *testing.T) {
+	limits := CommandLimits{
+		Commands: []string{"luci-bwc", "iwinfo"}, Niceness: 10, CpuTimeLimitSec: 5, MemoryLimitMb: 32,
+	}
+
+	command, args := limits.wrap("luci-bwc", []string{"-i", "wlan0"})
+	assert.Equal(t, "sh", command)
+	assert.Equal(
+		t,
+		[]string{"-c", `ulimit -t 5; ulimit -v 32768; exec nice -n 10 "$0" "$@"`, "luci-bwc", "-i", "wlan0"},
+		args,
+	)
+
+	// Commands that aren't listed run unrestricted.
+	command, args = limits.wrap("wifi", []string{"reload", "radio0"})
+	assert.Equal(t, "wifi", command)
+	assert.Equal(t, []string{"reload", "radio0"}, args)
+
+	// Only the limits that are set are applied.
+	limits = CommandLimits{Commands: []string{"iwinfo"}, Niceness: 19}
+	command, args = limits.wrap("iwinfo", []string{"wlan0", "scan"})
+	assert.Equal(t, "sh", command)
+	assert.Equal(t, []string{"-c", `exec nice -n 19 "$0" "$@"`, "iwinfo", "wlan0", "scan"}, args)
+	limits = CommandLimits{Commands: []string{"iwinfo"}, CpuTimeLimitSec: 2}
+	_, args = limits.wrap("iwinfo", nil)
+	assert.Equal(t, []string{"-c", `ulimit -t 2; exec "$0" "$@"`, "iwinfo"}, args)
+
+	// Nothing is wrapped if no limits are set.
+	limits = CommandLimits{Commands: []string{"iwinfo"}}
+	command, args = limits.wrap("iwinfo", []string{"wlan0", "info"})
+	assert.Equal(t, "iwinfo", command)
+	assert.Equal(t, []string{"wlan0", "info"}, args)
+}
+
+func TestRadio_SetSettingsAppliesCommandLimits(t *testing.T) {
+	defer setCommandLimits(CommandLimits{})
+	radio := &Radio{}
+	settings := defaultSettings()
+	settings.MonitoringCommandLimits = CommandLimits{Commands: []string{"iw"}, Niceness: 5}
+	radio.SetSettings(settings)
+
+	command, args := limitCommand("iw", []string{"dev"})
+	assert.Equal(t, "sh", command)
+	assert.Equal(t, []string{"-c", `exec nice -n 5 "$0" "$@"`, "iw", "dev"}, args)
+}
@@ -0,0 +1,54 @@
+package radio
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"unicode"
+)
+
+const (
+	// Maximum number of metadata fields that a configuration request may carry.
+	maxConfigurationMetadataFields = 16
+
+	// Maximum length of each metadata value, in characters.
+	maxConfigurationMetadataValueLength = 128
+)
+
+// Metadata keys are restricted to identifier-like names (e.g. "matchNumber" or "eventCode").
+var configurationMetadataKeyRe = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// validateConfigurationMetadata checks that the given opaque metadata of a configuration request is small enough to be
+// kept with every request record and can be logged and shown without escaping.
+func validateConfigurationMetadata(metadata map[string]string) error {
+	if len(metadata) > maxConfigurationMetadataFields {
+		return fmt.Errorf(
+			"too many metadata fields: %d (expecting at most %d)", len(metadata), maxConfigurationMetadataFields,
+		)
+	}
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !configurationMetadataKeyRe.MatchString(key) {
+			return fmt.Errorf(
+				"invalid metadata key: %q (expecting 1-32 letters, digits, underscores, or hyphens)", key,
+			)
+		}
+		value := []rune(metadata[key])
+		isPrintable := true
+		for _, character := range value {
+			isPrintable = isPrintable && unicode.IsPrint(character)
+		}
+		if !isPrintable || len(value) > maxConfigurationMetadataValueLength {
+			return fmt.Errorf(
+				"invalid value for metadata key %s (expecting up to %d printable characters)",
+				key,
+				maxConfigurationMetadataValueLength,
+			)
+		}
+	}
+	return nil
+}
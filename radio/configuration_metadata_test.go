@@ -0,0 +1,45 @@
+package radio
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfigurationMetadata(t *testing.T) {
+	assert.Nil(t, validateConfigurationMetadata(nil))
+	assert.Nil(
+		t,
+		validateConfigurationMetadata(
+			map[string]string{"matchNumber": "Q12", "eventCode": "2024casj", "operator": "Équipe FTA", "note": ""},
+		),
+	)
+	assert.Nil(t, validateConfigurationMetadata(map[string]string{"note": strings.Repeat("é", 128)}))
+
+	tooMany := make(map[string]string)
+	for i := 0; i < 17; i++ {
+		tooMany[fmt.Sprintf("field%d", i)] = "x"
+	}
+	assert.EqualError(t, validateConfigurationMetadata(tooMany), "too many metadata fields: 17 (expecting at most 16)")
+	assert.EqualError(
+		t,
+		validateConfigurationMetadata(map[string]string{"match number": "12"}),
+		`invalid metadata key: "match number" (expecting 1-32 letters, digits, underscores, or hyphens)`,
+	)
+	assert.EqualError(
+		t,
+		validateConfigurationMetadata(map[string]string{"": "12"}),
+		`invalid metadata key: "" (expecting 1-32 letters, digits, underscores, or hyphens)`,
+	)
+	assert.EqualError(
+		t,
+		validateConfigurationMetadata(map[string]string{"note": strings.Repeat("x", 129)}),
+		"invalid value for metadata key note (expecting up to 128 printable characters)",
+	)
+	assert.EqualError(
+		t,
+		validateConfigurationMetadata(map[string]string{"note": "line 1\nline 2"}),
+		"invalid value for metadata key note (expecting up to 128 printable characters)",
+	)
+}
@@ -0,0 +1,405 @@
+package radio
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Maximum number of configuration request outcomes to retain; the oldest finished ones are discarded first.
+const maxConfigurationRequestRecords = 100
+
+// ErrConfigurationRevisionMismatch is returned when a configuration request is conditional on a revision of the
+// configuration that has since been superseded by another request.
+var ErrConfigurationRevisionMismatch = errors.New("configuration has changed since it was read")
+
+// configurationRequestState represents the progress or outcome of a queued configuration request.
+type configurationRequestState string
+
+const (
+	// The request is waiting in the queue.
+	requestStatePending configurationRequestState = "PENDING"
+
+	// The request is currently being applied.
+	requestStateApplying configurationRequestState = "APPLYING"
+
+	// The request was applied successfully.
+	requestStateApplied configurationRequestState = "APPLIED"
+
+	// Applying the request failed; the error says why.
+	requestStateFailed configurationRequestState = "FAILED"
+
+	// The request was skipped because a later queued request overrides everything it would have changed.
+	requestStateSuperseded configurationRequestState = "SUPERSEDED"
+
+	// The request was cancelled via the API before it was applied.
+	requestStateCancelled configurationRequestState = "CANCELLED"
+)
+
+// ConfigurationRequestRecord represents the progress or outcome of a single queued configuration request.
+type ConfigurationRequestRecord struct {
+	// Identifier assigned to the request when it was queued.
+	Id int `json:"id"`
+
+	// Correlation ID of the HTTP request that submitted the request, or blank if it didn't come from one.
+	CorrelationId string `json:"correlationId"`
+
+	// Opaque metadata that the request carried (e.g. the match number and event code it was for).
+	Metadata map[string]string `json:"metadata"`
+
+	// Progress or outcome of the request.
+	State configurationRequestState `json:"state"`
+
+	// Time at which the request was queued.
+	SubmittedAt Timestamp `json:"submittedAt"`
+
+	// Time at which the request was applied, failed, superseded, or cancelled. Null while it is still pending.
+	FinishedAt *Timestamp `json:"finishedAt"`
+
+	// Identifier of the later request that made this one redundant, or zero if it wasn't superseded.
+	SupersededBy int `json:"supersededBy"`
+
+	// Description of why applying the request failed, or an empty string if it didn't.
+	Error string `json:"error"`
+
+	// Machine-readable kind of the failure, as for the status' lastError, or an empty string if it didn't fail.
+	ErrorKind string `json:"errorKind"`
+
+	// Ways in which the request disagreed with the event schedule for the match slot it declared when it was queued.
+	// Null if it agreed or wasn't checked.
+	ScheduleMismatches []string `json:"scheduleMismatches"`
+}
+
+// configurationRequestLog holds the outcomes of recent configuration requests; it is shared between the radio and web
+// goroutines.
+type configurationRequestLog struct {
+	mutex   sync.Mutex
+	lastId  int
+	records []ConfigurationRequestRecord
+}
+
+// EnqueueConfigurationRequest adds the given request to the queue to be applied asynchronously and returns the
+// identifier under which its outcome is recorded. Returns an error without queueing the request if the queue is full.
+func (radio *Radio) EnqueueConfigurationRequest(request ConfigurationRequest) (int, error) {
+	return radio.enqueueConfigurationRequest(request, nil)
+}
+
+// EnqueueConfigurationRequestIfRevision is like EnqueueConfigurationRequest, but only queues the request if the
+// configuration is still at the given revision, so that a change based on a stale read can't silently clobber a newer
+// one. Returns ErrConfigurationRevisionMismatch otherwise.
+func (radio *Radio) EnqueueConfigurationRequestIfRevision(request ConfigurationRequest, revision int) (int, error) {
+	return radio.enqueueConfigurationRequest(request, &revision)
+}
+
+// enqueueConfigurationRequest queues the given request, checking the expected revision of the configuration first if
+// one is given, and advances the revision to the request's identifier.
+func (radio *Radio) enqueueConfigurationRequest(request ConfigurationRequest, expectedRevision *int) (int, error) {
+	scheduleMismatches := radio.scheduleMismatches(request)
+	requestLog := &radio.configurationRequests
+	requestLog.mutex.Lock()
+	defer requestLog.mutex.Unlock()
+
+	if expectedRevision != nil && *expectedRevision != radio.ConfigurationRevision {
+		return 0, fmt.Errorf(
+			"%w (expected revision %d but it is at %d)",
+			ErrConfigurationRevisionMismatch,
+			*expectedRevision,
+			radio.ConfigurationRevision,
+		)
+	}
+	request.id = requestLog.lastId + 1
+	select {
+	case radio.ConfigurationRequestChannel <- request:
+	default:
+		return 0, errors.New("configuration request queue is full")
+	}
+	requestLog.lastId = request.id
+	radio.ConfigurationRevision = request.id
+	requestLog.records = append(
+		requestLog.records,
+		ConfigurationRequestRecord{
+			Id:                 request.id,
+			CorrelationId:      request.correlationId,
+			Metadata:           request.Metadata,
+			State:              requestStatePending,
+			SubmittedAt:        newTimestamp(),
+			ScheduleMismatches: scheduleMismatches,
+		},
+	)
+	requestLog.trim()
+	if len(scheduleMismatches) > 0 {
+		LogWithCorrelationId(
+			request.correlationId,
+			"Configuration request %d doesn't match the event schedule: %s",
+			request.id,
+			strings.Join(scheduleMismatches, "; "),
+		)
+	}
+	radio.markStatusChanged()
+	return request.id, nil
+}
+
+// WithCorrelationId returns a copy of the request tagged with the given correlation ID, which then prefixes the log
+// lines about applying it and is included in its outcome record.
+func (request ConfigurationRequest) WithCorrelationId(correlationId string) ConfigurationRequest {
+	request.correlationId = correlationId
+	return request
+}
+
+// GetConfigurationRequest returns the record of the configuration request with the given identifier, or false if
+// there is no such request or its record has since been discarded.
+func (radio *Radio) GetConfigurationRequest(id int) (ConfigurationRequestRecord, bool) {
+	requestLog := &radio.configurationRequests
+	requestLog.mutex.Lock()
+	defer requestLog.mutex.Unlock()
+	if record := requestLog.find(id); record != nil {
+		return *record, true
+	}
+	return ConfigurationRequestRecord{}, false
+}
+
+// GetConfigurationRequests returns the records of the most recent configuration requests, oldest first.
+func (radio *Radio) GetConfigurationRequests() []ConfigurationRequestRecord {
+	requestLog := &radio.configurationRequests
+	requestLog.mutex.Lock()
+	defer requestLog.mutex.Unlock()
+	records := make([]ConfigurationRequestRecord, len(requestLog.records))
+	copy(records, requestLog.records)
+	return records
+}
+
+// CancelConfigurationRequest cancels the configuration request with the given identifier so that it is never applied.
+// Only requests that are still pending can be cancelled.
+func (radio *Radio) CancelConfigurationRequest(id int) error {
+	requestLog := &radio.configurationRequests
+	requestLog.mutex.Lock()
+	defer requestLog.mutex.Unlock()
+	record := requestLog.find(id)
+	if record == nil {
+		return fmt.Errorf("no configuration request with ID %d", id)
+	}
+	if record.State != requestStatePending {
+		return fmt.Errorf("configuration request %d cannot be cancelled since it is %s", id, record.State)
+	}
+	record.finish(requestStateCancelled)
+	return nil
+}
+
+// handleConfigurationRequest applies the given request along with any others queued behind it, in the order they
+// were queued, recording the outcome of each. A request is skipped if a later one in the queue overrides everything
+// it would have changed and will itself be applied. Returns the error from the last request that was applied, if any.
+func (radio *Radio) handleConfigurationRequest(request ConfigurationRequest) error {
+	queue := []ConfigurationRequest{request}
+	for numQueued := len(radio.ConfigurationRequestChannel); numQueued > 0; numQueued-- {
+		queue = append(queue, <-radio.ConfigurationRequestChannel)
+	}
+
+	// Each request is only checked once, whether that is when it's considered for superseding an earlier one or when
+	// it's reached, so that a request that earlier ones were skipped for can't be rejected afterwards.
+	checkErrs := make(map[int]error)
+	checkBeforeApplying := func(i int) error {
+		if err, ok := checkErrs[i]; ok {
+			return err
+		}
+		checkErrs[i] = queue[i].checkBeforeApplying(radio)
+		return checkErrs[i]
+	}
+
+	var err error
+	for i, request := range queue {
+		if supersedingId, ok := radio.findSupersedingRequest(queue, i, checkBeforeApplying); ok {
+			LogWithCorrelationId(
+				request.correlationId,
+				"Skipping configuration request %d since request %d supersedes it.",
+				request.id,
+				supersedingId,
+			)
+			radio.configurationRequests.supersede(request.id, supersedingId)
+			continue
+		}
+		if !radio.configurationRequests.start(request.id) {
+			LogWithCorrelationId(request.correlationId, "Skipping cancelled configuration request %d.", request.id)
+			continue
+		}
+		if err = checkBeforeApplying(i); err != nil {
+			LogWithCorrelationId(request.correlationId, "Rejected configuration request %d: %v", request.id, err)
+			radio.configurationRequests.complete(request.id, err)
+			radio.LastError = newConfigurationError(request.id, err)
+			if i == len(queue)-1 && radio.Status == statusConfiguring {
+				// The radio was left as configured by an earlier request in the queue.
+				radio.setStatus(statusActive)
+			}
+			continue
+		}
+		err = radio.applyConfigurationRequest(request, i == len(queue)-1)
+		radio.configurationRequests.complete(request.id, err)
+	}
+	return err
+}
+
+// findSupersedingRequest returns the identifier of the last of the requests queued after the one at the given index
+// that supersedes it, hasn't been cancelled, and passes the given checks, or false if there is none.
+func (radio *Radio) findSupersedingRequest(
+	queue []ConfigurationRequest, index int, checkBeforeApplying func(int) error,
+) (int, bool) {
+	for i := len(queue) - 1; i > index; i-- {
+		if queue[i].supersedes(queue[index]) && !radio.configurationRequests.isCancelled(queue[i].id) &&
+			checkBeforeApplying(i) == nil {
+			return queue[i].id, true
+		}
+	}
+	return 0, false
+}
+
+// applyConfigurationRequest configures the radio with the given request and updates its status accordingly. The
+// status only returns to active once the last queued request has been applied.
+func (radio *Radio) applyConfigurationRequest(request ConfigurationRequest, isLast bool) error {
+	radio.setStatus(statusConfiguring)
+	radio.startConfigurationTimeline(request.id)
+	LogWithCorrelationId(request.correlationId, "Processing configuration request %d: %+v", request.id, request)
+	err := radio.configure(request)
+	radio.lastConfiguredAt = time.Now()
+	radio.updateConfigurationStats(func(stats *ConfigurationStats) {
+		stats.AttemptCount++
+		if err != nil {
+			stats.FailureCount++
+		} else {
+			stats.SuccessCount++
+		}
+	})
+	radio.saveConfigurationStats()
+	if err != nil {
+		LogWithCorrelationId(request.correlationId, "Error configuring radio: %v", err)
+		// Discard any changes that weren't committed so that they don't get committed along with the next request.
+		uciTree.Revert()
+		radio.recordConfigurationStep(configurationStepFailed, 0)
+		radio.LastError = newConfigurationError(request.id, err)
+		radio.setStatus(statusError)
+		radio.raiseAlert(
+			"RADIO_ERROR",
+			"Radio is in the error state after failing to apply configuration request %d: %v",
+			request.id,
+			err,
+		)
+		return err
+	}
+	radio.recordConfigurationStep(configurationStepApplied, 0)
+	radio.LastError = nil
+	radio.ConfigurationMetadata = request.Metadata
+	if isLast && len(radio.ConfigurationRequestChannel) == 0 {
+		radio.setStatus(statusActive)
+	}
+	LogWithCorrelationId(request.correlationId, "Applied configuration request %d.", request.id)
+	return nil
+}
+
+// LogWithCorrelationId logs the given message, prefixed with the given correlation ID if there is one so that it can
+// be traced back to the HTTP request that caused it.
+func LogWithCorrelationId(correlationId string, format string, args ...any) {
+	if correlationId != "" {
+		format = "[request " + correlationId + "] " + format
+	}
+	log.Printf(format, args...)
+}
+
+// start marks the request with the given identifier as being applied, returning false if it has been cancelled.
+// Requests that weren't queued through EnqueueConfigurationRequest have no record and are always applied.
+func (requestLog *configurationRequestLog) start(id int) bool {
+	requestLog.mutex.Lock()
+	defer requestLog.mutex.Unlock()
+	record := requestLog.find(id)
+	if record == nil {
+		return true
+	}
+	if record.State == requestStateCancelled {
+		return false
+	}
+	record.State = requestStateApplying
+	return true
+}
+
+// complete records the outcome of applying the request with the given identifier.
+func (requestLog *configurationRequestLog) complete(id int, err error) {
+	requestLog.mutex.Lock()
+	defer requestLog.mutex.Unlock()
+	if record := requestLog.find(id); record != nil {
+		if err != nil {
+			record.finish(requestStateFailed)
+			record.Error = err.Error()
+			record.ErrorKind = ErrorKind(err)
+		} else {
+			record.finish(requestStateApplied)
+		}
+	}
+}
+
+// supersede records that the request with the given identifier was skipped in favor of the given later one, unless
+// it has already been cancelled.
+func (requestLog *configurationRequestLog) supersede(id int, supersedingId int) {
+	requestLog.mutex.Lock()
+	defer requestLog.mutex.Unlock()
+	if record := requestLog.find(id); record != nil && record.State == requestStatePending {
+		record.finish(requestStateSuperseded)
+		record.SupersededBy = supersedingId
+	}
+}
+
+// cancelPending cancels every request that is still waiting in the queue and returns how many there were.
+func (requestLog *configurationRequestLog) cancelPending() int {
+	requestLog.mutex.Lock()
+	defer requestLog.mutex.Unlock()
+	count := 0
+	for i := range requestLog.records {
+		if requestLog.records[i].State == requestStatePending {
+			requestLog.records[i].finish(requestStateCancelled)
+			count++
+		}
+	}
+	return count
+}
+
+// isCancelled returns true if the request with the given identifier has been cancelled.
+func (requestLog *configurationRequestLog) isCancelled(id int) bool {
+	requestLog.mutex.Lock()
+	defer requestLog.mutex.Unlock()
+	record := requestLog.find(id)
+	return record != nil && record.State == requestStateCancelled
+}
+
+// find returns the record of the request with the given identifier, or nil if there is none. The mutex must be held.
+func (requestLog *configurationRequestLog) find(id int) *ConfigurationRequestRecord {
+	for i := range requestLog.records {
+		if requestLog.records[i].Id == id {
+			return &requestLog.records[i]
+		}
+	}
+	return nil
+}
+
+// trim discards the oldest finished records once there are too many. Records of requests that are still pending or
+// being applied are always kept. The mutex must be held.
+func (requestLog *configurationRequestLog) trim() {
+	excess := len(requestLog.records) - maxConfigurationRequestRecords
+	if excess <= 0 {
+		return
+	}
+	records := requestLog.records[:0]
+	for _, record := range requestLog.records {
+		if excess > 0 && record.FinishedAt != nil {
+			excess--
+			continue
+		}
+		records = append(records, record)
+	}
+	requestLog.records = records
+}
+
+// finish moves the record to the given final state.
+func (record *ConfigurationRequestRecord) finish(state configurationRequestState) {
+	finishedAt := newTimestamp()
+	record.State = state
+	record.FinishedAt = &finishedAt
+}
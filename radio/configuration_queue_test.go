@@ -0,0 +1,134 @@
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRadio_EnqueueConfigurationRequest(t *testing.T) {
+	radio := &Radio{ConfigurationRequestChannel: make(chan ConfigurationRequest, 2)}
+
+	id, err := radio.EnqueueConfigurationRequest(ConfigurationRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, id)
+	id, err = radio.EnqueueConfigurationRequest(ConfigurationRequest{}.WithCorrelationId("0123456789abcdef"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, id)
+	if assert.Equal(t, 2, len(radio.ConfigurationRequestChannel)) {
+		assert.Equal(t, 1, (<-radio.ConfigurationRequestChannel).id)
+		request := <-radio.ConfigurationRequestChannel
+		assert.Equal(t, 2, request.id)
+		assert.Equal(t, "0123456789abcdef", request.correlationId)
+	}
+
+	record, ok := radio.GetConfigurationRequest(2)
+	assert.True(t, ok)
+	assert.Equal(t, requestStatePending, record.State)
+	assert.Equal(t, "0123456789abcdef", record.CorrelationId)
+	assert.False(t, record.SubmittedAt.Wallclock.IsZero())
+	assert.Nil(t, record.FinishedAt)
+	_, ok = radio.GetConfigurationRequest(3)
+	assert.False(t, ok)
+
+	// A full queue rejects the request without consuming an ID or recording it.
+	radio.ConfigurationRequestChannel <- ConfigurationRequest{}
+	radio.ConfigurationRequestChannel <- ConfigurationRequest{}
+	_, err = radio.EnqueueConfigurationRequest(ConfigurationRequest{})
+	assert.EqualError(t, err, "configuration request queue is full")
+	assert.Equal(t, 2, len(radio.GetConfigurationRequests()))
+	<-radio.ConfigurationRequestChannel
+	id, err = radio.EnqueueConfigurationRequest(ConfigurationRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, id)
+}
+
+func TestRadio_EnqueueConfigurationRequestIfRevision(t *testing.T) {
+	radio := &Radio{ConfigurationRequestChannel: make(chan ConfigurationRequest, 3)}
+	assert.Equal(t, 0, radio.ConfigurationRevision)
+
+	id, err := radio.EnqueueConfigurationRequestIfRevision(ConfigurationRequest{}, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, id)
+	assert.Equal(t, 1, radio.ConfigurationRevision)
+
+	// A request based on a stale revision is rejected without being queued.
+	_, err = radio.EnqueueConfigurationRequest(ConfigurationRequest{})
+	assert.Nil(t, err)
+	_, err = radio.EnqueueConfigurationRequestIfRevision(ConfigurationRequest{}, 1)
+	assert.True(t, errors.Is(err, ErrConfigurationRevisionMismatch))
+	assert.EqualError(
+		t, err, "configuration has changed since it was read (expected revision 1 but it is at 2)",
+	)
+	assert.Equal(t, 2, len(radio.ConfigurationRequestChannel))
+	assert.Equal(t, 2, radio.ConfigurationRevision)
+
+	id, err = radio.EnqueueConfigurationRequestIfRevision(ConfigurationRequest{}, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, id)
+	assert.Equal(t, 3, radio.ConfigurationRevision)
+}
+
+func TestRadio_CancelConfigurationRequest(t *testing.T) {
+	radio := &Radio{ConfigurationRequestChannel: make(chan ConfigurationRequest, 2)}
+	id1, _ := radio.EnqueueConfigurationRequest(ConfigurationRequest{})
+	id2, _ := radio.EnqueueConfigurationRequest(ConfigurationRequest{})
+
+	assert.Nil(t, radio.CancelConfigurationRequest(id2))
+	record, _ := radio.GetConfigurationRequest(id2)
+	assert.Equal(t, requestStateCancelled, record.State)
+	assert.NotNil(t, record.FinishedAt)
+	assert.EqualError(
+		t, radio.CancelConfigurationRequest(id2), "configuration request 2 cannot be cancelled since it is CANCELLED",
+	)
+	assert.EqualError(t, radio.CancelConfigurationRequest(5), "no configuration request with ID 5")
+
+	// A cancelled request is neither applied nor allowed to supersede the requests before it.
+	requestLog := &radio.configurationRequests
+	assert.True(t, requestLog.isCancelled(id2))
+	assert.False(t, requestLog.start(id2))
+	queue := []ConfigurationRequest{<-radio.ConfigurationRequestChannel, {id: id2}}
+	_, ok := radio.findSupersedingRequest(queue, 0, func(int) error { return nil })
+	assert.False(t, ok)
+
+	// Requests can no longer be cancelled once they are being applied.
+	assert.True(t, requestLog.start(id1))
+	assert.EqualError(
+		t, radio.CancelConfigurationRequest(id1), "configuration request 1 cannot be cancelled since it is APPLYING",
+	)
+	requestLog.complete(id1, errors.New("oops"))
+	record, _ = radio.GetConfigurationRequest(id1)
+	assert.Equal(t, requestStateFailed, record.State)
+	assert.Equal(t, "oops", record.Error)
+
+	// A cancelled request keeps its outcome even if a later one would have superseded it.
+	requestLog.supersede(id2, 3)
+	record, _ = radio.GetConfigurationRequest(id2)
+	assert.Equal(t, requestStateCancelled, record.State)
+	assert.Equal(t, 0, record.SupersededBy)
+}
+
+func TestConfigurationRequestLog_trim(t *testing.T) {
+	radio := &Radio{ConfigurationRequestChannel: make(chan ConfigurationRequest, 1)}
+	requestLog := &radio.configurationRequests
+
+	// The oldest request stays pending while the rest finish, so it is never discarded.
+	pendingId, _ := radio.EnqueueConfigurationRequest(ConfigurationRequest{})
+	<-radio.ConfigurationRequestChannel
+	for i := 0; i < maxConfigurationRequestRecords+10; i++ {
+		id, err := radio.EnqueueConfigurationRequest(ConfigurationRequest{})
+		assert.Nil(t, err)
+		<-radio.ConfigurationRequestChannel
+		requestLog.start(id)
+		requestLog.complete(id, nil)
+	}
+
+	records := radio.GetConfigurationRequests()
+	if assert.Equal(t, maxConfigurationRequestRecords, len(records)) {
+		assert.Equal(t, pendingId, records[0].Id)
+		assert.Equal(t, requestStatePending, records[0].State)
+		assert.Equal(t, 13, records[1].Id)
+		assert.Equal(t, maxConfigurationRequestRecords+11, records[len(records)-1].Id)
+		assert.Equal(t, requestStateApplied, records[len(records)-1].State)
+	}
+}
@@ -11,9 +11,19 @@ import (
 
 const (
 	maxStationSsidLength = 14
-	stationSsidRegex     = "^[a-zA-Z0-9-]*$"
+	maxBssColor          = 63
+
+	// Range of beacon intervals accepted by hostapd, in time units (TU) of 1.024 ms.
+	minBeaconIntervalTu = 15
+	maxBeaconIntervalTu = 65535
+
+	// Largest DTIM period permitted by the 802.11 standard, in beacons.
+	maxDtimPeriod = 255
 )
 
+// Match slot labels are restricted to characters that can be logged and included in alerts without escaping.
+var matchSlotRe = regexp.MustCompile(`^[A-Za-z0-9 ._#-]{0,32}$`)
+
 // ConfigurationRequest represents a JSON request to configure the radio.
 type ConfigurationRequest struct {
 	// 5GHz or 6GHz channel number for the radio to use. Set to 0 to leave unchanged.
@@ -30,11 +40,74 @@ type ConfigurationRequest struct {
 	BlueVlans AllianceVlans `json:"blueVlans"`
 
 	// SSID and WPA key for each team station, keyed by alliance and number (e.g. "red1", "blue3).
-	// A null value indicates the station should be unconfigured.
+	// A null value indicates the station should be unconfigured. Stations that are omitted are also unconfigured unless
+	// PreserveOmittedStations is set.
 	StationConfigurations map[string]*StationConfiguration `json:"stationConfigurations"`
 
+	// Whether stations omitted from StationConfigurations should keep their current configuration rather than being
+	// unconfigured. Allows a single station to be assigned or cleared without resending the other five.
+	PreserveOmittedStations bool `json:"preserveOmittedStations"`
+
 	// IP address of the syslog server to send logs to (via UDP on port 514).
 	SyslogIpAddress string `json:"syslogIpAddress"`
+
+	// ISO 3166-1 alpha-2 code of the country whose wireless regulatory domain the radio should operate under (e.g.
+	// "US"). Set to an empty string to leave unchanged.
+	Country string `json:"country"`
+
+	// 802.11ax BSS color for the radio to use, between 1 and 63. Set to 0 to leave unchanged. Only supported on
+	// Vivid-Hosting radios.
+	BssColor int `json:"bssColor"`
+
+	// 802.11ax guard interval for the radio to use. Valid values are "0.8us", "1.6us", and "3.2us". Set to an empty
+	// string to leave unchanged. Only supported on Vivid-Hosting radios.
+	HeGuardInterval string `json:"heGuardInterval"`
+
+	// Whether the radio should enable 802.11ax target wake time. Omit to leave unchanged. Only supported on
+	// Vivid-Hosting radios.
+	TargetWakeTime *bool `json:"targetWakeTime"`
+
+	// Interval between beacons for the radio to use, in time units (TU) of 1.024 ms, between 15 and 65535. Set to 0 to
+	// leave unchanged.
+	BeaconIntervalTu int `json:"beaconIntervalTu"`
+
+	// Number of beacons between each delivery of buffered broadcast and multicast traffic to clients in power save,
+	// between 1 and 255. Set to 0 to leave unchanged.
+	DtimPeriod int `json:"dtimPeriod"`
+
+	// Whether to block traffic between team clients, both directly through the access point and forwarded between the
+	// team VLANs by the firewall. Omit to leave unchanged.
+	ClientIsolation *bool `json:"clientIsolation"`
+
+	// Whether the access point should serve the time over NTP to the robot radios and other devices on the team VLANs,
+	// for venues without internet access. Omit to leave unchanged.
+	TimeServer *bool `json:"timeServer"`
+
+	// 802.11w management frame protection mode to set on every station: "REQUIRED", "OPTIONAL", or "DISABLED".
+	// Stations that specify their own mode are exempt. Leave blank to leave unchanged.
+	ManagementFrameProtection managementFrameProtection `json:"managementFrameProtection"`
+
+	// Whether to skip the channel utilization check that may otherwise reject a change onto a busier channel.
+	OverrideChannelGuard bool `json:"overrideChannelGuard"`
+
+	// Label of the match or other slot in the schedule that the configuration is for (e.g. "Q12"), used to say where
+	// a reused WPA key was seen before. Leave blank to have the configuration identified by its request ID instead.
+	MatchSlot string `json:"matchSlot"`
+
+	// Opaque fields identifying what the configuration is for (e.g. "matchNumber", "eventCode", or "operator"), which
+	// the radio keeps with the configuration once it is applied and echoes in the status and request records.
+	Metadata map[string]string `json:"metadata"`
+
+	// Identifier assigned when the request was queued, under which its outcome is recorded. Zero if it wasn't queued.
+	id int
+
+	// Correlation ID of the HTTP request that submitted the request, or blank if it didn't come from one.
+	correlationId string
+
+	// Whether every station keeps its current configuration, for requests built from the changes to the effective
+	// configuration that don't change any station. Clients set PreserveOmittedStations instead, which needs at least
+	// one station.
+	preserveAllStations bool
 }
 
 // StationConfiguration represents the configuration for a single team station.
@@ -44,16 +117,31 @@ type StationConfiguration struct {
 
 	// Team-specific WPA key for the station. Must be at least eight characters long.
 	WpaKey string `json:"wpaKey"`
+
+	// 802.11w management frame protection mode for the station, overriding any mode given for the whole device. Leave
+	// blank to leave unchanged.
+	ManagementFrameProtection managementFrameProtection `json:"managementFrameProtection,omitempty"`
 }
 
 var validLinksysChannels = []int{36, 40, 44, 48, 149, 153, 157, 161, 165}
 
+// IsEmpty returns true if the request would not change anything.
+func (request ConfigurationRequest) IsEmpty() bool {
+	return request.Channel == 0 && request.ChannelBandwidth == "" && len(request.StationConfigurations) == 0 &&
+		request.RedVlans == "" && request.BlueVlans == "" && request.SyslogIpAddress == "" && request.Country == "" &&
+		request.BssColor == 0 && request.HeGuardInterval == "" && request.TargetWakeTime == nil &&
+		request.BeaconIntervalTu == 0 && request.DtimPeriod == 0 && request.ClientIsolation == nil &&
+		request.TimeServer == nil && request.ManagementFrameProtection == ""
+}
+
 // Validate checks that all parameters within the configuration request have valid values.
 func (request ConfigurationRequest) Validate(radio *Radio) error {
-	if request.Channel == 0 && request.ChannelBandwidth == "" && len(request.StationConfigurations) == 0 &&
-		request.RedVlans == "" && request.BlueVlans == "" && request.SyslogIpAddress == "" {
+	if request.IsEmpty() {
 		return errors.New("empty configuration request")
 	}
+	if request.PreserveOmittedStations && len(request.StationConfigurations) == 0 {
+		return errors.New("preserveOmittedStations requires at least one station configuration")
+	}
 
 	if request.Channel != 0 {
 		// Validate channel number.
@@ -70,10 +158,28 @@ func (request ConfigurationRequest) Validate(radio *Radio) error {
 			valid = isValid6GhzChannel(request.Channel)
 		}
 		if !valid {
-			return fmt.Errorf("invalid channel for %s: %d", radio.Type.String(), request.Channel)
+			return withKind(
+				ErrInvalidChannel, fmt.Errorf("invalid channel for %s: %d", radio.Type.String(), request.Channel),
+			)
 		}
 	}
 
+	if request.Country != "" && !isValidCountry(request.Country) {
+		return fmt.Errorf("invalid country: %s", request.Country)
+	}
+	channel, country := radio.getChannelAndCountry()
+	if request.Country != "" {
+		country = request.Country
+	}
+	if request.Channel != 0 {
+		channel = request.Channel
+	}
+	if country != "" && channel != 0 && !isChannelPermitted(country, radio.Type, channel) {
+		return withKind(
+			ErrInvalidChannel, fmt.Errorf("channel %d is not permitted in regulatory domain %s", channel, country),
+		)
+	}
+
 	if request.ChannelBandwidth != "" {
 		// Validate channel bandwidth.
 		if radio.Type == TypeLinksys {
@@ -84,6 +190,47 @@ func (request ConfigurationRequest) Validate(radio *Radio) error {
 		}
 	}
 
+	if request.BssColor != 0 || request.HeGuardInterval != "" || request.TargetWakeTime != nil {
+		// Validate 802.11ax options.
+		if !hardwareCapabilityTable[radio.Type].supportsHeOptions {
+			return fmt.Errorf("802.11ax options cannot be changed on %s", radio.Type.String())
+		}
+		if request.BssColor < 0 || request.BssColor > maxBssColor {
+			return fmt.Errorf("invalid BSS color: %d (expecting 1-%d)", request.BssColor, maxBssColor)
+		}
+		if request.HeGuardInterval != "" {
+			valid := false
+			for _, guardInterval := range validHeGuardIntervals {
+				if request.HeGuardInterval == guardInterval {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				return fmt.Errorf("invalid HE guard interval: %s", request.HeGuardInterval)
+			}
+		}
+	}
+
+	if request.BeaconIntervalTu != 0 || request.DtimPeriod != 0 {
+		// Validate beacon timing.
+		if !hardwareCapabilityTable[radio.Type].supportsBeaconTiming {
+			return fmt.Errorf("beacon timing cannot be changed on %s", radio.Type.String())
+		}
+		if request.BeaconIntervalTu != 0 &&
+			(request.BeaconIntervalTu < minBeaconIntervalTu || request.BeaconIntervalTu > maxBeaconIntervalTu) {
+			return fmt.Errorf(
+				"invalid beacon interval: %d (expecting %d-%d)",
+				request.BeaconIntervalTu,
+				minBeaconIntervalTu,
+				maxBeaconIntervalTu,
+			)
+		}
+		if request.DtimPeriod < 0 || request.DtimPeriod > maxDtimPeriod {
+			return fmt.Errorf("invalid DTIM period: %d (expecting 1-%d)", request.DtimPeriod, maxDtimPeriod)
+		}
+	}
+
 	if request.RedVlans != "" || request.BlueVlans != "" {
 		if request.RedVlans == "" || request.BlueVlans == "" {
 			return errors.New("both red and blue VLANs must be specified")
@@ -101,6 +248,7 @@ func (request ConfigurationRequest) Validate(radio *Radio) error {
 	}
 
 	// Validate station configurations.
+	charset := radio.GetSettings().CredentialCharset
 	for stationName, stationConfiguration := range request.StationConfigurations {
 		stationNameValid := false
 		for name := red1; name <= blue3; name++ {
@@ -129,8 +277,12 @@ func (request ConfigurationRequest) Validate(radio *Radio) error {
 				maxStationSsidLength,
 			)
 		}
-		if !regexp.MustCompile(stationSsidRegex).MatchString(stationConfiguration.Ssid) {
-			return fmt.Errorf("invalid SSID for station %s (expecting alphanumeric with hyphens)", stationName)
+		if !charset.allows(stationConfiguration.Ssid, true, "-") {
+			return fmt.Errorf(
+				"invalid SSID for station %s (expecting %s)",
+				stationName,
+				charset.expecting(true, "alphanumeric with hyphens"),
+			)
 		}
 		if len(stationConfiguration.WpaKey) < minWpaKeyLength || len(stationConfiguration.WpaKey) > maxWpaKeyLength {
 			return fmt.Errorf(
@@ -141,11 +293,29 @@ func (request ConfigurationRequest) Validate(radio *Radio) error {
 				maxWpaKeyLength,
 			)
 		}
-		if !regexp.MustCompile(alphanumericRegex).MatchString(stationConfiguration.WpaKey) {
-			return fmt.Errorf("invalid WPA key for station %s (expecting alphanumeric)", stationName)
+		if !charset.allows(stationConfiguration.WpaKey, false, "") {
+			return fmt.Errorf(
+				"invalid WPA key for station %s (expecting %s)", stationName, charset.expecting(false, "alphanumeric"),
+			)
 		}
 	}
 
+	if err := request.validateManagementFrameProtection(radio); err != nil {
+		return err
+	}
+
+	if !matchSlotRe.MatchString(request.MatchSlot) {
+		return fmt.Errorf(
+			"invalid match slot: %q (expecting up to 32 letters, digits, spaces, periods, underscores, hashes, or "+
+				"hyphens)",
+			request.MatchSlot,
+		)
+	}
+
+	if err := validateConfigurationMetadata(request.Metadata); err != nil {
+		return err
+	}
+
 	// Validate syslog IP address.
 	if request.SyslogIpAddress != "" {
 		match, _ := regexp.MatchString("^((25[0-5]|(2[0-4]|1\\d|[1-9]|)\\d)\\.?\\b){4}$", request.SyslogIpAddress)
@@ -156,3 +326,52 @@ func (request ConfigurationRequest) Validate(radio *Radio) error {
 
 	return nil
 }
+
+// checkBeforeApplying performs the checks on the request that depend on the radio's surroundings at the time it is
+// applied rather than on the request alone, returning an error if it should be rejected. Unlike Validate, this may
+// take a while and must only be called from the radio's main loop.
+func (request ConfigurationRequest) checkBeforeApplying(radio *Radio) error {
+	// Check that the channel change wouldn't move onto busier spectrum.
+	if request.Channel != 0 && !request.OverrideChannelGuard {
+		return radio.checkChannelChange(request.Channel)
+	}
+	return nil
+}
+
+// preservesOmittedStations returns true if stations omitted from the request keep their current configuration rather
+// than being unconfigured.
+func (request ConfigurationRequest) preservesOmittedStations() bool {
+	return request.PreserveOmittedStations || request.preserveAllStations
+}
+
+// supersedes returns true if this request overrides everything that the given earlier request would change, so that
+// applying this request alone has the same effect as applying both in order. A request that preserves omitted stations
+// never does, and neither does one that leaves unchanged a setting that the earlier one changes.
+func (request ConfigurationRequest) supersedes(earlier ConfigurationRequest) bool {
+	if request.preservesOmittedStations() {
+		return false
+	}
+	overridesSettings := (earlier.Channel == 0 || request.Channel != 0) &&
+		(earlier.ChannelBandwidth == "" || request.ChannelBandwidth != "") &&
+		(earlier.RedVlans == "" || request.RedVlans != "") &&
+		(earlier.BlueVlans == "" || request.BlueVlans != "") &&
+		(earlier.SyslogIpAddress == "" || request.SyslogIpAddress != "") &&
+		(earlier.Country == "" || request.Country != "") &&
+		(earlier.BssColor == 0 || request.BssColor != 0) &&
+		(earlier.HeGuardInterval == "" || request.HeGuardInterval != "") &&
+		(earlier.TargetWakeTime == nil || request.TargetWakeTime != nil) &&
+		(earlier.BeaconIntervalTu == 0 || request.BeaconIntervalTu != 0) &&
+		(earlier.DtimPeriod == 0 || request.DtimPeriod != 0) &&
+		(earlier.ClientIsolation == nil || request.ClientIsolation != nil) &&
+		(earlier.TimeServer == nil || request.TimeServer != nil)
+	if !overridesSettings {
+		return false
+	}
+	for station := red1; station <= blue3; station++ {
+		if earlier.requestedManagementFrameProtection(station) != "" &&
+			request.requestedManagementFrameProtection(station) == "" {
+			return false
+		}
+	}
+	return true
+}
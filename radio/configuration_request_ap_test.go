@@ -5,6 +5,7 @@ package radio
 
 import (
 	"github.com/stretchr/testify/assert"
+	"reflect"
 	"testing"
 )
 
@@ -17,10 +18,17 @@ func TestConfigurationRequest_Validate(t *testing.T) {
 	err := request.Validate(linksysRadio)
 	assert.EqualError(t, err, "empty configuration request")
 
+	// Preserving omitted stations without specifying any.
+	request = ConfigurationRequest{Channel: 36, PreserveOmittedStations: true}
+	err = request.Validate(linksysRadio)
+	assert.EqualError(t, err, "preserveOmittedStations requires at least one station configuration")
+	request = ConfigurationRequest{}
+
 	// Invalid 5GHz channel.
 	request.Channel = 5
 	err = request.Validate(linksysRadio)
 	assert.EqualError(t, err, "invalid channel for TypeLinksys: 5")
+	assert.ErrorIs(t, err, ErrInvalidChannel)
 
 	// Invalid 6GHz channel.
 	request.Channel = 36
@@ -37,6 +45,25 @@ func TestConfigurationRequest_Validate(t *testing.T) {
 	err = request.Validate(linksysRadio)
 	assert.EqualError(t, err, "channel bandwidth cannot be changed on TypeLinksys")
 
+	// 802.11ax options.
+	enabled := true
+	request = ConfigurationRequest{BssColor: 12, HeGuardInterval: "1.6us", TargetWakeTime: &enabled}
+	assert.Nil(t, request.Validate(vividHostingRadio))
+	err = request.Validate(linksysRadio)
+	assert.EqualError(t, err, "802.11ax options cannot be changed on TypeLinksys")
+	request = ConfigurationRequest{TargetWakeTime: &enabled}
+	err = request.Validate(linksysRadio)
+	assert.EqualError(t, err, "802.11ax options cannot be changed on TypeLinksys")
+	request = ConfigurationRequest{BssColor: 64}
+	err = request.Validate(vividHostingRadio)
+	assert.EqualError(t, err, "invalid BSS color: 64 (expecting 1-63)")
+	request = ConfigurationRequest{BssColor: -1}
+	err = request.Validate(vividHostingRadio)
+	assert.EqualError(t, err, "invalid BSS color: -1 (expecting 1-63)")
+	request = ConfigurationRequest{HeGuardInterval: "0.4us"}
+	err = request.Validate(vividHostingRadio)
+	assert.EqualError(t, err, "invalid HE guard interval: 0.4us")
+
 	// Invalid VLANs.
 	request = ConfigurationRequest{RedVlans: "10_20_30"}
 	err = request.Validate(linksysRadio)
@@ -56,49 +83,49 @@ func TestConfigurationRequest_Validate(t *testing.T) {
 
 	// Invalid station.
 	request = ConfigurationRequest{
-		StationConfigurations: map[string]StationConfiguration{"red4": {Ssid: "254", WpaKey: "12345678"}},
+		StationConfigurations: map[string]*StationConfiguration{"red4": {Ssid: "254", WpaKey: "12345678"}},
 	}
 	err = request.Validate(linksysRadio)
 	assert.EqualError(t, err, "invalid station: red4")
 
 	// Blank SSID.
 	request = ConfigurationRequest{
-		StationConfigurations: map[string]StationConfiguration{"blue1": {Ssid: "", WpaKey: "12345678"}},
+		StationConfigurations: map[string]*StationConfiguration{"blue1": {Ssid: "", WpaKey: "12345678"}},
 	}
 	err = request.Validate(linksysRadio)
 	assert.EqualError(t, err, "SSID for station blue1 cannot be blank")
 
 	// Too-long SSID.
 	request = ConfigurationRequest{
-		StationConfigurations: map[string]StationConfiguration{"blue1": {Ssid: "12345-longsuffix", WpaKey: "12345678"}},
+		StationConfigurations: map[string]*StationConfiguration{"blue1": {Ssid: "12345-longsuffix", WpaKey: "12345678"}},
 	}
 	err = request.Validate(linksysRadio)
 	assert.EqualError(t, err, "invalid SSID length for station blue1: 16 (expecting 1-14)")
 
 	// Invalid characters in SSID.
 	request = ConfigurationRequest{
-		StationConfigurations: map[string]StationConfiguration{"blue1": {Ssid: "abc_XYZ", WpaKey: "12345678"}},
+		StationConfigurations: map[string]*StationConfiguration{"blue1": {Ssid: "abc_XYZ", WpaKey: "12345678"}},
 	}
 	err = request.Validate(linksysRadio)
 	assert.EqualError(t, err, "invalid SSID for station blue1 (expecting alphanumeric with hyphens)")
 
 	// Too-short WPA key.
 	request = ConfigurationRequest{
-		StationConfigurations: map[string]StationConfiguration{"blue1": {Ssid: "12345-suffix", WpaKey: "1234567"}},
+		StationConfigurations: map[string]*StationConfiguration{"blue1": {Ssid: "12345-suffix", WpaKey: "1234567"}},
 	}
 	err = request.Validate(linksysRadio)
 	assert.EqualError(t, err, "invalid WPA key length for station blue1: 7 (expecting 8-16)")
 
 	// Too-long WPA key.
 	request = ConfigurationRequest{
-		StationConfigurations: map[string]StationConfiguration{"blue1": {Ssid: "254", WpaKey: "12345678123456789"}},
+		StationConfigurations: map[string]*StationConfiguration{"blue1": {Ssid: "254", WpaKey: "12345678123456789"}},
 	}
 	err = request.Validate(linksysRadio)
 	assert.EqualError(t, err, "invalid WPA key length for station blue1: 17 (expecting 8-16)")
 
 	// Invalid characters in WPA key.
 	request = ConfigurationRequest{
-		StationConfigurations: map[string]StationConfiguration{"blue1": {Ssid: "254", WpaKey: "aAbC2__+#"}},
+		StationConfigurations: map[string]*StationConfiguration{"blue1": {Ssid: "254", WpaKey: "aAbC2__+#"}},
 	}
 	err = request.Validate(linksysRadio)
 	assert.EqualError(t, err, "invalid WPA key for station blue1 (expecting alphanumeric)")
@@ -107,4 +134,194 @@ func TestConfigurationRequest_Validate(t *testing.T) {
 	request = ConfigurationRequest{SyslogIpAddress: "10.0.100.256"}
 	err = request.Validate(linksysRadio)
 	assert.EqualError(t, err, "invalid syslog IP address: 10.0.100.256")
+
+	// Invalid match slot.
+	request = ConfigurationRequest{SyslogIpAddress: "10.0.100.40", MatchSlot: "Q12"}
+	assert.Nil(t, request.Validate(linksysRadio))
+	request = ConfigurationRequest{SyslogIpAddress: "10.0.100.40", MatchSlot: "Q12\n"}
+	err = request.Validate(linksysRadio)
+	assert.EqualError(
+		t,
+		err,
+		"invalid match slot: \"Q12\\n\" (expecting up to 32 letters, digits, spaces, periods, underscores, hashes, "+
+			"or hyphens)",
+	)
+
+	// Invalid metadata.
+	request = ConfigurationRequest{SyslogIpAddress: "10.0.100.40", Metadata: map[string]string{"match number": "12"}}
+	err = request.Validate(linksysRadio)
+	assert.EqualError(
+		t, err, `invalid metadata key: "match number" (expecting 1-32 letters, digits, underscores, or hyphens)`,
+	)
+
+	// Invalid country.
+	request = ConfigurationRequest{Country: "XX"}
+	err = request.Validate(linksysRadio)
+	assert.EqualError(t, err, "invalid country: XX")
+
+	// Channel not permitted in the requested regulatory domain.
+	request = ConfigurationRequest{Channel: 149, Country: "GB"}
+	err = request.Validate(linksysRadio)
+	assert.EqualError(t, err, "channel 149 is not permitted in regulatory domain GB")
+	request = ConfigurationRequest{Channel: 101, Country: "DE"}
+	err = request.Validate(vividHostingRadio)
+	assert.EqualError(t, err, "channel 101 is not permitted in regulatory domain DE")
+
+	// Channel not permitted in the current regulatory domain.
+	request = ConfigurationRequest{Channel: 149}
+	err = request.Validate(&Radio{Type: TypeLinksys, Country: "IL"})
+	assert.EqualError(t, err, "channel 149 is not permitted in regulatory domain IL")
+
+	// Current channel not permitted in the requested regulatory domain.
+	request = ConfigurationRequest{Country: "CN"}
+	err = request.Validate(&Radio{Type: TypeVividHosting, Channel: 5})
+	assert.EqualError(t, err, "channel 5 is not permitted in regulatory domain CN")
+
+	// Valid country and channel combinations.
+	request = ConfigurationRequest{Channel: 36, Country: "GB"}
+	assert.Nil(t, request.Validate(linksysRadio))
+	request = ConfigurationRequest{Channel: 229, Country: "US"}
+	assert.Nil(t, request.Validate(vividHostingRadio))
+}
+
+func TestConfigurationRequest_ValidateBeaconTiming(t *testing.T) {
+	radio := &Radio{Type: TypeLinksys}
+	assert.Nil(t, ConfigurationRequest{BeaconIntervalTu: 100, DtimPeriod: 1}.Validate(radio))
+	assert.Nil(t, ConfigurationRequest{DtimPeriod: 255}.Validate(radio))
+
+	err := ConfigurationRequest{BeaconIntervalTu: 14}.Validate(radio)
+	assert.EqualError(t, err, "invalid beacon interval: 14 (expecting 15-65535)")
+	err = ConfigurationRequest{BeaconIntervalTu: 65536}.Validate(radio)
+	assert.EqualError(t, err, "invalid beacon interval: 65536 (expecting 15-65535)")
+	err = ConfigurationRequest{BeaconIntervalTu: -100}.Validate(radio)
+	assert.EqualError(t, err, "invalid beacon interval: -100 (expecting 15-65535)")
+	err = ConfigurationRequest{DtimPeriod: 256}.Validate(radio)
+	assert.EqualError(t, err, "invalid DTIM period: 256 (expecting 1-255)")
+	err = ConfigurationRequest{DtimPeriod: -1}.Validate(radio)
+	assert.EqualError(t, err, "invalid DTIM period: -1 (expecting 1-255)")
+
+	// Hardware that doesn't support configuring beacon timing rejects it.
+	err = ConfigurationRequest{DtimPeriod: 1}.Validate(&Radio{Type: TypeUnknown})
+	assert.EqualError(t, err, "beacon timing cannot be changed on TypeUnknown")
+}
+
+func TestConfigurationRequest_ValidateExtendedCharsets(t *testing.T) {
+	radio := &Radio{Type: TypeVividHosting, settings: defaultSettings()}
+	request := ConfigurationRequest{
+		StationConfigurations: map[string]*StationConfiguration{"red1": {Ssid: "Field A #2", WpaKey: "p@ss w0rd!"}},
+	}
+	err := request.Validate(radio)
+	assert.EqualError(t, err, "invalid SSID for station red1 (expecting alphanumeric with hyphens)")
+
+	radio.settings.CredentialCharset = credentialCharsetPrintableAscii
+	assert.Nil(t, request.Validate(radio))
+	request.StationConfigurations["red1"].Ssid = "Équipe"
+	err = request.Validate(radio)
+	assert.EqualError(t, err, `invalid SSID for station red1 (expecting printable ASCII other than ' and \)`)
+
+	radio.settings.CredentialCharset = credentialCharsetUtf8
+	assert.Nil(t, request.Validate(radio))
+	request.StationConfigurations["red1"].Ssid = "team's"
+	err = request.Validate(radio)
+	assert.EqualError(t, err, `invalid SSID for station red1 (expecting UTF-8 other than control characters, ' and \)`)
+	request.StationConfigurations["red1"].Ssid = "Équipe"
+	request.StationConfigurations["red1"].WpaKey = "clé secrète"
+	err = request.Validate(radio)
+	assert.EqualError(t, err, `invalid WPA key for station red1 (expecting printable ASCII other than ' and \)`)
+}
+
+func TestConfigurationRequest_supersedes(t *testing.T) {
+	enabled := true
+	full := ConfigurationRequest{
+		Channel: 149,
+		StationConfigurations: map[string]*StationConfiguration{
+			"red1": {Ssid: "1111", WpaKey: "11111111"},
+			"red2": {Ssid: "2222", WpaKey: "22222222"},
+		},
+	}
+	clearRed1 := ConfigurationRequest{
+		StationConfigurations: map[string]*StationConfiguration{"red1": nil}, PreserveOmittedStations: true,
+	}
+
+	// A later full request supersedes a partial one, but not the other way around.
+	assert.True(t, full.supersedes(clearRed1))
+	assert.False(t, clearRed1.supersedes(full))
+	assert.False(t, clearRed1.supersedes(clearRed1))
+
+	// A full request doesn't supersede one that changes a setting it leaves unchanged.
+	assert.True(t, full.supersedes(ConfigurationRequest{Channel: 36}))
+	assert.False(t, ConfigurationRequest{}.supersedes(full))
+	assert.False(t, full.supersedes(ConfigurationRequest{Country: "GB"}))
+	assert.False(t, full.supersedes(ConfigurationRequest{ClientIsolation: &enabled}))
+	assert.False(t, full.supersedes(ConfigurationRequest{TimeServer: &enabled}))
+	assert.True(
+		t,
+		ConfigurationRequest{Country: "US", ClientIsolation: &enabled}.supersedes(
+			ConfigurationRequest{Country: "GB", ClientIsolation: &enabled},
+		),
+	)
+
+	// Management frame protection may be overridden either for the whole device or per station.
+	perStation := ConfigurationRequest{
+		StationConfigurations: map[string]*StationConfiguration{
+			"red1": {Ssid: "1111", WpaKey: "11111111", ManagementFrameProtection: managementFrameProtectionRequired},
+		},
+	}
+	assert.False(t, full.supersedes(perStation))
+	deviceWide := ConfigurationRequest{ManagementFrameProtection: managementFrameProtectionOptional}
+	assert.True(t, deviceWide.supersedes(perStation))
+	assert.False(t, perStation.supersedes(deviceWide))
+}
+
+func TestConfigurationRequest_supersedesCoversAllFields(t *testing.T) {
+	// Fields that don't describe a setting which an earlier request could leave changed, or whose supersession is
+	// covered above.
+	specialFields := map[string]bool{
+		"StationConfigurations":   true,
+		"PreserveOmittedStations": true,
+		"OverrideChannelGuard":    true,
+		"MatchSlot":               true,
+		"Metadata":                true,
+	}
+
+	requestType := reflect.TypeOf(ConfigurationRequest{})
+	for i := 0; i < requestType.NumField(); i++ {
+		field := requestType.Field(i)
+		if !field.IsExported() || specialFields[field.Name] {
+			continue
+		}
+		value, ok := nonZeroValue(field.Type)
+		if !assert.True(t, ok, "no test value for field %s of type %s", field.Name, field.Type) {
+			continue
+		}
+		var earlier, later ConfigurationRequest
+		reflect.ValueOf(&earlier).Elem().Field(i).Set(value)
+		assert.False(t, later.supersedes(earlier), "supersedes doesn't account for field %s", field.Name)
+		reflect.ValueOf(&later).Elem().Field(i).Set(value)
+		assert.True(t, later.supersedes(earlier), "supersedes doesn't account for field %s", field.Name)
+	}
+}
+
+// nonZeroValue returns an arbitrary non-zero value of the given type, or false if the type isn't supported.
+func nonZeroValue(valueType reflect.Type) (reflect.Value, bool) {
+	value := reflect.New(valueType).Elem()
+	switch valueType.Kind() {
+	case reflect.Bool:
+		value.SetBool(true)
+	case reflect.Int:
+		value.SetInt(1)
+	case reflect.String:
+		value.SetString("x")
+	case reflect.Pointer:
+		element, ok := nonZeroValue(valueType.Elem())
+		if !ok {
+			return value, false
+		}
+		pointer := reflect.New(valueType.Elem())
+		pointer.Elem().Set(element)
+		value.Set(pointer)
+	default:
+		return value, false
+	}
+	return value, true
 }
@@ -4,18 +4,11 @@
 package radio
 
 import (
-	"errors"
 	"fmt"
-	"regexp"
 )
 
-const (
-	// Maximum length for the SSID suffix.
-	maxSsidSuffixLength = 8
-
-	// Regex to validate the SSID suffix.
-	ssidSuffixRegex = "^[a-zA-Z0-9]*$"
-)
+// Maximum length for the SSID suffix.
+const maxSsidSuffixLength = 8
 
 // ConfigurationRequest represents a JSON request to configure the radio.
 type ConfigurationRequest struct {
@@ -38,6 +31,16 @@ type ConfigurationRequest struct {
 	// WPA key for the 2.4GHz network broadcast by the radio for team use. Must be at least eight alphanumeric
 	// characters long.
 	WpaKey24 string `json:"wpaKey24"`
+
+	// Opaque fields identifying what the configuration is for (e.g. "eventCode" or "operator"), which the radio keeps
+	// with the configuration once it is applied and echoes in the status and request records.
+	Metadata map[string]string `json:"metadata"`
+
+	// Identifier assigned when the request was queued, under which its outcome is recorded. Zero if it wasn't queued.
+	id int
+
+	// Correlation ID of the HTTP request that submitted the request, or blank if it didn't come from one.
+	correlationId string
 }
 
 // Validate checks that all parameters within the configuration request have valid values.
@@ -47,10 +50,10 @@ func (request ConfigurationRequest) Validate(radio *Radio) error {
 	}
 
 	if request.Mode == modeTeamRobotRadio && request.Channel != 0 {
-		return fmt.Errorf("channel cannot be set in %s mode", modeTeamRobotRadio)
+		return withKind(ErrInvalidChannel, fmt.Errorf("channel cannot be set in %s mode", modeTeamRobotRadio))
 	}
 	if request.Mode == modeTeamAccessPoint && request.Channel != 0 && !isValid6GhzChannel(request.Channel) {
-		return fmt.Errorf("invalid 6GHz channel: %d", request.Channel)
+		return withKind(ErrInvalidChannel, fmt.Errorf("invalid 6GHz channel: %d", request.Channel))
 	}
 
 	if request.TeamNumber < 1 || request.TeamNumber > 25499 {
@@ -62,8 +65,9 @@ func (request ConfigurationRequest) Validate(radio *Radio) error {
 			"invalid ssidSuffix length: %d (expecting 0-%d)", len(request.SsidSuffix), maxSsidSuffixLength,
 		)
 	}
-	if !regexp.MustCompile(ssidSuffixRegex).MatchString(request.SsidSuffix) {
-		return errors.New("invalid ssidSuffix (expecting alphanumeric)")
+	charset := radio.GetSettings().CredentialCharset
+	if !charset.allows(request.SsidSuffix, true, "") {
+		return fmt.Errorf("invalid ssidSuffix (expecting %s)", charset.expecting(true, "alphanumeric"))
 	}
 
 	if len(request.WpaKey6) < minWpaKeyLength || len(request.WpaKey6) > maxWpaKeyLength {
@@ -71,8 +75,8 @@ func (request ConfigurationRequest) Validate(radio *Radio) error {
 			"invalid wpaKey6 length: %d (expecting %d-%d)", len(request.WpaKey6), minWpaKeyLength, maxWpaKeyLength,
 		)
 	}
-	if !regexp.MustCompile(alphanumericRegex).MatchString(request.WpaKey6) {
-		return errors.New("invalid wpaKey6 (expecting alphanumeric)")
+	if !charset.allows(request.WpaKey6, false, "") {
+		return fmt.Errorf("invalid wpaKey6 (expecting %s)", charset.expecting(false, "alphanumeric"))
 	}
 
 	if len(request.WpaKey24) < minWpaKeyLength || len(request.WpaKey24) > maxWpaKeyLength {
@@ -80,9 +84,21 @@ func (request ConfigurationRequest) Validate(radio *Radio) error {
 			"invalid wpaKey24 length: %d (expecting %d-%d)", len(request.WpaKey24), minWpaKeyLength, maxWpaKeyLength,
 		)
 	}
-	if !regexp.MustCompile(alphanumericRegex).MatchString(request.WpaKey24) {
-		return errors.New("invalid wpaKey24 (expecting alphanumeric)")
+	if !charset.allows(request.WpaKey24, false, "") {
+		return fmt.Errorf("invalid wpaKey24 (expecting %s)", charset.expecting(false, "alphanumeric"))
 	}
 
+	return validateConfigurationMetadata(request.Metadata)
+}
+
+// checkBeforeApplying performs the checks on the request that depend on the radio's surroundings at the time it is
+// applied rather than on the request alone. There are none for the robot radio.
+func (request ConfigurationRequest) checkBeforeApplying(radio *Radio) error {
 	return nil
 }
+
+// supersedes returns true if this request overrides everything that the given earlier request would change. Each robot
+// radio request fully describes the desired configuration, so a later one always supersedes an earlier one.
+func (request ConfigurationRequest) supersedes(earlier ConfigurationRequest) bool {
+	return true
+}
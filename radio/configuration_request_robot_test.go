@@ -5,6 +5,7 @@ package radio
 
 import (
 	"github.com/stretchr/testify/assert"
+	"reflect"
 	"testing"
 )
 
@@ -29,6 +30,7 @@ func TestConfigurationRequest_Validate(t *testing.T) {
 	request.Channel = 36
 	err = request.Validate(radio)
 	assert.EqualError(t, err, "invalid 6GHz channel: 36")
+	assert.ErrorIs(t, err, ErrInvalidChannel)
 	request.Channel = 0
 	assert.Nil(t, request.Validate(radio))
 	request.Mode = modeTeamRobotRadio
@@ -89,4 +91,53 @@ func TestConfigurationRequest_Validate(t *testing.T) {
 	request.WpaKey24 = "abc123!@#"
 	err = request.Validate(radio)
 	assert.EqualError(t, err, "invalid wpaKey24 (expecting alphanumeric)")
+
+	// Invalid metadata.
+	request.WpaKey24 = "12345678"
+	request.Metadata = map[string]string{"eventCode": "2024casj\n"}
+	err = request.Validate(radio)
+	assert.EqualError(t, err, "invalid value for metadata key eventCode (expecting up to 128 printable characters)")
+}
+
+func TestConfigurationRequest_ValidateExtendedCharsets(t *testing.T) {
+	radio := &Radio{settings: defaultSettings()}
+	request := ConfigurationRequest{
+		Mode: modeTeamRobotRadio, TeamNumber: 254, SsidSuffix: "Équipe", WpaKey6: "p@ss w0rd!", WpaKey24: "87654321",
+	}
+	err := request.Validate(radio)
+	assert.EqualError(t, err, "invalid ssidSuffix (expecting alphanumeric)")
+
+	radio.settings.CredentialCharset = credentialCharsetPrintableAscii
+	err = request.Validate(radio)
+	assert.EqualError(t, err, `invalid ssidSuffix (expecting printable ASCII other than ' and \)`)
+	request.SsidSuffix = "#2"
+	assert.Nil(t, request.Validate(radio))
+
+	radio.settings.CredentialCharset = credentialCharsetUtf8
+	request.SsidSuffix = "Équipe"
+	assert.Nil(t, request.Validate(radio))
+	request.WpaKey24 = "clé secrète"
+	err = request.Validate(radio)
+	assert.EqualError(t, err, `invalid wpaKey24 (expecting printable ASCII other than ' and \)`)
+}
+
+func TestConfigurationRequest_supersedesCoversAllFields(t *testing.T) {
+	// A later request only supersedes an earlier one because configure applies each of these fields on every request,
+	// with a zero value meaning a default rather than leaving the setting unchanged. A new field must be reviewed
+	// against supersedes before being added here.
+	fieldsAppliedByEveryRequest := map[string]bool{
+		"Mode": true, "Channel": true, "TeamNumber": true, "SsidSuffix": true, "WpaKey6": true, "WpaKey24": true,
+		"Metadata": true,
+	}
+
+	requestType := reflect.TypeOf(ConfigurationRequest{})
+	for i := 0; i < requestType.NumField(); i++ {
+		field := requestType.Field(i)
+		if field.IsExported() {
+			assert.True(
+				t, fieldsAppliedByEveryRequest[field.Name], "supersedes doesn't account for field %s", field.Name,
+			)
+		}
+	}
+	assert.True(t, ConfigurationRequest{}.supersedes(ConfigurationRequest{Mode: modeTeamRobotRadio, TeamNumber: 254}))
 }
@@ -0,0 +1,170 @@
+package radio
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Path to the file in which the configuration statistics are kept across restarts and firmware upgrades, so that they
+// accumulate over the radio's time in the field; variable to facilitate testing.
+var configurationStatsFilePath = "/root/frc-radio-api-configuration-stats.json"
+
+// ConfigurationStats represents how configuring the radio has gone while it was running a single combination of
+// hardware type and firmware version, so that misbehaving combinations can be identified across the fleet.
+type ConfigurationStats struct {
+	// Hardware type of the radio (e.g. "TypeLinksys").
+	HardwareType string `json:"hardwareType"`
+
+	// Firmware version that the radio was running.
+	FirmwareVersion string `json:"firmwareVersion"`
+
+	// Number of configuration requests that were applied.
+	AttemptCount int `json:"attemptCount"`
+
+	// Number of configuration requests that were applied successfully.
+	SuccessCount int `json:"successCount"`
+
+	// Number of configuration requests that failed and left the radio in the error state.
+	FailureCount int `json:"failureCount"`
+
+	// Percentage of the configuration requests that were applied successfully, or zero if none were applied.
+	SuccessRatePercent float64 `json:"successRatePercent"`
+
+	// Number of times committing and reloading the configuration had to be repeated within a request.
+	RetryCount int `json:"retryCount"`
+
+	// Number of times the configuration in effect didn't match the request when it was read back.
+	VerificationFailureCount int `json:"verificationFailureCount"`
+
+	// Number of times a known failure of the wireless stack was mitigated while applying a request.
+	MitigationCount int `json:"mitigationCount"`
+}
+
+// ConfigurationStatsReport represents the configuration statistics of every hardware type and firmware version that
+// the radio has run.
+type ConfigurationStatsReport struct {
+	// Hardware type of the radio.
+	HardwareType string `json:"hardwareType"`
+
+	// Firmware version that the radio is currently running.
+	FirmwareVersion string `json:"firmwareVersion"`
+
+	// Statistics of each combination of hardware type and firmware version, ordered by hardware type and then by
+	// firmware version.
+	Combinations []ConfigurationStats `json:"combinations"`
+}
+
+// configurationStatsLog accumulates the configuration statistics, which are read from the web server goroutine.
+type configurationStatsLog struct {
+	mutex   sync.Mutex
+	entries []ConfigurationStats
+
+	// Path of the file in which the statistics are kept, or blank if they haven't been loaded from it and so are only
+	// kept in memory.
+	filePath string
+}
+
+// loadConfigurationStats reads the statistics accumulated before the API last started and keeps them up to date on
+// disk from then on.
+func (radio *Radio) loadConfigurationStats() {
+	statsLog := &radio.configurationStats
+	statsLog.mutex.Lock()
+	defer statsLog.mutex.Unlock()
+	statsLog.filePath = configurationStatsFilePath
+	statsJson, err := os.ReadFile(statsLog.filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	var entries []ConfigurationStats
+	if err == nil {
+		err = json.Unmarshal(statsJson, &entries)
+	}
+	if err != nil {
+		log.Printf("Error reading configuration statistics; starting over: %v", err)
+		return
+	}
+	statsLog.entries = entries
+}
+
+// updateConfigurationStats applies the given change to the statistics of the radio's current hardware type and
+// firmware version.
+func (radio *Radio) updateConfigurationStats(update func(stats *ConfigurationStats)) {
+	statsLog := &radio.configurationStats
+	statsLog.mutex.Lock()
+	defer statsLog.mutex.Unlock()
+	stats := statsLog.find(radio.hardwareProfile(), radio.Version)
+	update(stats)
+	if stats.AttemptCount > 0 {
+		stats.SuccessRatePercent = 100 * float64(stats.SuccessCount) / float64(stats.AttemptCount)
+	}
+}
+
+// saveConfigurationStats writes the statistics to disk so that they survive a restart, if they were loaded from it.
+func (radio *Radio) saveConfigurationStats() {
+	statsLog := &radio.configurationStats
+	statsLog.mutex.Lock()
+	defer statsLog.mutex.Unlock()
+	if statsLog.filePath == "" {
+		return
+	}
+	statsJson, err := json.Marshal(statsLog.entries)
+	if err == nil {
+		err = os.WriteFile(statsLog.filePath, statsJson, 0600)
+	}
+	if err != nil {
+		log.Printf("Error saving configuration statistics: %v", err)
+	}
+}
+
+// GetConfigurationStats returns a snapshot of the statistics of every hardware type and firmware version that the
+// radio has run.
+func (radio *Radio) GetConfigurationStats() ConfigurationStatsReport {
+	statsLog := &radio.configurationStats
+	statsLog.mutex.Lock()
+	defer statsLog.mutex.Unlock()
+	combinations := make([]ConfigurationStats, len(statsLog.entries))
+	copy(combinations, statsLog.entries)
+	sort.Slice(combinations, func(i, j int) bool {
+		if combinations[i].HardwareType != combinations[j].HardwareType {
+			return combinations[i].HardwareType < combinations[j].HardwareType
+		}
+		return combinations[i].FirmwareVersion < combinations[j].FirmwareVersion
+	})
+	return ConfigurationStatsReport{
+		HardwareType: radio.hardwareProfile(), FirmwareVersion: radio.Version, Combinations: combinations,
+	}
+}
+
+// currentConfigurationStats returns a copy of the statistics of the radio's current hardware type and firmware version,
+// or nil if no configuration request has been applied on them yet.
+func (radio *Radio) currentConfigurationStats() *ConfigurationStats {
+	statsLog := &radio.configurationStats
+	statsLog.mutex.Lock()
+	defer statsLog.mutex.Unlock()
+	hardwareType := radio.hardwareProfile()
+	for _, stats := range statsLog.entries {
+		if stats.HardwareType == hardwareType && stats.FirmwareVersion == radio.Version {
+			return &stats
+		}
+	}
+	return nil
+}
+
+// find returns the statistics of the given hardware type and firmware version, adding them if there are none yet. Must
+// be called with the mutex held.
+func (statsLog *configurationStatsLog) find(hardwareType, firmwareVersion string) *ConfigurationStats {
+	for i := range statsLog.entries {
+		if statsLog.entries[i].HardwareType == hardwareType && statsLog.entries[i].FirmwareVersion == firmwareVersion {
+			return &statsLog.entries[i]
+		}
+	}
+	statsLog.entries = append(
+		statsLog.entries, ConfigurationStats{HardwareType: hardwareType, FirmwareVersion: firmwareVersion},
+	)
+	return &statsLog.entries[len(statsLog.entries)-1]
+}
@@ -0,0 +1,77 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRadio_updateConfigurationStats(t *testing.T) {
+	radio := &Radio{settings: defaultSettings(), Version: "1.2.3"}
+	assert.Nil(t, radio.currentConfigurationStats())
+
+	radio.updateConfigurationStats(func(stats *ConfigurationStats) {
+		stats.AttemptCount += 4
+		stats.SuccessCount += 3
+		stats.FailureCount++
+		stats.RetryCount++
+	})
+	radio.Version = "1.2.0"
+	radio.updateConfigurationStats(func(stats *ConfigurationStats) { stats.MitigationCount++ })
+
+	report := radio.GetConfigurationStats()
+	hardwareType := radio.hardwareProfile()
+	assert.Equal(t, hardwareType, report.HardwareType)
+	assert.Equal(t, "1.2.0", report.FirmwareVersion)
+	assert.Equal(
+		t,
+		[]ConfigurationStats{
+			{HardwareType: hardwareType, FirmwareVersion: "1.2.0", MitigationCount: 1},
+			{
+				HardwareType:       hardwareType,
+				FirmwareVersion:    "1.2.3",
+				AttemptCount:       4,
+				SuccessCount:       3,
+				FailureCount:       1,
+				SuccessRatePercent: 75,
+				RetryCount:         1,
+			},
+		},
+		report.Combinations,
+	)
+	assert.Equal(t, &report.Combinations[0], radio.currentConfigurationStats())
+}
+
+func TestRadio_saveConfigurationStats(t *testing.T) {
+	configurationStatsFilePath = filepath.Join(t.TempDir(), "configuration-stats.json")
+
+	// The statistics are only kept in memory until they have been loaded.
+	radio := &Radio{settings: defaultSettings(), Version: "1.2.3"}
+	radio.updateConfigurationStats(func(stats *ConfigurationStats) { stats.AttemptCount++ })
+	radio.saveConfigurationStats()
+	_, err := os.Stat(configurationStatsFilePath)
+	assert.True(t, os.IsNotExist(err))
+
+	radio.loadConfigurationStats()
+	radio.updateConfigurationStats(func(stats *ConfigurationStats) { stats.AttemptCount++ })
+	radio.saveConfigurationStats()
+
+	// The statistics carry over to the radio running new firmware after a restart.
+	radio = &Radio{settings: defaultSettings(), Version: "1.3.0"}
+	radio.loadConfigurationStats()
+	radio.updateConfigurationStats(func(stats *ConfigurationStats) { stats.SuccessCount++ })
+	combinations := radio.GetConfigurationStats().Combinations
+	if assert.Equal(t, 2, len(combinations)) {
+		assert.Equal(t, "1.2.3", combinations[0].FirmwareVersion)
+		assert.Equal(t, 2, combinations[0].AttemptCount)
+		assert.Equal(t, "1.3.0", combinations[1].FirmwareVersion)
+		assert.Equal(t, 1, combinations[1].SuccessCount)
+	}
+
+	// Unreadable statistics are discarded.
+	assert.Nil(t, os.WriteFile(configurationStatsFilePath, []byte("{"), 0600))
+	radio = &Radio{settings: defaultSettings()}
+	radio.loadConfigurationStats()
+	assert.Empty(t, radio.GetConfigurationStats().Combinations)
+}
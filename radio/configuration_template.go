@@ -0,0 +1,66 @@
+package radio
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// Maximum field number that can be set as an event variable.
+const maxFieldNumber = 99
+
+// Event codes are restricted to characters that can appear in an SSID or WPA key and never need escaping in JSON.
+var eventCodeRe = regexp.MustCompile(`^[A-Za-z0-9]{0,16}$`)
+
+// EventVariables holds the radio-level values that template variables in a configuration request are resolved against,
+// so that a single configuration file can be reused across fields and events.
+type EventVariables struct {
+	// Code of the event at which the radio is deployed (e.g. "2024CASJ"), substituted for {{.EventCode}}.
+	EventCode string `json:"eventCode"`
+
+	// Number of the field the radio serves, substituted for {{.FieldNumber}}.
+	FieldNumber int `json:"fieldNumber"`
+}
+
+// validate checks that all parameters within the event variables have valid values.
+func (variables EventVariables) validate() error {
+	if !eventCodeRe.MatchString(variables.EventCode) {
+		return fmt.Errorf(
+			"invalid eventVariables.eventCode: %q (expecting up to 16 letters and digits)", variables.EventCode,
+		)
+	}
+	if variables.FieldNumber < 0 || variables.FieldNumber > maxFieldNumber {
+		return fmt.Errorf(
+			"invalid eventVariables.fieldNumber: %d (expecting 0-%d)", variables.FieldNumber, maxFieldNumber,
+		)
+	}
+	return nil
+}
+
+// ResolveConfigurationTemplate substitutes the event variables into the template variables (e.g. {{.EventCode}}) in
+// the given configuration request body. Returns an error if the body references a variable that isn't set, so that a
+// radio missing its event variables is never configured with a partial SSID or WPA key.
+func (variables EventVariables) ResolveConfigurationTemplate(body []byte) ([]byte, error) {
+	if !strings.Contains(string(body), "{{") {
+		return body, nil
+	}
+	configurationTemplate, err := template.New("configuration").Option("missingkey=error").Parse(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration template: %v", err)
+	}
+
+	// Only set variables are offered, so that referencing an unset one fails rather than resolving to a blank or zero.
+	values := make(map[string]any)
+	if variables.EventCode != "" {
+		values["EventCode"] = variables.EventCode
+	}
+	if variables.FieldNumber != 0 {
+		values["FieldNumber"] = variables.FieldNumber
+	}
+	var resolved strings.Builder
+	if err = configurationTemplate.Execute(&resolved, values); err != nil {
+		return nil, fmt.Errorf("unable to resolve configuration template: %v", err)
+	}
+	return []byte(resolved.String()), nil
+}
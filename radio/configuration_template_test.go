@@ -0,0 +1,41 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEventVariables_ResolveConfigurationTemplate(t *testing.T) {
+	variables := EventVariables{EventCode: "2024CASJ", FieldNumber: 2}
+
+	// Bodies without template variables are passed through untouched.
+	body := []byte(`{"channel": 5, "syslogIpAddress": "10.0.100.40"}`)
+	resolved, err := variables.ResolveConfigurationTemplate(body)
+	assert.Nil(t, err)
+	assert.Equal(t, body, resolved)
+
+	resolved, err = variables.ResolveConfigurationTemplate(
+		[]byte(`{"stationConfigurations": {"red1": {"ssid": "{{.EventCode}}-F{{.FieldNumber}}", "wpaKey": "12345678"}}}`),
+	)
+	assert.Nil(t, err)
+	assert.Equal(
+		t, `{"stationConfigurations": {"red1": {"ssid": "2024CASJ-F2", "wpaKey": "12345678"}}}`, string(resolved),
+	)
+
+	resolved, err = variables.ResolveConfigurationTemplate([]byte(`{"ssid": "{{.EventCode"}`))
+	assert.Nil(t, resolved)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "invalid configuration template:")
+	}
+
+	// Referencing an unknown or unset variable is an error rather than resolving to a blank.
+	_, err = variables.ResolveConfigurationTemplate([]byte(`{"ssid": "{{.TeamNumber}}"}`))
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "unable to resolve configuration template:")
+		assert.Contains(t, err.Error(), "TeamNumber")
+	}
+	_, err = EventVariables{EventCode: "2024CASJ"}.ResolveConfigurationTemplate([]byte(`{"channel": {{.FieldNumber}}}`))
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "FieldNumber")
+	}
+}
@@ -0,0 +1,79 @@
+package radio
+
+import (
+	"time"
+)
+
+// configurationStep represents a stage reached while applying a configuration request.
+type configurationStep string
+
+const (
+	// The request passed its checks and is about to be applied.
+	configurationStepValidated configurationStep = "VALIDATED"
+
+	// The changes to the UCI configuration were committed.
+	configurationStepUciCommitted configurationStep = "UCI_COMMITTED"
+
+	// The Wi-Fi configuration was reloaded so that the committed changes take effect.
+	configurationStepWifiReloaded configurationStep = "WIFI_RELOADED"
+
+	// The configuration in effect is being read back and checked against the request.
+	configurationStepVerifying configurationStep = "VERIFYING"
+
+	// The request was applied successfully.
+	configurationStepApplied configurationStep = "APPLIED"
+
+	// Applying the request failed.
+	configurationStepFailed configurationStep = "FAILED"
+)
+
+// ConfigurationTimelineStep represents a single stage reached while applying a configuration request.
+type ConfigurationTimelineStep struct {
+	// Stage that was reached.
+	Step configurationStep `json:"step"`
+
+	// Which attempt at committing, reloading, and verifying the configuration the stage belongs to, starting at 1, or
+	// zero for stages outside those attempts.
+	Attempt int `json:"attempt"`
+
+	// Time at which the stage was reached.
+	At Timestamp `json:"at"`
+
+	// Time elapsed between the start of the timeline and the stage being reached, in seconds.
+	ElapsedSec float64 `json:"elapsedSec"`
+}
+
+// ConfigurationTimeline represents the progress of applying a single configuration request, so that the FMS can show
+// more than a generic indicator while the radio is configuring.
+type ConfigurationTimeline struct {
+	// Identifier of the configuration request being applied, or zero if it wasn't queued through the API.
+	RequestId int `json:"requestId"`
+
+	// Stages reached so far, oldest first. The last one is "APPLIED" or "FAILED" once the request is finished.
+	Steps []ConfigurationTimelineStep `json:"steps"`
+}
+
+// startConfigurationTimeline replaces the configuration timeline with a new one for the given request, beginning with
+// it having been validated.
+func (radio *Radio) startConfigurationTimeline(requestId int) {
+	radio.ConfigurationTimeline = &ConfigurationTimeline{RequestId: requestId}
+	radio.recordConfigurationStep(configurationStepValidated, 0)
+}
+
+// recordConfigurationStep adds the given stage to the timeline of the configuration request being applied, if any.
+func (radio *Radio) recordConfigurationStep(step configurationStep, attempt int) {
+	timeline := radio.ConfigurationTimeline
+	if timeline == nil {
+		return
+	}
+	at := newTimestamp()
+	var elapsed time.Duration
+	if len(timeline.Steps) > 0 {
+		elapsed = time.Duration(at.MonotonicNs - timeline.Steps[0].At.MonotonicNs)
+	}
+	timeline.Steps = append(
+		timeline.Steps,
+		ConfigurationTimelineStep{Step: step, Attempt: attempt, At: at, ElapsedSec: elapsed.Seconds()},
+	)
+	radio.markStatusChanged()
+}
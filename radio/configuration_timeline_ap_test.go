@@ -0,0 +1,71 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// timelineSteps returns the stage and attempt number of each step of the given timeline, for concise comparison.
+func timelineSteps(timeline *ConfigurationTimeline) []string {
+	var steps []string
+	for _, step := range timeline.Steps {
+		steps = append(steps, fmt.Sprintf("%s/%d", step.Step, step.Attempt))
+	}
+	return steps
+}
+
+func TestRadio_configurationTimeline(t *testing.T) {
+	radio, fakeTree, fakeShell := newVerificationTestRadio(t)
+	assert.Nil(t, radio.ConfigurationTimeline)
+
+	id, _ := radio.EnqueueConfigurationRequest(
+		ConfigurationRequest{
+			StationConfigurations: map[string]*StationConfiguration{"red1": {Ssid: "1111", WpaKey: "11111111"}},
+		},
+	)
+	assert.Nil(t, radio.handleConfigurationRequest(<-radio.ConfigurationRequestChannel))
+	if assert.NotNil(t, radio.ConfigurationTimeline) {
+		assert.Equal(t, id, radio.ConfigurationTimeline.RequestId)
+		assert.Equal(
+			t,
+			[]string{"VALIDATED/0", "UCI_COMMITTED/1", "WIFI_RELOADED/1", "VERIFYING/1", "APPLIED/0"},
+			timelineSteps(radio.ConfigurationTimeline),
+		)
+		steps := radio.ConfigurationTimeline.Steps
+		assert.Equal(t, 0.0, steps[0].ElapsedSec)
+		for i := 1; i < len(steps); i++ {
+			assert.GreaterOrEqual(t, steps[i].At.MonotonicNs, steps[i-1].At.MonotonicNs)
+			assert.GreaterOrEqual(t, steps[i].ElapsedSec, steps[i-1].ElapsedSec)
+		}
+	}
+
+	// Each failed verification is followed by another attempt.
+	fakeShell.onRunCommand = func(command string) {
+		if command == "wifi reload wifi1" {
+			fakeTree.valuesForGet["wireless.@wifi-iface[4].network"] = "vlan10"
+		}
+	}
+	assert.NotNil(t, radio.handleConfigurationRequest(ConfigurationRequest{}))
+	assert.Equal(t, 0, radio.ConfigurationTimeline.RequestId)
+	assert.Equal(
+		t,
+		[]string{
+			"VALIDATED/0",
+			"UCI_COMMITTED/1",
+			"WIFI_RELOADED/1",
+			"VERIFYING/1",
+			"UCI_COMMITTED/2",
+			"WIFI_RELOADED/2",
+			"VERIFYING/2",
+			"UCI_COMMITTED/3",
+			"WIFI_RELOADED/3",
+			"VERIFYING/3",
+			"FAILED/0",
+		},
+		timelineSteps(radio.ConfigurationTimeline),
+	)
+}
@@ -0,0 +1,96 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// verificationError describes an attribute of the configuration that didn't take effect after the wireless reload.
+type verificationError struct {
+	// Station whose network the attribute belongs to, or blank for an attribute of the whole device.
+	station string
+
+	// Name of the UCI option or network property that failed verification (e.g. "network").
+	attribute string
+
+	// Values found and expected, or blank if they shouldn't be revealed (e.g. for WPA keys).
+	actual   string
+	expected string
+}
+
+func (err *verificationError) Error() string {
+	subject := err.attribute
+	if err.station != "" {
+		subject = fmt.Sprintf("%s %s", err.station, err.attribute)
+	}
+	if err.actual == "" && err.expected == "" {
+		return fmt.Sprintf("%s did not take effect", subject)
+	}
+	return fmt.Sprintf("%s did not take effect: got %q, expecting %q", subject, err.actual, err.expected)
+}
+
+// verifyStationConfigurations checks that the configured networks as read from the access point, and the values
+// committed to the wireless configuration, match the given station configurations and the radio's channel settings.
+// Returns an error identifying the first attribute that differs.
+func (radio *Radio) verifyStationConfigurations(stationConfigurations map[string]*StationConfiguration) error {
+	for station := red1; station <= blue3; station++ {
+		config, ok := stationConfigurations[station.String()]
+		if !ok {
+			continue
+		}
+		if err := radio.verifyStation(station, config); err != nil {
+			return err
+		}
+	}
+
+	if radio.Channel > 0 {
+		expected := strconv.Itoa(radio.Channel)
+		if actual, _ := uciTree.GetLast("wireless", radio.device, "channel"); actual != expected {
+			return &verificationError{attribute: "channel", actual: actual, expected: expected}
+		}
+	}
+	if expected, ok := htmodeForBandwidth(radio.ChannelBandwidth); ok {
+		if actual, _ := uciTree.GetLast("wireless", radio.device, "htmode"); actual != expected {
+			return &verificationError{attribute: "htmode", actual: actual, expected: expected}
+		}
+	}
+	return nil
+}
+
+// verifyStation checks the SSID, WPA key, and VLAN network binding of the given station against its configuration,
+// where a null configuration means that the station should be unassigned.
+func (radio *Radio) verifyStation(station station, config *StationConfiguration) error {
+	stationStatus := radio.StationStatuses[station.String()]
+	if config != nil {
+		if stationStatus == nil || stationStatus.Ssid != config.Ssid {
+			actual := radio.placeholderSsid(station)
+			if stationStatus != nil {
+				actual = stationStatus.Ssid
+			}
+			return &verificationError{
+				station: station.String(), attribute: "ssid", actual: actual, expected: config.Ssid,
+			}
+		}
+		// Compare hashes rather than the keys themselves so that neither ends up in the error.
+		if hashWpaKey(config.WpaKey, stationStatus.WpaKeySalt) != stationStatus.HashedWpaKey {
+			return &verificationError{station: station.String(), attribute: "key"}
+		}
+	} else if stationStatus != nil {
+		// We expect the station status to be nil if the station is not configured.
+		return &verificationError{
+			station:   station.String(),
+			attribute: "ssid",
+			actual:    stationStatus.Ssid,
+			expected:  radio.placeholderSsid(station),
+		}
+	}
+
+	expected := fmt.Sprintf("vlan%d", radio.getStationVlan(station))
+	if actual, _ := uciTree.GetLast("wireless", wifiIfaceSection(station), "network"); actual != expected {
+		return &verificationError{station: station.String(), attribute: "network", actual: actual, expected: expected}
+	}
+	return nil
+}
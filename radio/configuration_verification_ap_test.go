@@ -0,0 +1,172 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// newVerificationTestRadio returns a Vivid-Hosting access point on which the red 1 network reports the SSID "1111" and
+// the others report their placeholder SSIDs.
+func newVerificationTestRadio(t *testing.T) (*Radio, *fakeUciTree, *fakeShell) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeTree.valuesForGet["system.@system[0].model"] = "VH-109(AP)"
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	retryBackoffDuration = 10 * time.Millisecond
+	wifiReloadBackoffDuration = 10 * time.Millisecond
+	fakeShell.commandOutput["cat /etc/vh_firmware"] = ""
+	radio := NewRadio()
+
+	fakeShell.commandOutput["wifi reload wifi1"] = ""
+	fakeShell.commandOutput["iwinfo ath1 info"] = "ath1\nESSID: \"1111\"\n"
+	fakeShell.commandOutput["iwinfo ath11 info"] = "ath11\nESSID: \"no-team-2\"\n"
+	fakeShell.commandOutput["iwinfo ath12 info"] = "ath12\nESSID: \"no-team-3\"\n"
+	fakeShell.commandOutput["iwinfo ath13 info"] = "ath13\nESSID: \"no-team-4\"\n"
+	fakeShell.commandOutput["iwinfo ath14 info"] = "ath14\nESSID: \"no-team-5\"\n"
+	fakeShell.commandOutput["iwinfo ath15 info"] = "ath15\nESSID: \"no-team-6\"\n"
+	return radio, fakeTree, fakeShell
+}
+
+func TestRadio_verifyStationConfigurations(t *testing.T) {
+	radio, fakeTree, _ := newVerificationTestRadio(t)
+	request := ConfigurationRequest{
+		Channel:               149,
+		ChannelBandwidth:      "40MHz",
+		StationConfigurations: map[string]*StationConfiguration{"red1": {Ssid: "1111", WpaKey: "11111111"}},
+	}
+	assert.Nil(t, radio.handleConfigurationRequest(request))
+	stationConfigurations := withOmittedStationsUnassigned(request.StationConfigurations)
+	assert.Nil(t, radio.verifyStationConfigurations(stationConfigurations))
+
+	// Each attribute is reported precisely when its committed value doesn't match.
+	fakeTree.valuesForGet["wireless.@wifi-iface[2].network"] = "vlan10"
+	assert.EqualError(
+		t,
+		radio.verifyStationConfigurations(stationConfigurations),
+		`red2 network did not take effect: got "vlan10", expecting "vlan20"`,
+	)
+	fakeTree.valuesForGet["wireless.@wifi-iface[2].network"] = "vlan20"
+
+	fakeTree.valuesForGet["wireless.wifi1.channel"] = "36"
+	assert.EqualError(
+		t,
+		radio.verifyStationConfigurations(stationConfigurations),
+		`channel did not take effect: got "36", expecting "149"`,
+	)
+	fakeTree.valuesForGet["wireless.wifi1.channel"] = "149"
+
+	fakeTree.valuesForGet["wireless.wifi1.htmode"] = "HT20"
+	assert.EqualError(
+		t,
+		radio.verifyStationConfigurations(stationConfigurations),
+		`htmode did not take effect: got "HT20", expecting "HT40"`,
+	)
+	fakeTree.valuesForGet["wireless.wifi1.htmode"] = "HT40"
+
+	// The key is compared by hash and never revealed.
+	stationConfigurations["red1"] = &StationConfiguration{Ssid: "1111", WpaKey: "99999999"}
+	assert.EqualError(t, radio.verifyStationConfigurations(stationConfigurations), "red1 key did not take effect")
+
+	stationConfigurations["red1"] = &StationConfiguration{Ssid: "9999", WpaKey: "11111111"}
+	assert.EqualError(
+		t,
+		radio.verifyStationConfigurations(stationConfigurations),
+		`red1 ssid did not take effect: got "1111", expecting "9999"`,
+	)
+
+	stationConfigurations["red1"] = nil
+	assert.EqualError(
+		t,
+		radio.verifyStationConfigurations(stationConfigurations),
+		`red1 ssid did not take effect: got "1111", expecting "no-team-1"`,
+	)
+}
+
+func TestRadio_configureStationsReportsFailedVerification(t *testing.T) {
+	radio, fakeTree, fakeShell := newVerificationTestRadio(t)
+
+	// The VLAN binding of one network silently fails to apply on every reload.
+	fakeShell.onRunCommand = func(command string) {
+		if command == "wifi reload wifi1" {
+			fakeTree.valuesForGet["wireless.@wifi-iface[4].network"] = "vlan10"
+		}
+	}
+	request := ConfigurationRequest{
+		StationConfigurations: map[string]*StationConfiguration{"red1": {Ssid: "1111", WpaKey: "11111111"}},
+	}
+	assert.EqualError(
+		t,
+		radio.handleConfigurationRequest(request),
+		`failed to configure stations after 3 attempts: blue1 network did not take effect: got "vlan10", `+
+			`expecting "vlan40"`,
+	)
+	assert.Equal(t, 3, fakeTree.commitCount)
+	assert.Contains(t, fakeTree.loadedConfigs, "wireless")
+	if assert.NotNil(t, radio.LastError) {
+		assert.Equal(t, "VERIFICATION_TIMEOUT", radio.LastError.Kind)
+	}
+	stats := radio.currentConfigurationStats()
+	if assert.NotNil(t, stats) {
+		assert.Equal(t, 1, stats.AttemptCount)
+		assert.Equal(t, 1, stats.FailureCount)
+		assert.Equal(t, 2, stats.RetryCount)
+		assert.Equal(t, 3, stats.VerificationFailureCount)
+	}
+}
+
+func TestRadio_handleConfigurationRequestRecordsErrorKind(t *testing.T) {
+	radio, fakeTree, _ := newVerificationTestRadio(t)
+	fakeTree.commitError = errors.New("disk full")
+	id, _ := radio.EnqueueConfigurationRequest(
+		ConfigurationRequest{
+			StationConfigurations: map[string]*StationConfiguration{"red1": {Ssid: "1111", WpaKey: "11111111"}},
+		},
+	)
+
+	err := radio.handleConfigurationRequest(<-radio.ConfigurationRequestChannel)
+	assert.EqualError(t, err, "failed to commit wireless configuration: disk full")
+	assert.ErrorIs(t, err, ErrCommitFailed)
+	record, _ := radio.GetConfigurationRequest(id)
+	assert.Equal(t, "COMMIT_FAILED", record.ErrorKind)
+	if assert.NotNil(t, radio.LastError) {
+		assert.Equal(t, id, radio.LastError.RequestId)
+		assert.Equal(t, "COMMIT_FAILED", radio.LastError.Kind)
+		assert.Equal(t, "failed to commit wireless configuration: disk full", radio.LastError.Message)
+	}
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "RADIO_ERROR", alerts[0].Type)
+		assert.Equal(
+			t,
+			fmt.Sprintf(
+				"Radio is in the error state after failing to apply configuration request %d: failed to commit "+
+					"wireless configuration: disk full",
+				id,
+			),
+			alerts[0].Message,
+		)
+	}
+
+	// The changes that couldn't be committed are discarded rather than left for the next request to commit.
+	assert.Equal(t, 1, fakeTree.revertCount)
+	assert.Empty(t, fakeTree.valuesFromSet)
+
+	// A later success clears the last error.
+	fakeTree.commitError = nil
+	assert.Nil(
+		t,
+		radio.handleConfigurationRequest(
+			ConfigurationRequest{
+				StationConfigurations: map[string]*StationConfiguration{"red1": {Ssid: "1111", WpaKey: "11111111"}},
+			},
+		),
+	)
+	assert.Nil(t, radio.LastError)
+}
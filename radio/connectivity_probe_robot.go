@@ -0,0 +1,203 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package radio
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// IP address of the FMS on the field network.
+	fmsIpAddress = "10.0.100.5"
+
+	// UDP port on which the FMS receives driver station status packets.
+	fmsUdpPort = 1160
+
+	// How long to wait for a reply to each ping.
+	pingTimeoutSec = 1
+)
+
+// How long to wait for a UDP port probe to be rejected; variable to facilitate testing.
+var udpProbeTimeout = time.Second
+
+var pingRttRe = regexp.MustCompile(`time=([\d.]+) ms`)
+
+// connectivityDiagnosis summarizes where along the network path from the robot traffic is being lost.
+type connectivityDiagnosis string
+
+const (
+	// The radio is configured as an access point, so there is no upstream path to probe.
+	diagnosisNotApplicable connectivityDiagnosis = "NOT_APPLICABLE"
+
+	// The radio isn't associated with an access point, pointing to an RF or configuration problem.
+	diagnosisNotLinked connectivityDiagnosis = "NOT_LINKED"
+
+	// The radio is associated but the access point's gateway doesn't respond, so the field network is unreachable.
+	diagnosisFieldUnreachable connectivityDiagnosis = "FIELD_UNREACHABLE"
+
+	// The field network is reachable but the driver station doesn't respond.
+	diagnosisDriverStationUnreachable connectivityDiagnosis = "DRIVER_STATION_UNREACHABLE"
+
+	// The driver station is reachable but the FMS doesn't respond.
+	diagnosisFmsUnreachable connectivityDiagnosis = "FMS_UNREACHABLE"
+
+	// Every probed endpoint responded.
+	diagnosisOk connectivityDiagnosis = "OK"
+)
+
+// udpPortState represents the outcome of probing a UDP port, which can only be definitively determined to be closed.
+type udpPortState string
+
+const (
+	// The host responded that nothing is listening on the port.
+	udpPortClosed udpPortState = "CLOSED"
+
+	// The probe wasn't rejected, so the port is either open or the probe was silently dropped.
+	udpPortOpenOrFiltered udpPortState = "OPEN_OR_FILTERED"
+)
+
+// connectivityTarget represents an endpoint whose reachability from the robot is probed.
+type connectivityTarget struct {
+	name    string
+	address string
+
+	// UDP port to probe in addition to pinging the address, or zero for none.
+	udpPort int
+}
+
+// ConnectivityProbeResult represents the reachability of a single endpoint from the robot.
+type ConnectivityProbeResult struct {
+	// Role of the endpoint: "gateway", "driverStation", or "fms".
+	Name string `json:"name"`
+
+	// IP address of the endpoint.
+	Address string `json:"address"`
+
+	// Whether the endpoint responded to a ping.
+	IsReachable bool `json:"isReachable"`
+
+	// Round-trip time of the ping, in milliseconds. Zero if the endpoint is unreachable.
+	RttMs float64 `json:"rttMs"`
+
+	// UDP port that was probed, or zero if none was.
+	UdpPort int `json:"udpPort,omitempty"`
+
+	// Outcome of probing the UDP port. Blank if it wasn't probed because the endpoint is unreachable.
+	UdpPortState udpPortState `json:"udpPortState,omitempty"`
+}
+
+// ConnectivityStatus represents the reachability of the driver station and FMS through the radio's current network
+// path, used to tell a radio that is linked but can't reach the field apart from one with an RF problem.
+type ConnectivityStatus struct {
+	// Time at which the endpoints were last probed.
+	CheckedAt Timestamp `json:"checkedAt"`
+
+	// Where along the network path traffic is being lost, if anywhere.
+	Diagnosis connectivityDiagnosis `json:"diagnosis"`
+
+	// Reachability of each probed endpoint, nearest first. Empty unless the radio is linked.
+	Targets []ConnectivityProbeResult `json:"targets"`
+}
+
+// connectivityTargetsFor returns the endpoints to probe for the given team, nearest first.
+func connectivityTargetsFor(teamNumber int) []connectivityTarget {
+	teamPartialIp := fmt.Sprintf("%d.%d", teamNumber/100, teamNumber%100)
+	return []connectivityTarget{
+		{name: "gateway", address: fmt.Sprintf("10.%s.4", teamPartialIp)},
+		{name: "driverStation", address: fmt.Sprintf("10.%s.5", teamPartialIp)},
+		{name: "fms", address: fmsIpAddress, udpPort: fmsUdpPort},
+	}
+}
+
+// updateConnectivity probes the reachability of the driver station and FMS and raises an alert if the radio is linked
+// but they have become unreachable.
+func (radio *Radio) updateConnectivity() {
+	var diagnosis connectivityDiagnosis
+	var results []ConnectivityProbeResult
+	if radio.Mode != modeTeamRobotRadio {
+		diagnosis = diagnosisNotApplicable
+	} else if !radio.NetworkStatus6.IsLinked {
+		diagnosis = diagnosisNotLinked
+	} else {
+		results = probeConnectivityTargets(connectivityTargetsFor(radio.TeamNumber))
+		diagnosis = diagnoseConnectivity(results)
+	}
+
+	previousDiagnosis := radio.Connectivity.Diagnosis
+	radio.Connectivity = ConnectivityStatus{CheckedAt: newTimestamp(), Diagnosis: diagnosis, Targets: results}
+	if results != nil && diagnosis != diagnosisOk && diagnosis != previousDiagnosis {
+		radio.raiseAlert("CONNECTIVITY_LOST", "Radio is linked but traffic isn't getting through: %s.", diagnosis)
+	}
+}
+
+// probeConnectivityTargets pings each of the given endpoints and probes the UDP ports, if any, of those that respond.
+func probeConnectivityTargets(targets []connectivityTarget) []ConnectivityProbeResult {
+	results := make([]ConnectivityProbeResult, 0, len(targets))
+	for _, target := range targets {
+		result := ConnectivityProbeResult{Name: target.name, Address: target.address, UdpPort: target.udpPort}
+		result.RttMs, result.IsReachable = ping(target.address)
+		if target.udpPort != 0 && result.IsReachable {
+			result.UdpPortState = probeUdpPort(target.address, target.udpPort)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// diagnoseConnectivity determines from the results of probing the endpoints, nearest first, where traffic is being
+// lost. A closed UDP port on the FMS means that it isn't listening for the driver station even if it responds to pings.
+func diagnoseConnectivity(results []ConnectivityProbeResult) connectivityDiagnosis {
+	for _, result := range results {
+		if result.IsReachable && result.UdpPortState != udpPortClosed {
+			continue
+		}
+		switch result.Name {
+		case "gateway":
+			return diagnosisFieldUnreachable
+		case "driverStation":
+			return diagnosisDriverStationUnreachable
+		default:
+			return diagnosisFmsUnreachable
+		}
+	}
+	return diagnosisOk
+}
+
+// ping sends a single ICMP echo request to the given address and returns the round-trip time in milliseconds, or false
+// if no reply was received.
+func ping(address string) (float64, bool) {
+	output, err := shell.runCommand("ping", "-c", "1", "-W", strconv.Itoa(pingTimeoutSec), address)
+	if err != nil {
+		return 0, false
+	}
+	match := pingRttRe.FindStringSubmatch(output)
+	if match == nil {
+		return 0, false
+	}
+	rttMs, _ := strconv.ParseFloat(match[1], 64)
+	return rttMs, true
+}
+
+// probeUdpPort sends an empty datagram to the given UDP port and waits for the host to reject it.
+func probeUdpPort(address string, port int) udpPortState {
+	connection, err := net.Dial("udp", net.JoinHostPort(address, strconv.Itoa(port)))
+	if err != nil {
+		return udpPortOpenOrFiltered
+	}
+	defer connection.Close()
+	_ = connection.SetDeadline(time.Now().Add(udpProbeTimeout))
+	if _, err = connection.Write([]byte{}); err == nil {
+		_, err = connection.Read(make([]byte, 1))
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return udpPortClosed
+	}
+	return udpPortOpenOrFiltered
+}
@@ -0,0 +1,103 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestConnectivityTargetsFor(t *testing.T) {
+	assert.Equal(
+		t,
+		[]connectivityTarget{
+			{name: "gateway", address: "10.2.54.4"},
+			{name: "driverStation", address: "10.2.54.5"},
+			{name: "fms", address: "10.0.100.5", udpPort: 1160},
+		},
+		connectivityTargetsFor(254),
+	)
+}
+
+func TestDiagnoseConnectivity(t *testing.T) {
+	results := []ConnectivityProbeResult{
+		{Name: "gateway", IsReachable: true},
+		{Name: "driverStation", IsReachable: true},
+		{Name: "fms", IsReachable: true, UdpPortState: udpPortOpenOrFiltered},
+	}
+	assert.Equal(t, diagnosisOk, diagnoseConnectivity(results))
+
+	results[2].UdpPortState = udpPortClosed
+	assert.Equal(t, diagnosisFmsUnreachable, diagnoseConnectivity(results))
+	results[1].IsReachable = false
+	assert.Equal(t, diagnosisDriverStationUnreachable, diagnoseConnectivity(results))
+	results[0].IsReachable = false
+	assert.Equal(t, diagnosisFieldUnreachable, diagnoseConnectivity(results))
+}
+
+func TestProbeUdpPort(t *testing.T) {
+	udpProbeTimeout = 50 * time.Millisecond
+
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	port := listener.LocalAddr().(*net.UDPAddr).Port
+	assert.Equal(t, udpPortOpenOrFiltered, probeUdpPort("127.0.0.1", port))
+
+	// Nothing is listening once the socket is closed, so the host rejects the probe.
+	assert.Nil(t, listener.Close())
+	assert.Equal(t, udpPortClosed, probeUdpPort("127.0.0.1", port))
+}
+
+func TestRadio_updateConnectivity(t *testing.T) {
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	radio := &Radio{Mode: modeTeamAccessPoint, TeamNumber: 254}
+
+	// Nothing is probed in access point mode or while the radio isn't linked.
+	radio.updateConnectivity()
+	assert.Equal(t, diagnosisNotApplicable, radio.Connectivity.Diagnosis)
+	radio.Mode = modeTeamRobotRadio
+	radio.updateConnectivity()
+	assert.Equal(t, diagnosisNotLinked, radio.Connectivity.Diagnosis)
+	assert.Empty(t, radio.Connectivity.Targets)
+	assert.Empty(t, fakeShell.commandsRun)
+
+	// Linked but unable to reach the field.
+	radio.NetworkStatus6.IsLinked = true
+	fakeShell.commandErrors["ping -c 1 -W 1 10.2.54.4"] = errors.New("exit status 1")
+	fakeShell.commandErrors["ping -c 1 -W 1 10.2.54.5"] = errors.New("exit status 1")
+	fakeShell.commandErrors["ping -c 1 -W 1 10.0.100.5"] = errors.New("exit status 1")
+	radio.updateConnectivity()
+	assert.Equal(t, diagnosisFieldUnreachable, radio.Connectivity.Diagnosis)
+	if assert.Equal(t, 3, len(radio.Connectivity.Targets)) {
+		fmsResult := ConnectivityProbeResult{Name: "fms", Address: "10.0.100.5", UdpPort: 1160}
+		assert.Equal(t, fmsResult, radio.Connectivity.Targets[2])
+	}
+
+	// Repeating the same diagnosis doesn't raise another alert.
+	radio.updateConnectivity()
+
+	// Everything but the FMS becomes reachable.
+	fakeShell.reset()
+	fakeShell.commandOutput["ping -c 1 -W 1 10.2.54.4"] = "PING 10.2.54.4 (10.2.54.4): 56 data bytes\n" +
+		"64 bytes from 10.2.54.4: seq=0 ttl=64 time=1.482 ms\n"
+	fakeShell.commandOutput["ping -c 1 -W 1 10.2.54.5"] = "64 bytes from 10.2.54.5: seq=0 ttl=128 time=3.105 ms\n"
+	fakeShell.commandErrors["ping -c 1 -W 1 10.0.100.5"] = errors.New("exit status 1")
+	radio.updateConnectivity()
+	assert.Equal(t, diagnosisFmsUnreachable, radio.Connectivity.Diagnosis)
+	assert.True(t, radio.Connectivity.Targets[0].IsReachable)
+	assert.Equal(t, 1.482, radio.Connectivity.Targets[0].RttMs)
+	assert.Equal(t, 3.105, radio.Connectivity.Targets[1].RttMs)
+	assert.False(t, radio.Connectivity.Targets[2].IsReachable)
+
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 2, len(alerts)) {
+		assert.Equal(t, "CONNECTIVITY_LOST", alerts[0].Type)
+		assert.Equal(t, "Radio is linked but traffic isn't getting through: FIELD_UNREACHABLE.", alerts[0].Message)
+		assert.Equal(t, "Radio is linked but traffic isn't getting through: FMS_UNREACHABLE.", alerts[1].Message)
+	}
+}
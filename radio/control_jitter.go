@@ -0,0 +1,27 @@
+package radio
+
+import (
+	"time"
+)
+
+// ControlJitter represents the variation in the inter-arrival time of the driver station control and status packets
+// exchanged with a team's robot, which is the latency effect that teams actually feel while driving.
+type ControlJitter struct {
+	// Number of jitter samples the percentiles are based on.
+	SampleCount int `json:"sampleCount"`
+
+	// Median jitter, in milliseconds.
+	P50Ms float64 `json:"p50Ms"`
+
+	// 95th percentile jitter, in milliseconds.
+	P95Ms float64 `json:"p95Ms"`
+}
+
+// controlPacket represents a driver station control or status packet seen on a network interface.
+type controlPacket struct {
+	interfaceName string
+	sourceIp      string
+	destIp        string
+	destPort      int
+	receivedAt    time.Time
+}
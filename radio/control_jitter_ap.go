@@ -0,0 +1,176 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// How far back the jitter samples that a station's percentiles are based on go.
+	controlJitterWindow = 30 * time.Second
+
+	// Maximum number of jitter samples kept per interface, which bounds memory use if a device floods the control
+	// ports.
+	maxControlJitterSamples = 3000
+
+	// Gap between packets of a flow after which the flow is considered to have restarted (e.g. because the driver
+	// station was disabled or reconnected) rather than jittered.
+	controlFlowResetGap = time.Second
+)
+
+// Ports to which the driver station sends control packets and the robot sends status packets, respectively.
+var controlJitterPorts = []int{1110, 1150}
+
+// controlJitterTracker accumulates the jitter of the control traffic seen on each interface. It is fed from the packet
+// capture goroutine and read from the monitoring loop.
+type controlJitterTracker struct {
+	mutex sync.Mutex
+
+	// Most recent packet timing of each flow.
+	flows map[controlFlowKey]*controlFlow
+
+	// Jitter samples from the recent past for each interface, oldest first.
+	samples map[string][]jitterSample
+}
+
+// controlFlowKey identifies a single direction of control traffic between two devices.
+type controlFlowKey struct {
+	interfaceName string
+	sourceIp      string
+	destIp        string
+	destPort      int
+}
+
+// controlFlow holds the timing of the most recent packets of a flow.
+type controlFlow struct {
+	lastReceivedAt time.Time
+	lastInterval   time.Duration
+	hasInterval    bool
+}
+
+// jitterSample represents the jitter computed from a single packet.
+type jitterSample struct {
+	receivedAt time.Time
+	jitter     time.Duration
+}
+
+// startControlJitterSampler starts capturing the control traffic on all interfaces in order to measure its jitter,
+// for as long as the process runs.
+func (radio *Radio) startControlJitterSampler() {
+	packets := make(chan controlPacket, 100)
+	if err := startControlPacketCapture(controlJitterPorts, packets); err != nil {
+		log.Printf("Error starting control packet capture; jitter won't be measured: %v", err)
+		return
+	}
+	go func() {
+		for packet := range packets {
+			radio.controlJitter.record(packet)
+		}
+	}()
+}
+
+// updateControlJitter updates the jitter percentiles of each team station's control traffic as of the given time.
+func (radio *Radio) updateControlJitter(now time.Time) {
+	for station := red1; station <= blue3; station++ {
+		stationStatus := radio.StationStatuses[station.String()]
+		if stationStatus == nil {
+			continue
+		}
+		stationStatus.ControlJitter = radio.controlJitter.stats(radio.stationInterfaces[station], now)
+	}
+}
+
+// record computes the jitter of the given packet relative to the previous packets of its flow. Jitter is the absolute
+// difference between consecutive inter-arrival times, as in RFC 3550 but without smoothing so that percentiles can be
+// taken over it.
+func (tracker *controlJitterTracker) record(packet controlPacket) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	if tracker.flows == nil {
+		tracker.flows = make(map[controlFlowKey]*controlFlow)
+		tracker.samples = make(map[string][]jitterSample)
+	}
+
+	key := controlFlowKey{
+		interfaceName: packet.interfaceName,
+		sourceIp:      packet.sourceIp,
+		destIp:        packet.destIp,
+		destPort:      packet.destPort,
+	}
+	flow, ok := tracker.flows[key]
+	if !ok {
+		tracker.flows[key] = &controlFlow{lastReceivedAt: packet.receivedAt}
+		return
+	}
+	interval := packet.receivedAt.Sub(flow.lastReceivedAt)
+	flow.lastReceivedAt = packet.receivedAt
+	if interval < 0 || interval > controlFlowResetGap {
+		flow.hasInterval = false
+		return
+	}
+	if flow.hasInterval {
+		jitter := interval - flow.lastInterval
+		if jitter < 0 {
+			jitter = -jitter
+		}
+		samples := append(tracker.samples[packet.interfaceName], jitterSample{packet.receivedAt, jitter})
+		if len(samples) > maxControlJitterSamples {
+			samples = samples[len(samples)-maxControlJitterSamples:]
+		}
+		tracker.samples[packet.interfaceName] = samples
+	}
+	flow.lastInterval = interval
+	flow.hasInterval = true
+}
+
+// stats returns the jitter percentiles of the control traffic seen on the given interface within the window before the
+// given time, or nil if there wasn't any. Expired samples and flows are discarded.
+func (tracker *controlJitterTracker) stats(interfaceName string, now time.Time) *ControlJitter {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	for key, flow := range tracker.flows {
+		if now.Sub(flow.lastReceivedAt) > controlJitterWindow {
+			delete(tracker.flows, key)
+		}
+	}
+	samples := tracker.samples[interfaceName]
+	firstRecent := sort.Search(len(samples), func(i int) bool {
+		return now.Sub(samples[i].receivedAt) <= controlJitterWindow
+	})
+	samples = samples[firstRecent:]
+	if len(samples) == 0 {
+		delete(tracker.samples, interfaceName)
+		return nil
+	}
+	tracker.samples[interfaceName] = samples
+
+	jitters := make([]time.Duration, len(samples))
+	for i, sample := range samples {
+		jitters[i] = sample.jitter
+	}
+	sort.Slice(jitters, func(i, j int) bool {
+		return jitters[i] < jitters[j]
+	})
+	return &ControlJitter{
+		SampleCount: len(jitters),
+		P50Ms:       jitterPercentileMs(jitters, 50),
+		P95Ms:       jitterPercentileMs(jitters, 95),
+	}
+}
+
+// jitterPercentileMs returns the given percentile of the given sorted jitter samples using the nearest-rank method, in
+// milliseconds rounded to the nearest hundredth.
+func jitterPercentileMs(jitters []time.Duration, percentile int) float64 {
+	rank := int(math.Ceil(float64(percentile) / 100 * float64(len(jitters))))
+	if rank < 1 {
+		rank = 1
+	}
+	return math.Round(float64(jitters[rank-1])/float64(time.Millisecond)*100) / 100
+}
@@ -0,0 +1,79 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestControlJitterTracker(t *testing.T) {
+	var tracker controlJitterTracker
+	start := time.Now()
+	send := func(interfaceName, sourceIp string, destPort int, offset time.Duration) {
+		tracker.record(controlPacket{
+			interfaceName: interfaceName,
+			sourceIp:      sourceIp,
+			destIp:        "10.2.54.2",
+			destPort:      destPort,
+			receivedAt:    start.Add(offset),
+		})
+	}
+
+	assert.Nil(t, tracker.stats("ath11", start))
+
+	// Packets every 20ms with the given extra delays yield jitter of 4, 6, 8, ... 20ms.
+	offset := time.Duration(0)
+	for i := 0; i <= 10; i++ {
+		offset += 20*time.Millisecond + time.Duration(i*(i+1))*time.Millisecond
+		send("ath11", "10.0.100.5", 1110, offset)
+	}
+	jitter := tracker.stats("ath11", start.Add(offset))
+	if assert.NotNil(t, jitter) {
+		assert.Equal(t, ControlJitter{SampleCount: 9, P50Ms: 12, P95Ms: 20}, *jitter)
+	}
+
+	// Flows in the other direction and on other interfaces are tracked independently.
+	send("ath11", "10.2.54.2", 1150, offset)
+	send("ath11", "10.2.54.2", 1150, offset+20*time.Millisecond)
+	send("ath11", "10.2.54.2", 1150, offset+40*time.Millisecond)
+	send("ath12", "10.1.14.2", 1150, offset)
+	jitter = tracker.stats("ath11", start.Add(offset))
+	if assert.NotNil(t, jitter) {
+		assert.Equal(t, ControlJitter{SampleCount: 10, P50Ms: 10, P95Ms: 20}, *jitter)
+	}
+	assert.Nil(t, tracker.stats("ath12", start.Add(offset)))
+
+	// A long gap restarts the flow rather than counting as jitter.
+	send("ath11", "10.2.54.2", 1150, offset+5*time.Second)
+	send("ath11", "10.2.54.2", 1150, offset+5*time.Second+20*time.Millisecond)
+	send("ath11", "10.2.54.2", 1150, offset+5*time.Second+45*time.Millisecond)
+	jitter = tracker.stats("ath11", start.Add(offset+5*time.Second))
+	if assert.NotNil(t, jitter) {
+		assert.Equal(t, 11, jitter.SampleCount)
+	}
+
+	// Samples age out of the window.
+	assert.Nil(t, tracker.stats("ath11", start.Add(offset+time.Minute)))
+	assert.Empty(t, tracker.flows)
+}
+
+func TestRadio_updateControlJitter(t *testing.T) {
+	radio := &Radio{
+		StationStatuses:   map[string]*NetworkStatus{"red1": {Ssid: "254"}, "blue3": {Ssid: "1114"}},
+		stationInterfaces: map[station]string{red1: "ath11", blue3: "ath16"},
+	}
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		radio.controlJitter.record(controlPacket{
+			interfaceName: "ath11", sourceIp: "10.0.100.5", destIp: "10.2.54.2", destPort: 1110,
+			receivedAt: now.Add(time.Duration(i*20) * time.Millisecond),
+		})
+	}
+
+	radio.updateControlJitter(now)
+	assert.Equal(t, &ControlJitter{SampleCount: 1, P50Ms: 0, P95Ms: 0}, radio.StationStatuses["red1"].ControlJitter)
+	assert.Nil(t, radio.StationStatuses["blue3"].ControlJitter)
+}
@@ -0,0 +1,155 @@
+//go:build linux
+
+package radio
+
+import (
+	"encoding/binary"
+	"errors"
+	"log"
+	"net"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	// Number of bytes captured from the start of each packet, which covers the longest IPv4 header plus the UDP ports.
+	controlPacketSnapLength = 128
+
+	// Offset within the ancillary data available to socket filters at which the link-layer protocol is found (i.e.
+	// SKF_AD_OFF + SKF_AD_PROTOCOL).
+	socketFilterProtocolOffset = -0x1000
+)
+
+// startControlPacketCapture opens a packet socket that sees the UDP packets sent to any of the given ports on every
+// interface in either direction, and sends each one on the given channel. A socket filter discards all other packets in
+// the kernel, so that the capture stays lightweight.
+func startControlPacketCapture(ports []int, packets chan<- controlPacket) error {
+	fd, err := syscall.Socket(
+		syscall.AF_PACKET, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, int(hostToNetworkShort(syscall.ETH_P_ALL)),
+	)
+	if err != nil {
+		return err
+	}
+	if err = syscall.AttachLsf(fd, controlPacketFilter(ports)); err != nil {
+		_ = syscall.Close(fd)
+		return err
+	}
+	// Have the kernel timestamp each packet on arrival, so that scheduling delays in the API don't count as jitter.
+	if err = syscall.SetsockoptInt(fd, syscall.SOL_SOCKET, syscall.SO_TIMESTAMPNS, 1); err != nil {
+		_ = syscall.Close(fd)
+		return err
+	}
+
+	go func() {
+		defer syscall.Close(fd)
+		buffer := make([]byte, controlPacketSnapLength)
+		oob := make([]byte, syscall.CmsgSpace(int(unsafe.Sizeof(syscall.Timespec{}))))
+		interfaceNames := make(map[int]string)
+		for {
+			n, oobn, _, from, err := syscall.Recvmsg(fd, buffer, oob, 0)
+			if errors.Is(err, syscall.EINTR) {
+				continue
+			}
+			if err != nil {
+				log.Printf("Error reading control packets; no longer measuring jitter: %v", err)
+				return
+			}
+			linkAddress, ok := from.(*syscall.SockaddrLinklayer)
+			if !ok {
+				continue
+			}
+			packet, ok := parseControlPacket(buffer[:n])
+			if !ok {
+				continue
+			}
+			// Interfaces are recreated with a new index whenever the Wi-Fi is reloaded, so the cache never goes stale.
+			name, ok := interfaceNames[linkAddress.Ifindex]
+			if !ok {
+				if networkInterface, err := net.InterfaceByIndex(linkAddress.Ifindex); err == nil {
+					name = networkInterface.Name
+				}
+				interfaceNames[linkAddress.Ifindex] = name
+			}
+			packet.interfaceName = name
+			packet.receivedAt = packetTimestamp(oob[:oobn])
+			packets <- packet
+		}
+	}()
+	return nil
+}
+
+// controlPacketFilter returns a socket filter program that only accepts unfragmented IPv4 UDP packets sent to one of
+// the given ports, truncated to the snap length. Offsets are relative to the IP header, since the socket is a datagram
+// one.
+func controlPacketFilter(ports []int) []syscall.SockFilter {
+	dropIndex := 8 + len(ports)
+	acceptIndex := dropIndex + 1
+	jumpTo := func(from, to int) int {
+		return to - from - 1
+	}
+	program := []*syscall.SockFilter{
+		syscall.LsfStmt(syscall.BPF_LD|syscall.BPF_H|syscall.BPF_ABS, socketFilterProtocolOffset),
+		syscall.LsfJump(syscall.BPF_JMP|syscall.BPF_JEQ|syscall.BPF_K, syscall.ETH_P_IP, 0, jumpTo(1, dropIndex)),
+		syscall.LsfStmt(syscall.BPF_LD|syscall.BPF_B|syscall.BPF_ABS, 9),
+		syscall.LsfJump(syscall.BPF_JMP|syscall.BPF_JEQ|syscall.BPF_K, syscall.IPPROTO_UDP, 0, jumpTo(3, dropIndex)),
+		syscall.LsfStmt(syscall.BPF_LD|syscall.BPF_H|syscall.BPF_ABS, 6),
+		syscall.LsfJump(syscall.BPF_JMP|syscall.BPF_JSET|syscall.BPF_K, 0x1fff, jumpTo(5, dropIndex), 0),
+		syscall.LsfStmt(syscall.BPF_LDX|syscall.BPF_B|syscall.BPF_MSH, 0),
+		syscall.LsfStmt(syscall.BPF_LD|syscall.BPF_H|syscall.BPF_IND, 2),
+	}
+	for i, port := range ports {
+		jump := jumpTo(8+i, acceptIndex)
+		program = append(program, syscall.LsfJump(syscall.BPF_JMP|syscall.BPF_JEQ|syscall.BPF_K, port, jump, 0))
+	}
+	program = append(
+		program,
+		syscall.LsfStmt(syscall.BPF_RET|syscall.BPF_K, 0),
+		syscall.LsfStmt(syscall.BPF_RET|syscall.BPF_K, controlPacketSnapLength),
+	)
+	filter := make([]syscall.SockFilter, len(program))
+	for i, instruction := range program {
+		filter[i] = *instruction
+	}
+	return filter
+}
+
+// parseControlPacket parses the addresses and destination port out of the given IPv4 UDP packet, starting at its IP
+// header.
+func parseControlPacket(data []byte) (controlPacket, bool) {
+	if len(data) < 20 || data[0]>>4 != 4 {
+		return controlPacket{}, false
+	}
+	headerLength := int(data[0]&0x0f) * 4
+	if headerLength < 20 || len(data) < headerLength+4 {
+		return controlPacket{}, false
+	}
+	return controlPacket{
+		sourceIp: net.IP(data[12:16]).String(),
+		destIp:   net.IP(data[16:20]).String(),
+		destPort: int(binary.BigEndian.Uint16(data[headerLength+2:])),
+	}, true
+}
+
+// packetTimestamp returns the time at which the kernel received a packet, from the given control messages, or the
+// current time if they don't include it.
+func packetTimestamp(oob []byte) time.Time {
+	messages, err := syscall.ParseSocketControlMessage(oob)
+	if err == nil {
+		for _, message := range messages {
+			if message.Header.Level == syscall.SOL_SOCKET && message.Header.Type == syscall.SCM_TIMESTAMPNS &&
+				len(message.Data) >= int(unsafe.Sizeof(syscall.Timespec{})) {
+				timespec := (*syscall.Timespec)(unsafe.Pointer(&message.Data[0]))
+				return time.Unix(timespec.Unix())
+			}
+		}
+	}
+	return time.Now()
+}
+
+// hostToNetworkShort converts the given 16-bit value from host to network byte order.
+func hostToNetworkShort(value uint16) uint16 {
+	bytes := make([]byte, 2)
+	binary.BigEndian.PutUint16(bytes, value)
+	return binary.LittleEndian.Uint16(bytes)
+}
@@ -0,0 +1,46 @@
+//go:build linux
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseControlPacket(t *testing.T) {
+	header := []byte{
+		0x45, 0x00, 0x00, 0x24, 0x00, 0x00, 0x40, 0x00, 0x40, 0x11, 0x00, 0x00, 10, 0, 100, 5, 10, 2, 54, 2,
+		0xd4, 0x31, 0x04, 0x56,
+	}
+	packet, ok := parseControlPacket(header)
+	if assert.True(t, ok) {
+		assert.Equal(t, controlPacket{sourceIp: "10.0.100.5", destIp: "10.2.54.2", destPort: 1110}, packet)
+	}
+
+	// The port is found after any IP options.
+	withOptions := append(append(append([]byte{0x46}, header[1:20]...), 0x01, 0x01, 0x01, 0x00), header[20:]...)
+	packet, ok = parseControlPacket(withOptions)
+	if assert.True(t, ok) {
+		assert.Equal(t, 1110, packet.destPort)
+	}
+
+	_, ok = parseControlPacket(header[:22])
+	assert.False(t, ok)
+	_, ok = parseControlPacket(append([]byte{0x60}, header[1:]...))
+	assert.False(t, ok)
+}
+
+func TestControlPacketFilter(t *testing.T) {
+	filter := controlPacketFilter([]int{1110, 1150})
+	if assert.Equal(t, 12, len(filter)) {
+		// Each port check jumps to the accepting return, which keeps the snap length.
+		assert.Equal(t, uint8(2), filter[8].Jt)
+		assert.Equal(t, uint8(1), filter[9].Jt)
+		assert.Equal(t, uint32(0), filter[10].K)
+		assert.Equal(t, uint32(controlPacketSnapLength), filter[11].K)
+	}
+	// Failed protocol checks jump to the dropping return.
+	assert.Equal(t, uint8(8), filter[1].Jf)
+	assert.Equal(t, uint8(6), filter[3].Jf)
+	assert.Equal(t, uint8(4), filter[5].Jt)
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package radio
+
+import "errors"
+
+// startControlPacketCapture always fails since packet sockets are only available on Linux.
+func startControlPacketCapture(ports []int, packets chan<- controlPacket) error {
+	return errors.New("packet capture is not supported on this platform")
+}
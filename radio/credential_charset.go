@@ -0,0 +1,48 @@
+package radio
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Characters that can't appear in an SSID or WPA key under any charset, since UCI option values are written between
+// single quotes without any escaping.
+const uciUnsafeCharacters = `'\`
+
+// isExtended returns true if the charset permits more than letters and digits.
+func (charset credentialCharset) isExtended() bool {
+	return charset == credentialCharsetPrintableAscii || charset == credentialCharsetUtf8
+}
+
+// allows returns true if every character of the given SSID or WPA key is permitted by the charset. The characters in
+// alphanumericExtras are additionally permitted under the alphanumeric charset.
+func (charset credentialCharset) allows(value string, isSsid bool, alphanumericExtras string) bool {
+	if !utf8.ValidString(value) {
+		return false
+	}
+	for _, char := range value {
+		isAsciiAlphanumeric := char < unicode.MaxASCII && (unicode.IsLetter(char) || unicode.IsDigit(char))
+		switch {
+		case isAsciiAlphanumeric || strings.ContainsRune(alphanumericExtras, char):
+		case !charset.isExtended(), strings.ContainsRune(uciUnsafeCharacters, char), unicode.IsControl(char):
+			return false
+		case char > unicode.MaxASCII && (!isSsid || charset != credentialCharsetUtf8):
+			return false
+		}
+	}
+	return true
+}
+
+// expecting returns a description of the characters permitted by the charset for use in validation errors, given the
+// description that applies under the alphanumeric charset.
+func (charset credentialCharset) expecting(isSsid bool, alphanumericDescription string) string {
+	switch {
+	case !charset.isExtended():
+		return alphanumericDescription
+	case isSsid && charset == credentialCharsetUtf8:
+		return `UTF-8 other than control characters, ' and \`
+	default:
+		return `printable ASCII other than ' and \`
+	}
+}
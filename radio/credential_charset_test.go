@@ -0,0 +1,64 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCredentialCharset_allows(t *testing.T) {
+	alphanumeric := credentialCharsetAlphanumeric
+	assert.True(t, alphanumeric.allows("Team254", true, ""))
+	assert.True(t, alphanumeric.allows("team-254", true, "-"))
+	assert.False(t, alphanumeric.allows("team-254", true, ""))
+	assert.False(t, alphanumeric.allows("Team 254!", true, "-"))
+	assert.False(t, alphanumeric.allows("Équipe", true, "-"))
+
+	// An unrecognized charset is treated as alphanumeric.
+	assert.False(t, credentialCharset("").allows("team 254", true, ""))
+
+	printableAscii := credentialCharsetPrintableAscii
+	assert.True(t, printableAscii.allows("Offseason #2 (Field A)", true, ""))
+	assert.True(t, printableAscii.allows(`p@ss "w0rd"`, false, ""))
+	assert.False(t, printableAscii.allows("Équipe", true, ""))
+	assert.False(t, printableAscii.allows("tab\there", true, ""))
+	assert.False(t, printableAscii.allows("it's", true, ""))
+	assert.False(t, printableAscii.allows(`back\slash`, false, ""))
+
+	utf8 := credentialCharsetUtf8
+	assert.True(t, utf8.allows("Équipe 254 🤖", true, ""))
+	assert.True(t, utf8.allows("Offseason #2", true, ""))
+	assert.False(t, utf8.allows("Équipe254", false, ""))
+	assert.False(t, utf8.allows("new\nline", true, ""))
+	assert.False(t, utf8.allows("\xff\xfe", true, ""))
+	assert.False(t, utf8.allows("it's", true, ""))
+}
+
+func TestCredentialCharset_expecting(t *testing.T) {
+	assert.Equal(t, "alphanumeric", credentialCharsetAlphanumeric.expecting(true, "alphanumeric"))
+	assert.Equal(
+		t, `printable ASCII other than ' and \`, credentialCharsetPrintableAscii.expecting(true, "alphanumeric"),
+	)
+	assert.Equal(
+		t, `UTF-8 other than control characters, ' and \`, credentialCharsetUtf8.expecting(true, "alphanumeric"),
+	)
+	assert.Equal(t, `printable ASCII other than ' and \`, credentialCharsetUtf8.expecting(false, "alphanumeric"))
+}
+
+func TestGetSsid(t *testing.T) {
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+
+	fakeShell.commandOutput["iwinfo wlan0 info"] = "wlan0     ESSID: \"Offseason #2 (Field A) Équipe\"\n" +
+		"          Access Point: 00:11:22:33:44:55\n"
+	ssid, err := getSsid("wlan0")
+	assert.Nil(t, err)
+	assert.Equal(t, "Offseason #2 (Field A) Équipe", ssid)
+	ssid, bssid, err := getSsidAndBssid("wlan0")
+	assert.Nil(t, err)
+	assert.Equal(t, "Offseason #2 (Field A) Équipe", ssid)
+	assert.Equal(t, "00:11:22:33:44:55", bssid)
+
+	fakeShell.commandOutput["iwinfo wlan0 info"] = "wlan0     ESSID: unknown\n"
+	_, err = getSsid("wlan0")
+	assert.EqualError(t, err, "error parsing iwinfo output for interface wlan0: wlan0     ESSID: unknown\n")
+}
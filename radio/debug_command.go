@@ -0,0 +1,221 @@
+package radio
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Maximum number of bytes of output returned for a debug command; the rest is cut off.
+	maxDebugCommandOutputBytes = 64 * 1024
+
+	// Maximum number of log lines that can be requested from logread through a debug command.
+	maxDebugLogReadLines = 10000
+
+	// Placeholders within the allowed forms of the debug commands, standing in for an argument that varies.
+	debugArgName = "<name>"
+	debugArgMac  = "<mac>"
+)
+
+// Allowed forms of the arguments to each debug command other than ubus calls and logread, all of which only read the
+// state of the radio. Scans are left out since they take the radio off channel.
+var debugCommandForms = map[string][][]string{
+	"iwinfo": {
+		{},
+		{debugArgName, "info"},
+		{debugArgName, "assoclist"},
+		{debugArgName, "txpowerlist"},
+		{debugArgName, "freqlist"},
+		{debugArgName, "countrylist"},
+		{debugArgName, "htmodelist"},
+	},
+	"iw": {
+		{"dev"},
+		{"list"},
+		{"phy"},
+		{"reg", "get"},
+		{"dev", debugArgName, "info"},
+		{"dev", debugArgName, "link"},
+		{"dev", debugArgName, "station", "dump"},
+		{"dev", debugArgName, "station", "get", debugArgMac},
+		{"dev", debugArgName, "survey", "dump"},
+		{"phy", debugArgName, "info"},
+		{"phy", debugArgName, "channels"},
+	},
+	"ubus": {
+		{"list"},
+		{"list", debugArgName},
+	},
+}
+
+// Methods of ubus objects that only read state, and so may be called through a debug command.
+var readOnlyUbusMethods = map[string]struct{}{
+	"assoclist":   {},
+	"board":       {},
+	"countrylist": {},
+	"devices":     {},
+	"dump":        {},
+	"freqlist":    {},
+	"get_clients": {},
+	"get_status":  {},
+	"info":        {},
+	"status":      {},
+	"txpowerlist": {},
+}
+
+var (
+	// Regular expression for an interface, PHY, or ubus object name given as a debug command argument.
+	debugNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+	// Regular expression for a MAC address given as a debug command argument.
+	debugMacRe = regexp.MustCompile(`^([0-9A-Fa-f]{2}:){5}[0-9A-Fa-f]{2}$`)
+)
+
+// DebugCommand represents a read-only diagnostic command to run on the radio on behalf of remote support.
+type DebugCommand struct {
+	// Name of the command: "iwinfo", "iw", "ubus", or "logread".
+	Command string `json:"command"`
+
+	// Arguments to the command, which must be in one of its allowed read-only forms.
+	Args []string `json:"args"`
+}
+
+// DebugCommandResult represents the outcome of running a debug command.
+type DebugCommandResult struct {
+	// Name of the command that was run.
+	Command string `json:"command"`
+
+	// Arguments that the command was run with.
+	Args []string `json:"args"`
+
+	// Combined standard output and standard error of the command.
+	Output string `json:"output"`
+
+	// Whether the output was cut off at 64KiB.
+	IsOutputTruncated bool `json:"isOutputTruncated"`
+
+	// Description of how the command failed (e.g. a non-zero exit status), or blank if it succeeded.
+	Error string `json:"error"`
+
+	// Time taken to run the command, in seconds.
+	ElapsedSec float64 `json:"elapsedSec"`
+}
+
+// Validate checks that the command is one of the allowed diagnostics and is in a form that only reads the state of the
+// radio.
+func (command DebugCommand) Validate() error {
+	var isAllowed bool
+	switch command.Command {
+	case "iwinfo", "iw":
+		isAllowed = matchesDebugCommandForm(command.Args, debugCommandForms[command.Command])
+	case "ubus":
+		isAllowed = matchesDebugCommandForm(command.Args, debugCommandForms["ubus"]) ||
+			isReadOnlyUbusCall(command.Args)
+	case "logread":
+		isAllowed = isReadOnlyLogRead(command.Args)
+	default:
+		return fmt.Errorf("invalid command: %q (expecting iwinfo, iw, ubus, or logread)", command.Command)
+	}
+	if !isAllowed {
+		return fmt.Errorf(
+			"invalid arguments for %s: %q (expecting one of its allowed read-only forms)",
+			command.Command,
+			strings.Join(command.Args, " "),
+		)
+	}
+	return nil
+}
+
+// RunDebugCommand runs the given debug command once it has been validated, returning its output even if it fails.
+func RunDebugCommand(command DebugCommand) (DebugCommandResult, error) {
+	if err := command.Validate(); err != nil {
+		return DebugCommandResult{}, err
+	}
+	startTime := time.Now()
+	output, err := shell.runCommand(command.Command, command.Args...)
+	result := DebugCommandResult{
+		Command:    command.Command,
+		Args:       command.Args,
+		Output:     output,
+		ElapsedSec: time.Since(startTime).Seconds(),
+	}
+	if result.Args == nil {
+		result.Args = []string{}
+	}
+	if len(result.Output) > maxDebugCommandOutputBytes {
+		result.Output = result.Output[:maxDebugCommandOutputBytes]
+		result.IsOutputTruncated = true
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result, nil
+}
+
+// matchesDebugCommandForm returns true if the given arguments are in one of the given forms.
+func matchesDebugCommandForm(args []string, forms [][]string) bool {
+	for _, form := range forms {
+		if len(args) != len(form) {
+			continue
+		}
+		matches := true
+		for i, expected := range form {
+			switch expected {
+			case debugArgName:
+				matches = matches && debugNameRe.MatchString(args[i])
+			case debugArgMac:
+				matches = matches && debugMacRe.MatchString(args[i])
+			default:
+				matches = matches && args[i] == expected
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// isReadOnlyUbusCall returns true if the given ubus arguments call a read-only method, optionally with a JSON object
+// as the message.
+func isReadOnlyUbusCall(args []string) bool {
+	if len(args) < 3 || len(args) > 4 || args[0] != "call" || !debugNameRe.MatchString(args[1]) {
+		return false
+	}
+	if _, ok := readOnlyUbusMethods[args[2]]; !ok {
+		return false
+	}
+	if len(args) == 4 {
+		var message map[string]any
+		return json.Unmarshal([]byte(args[3]), &message) == nil
+	}
+	return true
+}
+
+// isReadOnlyLogRead returns true if the given logread arguments only limit the number of lines and filter them by a
+// pattern, rather than following the log or forwarding it elsewhere.
+func isReadOnlyLogRead(args []string) bool {
+	if len(args)%2 != 0 {
+		return false
+	}
+	for i := 0; i < len(args); i += 2 {
+		switch args[i] {
+		case "-l":
+			lines, err := strconv.Atoi(args[i+1])
+			if err != nil || lines < 1 || lines > maxDebugLogReadLines {
+				return false
+			}
+		case "-e":
+			if args[i+1] == "" {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestDebugCommand_Validate(t *testing.T) {
+	allowed := []DebugCommand{
+		{Command: "iwinfo"},
+		{Command: "iwinfo", Args: []string{"ath1", "assoclist"}},
+		{Command: "iw", Args: []string{"reg", "get"}},
+		{Command: "iw", Args: []string{"dev", "wlan0-1", "station", "get", "00:11:22:33:44:55"}},
+		{Command: "iw", Args: []string{"phy", "phy1", "info"}},
+		{Command: "ubus", Args: []string{"list"}},
+		{Command: "ubus", Args: []string{"list", "network.interface.lan"}},
+		{Command: "ubus", Args: []string{"call", "system", "board"}},
+		{Command: "ubus", Args: []string{"call", "hostapd.wlan0", "get_clients", "{}"}},
+		{Command: "logread"},
+		{Command: "logread", Args: []string{"-l", "100", "-e", "hostapd"}},
+	}
+	for _, command := range allowed {
+		assert.Nil(t, command.Validate(), "%v", command)
+	}
+
+	assert.EqualError(
+		t,
+		DebugCommand{Command: "uci", Args: []string{"show"}}.Validate(),
+		`invalid command: "uci" (expecting iwinfo, iw, ubus, or logread)`,
+	)
+	assert.EqualError(
+		t,
+		DebugCommand{Command: "iwinfo", Args: []string{"ath1", "scan"}}.Validate(),
+		`invalid arguments for iwinfo: "ath1 scan" (expecting one of its allowed read-only forms)`,
+	)
+	denied := []DebugCommand{
+		{Command: "iw", Args: []string{"dev", "wlan0", "set", "txpower", "fixed", "100"}},
+		{Command: "iw", Args: []string{"dev", "-wlan0", "info"}},
+		{Command: "iw", Args: []string{"dev", "wlan0", "station", "get", "all"}},
+		{Command: "ubus", Args: []string{"call", "system", "reboot"}},
+		{Command: "ubus", Args: []string{"call", "network", "dump", "not json"}},
+		{Command: "ubus", Args: []string{"call", "network.wireless", "status", "{}", "extra"}},
+		{Command: "logread", Args: []string{"-f"}},
+		{Command: "logread", Args: []string{"-r", "10.0.100.6", "514"}},
+		{Command: "logread", Args: []string{"-l", "0"}},
+		{Command: "logread", Args: []string{"-e", ""}},
+	}
+	for _, command := range denied {
+		assert.NotNil(t, command.Validate(), "%v", command)
+	}
+}
+
+func TestRunDebugCommand(t *testing.T) {
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["iw reg get"] = "global\ncountry US: DFS-FCC\n"
+	fakeShell.commandOutput["logread -l 10000"] = strings.Repeat("x", maxDebugCommandOutputBytes+1)
+	fakeShell.commandErrors["ubus call hostapd.ath1 get_clients"] = errors.New("exit status 4")
+
+	result, err := RunDebugCommand(DebugCommand{Command: "iw", Args: []string{"reg", "get"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "global\ncountry US: DFS-FCC\n", result.Output)
+	assert.False(t, result.IsOutputTruncated)
+	assert.Equal(t, "", result.Error)
+
+	result, err = RunDebugCommand(DebugCommand{Command: "logread", Args: []string{"-l", "10000"}})
+	assert.Nil(t, err)
+	assert.Equal(t, maxDebugCommandOutputBytes, len(result.Output))
+	assert.True(t, result.IsOutputTruncated)
+
+	// A failing command still returns a result.
+	result, err = RunDebugCommand(DebugCommand{Command: "ubus", Args: []string{"call", "hostapd.ath1", "get_clients"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "exit status 4", result.Error)
+
+	// Commands that aren't allowed are never run.
+	_, err = RunDebugCommand(DebugCommand{Command: "iw", Args: []string{"dev", "ath1", "scan"}})
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, len(fakeShell.commandsRun))
+}
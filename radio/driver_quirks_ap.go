@@ -0,0 +1,74 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"strings"
+	"time"
+)
+
+// driverQuirks describes the special-case behaviors needed to configure a given hardware type and firmware version,
+// which the configuration logic consults instead of checking the hardware type itself.
+type driverQuirks struct {
+	// Whether every station must be unconfigured with a reload of its own before the new configuration is loaded,
+	// since the wireless stack is crash-prone otherwise.
+	clearBeforeConfigure bool
+
+	// Whether the wireless stack is checked for its known failure modes after each reload, so that they can be
+	// mitigated rather than retrying reloads that can't succeed.
+	detectReloadFailures bool
+
+	// Whether the WPA key must also be written to the sae_password option for WPA3 clients to use it.
+	duplicateSaePassword bool
+
+	// Whether a change to nothing but the WPA keys can be made through hostapd's control interface without reloading.
+	softKeyChange bool
+
+	// How long to wait after reloading the Wi-Fi configuration before polling the status, or zero for the default.
+	reloadBackoff time.Duration
+}
+
+// driverQuirkEntry applies a set of quirks to the radios of one hardware type whose firmware version starts with a
+// given prefix.
+type driverQuirkEntry struct {
+	// Hardware type that the entry applies to.
+	radioType RadioType
+
+	// Prefix of the firmware versions that the entry applies to, or blank for all of them.
+	firmwarePrefix string
+
+	// Quirks of the matching radios.
+	quirks driverQuirks
+}
+
+// Table of the quirks of each hardware type and firmware version. Where several entries match a radio, the last one
+// wins, so entries for specific firmware revisions go after the one for their hardware type.
+var driverQuirkTable = []driverQuirkEntry{
+	{radioType: TypeLinksys, quirks: driverQuirks{clearBeforeConfigure: true, detectReloadFailures: true}},
+	{radioType: TypeVividHosting, quirks: driverQuirks{duplicateSaePassword: true, softKeyChange: true}},
+}
+
+// quirksFor returns the quirks of the given hardware type running the given firmware version.
+func quirksFor(radioType RadioType, version string) driverQuirks {
+	var quirks driverQuirks
+	for _, entry := range driverQuirkTable {
+		if entry.radioType == radioType && strings.HasPrefix(version, entry.firmwarePrefix) {
+			quirks = entry.quirks
+		}
+	}
+	return quirks
+}
+
+// driverQuirks returns the quirks of the radio's hardware type and firmware version.
+func (radio *Radio) driverQuirks() driverQuirks {
+	return quirksFor(radio.Type, radio.Version)
+}
+
+// reloadBackoffDuration returns how long to wait after reloading the Wi-Fi configuration before polling the status.
+func (radio *Radio) reloadBackoffDuration() time.Duration {
+	if backoff := radio.driverQuirks().reloadBackoff; backoff > 0 {
+		return backoff
+	}
+	return wifiReloadBackoffDuration
+}
@@ -0,0 +1,68 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestQuirksFor(t *testing.T) {
+	linksysQuirks := driverQuirks{clearBeforeConfigure: true, detectReloadFailures: true}
+	assert.Equal(t, linksysQuirks, quirksFor(TypeLinksys, "OpenWrt 21.02"))
+	vividHostingQuirks := driverQuirks{duplicateSaePassword: true, softKeyChange: true}
+	assert.Equal(t, vividHostingQuirks, quirksFor(TypeVividHosting, "1.2.3"))
+	assert.Equal(t, driverQuirks{}, quirksFor(TypeUnknown, ""))
+
+	// An entry for a specific firmware revision overrides the one for its hardware type.
+	originalTable := driverQuirkTable
+	t.Cleanup(func() { driverQuirkTable = originalTable })
+	driverQuirkTable = append(
+		driverQuirkTable,
+		driverQuirkEntry{
+			radioType:      TypeVividHosting,
+			firmwarePrefix: "2.",
+			quirks:         driverQuirks{duplicateSaePassword: true, reloadBackoff: 8 * time.Second},
+		},
+	)
+	assert.Equal(t, vividHostingQuirks, quirksFor(TypeVividHosting, "1.2.3"))
+	assert.Equal(
+		t,
+		driverQuirks{duplicateSaePassword: true, reloadBackoff: 8 * time.Second},
+		quirksFor(TypeVividHosting, "2.0.1"),
+	)
+}
+
+func TestRadio_reloadBackoffDuration(t *testing.T) {
+	wifiReloadBackoffDuration = 10 * time.Millisecond
+	radio := &Radio{Type: TypeVividHosting, Version: "2.0.1"}
+	assert.Equal(t, 10*time.Millisecond, radio.reloadBackoffDuration())
+
+	originalTable := driverQuirkTable
+	t.Cleanup(func() { driverQuirkTable = originalTable })
+	driverQuirkTable = []driverQuirkEntry{
+		{radioType: TypeVividHosting, firmwarePrefix: "2.", quirks: driverQuirks{reloadBackoff: 20 * time.Millisecond}},
+	}
+	assert.Equal(t, 20*time.Millisecond, radio.reloadBackoffDuration())
+}
+
+func TestRadio_configureStationsHonorsQuirks(t *testing.T) {
+	radio, fakeTree, _ := newVerificationTestRadio(t)
+	originalTable := driverQuirkTable
+	t.Cleanup(func() { driverQuirkTable = originalTable })
+	request := ConfigurationRequest{
+		StationConfigurations: map[string]*StationConfiguration{"red1": {Ssid: "1111", WpaKey: "11111111"}},
+	}
+
+	assert.Nil(t, radio.handleConfigurationRequest(request))
+	assert.Equal(t, "11111111", fakeTree.valuesFromSet["wireless.@wifi-iface[1].sae_password"])
+
+	// Without the quirk, the SAE password is left alone.
+	driverQuirkTable = nil
+	fakeTree.valuesFromSet = make(map[string]string)
+	assert.Nil(t, radio.handleConfigurationRequest(request))
+	assert.Equal(t, "11111111", fakeTree.valuesFromSet["wireless.@wifi-iface[1].key"])
+	assert.NotContains(t, fakeTree.valuesFromSet, "wireless.@wifi-iface[1].sae_password")
+}
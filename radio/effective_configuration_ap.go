@@ -0,0 +1,127 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+// EffectiveConfiguration returns the configuration currently in effect on the radio, in the same form as a request
+// that would produce it. Requests that are still queued are not reflected.
+func (radio *Radio) EffectiveConfiguration() ConfigurationRequest {
+	clientIsolation := radio.ClientIsolation.isEnforced()
+	timeServer := radio.TimeServer
+	configuration := ConfigurationRequest{
+		Channel:               radio.Channel,
+		ChannelBandwidth:      radio.ChannelBandwidth,
+		RedVlans:              radio.RedVlans,
+		BlueVlans:             radio.BlueVlans,
+		StationConfigurations: radio.mergeWithCurrentStations(nil),
+		SyslogIpAddress:       radio.SyslogIpAddress,
+		Country:               radio.Country,
+		ClientIsolation:       &clientIsolation,
+		TimeServer:            &timeServer,
+		Metadata:              radio.ConfigurationMetadata,
+	}
+	if hardwareCapabilityTable[radio.Type].supportsHeOptions {
+		targetWakeTime := radio.TargetWakeTime
+		configuration.BssColor = radio.BssColor
+		configuration.HeGuardInterval = radio.HeGuardInterval
+		configuration.TargetWakeTime = &targetWakeTime
+	}
+	if hardwareCapabilityTable[radio.Type].supportsManagementFrameProtection {
+		configuration.ManagementFrameProtection = radio.commonManagementFrameProtection()
+	}
+	if hardwareCapabilityTable[radio.Type].supportsBeaconTiming {
+		configuration.BeaconIntervalTu = radio.BeaconIntervalTu
+		configuration.DtimPeriod = radio.DtimPeriod
+	}
+	return configuration
+}
+
+// ConfigurationChanges returns a request containing only the parts of the given desired configuration that differ
+// from the effective one, so that applying it does no more work than necessary. Station configurations are only
+// included for stations that change, with the others preserved, even if no station changes at all. Returns an empty
+// request if nothing would change.
+func (radio *Radio) ConfigurationChanges(desired ConfigurationRequest) ConfigurationRequest {
+	current := radio.EffectiveConfiguration()
+	changes := ConfigurationRequest{
+		OverrideChannelGuard: desired.OverrideChannelGuard,
+		Metadata:             desired.Metadata,
+		preserveAllStations:  true,
+	}
+	if desired.Channel != current.Channel {
+		changes.Channel = desired.Channel
+	}
+	if desired.ChannelBandwidth != current.ChannelBandwidth {
+		changes.ChannelBandwidth = desired.ChannelBandwidth
+	}
+	if desired.RedVlans != current.RedVlans || desired.BlueVlans != current.BlueVlans {
+		changes.RedVlans = desired.RedVlans
+		changes.BlueVlans = desired.BlueVlans
+	}
+	if desired.SyslogIpAddress != current.SyslogIpAddress {
+		changes.SyslogIpAddress = desired.SyslogIpAddress
+	}
+	if desired.Country != current.Country {
+		changes.Country = desired.Country
+	}
+	if desired.BssColor != current.BssColor {
+		changes.BssColor = desired.BssColor
+	}
+	if desired.HeGuardInterval != current.HeGuardInterval {
+		changes.HeGuardInterval = desired.HeGuardInterval
+	}
+	if desired.TargetWakeTime != nil &&
+		(current.TargetWakeTime == nil || *desired.TargetWakeTime != *current.TargetWakeTime) {
+		changes.TargetWakeTime = desired.TargetWakeTime
+	}
+	if desired.BeaconIntervalTu != current.BeaconIntervalTu {
+		changes.BeaconIntervalTu = desired.BeaconIntervalTu
+	}
+	if desired.DtimPeriod != current.DtimPeriod {
+		changes.DtimPeriod = desired.DtimPeriod
+	}
+	if desired.ClientIsolation != nil && (*desired.ClientIsolation != radio.ClientIsolation.Wireless ||
+		*desired.ClientIsolation != radio.ClientIsolation.Firewall) {
+		// Compare against each layer so that isolation that is only partially in place gets fully applied or removed.
+		changes.ClientIsolation = desired.ClientIsolation
+	}
+	if desired.TimeServer != nil && *desired.TimeServer != radio.TimeServer {
+		changes.TimeServer = desired.TimeServer
+	}
+	if desired.ManagementFrameProtection != current.ManagementFrameProtection {
+		changes.ManagementFrameProtection = desired.ManagementFrameProtection
+	}
+
+	addStationChange := func(stationName string, config *StationConfiguration) {
+		if changes.StationConfigurations == nil {
+			changes.StationConfigurations = make(map[string]*StationConfiguration)
+			changes.PreserveOmittedStations = true
+		}
+		changes.StationConfigurations[stationName] = config
+	}
+	for station := red1; station <= blue3; station++ {
+		// Stations omitted from the desired configuration are unconfigured, as with a full request.
+		desiredConfig := desired.StationConfigurations[station.String()]
+		currentConfig := current.StationConfigurations[station.String()]
+		if changes.ManagementFrameProtection == "" && desiredConfig != nil && currentConfig != nil &&
+			desiredConfig.ManagementFrameProtection != "" &&
+			desiredConfig.ManagementFrameProtection == radio.ManagementFrameProtection[station.String()] {
+			// The station's mode is reported separately, so an override matching it doesn't change anything unless a
+			// device-wide mode is also being applied.
+			unchangedConfig := *currentConfig
+			unchangedConfig.ManagementFrameProtection = desiredConfig.ManagementFrameProtection
+			currentConfig = &unchangedConfig
+		}
+		if desiredConfig == nil && currentConfig == nil ||
+			desiredConfig != nil && currentConfig != nil && *desiredConfig == *currentConfig {
+			continue
+		}
+		addStationChange(station.String(), desiredConfig)
+	}
+	for stationName, config := range desired.StationConfigurations {
+		// Pass through any unrecognized stations so that validation rejects them rather than them being ignored.
+		if _, ok := current.StationConfigurations[stationName]; !ok {
+			addStationChange(stationName, config)
+		}
+	}
+	return changes
+}
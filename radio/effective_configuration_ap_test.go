@@ -0,0 +1,170 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newEffectiveConfigurationTestRadio() (*Radio, *fakeUciTree) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	radio := &Radio{
+		Channel:          149,
+		ChannelBandwidth: "20MHz",
+		RedVlans:         Vlans102030,
+		BlueVlans:        Vlans405060,
+		Country:          "US",
+		StationStatuses:  map[string]*NetworkStatus{"red1": {Ssid: "254"}, "blue3": {Ssid: "1114"}},
+		Type:             TypeLinksys,
+	}
+	fakeTree.valuesForGet["wireless.@wifi-iface[1].key"] = "11111111"
+	fakeTree.valuesForGet["wireless.@wifi-iface[6].key"] = "66666666"
+	return radio, fakeTree
+}
+
+func TestRadio_EffectiveConfiguration(t *testing.T) {
+	radio, _ := newEffectiveConfigurationTestRadio()
+	configuration := radio.EffectiveConfiguration()
+	assert.Equal(t, 149, configuration.Channel)
+	assert.Equal(t, "20MHz", configuration.ChannelBandwidth)
+	assert.Equal(t, Vlans102030, configuration.RedVlans)
+	assert.Equal(t, Vlans405060, configuration.BlueVlans)
+	assert.Equal(t, "US", configuration.Country)
+	assert.Equal(
+		t,
+		map[string]*StationConfiguration{
+			"red1":  {Ssid: "254", WpaKey: "11111111"},
+			"red2":  nil,
+			"red3":  nil,
+			"blue1": nil,
+			"blue2": nil,
+			"blue3": {Ssid: "1114", WpaKey: "66666666"},
+		},
+		configuration.StationConfigurations,
+	)
+	assert.Nil(t, configuration.TargetWakeTime)
+	if assert.NotNil(t, configuration.ClientIsolation) {
+		assert.False(t, *configuration.ClientIsolation)
+	}
+
+	// 802.11ax options are only included on hardware that supports them.
+	radio.Type = TypeVividHosting
+	radio.BssColor = 7
+	radio.HeGuardInterval = "0.8us"
+	radio.TargetWakeTime = true
+	configuration = radio.EffectiveConfiguration()
+	assert.Equal(t, 7, configuration.BssColor)
+	assert.Equal(t, "0.8us", configuration.HeGuardInterval)
+	if assert.NotNil(t, configuration.TargetWakeTime) {
+		assert.True(t, *configuration.TargetWakeTime)
+	}
+
+	// Beacon timing is included on hardware that supports configuring it.
+	radio.BeaconIntervalTu = 100
+	radio.DtimPeriod = 3
+	configuration = radio.EffectiveConfiguration()
+	assert.Equal(t, 100, configuration.BeaconIntervalTu)
+	assert.Equal(t, 3, configuration.DtimPeriod)
+	radio.Type = TypeUnknown
+	configuration = radio.EffectiveConfiguration()
+	assert.Equal(t, 0, configuration.BeaconIntervalTu)
+	assert.Equal(t, 0, configuration.DtimPeriod)
+	radio.Type = TypeVividHosting
+
+	// Management frame protection is only included if every station has the same mode.
+	radio.ManagementFrameProtection = map[string]managementFrameProtection{}
+	for _, station := range []string{"red1", "red2", "red3", "blue1", "blue2", "blue3"} {
+		radio.ManagementFrameProtection[station] = managementFrameProtectionRequired
+	}
+	assert.Equal(t, managementFrameProtectionRequired, radio.EffectiveConfiguration().ManagementFrameProtection)
+	radio.ManagementFrameProtection["blue2"] = managementFrameProtectionOptional
+	assert.Equal(t, managementFrameProtection(""), radio.EffectiveConfiguration().ManagementFrameProtection)
+}
+
+func TestRadio_ConfigurationChanges(t *testing.T) {
+	radio, _ := newEffectiveConfigurationTestRadio()
+
+	// No changes.
+	changes := radio.ConfigurationChanges(radio.EffectiveConfiguration())
+	assert.True(t, changes.IsEmpty())
+	assert.False(t, changes.PreserveOmittedStations)
+
+	// Single station key change and another station removed.
+	desired := radio.EffectiveConfiguration()
+	desired.StationConfigurations["blue3"] = &StationConfiguration{Ssid: "1114", WpaKey: "abcdefgh"}
+	delete(desired.StationConfigurations, "red1")
+	changes = radio.ConfigurationChanges(desired)
+	assert.Equal(
+		t,
+		ConfigurationRequest{
+			StationConfigurations: map[string]*StationConfiguration{
+				"red1":  nil,
+				"blue3": {Ssid: "1114", WpaKey: "abcdefgh"},
+			},
+			PreserveOmittedStations: true,
+			preserveAllStations:     true,
+		},
+		changes,
+	)
+
+	// Radio-level changes leave the stations alone.
+	desired = radio.EffectiveConfiguration()
+	desired.Channel = 36
+	desired.BlueVlans = Vlans708090
+	desired.OverrideChannelGuard = true
+	changes = radio.ConfigurationChanges(desired)
+	assert.Equal(
+		t,
+		ConfigurationRequest{
+			Channel:              36,
+			RedVlans:             Vlans102030,
+			BlueVlans:            Vlans708090,
+			OverrideChannelGuard: true,
+			preserveAllStations:  true,
+		},
+		changes,
+	)
+
+	// Changing an 802.11ax option.
+	radio.Type = TypeVividHosting
+	desired = radio.EffectiveConfiguration()
+	targetWakeTime := true
+	desired.TargetWakeTime = &targetWakeTime
+	changes = radio.ConfigurationChanges(desired)
+	assert.Equal(t, ConfigurationRequest{TargetWakeTime: &targetWakeTime, preserveAllStations: true}, changes)
+
+	// Partially applied client isolation is reapplied.
+	radio.ClientIsolation = ClientIsolationStatus{Wireless: true}
+	desired = radio.EffectiveConfiguration()
+	assert.False(t, *desired.ClientIsolation)
+	changes = radio.ConfigurationChanges(desired)
+	assert.Equal(t, ConfigurationRequest{ClientIsolation: desired.ClientIsolation, preserveAllStations: true}, changes)
+	radio.ClientIsolation.Firewall = true
+	assert.True(t, radio.ConfigurationChanges(radio.EffectiveConfiguration()).IsEmpty())
+
+	// Changing the management frame protection of the whole device or of a single station.
+	radio.ManagementFrameProtection = map[string]managementFrameProtection{}
+	for _, station := range []string{"red1", "red2", "red3", "blue1", "blue2", "blue3"} {
+		radio.ManagementFrameProtection[station] = managementFrameProtectionOptional
+	}
+	desired = radio.EffectiveConfiguration()
+	desired.ManagementFrameProtection = managementFrameProtectionRequired
+	changes = radio.ConfigurationChanges(desired)
+	assert.Equal(
+		t,
+		ConfigurationRequest{ManagementFrameProtection: managementFrameProtectionRequired, preserveAllStations: true},
+		changes,
+	)
+	desired = radio.EffectiveConfiguration()
+	desired.StationConfigurations["red1"].ManagementFrameProtection = managementFrameProtectionRequired
+	changes = radio.ConfigurationChanges(desired)
+	assert.Equal(t, managementFrameProtectionRequired, changes.StationConfigurations["red1"].ManagementFrameProtection)
+	assert.Equal(t, 1, len(changes.StationConfigurations))
+
+	// A station override matching the station's current mode is not a change.
+	desired.StationConfigurations["red1"].ManagementFrameProtection = managementFrameProtectionOptional
+	assert.True(t, radio.ConfigurationChanges(desired).IsEmpty())
+}
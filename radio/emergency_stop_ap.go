@@ -0,0 +1,69 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"log"
+)
+
+// EmergencyStopStatus represents whether the team networks have been taken off the air field-wide via the API, for
+// incidents where the field must go RF-silent quickly.
+type EmergencyStopStatus struct {
+	// Whether the team networks are currently held down by an emergency stop.
+	IsActive bool `json:"isActive"`
+
+	// Time at which the emergency stop was activated. Null if it isn't active.
+	ActivatedAt *Timestamp `json:"activatedAt"`
+
+	// Number of queued configuration requests that were cancelled when the emergency stop was activated.
+	CancelledRequestCount int `json:"cancelledRequestCount"`
+}
+
+// ClearAllNetworks immediately stops all six team networks from broadcasting and cancels any configuration requests
+// still waiting in the queue, rather than waiting for the radio goroutine to get through them. The networks stay down
+// until the next configuration request is applied. Returns the number of queued requests that were cancelled.
+func (radio *Radio) ClearAllNetworks() int {
+	cancelledCount := radio.configurationRequests.cancelPending()
+
+	radio.emergencyStopMutex.Lock()
+	activatedAt := newTimestamp()
+	radio.EmergencyStop = EmergencyStopStatus{
+		IsActive: true, ActivatedAt: &activatedAt, CancelledRequestCount: cancelledCount,
+	}
+	radio.emergencyStopMutex.Unlock()
+	radio.markStatusChanged()
+
+	// Take every interface down without consulting the wireless configuration, which the radio goroutine may be in
+	// the middle of changing; a configuration that is already being applied takes them down again once it's done.
+	for station := red1; station <= blue3; station++ {
+		wifiInterface := radio.stationInterfaces[station]
+		if _, err := shell.runCommand("hostapd_cli", "-i", wifiInterface, "disable"); err != nil {
+			log.Printf("Error disabling %s for emergency stop: %v", wifiInterface, err)
+		}
+	}
+	radio.raiseAlert(
+		"EMERGENCY_STOP",
+		"Disabled all team networks and cancelled %d queued configuration requests.",
+		cancelledCount,
+	)
+	return cancelledCount
+}
+
+// isEmergencyStopActive returns true if the team networks are currently held down by an emergency stop.
+func (radio *Radio) isEmergencyStopActive() bool {
+	radio.emergencyStopMutex.Lock()
+	defer radio.emergencyStopMutex.Unlock()
+	return radio.EmergencyStop.IsActive
+}
+
+// releaseEmergencyStop ends the emergency stop, if one is active, so that the configuration about to be applied
+// brings the team networks back.
+func (radio *Radio) releaseEmergencyStop() {
+	radio.emergencyStopMutex.Lock()
+	defer radio.emergencyStopMutex.Unlock()
+	if radio.EmergencyStop.IsActive {
+		log.Println("Releasing emergency stop to apply a new configuration.")
+		radio.EmergencyStop = EmergencyStopStatus{}
+	}
+}
@@ -0,0 +1,80 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// setDisableCommandOutputs makes the fake shell accept taking each of the Vivid-Hosting team interfaces down.
+func setDisableCommandOutputs(fakeShell *fakeShell) {
+	for _, wifiInterface := range []string{"ath1", "ath11", "ath12", "ath13", "ath14", "ath15"} {
+		fakeShell.commandOutput["hostapd_cli -i "+wifiInterface+" disable"] = ""
+	}
+}
+
+func TestRadio_ClearAllNetworks(t *testing.T) {
+	radio, _, fakeShell := newVerificationTestRadio(t)
+	setDisableCommandOutputs(fakeShell)
+	firstId, _ := radio.EnqueueConfigurationRequest(ConfigurationRequest{Channel: 149})
+	secondId, _ := radio.EnqueueConfigurationRequest(ConfigurationRequest{Channel: 157})
+
+	assert.Equal(t, 2, radio.ClearAllNetworks())
+	assert.True(t, radio.EmergencyStop.IsActive)
+	assert.NotNil(t, radio.EmergencyStop.ActivatedAt)
+	assert.Equal(t, 2, radio.EmergencyStop.CancelledRequestCount)
+	assert.Contains(t, fakeShell.commandsRun, "hostapd_cli -i ath1 disable")
+	assert.Contains(t, fakeShell.commandsRun, "hostapd_cli -i ath15 disable")
+	for _, id := range []int{firstId, secondId} {
+		record, _ := radio.GetConfigurationRequest(id)
+		assert.Equal(t, requestStateCancelled, record.State)
+	}
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "EMERGENCY_STOP", alerts[0].Type)
+	}
+
+	// The cancelled requests are skipped rather than bringing the networks back.
+	assert.Nil(t, radio.handleConfigurationRequest(<-radio.ConfigurationRequestChannel))
+	assert.True(t, radio.EmergencyStop.IsActive)
+
+	// Ending quiet hours doesn't bring the networks back either; the fake shell fails on any enable command.
+	radio.exitQuietHours()
+
+	// The next configuration request that is applied releases the emergency stop.
+	fakeShell.commandsRun = make(map[string]struct{})
+	request := ConfigurationRequest{
+		StationConfigurations: map[string]*StationConfiguration{"red1": {Ssid: "1111", WpaKey: "11111111"}},
+	}
+	assert.Nil(t, radio.handleConfigurationRequest(request))
+	assert.Equal(t, EmergencyStopStatus{}, radio.EmergencyStop)
+	assert.NotContains(t, fakeShell.commandsRun, "hostapd_cli -i ath1 disable")
+}
+
+func TestRadio_ClearAllNetworksWhileConfiguring(t *testing.T) {
+	radio, _, fakeShell := newVerificationTestRadio(t)
+	setDisableCommandOutputs(fakeShell)
+
+	// The emergency stop arrives while the wireless configuration is being reloaded.
+	disableCount := 0
+	fakeShell.onRunCommand = func(command string) {
+		switch command {
+		case "wifi reload wifi1":
+			if disableCount == 0 {
+				radio.ClearAllNetworks()
+			}
+		case "hostapd_cli -i ath1 disable":
+			disableCount++
+		}
+	}
+	request := ConfigurationRequest{
+		StationConfigurations: map[string]*StationConfiguration{"red1": {Ssid: "1111", WpaKey: "11111111"}},
+	}
+	assert.Nil(t, radio.handleConfigurationRequest(request))
+	assert.True(t, radio.EmergencyStop.IsActive)
+
+	// The networks brought back up by the reload are taken down again.
+	assert.Equal(t, 2, disableCount)
+}
@@ -0,0 +1,95 @@
+package radio
+
+import (
+	"errors"
+)
+
+// Sentinel errors identifying the kind of a failure to configure the radio, so that callers can tell kinds apart using
+// errors.Is rather than by matching messages.
+var (
+	// ErrInvalidChannel identifies a requested channel that the radio can't use.
+	ErrInvalidChannel = errors.New("invalid channel")
+
+	// ErrCommitFailed identifies a failure to commit changes to the UCI configuration.
+	ErrCommitFailed = errors.New("UCI commit failed")
+
+	// ErrVerificationTimeout identifies a configuration that still hadn't taken effect after the last attempt.
+	ErrVerificationTimeout = errors.New("configuration did not take effect")
+
+	// ErrShellTimeout identifies a shell command that was killed for running too long.
+	ErrShellTimeout = errors.New("shell command timed out")
+)
+
+// Machine-readable names of the kinds of error, in the order in which they are checked.
+var errorKinds = []struct {
+	sentinel error
+	name     string
+}{
+	{ErrInvalidChannel, "INVALID_CHANNEL"},
+	{ErrCommitFailed, "COMMIT_FAILED"},
+	{ErrVerificationTimeout, "VERIFICATION_TIMEOUT"},
+	{ErrShellTimeout, "SHELL_TIMEOUT"},
+	{ErrConfigurationRevisionMismatch, "REVISION_MISMATCH"},
+}
+
+// ConfigurationError represents the most recent failure to apply a configuration request.
+type ConfigurationError struct {
+	// Identifier of the request that failed, or zero if it wasn't queued through the API.
+	RequestId int `json:"requestId"`
+
+	// Machine-readable kind of the failure: "INVALID_CHANNEL", "COMMIT_FAILED", "VERIFICATION_TIMEOUT",
+	// "SHELL_TIMEOUT", "REVISION_MISMATCH", or "OTHER".
+	Kind string `json:"kind"`
+
+	// Description of the failure.
+	Message string `json:"message"`
+
+	// Time at which the failure happened.
+	At Timestamp `json:"at"`
+}
+
+// kindError tags an error with the sentinel identifying its kind, without changing its message.
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (err *kindError) Error() string {
+	return err.err.Error()
+}
+
+func (err *kindError) Unwrap() error {
+	return err.err
+}
+
+func (err *kindError) Is(target error) bool {
+	return target == err.kind
+}
+
+// withKind returns the given error tagged as being of the kind identified by the given sentinel, or nil if the error
+// is nil.
+func withKind(kind error, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &kindError{kind: kind, err: err}
+}
+
+// ErrorKind returns the machine-readable name of the kind of the given error, "OTHER" if it isn't of a known kind, or
+// blank if it is nil.
+func ErrorKind(err error) string {
+	if err == nil {
+		return ""
+	}
+	for _, kind := range errorKinds {
+		if errors.Is(err, kind.sentinel) {
+			return kind.name
+		}
+	}
+	return "OTHER"
+}
+
+// newConfigurationError returns a record of the given failure to apply the request with the given identifier.
+func newConfigurationError(requestId int, err error) *ConfigurationError {
+	return &ConfigurationError{RequestId: requestId, Kind: ErrorKind(err), Message: err.Error(), At: newTimestamp()}
+}
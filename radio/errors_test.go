@@ -0,0 +1,58 @@
+package radio
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWithKind(t *testing.T) {
+	assert.Nil(t, withKind(ErrCommitFailed, nil))
+
+	err := withKind(ErrCommitFailed, errors.New("disk full"))
+	assert.EqualError(t, err, "disk full")
+	assert.ErrorIs(t, err, ErrCommitFailed)
+	assert.NotErrorIs(t, err, ErrShellTimeout)
+
+	// The kind survives being wrapped further.
+	err = fmt.Errorf("failed to commit wireless configuration: %w", err)
+	assert.EqualError(t, err, "failed to commit wireless configuration: disk full")
+	assert.ErrorIs(t, err, ErrCommitFailed)
+}
+
+func TestErrorKind(t *testing.T) {
+	assert.Equal(t, "", ErrorKind(nil))
+	assert.Equal(t, "INVALID_CHANNEL", ErrorKind(withKind(ErrInvalidChannel, errors.New("invalid channel: 5"))))
+	assert.Equal(t, "COMMIT_FAILED", ErrorKind(fmt.Errorf("oops: %w", withKind(ErrCommitFailed, errors.New("x")))))
+	assert.Equal(t, "VERIFICATION_TIMEOUT", ErrorKind(withKind(ErrVerificationTimeout, errors.New("x"))))
+	assert.Equal(t, "SHELL_TIMEOUT", ErrorKind(fmt.Errorf("%w: iw killed", ErrShellTimeout)))
+	assert.Equal(t, "REVISION_MISMATCH", ErrorKind(fmt.Errorf("%w (at 3)", ErrConfigurationRevisionMismatch)))
+	assert.Equal(t, "OTHER", ErrorKind(errors.New("oops")))
+}
+
+func TestExecShell_runCommandTimeout(t *testing.T) {
+	originalTimeout := shellCommandTimeout
+	t.Cleanup(func() { shellCommandTimeout = originalTimeout })
+	shellCommandTimeout = 50 * time.Millisecond
+
+	_, err := execShell{}.runCommand("sleep", "5")
+	assert.ErrorIs(t, err, ErrShellTimeout)
+	assert.EqualError(t, err, "shell command timed out: sleep killed after 50ms")
+
+	output, err := execShell{}.runCommand("echo", "hello")
+	assert.Nil(t, err)
+	assert.Equal(t, "hello\n", output)
+}
+
+func TestRadio_commitUciFailure(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeTree.commitError = errors.New("disk full")
+	radio := &Radio{}
+
+	err := radio.commitUci("wireless")
+	assert.EqualError(t, err, "disk full")
+	assert.ErrorIs(t, err, ErrCommitFailed)
+}
@@ -0,0 +1,167 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const (
+	// Maximum number of matches that an event schedule can hold.
+	maxScheduledMatches = 1000
+
+	// Highest valid team number.
+	maxTeamNumber = 25499
+)
+
+// EventSchedule represents the teams expected on each station in each match of the event, against which incoming
+// configuration requests are checked so that a team assignment error in the FMS is caught before it reaches the field.
+type EventSchedule struct {
+	// Matches in the schedule, in any order.
+	Matches []ScheduledMatch `json:"matches"`
+}
+
+// ScheduledMatch represents the teams expected on the field in a single match of the event schedule.
+type ScheduledMatch struct {
+	// Label of the match, as given in the matchSlot of the configuration requests for it (e.g. "Q12").
+	Slot string `json:"slot"`
+
+	// Number of the team expected on each station, keyed by alliance and number (e.g. "red1", "blue3"). Stations that
+	// are omitted are expected to be left without a team.
+	Teams map[string]int `json:"teams"`
+}
+
+// Validate checks that the schedule's match slots, stations, and team numbers are valid.
+func (schedule EventSchedule) Validate() error {
+	if len(schedule.Matches) > maxScheduledMatches {
+		return fmt.Errorf("too many matches: %d (expecting at most %d)", len(schedule.Matches), maxScheduledMatches)
+	}
+	slots := make(map[string]bool)
+	for _, match := range schedule.Matches {
+		if match.Slot == "" || !matchSlotRe.MatchString(match.Slot) {
+			return fmt.Errorf(
+				"invalid match slot: %q (expecting 1-32 letters, digits, spaces, periods, underscores, hashes, or "+
+					"hyphens)",
+				match.Slot,
+			)
+		}
+		if slots[match.Slot] {
+			return fmt.Errorf("duplicate match slot: %s", match.Slot)
+		}
+		slots[match.Slot] = true
+
+		for stationName := range match.Teams {
+			if _, ok := parseStation(stationName); !ok {
+				return fmt.Errorf("invalid station in match %s: %s", match.Slot, stationName)
+			}
+		}
+		teams := make(map[int]string)
+		for station := red1; station <= blue3; station++ {
+			stationName := station.String()
+			teamNumber, ok := match.Teams[stationName]
+			if !ok {
+				continue
+			}
+			if teamNumber < 1 || teamNumber > maxTeamNumber {
+				return fmt.Errorf(
+					"invalid team number for %s in match %s: %d (expecting 1-%d)",
+					stationName,
+					match.Slot,
+					teamNumber,
+					maxTeamNumber,
+				)
+			}
+			if otherStation, ok := teams[teamNumber]; ok {
+				return fmt.Errorf(
+					"team %d is on both %s and %s in match %s", teamNumber, otherStation, stationName, match.Slot,
+				)
+			}
+			teams[teamNumber] = stationName
+		}
+	}
+	return nil
+}
+
+// GetEventSchedule returns the event schedule that configuration requests are checked against.
+func (radio *Radio) GetEventSchedule() EventSchedule {
+	radio.eventScheduleMutex.Lock()
+	defer radio.eventScheduleMutex.Unlock()
+	return radio.eventSchedule
+}
+
+// SetEventSchedule validates the given event schedule and checks subsequent configuration requests against it. An
+// empty schedule disables the checks.
+func (radio *Radio) SetEventSchedule(schedule EventSchedule) error {
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+
+	radio.eventScheduleMutex.Lock()
+	defer radio.eventScheduleMutex.Unlock()
+	radio.eventSchedule = schedule
+	return nil
+}
+
+// scheduleMismatches returns a description of each way in which the given configuration request disagrees with the
+// event schedule for the match slot that it declares. Returns nil if it agrees, or if there is no schedule or the
+// request doesn't declare a match slot.
+func (radio *Radio) scheduleMismatches(request ConfigurationRequest) []string {
+	schedule := radio.GetEventSchedule()
+	if len(schedule.Matches) == 0 || request.MatchSlot == "" {
+		return nil
+	}
+	var match *ScheduledMatch
+	for i := range schedule.Matches {
+		if schedule.Matches[i].Slot == request.MatchSlot {
+			match = &schedule.Matches[i]
+			break
+		}
+	}
+	if match == nil {
+		return []string{fmt.Sprintf("match %s isn't in the event schedule", request.MatchSlot)}
+	}
+
+	scheduledStations := make(map[string]string)
+	for stationName, teamNumber := range match.Teams {
+		scheduledStations[strconv.Itoa(teamNumber)] = stationName
+	}
+	var mismatches []string
+	for station := red1; station <= blue3; station++ {
+		config, ok := request.StationConfigurations[station.String()]
+		if !ok && request.preservesOmittedStations() {
+			continue
+		}
+		expectedTeam := ""
+		if teamNumber, ok := match.Teams[station.String()]; ok {
+			expectedTeam = strconv.Itoa(teamNumber)
+		}
+		team := ""
+		if config != nil {
+			team = config.Ssid
+		}
+		if team == expectedTeam {
+			continue
+		}
+
+		var mismatch string
+		switch {
+		case team == "":
+			mismatch = fmt.Sprintf("station %s has no team but the schedule has team %s", station, expectedTeam)
+		case expectedTeam == "":
+			mismatch = fmt.Sprintf("station %s has team %s but the schedule has no team there", station, team)
+		default:
+			mismatch = fmt.Sprintf("station %s has team %s but the schedule has team %s", station, team, expectedTeam)
+		}
+		if team != "" {
+			if scheduledStation, ok := scheduledStations[team]; ok {
+				mismatch += fmt.Sprintf(" (team %s is scheduled on %s)", team, scheduledStation)
+			} else {
+				mismatch += fmt.Sprintf(" (team %s isn't scheduled in match %s)", team, match.Slot)
+			}
+		}
+		mismatches = append(mismatches, mismatch)
+	}
+	return mismatches
+}
@@ -0,0 +1,137 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEventSchedule_Validate(t *testing.T) {
+	match := func(slot string, teams map[string]int) ScheduledMatch {
+		return ScheduledMatch{Slot: slot, Teams: teams}
+	}
+	assert.Nil(t, EventSchedule{}.Validate())
+	assert.Nil(
+		t,
+		EventSchedule{Matches: []ScheduledMatch{
+			match("Q1", map[string]int{"red1": 254, "red2": 1114, "blue3": 25499}),
+			match("Q2", nil),
+		}}.Validate(),
+	)
+
+	assert.EqualError(
+		t,
+		EventSchedule{Matches: []ScheduledMatch{match("", nil)}}.Validate(),
+		"invalid match slot: \"\" (expecting 1-32 letters, digits, spaces, periods, underscores, hashes, or hyphens)",
+	)
+	assert.EqualError(
+		t, EventSchedule{Matches: []ScheduledMatch{match("Q1", nil), match("Q1", nil)}}.Validate(),
+		"duplicate match slot: Q1",
+	)
+	assert.EqualError(
+		t,
+		EventSchedule{Matches: []ScheduledMatch{match("Q1", map[string]int{"red4": 254})}}.Validate(),
+		"invalid station in match Q1: red4",
+	)
+	assert.EqualError(
+		t,
+		EventSchedule{Matches: []ScheduledMatch{match("Q1", map[string]int{"red1": 0})}}.Validate(),
+		"invalid team number for red1 in match Q1: 0 (expecting 1-25499)",
+	)
+	assert.EqualError(
+		t,
+		EventSchedule{Matches: []ScheduledMatch{match("Q1", map[string]int{"red1": 254, "red2": 254})}}.Validate(),
+		"team 254 is on both red1 and red2 in match Q1",
+	)
+
+	tooMany := EventSchedule{}
+	for i := 0; i <= maxScheduledMatches; i++ {
+		tooMany.Matches = append(tooMany.Matches, match(fmt.Sprintf("Q%d", i), nil))
+	}
+	assert.EqualError(t, tooMany.Validate(), "too many matches: 1001 (expecting at most 1000)")
+}
+
+func TestRadio_scheduleMismatches(t *testing.T) {
+	radio := &Radio{ConfigurationRequestChannel: make(chan ConfigurationRequest, 10)}
+	config := func(ssid string) *StationConfiguration {
+		return &StationConfiguration{Ssid: ssid, WpaKey: "12345678"}
+	}
+	request := ConfigurationRequest{
+		MatchSlot: "Q12",
+		StationConfigurations: map[string]*StationConfiguration{
+			"red1": config("254"), "red2": config("1114"), "red3": config("2056"), "blue1": config("1678"),
+			"blue2": config("148"), "blue3": config("118"),
+		},
+	}
+
+	// Nothing is checked without a schedule.
+	assert.Nil(t, radio.scheduleMismatches(request))
+
+	assert.Nil(
+		t,
+		radio.SetEventSchedule(EventSchedule{Matches: []ScheduledMatch{{
+			Slot: "Q12",
+			Teams: map[string]int{
+				"red1": 254, "red2": 1114, "red3": 2056, "blue1": 1678, "blue2": 148, "blue3": 118,
+			},
+		}}}),
+	)
+	assert.Nil(t, radio.scheduleMismatches(request))
+
+	// Requests that don't declare a match aren't checked.
+	request.MatchSlot = ""
+	assert.Nil(t, radio.scheduleMismatches(request))
+	request.MatchSlot = "Q13"
+	assert.Equal(t, []string{"match Q13 isn't in the event schedule"}, radio.scheduleMismatches(request))
+
+	// Swapped and missing teams are described.
+	request.MatchSlot = "Q12"
+	request.StationConfigurations["red1"] = config("1678")
+	request.StationConfigurations["blue1"] = config("254")
+	request.StationConfigurations["red3"] = config("971")
+	request.StationConfigurations["blue3"] = nil
+	assert.Equal(
+		t,
+		[]string{
+			"station red1 has team 1678 but the schedule has team 254 (team 1678 is scheduled on blue1)",
+			"station red3 has team 971 but the schedule has team 2056 (team 971 isn't scheduled in match Q12)",
+			"station blue1 has team 254 but the schedule has team 1678 (team 254 is scheduled on red1)",
+			"station blue3 has no team but the schedule has team 118",
+		},
+		radio.scheduleMismatches(request),
+	)
+
+	// Omitted stations only count as left without a team if they aren't being preserved.
+	request.StationConfigurations = map[string]*StationConfiguration{"red2": config("1114")}
+	assert.Equal(t, 5, len(radio.scheduleMismatches(request)))
+	request.PreserveOmittedStations = true
+	assert.Nil(t, radio.scheduleMismatches(request))
+
+	// Stations that the schedule leaves empty are expected to stay empty.
+	assert.Nil(t, radio.SetEventSchedule(EventSchedule{Matches: []ScheduledMatch{{Slot: "Q12"}}}))
+	assert.Equal(
+		t,
+		[]string{
+			"station red2 has team 1114 but the schedule has no team there (team 1114 isn't scheduled in match Q12)",
+		},
+		radio.scheduleMismatches(request),
+	)
+
+	// Mismatches are recorded along with the request when it is queued.
+	id, err := radio.EnqueueConfigurationRequest(request)
+	assert.Nil(t, err)
+	record, _ := radio.GetConfigurationRequest(id)
+	assert.Equal(t, radio.scheduleMismatches(request), record.ScheduleMismatches)
+	request.MatchSlot = ""
+	id, err = radio.EnqueueConfigurationRequest(request)
+	assert.Nil(t, err)
+	record, _ = radio.GetConfigurationRequest(id)
+	assert.Nil(t, record.ScheduleMismatches)
+
+	// Invalid schedules are rejected.
+	assert.NotNil(t, radio.SetEventSchedule(EventSchedule{Matches: []ScheduledMatch{{Slot: ""}}}))
+	assert.Equal(t, 1, len(radio.GetEventSchedule().Matches))
+}
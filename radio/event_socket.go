@@ -0,0 +1,130 @@
+package radio
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// How long to wait for a slow consumer to drain the event socket before dropping an event.
+	eventSocketWriteTimeout = 100 * time.Millisecond
+
+	// Longest path that can be given for a Unix socket on Linux.
+	maxEventSocketPathLength = 107
+)
+
+// eventType represents the kind of event published to the event socket.
+type eventType string
+
+const (
+	// The configuration stage of the radio changed; the data is a StatusTransition.
+	eventTypeStatusTransition eventType = "STATUS_TRANSITION"
+
+	// A monitoring poll completed; the data is a MonitoringSample.
+	eventTypeMonitoringSample eventType = "MONITORING_SAMPLE"
+
+	// The API started up for the first time since the radio booted; the data is a BootStatus.
+	eventTypeBoot eventType = "BOOT"
+
+	// Clients started connecting to a team network in a loop; the data is an AssociationStorm. Only published by the
+	// access point.
+	eventTypeAssociationStorm eventType = "ASSOCIATION_STORM"
+)
+
+// Event represents a single record published to the event socket for other daemons running on the radio.
+type Event struct {
+	// Time at which the event happened.
+	Time Timestamp `json:"time"`
+
+	// Kind of event, which determines the shape of the data.
+	Type eventType `json:"type"`
+
+	// Details of the event.
+	Data any `json:"data"`
+}
+
+// eventSocketPublisher sends events to the Unix datagram socket of a co-resident daemon; it is shared between the
+// radio and web goroutines.
+type eventSocketPublisher struct {
+	mutex sync.Mutex
+
+	// Path of the socket that the connection is to, which is redialled if the settings change.
+	path       string
+	connection *net.UnixConn
+
+	// Whether the most recent event couldn't be delivered, so that a missing consumer is only logged once.
+	isFailing bool
+}
+
+// validateEventSocketPath checks that the given event socket path is blank or usable as a Unix socket address.
+func validateEventSocketPath(path string) error {
+	if path != "" && (!filepath.IsAbs(path) || len(path) > maxEventSocketPathLength) {
+		return fmt.Errorf(
+			"invalid eventSocketPath: %s (expecting an absolute path of up to %d characters)",
+			path,
+			maxEventSocketPathLength,
+		)
+	}
+	return nil
+}
+
+// publishEvent sends an event of the given type and data as a JSON line to the event socket, if one is configured.
+// Events are dropped if no daemon is listening or it can't keep up, so that a consumer can never hold up the radio.
+func (radio *Radio) publishEvent(eventType eventType, data any) {
+	path := radio.GetSettings().EventSocketPath
+	if path == "" {
+		return
+	}
+	eventJson, err := json.Marshal(Event{Time: newTimestamp(), Type: eventType, Data: data})
+	if err != nil {
+		log.Printf("Error marshalling %s event: %v", eventType, err)
+		return
+	}
+	radio.eventSocket.publish(path, append(eventJson, '\n'))
+}
+
+// publish writes the given datagram to the socket at the given path, connecting to it first if needed.
+func (publisher *eventSocketPublisher) publish(path string, datagram []byte) {
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+
+	if publisher.connection != nil && publisher.path != path {
+		_ = publisher.connection.Close()
+		publisher.connection = nil
+	}
+	err := publisher.write(path, datagram)
+	if err != nil && publisher.connection != nil {
+		// The consumer may have restarted and rebound the socket, so reconnect for the next event.
+		_ = publisher.connection.Close()
+		publisher.connection = nil
+	}
+	if err != nil && !publisher.isFailing {
+		log.Printf("Error publishing events to %s; dropping them until it accepts them again: %v", path, err)
+	} else if err == nil && publisher.isFailing {
+		log.Printf("Publishing events to %s again.", path)
+	}
+	publisher.isFailing = err != nil
+}
+
+// write sends the given datagram over the connection to the socket at the given path, which the caller must hold the
+// mutex for.
+func (publisher *eventSocketPublisher) write(path string, datagram []byte) error {
+	if publisher.connection == nil {
+		connection, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: path, Net: "unixgram"})
+		if err != nil {
+			return err
+		}
+		publisher.path = path
+		publisher.connection = connection
+	}
+	if err := publisher.connection.SetWriteDeadline(time.Now().Add(eventSocketWriteTimeout)); err != nil {
+		return err
+	}
+	_, err := publisher.connection.Write(datagram)
+	return err
+}
@@ -0,0 +1,88 @@
+package radio
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listenForEvents binds a Unix datagram socket at the given path on behalf of a consuming daemon.
+func listenForEvents(t *testing.T, path string) *net.UnixConn {
+	listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	return listener
+}
+
+// readEvent returns the next event received by the given consumer socket.
+func readEvent(t *testing.T, listener *net.UnixConn) map[string]any {
+	buffer := make([]byte, 65536)
+	_ = listener.SetReadDeadline(time.Now().Add(time.Second))
+	length, err := listener.Read(buffer)
+	if !assert.Nil(t, err) {
+		return nil
+	}
+	assert.True(t, strings.HasSuffix(string(buffer[:length]), "\n"))
+	var event map[string]any
+	assert.Nil(t, json.Unmarshal(buffer[:length], &event))
+	return event
+}
+
+func TestRadio_publishEvent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.sock")
+	radio := &Radio{settings: defaultSettings()}
+
+	// Nothing is published while the socket is disabled or no daemon is listening.
+	radio.setStatus(statusConfiguring)
+	radio.settings.EventSocketPath = path
+	radio.setStatus(statusActive)
+	assert.True(t, radio.eventSocket.isFailing)
+
+	listener := listenForEvents(t, path)
+	radio.setStatus(statusConfiguring)
+	event := readEvent(t, listener)
+	assert.Equal(t, "STATUS_TRANSITION", event["type"])
+	assert.NotEmpty(t, event["time"].(map[string]any)["wallclock"])
+	data := event["data"].(map[string]any)
+	assert.Equal(t, "ACTIVE", data["from"])
+	assert.Equal(t, "CONFIGURING", data["to"])
+	assert.False(t, radio.eventSocket.isFailing)
+
+	radio.MonitoredAt = newTimestamp()
+	radio.recordMonitoringSample()
+	event = readEvent(t, listener)
+	assert.Equal(t, "MONITORING_SAMPLE", event["type"])
+	sample := event["data"].(map[string]any)
+	assert.Contains(t, sample, "networks")
+	assert.Equal(t, float64(radio.MonitoredAt.MonotonicNs), sample["monitoredAt"].(map[string]any)["monotonicNs"])
+}
+
+func TestEventSocketPublisher_publishReconnects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.sock")
+	var publisher eventSocketPublisher
+	listener := listenForEvents(t, path)
+	publisher.publish(path, []byte("first\n"))
+	buffer := make([]byte, 64)
+	length, err := listener.Read(buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, "first\n", string(buffer[:length]))
+
+	// A consumer that restarts and rebinds the socket receives events again.
+	_ = listener.Close()
+	publisher.publish(path, []byte("dropped\n"))
+	assert.Nil(t, os.Remove(path))
+	assert.True(t, publisher.isFailing)
+	listener = listenForEvents(t, path)
+	publisher.publish(path, []byte("second\n"))
+	assert.False(t, publisher.isFailing)
+	length, err = listener.Read(buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, "second\n", string(buffer[:length]))
+}
@@ -1,7 +1,10 @@
 package radio
 
 import (
+	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"strings"
 	"testing"
 )
@@ -21,9 +24,15 @@ type fakeShell struct {
 	// Map of commands to their error response, for tests to set. A given command should only appear once between
 	// commandOutput and commandErrors.
 	commandErrors map[string]error
+
+	// Optional function called with each command before its response is looked up, for tests to change the fake's
+	// responses at a given point in a sequence of commands without racing against the code under test.
+	onRunCommand func(command string)
 }
 
 func newFakeShell(t *testing.T) *fakeShell {
+	// Restore the real shell once the test is over so that later tests don't report failures against this one.
+	t.Cleanup(func() { shell = execShell{} })
 	return &fakeShell{
 		t:             t,
 		commandsRun:   make(map[string]struct{}),
@@ -35,6 +44,9 @@ func newFakeShell(t *testing.T) *fakeShell {
 func (shell *fakeShell) runCommand(command string, args ...string) (string, error) {
 	fullCommand := strings.Join(append([]string{command}, args...), " ")
 	shell.commandsRun[fullCommand] = struct{}{}
+	if shell.onRunCommand != nil {
+		shell.onRunCommand(fullCommand)
+	}
 	if output, ok := shell.commandOutput[fullCommand]; ok {
 		return output, nil
 	}
@@ -58,9 +70,36 @@ func (shell *fakeShell) startCommand(command string, args ...string) error {
 	return nil
 }
 
+func (shell *fakeShell) streamCommand(
+	ctx context.Context, output io.Writer, command string, args ...string,
+) error {
+	fullCommand := strings.Join(append([]string{command}, args...), " ")
+	shell.commandsRun[fullCommand] = struct{}{}
+	if commandOutput, ok := shell.commandOutput[fullCommand]; ok {
+		_, err := io.WriteString(output, commandOutput)
+		return err
+	}
+	if err, ok := shell.commandErrors[fullCommand]; ok {
+		return err
+	}
+	assert.Fail(shell.t, "unexpected command: "+fullCommand)
+	return nil
+}
+
 // reset clears the state of the fake shell.
 func (shell *fakeShell) reset() {
 	shell.commandsRun = make(map[string]struct{})
 	shell.commandOutput = make(map[string]string)
 	shell.commandErrors = make(map[string]error)
+	shell.onRunCommand = nil
+}
+
+// blockingShell stubs the shellWrapper interface such that streamed commands run until their context is cancelled.
+type blockingShell struct {
+	*fakeShell
+}
+
+func (shell blockingShell) streamCommand(ctx context.Context, output io.Writer, command string, args ...string) error {
+	<-ctx.Done()
+	return errors.New("signal: killed")
 }
@@ -3,30 +3,41 @@ package radio
 import (
 	"fmt"
 	"github.com/digineo/go-uci"
+	"strings"
 )
 
 // fakeUciTree stubs the uci.Tree interface for testing purposes.
 type fakeUciTree struct {
-	valuesForGet  map[string]string
-	valuesFromSet map[string]string
-	setCount      int
-	commitCount   int
+	valuesForGet   map[string]string
+	valuesFromSet  map[string]string
+	sectionsForGet map[string][]string
+	setCount       int
+	commitCount    int
+	commitError    error
+	revertCount    int
+	loadedConfigs  []string
 }
 
 func newFakeUciTree() *fakeUciTree {
-	return &fakeUciTree{valuesForGet: make(map[string]string), valuesFromSet: make(map[string]string)}
+	return &fakeUciTree{
+		valuesForGet:   make(map[string]string),
+		valuesFromSet:  make(map[string]string),
+		sectionsForGet: make(map[string][]string),
+	}
 }
 
 // reset clears the state of the fake UCI tree.
 func (tree *fakeUciTree) reset() {
 	tree.valuesForGet = make(map[string]string)
 	tree.valuesFromSet = make(map[string]string)
+	tree.sectionsForGet = make(map[string][]string)
 	tree.setCount = 0
 	tree.commitCount = 0
+	tree.loadedConfigs = nil
 }
 
 func (tree *fakeUciTree) SetType(config, section, option string, typ uci.OptionType, values ...string) bool {
-	tree.valuesFromSet[fmt.Sprintf("%s.%s.%s", config, section, option)] = values[0]
+	tree.valuesFromSet[fmt.Sprintf("%s.%s.%s", config, section, option)] = strings.Join(values, " ")
 	tree.setCount++
 	return true
 }
@@ -52,24 +63,50 @@ func (tree *fakeUciTree) DelSection(config, section string) {
 }
 
 func (tree *fakeUciTree) Commit() error {
+	if tree.commitError != nil {
+		return tree.commitError
+	}
+	// Committed values become visible to subsequent reads, as they would be after reloading the configuration.
+	for key, value := range tree.valuesFromSet {
+		if value == "***DELETED***" {
+			delete(tree.valuesForGet, key)
+		} else if value != "***ADDED***" {
+			tree.valuesForGet[key] = value
+		}
+	}
 	tree.commitCount++
 	return nil
 }
 
 func (tree *fakeUciTree) LoadConfig(name string, forceReload bool) error {
-	panic("not implemented")
+	tree.loadedConfigs = append(tree.loadedConfigs, name)
+	return nil
 }
 
 func (tree *fakeUciTree) Revert(configs ...string) {
-	panic("not implemented")
+	for key := range tree.valuesFromSet {
+		for _, config := range configs {
+			if strings.HasPrefix(key, config+".") {
+				delete(tree.valuesFromSet, key)
+			}
+		}
+		if len(configs) == 0 {
+			delete(tree.valuesFromSet, key)
+		}
+	}
+	tree.revertCount++
 }
 
 func (tree *fakeUciTree) GetSections(config, secType string) ([]string, bool) {
-	panic("not implemented")
+	sections, ok := tree.sectionsForGet[fmt.Sprintf("%s.%s", config, secType)]
+	return sections, ok
 }
 
 func (tree *fakeUciTree) Get(config, section, option string) ([]string, bool) {
-	panic("not implemented")
+	if value, ok := tree.valuesForGet[fmt.Sprintf("%s.%s.%s", config, section, option)]; ok {
+		return []string{value}, true
+	}
+	return nil, true
 }
 
 func (tree *fakeUciTree) GetBool(config, section, option string) (bool, bool) {
@@ -0,0 +1,170 @@
+// This file is only included in builds with fault injection enabled, which are meant for integration testing and must
+// never be deployed at an event.
+//go:build faultinjection
+
+package radio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/digineo/go-uci"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Faults currently armed; shared between the radio and web goroutines.
+var faultInjector faultRegistry
+
+// faultType represents a kind of failure that can be injected into the radio's interactions with the system.
+type faultType string
+
+const (
+	// Make committing UCI changes fail.
+	faultUciCommitFailure faultType = "UCI_COMMIT_FAILURE"
+
+	// Make reloading the Wi-Fi configuration hang for the fault's delay and then fail as if it had timed out.
+	faultWifiReloadTimeout faultType = "WIFI_RELOAD_TIMEOUT"
+
+	// Make iwinfo return truncated output followed by garbage.
+	faultGarbledIwinfo faultType = "GARBLED_IWINFO"
+)
+
+// Fault represents a failure armed to be injected the next time the corresponding operation is performed.
+type Fault struct {
+	// Kind of failure to inject.
+	Type faultType `json:"type"`
+
+	// Number of times to inject the failure before disarming it. Zero keeps it armed until the faults are cleared.
+	Count int `json:"count"`
+
+	// How long to hang before failing, in milliseconds. Only applicable to WIFI_RELOAD_TIMEOUT.
+	DelayMs int `json:"delayMs"`
+
+	// Number of times the failure has been injected so far.
+	TriggeredCount int `json:"triggeredCount"`
+}
+
+// faultRegistry holds the armed faults.
+type faultRegistry struct {
+	mutex  sync.Mutex
+	faults []Fault
+}
+
+func init() {
+	log.Println("Fault injection is enabled; this build must not be used at an event.")
+	shell = faultInjectingShell{shellWrapper: shell}
+	if staging, ok := uciTree.(*uciStagingTree); ok {
+		// Inject faults beneath the staging layer so that failed commits exercise its rollback.
+		staging.base = faultInjectingUciTree{Tree: staging.base}
+	} else {
+		uciTree = faultInjectingUciTree{Tree: uciTree}
+	}
+}
+
+// InjectFault arms the given fault, in addition to any that are already armed.
+func InjectFault(fault Fault) error {
+	switch fault.Type {
+	case faultUciCommitFailure, faultWifiReloadTimeout, faultGarbledIwinfo:
+	default:
+		return fmt.Errorf("invalid fault type: %s", fault.Type)
+	}
+	if fault.Count < 0 {
+		return fmt.Errorf("invalid count: %d", fault.Count)
+	}
+	if fault.DelayMs < 0 {
+		return fmt.Errorf("invalid delayMs: %d", fault.DelayMs)
+	}
+
+	faultInjector.mutex.Lock()
+	defer faultInjector.mutex.Unlock()
+	fault.TriggeredCount = 0
+	faultInjector.faults = append(faultInjector.faults, fault)
+	log.Printf("Armed fault: %+v", fault)
+	return nil
+}
+
+// GetFaults returns the faults that are currently armed, in the order they were armed.
+func GetFaults() []Fault {
+	faultInjector.mutex.Lock()
+	defer faultInjector.mutex.Unlock()
+	faults := make([]Fault, len(faultInjector.faults))
+	copy(faults, faultInjector.faults)
+	return faults
+}
+
+// ClearFaults disarms all faults.
+func ClearFaults() {
+	faultInjector.mutex.Lock()
+	defer faultInjector.mutex.Unlock()
+	faultInjector.faults = nil
+}
+
+// trigger returns the first armed fault of the given type and counts it as injected, disarming it if it has been
+// injected as many times as requested. Returns false if no such fault is armed.
+func (registry *faultRegistry) trigger(faultType faultType) (Fault, bool) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	for i := range registry.faults {
+		fault := &registry.faults[i]
+		if fault.Type != faultType {
+			continue
+		}
+		fault.TriggeredCount++
+		triggered := *fault
+		if fault.Count > 0 && fault.TriggeredCount >= fault.Count {
+			registry.faults = append(registry.faults[:i], registry.faults[i+1:]...)
+		}
+		log.Printf("Injecting fault: %s", faultType)
+		return triggered, true
+	}
+	return Fault{}, false
+}
+
+// faultInjectingShell is an implementation of the shellWrapper interface that injects armed faults into the commands
+// run by the wrapped shell.
+type faultInjectingShell struct {
+	shellWrapper
+}
+
+func (shell faultInjectingShell) runCommand(command string, args ...string) (string, error) {
+	if command == "wifi" && len(args) > 0 && args[0] == "reload" {
+		if fault, ok := faultInjector.trigger(faultWifiReloadTimeout); ok {
+			time.Sleep(time.Duration(fault.DelayMs) * time.Millisecond)
+			return "", fmt.Errorf("injected fault: %s timed out", strings.Join(append([]string{command}, args...), " "))
+		}
+	}
+	output, err := shell.shellWrapper.runCommand(command, args...)
+	if command == "iwinfo" && err == nil {
+		if _, ok := faultInjector.trigger(faultGarbledIwinfo); ok {
+			output = output[:len(output)/2] + "\x00\xff\xfe#@!\n"
+		}
+	}
+	return output, err
+}
+
+func (shell faultInjectingShell) startCommand(command string, args ...string) error {
+	return shell.shellWrapper.startCommand(command, args...)
+}
+
+func (shell faultInjectingShell) streamCommand(
+	ctx context.Context, output io.Writer, command string, args ...string,
+) error {
+	return shell.shellWrapper.streamCommand(ctx, output, command, args...)
+}
+
+// faultInjectingUciTree is an implementation of the uci.Tree interface that injects armed faults into the wrapped
+// tree.
+type faultInjectingUciTree struct {
+	uci.Tree
+}
+
+func (tree faultInjectingUciTree) Commit() error {
+	if _, ok := faultInjector.trigger(faultUciCommitFailure); ok {
+		return errors.New("injected fault: UCI commit failed")
+	}
+	return tree.Tree.Commit()
+}
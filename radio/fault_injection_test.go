@@ -0,0 +1,84 @@
+// This file is only included in builds with fault injection enabled, which are meant for integration testing and must
+// never be deployed at an event.
+//go:build faultinjection
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestInjectFault(t *testing.T) {
+	t.Cleanup(ClearFaults)
+
+	assert.EqualError(t, InjectFault(Fault{Type: "BLUE_SMOKE"}), "invalid fault type: BLUE_SMOKE")
+	assert.EqualError(t, InjectFault(Fault{Type: faultUciCommitFailure, Count: -1}), "invalid count: -1")
+	assert.EqualError(t, InjectFault(Fault{Type: faultWifiReloadTimeout, DelayMs: -5}), "invalid delayMs: -5")
+	assert.Empty(t, GetFaults())
+
+	assert.Nil(t, InjectFault(Fault{Type: faultUciCommitFailure, Count: 2, TriggeredCount: 7}))
+	assert.Nil(t, InjectFault(Fault{Type: faultGarbledIwinfo}))
+	assert.Equal(
+		t, []Fault{{Type: faultUciCommitFailure, Count: 2}, {Type: faultGarbledIwinfo}}, GetFaults(),
+	)
+
+	ClearFaults()
+	assert.Empty(t, GetFaults())
+}
+
+func TestFaultInjectingUciTree_Commit(t *testing.T) {
+	t.Cleanup(ClearFaults)
+	fakeTree := newFakeUciTree()
+	tree := faultInjectingUciTree{Tree: fakeTree}
+
+	assert.Nil(t, tree.Commit())
+	assert.Equal(t, 1, fakeTree.commitCount)
+
+	// The fault is disarmed once it has been injected as many times as requested.
+	assert.Nil(t, InjectFault(Fault{Type: faultUciCommitFailure, Count: 2}))
+	assert.EqualError(t, tree.Commit(), "injected fault: UCI commit failed")
+	assert.Equal(t, []Fault{{Type: faultUciCommitFailure, Count: 2, TriggeredCount: 1}}, GetFaults())
+	assert.EqualError(t, tree.Commit(), "injected fault: UCI commit failed")
+	assert.Empty(t, GetFaults())
+	assert.Nil(t, tree.Commit())
+	assert.Equal(t, 2, fakeTree.commitCount)
+}
+
+func TestFaultInjectingShell_runCommand(t *testing.T) {
+	t.Cleanup(ClearFaults)
+	fakeShell := newFakeShell(t)
+	shell := faultInjectingShell{shellWrapper: fakeShell}
+	fakeShell.commandOutput["wifi reload"] = ""
+	fakeShell.commandOutput["iwinfo wlan0 info"] = "wlan0     ESSID: \"9999\"\n"
+	fakeShell.commandOutput["uci show wireless"] = "wireless.wifi0=wifi-device\n"
+
+	// A fault with no count stays armed until the faults are cleared.
+	assert.Nil(t, InjectFault(Fault{Type: faultWifiReloadTimeout, DelayMs: 20}))
+	for i := 0; i < 2; i++ {
+		startTime := time.Now()
+		_, err := shell.runCommand("wifi", "reload")
+		assert.EqualError(t, err, "injected fault: wifi reload timed out")
+		assert.GreaterOrEqual(t, time.Since(startTime), 20*time.Millisecond)
+	}
+	assert.NotContains(t, fakeShell.commandsRun, "wifi reload")
+	assert.Equal(t, 2, GetFaults()[0].TriggeredCount)
+
+	// Garbled output still starts out like the real output so that it gets past any cursory checks.
+	assert.Nil(t, InjectFault(Fault{Type: faultGarbledIwinfo, Count: 1}))
+	output, err := shell.runCommand("uci", "show", "wireless")
+	assert.Nil(t, err)
+	assert.Equal(t, "wireless.wifi0=wifi-device\n", output)
+	output, err = shell.runCommand("iwinfo", "wlan0", "info")
+	assert.Nil(t, err)
+	assert.Equal(t, "wlan0     ES\x00\xff\xfe#@!\n", output)
+	output, err = shell.runCommand("iwinfo", "wlan0", "info")
+	assert.Nil(t, err)
+	assert.Equal(t, "wlan0     ESSID: \"9999\"\n", output)
+
+	ClearFaults()
+	_, err = shell.runCommand("wifi", "reload")
+	assert.Nil(t, err)
+	assert.Contains(t, fakeShell.commandsRun, "wifi reload")
+}
@@ -0,0 +1,45 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import "log"
+
+// RequestGhostClientRemoval schedules the removal of any ghost associations on the next monitoring poll, regardless of
+// whether automatic removal is enabled.
+func (radio *Radio) RequestGhostClientRemoval() {
+	radio.ghostClientRemovalRequested.Store(true)
+}
+
+// removeGhostClients deauthenticates any stale associations found during the latest monitoring poll so that hostapd
+// forgets about them, if automatic removal is enabled or a removal has been requested.
+func (radio *Radio) removeGhostClients() {
+	requested := radio.ghostClientRemovalRequested.Swap(false)
+	if !radio.GetSettings().AutoRemoveGhostClients && !requested {
+		return
+	}
+
+	for station := red1; station <= blue3; station++ {
+		stationStatus := radio.StationStatuses[station.String()]
+		if stationStatus == nil {
+			continue
+		}
+		wifiInterface := radio.stationInterfaces[station]
+		for _, macAddress := range stationStatus.GhostMacAddresses {
+			_, err := shell.runCommand("hostapd_cli", "-i", wifiInterface, "deauthenticate", macAddress)
+			if err != nil {
+				log.Printf("Error deauthenticating ghost client %s from %s: %v", macAddress, wifiInterface, err)
+				continue
+			}
+			stationStatus.GhostClientsRemovedCount++
+			radio.raiseStationAlert(
+				station.String(),
+				"GHOST_CLIENT_REMOVED",
+				"Removed stale association for %s from station %s (SSID \"%s\").",
+				macAddress,
+				station,
+				stationStatus.Ssid,
+			)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRadio_removeGhostClients(t *testing.T) {
+	uciTree = newFakeUciTree()
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+	fakeShell.reset()
+	radio.SetSettings(defaultSettings())
+	radio.StationStatuses["red1"] = &NetworkStatus{Ssid: "254", GhostMacAddresses: []string{"48:DA:35:B0:00:CF"}}
+	radio.StationStatuses["blue3"] = &NetworkStatus{
+		Ssid: "1503", GhostMacAddresses: []string{"37:DA:35:B0:00:BE", "12:34:56:78:9A:BC"},
+	}
+
+	// Removal is disabled by default.
+	radio.removeGhostClients()
+	assert.Empty(t, fakeShell.commandsRun)
+
+	// A one-off removal deauthenticates each ghost client, tolerating failures.
+	fakeShell.commandOutput["hostapd_cli -i wlan0 deauthenticate 48:DA:35:B0:00:CF"] = "OK"
+	fakeShell.commandOutput["hostapd_cli -i wlan0-5 deauthenticate 37:DA:35:B0:00:BE"] = "OK"
+	fakeShell.commandErrors["hostapd_cli -i wlan0-5 deauthenticate 12:34:56:78:9A:BC"] = errors.New("oops")
+	radio.RequestGhostClientRemoval()
+	radio.removeGhostClients()
+	assert.Equal(t, 3, len(fakeShell.commandsRun))
+	assert.Equal(t, 1, radio.StationStatuses["red1"].GhostClientsRemovedCount)
+	assert.Equal(t, 1, radio.StationStatuses["blue3"].GhostClientsRemovedCount)
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 2, len(alerts)) {
+		assert.Equal(t, "GHOST_CLIENT_REMOVED", alerts[0].Type)
+		assert.Equal(
+			t, "Removed stale association for 48:DA:35:B0:00:CF from station red1 (SSID \"254\").", alerts[0].Message,
+		)
+	}
+
+	// The one-off request shouldn't carry over to the next poll.
+	fakeShell.reset()
+	radio.removeGhostClients()
+	assert.Empty(t, fakeShell.commandsRun)
+
+	// Automatic removal runs on every poll.
+	settings := radio.GetSettings()
+	settings.AutoRemoveGhostClients = true
+	radio.SetSettings(settings)
+	radio.StationStatuses["blue3"] = nil
+	fakeShell.commandOutput["hostapd_cli -i wlan0 deauthenticate 48:DA:35:B0:00:CF"] = "OK"
+	radio.removeGhostClients()
+	assert.Equal(t, 1, len(fakeShell.commandsRun))
+	assert.Equal(t, 2, radio.StationStatuses["red1"].GhostClientsRemovedCount)
+}
@@ -0,0 +1,107 @@
+package radio
+
+import (
+	"errors"
+	"time"
+)
+
+// How long to wait for the radio to finish what it is doing before giving up on a handover; variable to facilitate
+// testing.
+var handoverTimeout = 30 * time.Second
+
+// HandoverState holds the in-memory state of the radio that is carried over to a new API process when the API binary is
+// upgraded in place. Everything else is either persisted or re-read from the radio on startup.
+type HandoverState struct {
+	// Most recent alerts, oldest first.
+	Alerts []Alert `json:"alerts"`
+
+	// Identifier of the most recently queued configuration request, so that the new process doesn't reuse identifiers.
+	LastConfigurationRequestId int `json:"lastConfigurationRequestId"`
+
+	// Records of the most recent configuration requests, oldest first.
+	ConfigurationRequests []ConfigurationRequestRecord `json:"configurationRequests"`
+
+	// Metadata of the configuration that was most recently applied.
+	ConfigurationMetadata map[string]string `json:"configurationMetadata"`
+
+	// State specific to the access point or robot radio.
+	Personality personalityHandoverState `json:"personality"`
+}
+
+// handoverRequest represents a pending request to hand the radio over to a new API process.
+type handoverRequest struct {
+	handOver func(state HandoverState) error
+	result   chan error
+}
+
+// HandOver waits for the radio to finish any configuration or poll in progress and then calls the given function with
+// its current state from the radio goroutine, so that the radio is never left half-configured. The function is expected
+// to replace the process and so only returns on failure, after which the radio carries on as before. Returns an error
+// without calling the function if configuration requests are still queued or the radio doesn't become idle in time.
+func (radio *Radio) HandOver(handOver func(state HandoverState) error) error {
+	request := handoverRequest{handOver: handOver, result: make(chan error, 1)}
+	if !radio.isLoopRunning.Load() {
+		// The radio isn't being configured or polled yet, so there is nothing to wait for.
+		radio.handleHandoverRequest(request)
+		return <-request.result
+	}
+	select {
+	case radio.handoverChannel <- request:
+		return <-request.result
+	case <-time.After(handoverTimeout):
+		return errors.New("timed out waiting for the radio to become idle")
+	}
+}
+
+// handleHandoverRequest carries out the given handover request, unless doing so would drop queued configuration
+// requests or interrupt something that the new process wouldn't know how to finish.
+func (radio *Radio) handleHandoverRequest(request handoverRequest) {
+	if len(radio.ConfigurationRequestChannel) > 0 {
+		request.result <- errors.New("configuration requests are still queued; try again once they have been applied")
+		return
+	}
+	if err := radio.checkBeforeHandover(); err != nil {
+		request.result <- err
+		return
+	}
+	request.result <- request.handOver(radio.handoverState())
+}
+
+// handoverState returns the in-memory state to carry over to a new API process.
+func (radio *Radio) handoverState() HandoverState {
+	requestLog := &radio.configurationRequests
+	requestLog.mutex.Lock()
+	defer requestLog.mutex.Unlock()
+	state := HandoverState{
+		Alerts:                     radio.GetAlerts(),
+		LastConfigurationRequestId: requestLog.lastId,
+		ConfigurationRequests:      make([]ConfigurationRequestRecord, len(requestLog.records)),
+		ConfigurationMetadata:      radio.ConfigurationMetadata,
+		Personality:                radio.personalityHandoverState(),
+	}
+	copy(state.ConfigurationRequests, requestLog.records)
+	return state
+}
+
+// RestoreHandoverState takes on the state carried over from the API process that this one replaced. Anything recorded
+// since this process started is kept after the carried-over state.
+func (radio *Radio) RestoreHandoverState(state HandoverState) {
+	radio.alerts.mutex.Lock()
+	radio.alerts.alerts = append(state.Alerts, radio.alerts.alerts...)
+	if len(radio.alerts.alerts) > maxAlerts {
+		radio.alerts.alerts = radio.alerts.alerts[len(radio.alerts.alerts)-maxAlerts:]
+	}
+	radio.alerts.mutex.Unlock()
+
+	radio.restorePersonalityHandoverState(state.Personality)
+
+	requestLog := &radio.configurationRequests
+	requestLog.mutex.Lock()
+	defer requestLog.mutex.Unlock()
+	if len(requestLog.records) == 0 && state.LastConfigurationRequestId > requestLog.lastId {
+		requestLog.lastId = state.LastConfigurationRequestId
+		requestLog.records = state.ConfigurationRequests
+		requestLog.trim()
+		radio.ConfigurationMetadata = state.ConfigurationMetadata
+	}
+}
@@ -0,0 +1,159 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"time"
+)
+
+// personalityHandoverState holds the in-memory state specific to the access point that is carried over to a new API
+// process.
+type personalityHandoverState struct {
+	// State of the keepalive contract with the FMS.
+	Heartbeat HeartbeatStatus `json:"heartbeat"`
+
+	// Whether the FMS has signaled that a match is in progress.
+	MatchLock MatchLockStatus `json:"matchLock"`
+
+	// Quiet hours schedule and whether the team networks are currently off the air for it.
+	QuietHours QuietHoursStatus `json:"quietHours"`
+
+	// Whether the transmit power is currently lowered for quiet hours.
+	QuietHoursTxPowerLowered bool `json:"quietHoursTxPowerLowered"`
+
+	// State of the pairing with a standby access point, including whether the standby has taken over.
+	Standby StandbyStatus `json:"standby"`
+
+	// State of the policy for switching to a backup channel under sustained interference.
+	ChannelFailover ChannelFailoverStatus `json:"channelFailover"`
+
+	// Association history of each station, keyed by station name.
+	AssociationHistories map[string]handedOverAssociationHistory `json:"associationHistories"`
+
+	// Hashes of the WPA keys that each team has been configured with at the current event.
+	WpaKeyHistory WpaKeyHistory `json:"wpaKeyHistory"`
+}
+
+// handedOverAssociationHistory is the form in which the association history of a station is carried over.
+type handedOverAssociationHistory struct {
+	Ssid            string        `json:"ssid"`
+	MacAddress      string        `json:"macAddress"`
+	PolledAt        time.Time     `json:"polledAt"`
+	DropTimes       []time.Time   `json:"dropTimes"`
+	AssociatedTime  time.Duration `json:"associatedTime"`
+	DisconnectCount int           `json:"disconnectCount"`
+	DisconnectedAt  time.Time     `json:"disconnectedAt"`
+	LongestGap      time.Duration `json:"longestGap"`
+}
+
+// checkBeforeHandover returns an error if the access point is in the middle of something that a new API process
+// wouldn't know how to finish.
+func (radio *Radio) checkBeforeHandover() error {
+	if change := radio.GetManagementNetwork().Change; change != nil && change.IsInProgress() {
+		return errors.New(
+			"a management network change is in progress; try again once it has been confirmed or reverted",
+		)
+	}
+	return nil
+}
+
+// personalityHandoverState returns the in-memory state specific to the access point to carry over to a new API
+// process. Must be called from the radio goroutine.
+func (radio *Radio) personalityHandoverState() personalityHandoverState {
+	radio.heartbeatMutex.Lock()
+	heartbeat := radio.Heartbeat
+	radio.heartbeatMutex.Unlock()
+	radio.matchLockMutex.Lock()
+	matchLock := radio.MatchLock
+	radio.matchLockMutex.Unlock()
+
+	state := personalityHandoverState{
+		Heartbeat:                heartbeat,
+		MatchLock:                matchLock,
+		QuietHours:               radio.GetQuietHours(),
+		QuietHoursTxPowerLowered: radio.quietHoursTxPowerLowered,
+		Standby:                  radio.GetStandby(),
+		ChannelFailover:          radio.ChannelFailover,
+		AssociationHistories:     make(map[string]handedOverAssociationHistory),
+		WpaKeyHistory:            radio.wpaKeyHistory,
+	}
+	for station, history := range radio.associationHistories {
+		state.AssociationHistories[station.String()] = handedOverAssociationHistory{
+			Ssid:            history.ssid,
+			MacAddress:      history.macAddress,
+			PolledAt:        history.polledAt,
+			DropTimes:       history.dropTimes,
+			AssociatedTime:  history.associatedTime,
+			DisconnectCount: history.disconnectCount,
+			DisconnectedAt:  history.disconnectedAt,
+			LongestGap:      history.longestGap,
+		}
+	}
+	return state
+}
+
+// restorePersonalityHandoverState takes on the state specific to the access point carried over from the API process
+// that this one replaced. State that only the radio goroutine touches is adopted by it on the next monitoring poll.
+func (radio *Radio) restorePersonalityHandoverState(state personalityHandoverState) {
+	radio.heartbeatMutex.Lock()
+	radio.Heartbeat = state.Heartbeat
+	radio.heartbeatMutex.Unlock()
+
+	radio.matchLockMutex.Lock()
+	radio.MatchLock = state.MatchLock
+	radio.matchLockMutex.Unlock()
+
+	radio.quietHoursMutex.Lock()
+	radio.QuietHours.IsActive = state.QuietHours.IsActive
+	radio.QuietHours.LastChangedAt = state.QuietHours.LastChangedAt
+	radio.quietHoursMutex.Unlock()
+
+	// Whether the networks are currently held off the air is left to this process, which may already have taken them
+	// down on startup; they are brought back on the next poll if the standby had taken over.
+	radio.standbyMutex.Lock()
+	radio.Standby.HasTakenOver = state.Standby.HasTakenOver
+	radio.Standby.LastMirroredAt = state.Standby.LastMirroredAt
+	radio.Standby.IsInSync = state.Standby.IsInSync
+	radio.Standby.MirrorError = state.Standby.MirrorError
+	radio.standbyMutex.Unlock()
+
+	radio.handoverMutex.Lock()
+	radio.restoredHandoverState = &state
+	radio.handoverMutex.Unlock()
+	radio.markStatusChanged()
+}
+
+// adoptRestoredHandoverState takes on the carried-over state that only the radio goroutine touches, if there is any
+// that hasn't been adopted yet.
+func (radio *Radio) adoptRestoredHandoverState() {
+	radio.handoverMutex.Lock()
+	state := radio.restoredHandoverState
+	radio.restoredHandoverState = nil
+	radio.handoverMutex.Unlock()
+	if state == nil {
+		return
+	}
+
+	radio.quietHoursTxPowerLowered = state.QuietHoursTxPowerLowered
+	radio.ChannelFailover = state.ChannelFailover
+	radio.wpaKeyHistory = state.WpaKeyHistory
+	radio.associationHistories = make(map[station]*associationHistory)
+	for station := red1; station <= blue3; station++ {
+		history, ok := state.AssociationHistories[station.String()]
+		if !ok {
+			continue
+		}
+		radio.associationHistories[station] = &associationHistory{
+			ssid:            history.Ssid,
+			macAddress:      history.MacAddress,
+			polledAt:        history.PolledAt,
+			dropTimes:       history.DropTimes,
+			associatedTime:  history.AssociatedTime,
+			disconnectCount: history.DisconnectCount,
+			disconnectedAt:  history.DisconnectedAt,
+			longestGap:      history.LongestGap,
+		}
+	}
+}
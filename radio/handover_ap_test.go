@@ -0,0 +1,110 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRadio_handoverStateRoundTrip(t *testing.T) {
+	now := time.Now()
+	mirroredAt := newTimestamp()
+	heartbeatAt := newTimestamp()
+	radio := &Radio{settings: defaultSettings()}
+	radio.Heartbeat = HeartbeatStatus{Enabled: true, LastReceived: &heartbeatAt, IsExpired: true, ExpiredCount: 2}
+	radio.MatchLock = MatchLockStatus{IsHeld: true, ExpiresAt: now.Add(time.Minute)}
+	radio.QuietHours = QuietHoursStatus{
+		Schedule: QuietHoursSchedule{Enabled: true, StartTime: "22:00", EndTime: "07:00", TxPowerDbm: 5},
+		IsActive: true,
+	}
+	radio.quietHoursTxPowerLowered = true
+	radio.Standby = StandbyStatus{HasTakenOver: true, LastMirroredAt: &mirroredAt, IsInSync: true}
+	radio.ChannelFailover = ChannelFailoverStatus{IsEnabled: true, FailoverCount: 1, LastFailoverAt: now}
+	history := &associationHistory{
+		ssid:            "254",
+		macAddress:      "00:11:22:33:44:55",
+		polledAt:        now,
+		dropTimes:       []time.Time{now.Add(-time.Minute)},
+		associatedTime:  time.Hour,
+		disconnectCount: 1,
+		longestGap:      time.Second,
+	}
+	radio.associationHistories = map[station]*associationHistory{blue2: history}
+	radio.wpaKeyHistory = WpaKeyHistory{
+		EventCode:          "2024CASJ",
+		Salt:               "salt",
+		Teams:              map[string][]WpaKeyUse{"254": {{KeyHash: "hash", Station: "red1", FirstSlot: "Q1"}}},
+		ConfigurationCount: 3,
+	}
+
+	stateBytes, err := json.Marshal(radio.handoverState())
+	assert.Nil(t, err)
+	var state HandoverState
+	assert.Nil(t, json.Unmarshal(stateBytes, &state))
+
+	newRadio := &Radio{settings: defaultSettings()}
+	newRadio.Standby.IsHoldingNetworks = true
+	newRadio.RestoreHandoverState(state)
+	if assert.NotNil(t, newRadio.Heartbeat.LastReceived) {
+		assert.True(t, heartbeatAt.Wallclock.Equal(newRadio.Heartbeat.LastReceived.Wallclock))
+	}
+	assert.True(t, newRadio.Heartbeat.IsExpired)
+	assert.Equal(t, 2, newRadio.Heartbeat.ExpiredCount)
+	assert.True(t, radio.MatchLock.ExpiresAt.Equal(newRadio.MatchLock.ExpiresAt))
+	assert.True(t, newRadio.isMatchLockHeld())
+	assert.True(t, newRadio.isQuietHoursActive())
+	standby := newRadio.GetStandby()
+	assert.True(t, standby.HasTakenOver)
+	assert.True(t, standby.IsInSync)
+	assert.Equal(t, mirroredAt.MonotonicNs, standby.LastMirroredAt.MonotonicNs)
+	// Whether the networks are held is left to the new process.
+	assert.True(t, standby.IsHoldingNetworks)
+
+	// State owned by the radio goroutine is only adopted by it.
+	assert.False(t, newRadio.quietHoursTxPowerLowered)
+	assert.Nil(t, newRadio.associationHistories)
+	newRadio.adoptRestoredHandoverState()
+	assert.True(t, newRadio.quietHoursTxPowerLowered)
+	assert.Equal(t, radio.ChannelFailover.FailoverCount, newRadio.ChannelFailover.FailoverCount)
+	assert.True(t, radio.ChannelFailover.LastFailoverAt.Equal(newRadio.ChannelFailover.LastFailoverAt))
+	if assert.Contains(t, newRadio.associationHistories, blue2) {
+		restored := newRadio.associationHistories[blue2]
+		assert.Equal(t, history.ssid, restored.ssid)
+		assert.Equal(t, history.macAddress, restored.macAddress)
+		assert.Equal(t, history.associatedTime, restored.associatedTime)
+		assert.Equal(t, history.disconnectCount, restored.disconnectCount)
+		assert.Equal(t, history.longestGap, restored.longestGap)
+		assert.True(t, history.polledAt.Equal(restored.polledAt))
+		assert.Equal(t, 1, len(restored.dropTimes))
+	}
+	assert.Equal(t, radio.wpaKeyHistory, newRadio.wpaKeyHistory)
+
+	// Adopting again does nothing since the state has already been taken on.
+	newRadio.quietHoursTxPowerLowered = false
+	newRadio.adoptRestoredHandoverState()
+	assert.False(t, newRadio.quietHoursTxPowerLowered)
+}
+
+func TestRadio_HandOverRefusedDuringManagementNetworkChange(t *testing.T) {
+	radio := &Radio{ConfigurationRequestChannel: make(chan ConfigurationRequest, 1)}
+	radio.ManagementNetwork.Change = &ManagementNetworkChange{State: managementChangeStateAwaitingConfirmation}
+	isCalled := false
+	handOver := func(state HandoverState) error {
+		isCalled = true
+		return nil
+	}
+	assert.EqualError(
+		t,
+		radio.HandOver(handOver),
+		"a management network change is in progress; try again once it has been confirmed or reverted",
+	)
+	assert.False(t, isCalled)
+
+	radio.ManagementNetwork.Change.State = managementChangeStateConfirmed
+	assert.Nil(t, radio.HandOver(handOver))
+	assert.True(t, isCalled)
+}
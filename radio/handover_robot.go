@@ -0,0 +1,24 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package radio
+
+// personalityHandoverState holds the in-memory state specific to the robot radio that is carried over to a new API
+// process. The robot radio has none beyond what is common to both.
+type personalityHandoverState struct{}
+
+// checkBeforeHandover returns an error if the robot radio is in the middle of something that a new API process
+// wouldn't know how to finish. There is nothing of the sort on the robot radio.
+func (radio *Radio) checkBeforeHandover() error {
+	return nil
+}
+
+// personalityHandoverState returns the in-memory state specific to the robot radio to carry over to a new API process.
+func (radio *Radio) personalityHandoverState() personalityHandoverState {
+	return personalityHandoverState{}
+}
+
+// restorePersonalityHandoverState takes on the state specific to the robot radio carried over from the API process
+// that this one replaced.
+func (radio *Radio) restorePersonalityHandoverState(state personalityHandoverState) {
+}
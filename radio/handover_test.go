@@ -0,0 +1,77 @@
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRadio_HandOver(t *testing.T) {
+	radio := &Radio{
+		ConfigurationRequestChannel: make(chan ConfigurationRequest, 2),
+		handoverChannel:             make(chan handoverRequest),
+	}
+	radio.raiseAlert("ROGUE_NETWORK", "Rogue network detected.")
+	id, _ := radio.EnqueueConfigurationRequest(ConfigurationRequest{})
+
+	// The handover is refused while configuration requests are still queued.
+	isCalled := false
+	handOver := func(state HandoverState) error {
+		isCalled = true
+		assert.Equal(t, radio.GetAlerts(), state.Alerts)
+		assert.Equal(t, id, state.LastConfigurationRequestId)
+		assert.Equal(t, radio.GetConfigurationRequests(), state.ConfigurationRequests)
+		return errors.New("exec failed")
+	}
+	assert.EqualError(
+		t, radio.HandOver(handOver), "configuration requests are still queued; try again once they have been applied",
+	)
+	assert.False(t, isCalled)
+
+	// Before the main loop starts, the handover happens right away.
+	<-radio.ConfigurationRequestChannel
+	assert.EqualError(t, radio.HandOver(handOver), "exec failed")
+	assert.True(t, isCalled)
+
+	// Once the main loop is running, the handover happens from it.
+	radio.isLoopRunning.Store(true)
+	isCalled = false
+	go func() {
+		radio.handleHandoverRequest(<-radio.handoverChannel)
+	}()
+	assert.EqualError(t, radio.HandOver(handOver), "exec failed")
+	assert.True(t, isCalled)
+
+	handoverTimeout = 10 * time.Millisecond
+	defer func() { handoverTimeout = 30 * time.Second }()
+	isCalled = false
+	assert.EqualError(t, radio.HandOver(handOver), "timed out waiting for the radio to become idle")
+	assert.False(t, isCalled)
+}
+
+func TestRadio_RestoreHandoverState(t *testing.T) {
+	radio := &Radio{ConfigurationRequestChannel: make(chan ConfigurationRequest, 1)}
+	radio.raiseAlert("CONFIG_DRIFT", "Raised since startup.")
+
+	state := HandoverState{LastConfigurationRequestId: 12}
+	for i := 0; i < maxAlerts; i++ {
+		state.Alerts = append(state.Alerts, Alert{Type: "ROGUE_NETWORK"})
+	}
+	state.ConfigurationRequests = []ConfigurationRequestRecord{{Id: 12, State: requestStateApplied}}
+	radio.RestoreHandoverState(state)
+
+	// Alerts raised since startup are kept after the carried-over ones.
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, maxAlerts, len(alerts)) {
+		assert.Equal(t, "ROGUE_NETWORK", alerts[0].Type)
+		assert.Equal(t, "CONFIG_DRIFT", alerts[maxAlerts-1].Type)
+	}
+	assert.Equal(t, state.ConfigurationRequests, radio.GetConfigurationRequests())
+	id, _ := radio.EnqueueConfigurationRequest(ConfigurationRequest{})
+	assert.Equal(t, 13, id)
+
+	// Configuration requests are left alone if any have been queued since startup.
+	radio.RestoreHandoverState(HandoverState{LastConfigurationRequestId: 20})
+	assert.Equal(t, 2, len(radio.GetConfigurationRequests()))
+}
@@ -0,0 +1,141 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Maximum number of handshake failure timestamps to retain per station.
+	maxRecentHandshakeFailures = 10
+
+	// Layout of the timestamp at the start of each system log line.
+	systemLogTimeLayout = "Mon Jan _2 15:04:05 2006"
+
+	// Number of most recent system log lines to read on each scan for handshake failures; this comfortably exceeds what
+	// hostapd logs between two monitoring polls, without rereading the whole log buffer every time.
+	handshakeLogReadLines = 200
+)
+
+var (
+	// Regex matching a hostapd log line and capturing the interface name and message.
+	hostapdLogLineRe = regexp.MustCompile(`hostapd: ([^:\s]+): (.*)$`)
+
+	// Regex matching hostapd messages that indicate a client failed to authenticate, e.g. due to a wrong WPA key.
+	handshakeFailureRe = regexp.MustCompile(
+		`AP-STA-POSSIBLE-PSK-MISMATCH|pairwise key handshake failed|SAE: .*[Cc]onfirm mismatch`,
+	)
+)
+
+// systemLogCursor tracks the position up to which the system log has already been processed, since consecutive reads of
+// the tail of the log buffer overlap and timestamps only have one-second resolution.
+type systemLogCursor struct {
+	isInitialized bool
+	time          time.Time
+	linesAtTime   int
+}
+
+// updateHandshakeFailures scans the hostapd log for new authentication and key handshake failures and client
+// connections and disconnections, and attributes them to the respective team stations.
+func (radio *Radio) updateHandshakeFailures() {
+	stationsByInterface := make(map[string]station)
+	for station := red1; station <= blue3; station++ {
+		if radio.StationStatuses[station.String()] != nil {
+			stationsByInterface[radio.stationInterfaces[station]] = station
+		}
+	}
+	if len(stationsByInterface) == 0 {
+		// Nobody to attribute failures to.
+		return
+	}
+
+	output, err := shell.runCommand("logread", "-l", strconv.Itoa(handshakeLogReadLines), "-e", "hostapd")
+	if err != nil {
+		log.Printf("Error running 'logread -l %d -e hostapd': %v", handshakeLogReadLines, err)
+		return
+	}
+	isInitialized := radio.handshakeLogCursor.isInitialized
+	for _, line := range radio.handshakeLogCursor.newLines(output) {
+		if !isInitialized {
+			// Don't attribute failures that predate the API having started to whichever team is now assigned.
+			continue
+		}
+		match := hostapdLogLineRe.FindStringSubmatch(line.text)
+		if match == nil {
+			continue
+		}
+		station, ok := stationsByInterface[match[1]]
+		if !ok {
+			continue
+		}
+		if handshakeFailureRe.MatchString(match[2]) {
+			radio.StationStatuses[station.String()].recordHandshakeFailure(line.time)
+		} else if event, ok := parseAssociationEvent(match[2], line.time); ok {
+			radio.recordAssociationEvent(station, event)
+		}
+	}
+}
+
+// timedLogLine represents a single line of the system log along with its parsed timestamp.
+type timedLogLine struct {
+	time time.Time
+	text string
+}
+
+// newLines returns the lines of the given system log output that haven't already been returned by a previous call, and
+// advances the cursor past them.
+func (cursor *systemLogCursor) newLines(output string) []timedLogLine {
+	var lines []timedLogLine
+	var lastTime time.Time
+	linesAtLastTime := 0
+	linesAtCursorTime := 0
+	for _, text := range strings.Split(output, "\n") {
+		if len(text) < len(systemLogTimeLayout) {
+			continue
+		}
+		lineTime, err := time.ParseInLocation(systemLogTimeLayout, text[:len(systemLogTimeLayout)], time.Local)
+		if err != nil {
+			continue
+		}
+
+		if lineTime.Equal(lastTime) {
+			linesAtLastTime++
+		} else {
+			lastTime = lineTime
+			linesAtLastTime = 1
+		}
+		if lineTime.Before(cursor.time) {
+			continue
+		}
+		if lineTime.Equal(cursor.time) {
+			linesAtCursorTime++
+			if linesAtCursorTime <= cursor.linesAtTime {
+				continue
+			}
+		}
+		lines = append(lines, timedLogLine{time: lineTime, text: text})
+	}
+
+	if !lastTime.IsZero() && !lastTime.Before(cursor.time) {
+		cursor.time = lastTime
+		cursor.linesAtTime = linesAtLastTime
+	}
+	cursor.isInitialized = true
+	return lines
+}
+
+// recordHandshakeFailure notes that a client failed to authenticate to this network at the given time.
+func (status *NetworkStatus) recordHandshakeFailure(failureTime time.Time) {
+	status.HandshakeFailureCount++
+	failures := append(status.RecentHandshakeFailures, failureTime)
+	if len(failures) > maxRecentHandshakeFailures {
+		failures = failures[len(failures)-maxRecentHandshakeFailures:]
+	}
+	status.RecentHandshakeFailures = failures
+}
@@ -0,0 +1,92 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSystemLogCursor_newLines(t *testing.T) {
+	var cursor systemLogCursor
+	output := "Sat Mar  2 10:15:04 2024 daemon.info hostapd: line 1\n" +
+		"Sat Mar  2 10:15:05 2024 daemon.info hostapd: line 2\n" +
+		"Sat Mar  2 10:15:05 2024 daemon.info hostapd: line 3\n" +
+		"garbage\n"
+	lines := cursor.newLines(output)
+	if assert.Equal(t, 3, len(lines)) {
+		assert.Equal(t, time.Date(2024, 3, 2, 10, 15, 4, 0, time.Local), lines[0].time)
+		assert.Contains(t, lines[2].text, "line 3")
+	}
+	assert.True(t, cursor.isInitialized)
+
+	// Only lines not already seen are returned, including ones logged in the same second as the last seen line.
+	assert.Equal(t, 0, len(cursor.newLines(output)))
+	output += "Sat Mar  2 10:15:05 2024 daemon.info hostapd: line 4\n" +
+		"Sat Mar  2 10:15:06 2024 daemon.info hostapd: line 5\n"
+	lines = cursor.newLines(output)
+	if assert.Equal(t, 2, len(lines)) {
+		assert.Contains(t, lines[0].text, "line 4")
+		assert.Contains(t, lines[1].text, "line 5")
+	}
+	assert.Equal(t, 0, len(cursor.newLines(output)))
+	assert.Equal(t, 0, len(cursor.newLines("")))
+}
+
+func TestRadio_updateHandshakeFailures(t *testing.T) {
+	uciTree = newFakeUciTree()
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+	fakeShell.reset()
+
+	// No teams assigned.
+	radio.updateHandshakeFailures()
+	assert.Equal(t, 0, len(fakeShell.commandsRun))
+
+	// Failures predating the first scan are ignored.
+	radio.StationStatuses["red1"] = &NetworkStatus{Ssid: "1111"}
+	radio.StationStatuses["blue2"] = &NetworkStatus{Ssid: "5555"}
+	output := "Sat Mar  2 10:15:04 2024 daemon.info hostapd: wlan0: AP-STA-POSSIBLE-PSK-MISMATCH 48:da:35:b0:01:cf\n"
+	fakeShell.commandOutput["logread -l 200 -e hostapd"] = output
+	radio.updateHandshakeFailures()
+	assert.Equal(t, 0, radio.StationStatuses["red1"].HandshakeFailureCount)
+
+	// New failures are attributed to the right stations.
+	output += "Sat Mar  2 10:15:10 2024 daemon.info hostapd: wlan0: AP-STA-POSSIBLE-PSK-MISMATCH 48:da:35:b0:01:cf\n" +
+		"Sat Mar  2 10:15:11 2024 daemon.info hostapd: wlan0: STA 48:da:35:b0:01:cf IEEE 802.11: authenticated\n" +
+		"Sat Mar  2 10:15:12 2024 daemon.info hostapd: wlan0-4: STA 48:da:35:b0:02:cf WPA: pairwise key handshake " +
+		"failed (RSN) after 4 tries\n" +
+		"Sat Mar  2 10:15:13 2024 daemon.info hostapd: wlan0-1: AP-STA-POSSIBLE-PSK-MISMATCH 48:da:35:b0:03:cf\n"
+	fakeShell.commandOutput["logread -l 200 -e hostapd"] = output
+	radio.updateHandshakeFailures()
+	assert.Equal(t, 1, radio.StationStatuses["red1"].HandshakeFailureCount)
+	assert.Equal(
+		t,
+		[]time.Time{time.Date(2024, 3, 2, 10, 15, 10, 0, time.Local)},
+		radio.StationStatuses["red1"].RecentHandshakeFailures,
+	)
+	assert.Equal(t, 1, radio.StationStatuses["blue2"].HandshakeFailureCount)
+	assert.Nil(t, radio.StationStatuses["red2"])
+
+	// Already processed lines aren't counted again.
+	radio.updateHandshakeFailures()
+	assert.Equal(t, 1, radio.StationStatuses["red1"].HandshakeFailureCount)
+
+	// Only the most recent failure times are retained.
+	for i := 0; i < maxRecentHandshakeFailures+2; i++ {
+		radio.StationStatuses["red1"].recordHandshakeFailure(time.Now())
+	}
+	assert.Equal(t, maxRecentHandshakeFailures+3, radio.StationStatuses["red1"].HandshakeFailureCount)
+	assert.Equal(t, maxRecentHandshakeFailures, len(radio.StationStatuses["red1"].RecentHandshakeFailures))
+
+	// logread fails.
+	delete(fakeShell.commandOutput, "logread -l 200 -e hostapd")
+	fakeShell.commandErrors["logread -l 200 -e hostapd"] = errors.New("oops")
+	radio.updateHandshakeFailures()
+	assert.Equal(t, 1, radio.StationStatuses["blue2"].HandshakeFailureCount)
+}
@@ -0,0 +1,82 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"log"
+	"time"
+)
+
+// HeartbeatStatus represents the state of the keepalive contract between the FMS and the access point.
+type HeartbeatStatus struct {
+	// Whether heartbeat monitoring is enabled in the settings.
+	Enabled bool `json:"enabled"`
+
+	// Time at which the last heartbeat was received from the FMS. Nil if none has been received yet.
+	LastReceived *Timestamp `json:"lastReceived"`
+
+	// Whether heartbeats have stopped arriving for longer than the configured timeout.
+	IsExpired bool `json:"isExpired"`
+
+	// Number of times the heartbeat has expired since the API started.
+	ExpiredCount int `json:"expiredCount"`
+}
+
+// RecordHeartbeat notes that a heartbeat was just received from the FMS.
+func (radio *Radio) RecordHeartbeat() {
+	radio.heartbeatMutex.Lock()
+	defer radio.heartbeatMutex.Unlock()
+	receivedAt := newTimestamp()
+	radio.Heartbeat.LastReceived = &receivedAt
+	if radio.Heartbeat.IsExpired {
+		log.Println("Heartbeat from FMS resumed.")
+	}
+	radio.Heartbeat.IsExpired = false
+	radio.markStatusChanged()
+}
+
+// checkHeartbeat takes the configured action if heartbeats from the FMS have stopped. Monitoring only begins once the
+// first heartbeat has been received so that an FMS which doesn't send heartbeats is unaffected.
+func (radio *Radio) checkHeartbeat() {
+	radio.heartbeatMutex.Lock()
+	defer radio.heartbeatMutex.Unlock()
+	settings := radio.GetSettings()
+	status := &radio.Heartbeat
+	status.Enabled = settings.HeartbeatTimeoutSec > 0
+
+	if !status.Enabled || status.LastReceived == nil || status.IsExpired {
+		return
+	}
+	timeout := time.Duration(settings.HeartbeatTimeoutSec) * time.Second
+	if time.Since(status.LastReceived.Wallclock) < timeout {
+		return
+	}
+
+	status.IsExpired = true
+	status.ExpiredCount++
+	radio.raiseAlert(
+		"HEARTBEAT_EXPIRED",
+		"No heartbeat received from FMS since %s; taking action %s.",
+		status.LastReceived.Wallclock.Format(time.RFC3339),
+		settings.HeartbeatAction,
+	)
+	if settings.HeartbeatAction == heartbeatActionRevert {
+		if radio.isInMaintenance() {
+			log.Println("Not reverting to safe configuration since maintenance mode is enabled.")
+			return
+		}
+		if _, err := radio.EnqueueConfigurationRequest(safeConfigurationRequest()); err != nil {
+			log.Printf("Unable to revert to safe configuration: %v", err)
+		}
+	}
+}
+
+// safeConfigurationRequest returns a request that unconfigures all team stations.
+func safeConfigurationRequest() ConfigurationRequest {
+	request := ConfigurationRequest{StationConfigurations: make(map[string]*StationConfiguration)}
+	for station := red1; station <= blue3; station++ {
+		request.StationConfigurations[station.String()] = nil
+	}
+	return request
+}
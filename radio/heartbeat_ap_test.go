@@ -0,0 +1,99 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRadio_checkHeartbeat(t *testing.T) {
+	radio := &Radio{
+		ConfigurationRequestChannel: make(chan ConfigurationRequest, configurationRequestBufferSize),
+		settings:                    defaultSettings(),
+	}
+
+	// Heartbeat monitoring disabled.
+	radio.RecordHeartbeat()
+	radio.Heartbeat.LastReceived.Wallclock = time.Now().Add(-time.Hour)
+	radio.checkHeartbeat()
+	assert.False(t, radio.Heartbeat.Enabled)
+	assert.False(t, radio.Heartbeat.IsExpired)
+
+	// No heartbeat received yet.
+	settings := radio.GetSettings()
+	settings.HeartbeatTimeoutSec = 10
+	radio.SetSettings(settings)
+	radio.Heartbeat.LastReceived = nil
+	radio.checkHeartbeat()
+	assert.True(t, radio.Heartbeat.Enabled)
+	assert.False(t, radio.Heartbeat.IsExpired)
+
+	// Recent heartbeat.
+	radio.RecordHeartbeat()
+	radio.checkHeartbeat()
+	assert.False(t, radio.Heartbeat.IsExpired)
+	assert.Equal(t, 0, radio.Heartbeat.ExpiredCount)
+
+	// Expired heartbeat with alert action only.
+	radio.Heartbeat.LastReceived.Wallclock = time.Now().Add(-11 * time.Second)
+	radio.checkHeartbeat()
+	assert.True(t, radio.Heartbeat.IsExpired)
+	assert.Equal(t, 1, radio.Heartbeat.ExpiredCount)
+	assert.Equal(t, 0, len(radio.ConfigurationRequestChannel))
+	if alerts := radio.GetAlerts(); assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "HEARTBEAT_EXPIRED", alerts[0].Type)
+	}
+
+	// Action is only taken once per expiry.
+	radio.checkHeartbeat()
+	assert.Equal(t, 1, radio.Heartbeat.ExpiredCount)
+
+	// Heartbeat resumes.
+	radio.RecordHeartbeat()
+	assert.False(t, radio.Heartbeat.IsExpired)
+
+	// Expired heartbeat with revert action.
+	settings = radio.GetSettings()
+	settings.HeartbeatAction = heartbeatActionRevert
+	radio.SetSettings(settings)
+	radio.Heartbeat.LastReceived.Wallclock = time.Now().Add(-11 * time.Second)
+	radio.checkHeartbeat()
+	assert.True(t, radio.Heartbeat.IsExpired)
+	assert.Equal(t, 2, radio.Heartbeat.ExpiredCount)
+	if assert.Equal(t, 1, len(radio.ConfigurationRequestChannel)) {
+		request := <-radio.ConfigurationRequestChannel
+		assert.Equal(t, 6, len(request.StationConfigurations))
+		for _, config := range request.StationConfigurations {
+			assert.Nil(t, config)
+		}
+	}
+
+	// The configuration is left alone while the radio is in maintenance mode.
+	radio.RecordHeartbeat()
+	radio.setMaintenanceMode(true, "", maintenanceSourceApi)
+	radio.Heartbeat.LastReceived.Wallclock = time.Now().Add(-11 * time.Second)
+	radio.checkHeartbeat()
+	assert.True(t, radio.Heartbeat.IsExpired)
+	assert.Equal(t, 3, radio.Heartbeat.ExpiredCount)
+	assert.Empty(t, radio.ConfigurationRequestChannel)
+}
+
+func TestRadio_MarshalStatusWhileRecordingHeartbeats(t *testing.T) {
+	radio := &Radio{settings: defaultSettings()}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			radio.RecordHeartbeat()
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		statusJson, err := radio.MarshalStatus()
+		assert.Nil(t, err)
+		assert.Contains(t, string(statusJson), `"heartbeat": {`)
+	}
+	<-done
+}
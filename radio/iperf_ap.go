@@ -0,0 +1,230 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// Default and maximum length of time an iperf3 server is left running for, in seconds.
+	defaultIperfDurationSec = 30
+	maxIperfDurationSec     = 300
+
+	// Default and maximum bandwidth a team can use during a throughput check before it is aborted, in megabits per
+	// second.
+	defaultIperfMaxBandwidthMbps = 100
+	maxIperfMaxBandwidthMbps     = 1000
+
+	// Maximum number of throughput check results to retain.
+	maxIperfResults = 50
+)
+
+// IperfRequest represents a JSON request to start an iperf3 server for a team to check their throughput against.
+type IperfRequest struct {
+	// Team station whose VLAN the server should listen on (e.g. "red1").
+	Station string `json:"station"`
+
+	// How long to leave the server running for if no test is completed, in seconds. Defaults to 30 if zero.
+	DurationSec int `json:"durationSec"`
+
+	// Bandwidth above which the test is aborted, in megabits per second. Defaults to 100 if zero.
+	MaxBandwidthMbps int `json:"maxBandwidthMbps"`
+}
+
+// IperfResult represents the outcome of a single throughput check.
+type IperfResult struct {
+	// Team station the check was run on.
+	Station string `json:"station"`
+
+	// SSID (i.e. team number) assigned to the station at the time of the check.
+	Ssid string `json:"ssid"`
+
+	// Time at which the server was started.
+	StartedAt time.Time `json:"startedAt"`
+
+	// Time at which the server exited.
+	FinishedAt time.Time `json:"finishedAt"`
+
+	// Throughput received by the server, in megabits per second. Zero if no test was completed.
+	ReceivedMbps float64 `json:"receivedMbps"`
+
+	// Description of why no throughput was measured, or an empty string if the test succeeded.
+	Error string `json:"error"`
+}
+
+// IperfStatus represents the state of the iperf3 server and the results of past throughput checks.
+type IperfStatus struct {
+	// Whether a server is currently running.
+	IsRunning bool `json:"isRunning"`
+
+	// Parameters of the currently running server, if any.
+	Request *IperfRequest `json:"request"`
+
+	// Results of past throughput checks, oldest first.
+	Results []IperfResult `json:"results"`
+}
+
+// iperfServer tracks the running iperf3 server, if any; it is shared between the web goroutines.
+type iperfServer struct {
+	mutex   sync.Mutex
+	request *IperfRequest
+	cancel  context.CancelFunc
+	done    chan struct{}
+	results []IperfResult
+}
+
+// StartIperfServer starts a bounded iperf3 server on the VLAN of the requested team station, which exits after a single
+// test or once the requested duration elapses, whichever comes first.
+func (radio *Radio) StartIperfServer(request IperfRequest) error {
+	stationNameValid := false
+	for station := red1; station <= blue3; station++ {
+		if request.Station == station.String() {
+			stationNameValid = true
+			break
+		}
+	}
+	if !stationNameValid {
+		return fmt.Errorf("invalid station: %s", request.Station)
+	}
+	assignment, ok := radio.getStationAssignment(request.Station)
+	if !ok {
+		return fmt.Errorf("station %s does not have a team assigned", request.Station)
+	}
+	if request.DurationSec == 0 {
+		request.DurationSec = defaultIperfDurationSec
+	}
+	if request.DurationSec < 0 || request.DurationSec > maxIperfDurationSec {
+		return fmt.Errorf("invalid duration: %d (expecting 1-%d)", request.DurationSec, maxIperfDurationSec)
+	}
+	if request.MaxBandwidthMbps == 0 {
+		request.MaxBandwidthMbps = defaultIperfMaxBandwidthMbps
+	}
+	if request.MaxBandwidthMbps < 0 || request.MaxBandwidthMbps > maxIperfMaxBandwidthMbps {
+		return fmt.Errorf(
+			"invalid max bandwidth: %d (expecting 1-%d)", request.MaxBandwidthMbps, maxIperfMaxBandwidthMbps,
+		)
+	}
+
+	device, _ := uciTree.GetLast("network", fmt.Sprintf("vlan%d", assignment.vlan), "device")
+	if device == "" {
+		return fmt.Errorf("unable to determine network device for VLAN %d", assignment.vlan)
+	}
+
+	radio.iperf.mutex.Lock()
+	defer radio.iperf.mutex.Unlock()
+	if radio.iperf.request != nil {
+		return fmt.Errorf("iperf3 server is already running for station %s", radio.iperf.request.Station)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(request.DurationSec)*time.Second)
+	radio.iperf.request = &request
+	radio.iperf.cancel = cancel
+	radio.iperf.done = make(chan struct{})
+	log.Printf(
+		"Starting iperf3 server on %s for station %s (%s): %+v", device, request.Station, assignment.ssid, request,
+	)
+	go radio.runIperfServer(ctx, request, assignment.ssid, device, radio.iperf.done)
+	return nil
+}
+
+// runIperfServer runs the iperf3 server until it exits and records the result.
+func (radio *Radio) runIperfServer(ctx context.Context, request IperfRequest, ssid, device string, done chan struct{}) {
+	defer close(done)
+	result := IperfResult{Station: request.Station, Ssid: ssid, StartedAt: time.Now()}
+	var output bytes.Buffer
+	err := shell.streamCommand(
+		ctx,
+		&output,
+		"iperf3",
+		"--server",
+		"--one-off",
+		"--json",
+		"--bind-dev",
+		device,
+		"--server-bitrate-limit",
+		fmt.Sprintf("%dM", request.MaxBandwidthMbps),
+	)
+	result.FinishedAt = time.Now()
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.Error = "no test completed before the server timed out"
+	case ctx.Err() != nil:
+		result.Error = "server was stopped"
+	default:
+		result.ReceivedMbps, result.Error = parseIperfOutput(output.Bytes(), err)
+	}
+	log.Printf("iperf3 result for station %s (%s): %+v", request.Station, ssid, result)
+
+	radio.iperf.mutex.Lock()
+	defer radio.iperf.mutex.Unlock()
+	radio.iperf.cancel()
+	radio.iperf.request = nil
+	radio.iperf.cancel = nil
+	radio.iperf.results = append(radio.iperf.results, result)
+	if len(radio.iperf.results) > maxIperfResults {
+		radio.iperf.results = radio.iperf.results[len(radio.iperf.results)-maxIperfResults:]
+	}
+}
+
+// parseIperfOutput extracts the received throughput in megabits per second from the JSON output of an iperf3 server,
+// or a description of the error if the test failed.
+func parseIperfOutput(output []byte, runErr error) (float64, string) {
+	var report struct {
+		End struct {
+			SumReceived struct {
+				BitsPerSecond float64 `json:"bits_per_second"`
+			} `json:"sum_received"`
+		} `json:"end"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		if runErr != nil {
+			return 0, runErr.Error()
+		}
+		return 0, fmt.Sprintf("invalid iperf3 output: %v", err)
+	}
+	if report.Error != "" {
+		return 0, report.Error
+	}
+	if runErr != nil {
+		return 0, runErr.Error()
+	}
+	return math.Round(report.End.SumReceived.BitsPerSecond/1e5) / 10, ""
+}
+
+// StopIperfServer stops the running iperf3 server, if any, and waits for it to exit. Returns false if no server was
+// running.
+func (radio *Radio) StopIperfServer() bool {
+	radio.iperf.mutex.Lock()
+	if radio.iperf.request == nil {
+		radio.iperf.mutex.Unlock()
+		return false
+	}
+	radio.iperf.cancel()
+	done := radio.iperf.done
+	radio.iperf.mutex.Unlock()
+	<-done
+	return true
+}
+
+// GetIperfStatus returns the state of the iperf3 server and the results of past throughput checks.
+func (radio *Radio) GetIperfStatus() IperfStatus {
+	radio.iperf.mutex.Lock()
+	defer radio.iperf.mutex.Unlock()
+	status := IperfStatus{IsRunning: radio.iperf.request != nil, Results: make([]IperfResult, len(radio.iperf.results))}
+	if radio.iperf.request != nil {
+		request := *radio.iperf.request
+		status.Request = &request
+	}
+	copy(status.Results, radio.iperf.results)
+	return status
+}
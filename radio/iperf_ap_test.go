@@ -0,0 +1,122 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+const testIperfCommand = "iperf3 --server --one-off --json --bind-dev br-vlan40 --server-bitrate-limit 50M"
+
+func newIperfTestRadio() *Radio {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeTree.valuesForGet["network.vlan40.device"] = "br-vlan40"
+	radio := &Radio{
+		RedVlans:        Vlans102030,
+		BlueVlans:       Vlans405060,
+		StationStatuses: map[string]*NetworkStatus{"red1": nil, "blue1": {Ssid: "254"}},
+	}
+	radio.publishStationAssignments()
+	return radio
+}
+
+func TestRadio_StartIperfServer(t *testing.T) {
+	radio := newIperfTestRadio()
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput[testIperfCommand] = `{"end": {"sum_received": {"bits_per_second": 87654321}}}`
+
+	assert.Nil(t, radio.StartIperfServer(IperfRequest{Station: "blue1", DurationSec: 10, MaxBandwidthMbps: 50}))
+	assert.Eventually(t, func() bool { return !radio.GetIperfStatus().IsRunning }, time.Second, time.Millisecond)
+	status := radio.GetIperfStatus()
+	assert.Nil(t, status.Request)
+	if assert.Equal(t, 1, len(status.Results)) {
+		assert.Equal(t, "blue1", status.Results[0].Station)
+		assert.Equal(t, "254", status.Results[0].Ssid)
+		assert.Equal(t, 87.7, status.Results[0].ReceivedMbps)
+		assert.Equal(t, "", status.Results[0].Error)
+	}
+
+	// The test fails.
+	fakeShell.commandOutput[testIperfCommand] = `{"start": {}, "error": "the client has unexpectedly closed the connection"}`
+	assert.Nil(t, radio.StartIperfServer(IperfRequest{Station: "blue1", MaxBandwidthMbps: 50}))
+	assert.Eventually(t, func() bool { return !radio.GetIperfStatus().IsRunning }, time.Second, time.Millisecond)
+	status = radio.GetIperfStatus()
+	if assert.Equal(t, 2, len(status.Results)) {
+		assert.Equal(t, 0.0, status.Results[1].ReceivedMbps)
+		assert.Equal(t, "the client has unexpectedly closed the connection", status.Results[1].Error)
+	}
+}
+
+func TestRadio_StopIperfServer(t *testing.T) {
+	radio := newIperfTestRadio()
+	shell = blockingShell{newFakeShell(t)}
+	assert.False(t, radio.StopIperfServer())
+
+	assert.Nil(t, radio.StartIperfServer(IperfRequest{Station: "blue1", MaxBandwidthMbps: 50}))
+	status := radio.GetIperfStatus()
+	assert.True(t, status.IsRunning)
+	assert.Equal(t, &IperfRequest{Station: "blue1", DurationSec: 30, MaxBandwidthMbps: 50}, status.Request)
+	assert.EqualError(
+		t,
+		radio.StartIperfServer(IperfRequest{Station: "blue1"}),
+		"iperf3 server is already running for station blue1",
+	)
+
+	assert.True(t, radio.StopIperfServer())
+	status = radio.GetIperfStatus()
+	assert.False(t, status.IsRunning)
+	if assert.Equal(t, 1, len(status.Results)) {
+		assert.Equal(t, "server was stopped", status.Results[0].Error)
+	}
+
+	// The server times out.
+	assert.Nil(t, radio.StartIperfServer(IperfRequest{Station: "blue1", DurationSec: 1, MaxBandwidthMbps: 50}))
+	assert.Eventually(t, func() bool { return !radio.GetIperfStatus().IsRunning }, 3*time.Second, 10*time.Millisecond)
+	status = radio.GetIperfStatus()
+	if assert.Equal(t, 2, len(status.Results)) {
+		assert.Equal(t, "no test completed before the server timed out", status.Results[1].Error)
+	}
+}
+
+func TestRadio_StartIperfServerErrors(t *testing.T) {
+	radio := newIperfTestRadio()
+	shell = newFakeShell(t)
+
+	assert.EqualError(t, radio.StartIperfServer(IperfRequest{Station: "red4"}), "invalid station: red4")
+	assert.EqualError(
+		t, radio.StartIperfServer(IperfRequest{Station: "red1"}), "station red1 does not have a team assigned",
+	)
+	assert.EqualError(
+		t,
+		radio.StartIperfServer(IperfRequest{Station: "blue1", DurationSec: 301}),
+		"invalid duration: 301 (expecting 1-300)",
+	)
+	assert.EqualError(
+		t,
+		radio.StartIperfServer(IperfRequest{Station: "blue1", MaxBandwidthMbps: -1}),
+		"invalid max bandwidth: -1 (expecting 1-1000)",
+	)
+	radio.BlueVlans = Vlans708090
+	radio.publishStationAssignments()
+	assert.EqualError(
+		t, radio.StartIperfServer(IperfRequest{Station: "blue1"}), "unable to determine network device for VLAN 70",
+	)
+	assert.False(t, radio.GetIperfStatus().IsRunning)
+}
+
+func TestParseIperfOutput(t *testing.T) {
+	mbps, errorMessage := parseIperfOutput([]byte(`{"end": {"sum_received": {"bits_per_second": 1.5e7}}}`), nil)
+	assert.Equal(t, 15.0, mbps)
+	assert.Equal(t, "", errorMessage)
+
+	_, errorMessage = parseIperfOutput([]byte("garbage"), errors.New("exit status 1"))
+	assert.Equal(t, "exit status 1", errorMessage)
+	_, errorMessage = parseIperfOutput([]byte("garbage"), nil)
+	assert.Contains(t, errorMessage, "invalid iperf3 output")
+}
@@ -0,0 +1,53 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package radio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Prefix of the payload encoded in the QR codes scanned at a radio-flashing station, which takes the form
+// "FRC:<team number>:<WPA key>".
+const kioskPayloadPrefix = "FRC:"
+
+// KioskProvisioningRequest represents a JSON request from the kiosk provisioning page to configure the radio for a team
+// with the standard FRC robot radio configuration.
+type KioskProvisioningRequest struct {
+	// Team number to configure the radio for. Ignored if a payload is given.
+	TeamNumber int `json:"teamNumber"`
+
+	// WPA key for both of the radio's networks. Ignored if a payload is given.
+	WpaKey string `json:"wpaKey"`
+
+	// Contents of a scanned QR code giving the team number and WPA key, in the form "FRC:<team number>:<WPA key>".
+	Payload string `json:"payload"`
+}
+
+// ConfigurationRequest returns the configuration request that applies the standard FRC robot radio configuration for
+// the team and WPA key given by this provisioning request. The returned request still has to be validated.
+func (request KioskProvisioningRequest) ConfigurationRequest() (ConfigurationRequest, error) {
+	teamNumber, wpaKey := request.TeamNumber, request.WpaKey
+	if request.Payload != "" {
+		fields := strings.SplitN(strings.TrimSpace(request.Payload), ":", 3)
+		if len(fields) != 3 || fields[0]+":" != kioskPayloadPrefix {
+			return ConfigurationRequest{}, fmt.Errorf(
+				"invalid payload: %q (expecting %s<team number>:<WPA key>)", request.Payload, kioskPayloadPrefix,
+			)
+		}
+		var err error
+		if teamNumber, err = strconv.Atoi(fields[1]); err != nil {
+			return ConfigurationRequest{}, fmt.Errorf("invalid team number in payload: %s", fields[1])
+		}
+		wpaKey = fields[2]
+	}
+
+	return ConfigurationRequest{
+		Mode:       modeTeamRobotRadio,
+		TeamNumber: teamNumber,
+		WpaKey6:    wpaKey,
+		WpaKey24:   wpaKey,
+	}, nil
+}
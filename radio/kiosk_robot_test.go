@@ -0,0 +1,35 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestKioskProvisioningRequest_ConfigurationRequest(t *testing.T) {
+	expected := ConfigurationRequest{
+		Mode: modeTeamRobotRadio, TeamNumber: 254, WpaKey6: "12345678", WpaKey24: "12345678",
+	}
+
+	request, err := KioskProvisioningRequest{TeamNumber: 254, WpaKey: "12345678"}.ConfigurationRequest()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, request)
+
+	// The payload takes precedence over the individual fields.
+	request, err = KioskProvisioningRequest{TeamNumber: 1, WpaKey: "abc", Payload: "FRC:254:12345678\n"}.
+		ConfigurationRequest()
+	assert.Nil(t, err)
+	assert.Equal(t, expected, request)
+
+	// WPA keys may contain the separator.
+	request, err = KioskProvisioningRequest{Payload: "FRC:254:1234:5678"}.ConfigurationRequest()
+	assert.Nil(t, err)
+	assert.Equal(t, "1234:5678", request.WpaKey6)
+
+	_, err = KioskProvisioningRequest{Payload: "254:12345678"}.ConfigurationRequest()
+	assert.EqualError(t, err, "invalid payload: \"254:12345678\" (expecting FRC:<team number>:<WPA key>)")
+	_, err = KioskProvisioningRequest{Payload: "FRC:team:12345678"}.ConfigurationRequest()
+	assert.EqualError(t, err, "invalid team number in payload: team")
+}
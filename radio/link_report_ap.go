@@ -0,0 +1,217 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	// Number of pings sent at each packet size during a link test.
+	linkTestPingCount = 5
+
+	// Round-trip time above which a link is considered degraded, in milliseconds.
+	linkTestDegradedRttMs = 20
+)
+
+// ICMP payload sizes swept during a link test, from a minimal ping up to nearly a full Ethernet frame.
+var linkTestPacketSizes = []int{56, 512, 1400}
+
+var (
+	pingSummaryRe        = regexp.MustCompile(`(\d+) packets transmitted, (\d+) (?:packets )?received`)
+	pingRoundTripRe      = regexp.MustCompile(`min/avg/max(?:/mdev)? = ([\d.]+)/([\d.]+)/([\d.]+)`)
+	expectedThroughputRe = regexp.MustCompile(`expected throughput:\s+([\d.]+)Mbps`)
+)
+
+// ErrLinkTestInProgress is returned when a link test is requested while another is still running.
+var ErrLinkTestInProgress = errors.New("a link test is already running")
+
+// linkVerdict represents the overall assessment of the link to a single robot.
+type linkVerdict string
+
+const (
+	// Every ping was answered promptly.
+	linkVerdictHealthy linkVerdict = "HEALTHY"
+
+	// Some pings were lost or slow.
+	linkVerdictDegraded linkVerdict = "DEGRADED"
+
+	// No pings were answered at any packet size.
+	linkVerdictUnreachable linkVerdict = "UNREACHABLE"
+)
+
+// PingResult represents the outcome of the pings sent at a single packet size during a link test.
+type PingResult struct {
+	// Size of the ICMP payload, in bytes.
+	PacketSizeBytes int `json:"packetSizeBytes"`
+
+	// Number of pings sent and answered.
+	Sent     int `json:"sent"`
+	Received int `json:"received"`
+
+	// Percentage of pings that went unanswered.
+	LossPercent float64 `json:"lossPercent"`
+
+	// Round-trip times of the answered pings, in milliseconds. Zero if none were answered.
+	MinRttMs float64 `json:"minRttMs"`
+	AvgRttMs float64 `json:"avgRttMs"`
+	MaxRttMs float64 `json:"maxRttMs"`
+}
+
+// LinkReport represents the outcome of an active test of the link between the access point and the robot associated
+// with one team station.
+type LinkReport struct {
+	// Team station that was tested.
+	Station string `json:"station"`
+
+	// SSID (i.e. team number) assigned to the station at the time of the test.
+	Ssid string `json:"ssid"`
+
+	// MAC address of the robot radio associated with the station.
+	MacAddress string `json:"macAddress"`
+
+	// IP address of the robot radio that was pinged.
+	IpAddress string `json:"ipAddress"`
+
+	// Time at which the test started.
+	StartedAt Timestamp `json:"startedAt"`
+
+	// How long the test took, in seconds.
+	DurationSec float64 `json:"durationSec"`
+
+	// Signal strength and signal-to-noise ratio of the link at the start of the test, in dBm and dB respectively.
+	SignalDbm        int `json:"signalDbm"`
+	SignalNoiseRatio int `json:"signalNoiseRatio"`
+
+	// Upper-bound link rates at the start of the test, in megabits per second.
+	RxRateMbps float64 `json:"rxRateMbps"`
+	TxRateMbps float64 `json:"txRateMbps"`
+
+	// Throughput that the driver expects to achieve to the robot, in megabits per second, or zero if it doesn't
+	// report one.
+	ExpectedThroughputMbps float64 `json:"expectedThroughputMbps"`
+
+	// Outcome of the pings at each packet size, in increasing order of size.
+	Pings []PingResult `json:"pings"`
+
+	// Overall assessment of the link: "HEALTHY", "DEGRADED", or "UNREACHABLE".
+	Verdict linkVerdict `json:"verdict"`
+}
+
+// RunLinkTest performs a brief active test of the link to the robot associated with the given team station, sweeping
+// pings to its radio across several packet sizes, and returns a report of the result. Only one test runs at a time so
+// that the tests themselves don't load the field.
+func (radio *Radio) RunLinkTest(stationName string) (*LinkReport, error) {
+	station, ok := parseStation(stationName)
+	if !ok {
+		return nil, fmt.Errorf("invalid station: %s", stationName)
+	}
+	assignment, ok := radio.getStationAssignment(stationName)
+	if !ok {
+		return nil, fmt.Errorf("station %s does not have a team assigned", stationName)
+	}
+	teamNumber, err := strconv.Atoi(assignment.ssid)
+	if err != nil {
+		return nil, fmt.Errorf("station %s SSID %q is not a team number", stationName, assignment.ssid)
+	}
+	if !radio.linkTestMutex.TryLock() {
+		return nil, ErrLinkTestInProgress
+	}
+	defer radio.linkTestMutex.Unlock()
+
+	wifiInterface := radio.stationInterfaces[station]
+	output, err := shell.runCommand("iwinfo", wifiInterface, "assoclist")
+	if err != nil {
+		return nil, fmt.Errorf("error getting association list for interface %s: %v", wifiInterface, err)
+	}
+	var link NetworkStatus
+	link.parseAssocList(output)
+	if !link.IsLinked {
+		return nil, fmt.Errorf("station %s does not have a linked robot", stationName)
+	}
+
+	startTime := time.Now()
+	report := LinkReport{
+		Station:          stationName,
+		Ssid:             assignment.ssid,
+		MacAddress:       link.MacAddress,
+		IpAddress:        fmt.Sprintf("10.%d.%d.1", teamNumber/100, teamNumber%100),
+		StartedAt:        newTimestamp(),
+		SignalDbm:        link.SignalDbm,
+		SignalNoiseRatio: link.SignalNoiseRatio,
+		RxRateMbps:       link.RxRateMbps,
+		TxRateMbps:       link.TxRateMbps,
+	}
+	if output, err = shell.runCommand("iw", "dev", wifiInterface, "station", "get", link.MacAddress); err != nil {
+		log.Printf("Error getting expected throughput for %s on %s: %v", link.MacAddress, wifiInterface, err)
+	} else if matches := expectedThroughputRe.FindStringSubmatch(output); len(matches) > 0 {
+		report.ExpectedThroughputMbps, _ = strconv.ParseFloat(matches[1], 64)
+	}
+	for _, packetSize := range linkTestPacketSizes {
+		report.Pings = append(report.Pings, ping(report.IpAddress, packetSize))
+	}
+	report.DurationSec = time.Since(startTime).Seconds()
+	report.Verdict = assessLink(report.Pings)
+	log.Printf("Link test for station %s (%s): %+v", stationName, assignment.ssid, report)
+	return &report, nil
+}
+
+// ping sends a burst of pings with the given payload size to the given address and summarizes the outcome. A ping
+// that exits with an error because nothing was answered is reported as total loss.
+func ping(ipAddress string, packetSize int) PingResult {
+	result := PingResult{PacketSizeBytes: packetSize, Sent: linkTestPingCount, LossPercent: 100}
+	output, err := shell.runCommand(
+		"ping", "-c", strconv.Itoa(linkTestPingCount), "-W", "1", "-s", strconv.Itoa(packetSize), ipAddress,
+	)
+	matches := pingSummaryRe.FindStringSubmatch(output)
+	if len(matches) == 0 {
+		if err != nil {
+			log.Printf("Error pinging %s with %d-byte packets: %v", ipAddress, packetSize, err)
+		}
+		return result
+	}
+	result.Sent, _ = strconv.Atoi(matches[1])
+	result.Received, _ = strconv.Atoi(matches[2])
+	if result.Sent > 0 {
+		result.LossPercent = float64(100*(result.Sent-result.Received)) / float64(result.Sent)
+	}
+	if matches = pingRoundTripRe.FindStringSubmatch(output); len(matches) > 0 {
+		result.MinRttMs, _ = strconv.ParseFloat(matches[1], 64)
+		result.AvgRttMs, _ = strconv.ParseFloat(matches[2], 64)
+		result.MaxRttMs, _ = strconv.ParseFloat(matches[3], 64)
+	}
+	return result
+}
+
+// assessLink returns the overall verdict on a link given the outcome of the ping sweep.
+func assessLink(pings []PingResult) linkVerdict {
+	verdict := linkVerdictUnreachable
+	for _, result := range pings {
+		if result.Received > 0 && verdict == linkVerdictUnreachable {
+			verdict = linkVerdictHealthy
+		}
+	}
+	for _, result := range pings {
+		// Large packets being lost while small ones get through also points to a marginal link.
+		if verdict == linkVerdictHealthy && (result.LossPercent > 0 || result.AvgRttMs > linkTestDegradedRttMs) {
+			verdict = linkVerdictDegraded
+		}
+	}
+	return verdict
+}
+
+// parseStation returns the team station with the given name (e.g. "red1"), and false if there is none.
+func parseStation(name string) (station, bool) {
+	for station := red1; station <= blue3; station++ {
+		if name == station.String() {
+			return station, true
+		}
+	}
+	return 0, false
+}
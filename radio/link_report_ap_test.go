@@ -0,0 +1,116 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const testLinkAssocList = "48:DA:35:B0:00:CF  -53 dBm / -95 dBm (SNR 42)  0 ms ago\n" +
+	"\tRX: 550.6 MBit/s                                4095 Pkts.\n" +
+	"\tTX: 254.0 MBit/s                                 123 Pkts.\n" +
+	"\texpected throughput: unknown"
+
+func newLinkTestRadio(t *testing.T) (*Radio, *fakeShell) {
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	radio := &Radio{
+		stationInterfaces: map[station]string{red1: "ath1", blue1: "ath13"},
+		StationStatuses:   map[string]*NetworkStatus{"red1": nil, "blue1": {Ssid: "254"}},
+	}
+	radio.publishStationAssignments()
+	return radio, fakeShell
+}
+
+func pingOutput(sent, received int, roundTrip string) string {
+	output := fmt.Sprintf(
+		"PING 10.2.54.1 (10.2.54.1): 56 data bytes\n\n--- 10.2.54.1 ping statistics ---\n"+
+			"%d packets transmitted, %d packets received\n",
+		sent,
+		received,
+	)
+	if roundTrip != "" {
+		output += "round-trip min/avg/max = " + roundTrip + " ms\n"
+	}
+	return output
+}
+
+func TestRadio_RunLinkTest(t *testing.T) {
+	radio, fakeShell := newLinkTestRadio(t)
+	fakeShell.commandOutput["iwinfo ath13 assoclist"] = testLinkAssocList
+	fakeShell.commandOutput["iw dev ath13 station get 48:DA:35:B0:00:CF"] =
+		"Station 48:da:35:b0:00:cf (on ath13)\n\tsignal:  \t-53 dBm\n\texpected throughput:\t312.5Mbps\n"
+	fakeShell.commandOutput["ping -c 5 -W 1 -s 56 10.2.54.1"] = pingOutput(5, 5, "1.201/1.532/2.004")
+	fakeShell.commandOutput["ping -c 5 -W 1 -s 512 10.2.54.1"] = pingOutput(5, 5, "1.402/2.113/3.250")
+	fakeShell.commandOutput["ping -c 5 -W 1 -s 1400 10.2.54.1"] = pingOutput(5, 5, "2.015/2.841/4.122")
+
+	report, err := radio.RunLinkTest("blue1")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "blue1", report.Station)
+		assert.Equal(t, "254", report.Ssid)
+		assert.Equal(t, "48:DA:35:B0:00:CF", report.MacAddress)
+		assert.Equal(t, "10.2.54.1", report.IpAddress)
+		assert.Equal(t, -53, report.SignalDbm)
+		assert.Equal(t, 42, report.SignalNoiseRatio)
+		assert.Equal(t, 550.6, report.RxRateMbps)
+		assert.Equal(t, 254.0, report.TxRateMbps)
+		assert.Equal(t, 312.5, report.ExpectedThroughputMbps)
+		assert.Equal(
+			t,
+			[]PingResult{
+				{PacketSizeBytes: 56, Sent: 5, Received: 5, MinRttMs: 1.201, AvgRttMs: 1.532, MaxRttMs: 2.004},
+				{PacketSizeBytes: 512, Sent: 5, Received: 5, MinRttMs: 1.402, AvgRttMs: 2.113, MaxRttMs: 3.25},
+				{PacketSizeBytes: 1400, Sent: 5, Received: 5, MinRttMs: 2.015, AvgRttMs: 2.841, MaxRttMs: 4.122},
+			},
+			report.Pings,
+		)
+		assert.Equal(t, linkVerdictHealthy, report.Verdict)
+	}
+
+	// Large packets are lost and the driver doesn't report an expected throughput.
+	fakeShell.commandOutput["iw dev ath13 station get 48:DA:35:B0:00:CF"] = "Station 48:da:35:b0:00:cf (on ath13)\n"
+	delete(fakeShell.commandOutput, "ping -c 5 -W 1 -s 1400 10.2.54.1")
+	fakeShell.commandErrors["ping -c 5 -W 1 -s 1400 10.2.54.1"] = errors.New("exit status 1")
+	report, err = radio.RunLinkTest("blue1")
+	if assert.Nil(t, err) {
+		assert.Equal(t, 0.0, report.ExpectedThroughputMbps)
+		assert.Equal(t, PingResult{PacketSizeBytes: 1400, Sent: 5, LossPercent: 100}, report.Pings[2])
+		assert.Equal(t, linkVerdictDegraded, report.Verdict)
+	}
+}
+
+func TestRadio_RunLinkTestErrors(t *testing.T) {
+	radio, fakeShell := newLinkTestRadio(t)
+
+	_, err := radio.RunLinkTest("green1")
+	assert.EqualError(t, err, "invalid station: green1")
+
+	_, err = radio.RunLinkTest("red1")
+	assert.EqualError(t, err, "station red1 does not have a team assigned")
+
+	fakeShell.commandOutput["iwinfo ath13 assoclist"] = "No station connected\n"
+	_, err = radio.RunLinkTest("blue1")
+	assert.EqualError(t, err, "station blue1 does not have a linked robot")
+
+	radio.linkTestMutex.Lock()
+	_, err = radio.RunLinkTest("blue1")
+	assert.Equal(t, ErrLinkTestInProgress, err)
+	radio.linkTestMutex.Unlock()
+}
+
+func TestAssessLink(t *testing.T) {
+	healthy := PingResult{Sent: 5, Received: 5, AvgRttMs: 2.5}
+	lossy := PingResult{Sent: 5, Received: 4, LossPercent: 20, AvgRttMs: 2.5}
+	slow := PingResult{Sent: 5, Received: 5, AvgRttMs: 35}
+	lost := PingResult{Sent: 5, LossPercent: 100}
+
+	assert.Equal(t, linkVerdictHealthy, assessLink([]PingResult{healthy, healthy, healthy}))
+	assert.Equal(t, linkVerdictDegraded, assessLink([]PingResult{healthy, lossy, healthy}))
+	assert.Equal(t, linkVerdictDegraded, assessLink([]PingResult{healthy, healthy, slow}))
+	assert.Equal(t, linkVerdictDegraded, assessLink([]PingResult{healthy, healthy, lost}))
+	assert.Equal(t, linkVerdictUnreachable, assessLink([]PingResult{lost, lost, lost}))
+}
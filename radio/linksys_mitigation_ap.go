@@ -0,0 +1,168 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// linksysFailureMode represents a known way in which the wireless stack of the Linksys access point fails.
+type linksysFailureMode string
+
+const (
+	// The hostapd daemon crashed, taking all the networks down with it.
+	linksysFailureHostapdCrash linksysFailureMode = "HOSTAPD_CRASH"
+
+	// Some station interfaces didn't come back after the wireless configuration was reloaded.
+	linksysFailureMissingInterfaces linksysFailureMode = "MISSING_INTERFACES"
+)
+
+// linksysMitigation represents an action taken to recover the Linksys access point from a failure.
+type linksysMitigation string
+
+const (
+	// Re-run the sequence of clearing the stations and then configuring them.
+	linksysMitigationReconfigure linksysMitigation = "RECONFIGURE"
+
+	// Take the wireless device down and bring it back up before re-running the clear-then-configure sequence.
+	linksysMitigationWirelessRestart linksysMitigation = "WIRELESS_RESTART"
+)
+
+// Mitigations to attempt in turn while a configuration keeps failing in a known way, before giving up on it.
+var linksysMitigations = []linksysMitigation{linksysMitigationReconfigure, linksysMitigationWirelessRestart}
+
+// LinksysMitigationStatus represents the known failures of the Linksys wireless stack that have been detected while
+// configuring it and the mitigations attempted for them since the API started.
+type LinksysMitigationStatus struct {
+	// Number of times hostapd was found not to be running after a reload.
+	HostapdCrashCount int `json:"hostapdCrashCount"`
+
+	// Number of times station interfaces were found missing after a reload.
+	MissingInterfaceCount int `json:"missingInterfaceCount"`
+
+	// Number of times the clear-then-configure sequence was re-run.
+	ReconfigureCount int `json:"reconfigureCount"`
+
+	// Number of times the wireless device was restarted.
+	WirelessRestartCount int `json:"wirelessRestartCount"`
+
+	// Description of the most recently detected failure, or blank if there has been none.
+	LastFailure string `json:"lastFailure"`
+
+	// Time at which the most recent failure was detected. Zero if there has been none.
+	LastFailureAt Timestamp `json:"lastFailureAt"`
+}
+
+// linksysFailure is the error returned when the Linksys wireless stack is found to have failed in a known way.
+type linksysFailure struct {
+	mode linksysFailureMode
+
+	// Station interfaces that are missing, for a failure of that mode.
+	missingInterfaces []string
+}
+
+func (failure *linksysFailure) Error() string {
+	if failure.mode == linksysFailureHostapdCrash {
+		return "hostapd is not running"
+	}
+	return fmt.Sprintf("station interfaces missing after reload: %s", strings.Join(failure.missingInterfaces, ", "))
+}
+
+// configureLinksysStations clears the stations and then configures them with the given configurations, which the
+// Linksys access point is crash-prone without. If the wireless stack fails in a known way along the way, it is
+// mitigated and the sequence re-run rather than retrying reloads that can't succeed.
+func (radio *Radio) configureLinksysStations(stationConfigurations map[string]*StationConfiguration) error {
+	for attempt := 0; ; attempt++ {
+		err := radio.configureStations(withOmittedStationsUnassigned(nil))
+		if err == nil {
+			time.Sleep(radio.reloadBackoffDuration())
+			err = radio.configureStations(stationConfigurations)
+		}
+		var failure *linksysFailure
+		if !errors.As(err, &failure) {
+			return err
+		}
+		radio.recordLinksysFailure(failure)
+		if attempt >= len(linksysMitigations) {
+			return fmt.Errorf("%v; giving up after %d mitigation attempts", err, attempt)
+		}
+		mitigation := linksysMitigations[attempt]
+		radio.raiseAlert(
+			"LINKSYS_FAILURE", "Wireless stack failed (%v); attempting mitigation %s.", failure, mitigation,
+		)
+		if err = radio.mitigateLinksysFailure(mitigation); err != nil {
+			return fmt.Errorf("failed to mitigate wireless stack failure with %s: %v", mitigation, err)
+		}
+	}
+}
+
+// detectLinksysFailure checks the Linksys wireless stack for its known failure modes after a reload, returning the
+// failure found or nil if there is none.
+func (radio *Radio) detectLinksysFailure() *linksysFailure {
+	if _, err := shell.runCommand("pidof", "hostapd"); err != nil {
+		return &linksysFailure{mode: linksysFailureHostapdCrash}
+	}
+	output, err := shell.runCommand("iw", "dev")
+	if err != nil {
+		log.Printf("Error listing wireless interfaces: %v", err)
+		return nil
+	}
+	presentInterfaces := make(map[string]struct{})
+	for _, line := range strings.Split(output, "\n") {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(line), "Interface "); ok {
+			presentInterfaces[name] = struct{}{}
+		}
+	}
+	var missingInterfaces []string
+	for station := red1; station <= blue3; station++ {
+		if radio.isStationDisabled(station) {
+			continue
+		}
+		if _, ok := presentInterfaces[radio.stationInterfaces[station]]; !ok {
+			missingInterfaces = append(missingInterfaces, radio.stationInterfaces[station])
+		}
+	}
+	if len(missingInterfaces) > 0 {
+		return &linksysFailure{mode: linksysFailureMissingInterfaces, missingInterfaces: missingInterfaces}
+	}
+	return nil
+}
+
+// recordLinksysFailure counts the given failure in the status.
+func (radio *Radio) recordLinksysFailure(failure *linksysFailure) {
+	switch failure.mode {
+	case linksysFailureHostapdCrash:
+		radio.LinksysMitigation.HostapdCrashCount++
+	case linksysFailureMissingInterfaces:
+		radio.LinksysMitigation.MissingInterfaceCount++
+	}
+	radio.LinksysMitigation.LastFailure = failure.Error()
+	radio.LinksysMitigation.LastFailureAt = newTimestamp()
+	radio.markStatusChanged()
+}
+
+// mitigateLinksysFailure takes the given action to recover the wireless stack ahead of re-running the
+// clear-then-configure sequence.
+func (radio *Radio) mitigateLinksysFailure(mitigation linksysMitigation) error {
+	switch mitigation {
+	case linksysMitigationReconfigure:
+		radio.LinksysMitigation.ReconfigureCount++
+	case linksysMitigationWirelessRestart:
+		radio.LinksysMitigation.WirelessRestartCount++
+		if _, err := shell.runCommand("wifi", "down", radio.device); err != nil {
+			return err
+		}
+		if _, err := shell.runCommand("wifi", "up", radio.device); err != nil {
+			return err
+		}
+	}
+	radio.updateConfigurationStats(func(stats *ConfigurationStats) { stats.MitigationCount++ })
+	radio.markStatusChanged()
+	time.Sleep(radio.reloadBackoffDuration())
+	return nil
+}
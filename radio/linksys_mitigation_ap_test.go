@@ -0,0 +1,125 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// newLinksysRadio returns a Linksys radio on which hostapd is running.
+func newLinksysRadio(t *testing.T) (*Radio, *fakeShell) {
+	uciTree = newFakeUciTree()
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	wifiReloadBackoffDuration = 10 * time.Millisecond
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+	fakeShell.commandOutput["wifi reload radio0"] = ""
+	fakeShell.commandOutput["pidof hostapd"] = "1234\n"
+	return radio, fakeShell
+}
+
+// setStationInterfaces sets up each station interface of the given radio with its placeholder SSID, except for the
+// given missing ones, which have disappeared as they do when the Linksys wireless stack fails.
+func setStationInterfaces(radio *Radio, fakeShell *fakeShell, missingInterfaces ...string) {
+	isMissing := make(map[string]bool)
+	for _, wifiInterface := range missingInterfaces {
+		isMissing[wifiInterface] = true
+	}
+	fakeShell.commandOutput["iw dev"] = "phy#0\n"
+	for station := red1; station <= blue3; station++ {
+		wifiInterface := radio.stationInterfaces[station]
+		command := fmt.Sprintf("iwinfo %s info", wifiInterface)
+		if isMissing[wifiInterface] {
+			delete(fakeShell.commandOutput, command)
+			fakeShell.commandErrors[command] = errors.New("No such wireless device")
+			continue
+		}
+		delete(fakeShell.commandErrors, command)
+		fakeShell.commandOutput["iw dev"] += fmt.Sprintf("\tInterface %s\n\t\ttype AP\n", wifiInterface)
+		fakeShell.commandOutput[command] = fmt.Sprintf("%s\nESSID: \"no-team-%d\"\n", wifiInterface, int(station)+1)
+	}
+}
+
+func TestRadio_detectLinksysFailure(t *testing.T) {
+	radio, fakeShell := newLinksysRadio(t)
+	setStationInterfaces(radio, fakeShell)
+	assert.Nil(t, radio.detectLinksysFailure())
+
+	setStationInterfaces(radio, fakeShell, "wlan0-3", "wlan0-5")
+	failure := radio.detectLinksysFailure()
+	if assert.NotNil(t, failure) {
+		assert.Equal(t, linksysFailureMissingInterfaces, failure.mode)
+		assert.EqualError(
+			t, failure, "station interfaces missing after reload: wlan0-3, wlan0-5",
+		)
+	}
+
+	delete(fakeShell.commandOutput, "pidof hostapd")
+	fakeShell.commandErrors["pidof hostapd"] = errors.New("exit status 1")
+	failure = radio.detectLinksysFailure()
+	if assert.NotNil(t, failure) {
+		assert.Equal(t, linksysFailureHostapdCrash, failure.mode)
+		assert.EqualError(t, failure, "hostapd is not running")
+	}
+}
+
+func TestRadio_configureLinksysStationsMitigatesFailure(t *testing.T) {
+	radio, fakeShell := newLinksysRadio(t)
+	setStationInterfaces(radio, fakeShell, "wlan0-3")
+	fakeShell.commandOutput["wifi down radio0"] = ""
+	fakeShell.commandOutput["wifi up radio0"] = ""
+	fakeShell.onRunCommand = func(command string) {
+		if command == "wifi up radio0" {
+			// Restarting the wireless stack brings the interface back.
+			setStationInterfaces(radio, fakeShell)
+		}
+	}
+
+	assert.Nil(t, radio.configureLinksysStations(withOmittedStationsUnassigned(nil)))
+	assert.Equal(
+		t,
+		LinksysMitigationStatus{
+			MissingInterfaceCount: 2,
+			ReconfigureCount:      1,
+			WirelessRestartCount:  1,
+			LastFailure:           "station interfaces missing after reload: wlan0-3",
+			LastFailureAt:         radio.LinksysMitigation.LastFailureAt,
+		},
+		radio.LinksysMitigation,
+	)
+	assert.Equal(t, 2, radio.currentConfigurationStats().MitigationCount)
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 2, len(alerts)) {
+		assert.Equal(t, "LINKSYS_FAILURE", alerts[0].Type)
+		assert.Equal(
+			t,
+			"Wireless stack failed (station interfaces missing after reload: wlan0-3); attempting mitigation "+
+				"RECONFIGURE.",
+			alerts[0].Message,
+		)
+		assert.Contains(t, alerts[1].Message, "attempting mitigation WIRELESS_RESTART")
+	}
+}
+
+func TestRadio_configureLinksysStationsGivesUp(t *testing.T) {
+	radio, fakeShell := newLinksysRadio(t)
+	setStationInterfaces(radio, fakeShell, "wlan0")
+	delete(fakeShell.commandOutput, "pidof hostapd")
+	fakeShell.commandErrors["pidof hostapd"] = errors.New("exit status 1")
+	fakeShell.commandOutput["wifi down radio0"] = ""
+	fakeShell.commandOutput["wifi up radio0"] = ""
+
+	assert.EqualError(
+		t,
+		radio.configureLinksysStations(withOmittedStationsUnassigned(nil)),
+		"hostapd is not running; giving up after 2 mitigation attempts",
+	)
+	assert.Equal(t, 3, radio.LinksysMitigation.HostapdCrashCount)
+	assert.Equal(t, 1, radio.LinksysMitigation.WirelessRestartCount)
+}
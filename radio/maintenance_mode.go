@@ -0,0 +1,149 @@
+package radio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// Maximum length of the reason given for enabling maintenance mode.
+	maxMaintenanceReasonLength = 200
+
+	// Interval at which the maintenance button's GPIO is polled for presses.
+	maintenanceButtonPollIntervalMs = 100
+)
+
+// maintenanceSource represents how maintenance mode was enabled.
+type maintenanceSource string
+
+const (
+	// Maintenance mode was enabled via the /maintenance endpoint.
+	maintenanceSourceApi maintenanceSource = "API"
+
+	// Maintenance mode was enabled by pressing the hardware maintenance button.
+	maintenanceSourceButton maintenanceSource = "BUTTON"
+)
+
+// MaintenanceStatus represents whether the radio is in maintenance mode, during which the API rejects requests that
+// would change its configuration and the radio makes no automatic configuration changes of its own, while monitoring
+// and status reporting carry on as usual.
+type MaintenanceStatus struct {
+	// Whether maintenance mode is currently enabled.
+	IsEnabled bool `json:"isEnabled"`
+
+	// Why maintenance mode was enabled, as returned to rejected requests. Blank if not enabled.
+	Reason string `json:"reason"`
+
+	// How maintenance mode was enabled: "API" or "BUTTON". Blank if not enabled.
+	Source maintenanceSource `json:"source"`
+
+	// Time at which maintenance mode was enabled. Zero if not enabled.
+	EnabledAt Timestamp `json:"enabledAt"`
+}
+
+// MaintenanceButtonSettings holds the parameters for reading a hardware button that toggles maintenance mode.
+type MaintenanceButtonSettings struct {
+	// Path to the sysfs value file of the button's GPIO (e.g. "/sys/class/gpio/gpio12/value"). Blank disables the
+	// button.
+	GpioValuePath string `json:"gpioValuePath"`
+
+	// Whether the GPIO reads as 0 while the button is pressed, as is typical of buttons with a pull-up resistor.
+	ActiveLow bool `json:"activeLow"`
+}
+
+// validate checks that the maintenance button settings have valid values.
+func (settings MaintenanceButtonSettings) validate() error {
+	if settings.GpioValuePath != "" && !filepath.IsAbs(settings.GpioValuePath) {
+		return fmt.Errorf(
+			"invalid maintenanceButton.gpioValuePath: %q (expecting an absolute path)",
+			settings.GpioValuePath,
+		)
+	}
+	return nil
+}
+
+// SetMaintenanceMode enables or disables maintenance mode on behalf of an API client, recording the given reason for
+// enabling it.
+func (radio *Radio) SetMaintenanceMode(isEnabled bool, reason string) error {
+	if len(reason) > maxMaintenanceReasonLength {
+		return fmt.Errorf(
+			"invalid reason: %d characters long (expecting at most %d)",
+			len(reason),
+			maxMaintenanceReasonLength,
+		)
+	}
+	radio.setMaintenanceMode(isEnabled, reason, maintenanceSourceApi)
+	return nil
+}
+
+// GetMaintenanceStatus returns a snapshot of the maintenance mode state.
+func (radio *Radio) GetMaintenanceStatus() MaintenanceStatus {
+	radio.maintenanceMutex.Lock()
+	defer radio.maintenanceMutex.Unlock()
+	return radio.Maintenance
+}
+
+// isInMaintenance returns true if maintenance mode is enabled.
+func (radio *Radio) isInMaintenance() bool {
+	return radio.GetMaintenanceStatus().IsEnabled
+}
+
+// setMaintenanceMode enables or disables maintenance mode, raising an alert if its state changes.
+func (radio *Radio) setMaintenanceMode(isEnabled bool, reason string, source maintenanceSource) {
+	radio.maintenanceMutex.Lock()
+	wasEnabled := radio.Maintenance.IsEnabled
+	if isEnabled {
+		if reason == "" {
+			reason = "maintenance in progress"
+		}
+		radio.Maintenance = MaintenanceStatus{
+			IsEnabled: true, Reason: reason, Source: source, EnabledAt: newTimestamp(),
+		}
+	} else {
+		radio.Maintenance = MaintenanceStatus{}
+	}
+	radio.maintenanceMutex.Unlock()
+	radio.markStatusChanged()
+
+	if isEnabled && !wasEnabled {
+		radio.raiseAlert("MAINTENANCE_MODE", "Maintenance mode enabled via %s: %s", source, reason)
+	} else if !isEnabled && wasEnabled {
+		radio.raiseAlert("MAINTENANCE_MODE", "Maintenance mode disabled via %s.", source)
+	}
+}
+
+// watchMaintenanceButton polls the maintenance button indefinitely, toggling maintenance mode each time it is pressed.
+// The button's settings are re-read at each poll so that a settings reload takes effect without a restart.
+func (radio *Radio) watchMaintenanceButton() {
+	wasPressed := false
+	for {
+		wasPressed = radio.pollMaintenanceButton(wasPressed)
+		time.Sleep(maintenanceButtonPollIntervalMs * time.Millisecond)
+	}
+}
+
+// pollMaintenanceButton reads the state of the maintenance button given whether it was pressed as of the previous
+// poll, toggles maintenance mode if it has just been pressed, and returns whether it is currently pressed. Read errors
+// are not logged since the GPIO is polled so frequently.
+func (radio *Radio) pollMaintenanceButton(wasPressed bool) bool {
+	button := radio.GetSettings().MaintenanceButton
+	if button.GpioValuePath == "" {
+		return false
+	}
+	contents, err := os.ReadFile(button.GpioValuePath)
+	if err != nil {
+		return wasPressed
+	}
+	isPressed := (strings.TrimSpace(string(contents)) == "1") != button.ActiveLow
+	if isPressed && !wasPressed {
+		radio.setMaintenanceMode(
+			!radio.isInMaintenance(),
+			"enabled using the maintenance button",
+			maintenanceSourceButton,
+		)
+	}
+	return isPressed
+}
@@ -0,0 +1,92 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRadio_SetMaintenanceMode(t *testing.T) {
+	radio := &Radio{}
+	assert.False(t, radio.GetMaintenanceStatus().IsEnabled)
+
+	assert.Nil(t, radio.SetMaintenanceMode(true, "flashing firmware"))
+	maintenance := radio.GetMaintenanceStatus()
+	assert.True(t, maintenance.IsEnabled)
+	assert.Equal(t, "flashing firmware", maintenance.Reason)
+	assert.Equal(t, maintenanceSourceApi, maintenance.Source)
+	assert.False(t, maintenance.EnabledAt.Wallclock.IsZero())
+	if alerts := radio.GetAlerts(); assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "MAINTENANCE_MODE", alerts[0].Type)
+		assert.Equal(t, "Maintenance mode enabled via API: flashing firmware", alerts[0].Message)
+	}
+
+	// Disabling clears the rest of the state.
+	assert.Nil(t, radio.SetMaintenanceMode(false, ""))
+	assert.Equal(t, MaintenanceStatus{}, radio.GetMaintenanceStatus())
+	if alerts := radio.GetAlerts(); assert.Equal(t, 2, len(alerts)) {
+		assert.Equal(t, "Maintenance mode disabled via API.", alerts[1].Message)
+	}
+
+	// No alert is raised if the state doesn't change.
+	assert.Nil(t, radio.SetMaintenanceMode(false, ""))
+	assert.Equal(t, 2, len(radio.GetAlerts()))
+
+	// A default reason is filled in if none is given.
+	assert.Nil(t, radio.SetMaintenanceMode(true, ""))
+	assert.Equal(t, "maintenance in progress", radio.GetMaintenanceStatus().Reason)
+
+	err := radio.SetMaintenanceMode(true, strings.Repeat("a", maxMaintenanceReasonLength+1))
+	assert.EqualError(t, err, "invalid reason: 201 characters long (expecting at most 200)")
+	assert.Equal(t, "maintenance in progress", radio.GetMaintenanceStatus().Reason)
+}
+
+func TestRadio_pollMaintenanceButton(t *testing.T) {
+	radio := &Radio{settings: defaultSettings()}
+	gpioValuePath := filepath.Join(t.TempDir(), "value")
+	setButton := func(value string) {
+		assert.Nil(t, os.WriteFile(gpioValuePath, []byte(value+"\n"), 0644))
+	}
+
+	// The button is ignored if it isn't configured.
+	setButton("1")
+	assert.False(t, radio.pollMaintenanceButton(false))
+	assert.False(t, radio.isInMaintenance())
+
+	settings := radio.GetSettings()
+	settings.MaintenanceButton.GpioValuePath = gpioValuePath
+	radio.SetSettings(settings)
+
+	// Pressing the button enables maintenance mode, and holding it down doesn't toggle it again.
+	assert.True(t, radio.pollMaintenanceButton(false))
+	maintenance := radio.GetMaintenanceStatus()
+	assert.True(t, maintenance.IsEnabled)
+	assert.Equal(t, maintenanceSourceButton, maintenance.Source)
+	assert.Equal(t, "enabled using the maintenance button", maintenance.Reason)
+	assert.True(t, radio.pollMaintenanceButton(true))
+	assert.True(t, radio.isInMaintenance())
+
+	// Releasing and pressing it again disables maintenance mode.
+	setButton("0")
+	assert.False(t, radio.pollMaintenanceButton(true))
+	assert.True(t, radio.isInMaintenance())
+	setButton("1")
+	assert.True(t, radio.pollMaintenanceButton(false))
+	assert.False(t, radio.isInMaintenance())
+
+	// An active-low button reads as pressed when the GPIO is 0.
+	settings.MaintenanceButton.ActiveLow = true
+	radio.SetSettings(settings)
+	assert.False(t, radio.pollMaintenanceButton(false))
+	setButton("0")
+	assert.True(t, radio.pollMaintenanceButton(false))
+	assert.True(t, radio.isInMaintenance())
+
+	// The previous state is kept if the GPIO can't be read.
+	assert.Nil(t, os.Remove(gpioValuePath))
+	assert.True(t, radio.pollMaintenanceButton(true))
+	assert.False(t, radio.pollMaintenanceButton(false))
+	assert.True(t, radio.isInMaintenance())
+}
@@ -0,0 +1,150 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRadio_readManagementFrameProtection(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeTree.valuesForGet["wireless.@wifi-iface[1].ieee80211w"] = "2"
+	fakeTree.valuesForGet["wireless.@wifi-iface[2].ieee80211w"] = "1"
+	fakeTree.valuesForGet["wireless.@wifi-iface[3].ieee80211w"] = "0"
+
+	radio := Radio{Type: TypeVividHosting}
+	assert.Equal(
+		t,
+		map[string]managementFrameProtection{
+			"red1":  managementFrameProtectionRequired,
+			"red2":  managementFrameProtectionOptional,
+			"red3":  managementFrameProtectionDisabled,
+			"blue1": managementFrameProtectionDisabled,
+			"blue2": managementFrameProtectionDisabled,
+			"blue3": managementFrameProtectionDisabled,
+		},
+		radio.readManagementFrameProtection(),
+	)
+
+	radio.Type = TypeLinksys
+	assert.Equal(t, map[string]managementFrameProtection{}, radio.readManagementFrameProtection())
+}
+
+func TestConfigurationRequest_validateManagementFrameProtection(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	radio := Radio{Type: TypeVividHosting}
+	for _, position := range []string{"1", "2", "3", "4", "5", "6"} {
+		fakeTree.valuesForGet["wireless.@wifi-iface["+position+"].encryption"] = "psk2+ccmp"
+	}
+
+	for _, mode := range []managementFrameProtection{"REQUIRED", "OPTIONAL", "DISABLED"} {
+		assert.Nil(t, ConfigurationRequest{ManagementFrameProtection: mode}.validateManagementFrameProtection(&radio))
+	}
+	assert.Nil(t, ConfigurationRequest{}.validateManagementFrameProtection(&radio))
+	assert.EqualError(
+		t,
+		ConfigurationRequest{ManagementFrameProtection: "MAYBE"}.validateManagementFrameProtection(&radio),
+		"invalid management frame protection for station red1: MAYBE",
+	)
+
+	// A station-specific mode takes precedence over the device-wide one.
+	request := ConfigurationRequest{
+		ManagementFrameProtection: "REQUIRED",
+		StationConfigurations: map[string]*StationConfiguration{
+			"blue2": {Ssid: "254", WpaKey: "12345678", ManagementFrameProtection: "SOMETIMES"},
+		},
+	}
+	assert.EqualError(
+		t,
+		request.validateManagementFrameProtection(&radio),
+		"invalid management frame protection for station blue2: SOMETIMES",
+	)
+
+	// WPA3-SAE transition mode can't have protection disabled.
+	fakeTree.valuesForGet["wireless.@wifi-iface[2].sae"] = "1"
+	assert.EqualError(
+		t,
+		ConfigurationRequest{ManagementFrameProtection: "DISABLED"}.validateManagementFrameProtection(&radio),
+		"management frame protection cannot be DISABLED for station red2 since it allows WPA3-SAE",
+	)
+	assert.Nil(t, ConfigurationRequest{ManagementFrameProtection: "OPTIONAL"}.validateManagementFrameProtection(&radio))
+
+	// WPA3-SAE alone requires protection.
+	fakeTree.valuesForGet["wireless.@wifi-iface[3].encryption"] = "sae"
+	assert.EqualError(
+		t,
+		ConfigurationRequest{ManagementFrameProtection: "OPTIONAL"}.validateManagementFrameProtection(&radio),
+		"management frame protection must be REQUIRED for station red3 since it only allows WPA3-SAE",
+	)
+	assert.Nil(t, ConfigurationRequest{ManagementFrameProtection: "REQUIRED"}.validateManagementFrameProtection(&radio))
+
+	// Protection can't be used without CCMP.
+	fakeTree.valuesForGet["wireless.@wifi-iface[4].encryption"] = "psk+tkip"
+	assert.EqualError(
+		t,
+		ConfigurationRequest{ManagementFrameProtection: "REQUIRED"}.validateManagementFrameProtection(&radio),
+		"management frame protection requires WPA2 or later with CCMP but station blue1 uses encryption \"psk+tkip\"",
+	)
+
+	// The hardware must support protection.
+	radio.Type = TypeLinksys
+	assert.EqualError(
+		t,
+		ConfigurationRequest{ManagementFrameProtection: "DISABLED"}.validateManagementFrameProtection(&radio),
+		"management frame protection cannot be changed on TypeLinksys",
+	)
+}
+
+func TestRadio_handleConfigurationRequestManagementFrameProtection(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeTree.valuesForGet["system.@system[0].model"] = "VH-109(AP)"
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	wifiReloadBackoffDuration = 10 * time.Millisecond
+	fakeShell.commandOutput["cat /etc/vh_firmware"] = ""
+	fakeTree.valuesForGet["wireless.@wifi-iface[1].ieee80211w"] = "1"
+	for _, iface := range []string{"ath1", "ath11", "ath12", "ath13", "ath14", "ath15"} {
+		fakeShell.commandOutput["iwinfo "+iface+" info"] = iface + "\nESSID: \"no-team-1\"\n"
+	}
+	radio := NewRadio()
+	radio.setInitialState()
+	assert.Equal(t, managementFrameProtectionOptional, radio.ManagementFrameProtection["red1"])
+	assert.Equal(t, managementFrameProtectionDisabled, radio.ManagementFrameProtection["red2"])
+
+	fakeShell.commandOutput["wifi reload wifi1"] = ""
+	fakeShell.commandOutput["iwinfo ath13 info"] = "ath13\nESSID: \"1234\"\n"
+	request := ConfigurationRequest{
+		ManagementFrameProtection: "REQUIRED",
+		StationConfigurations: map[string]*StationConfiguration{
+			"blue1": {Ssid: "1234", WpaKey: "12345678", ManagementFrameProtection: "OPTIONAL"},
+		},
+	}
+	assert.Nil(t, radio.handleConfigurationRequest(request))
+	for _, position := range []string{"1", "2", "3", "5", "6"} {
+		assert.Equal(t, "2", fakeTree.valuesFromSet["wireless.@wifi-iface["+position+"].ieee80211w"])
+	}
+	assert.Equal(t, "1", fakeTree.valuesFromSet["wireless.@wifi-iface[4].ieee80211w"])
+	assert.Equal(t, managementFrameProtectionRequired, radio.ManagementFrameProtection["red1"])
+	assert.Equal(t, managementFrameProtectionOptional, radio.ManagementFrameProtection["blue1"])
+	assert.Equal(t, managementFrameProtection(""), radio.commonManagementFrameProtection())
+
+	// Requests that omit the option leave the stations untouched.
+	fakeTree.valuesFromSet = make(map[string]string)
+	request = ConfigurationRequest{
+		Channel:               5,
+		StationConfigurations: map[string]*StationConfiguration{"blue1": {Ssid: "1234", WpaKey: "12345678"}},
+	}
+	assert.Nil(t, radio.handleConfigurationRequest(request))
+	for _, position := range []string{"1", "4"} {
+		_, ok := fakeTree.valuesFromSet["wireless.@wifi-iface["+position+"].ieee80211w"]
+		assert.False(t, ok)
+	}
+	assert.Equal(t, managementFrameProtectionRequired, radio.ManagementFrameProtection["red1"])
+	assert.Equal(t, managementFrameProtectionOptional, radio.ManagementFrameProtection["blue1"])
+}
@@ -0,0 +1,437 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/digineo/go-uci"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// UCI network interface through which the access point is managed.
+	managementUciInterface = "lan"
+
+	// How long to wait for a management network change to be confirmed if no timeout is specified.
+	defaultManagementRollbackTimeoutSec = 60
+
+	// Shortest and longest times allowed for a management network change to be confirmed before it is reverted.
+	minManagementRollbackTimeoutSec = 10
+	maxManagementRollbackTimeoutSec = 600
+)
+
+// Path to the file in which the addressing in effect before an unconfirmed management network change is kept, so that
+// it can be restored if the API restarts before the change is confirmed; variable to facilitate testing.
+var managementRollbackFilePath = "/root/frc-radio-api-management-rollback.json"
+
+// managementChangeState represents the progress of a change to the management network addressing.
+type managementChangeState string
+
+const (
+	// The change has been accepted and will be applied on the next monitoring poll.
+	managementChangeStatePending managementChangeState = "PENDING"
+
+	// The change has been applied and will be reverted unless it is confirmed via the new address in time.
+	managementChangeStateAwaitingConfirmation managementChangeState = "AWAITING_CONFIRMATION"
+
+	// The change was confirmed via the new address and is permanent.
+	managementChangeStateConfirmed managementChangeState = "CONFIRMED"
+
+	// The change wasn't confirmed in time and the previous addressing has been restored.
+	managementChangeStateReverted managementChangeState = "REVERTED"
+
+	// The change couldn't be applied or reverted; see the error.
+	managementChangeStateFailed managementChangeState = "FAILED"
+)
+
+// ManagementNetwork represents the addressing through which the access point itself is reached.
+type ManagementNetwork struct {
+	// Static IPv4 address of the access point.
+	IpAddress string `json:"ipAddress"`
+
+	// Netmask of the management subnet in dotted decimal form (e.g. "255.255.255.0").
+	Netmask string `json:"netmask"`
+
+	// Default gateway, or blank if there is none.
+	Gateway string `json:"gateway"`
+
+	// 802.1Q VLAN on which the management traffic is tagged, or 0 if it is untagged.
+	AdminVlan int `json:"adminVlan"`
+}
+
+// ManagementNetworkChangeRequest represents a request to change the management network addressing.
+type ManagementNetworkChangeRequest struct {
+	ManagementNetwork
+
+	// How long to wait for the change to be confirmed via the new address before reverting it, in seconds. Defaults to
+	// 60 if zero.
+	RollbackTimeoutSec int `json:"rollbackTimeoutSec"`
+}
+
+// ManagementNetworkChange represents the progress of the most recent change to the management network addressing.
+type ManagementNetworkChange struct {
+	// Progress of the change.
+	State managementChangeState `json:"state"`
+
+	// Addressing being changed to.
+	Requested ManagementNetwork `json:"requested"`
+
+	// Addressing in effect before the change, which is restored if it isn't confirmed. Blank until the change has been
+	// applied.
+	Previous ManagementNetwork `json:"previous"`
+
+	// How long the change is given to be confirmed before it is reverted, in seconds.
+	RollbackTimeoutSec int `json:"rollbackTimeoutSec"`
+
+	// Time by which the change must be confirmed. Zero unless it is awaiting confirmation.
+	ConfirmBy time.Time `json:"confirmBy"`
+
+	// Description of why the change couldn't be applied or reverted, or an empty string if it could.
+	Error string `json:"error"`
+}
+
+// ManagementNetworkStatus represents the management network addressing and any change to it in progress.
+type ManagementNetworkStatus struct {
+	// Addressing currently configured on the access point.
+	Current ManagementNetwork `json:"current"`
+
+	// Most recent change to the addressing. Null if none has been requested since the API started.
+	Change *ManagementNetworkChange `json:"change"`
+}
+
+// Validate checks that the management network addressing is well-formed and consistent.
+func (network ManagementNetwork) Validate() error {
+	ipAddress := net.ParseIP(network.IpAddress).To4()
+	if ipAddress == nil {
+		return fmt.Errorf("invalid ipAddress: %q (expecting an IPv4 address)", network.IpAddress)
+	}
+	netmaskAddress := net.ParseIP(network.Netmask).To4()
+	if netmaskAddress == nil {
+		return fmt.Errorf("invalid netmask: %q (expecting an IPv4 netmask)", network.Netmask)
+	}
+	netmask := net.IPMask(netmaskAddress)
+	if ones, bits := netmask.Size(); bits == 0 || ones == 0 || ones > 30 {
+		return fmt.Errorf("invalid netmask: %q (expecting a contiguous netmask of /1 to /30)", network.Netmask)
+	}
+	subnet := net.IPNet{IP: ipAddress.Mask(netmask), Mask: netmask}
+	if ipAddress.Equal(subnet.IP) || ipAddress.Equal(broadcastAddress(subnet)) {
+		return fmt.Errorf("invalid ipAddress: %s (expecting a host address within %s)", network.IpAddress, &subnet)
+	}
+	if teamSubnet := overlappingTeamSubnet(subnet); teamSubnet != "" {
+		return fmt.Errorf(
+			"invalid ipAddress: %s (expecting a subnet that doesn't overlap the team subnet %s)",
+			network.IpAddress,
+			teamSubnet,
+		)
+	}
+	if network.Gateway != "" {
+		gateway := net.ParseIP(network.Gateway).To4()
+		if gateway == nil {
+			return fmt.Errorf("invalid gateway: %q (expecting an IPv4 address)", network.Gateway)
+		}
+		if !subnet.Contains(gateway) || gateway.Equal(ipAddress) {
+			return fmt.Errorf(
+				"invalid gateway: %s (expecting another host address within %s)", network.Gateway, &subnet,
+			)
+		}
+	}
+	if network.AdminVlan < 0 || network.AdminVlan > 4094 {
+		return fmt.Errorf("invalid adminVlan: %d (expecting 1-4094, or 0 for untagged)", network.AdminVlan)
+	}
+	return nil
+}
+
+// Validate checks that the requested addressing and rollback timeout are valid.
+func (request ManagementNetworkChangeRequest) Validate() error {
+	if request.RollbackTimeoutSec != 0 && (request.RollbackTimeoutSec < minManagementRollbackTimeoutSec ||
+		request.RollbackTimeoutSec > maxManagementRollbackTimeoutSec) {
+		return fmt.Errorf(
+			"invalid rollbackTimeoutSec: %d (expecting %d-%d, or 0 for the default of %d)",
+			request.RollbackTimeoutSec,
+			minManagementRollbackTimeoutSec,
+			maxManagementRollbackTimeoutSec,
+			defaultManagementRollbackTimeoutSec,
+		)
+	}
+	return request.ManagementNetwork.Validate()
+}
+
+// IsInProgress returns true if the change has yet to be applied or confirmed.
+func (change ManagementNetworkChange) IsInProgress() bool {
+	return change.State == managementChangeStatePending || change.State == managementChangeStateAwaitingConfirmation
+}
+
+// overlappingTeamSubnet returns the first of the 10.TE.AM.0/24 team subnets that overlaps the given IPv4 subnet, or an
+// empty string if none does.
+func overlappingTeamSubnet(subnet net.IPNet) string {
+	teamNetwork := net.IPNet{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: net.CIDRMask(8, 32)}
+	if !teamNetwork.Contains(subnet.IP) && !subnet.Contains(teamNetwork.IP) {
+		return ""
+	}
+	ones, _ := subnet.Mask.Size()
+	if ones <= 16 {
+		// The subnet spans at least a whole 10.TE.x.0/16 block, which always includes a team subnet.
+		return fmt.Sprintf("10.%d.1.0/24", subnet.IP[1])
+	}
+	// The upper octets of the team number are fixed, so look for a lower one within the span of the subnet.
+	first, last := int(subnet.IP[2]), int(broadcastAddress(subnet)[2])
+	if subnet.IP[1] == 0 && first == 0 {
+		// There is no team zero.
+		first = 1
+	}
+	if first > last || first > 99 {
+		return ""
+	}
+	return fmt.Sprintf("10.%d.%d.0/24", subnet.IP[1], first)
+}
+
+// broadcastAddress returns the broadcast address of the given IPv4 subnet.
+func broadcastAddress(subnet net.IPNet) net.IP {
+	broadcast := make(net.IP, len(subnet.IP))
+	for i := range subnet.IP {
+		broadcast[i] = subnet.IP[i] | ^subnet.Mask[i]
+	}
+	return broadcast
+}
+
+// GetManagementNetwork returns the management network addressing and the progress of any change to it.
+func (radio *Radio) GetManagementNetwork() ManagementNetworkStatus {
+	radio.managementNetworkMutex.Lock()
+	defer radio.managementNetworkMutex.Unlock()
+	status := radio.ManagementNetwork
+	if status.Change != nil {
+		change := *status.Change
+		status.Change = &change
+	}
+	return status
+}
+
+// RequestManagementNetworkChange validates the given change to the management network addressing and schedules it to
+// be applied on the next monitoring poll. The change is reverted unless it is confirmed via the new address within the
+// requested timeout, so that a mistake can't leave the access point unreachable.
+func (radio *Radio) RequestManagementNetworkChange(request ManagementNetworkChangeRequest) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	if request.RollbackTimeoutSec == 0 {
+		request.RollbackTimeoutSec = defaultManagementRollbackTimeoutSec
+	}
+
+	radio.managementNetworkMutex.Lock()
+	defer radio.managementNetworkMutex.Unlock()
+	if change := radio.ManagementNetwork.Change; change != nil && change.IsInProgress() {
+		return errors.New("a management network change is already in progress")
+	}
+	radio.ManagementNetwork.Change = &ManagementNetworkChange{
+		State:              managementChangeStatePending,
+		Requested:          request.ManagementNetwork,
+		RollbackTimeoutSec: request.RollbackTimeoutSec,
+	}
+	radio.markStatusChanged()
+	return nil
+}
+
+// ConfirmManagementNetworkChange makes the management network change awaiting confirmation permanent. The given
+// address is the one on which the confirmation was received, which must be the new one to prove that the access point
+// is reachable there.
+func (radio *Radio) ConfirmManagementNetworkChange(receivedOnIpAddress string) error {
+	radio.managementNetworkMutex.Lock()
+	defer radio.managementNetworkMutex.Unlock()
+	change := radio.ManagementNetwork.Change
+	if change == nil || change.State != managementChangeStateAwaitingConfirmation {
+		return errors.New("no management network change is awaiting confirmation")
+	}
+	if receivedOnIpAddress != change.Requested.IpAddress {
+		return fmt.Errorf(
+			"confirmation must be sent to the new management address %s, not %s",
+			change.Requested.IpAddress,
+			receivedOnIpAddress,
+		)
+	}
+	change.State = managementChangeStateConfirmed
+	change.ConfirmBy = time.Time{}
+	removeManagementRollback()
+	radio.markStatusChanged()
+	log.Printf("Management network change to %+v confirmed.", change.Requested)
+	return nil
+}
+
+// checkManagementNetwork applies a pending management network change, or reverts one that hasn't been confirmed by
+// its deadline as of the given time.
+func (radio *Radio) checkManagementNetwork(now time.Time) {
+	radio.managementNetworkMutex.Lock()
+	defer radio.managementNetworkMutex.Unlock()
+	status := &radio.ManagementNetwork
+	change := status.Change
+	if change == nil {
+		return
+	}
+
+	switch change.State {
+	case managementChangeStatePending:
+		change.Previous = status.Current
+		if err := saveManagementRollback(change.Previous); err != nil {
+			change.State = managementChangeStateFailed
+			change.Error = fmt.Sprintf("failed to save previous addressing: %v", err)
+			break
+		}
+		if err := radio.writeManagementNetwork(change.Requested); err != nil {
+			change.State = managementChangeStateFailed
+			change.Error = fmt.Sprintf("failed to apply change: %v", err)
+			radio.restoreManagementNetwork(change)
+			break
+		}
+		status.Current = change.Requested
+		change.ConfirmBy = now.Add(time.Duration(change.RollbackTimeoutSec) * time.Second)
+		change.State = managementChangeStateAwaitingConfirmation
+		log.Printf(
+			"Changed management network to %+v; reverting in %d seconds unless confirmed.",
+			change.Requested,
+			change.RollbackTimeoutSec,
+		)
+	case managementChangeStateAwaitingConfirmation:
+		if now.Before(change.ConfirmBy) {
+			return
+		}
+		change.ConfirmBy = time.Time{}
+		change.State = managementChangeStateReverted
+		radio.restoreManagementNetwork(change)
+		radio.raiseAlert(
+			"MANAGEMENT_NETWORK_REVERTED",
+			"Management network change to %s wasn't confirmed within %d seconds; reverted to %s.",
+			change.Requested.IpAddress,
+			change.RollbackTimeoutSec,
+			change.Previous.IpAddress,
+		)
+	default:
+		return
+	}
+	radio.markStatusChanged()
+}
+
+// restoreManagementNetwork restores the addressing in effect before the given change. The mutex must be held.
+func (radio *Radio) restoreManagementNetwork(change *ManagementNetworkChange) {
+	if err := radio.writeManagementNetwork(change.Previous); err != nil {
+		log.Printf("Error restoring management network %+v: %v", change.Previous, err)
+		change.State = managementChangeStateFailed
+		if change.Error == "" {
+			change.Error = fmt.Sprintf("failed to revert change: %v", err)
+		}
+		return
+	}
+	radio.ManagementNetwork.Current = change.Previous
+	removeManagementRollback()
+}
+
+// revertUnconfirmedManagementNetwork restores the addressing in effect before a management network change that the
+// API restarted without confirming, if there is one, so that the access point doesn't keep addressing that may be
+// unreachable.
+func (radio *Radio) revertUnconfirmedManagementNetwork() {
+	previousJson, err := os.ReadFile(managementRollbackFilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	var previous ManagementNetwork
+	if err == nil {
+		err = json.Unmarshal(previousJson, &previous)
+	}
+	if err != nil {
+		log.Printf("Error reading previous management network; leaving the current one in place: %v", err)
+		removeManagementRollback()
+		return
+	}
+
+	radio.managementNetworkMutex.Lock()
+	defer radio.managementNetworkMutex.Unlock()
+	change := &ManagementNetworkChange{
+		State: managementChangeStateReverted, Requested: readManagementNetwork(), Previous: previous,
+	}
+	radio.ManagementNetwork.Change = change
+	radio.restoreManagementNetwork(change)
+	if change.State == managementChangeStateReverted {
+		radio.raiseAlert(
+			"MANAGEMENT_NETWORK_REVERTED",
+			"Management network change to %s wasn't confirmed before the API restarted; reverted to %s.",
+			change.Requested.IpAddress,
+			change.Previous.IpAddress,
+		)
+	}
+}
+
+// saveManagementRollback records the given addressing as the one to restore if the API restarts before the management
+// network change in progress is confirmed.
+func saveManagementRollback(previous ManagementNetwork) error {
+	previousJson, err := json.Marshal(previous)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(managementRollbackFilePath, previousJson, 0600)
+}
+
+// removeManagementRollback discards the addressing recorded by saveManagementRollback once it is no longer needed.
+func removeManagementRollback() {
+	if err := os.Remove(managementRollbackFilePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Error removing %s: %v", managementRollbackFilePath, err)
+	}
+}
+
+// readManagementNetwork returns the management network addressing as configured in UCI.
+func readManagementNetwork() ManagementNetwork {
+	var network ManagementNetwork
+	network.IpAddress, _ = uciTree.GetLast("network", managementUciInterface, "ipaddr")
+	network.Netmask, _ = uciTree.GetLast("network", managementUciInterface, "netmask")
+	network.Gateway, _ = uciTree.GetLast("network", managementUciInterface, "gateway")
+	device, _ := uciTree.GetLast("network", managementUciInterface, "device")
+	_, network.AdminVlan = splitVlanDevice(device)
+	return network
+}
+
+// writeManagementNetwork configures the given management network addressing in UCI and reloads the network.
+func (radio *Radio) writeManagementNetwork(network ManagementNetwork) error {
+	device, _ := uciTree.GetLast("network", managementUciInterface, "device")
+	if device == "" {
+		return fmt.Errorf("network interface %s has no device", managementUciInterface)
+	}
+	baseDevice, _ := splitVlanDevice(device)
+	if network.AdminVlan > 0 {
+		device = fmt.Sprintf("%s.%d", baseDevice, network.AdminVlan)
+	} else {
+		device = baseDevice
+	}
+
+	uciTree.SetType("network", managementUciInterface, "ipaddr", uci.TypeOption, network.IpAddress)
+	uciTree.SetType("network", managementUciInterface, "netmask", uci.TypeOption, network.Netmask)
+	if network.Gateway != "" {
+		uciTree.SetType("network", managementUciInterface, "gateway", uci.TypeOption, network.Gateway)
+	} else {
+		uciTree.Del("network", managementUciInterface, "gateway")
+	}
+	uciTree.SetType("network", managementUciInterface, "device", uci.TypeOption, device)
+	if err := radio.commitUci("network"); err != nil {
+		return fmt.Errorf("failed to commit network configuration: %v", err)
+	}
+	if _, err := shell.runCommand("/etc/init.d/network", "reload"); err != nil {
+		return fmt.Errorf("failed to reload network configuration: %v", err)
+	}
+	return nil
+}
+
+// splitVlanDevice splits the given network device name into its underlying device and the 802.1Q VLAN it is tagged
+// with, which is 0 if it is untagged (e.g. "br-lan.100" into "br-lan" and 100).
+func splitVlanDevice(device string) (string, int) {
+	if i := strings.LastIndex(device, "."); i >= 0 {
+		if vlan, err := strconv.Atoi(device[i+1:]); err == nil && vlan > 0 {
+			return device[:i], vlan
+		}
+	}
+	return device, 0
+}
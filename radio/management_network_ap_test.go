@@ -0,0 +1,266 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestManagementNetwork_Validate(t *testing.T) {
+	network := ManagementNetwork{
+		IpAddress: "10.0.100.2", Netmask: "255.255.255.0", Gateway: "10.0.100.1", AdminVlan: 100,
+	}
+	assert.Nil(t, network.Validate())
+	network.Gateway = ""
+	network.AdminVlan = 0
+	assert.Nil(t, network.Validate())
+
+	network = ManagementNetwork{IpAddress: "10.0.100", Netmask: "255.255.255.0"}
+	assert.EqualError(t, network.Validate(), "invalid ipAddress: \"10.0.100\" (expecting an IPv4 address)")
+	network = ManagementNetwork{IpAddress: "fd00::2", Netmask: "255.255.255.0"}
+	assert.EqualError(t, network.Validate(), "invalid ipAddress: \"fd00::2\" (expecting an IPv4 address)")
+	network = ManagementNetwork{IpAddress: "10.0.100.2", Netmask: "255.0.255.0"}
+	assert.EqualError(
+		t, network.Validate(), "invalid netmask: \"255.0.255.0\" (expecting a contiguous netmask of /1 to /30)",
+	)
+	network = ManagementNetwork{IpAddress: "10.0.100.2", Netmask: "255.255.255.255"}
+	assert.EqualError(
+		t, network.Validate(), "invalid netmask: \"255.255.255.255\" (expecting a contiguous netmask of /1 to /30)",
+	)
+	network = ManagementNetwork{IpAddress: "10.0.100.255", Netmask: "255.255.255.0"}
+	assert.EqualError(
+		t, network.Validate(), "invalid ipAddress: 10.0.100.255 (expecting a host address within 10.0.100.0/24)",
+	)
+	network = ManagementNetwork{IpAddress: "10.0.100.2", Netmask: "255.255.255.0", Gateway: "10.0.101.1"}
+	assert.EqualError(
+		t, network.Validate(), "invalid gateway: 10.0.101.1 (expecting another host address within 10.0.100.0/24)",
+	)
+	network = ManagementNetwork{IpAddress: "10.0.100.2", Netmask: "255.255.255.0", Gateway: "10.0.100.2"}
+	assert.EqualError(
+		t, network.Validate(), "invalid gateway: 10.0.100.2 (expecting another host address within 10.0.100.0/24)",
+	)
+	network = ManagementNetwork{IpAddress: "10.0.100.2", Netmask: "255.255.255.0", AdminVlan: 4095}
+	assert.EqualError(t, network.Validate(), "invalid adminVlan: 4095 (expecting 1-4094, or 0 for untagged)")
+
+	// The management subnet can't overlap any of the team subnets.
+	network = ManagementNetwork{IpAddress: "10.2.54.2", Netmask: "255.255.255.0"}
+	assert.EqualError(
+		t,
+		network.Validate(),
+		"invalid ipAddress: 10.2.54.2 (expecting a subnet that doesn't overlap the team subnet 10.2.54.0/24)",
+	)
+	network = ManagementNetwork{IpAddress: "10.0.100.2", Netmask: "255.255.128.0"}
+	assert.EqualError(
+		t,
+		network.Validate(),
+		"invalid ipAddress: 10.0.100.2 (expecting a subnet that doesn't overlap the team subnet 10.0.1.0/24)",
+	)
+	network = ManagementNetwork{IpAddress: "10.100.100.2", Netmask: "255.0.0.0"}
+	assert.EqualError(
+		t,
+		network.Validate(),
+		"invalid ipAddress: 10.100.100.2 (expecting a subnet that doesn't overlap the team subnet 10.0.1.0/24)",
+	)
+	network = ManagementNetwork{IpAddress: "10.1.99.2", Netmask: "255.255.254.0"}
+	assert.EqualError(
+		t,
+		network.Validate(),
+		"invalid ipAddress: 10.1.99.2 (expecting a subnet that doesn't overlap the team subnet 10.1.98.0/24)",
+	)
+	for _, ipAddress := range []string{"10.0.0.2", "10.0.100.2", "10.25.200.2", "192.168.1.2"} {
+		network = ManagementNetwork{IpAddress: ipAddress, Netmask: "255.255.255.0"}
+		assert.Nil(t, network.Validate(), ipAddress)
+	}
+	network = ManagementNetwork{IpAddress: "10.0.128.2", Netmask: "255.255.128.0"}
+	assert.Nil(t, network.Validate())
+
+	request := ManagementNetworkChangeRequest{
+		ManagementNetwork:  ManagementNetwork{IpAddress: "10.0.100.2", Netmask: "255.255.255.0"},
+		RollbackTimeoutSec: 5,
+	}
+	assert.EqualError(
+		t, request.Validate(), "invalid rollbackTimeoutSec: 5 (expecting 10-600, or 0 for the default of 60)",
+	)
+}
+
+func TestRadio_checkManagementNetwork(t *testing.T) {
+	managementRollbackFilePath = filepath.Join(t.TempDir(), "management-rollback.json")
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	radio := &Radio{}
+	fakeTree.valuesForGet["network.lan.ipaddr"] = "10.0.100.2"
+	fakeTree.valuesForGet["network.lan.netmask"] = "255.255.255.0"
+	fakeTree.valuesForGet["network.lan.device"] = "br-lan.100"
+	radio.ManagementNetwork.Current = readManagementNetwork()
+	original := ManagementNetwork{IpAddress: "10.0.100.2", Netmask: "255.255.255.0", AdminVlan: 100}
+	assert.Equal(t, original, radio.GetManagementNetwork().Current)
+
+	// Nothing happens without a change having been requested.
+	radio.checkManagementNetwork(time.Now())
+	assert.Nil(t, radio.GetManagementNetwork().Change)
+	assert.EqualError(
+		t, radio.ConfirmManagementNetworkChange("10.0.100.3"), "no management network change is awaiting confirmation",
+	)
+
+	requested := ManagementNetwork{
+		IpAddress: "10.0.150.2", Netmask: "255.255.254.0", Gateway: "10.0.150.1", AdminVlan: 150,
+	}
+	assert.Nil(t, radio.RequestManagementNetworkChange(ManagementNetworkChangeRequest{ManagementNetwork: requested}))
+	change := radio.GetManagementNetwork().Change
+	if assert.NotNil(t, change) {
+		assert.Equal(t, managementChangeStatePending, change.State)
+		assert.Equal(t, 60, change.RollbackTimeoutSec)
+	}
+	assert.EqualError(
+		t,
+		radio.RequestManagementNetworkChange(ManagementNetworkChangeRequest{ManagementNetwork: requested}),
+		"a management network change is already in progress",
+	)
+
+	// The change is applied on the next poll.
+	startTime := time.Now()
+	fakeShell.commandOutput["/etc/init.d/network reload"] = ""
+	radio.checkManagementNetwork(startTime)
+	assert.Equal(t, "10.0.150.2", fakeTree.valuesFromSet["network.lan.ipaddr"])
+	assert.Equal(t, "255.255.254.0", fakeTree.valuesFromSet["network.lan.netmask"])
+	assert.Equal(t, "10.0.150.1", fakeTree.valuesFromSet["network.lan.gateway"])
+	assert.Equal(t, "br-lan.150", fakeTree.valuesFromSet["network.lan.device"])
+	assert.Equal(t, 1, fakeTree.commitCount)
+	assert.Contains(t, fakeShell.commandsRun, "/etc/init.d/network reload")
+	status := radio.GetManagementNetwork()
+	assert.Equal(t, requested, status.Current)
+	assert.Equal(t, managementChangeStateAwaitingConfirmation, status.Change.State)
+	assert.Equal(t, original, status.Change.Previous)
+	assert.Equal(t, startTime.Add(time.Minute), status.Change.ConfirmBy)
+	assert.FileExists(t, managementRollbackFilePath)
+
+	// The change is reverted if it isn't confirmed in time.
+	fakeShell.reset()
+	radio.checkManagementNetwork(startTime.Add(59 * time.Second))
+	assert.Empty(t, fakeShell.commandsRun)
+	assert.EqualError(
+		t,
+		radio.ConfirmManagementNetworkChange("10.0.100.2"),
+		"confirmation must be sent to the new management address 10.0.150.2, not 10.0.100.2",
+	)
+	fakeShell.commandOutput["/etc/init.d/network reload"] = ""
+	radio.checkManagementNetwork(startTime.Add(time.Minute))
+	assert.Equal(t, "10.0.100.2", fakeTree.valuesFromSet["network.lan.ipaddr"])
+	assert.Equal(t, "***DELETED***", fakeTree.valuesFromSet["network.lan.gateway"])
+	assert.Equal(t, "br-lan.100", fakeTree.valuesFromSet["network.lan.device"])
+	status = radio.GetManagementNetwork()
+	assert.Equal(t, original, status.Current)
+	assert.Equal(t, managementChangeStateReverted, status.Change.State)
+	assert.True(t, status.Change.ConfirmBy.IsZero())
+	assert.NoFileExists(t, managementRollbackFilePath)
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "MANAGEMENT_NETWORK_REVERTED", alerts[0].Type)
+	}
+
+	// A change confirmed via the new address is kept.
+	requested.AdminVlan = 0
+	assert.Nil(
+		t,
+		radio.RequestManagementNetworkChange(
+			ManagementNetworkChangeRequest{ManagementNetwork: requested, RollbackTimeoutSec: 30},
+		),
+	)
+	radio.checkManagementNetwork(startTime.Add(2 * time.Minute))
+	assert.Equal(t, "br-lan", fakeTree.valuesFromSet["network.lan.device"])
+	assert.FileExists(t, managementRollbackFilePath)
+	assert.Nil(t, radio.ConfirmManagementNetworkChange("10.0.150.2"))
+	assert.NoFileExists(t, managementRollbackFilePath)
+	fakeShell.reset()
+	radio.checkManagementNetwork(startTime.Add(3 * time.Minute))
+	assert.Empty(t, fakeShell.commandsRun)
+	status = radio.GetManagementNetwork()
+	assert.Equal(t, requested, status.Current)
+	assert.Equal(t, managementChangeStateConfirmed, status.Change.State)
+	assert.False(t, status.Change.IsInProgress())
+}
+
+func TestRadio_checkManagementNetworkFailure(t *testing.T) {
+	managementRollbackFilePath = filepath.Join(t.TempDir(), "management-rollback.json")
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	radio := &Radio{}
+	fakeTree.valuesForGet["network.lan.ipaddr"] = "10.0.100.2"
+	fakeTree.valuesForGet["network.lan.netmask"] = "255.255.255.0"
+	radio.ManagementNetwork.Current = readManagementNetwork()
+
+	requested := ManagementNetwork{IpAddress: "10.0.100.3", Netmask: "255.255.255.0"}
+	assert.Nil(t, radio.RequestManagementNetworkChange(ManagementNetworkChangeRequest{ManagementNetwork: requested}))
+	radio.checkManagementNetwork(time.Now())
+	status := radio.GetManagementNetwork()
+	assert.Equal(t, "10.0.100.2", status.Current.IpAddress)
+	assert.Equal(t, managementChangeStateFailed, status.Change.State)
+	assert.Equal(t, "failed to apply change: network interface lan has no device", status.Change.Error)
+	assert.Equal(t, 0, fakeTree.commitCount)
+	// Since the previous addressing couldn't be restored either, it is kept to be restored on the next startup.
+	assert.FileExists(t, managementRollbackFilePath)
+}
+
+func TestRadio_revertUnconfirmedManagementNetwork(t *testing.T) {
+	managementRollbackFilePath = filepath.Join(t.TempDir(), "management-rollback.json")
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	radio := &Radio{}
+
+	// Nothing happens if no change was left unconfirmed.
+	radio.revertUnconfirmedManagementNetwork()
+	assert.Nil(t, radio.GetManagementNetwork().Change)
+	assert.Equal(t, 0, fakeTree.commitCount)
+
+	// A change left unconfirmed by a restart is reverted.
+	fakeTree.valuesForGet["network.lan.ipaddr"] = "10.0.150.2"
+	fakeTree.valuesForGet["network.lan.netmask"] = "255.255.255.0"
+	fakeTree.valuesForGet["network.lan.device"] = "br-lan.150"
+	previous := ManagementNetwork{IpAddress: "10.0.100.2", Netmask: "255.255.255.0", AdminVlan: 100}
+	assert.Nil(t, saveManagementRollback(previous))
+	fakeShell.commandOutput["/etc/init.d/network reload"] = ""
+	radio.revertUnconfirmedManagementNetwork()
+	assert.Equal(t, "10.0.100.2", fakeTree.valuesFromSet["network.lan.ipaddr"])
+	assert.Equal(t, "br-lan.100", fakeTree.valuesFromSet["network.lan.device"])
+	assert.Equal(t, 1, fakeTree.commitCount)
+	status := radio.GetManagementNetwork()
+	assert.Equal(t, previous, status.Current)
+	if assert.NotNil(t, status.Change) {
+		assert.Equal(t, managementChangeStateReverted, status.Change.State)
+		assert.Equal(t, "10.0.150.2", status.Change.Requested.IpAddress)
+	}
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "MANAGEMENT_NETWORK_REVERTED", alerts[0].Type)
+	}
+	assert.NoFileExists(t, managementRollbackFilePath)
+
+	// An unreadable record is discarded without touching the addressing.
+	assert.Nil(t, os.WriteFile(managementRollbackFilePath, []byte("{"), 0600))
+	radio.revertUnconfirmedManagementNetwork()
+	assert.Equal(t, 1, fakeTree.commitCount)
+	assert.NoFileExists(t, managementRollbackFilePath)
+}
+
+func TestSplitVlanDevice(t *testing.T) {
+	device, vlan := splitVlanDevice("br-lan.100")
+	assert.Equal(t, "br-lan", device)
+	assert.Equal(t, 100, vlan)
+	device, vlan = splitVlanDevice("br-lan")
+	assert.Equal(t, "br-lan", device)
+	assert.Equal(t, 0, vlan)
+	device, vlan = splitVlanDevice("eth0.x")
+	assert.Equal(t, "eth0.x", device)
+	assert.Equal(t, 0, vlan)
+}
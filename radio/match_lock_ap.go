@@ -0,0 +1,68 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// How long a match lock is held for if no duration is specified.
+	defaultMatchLockDurationSec = 300
+
+	// Longest duration for which a match lock can be held without being renewed.
+	maxMatchLockDurationSec = 900
+)
+
+// MatchLockStatus represents whether the FMS has signaled that a match is in progress, during which disruptive
+// automatic actions such as channel failover are suppressed.
+type MatchLockStatus struct {
+	// Whether the lock is currently held.
+	IsHeld bool `json:"isHeld"`
+
+	// Time at which the lock expires unless it is renewed or released first. Zero if not held.
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// AcquireMatchLock acquires or renews the match lock for the given number of seconds, or the default duration if zero,
+// and returns the time at which it will expire. The lock expires on its own so that it can't be left held indefinitely
+// if the FMS goes away.
+func (radio *Radio) AcquireMatchLock(durationSec int) (time.Time, error) {
+	if durationSec == 0 {
+		durationSec = defaultMatchLockDurationSec
+	}
+	if durationSec < 0 || durationSec > maxMatchLockDurationSec {
+		return time.Time{}, fmt.Errorf("invalid durationSec: %d (expecting 1-%d)", durationSec, maxMatchLockDurationSec)
+	}
+
+	radio.matchLockMutex.Lock()
+	defer radio.matchLockMutex.Unlock()
+	radio.MatchLock.IsHeld = true
+	radio.MatchLock.ExpiresAt = time.Now().Add(time.Duration(durationSec) * time.Second)
+	radio.markStatusChanged()
+	return radio.MatchLock.ExpiresAt, nil
+}
+
+// ReleaseMatchLock releases the match lock, returning false if it wasn't held.
+func (radio *Radio) ReleaseMatchLock() bool {
+	radio.matchLockMutex.Lock()
+	defer radio.matchLockMutex.Unlock()
+	wasHeld := radio.MatchLock.IsHeld
+	radio.MatchLock = MatchLockStatus{}
+	radio.markStatusChanged()
+	return wasHeld
+}
+
+// isMatchLockHeld returns true if the match lock is held, releasing it first if it has expired.
+func (radio *Radio) isMatchLockHeld() bool {
+	radio.matchLockMutex.Lock()
+	defer radio.matchLockMutex.Unlock()
+	if radio.MatchLock.IsHeld && time.Now().After(radio.MatchLock.ExpiresAt) {
+		log.Println("Match lock expired.")
+		radio.MatchLock = MatchLockStatus{}
+	}
+	return radio.MatchLock.IsHeld
+}
@@ -0,0 +1,52 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRadio_AcquireMatchLock(t *testing.T) {
+	radio := &Radio{}
+	assert.False(t, radio.isMatchLockHeld())
+
+	// Default duration.
+	expiresAt, err := radio.AcquireMatchLock(0)
+	assert.Nil(t, err)
+	assert.True(t, radio.isMatchLockHeld())
+	assert.Equal(t, expiresAt, radio.MatchLock.ExpiresAt)
+	assert.WithinDuration(t, time.Now().Add(defaultMatchLockDurationSec*time.Second), expiresAt, time.Second)
+
+	// Renewal with an explicit duration.
+	expiresAt, err = radio.AcquireMatchLock(30)
+	assert.Nil(t, err)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), expiresAt, time.Second)
+
+	// Invalid durations.
+	_, err = radio.AcquireMatchLock(-1)
+	assert.EqualError(t, err, "invalid durationSec: -1 (expecting 1-900)")
+	_, err = radio.AcquireMatchLock(901)
+	assert.EqualError(t, err, "invalid durationSec: 901 (expecting 1-900)")
+	assert.Equal(t, expiresAt, radio.MatchLock.ExpiresAt)
+}
+
+func TestRadio_ReleaseMatchLock(t *testing.T) {
+	radio := &Radio{}
+	assert.False(t, radio.ReleaseMatchLock())
+
+	_, _ = radio.AcquireMatchLock(60)
+	assert.True(t, radio.ReleaseMatchLock())
+	assert.False(t, radio.isMatchLockHeld())
+	assert.Equal(t, MatchLockStatus{}, radio.MatchLock)
+}
+
+func TestRadio_isMatchLockHeldExpiry(t *testing.T) {
+	radio := &Radio{}
+	_, _ = radio.AcquireMatchLock(60)
+	radio.MatchLock.ExpiresAt = time.Now().Add(-time.Second)
+	assert.False(t, radio.isMatchLockHeld())
+	assert.Equal(t, MatchLockStatus{}, radio.MatchLock)
+}
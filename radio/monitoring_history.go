@@ -0,0 +1,100 @@
+package radio
+
+import "sync"
+
+// Maximum number of monitoring samples to retain; older ones are discarded first.
+const maxMonitoringSamples = 360
+
+// MonitoringSample represents the link telemetry of each network as of one monitoring poll.
+type MonitoringSample struct {
+	// Time at which the poll finished.
+	MonitoredAt Timestamp `json:"monitoredAt"`
+
+	// Telemetry of each network with a team configured, keyed by the name of the team station on the access point or by
+	// "networkStatus6" and "networkStatus24" on the robot radio.
+	Networks map[string]NetworkSample `json:"networks"`
+}
+
+// NetworkSample represents the link telemetry of a single network as of one monitoring poll.
+type NetworkSample struct {
+	// SSID for the network.
+	Ssid string `json:"ssid"`
+
+	// Whether the network was associated with a remote device.
+	IsLinked bool `json:"isLinked"`
+
+	// Signal strength of the link to the remote device, in decibel-milliwatts.
+	SignalDbm int `json:"signalDbm"`
+
+	// Noise level of the link to the remote device, in decibel-milliwatts.
+	NoiseDbm int `json:"noiseDbm"`
+
+	// Signal-to-noise ratio (SNR) in decibels.
+	SignalNoiseRatio int `json:"signalNoiseRatio"`
+
+	// Upper-bound link receive rate in megabits per second.
+	RxRateMbps float64 `json:"rxRateMbps"`
+
+	// Upper-bound link transmit rate in megabits per second.
+	TxRateMbps float64 `json:"txRateMbps"`
+
+	// Five-second average total (rx + tx) bandwidth in megabits per second.
+	BandwidthUsedMbps float64 `json:"bandwidthUsedMbps"`
+
+	// Retried transmissions as a percentage of packets transmitted. Only tracked on the access point.
+	TxRetryRatePercent float64 `json:"txRetryRatePercent"`
+
+	// Overall connection quality from 0 to 100. Only tracked on the access point.
+	QualityScore int `json:"qualityScore"`
+}
+
+// monitoringHistory holds the most recent monitoring samples; it is shared between the radio and web goroutines.
+type monitoringHistory struct {
+	mutex   sync.Mutex
+	samples []MonitoringSample
+}
+
+// recordMonitoringSample adds the current link telemetry of each network to the history, discarding the oldest sample
+// if the history is full.
+func (radio *Radio) recordMonitoringSample() {
+	sample := MonitoringSample{MonitoredAt: radio.MonitoredAt, Networks: make(map[string]NetworkSample)}
+	for name, status := range radio.monitoredNetworks() {
+		sample.Networks[name] = NetworkSample{
+			Ssid:               status.Ssid,
+			IsLinked:           status.IsLinked,
+			SignalDbm:          status.SignalDbm,
+			NoiseDbm:           status.NoiseDbm,
+			SignalNoiseRatio:   status.SignalNoiseRatio,
+			RxRateMbps:         status.RxRateMbps,
+			TxRateMbps:         status.TxRateMbps,
+			BandwidthUsedMbps:  status.BandwidthUsedMbps,
+			TxRetryRatePercent: status.TxRetryRatePercent,
+			QualityScore:       status.QualityScore,
+		}
+	}
+
+	radio.publishEvent(eventTypeMonitoringSample, sample)
+	radio.pushTelemetry(sample)
+
+	history := &radio.monitoringHistory
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+	history.samples = append(history.samples, sample)
+	if len(history.samples) > maxMonitoringSamples {
+		history.samples = history.samples[len(history.samples)-maxMonitoringSamples:]
+	}
+}
+
+// GetMonitoringHistory returns the retained monitoring samples taken after the given monotonic timestamp, oldest first.
+func (radio *Radio) GetMonitoringHistory(afterMonotonicNs int64) []MonitoringSample {
+	history := &radio.monitoringHistory
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+	samples := make([]MonitoringSample, 0, len(history.samples))
+	for _, sample := range history.samples {
+		if sample.MonitoredAt.MonotonicNs > afterMonotonicNs {
+			samples = append(samples, sample)
+		}
+	}
+	return samples
+}
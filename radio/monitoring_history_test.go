@@ -0,0 +1,30 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRadio_recordMonitoringSample(t *testing.T) {
+	radio := &Radio{}
+	assert.Empty(t, radio.GetMonitoringHistory(0))
+
+	for i := 1; i <= maxMonitoringSamples+5; i++ {
+		radio.MonitoredAt = Timestamp{MonotonicNs: int64(i)}
+		radio.recordMonitoringSample()
+	}
+
+	// The oldest samples are discarded once the history is full.
+	samples := radio.GetMonitoringHistory(0)
+	if assert.Equal(t, maxMonitoringSamples, len(samples)) {
+		assert.Equal(t, int64(6), samples[0].MonitoredAt.MonotonicNs)
+		assert.Equal(t, int64(maxMonitoringSamples+5), samples[len(samples)-1].MonitoredAt.MonotonicNs)
+	}
+
+	// Only samples taken after the given timestamp are returned.
+	samples = radio.GetMonitoringHistory(maxMonitoringSamples + 3)
+	if assert.Equal(t, 2, len(samples)) {
+		assert.Equal(t, int64(maxMonitoringSamples+4), samples[0].MonitoredAt.MonotonicNs)
+	}
+	assert.Empty(t, radio.GetMonitoringHistory(maxMonitoringSamples+5))
+}
@@ -0,0 +1,135 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// How long a neighbor network must go unseen before it is forgotten.
+	neighborNetworkExpiry = 10 * time.Minute
+
+	// Spacing between consecutive channel numbers in all bands, in MHz.
+	channelSpacingMhz = 5
+
+	// Width of a single channel, assumed for neighbors whose scan results don't report one.
+	baseChannelWidthMhz = 20
+)
+
+// NeighborNetwork represents a foreign network seen in the background scans, for gauging how much the venue's Wi-Fi is
+// encroaching on the field.
+type NeighborNetwork struct {
+	// SSID broadcast by the neighbor network.
+	Ssid string `json:"ssid"`
+
+	// MAC address of the neighbor access point.
+	Bssid string `json:"bssid"`
+
+	// Primary channel the neighbor network was most recently seen on.
+	Channel int `json:"channel"`
+
+	// Signal strength of the neighbor network in the most recent scan that saw it, in decibel-milliwatts.
+	SignalDbm int `json:"signalDbm"`
+
+	// Channel width that the neighbor network advertises, in MHz, or zero if its scan results didn't report one.
+	WidthMhz int `json:"widthMhz"`
+
+	// Whether the neighbor network's primary channel is the field channel.
+	IsCoChannel bool `json:"isCoChannel"`
+
+	// Whether the neighbor network is on a channel next to the field channel, or is wide enough that it may overlap the
+	// field channel from a nearby primary channel.
+	IsAdjacentChannel bool `json:"isAdjacentChannel"`
+
+	// Time at which the neighbor network was first seen.
+	FirstSeen time.Time `json:"firstSeen"`
+
+	// Time at which the neighbor network was most recently seen.
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// updateNeighborNetworks records the networks from the given scan results in the neighbor table and forgets those that
+// haven't been seen recently. Must be called with the survey mutex held.
+func (radio *Radio) updateNeighborNetworks(bsss []neighborBss) {
+	if radio.survey.neighborNetworks == nil {
+		radio.survey.neighborNetworks = make(map[string]*NeighborNetwork)
+	}
+
+	now := time.Now()
+	for _, bss := range bsss {
+		neighbor, ok := radio.survey.neighborNetworks[bss.bssid]
+		if !ok {
+			neighbor = &NeighborNetwork{Bssid: bss.bssid, FirstSeen: now}
+			radio.survey.neighborNetworks[bss.bssid] = neighbor
+		}
+		neighbor.Ssid = bss.ssid
+		neighbor.Channel = bss.channel
+		neighbor.SignalDbm = bss.signalDbm
+		neighbor.WidthMhz = bss.widthMhz
+		neighbor.LastSeen = now
+	}
+
+	for bssid, neighbor := range radio.survey.neighborNetworks {
+		if now.Sub(neighbor.LastSeen) > neighborNetworkExpiry {
+			delete(radio.survey.neighborNetworks, bssid)
+		}
+	}
+}
+
+// GetNeighborNetworks returns the foreign networks seen recently, with those on the field channel first, then those
+// adjacent to it, and otherwise strongest first.
+func (radio *Radio) GetNeighborNetworks() []NeighborNetwork {
+	radio.survey.mutex.Lock()
+	defer radio.survey.mutex.Unlock()
+	return radio.neighborNetworksOnChannel(0)
+}
+
+// neighborNetworksOnChannel returns a sorted snapshot of the neighbor table, flagged relative to the current field
+// channel and limited to the given primary channel unless it is zero. Must be called with the survey mutex held.
+func (radio *Radio) neighborNetworksOnChannel(channel int) []NeighborNetwork {
+	neighbors := make([]NeighborNetwork, 0, len(radio.survey.neighborNetworks))
+	for _, neighbor := range radio.survey.neighborNetworks {
+		if channel != 0 && neighbor.Channel != channel {
+			continue
+		}
+		flagged := *neighbor
+		flagged.IsCoChannel, flagged.IsAdjacentChannel = classifyNeighborChannel(
+			radio.Channel, neighbor.Channel, neighbor.WidthMhz,
+		)
+		neighbors = append(neighbors, flagged)
+	}
+	sort.Slice(neighbors, func(i, j int) bool {
+		if neighbors[i].IsCoChannel != neighbors[j].IsCoChannel {
+			return neighbors[i].IsCoChannel
+		}
+		if neighbors[i].IsAdjacentChannel != neighbors[j].IsAdjacentChannel {
+			return neighbors[i].IsAdjacentChannel
+		}
+		if neighbors[i].SignalDbm != neighbors[j].SignalDbm {
+			return neighbors[i].SignalDbm > neighbors[j].SignalDbm
+		}
+		return neighbors[i].Bssid < neighbors[j].Bssid
+	})
+	return neighbors
+}
+
+// classifyNeighborChannel returns whether a neighbor network with the given primary channel and width is co-channel
+// with or adjacent to the given field channel. A neighbor counts as adjacent if its primary channel is within one
+// channel width of the field channel (which in the 2.4GHz band covers the overlapping channels) or within its own
+// advertised width, since its bonded channels may then cover the field channel.
+func classifyNeighborChannel(fieldChannel, neighborChannel, neighborWidthMhz int) (bool, bool) {
+	if fieldChannel == 0 || neighborChannel == 0 {
+		return false, false
+	}
+	distanceMhz := channelSpacingMhz * (neighborChannel - fieldChannel)
+	if distanceMhz < 0 {
+		distanceMhz = -distanceMhz
+	}
+	if distanceMhz == 0 {
+		return true, false
+	}
+	return false, distanceMhz <= baseChannelWidthMhz || distanceMhz < neighborWidthMhz
+}
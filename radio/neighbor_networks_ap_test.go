@@ -0,0 +1,77 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRadio_updateNeighborNetworks(t *testing.T) {
+	radio := Radio{Channel: 36}
+	assert.Equal(t, []NeighborNetwork{}, radio.GetNeighborNetworks())
+
+	bsss := []neighborBss{
+		{ssid: "VenueWiFi", bssid: "00:11:22:33:44:55", channel: 149, signalDbm: -45, widthMhz: 80},
+		{ssid: "Concessions", bssid: "00:11:22:33:44:66", channel: 40, signalDbm: -70, widthMhz: 20},
+		{ssid: "Press", bssid: "00:11:22:33:44:77", channel: 36, signalDbm: -80},
+		{ssid: "Stage", bssid: "00:11:22:33:44:88", channel: 48, signalDbm: -60, widthMhz: 80},
+	}
+	radio.updateNeighborNetworks(bsss)
+	neighbors := radio.GetNeighborNetworks()
+	if assert.Equal(t, 4, len(neighbors)) {
+		assert.Equal(t, "Press", neighbors[0].Ssid)
+		assert.True(t, neighbors[0].IsCoChannel)
+		assert.False(t, neighbors[0].IsAdjacentChannel)
+		assert.Equal(t, "Stage", neighbors[1].Ssid)
+		assert.True(t, neighbors[1].IsAdjacentChannel)
+		assert.Equal(t, "Concessions", neighbors[2].Ssid)
+		assert.True(t, neighbors[2].IsAdjacentChannel)
+		assert.Equal(t, "VenueWiFi", neighbors[3].Ssid)
+		assert.False(t, neighbors[3].IsCoChannel)
+		assert.False(t, neighbors[3].IsAdjacentChannel)
+		assert.Equal(t, 80, neighbors[3].WidthMhz)
+	}
+
+	// The flags follow the field channel when it changes.
+	radio.Channel = 149
+	neighbors = radio.GetNeighborNetworks()
+	if assert.Equal(t, 4, len(neighbors)) {
+		assert.Equal(t, "VenueWiFi", neighbors[0].Ssid)
+		assert.True(t, neighbors[0].IsCoChannel)
+	}
+
+	// Seeing a network again updates it in place.
+	firstSeen := radio.survey.neighborNetworks["00:11:22:33:44:55"].FirstSeen
+	bsss[0].signalDbm = -50
+	radio.updateNeighborNetworks(bsss[:1])
+	neighbor := radio.survey.neighborNetworks["00:11:22:33:44:55"]
+	assert.Equal(t, -50, neighbor.SignalDbm)
+	assert.Equal(t, firstSeen, neighbor.FirstSeen)
+
+	// Networks that haven't been seen for a while are forgotten.
+	radio.survey.neighborNetworks["00:11:22:33:44:66"].LastSeen = time.Now().Add(-neighborNetworkExpiry - time.Second)
+	radio.updateNeighborNetworks(nil)
+	assert.Equal(t, 3, len(radio.GetNeighborNetworks()))
+}
+
+func TestClassifyNeighborChannel(t *testing.T) {
+	check := func(fieldChannel, neighborChannel, widthMhz int, expectedCoChannel, expectedAdjacent bool) {
+		isCoChannel, isAdjacent := classifyNeighborChannel(fieldChannel, neighborChannel, widthMhz)
+		assert.Equal(t, expectedCoChannel, isCoChannel, "%d vs. %d", fieldChannel, neighborChannel)
+		assert.Equal(t, expectedAdjacent, isAdjacent, "%d vs. %d", fieldChannel, neighborChannel)
+	}
+	check(36, 36, 20, true, false)
+	check(36, 40, 0, false, true)
+	check(44, 36, 20, false, false)
+	check(44, 36, 40, false, false)
+	check(48, 36, 80, false, true)
+	check(52, 36, 80, false, false)
+	check(60, 36, 160, false, true)
+	check(6, 2, 20, false, true)
+	check(6, 1, 20, false, false)
+	check(0, 36, 20, false, false)
+	check(36, 0, 20, false, false)
+}
@@ -5,6 +5,7 @@ import (
 	"math"
 	"regexp"
 	"strconv"
+	"time"
 )
 
 const (
@@ -15,6 +16,21 @@ const (
 	connectionQualityExcellentMinimum = 412.9
 	connectionQualityGoodMinimum      = 309.7
 	connectionQualityCautionMinimum   = 172.1
+
+	// Maximum age of the data for an associated device for it to be considered linked.
+	maxLinkedDataAgeMs = 4000
+
+	// Minimum age of the data for an associated device for it to be considered a ghost (i.e. a stale association for
+	// a device that is no longer present).
+	minGhostClientDataAgeMs = 30000
+)
+
+var (
+	assocListEntryRe = regexp.MustCompile(
+		"((?:[0-9A-F]{2}:){5}(?:[0-9A-F]{2}))\\s+(-\\d+) dBm / (-\\d+) dBm \\(SNR (\\d+)\\)\\s+(\\d+) ms ago",
+	)
+	assocListRxRe = regexp.MustCompile("RX:\\s+(\\d+\\.\\d+)\\s+MBit/s\\s+(\\d+) Pkts.")
+	assocListTxRe = regexp.MustCompile("TX:\\s+(\\d+\\.\\d+)\\s+MBit/s\\s+(\\d+) Pkts.")
 )
 
 // NetworkStatus encapsulates the status of a single Wi-Fi interface on the device (i.e. a team SSID network on the
@@ -67,19 +83,116 @@ type NetworkStatus struct {
 	// Number of bytes transmitted to the remote device. Zero if not associated.
 	TxBytes int `json:"txBytes"`
 
+	// Physical layer parameters negotiated for receiving from the remote device. Null if not associated.
+	RxPhy *PhyParameters `json:"rxPhy"`
+
+	// Physical layer parameters negotiated for transmitting to the remote device. Null if not associated.
+	TxPhy *PhyParameters `json:"txPhy"`
+
+	// Capabilities advertised by the remote device when it associated. Null if not associated. Only tracked on the
+	// access point.
+	ClientCapabilities *ClientCapabilities `json:"clientCapabilities"`
+
 	// Current five-second average total (rx + tx) bandwidth in megabits per second.
 	BandwidthUsedMbps float64 `json:"bandwidthUsedMbps"`
 
 	// Human-readable string describing connection quality to the remote device. Based on RX rate. Blank if not associated.
 	ConnectionQuality string `json:"connectionQuality"`
 
+	// Cumulative number of transmissions to the remote device that had to be retried, according to the driver. Zero if
+	// not associated. Only tracked on the access point.
+	TxRetries int `json:"txRetries"`
+
+	// Cumulative number of transmissions to the remote device that failed after all retries. Zero if not associated.
+	// Only tracked on the access point.
+	TxFailed int `json:"txFailed"`
+
+	// Cumulative number of packets from the remote device that were dropped. Zero if not associated. Only tracked on
+	// the access point.
+	RxDropped int `json:"rxDropped"`
+
+	// Retried transmissions as a percentage of packets transmitted since the rates were last assessed. Only tracked on
+	// the access point.
+	TxRetryRatePercent float64 `json:"txRetryRatePercent"`
+
+	// Failed transmissions as a percentage of packets transmitted since the rates were last assessed. Only tracked on
+	// the access point.
+	TxFailureRatePercent float64 `json:"txFailureRatePercent"`
+
+	// Dropped packets as a percentage of packets received since the rates were last assessed. Only tracked on the
+	// access point.
+	RxDropRatePercent float64 `json:"rxDropRatePercent"`
+
+	// Whether the retry rate exceeds the configured threshold, indicating that retries are adding latency even if the
+	// throughput looks fine. Only tracked on the access point.
+	HasHighRetryRate bool `json:"hasHighRetryRate"`
+
+	// Number of times the link to a remote device has dropped in the last ten minutes. Only tracked on the access point.
+	RecentLinkDropCount int `json:"recentLinkDropCount"`
+
+	// Total time a remote device has been associated since the team was configured on the station, in seconds. Only
+	// tracked on the access point.
+	AssociatedTimeSec int `json:"associatedTimeSec"`
+
+	// Number of times the associated device has disconnected or been replaced by another since the team was configured
+	// on the station. Only tracked on the access point.
+	DisconnectCount int `json:"disconnectCount"`
+
+	// Longest period without an associated device since one first connected after the team was configured on the
+	// station, including any ongoing one, in seconds. Only tracked on the access point.
+	LongestGapSec int `json:"longestGapSec"`
+
+	// Number of times a client has connected to the network in the last minute, as logged by hostapd. Only tracked on
+	// the access point.
+	ConnectionsPerMin int `json:"connectionsPerMin"`
+
+	// Whether clients are connecting more often than the configured threshold, indicating a reconnection loop that
+	// disrupts the whole channel. Only tracked on the access point.
+	IsAssociationStorm bool `json:"isAssociationStorm"`
+
+	// Overall connection quality from 0 (unusable) to 100 (ideal), weighing the signal-to-noise ratio, retry rate,
+	// bandwidth headroom, and association stability. Zero if not associated. Only tracked on the access point.
+	QualityScore int `json:"qualityScore"`
+
+	// Number of failed authentication or key handshake attempts (e.g. due to a wrong WPA key) since the network was
+	// configured. Only tracked on the access point.
+	HandshakeFailureCount int `json:"handshakeFailureCount"`
+
+	// Times of the most recent failed authentication or key handshake attempts, oldest first. Only tracked on the
+	// access point.
+	RecentHandshakeFailures []time.Time `json:"recentHandshakeFailures"`
+
+	// MAC addresses of associated devices from which nothing has been heard for long enough that the association is
+	// likely stale.
+	GhostMacAddresses []string `json:"ghostMacAddresses"`
+
+	// Number of ghost associations that have been removed by deauthenticating them since the network was configured.
+	// Only tracked on the access point.
+	GhostClientsRemovedCount int `json:"ghostClientsRemovedCount"`
+
+	// Breakdown of the traffic to and from the team's network by kind, sampled from the connection tracking table. Null
+	// if no team number could be derived from the SSID or the table couldn't be read. Only tracked on the access point.
+	TrafficMix *TrafficMix `json:"trafficMix"`
+
+	// Jitter of the driver station control and status packets exchanged with the team's robot, captured passively
+	// from the team's interface. Null if none were seen in the last 30 seconds. Only tracked on the access point.
+	ControlJitter *ControlJitter `json:"controlJitter"`
+
+	// Devices on the team's network that synchronize their clocks with the access point's time server. Null if the
+	// time server is disabled or no team number could be derived from the SSID. Only tracked on the access point.
+	TimeServerClients *TimeServerClientStatus `json:"timeServerClients"`
+
+	// Hardware-specific fields provided by status enrichers, keyed by the namespace of each enricher. Nil if none
+	// apply.
+	Extensions map[string]map[string]any `json:"extensions"`
+
 	// Flag representing whether the interface is for a robot.
 	IsRobot bool `json:"-"`
 }
 
 // updateMonitoring polls the access point for the current bandwidth usage and link state of the given network interface
-// and updates the in-memory state.
-func (status *NetworkStatus) updateMonitoring(networkInterface string) {
+// and updates the in-memory state, including any extra fields from the given enrichers.
+func (status *NetworkStatus) updateMonitoring(networkInterface string, enrichers []StatusEnricher) {
 	// Update the bandwidth usage.
 	output, err := shell.runCommand("luci-bwc", "-i", networkInterface)
 	if err != nil {
@@ -96,8 +209,12 @@ func (status *NetworkStatus) updateMonitoring(networkInterface string) {
 		status.RxRateMbps = monitoringErrorCode
 		status.TxRateMbps = monitoringErrorCode
 		status.SignalNoiseRatio = monitoringErrorCode
+		status.RxPhy = nil
+		status.TxPhy = nil
 	} else {
 		status.parseAssocList(output)
+		status.updatePhyParameters(output)
+		status.enrich(networkInterface, output, enrichers)
 	}
 
 	// Update the number of bytes received and transmitted.
@@ -131,12 +248,6 @@ func (status *NetworkStatus) parseBandwidthUsed(response string) {
 // parseAssocList parses the given data from the radio's association list and updates the status structure with the
 // result.
 func (status *NetworkStatus) parseAssocList(response string) {
-	line1Re := regexp.MustCompile(
-		"((?:[0-9A-F]{2}:){5}(?:[0-9A-F]{2}))\\s+(-\\d+) dBm / (-\\d+) dBm \\(SNR (\\d+)\\)\\s+(\\d+) ms ago",
-	)
-	line2Re := regexp.MustCompile("RX:\\s+(\\d+\\.\\d+)\\s+MBit/s\\s+(\\d+) Pkts.")
-	line3R3 := regexp.MustCompile("TX:\\s+(\\d+\\.\\d+)\\s+MBit/s\\s+(\\d+) Pkts.")
-
 	status.IsLinked = false
 	status.MacAddress = ""
 	status.SignalDbm = 0
@@ -147,16 +258,24 @@ func (status *NetworkStatus) parseAssocList(response string) {
 	status.TxRateMbps = 0
 	status.TxPackets = 0
 	status.ConnectionQuality = ""
-	for _, line1Match := range line1Re.FindAllStringSubmatch(response, -1) {
+	status.GhostMacAddresses = nil
+	for _, line1Match := range assocListEntryRe.FindAllStringSubmatch(response, -1) {
 		macAddress := line1Match[1]
 		dataAgeMs, _ := strconv.Atoi(line1Match[5])
-		if macAddress != "00:00:00:00:00:00" && dataAgeMs <= 4000 {
+		if macAddress == "00:00:00:00:00:00" {
+			continue
+		}
+		if dataAgeMs >= minGhostClientDataAgeMs {
+			status.GhostMacAddresses = append(status.GhostMacAddresses, macAddress)
+			continue
+		}
+		if dataAgeMs <= maxLinkedDataAgeMs && !status.IsLinked {
 			status.IsLinked = true
 			status.MacAddress = macAddress
 			status.SignalDbm, _ = strconv.Atoi(line1Match[2])
 			status.NoiseDbm, _ = strconv.Atoi(line1Match[3])
 			status.SignalNoiseRatio, _ = strconv.Atoi(line1Match[4])
-			line2Match := line2Re.FindStringSubmatch(response)
+			line2Match := assocListRxRe.FindStringSubmatch(response)
 			if len(line2Match) > 0 {
 				status.RxRateMbps, _ = strconv.ParseFloat(line2Match[1], 64)
 				status.RxPackets, _ = strconv.Atoi(line2Match[2])
@@ -164,7 +283,7 @@ func (status *NetworkStatus) parseAssocList(response string) {
 					status.determineConnectionQuality(status.RxRateMbps)
 				}
 			}
-			line3Match := line3R3.FindStringSubmatch(response)
+			line3Match := assocListTxRe.FindStringSubmatch(response)
 			if len(line3Match) > 0 {
 				status.TxRateMbps, _ = strconv.ParseFloat(line3Match[1], 64)
 				status.TxPackets, _ = strconv.Atoi(line3Match[2])
@@ -172,7 +291,6 @@ func (status *NetworkStatus) parseAssocList(response string) {
 					status.determineConnectionQuality(status.TxRateMbps)
 				}
 			}
-			break
 		}
 	}
 }
@@ -101,6 +101,20 @@ func TestNetworkStatus_ParseAssocList(t *testing.T) {
 		"\texpected throughput: unknown"
 	status.parseAssocList(response)
 	assert.Equal(t, NetworkStatus{}, status)
+
+	// Ghost associations are reported alongside a valid link.
+	response = "37:DA:35:B0:00:BE  -64 dBm / -84 dBm (SNR 7)  30000 ms ago\n" +
+		"\tRX: 6.0 MBit/s                                     12 Pkts.\n" +
+		"\tTX: 6.0 MBit/s                                      3 Pkts.\n" +
+		"\texpected throughput: unknown\n" +
+		"48:DA:35:B0:00:CF  -53 dBm / -95 dBm (SNR 42)  0 ms ago\n" +
+		"\tRX: 550.6 MBit/s                                4095 Pkts.\n" +
+		"\tTX: 254.0 MBit/s                                 123 Pkts.\n" +
+		"\texpected throughput: unknown"
+	status.parseAssocList(response)
+	assert.True(t, status.IsLinked)
+	assert.Equal(t, "48:DA:35:B0:00:CF", status.MacAddress)
+	assert.Equal(t, []string{"37:DA:35:B0:00:BE"}, status.GhostMacAddresses)
 }
 
 func TestNetworkStatus_ParseIfconfig(t *testing.T) {
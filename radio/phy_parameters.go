@@ -0,0 +1,50 @@
+package radio
+
+// PhyParameters represents the physical layer parameters negotiated for one direction of the link with the remote
+// device, which reveal a degraded link (e.g. a narrower channel or fewer spatial streams than expected) even when the
+// signal strength looks fine.
+type PhyParameters struct {
+	// 802.11 generation of the rate in use: "LEGACY" (pre-802.11n), "HT" (802.11n), "VHT" (802.11ac), or "HE"
+	// (802.11ax).
+	Mode string `json:"mode"`
+
+	// Channel bandwidth actually in use, in megahertz. Zero if not reported, as is the case for legacy rates.
+	ChannelWidthMhz int `json:"channelWidthMhz"`
+
+	// Modulation and coding scheme index. Zero for legacy rates.
+	Mcs int `json:"mcs"`
+
+	// Number of spatial streams. Zero for legacy rates.
+	Nss int `json:"nss"`
+
+	// Guard interval in use, in nanoseconds.
+	GuardIntervalNs int `json:"guardIntervalNs"`
+
+	// Whether the short guard interval is in use.
+	IsShortGi bool `json:"isShortGi"`
+}
+
+// updatePhyParameters parses the negotiated physical layer parameters of the link with the associated device from the
+// given data from the radio's association list. They are left nil if no device is linked or they aren't reported.
+func (status *NetworkStatus) updatePhyParameters(assocList string) {
+	status.RxPhy = nil
+	status.TxPhy = nil
+	if !status.IsLinked {
+		return
+	}
+	for direction, rate := range parseAssocListRates(assocListEntry(assocList, status.MacAddress)) {
+		phy := &PhyParameters{
+			Mode:            rate.mode,
+			ChannelWidthMhz: rate.channelWidthMhz,
+			Mcs:             rate.mcs,
+			Nss:             rate.nss,
+			GuardIntervalNs: rate.guardIntervalNs,
+			IsShortGi:       rate.guardIntervalNs == 400,
+		}
+		if direction == "rx" {
+			status.RxPhy = phy
+		} else {
+			status.TxPhy = phy
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNetworkStatus_updatePhyParameters(t *testing.T) {
+	var status NetworkStatus
+
+	// Only the linked device is considered, even if a stale association is listed first.
+	status.parseAssocList(enrichedAssocList)
+	status.updatePhyParameters(enrichedAssocList)
+	assert.Equal(
+		t, &PhyParameters{Mode: "HE", ChannelWidthMhz: 80, Mcs: 11, Nss: 2, GuardIntervalNs: 800}, status.RxPhy,
+	)
+	assert.Equal(t, &PhyParameters{Mode: "LEGACY", GuardIntervalNs: 800}, status.TxPhy)
+
+	// A robot radio that fell back to a narrow channel and a single stream shows up as such.
+	response := "48:DA:35:B0:00:CF  -53 dBm / -95 dBm (SNR 42)  0 ms ago\n" +
+		"\tRX: 72.2 MBit/s, MCS 7, 20MHz, short GI                      4095 Pkts.\n" +
+		"\tTX: 433.3 MBit/s, VHT-MCS 9, 80MHz, VHT-NSS 1, short GI      123 Pkts.\n" +
+		"\texpected throughput: unknown"
+	status.parseAssocList(response)
+	status.updatePhyParameters(response)
+	assert.Equal(
+		t,
+		&PhyParameters{Mode: "HT", ChannelWidthMhz: 20, Mcs: 7, Nss: 1, GuardIntervalNs: 400, IsShortGi: true},
+		status.RxPhy,
+	)
+	assert.Equal(
+		t,
+		&PhyParameters{Mode: "VHT", ChannelWidthMhz: 80, Mcs: 9, Nss: 1, GuardIntervalNs: 400, IsShortGi: true},
+		status.TxPhy,
+	)
+
+	// No device is linked.
+	status.parseAssocList("")
+	status.updatePhyParameters("")
+	assert.Nil(t, status.RxPhy)
+	assert.Nil(t, status.TxPhy)
+}
@@ -0,0 +1,127 @@
+package radio
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Highest brownout drop that may be configured, as a percentage of the nominal input voltage.
+const maxBrownoutDropPercent = 50
+
+// Pattern matching the sysfs directories through which the power supplies of the radio report whether they are
+// supplying it and at what voltage, where the hardware exposes them (e.g. on Vivid-Hosting radios).
+var powerSupplyGlob = "/sys/class/power_supply/*"
+
+// PowerStatus represents the power input of the radio, for telling marginal PoE injectors apart from firmware problems
+// as the cause of intermittent reboots.
+type PowerStatus struct {
+	// Name of the power supply that the radio is being powered from (e.g. "poe" or "dc"), as reported by the hardware.
+	Source string `json:"source"`
+
+	// Input voltage as of the most recent monitoring poll, in volts.
+	VoltageV float64 `json:"voltageV"`
+
+	// Highest input voltage seen from the current source since the API started, taken as its nominal voltage.
+	NominalVoltageV float64 `json:"nominalVoltageV"`
+
+	// Lowest input voltage seen from the current source since the API started, in volts.
+	MinVoltageV float64 `json:"minVoltageV"`
+
+	// Whether the input voltage has fallen too far below the nominal voltage without having recovered since.
+	IsBrownout bool `json:"isBrownout"`
+
+	// Number of times the input voltage has fallen too far below the nominal voltage since the API started.
+	BrownoutCount int `json:"brownoutCount"`
+
+	// Time at which the most recent brownout began. Zero if there has been none.
+	LastBrownoutAt Timestamp `json:"lastBrownoutAt"`
+}
+
+// readPowerSupply returns the name and voltage in volts of the power supply that is currently powering the radio, or
+// false if the hardware doesn't report one. Where several report being online, the first in name order is used.
+func readPowerSupply() (string, float64, bool) {
+	paths, _ := filepath.Glob(powerSupplyGlob)
+	sort.Strings(paths)
+	for _, path := range paths {
+		online, err := os.ReadFile(filepath.Join(path, "online"))
+		if err != nil || strings.TrimSpace(string(online)) != "1" {
+			continue
+		}
+		contents, err := os.ReadFile(filepath.Join(path, "voltage_now"))
+		if err != nil {
+			continue
+		}
+		microvolts, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+		if err != nil {
+			continue
+		}
+		return filepath.Base(path), float64(microvolts) / 1e6, true
+	}
+	return "", 0, false
+}
+
+// updatePowerStatus polls the power input of the radio, raising an alert when its source changes and when its voltage
+// falls far enough below the nominal voltage to count as a brownout.
+func (radio *Radio) updatePowerStatus() {
+	source, voltage, ok := readPowerSupply()
+	if !ok {
+		return
+	}
+	if radio.Power == nil {
+		radio.Power = &PowerStatus{Source: source, NominalVoltageV: voltage, MinVoltageV: voltage}
+	} else if radio.Power.Source != source {
+		radio.raiseAlert(
+			"POWER_SOURCE_CHANGED",
+			"Radio power source changed from %s to %s at %.2fV.",
+			radio.Power.Source,
+			source,
+			voltage,
+		)
+		// The new source has a nominal voltage of its own, but the brownout history carries over.
+		radio.Power = &PowerStatus{
+			Source:          source,
+			NominalVoltageV: voltage,
+			MinVoltageV:     voltage,
+			BrownoutCount:   radio.Power.BrownoutCount,
+			LastBrownoutAt:  radio.Power.LastBrownoutAt,
+		}
+	}
+	power := radio.Power
+	power.VoltageV = voltage
+	if voltage > power.NominalVoltageV {
+		power.NominalVoltageV = voltage
+	}
+	if voltage < power.MinVoltageV {
+		power.MinVoltageV = voltage
+	}
+
+	dropPercent := radio.GetSettings().BrownoutDropPercent
+	if dropPercent == 0 {
+		power.IsBrownout = false
+		return
+	}
+	threshold := power.NominalVoltageV * (1 - dropPercent/100)
+	if !power.IsBrownout && voltage < threshold {
+		power.IsBrownout = true
+		power.BrownoutCount++
+		power.LastBrownoutAt = newTimestamp()
+		radio.raiseAlert(
+			"BROWNOUT",
+			"Radio input voltage from %s fell to %.2fV, more than %.0f%% below its nominal %.2fV; check the PoE "+
+				"injector and cabling.",
+			source,
+			voltage,
+			dropPercent,
+			power.NominalVoltageV,
+		)
+	} else if power.IsBrownout && voltage >= power.NominalVoltageV*(1-dropPercent/200) {
+		// The voltage must recover to within half the drop so that a voltage hovering around the threshold doesn't
+		// raise an alert at every poll.
+		power.IsBrownout = false
+		log.Printf("Radio input voltage from %s has recovered to %.2fV.", source, voltage)
+	}
+}
@@ -0,0 +1,84 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRadio_updatePowerStatus(t *testing.T) {
+	directory := t.TempDir()
+	originalGlob := powerSupplyGlob
+	t.Cleanup(func() { powerSupplyGlob = originalGlob })
+	powerSupplyGlob = filepath.Join(directory, "*")
+	setPowerSupply := func(name string, online string, microvolts string) {
+		assert.Nil(t, os.MkdirAll(filepath.Join(directory, name), 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(directory, name, "online"), []byte(online), 0644))
+		assert.Nil(t, os.WriteFile(filepath.Join(directory, name, "voltage_now"), []byte(microvolts), 0644))
+	}
+	var radio Radio
+	radio.SetSettings(defaultSettings())
+
+	// Nothing is reported if the hardware doesn't expose an online power supply.
+	radio.updatePowerStatus()
+	setPowerSupply("dc", "0\n", "0\n")
+	setPowerSupply("poe", "1\n", "garbage\n")
+	radio.updatePowerStatus()
+	assert.Nil(t, radio.Power)
+
+	setPowerSupply("poe", "1\n", "48120000\n")
+	radio.updatePowerStatus()
+	assert.Equal(
+		t, &PowerStatus{Source: "poe", VoltageV: 48.12, NominalVoltageV: 48.12, MinVoltageV: 48.12}, radio.Power,
+	)
+
+	// A small sag isn't a brownout.
+	setPowerSupply("poe", "1\n", "44000000\n")
+	radio.updatePowerStatus()
+	assert.False(t, radio.Power.IsBrownout)
+	assert.Equal(t, 44.0, radio.Power.MinVoltageV)
+	assert.Empty(t, radio.GetAlerts())
+
+	setPowerSupply("poe", "1\n", "41500000\n")
+	radio.updatePowerStatus()
+	assert.True(t, radio.Power.IsBrownout)
+	assert.Equal(t, 1, radio.Power.BrownoutCount)
+	assert.False(t, radio.Power.LastBrownoutAt.Wallclock.IsZero())
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "BROWNOUT", alerts[0].Type)
+		assert.Equal(
+			t,
+			"Radio input voltage from poe fell to 41.50V, more than 10% below its nominal 48.12V; check the PoE "+
+				"injector and cabling.",
+			alerts[0].Message,
+		)
+	}
+
+	// The alert isn't repeated until the voltage has recovered to within half the drop.
+	setPowerSupply("poe", "1\n", "44000000\n")
+	radio.updatePowerStatus()
+	setPowerSupply("poe", "1\n", "41500000\n")
+	radio.updatePowerStatus()
+	assert.Equal(t, 1, radio.Power.BrownoutCount)
+	setPowerSupply("poe", "1\n", "47000000\n")
+	radio.updatePowerStatus()
+	assert.False(t, radio.Power.IsBrownout)
+	setPowerSupply("poe", "1\n", "41500000\n")
+	radio.updatePowerStatus()
+	assert.Equal(t, 2, radio.Power.BrownoutCount)
+
+	// A change of source starts over from the new source's voltage but keeps the brownout history.
+	setPowerSupply("dc", "1\n", "12050000\n")
+	radio.updatePowerStatus()
+	assert.Equal(t, "dc", radio.Power.Source)
+	assert.Equal(t, 12.05, radio.Power.NominalVoltageV)
+	assert.False(t, radio.Power.IsBrownout)
+	assert.Equal(t, 2, radio.Power.BrownoutCount)
+	alerts = radio.GetAlerts()
+	if assert.Equal(t, 3, len(alerts)) {
+		assert.Equal(t, "POWER_SOURCE_CHANGED", alerts[2].Type)
+		assert.Equal(t, "Radio power source changed from poe to dc at 12.05V.", alerts[2].Message)
+	}
+}
@@ -0,0 +1,160 @@
+package radio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+)
+
+// Path to the file on flash in which the outcome of the first-boot provisioning is kept, so that the radio only has to
+// be provisioned once; variable to facilitate testing.
+var provisioningFilePath = "/root/frc-radio-api-provisioning.json"
+
+// ProvisioningRequest represents the one-time setup of a freshly flashed radio, submitted via POST /provision. The
+// admin password is handled by the web server, since it owns the secrets.
+type ProvisioningRequest struct {
+	// Password to require for the API from now on.
+	Password string `json:"password"`
+
+	// Management IP address of the radio once it is in service. Blank leaves the current address in place. Only
+	// supported on the access point.
+	ManagementIpAddress string `json:"managementIpAddress"`
+
+	// Code of the event at which the radio is deployed, used for the {{.EventCode}} template variable unless the
+	// settings file sets one.
+	EventCode string `json:"eventCode"`
+
+	// Hardware type that the radio is expected to be (e.g. "TypeVividHosting"), confirming that the right image was
+	// flashed onto the right hardware.
+	HardwareType string `json:"hardwareType"`
+}
+
+// ProvisioningStatus represents whether the radio has completed its first-boot provisioning.
+type ProvisioningStatus struct {
+	// Whether the radio has been provisioned.
+	IsProvisioned bool `json:"isProvisioned"`
+
+	// Time at which the radio was provisioned. Zero if it hasn't been.
+	ProvisionedAt Timestamp `json:"provisionedAt"`
+
+	// Event code given when the radio was provisioned.
+	EventCode string `json:"eventCode"`
+
+	// Hardware type confirmed when the radio was provisioned.
+	HardwareType string `json:"hardwareType"`
+
+	// Management IP address requested when the radio was provisioned, or blank if it was left unchanged.
+	ManagementIpAddress string `json:"managementIpAddress"`
+}
+
+// Validate checks that the provisioning request confirms the radio's hardware type and has valid values.
+func (request ProvisioningRequest) Validate(radio *Radio) error {
+	if hardwareType := radio.hardwareProfile(); request.HardwareType != hardwareType {
+		return fmt.Errorf(
+			"invalid hardwareType: %q (expecting %q, the type of this radio)", request.HardwareType, hardwareType,
+		)
+	}
+	if !eventCodeRe.MatchString(request.EventCode) {
+		return fmt.Errorf("invalid eventCode: %q (expecting up to 16 letters and digits)", request.EventCode)
+	}
+	return radio.validateProvisionedManagementIp(request.ManagementIpAddress)
+}
+
+// GetProvisioningStatus returns a snapshot of the first-boot provisioning state.
+func (radio *Radio) GetProvisioningStatus() ProvisioningStatus {
+	radio.provisioningMutex.Lock()
+	defer radio.provisioningMutex.Unlock()
+	return radio.Provisioning
+}
+
+// IsAwaitingProvisioning returns true if the settings require the radio to be provisioned and it hasn't been yet, in
+// which case the API serves nothing but POST /provision.
+func (radio *Radio) IsAwaitingProvisioning() bool {
+	return radio.GetSettings().RequireProvisioning && !radio.GetProvisioningStatus().IsProvisioned
+}
+
+// GetEventVariables returns the event variables that configuration templates are resolved against: those from the
+// settings, with the event code given at provisioning filling in for one that the settings don't set.
+func (radio *Radio) GetEventVariables() EventVariables {
+	variables := radio.GetSettings().EventVariables
+	if variables.EventCode == "" {
+		variables.EventCode = radio.GetProvisioningStatus().EventCode
+	}
+	return variables
+}
+
+// Provision completes the first-boot provisioning with the given request, which must already have been validated,
+// recording it on flash and releasing the radio to start normal operation.
+func (radio *Radio) Provision(request ProvisioningRequest) error {
+	radio.provisioningMutex.Lock()
+	defer radio.provisioningMutex.Unlock()
+	if radio.Provisioning.IsProvisioned {
+		return errors.New("radio has already been provisioned")
+	}
+	if err := radio.requestProvisionedManagementIp(request.ManagementIpAddress); err != nil {
+		return err
+	}
+
+	provisioning := ProvisioningStatus{
+		IsProvisioned:       true,
+		ProvisionedAt:       newTimestamp(),
+		EventCode:           request.EventCode,
+		HardwareType:        request.HardwareType,
+		ManagementIpAddress: request.ManagementIpAddress,
+	}
+	provisioningJson, err := json.MarshalIndent(provisioning, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(provisioningFilePath, provisioningJson, 0600); err != nil {
+		return fmt.Errorf("failed to save provisioning state: %v", err)
+	}
+	radio.Provisioning = provisioning
+	radio.markStatusChanged()
+	log.Printf("Radio provisioned: %+v", provisioning)
+
+	select {
+	case radio.provisionedChannel <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// loadProvisioningStatus reads the outcome of the first-boot provisioning from flash, if the radio has been
+// provisioned.
+func (radio *Radio) loadProvisioningStatus() {
+	provisioningJson, err := os.ReadFile(provisioningFilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	var provisioning ProvisioningStatus
+	if err == nil {
+		err = json.Unmarshal(provisioningJson, &provisioning)
+	}
+	if err != nil {
+		log.Printf("Error reading provisioning state; treating the radio as unprovisioned: %v", err)
+		return
+	}
+	radio.provisioningMutex.Lock()
+	defer radio.provisioningMutex.Unlock()
+	radio.Provisioning = provisioning
+}
+
+// waitForProvisioning blocks in the PROVISIONING status until the radio has been provisioned, if the settings require
+// it.
+func (radio *Radio) waitForProvisioning() {
+	if !radio.IsAwaitingProvisioning() {
+		return
+	}
+	log.Println("Radio hasn't been provisioned; waiting for POST /provision.")
+	radio.setStatus(statusProvisioning)
+	radio.markStatusChanged()
+	for radio.IsAwaitingProvisioning() {
+		<-radio.provisionedChannel
+	}
+	radio.setStatus(statusBooting)
+	radio.markStatusChanged()
+}
@@ -0,0 +1,35 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+// hardwareProfile returns the hardware type that a provisioning request must confirm.
+func (radio *Radio) hardwareProfile() string {
+	return radio.Type.String()
+}
+
+// validateProvisionedManagementIp checks that the management network would remain valid with the given IP address
+// requested at provisioning, if any.
+func (radio *Radio) validateProvisionedManagementIp(ipAddress string) error {
+	if ipAddress == "" {
+		return nil
+	}
+	return provisionedManagementNetworkChange(ipAddress).Validate()
+}
+
+// requestProvisionedManagementIp schedules the change to the given management IP address requested at provisioning,
+// if any. It is subject to confirmation via the new address like any other management network change.
+func (radio *Radio) requestProvisionedManagementIp(ipAddress string) error {
+	if ipAddress == "" {
+		return nil
+	}
+	return radio.RequestManagementNetworkChange(provisionedManagementNetworkChange(ipAddress))
+}
+
+// provisionedManagementNetworkChange returns the change from the configured management network addressing to the
+// given IP address.
+func provisionedManagementNetworkChange(ipAddress string) ManagementNetworkChangeRequest {
+	network := readManagementNetwork()
+	network.IpAddress = ipAddress
+	return ManagementNetworkChangeRequest{ManagementNetwork: network}
+}
@@ -0,0 +1,39 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestRadio_ProvisionManagementIp(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	provisioningFilePath = filepath.Join(t.TempDir(), "provisioning.json")
+	fakeTree.valuesForGet["network.lan.ipaddr"] = "10.0.100.2"
+	fakeTree.valuesForGet["network.lan.netmask"] = "255.255.255.0"
+	fakeTree.valuesForGet["network.lan.device"] = "br-lan.100"
+	radio := &Radio{Type: TypeVividHosting, settings: defaultSettings()}
+	request := ProvisioningRequest{HardwareType: "TypeVividHosting", ManagementIpAddress: "10.0.100.255"}
+	assert.EqualError(
+		t, request.Validate(radio), "invalid ipAddress: 10.0.100.255 (expecting a host address within 10.0.100.0/24)",
+	)
+
+	// The new address is applied as a management network change, keeping the rest of the addressing.
+	request.ManagementIpAddress = "10.0.100.3"
+	assert.Nil(t, request.Validate(radio))
+	assert.Nil(t, radio.Provision(request))
+	change := radio.GetManagementNetwork().Change
+	if assert.NotNil(t, change) {
+		assert.Equal(t, managementChangeStatePending, change.State)
+		assert.Equal(
+			t,
+			ManagementNetwork{IpAddress: "10.0.100.3", Netmask: "255.255.255.0", AdminVlan: 100},
+			change.Requested,
+		)
+	}
+	assert.Equal(t, "10.0.100.3", radio.GetProvisioningStatus().ManagementIpAddress)
+}
@@ -0,0 +1,26 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package radio
+
+import "errors"
+
+// hardwareProfile returns the hardware type that a provisioning request must confirm.
+func (radio *Radio) hardwareProfile() string {
+	return TypeVividHosting.String()
+}
+
+// validateProvisionedManagementIp rejects a management IP address requested at provisioning, since the robot radio's
+// address is derived from its team number.
+func (radio *Radio) validateProvisionedManagementIp(ipAddress string) error {
+	if ipAddress != "" {
+		return errors.New("invalid managementIpAddress: not supported on the robot radio (expecting blank)")
+	}
+	return nil
+}
+
+// requestProvisionedManagementIp does nothing on the robot radio, which doesn't support changing its management
+// address.
+func (radio *Radio) requestProvisionedManagementIp(ipAddress string) error {
+	return nil
+}
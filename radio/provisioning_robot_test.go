@@ -0,0 +1,20 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestProvisioningRequest_ValidateManagementIp(t *testing.T) {
+	radio := &Radio{settings: defaultSettings()}
+	request := ProvisioningRequest{HardwareType: "TypeVividHosting"}
+	assert.Nil(t, request.Validate(radio))
+
+	request.ManagementIpAddress = "10.0.100.3"
+	assert.EqualError(
+		t, request.Validate(radio), "invalid managementIpAddress: not supported on the robot radio (expecting blank)",
+	)
+}
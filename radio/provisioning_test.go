@@ -0,0 +1,102 @@
+package radio
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProvisioningRequest_Validate(t *testing.T) {
+	radio := &Radio{settings: defaultSettings()}
+	request := ProvisioningRequest{EventCode: "2026casj", HardwareType: radio.hardwareProfile()}
+	assert.Nil(t, request.Validate(radio))
+
+	request.HardwareType = "TypeToaster"
+	assert.EqualError(
+		t,
+		request.Validate(radio),
+		"invalid hardwareType: \"TypeToaster\" (expecting \""+radio.hardwareProfile()+"\", the type of this radio)",
+	)
+
+	request.HardwareType = radio.hardwareProfile()
+	request.EventCode = "2026-casj"
+	assert.EqualError(
+		t, request.Validate(radio), "invalid eventCode: \"2026-casj\" (expecting up to 16 letters and digits)",
+	)
+}
+
+func TestRadio_Provision(t *testing.T) {
+	provisioningFilePath = filepath.Join(t.TempDir(), "provisioning.json")
+	radio := &Radio{settings: defaultSettings(), provisionedChannel: make(chan struct{}, 1)}
+	radio.settings.EventVariables.EventCode = ""
+	assert.False(t, radio.IsAwaitingProvisioning())
+	radio.settings.RequireProvisioning = true
+	assert.True(t, radio.IsAwaitingProvisioning())
+
+	request := ProvisioningRequest{EventCode: "2026casj", HardwareType: radio.hardwareProfile()}
+	assert.Nil(t, radio.Provision(request))
+	assert.False(t, radio.IsAwaitingProvisioning())
+	provisioning := radio.GetProvisioningStatus()
+	assert.True(t, provisioning.IsProvisioned)
+	assert.False(t, provisioning.ProvisionedAt.Wallclock.IsZero())
+	assert.Equal(t, "2026casj", provisioning.EventCode)
+	assert.Equal(t, 1, len(radio.provisionedChannel))
+
+	// The provisioning is stored on flash, where it is picked up after a restart.
+	var storedProvisioning ProvisioningStatus
+	provisioningJson, err := os.ReadFile(provisioningFilePath)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(provisioningJson, &storedProvisioning))
+	assert.Equal(t, "2026casj", storedProvisioning.EventCode)
+	restartedRadio := &Radio{settings: radio.settings}
+	restartedRadio.loadProvisioningStatus()
+	assert.True(t, restartedRadio.GetProvisioningStatus().IsProvisioned)
+	assert.False(t, restartedRadio.IsAwaitingProvisioning())
+
+	// The radio can only be provisioned once.
+	assert.EqualError(t, radio.Provision(request), "radio has already been provisioned")
+
+	// The event code given at provisioning applies unless the settings give one.
+	assert.Equal(t, "2026casj", radio.GetEventVariables().EventCode)
+	radio.settings.EventVariables.EventCode = "2026cmptx"
+	assert.Equal(t, "2026cmptx", radio.GetEventVariables().EventCode)
+}
+
+func TestRadio_loadProvisioningStatusInvalidFile(t *testing.T) {
+	provisioningFilePath = filepath.Join(t.TempDir(), "provisioning.json")
+	radio := &Radio{settings: defaultSettings()}
+	radio.loadProvisioningStatus()
+	assert.False(t, radio.GetProvisioningStatus().IsProvisioned)
+
+	assert.Nil(t, os.WriteFile(provisioningFilePath, []byte("not JSON"), 0600))
+	radio.loadProvisioningStatus()
+	assert.False(t, radio.GetProvisioningStatus().IsProvisioned)
+}
+
+func TestRadio_waitForProvisioning(t *testing.T) {
+	provisioningFilePath = filepath.Join(t.TempDir(), "provisioning.json")
+	radio := &Radio{settings: defaultSettings(), provisionedChannel: make(chan struct{}, 1)}
+	radio.settings.RequireProvisioning = true
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		assert.Nil(t, radio.Provision(ProvisioningRequest{HardwareType: radio.hardwareProfile()}))
+	}()
+
+	// The radio stays in the PROVISIONING status until it is provisioned.
+	radio.waitForProvisioning()
+	assert.False(t, radio.IsAwaitingProvisioning())
+	assert.Equal(t, statusBooting, radio.Status)
+	if assert.Equal(t, 2, len(radio.StatusTransitions)) {
+		assert.Equal(t, statusProvisioning, radio.StatusTransitions[0].To)
+		assert.Equal(t, statusBooting, radio.StatusTransitions[1].To)
+	}
+
+	// A radio that doesn't require provisioning doesn't wait.
+	radio = &Radio{settings: defaultSettings()}
+	radio.waitForProvisioning()
+	assert.Empty(t, radio.StatusTransitions)
+}
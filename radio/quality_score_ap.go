@@ -0,0 +1,69 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import "math"
+
+const (
+	// Signal-to-noise ratios at or below which the signal component of the quality score is zero and at or above which
+	// it is full, in decibels.
+	qualityMinSignalNoiseRatio = 10
+	qualityMaxSignalNoiseRatio = 40
+
+	// Retry rate at or above which the retry component of the quality score is zero, in percent.
+	qualityMaxRetryRatePercent = 50
+
+	// Number of recent link drops at or above which the stability component of the quality score is zero.
+	qualityMaxLinkDrops = 4
+)
+
+// updateQualityScores recomputes the connection quality score of each station from its latest monitoring data and
+// association history.
+func (radio *Radio) updateQualityScores() {
+	for _, stationStatus := range radio.StationStatuses {
+		if stationStatus != nil {
+			stationStatus.QualityScore = qualityScore(stationStatus, radio.GetSettings().QualityScoreWeights)
+		}
+	}
+}
+
+// qualityScore returns the connection quality of the given network from 0 to 100 as the weighted average of its
+// components, each of which is also scored from 0 to 100:
+//   - Signal: scales linearly from a 10 dB signal-to-noise ratio to 40 dB.
+//   - Retries: scales linearly from 50% of transmissions being retried down to none.
+//   - Headroom: the fraction of the link rate (the faster of the receive and transmit rates) left unused by the
+//     bandwidth currently in use.
+//   - Stability: loses a quarter for each link drop in the last ten minutes.
+//
+// Components that couldn't be measured score zero. Returns zero if the network isn't associated.
+func qualityScore(status *NetworkStatus, weights QualityScoreWeights) int {
+	if !status.IsLinked {
+		return 0
+	}
+
+	var signalScore, retryScore, headroomScore float64
+	if status.SignalNoiseRatio != monitoringErrorCode {
+		signalScore = clampedFraction(
+			float64(status.SignalNoiseRatio-qualityMinSignalNoiseRatio),
+			qualityMaxSignalNoiseRatio-qualityMinSignalNoiseRatio,
+		)
+	}
+	if status.TxRetries != monitoringErrorCode {
+		retryScore = 1 - clampedFraction(status.TxRetryRatePercent, qualityMaxRetryRatePercent)
+	}
+	linkRateMbps := math.Max(status.RxRateMbps, status.TxRateMbps)
+	if linkRateMbps > 0 && status.BandwidthUsedMbps >= 0 {
+		headroomScore = 1 - clampedFraction(status.BandwidthUsedMbps, linkRateMbps)
+	}
+	stabilityScore := 1 - clampedFraction(float64(status.RecentLinkDropCount), qualityMaxLinkDrops)
+
+	score := weights.SignalNoiseRatio*signalScore + weights.RetryRate*retryScore +
+		weights.BandwidthHeadroom*headroomScore + weights.AssociationStability*stabilityScore
+	return int(math.Round(100 * score / weights.total()))
+}
+
+// clampedFraction returns the given value as a fraction of the given maximum, limited to the range from 0 to 1.
+func clampedFraction(value, maximum float64) float64 {
+	return math.Min(math.Max(value/maximum, 0), 1)
+}
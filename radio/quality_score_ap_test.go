@@ -0,0 +1,67 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestQualityScore(t *testing.T) {
+	weights := defaultSettings().QualityScoreWeights
+
+	assert.Equal(t, 0, qualityScore(&NetworkStatus{SignalNoiseRatio: 40}, weights))
+
+	// An ideal link.
+	status := &NetworkStatus{IsLinked: true, SignalNoiseRatio: 45, RxRateMbps: 860.3, TxRateMbps: 6}
+	assert.Equal(t, 100, qualityScore(status, weights))
+
+	// Each component contributes in proportion to its weight.
+	status.SignalNoiseRatio = 25
+	assert.Equal(t, 80, qualityScore(status, weights))
+	status.TxRetryRatePercent = 10
+	assert.Equal(t, 75, qualityScore(status, weights))
+	status.BandwidthUsedMbps = 430.15
+	assert.Equal(t, 68, qualityScore(status, weights))
+	status.RecentLinkDropCount = 1
+	assert.Equal(t, 63, qualityScore(status, weights))
+	status.RecentLinkDropCount = 10
+	assert.Equal(t, 48, qualityScore(status, weights))
+
+	// Only the proportions of the weights matter.
+	assert.Equal(t, 50, qualityScore(status, QualityScoreWeights{SignalNoiseRatio: 1}))
+	assert.Equal(t, 25, qualityScore(status, QualityScoreWeights{SignalNoiseRatio: 1, AssociationStability: 1}))
+
+	// Components that couldn't be measured score zero.
+	status = &NetworkStatus{
+		IsLinked:          true,
+		SignalNoiseRatio:  monitoringErrorCode,
+		TxRetries:         monitoringErrorCode,
+		RxRateMbps:        monitoringErrorCode,
+		TxRateMbps:        monitoringErrorCode,
+		BandwidthUsedMbps: monitoringErrorCode,
+	}
+	assert.Equal(t, 20, qualityScore(status, weights))
+}
+
+func TestRadio_updateQualityScores(t *testing.T) {
+	radio := &Radio{settings: defaultSettings(), StationStatuses: map[string]*NetworkStatus{}}
+	radio.StationStatuses["red1"] = &NetworkStatus{
+		Ssid: "1111", IsLinked: true, MacAddress: "48:DA:35:B0:01:CF", SignalNoiseRatio: 40, RxRateMbps: 860.3,
+	}
+	radio.StationStatuses["red2"] = &NetworkStatus{
+		Ssid:                "2222",
+		IsLinked:            true,
+		MacAddress:          "48:DA:35:B0:01:D0",
+		SignalNoiseRatio:    40,
+		RxRateMbps:          860.3,
+		RecentLinkDropCount: 1,
+	}
+	radio.StationStatuses["blue2"] = &NetworkStatus{Ssid: "5555"}
+
+	radio.updateQualityScores()
+	assert.Equal(t, 100, radio.StationStatuses["red1"].QualityScore)
+	assert.Equal(t, 95, radio.StationStatuses["red2"].QualityScore)
+	assert.Equal(t, 0, radio.StationStatuses["blue2"].QualityScore)
+}
@@ -0,0 +1,190 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+)
+
+// Maximum transmit power that quiet hours can reduce the radio to, in dBm.
+const maxQuietHoursTxPowerDbm = 30
+
+// QuietHoursSchedule represents a daily window, such as overnight in the venue, during which the team networks are
+// taken off the air to reduce the radio's RF footprint and attack surface.
+type QuietHoursSchedule struct {
+	// Whether the schedule is in effect.
+	Enabled bool `json:"enabled"`
+
+	// Local time of day at which quiet hours begin, in 24-hour "HH:MM" format.
+	StartTime string `json:"startTime"`
+
+	// Local time of day at which quiet hours end, in 24-hour "HH:MM" format. May be earlier than the start time for a
+	// window that spans midnight.
+	EndTime string `json:"endTime"`
+
+	// Transmit power to reduce the radio to during quiet hours, in dBm, or zero to leave it unchanged.
+	TxPowerDbm int `json:"txPowerDbm"`
+}
+
+// QuietHoursStatus represents the quiet hours schedule and whether it is currently keeping the team networks down.
+type QuietHoursStatus struct {
+	// Schedule currently in effect.
+	Schedule QuietHoursSchedule `json:"schedule"`
+
+	// Whether the team networks are currently disabled for quiet hours.
+	IsActive bool `json:"isActive"`
+
+	// Time at which quiet hours are next due to begin or end. Zero if the schedule is disabled.
+	NextChangeAt time.Time `json:"nextChangeAt"`
+
+	// Time at which quiet hours last began or ended. Zero if they haven't since the API started.
+	LastChangedAt time.Time `json:"lastChangedAt"`
+}
+
+// Validate checks that the schedule's times of day and transmit power are valid.
+func (schedule QuietHoursSchedule) Validate() error {
+	if !schedule.Enabled && schedule.StartTime == "" && schedule.EndTime == "" {
+		return nil
+	}
+	start, err := parseTimeOfDay(schedule.StartTime)
+	if err != nil {
+		return fmt.Errorf("invalid startTime: %q (expecting HH:MM)", schedule.StartTime)
+	}
+	end, err := parseTimeOfDay(schedule.EndTime)
+	if err != nil {
+		return fmt.Errorf("invalid endTime: %q (expecting HH:MM)", schedule.EndTime)
+	}
+	if start == end {
+		return errors.New("startTime and endTime cannot be the same")
+	}
+	if schedule.TxPowerDbm < 0 || schedule.TxPowerDbm > maxQuietHoursTxPowerDbm {
+		return fmt.Errorf("invalid txPowerDbm: %d (expecting 0-%d)", schedule.TxPowerDbm, maxQuietHoursTxPowerDbm)
+	}
+	return nil
+}
+
+// isQuietAt returns true if the given time falls within the schedule's window. The schedule must be valid.
+func (schedule QuietHoursSchedule) isQuietAt(now time.Time) bool {
+	if !schedule.Enabled {
+		return false
+	}
+	start, _ := parseTimeOfDay(schedule.StartTime)
+	end, _ := parseTimeOfDay(schedule.EndTime)
+	return isWithinTimesOfDay(now, start, end)
+}
+
+// nextChangeAfter returns the next time after the given one at which quiet hours begin or end, or zero if the schedule
+// is disabled. The schedule must be valid.
+func (schedule QuietHoursSchedule) nextChangeAfter(now time.Time) time.Time {
+	if !schedule.Enabled {
+		return time.Time{}
+	}
+	var next time.Time
+	for _, value := range []string{schedule.StartTime, schedule.EndTime} {
+		offset, _ := parseTimeOfDay(value)
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		change := midnight.Add(offset)
+		if !change.After(now) {
+			change = time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location()).Add(offset)
+		}
+		if next.IsZero() || change.Before(next) {
+			next = change
+		}
+	}
+	return next
+}
+
+// GetQuietHours returns the quiet hours schedule and its current state.
+func (radio *Radio) GetQuietHours() QuietHoursStatus {
+	radio.quietHoursMutex.Lock()
+	defer radio.quietHoursMutex.Unlock()
+	return radio.QuietHours
+}
+
+// SetQuietHoursSchedule validates the given quiet hours schedule and puts it into effect on the next monitoring poll.
+func (radio *Radio) SetQuietHoursSchedule(schedule QuietHoursSchedule) error {
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+
+	radio.quietHoursMutex.Lock()
+	defer radio.quietHoursMutex.Unlock()
+	radio.QuietHours.Schedule = schedule
+	radio.QuietHours.NextChangeAt = schedule.nextChangeAfter(time.Now())
+	radio.markStatusChanged()
+	return nil
+}
+
+// checkQuietHours takes the team networks down or brings them back up if quiet hours have begun or ended as of the
+// given time. Quiet hours don't begin while a match is in progress.
+func (radio *Radio) checkQuietHours(now time.Time) {
+	radio.quietHoursMutex.Lock()
+	status := &radio.QuietHours
+	schedule := status.Schedule
+	isActive := status.IsActive
+	status.NextChangeAt = schedule.nextChangeAfter(now)
+	radio.quietHoursMutex.Unlock()
+
+	shouldBeActive := schedule.isQuietAt(now)
+	if shouldBeActive == isActive || shouldBeActive && radio.isMatchLockHeld() {
+		return
+	}
+	if shouldBeActive {
+		radio.enterQuietHours(schedule)
+		radio.raiseAlert(
+			"QUIET_HOURS_STARTED", "Disabled team networks for quiet hours until %s.", schedule.EndTime,
+		)
+	} else {
+		radio.exitQuietHours()
+		radio.raiseAlert("QUIET_HOURS_ENDED", "Re-enabled team networks after quiet hours.")
+	}
+
+	radio.quietHoursMutex.Lock()
+	status.IsActive = shouldBeActive
+	status.LastChangedAt = now
+	radio.quietHoursMutex.Unlock()
+}
+
+// enterQuietHours lowers the transmit power if the given schedule calls for it and stops each team network from
+// broadcasting. The wireless configuration is left untouched so that the networks come back as they were.
+func (radio *Radio) enterQuietHours(schedule QuietHoursSchedule) {
+	if schedule.TxPowerDbm > 0 {
+		// The transmit power applies to the whole device, so it can be set through any of its interfaces.
+		wifiInterface := radio.stationInterfaces[red1]
+		mbm := strconv.Itoa(schedule.TxPowerDbm * 100)
+		if _, err := shell.runCommand("iw", "dev", wifiInterface, "set", "txpower", "fixed", mbm); err != nil {
+			log.Printf("Error lowering transmit power for quiet hours: %v", err)
+		} else {
+			radio.quietHoursTxPowerLowered = true
+		}
+	}
+	radio.setTeamNetworksEnabled(false, "for quiet hours")
+}
+
+// exitQuietHours restarts each team network, unless this is a standby that has yet to take over or an emergency stop is
+// holding them down, and restores the transmit power if it was lowered.
+func (radio *Radio) exitQuietHours() {
+	if !radio.isHoldingStandbyNetworks() && !radio.isEmergencyStopActive() {
+		radio.setTeamNetworksEnabled(true, "after quiet hours")
+	}
+	if radio.quietHoursTxPowerLowered {
+		wifiInterface := radio.stationInterfaces[red1]
+		if _, err := shell.runCommand("iw", "dev", wifiInterface, "set", "txpower", "auto"); err != nil {
+			log.Printf("Error restoring transmit power after quiet hours: %v", err)
+		} else {
+			radio.quietHoursTxPowerLowered = false
+		}
+	}
+}
+
+// isQuietHoursActive returns true if the team networks are currently disabled for quiet hours.
+func (radio *Radio) isQuietHoursActive() bool {
+	radio.quietHoursMutex.Lock()
+	defer radio.quietHoursMutex.Unlock()
+	return radio.QuietHours.IsActive
+}
@@ -0,0 +1,185 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestQuietHoursSchedule_Validate(t *testing.T) {
+	assert.Nil(t, QuietHoursSchedule{}.Validate())
+	assert.Nil(t, QuietHoursSchedule{Enabled: true, StartTime: "22:00", EndTime: "07:30", TxPowerDbm: 5}.Validate())
+	assert.Nil(t, QuietHoursSchedule{StartTime: "22:00", EndTime: "07:30"}.Validate())
+
+	assert.EqualError(
+		t, QuietHoursSchedule{Enabled: true, EndTime: "07:30"}.Validate(), "invalid startTime: \"\" (expecting HH:MM)",
+	)
+	assert.EqualError(
+		t,
+		QuietHoursSchedule{Enabled: true, StartTime: "22:00", EndTime: "7am"}.Validate(),
+		"invalid endTime: \"7am\" (expecting HH:MM)",
+	)
+	assert.EqualError(
+		t,
+		QuietHoursSchedule{Enabled: true, StartTime: "24:00", EndTime: "07:30"}.Validate(),
+		"invalid startTime: \"24:00\" (expecting HH:MM)",
+	)
+	assert.EqualError(
+		t,
+		QuietHoursSchedule{Enabled: true, StartTime: "22:00", EndTime: "22:00"}.Validate(),
+		"startTime and endTime cannot be the same",
+	)
+	assert.EqualError(
+		t,
+		QuietHoursSchedule{Enabled: true, StartTime: "22:00", EndTime: "07:30", TxPowerDbm: 31}.Validate(),
+		"invalid txPowerDbm: 31 (expecting 0-30)",
+	)
+}
+
+func TestQuietHoursSchedule_isQuietAt(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 3, 2, hour, minute, 0, 0, time.Local)
+	}
+
+	overnight := QuietHoursSchedule{Enabled: true, StartTime: "22:00", EndTime: "07:30"}
+	assert.False(t, overnight.isQuietAt(at(21, 59)))
+	assert.True(t, overnight.isQuietAt(at(22, 0)))
+	assert.True(t, overnight.isQuietAt(at(3, 0)))
+	assert.True(t, overnight.isQuietAt(at(7, 29)))
+	assert.False(t, overnight.isQuietAt(at(7, 30)))
+	assert.False(t, overnight.isQuietAt(at(12, 0)))
+
+	midday := QuietHoursSchedule{Enabled: true, StartTime: "12:00", EndTime: "13:00"}
+	assert.False(t, midday.isQuietAt(at(11, 59)))
+	assert.True(t, midday.isQuietAt(at(12, 30)))
+	assert.False(t, midday.isQuietAt(at(13, 0)))
+
+	overnight.Enabled = false
+	assert.False(t, overnight.isQuietAt(at(3, 0)))
+}
+
+func TestQuietHoursSchedule_nextChangeAfter(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 3, day, hour, minute, 0, 0, time.Local)
+	}
+
+	schedule := QuietHoursSchedule{Enabled: true, StartTime: "22:00", EndTime: "07:30"}
+	assert.Equal(t, at(2, 22, 0), schedule.nextChangeAfter(at(2, 12, 0)))
+	assert.Equal(t, at(3, 7, 30), schedule.nextChangeAfter(at(2, 22, 0)))
+	assert.Equal(t, at(2, 7, 30), schedule.nextChangeAfter(at(2, 3, 0)))
+
+	schedule.Enabled = false
+	assert.True(t, schedule.nextChangeAfter(at(2, 12, 0)).IsZero())
+}
+
+func TestRadio_SetQuietHoursSchedule(t *testing.T) {
+	uciTree = newFakeUciTree()
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+
+	schedule := QuietHoursSchedule{Enabled: true, StartTime: "22:00", EndTime: "07:30"}
+	assert.Nil(t, radio.SetQuietHoursSchedule(schedule))
+	status := radio.GetQuietHours()
+	assert.Equal(t, schedule, status.Schedule)
+	assert.False(t, status.IsActive)
+	assert.False(t, status.NextChangeAt.IsZero())
+
+	assert.EqualError(
+		t,
+		radio.SetQuietHoursSchedule(QuietHoursSchedule{Enabled: true, StartTime: "22:00"}),
+		"invalid endTime: \"\" (expecting HH:MM)",
+	)
+	assert.Equal(t, schedule, radio.GetQuietHours().Schedule)
+}
+
+func TestRadio_checkQuietHours(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+	fakeShell.reset()
+	fakeTree.valuesForGet["wireless.@wifi-iface[6].disabled"] = "1"
+	evening := time.Date(2024, 3, 2, 21, 0, 0, 0, time.Local)
+	night := time.Date(2024, 3, 2, 23, 0, 0, 0, time.Local)
+	morning := time.Date(2024, 3, 3, 8, 0, 0, 0, time.Local)
+	expectHostapdCommands := func(action string) {
+		for _, wifiInterface := range []string{"wlan0", "wlan0-1", "wlan0-2", "wlan0-3", "wlan0-4"} {
+			fakeShell.commandOutput["hostapd_cli -i "+wifiInterface+" "+action] = "OK"
+		}
+	}
+
+	// Nothing happens while the schedule is disabled.
+	radio.checkQuietHours(night)
+	assert.Empty(t, fakeShell.commandsRun)
+	assert.False(t, radio.QuietHours.IsActive)
+
+	schedule := QuietHoursSchedule{Enabled: true, StartTime: "22:00", EndTime: "07:30", TxPowerDbm: 5}
+	assert.Nil(t, radio.SetQuietHoursSchedule(schedule))
+	radio.checkQuietHours(evening)
+	assert.Empty(t, fakeShell.commandsRun)
+	assert.Equal(t, time.Date(2024, 3, 2, 22, 0, 0, 0, time.Local), radio.QuietHours.NextChangeAt)
+
+	// Quiet hours are deferred while a match is in progress.
+	_, err := radio.AcquireMatchLock(60)
+	assert.Nil(t, err)
+	radio.checkQuietHours(night)
+	assert.Empty(t, fakeShell.commandsRun)
+	assert.False(t, radio.QuietHours.IsActive)
+	radio.ReleaseMatchLock()
+
+	// Starting quiet hours lowers the power and disables each network that exists, tolerating failures.
+	fakeShell.commandOutput["iw dev wlan0 set txpower fixed 500"] = ""
+	expectHostapdCommands("disable")
+	delete(fakeShell.commandOutput, "hostapd_cli -i wlan0-3 disable")
+	fakeShell.commandErrors["hostapd_cli -i wlan0-3 disable"] = errors.New("oops")
+	radio.checkQuietHours(night)
+	assert.Equal(t, 6, len(fakeShell.commandsRun))
+	assert.Contains(t, fakeShell.commandsRun, "iw dev wlan0 set txpower fixed 500")
+	assert.True(t, radio.QuietHours.IsActive)
+	assert.Equal(t, night, radio.QuietHours.LastChangedAt)
+	assert.Equal(t, time.Date(2024, 3, 3, 7, 30, 0, 0, time.Local), radio.QuietHours.NextChangeAt)
+
+	// Subsequent polls during quiet hours don't repeat the commands.
+	fakeShell.reset()
+	radio.checkQuietHours(night.Add(time.Hour))
+	assert.Empty(t, fakeShell.commandsRun)
+
+	// Ending quiet hours re-enables the networks and restores the power.
+	expectHostapdCommands("enable")
+	fakeShell.commandOutput["iw dev wlan0 set txpower auto"] = ""
+	radio.checkQuietHours(morning)
+	assert.Equal(t, 6, len(fakeShell.commandsRun))
+	assert.Contains(t, fakeShell.commandsRun, "iw dev wlan0 set txpower auto")
+	assert.False(t, radio.QuietHours.IsActive)
+	assert.False(t, radio.quietHoursTxPowerLowered)
+
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 2, len(alerts)) {
+		assert.Equal(t, "QUIET_HOURS_STARTED", alerts[0].Type)
+		assert.Equal(t, "Disabled team networks for quiet hours until 07:30.", alerts[0].Message)
+		assert.Equal(t, "QUIET_HOURS_ENDED", alerts[1].Type)
+	}
+
+	// Disabling the schedule during quiet hours ends them on the next poll.
+	fakeShell.reset()
+	radio.QuietHours.Schedule.TxPowerDbm = 0
+	expectHostapdCommands("disable")
+	radio.checkQuietHours(night)
+	assert.Equal(t, 5, len(fakeShell.commandsRun))
+	assert.True(t, radio.QuietHours.IsActive)
+	assert.Nil(t, radio.SetQuietHoursSchedule(QuietHoursSchedule{}))
+	fakeShell.reset()
+	expectHostapdCommands("enable")
+	radio.checkQuietHours(night)
+	assert.Equal(t, 5, len(fakeShell.commandsRun))
+	assert.False(t, radio.QuietHours.IsActive)
+	assert.True(t, radio.QuietHours.NextChangeAt.IsZero())
+}
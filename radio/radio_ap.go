@@ -9,6 +9,8 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -34,18 +36,128 @@ type Radio struct {
 	// Enum representing the current configuration stage of the radio.
 	Status radioStatus `json:"status"`
 
+	// Time at which the status last changed.
+	StatusChangedAt Timestamp `json:"statusChangedAt"`
+
+	// Most recent changes of the status, oldest first.
+	StatusTransitions []StatusTransition `json:"statusTransitions"`
+
+	// Progress of the configuration request currently being applied, or of the last one applied if none is. Null if
+	// none has been applied since the API started.
+	ConfigurationTimeline *ConfigurationTimeline `json:"configurationTimeline"`
+
+	// Most recent failure to apply a configuration request, or null if the last request to be applied succeeded.
+	LastError *ConfigurationError `json:"lastError"`
+
 	// Map of team station names to their current status.
 	StationStatuses map[string]*NetworkStatus `json:"stationStatuses"`
 
+	// Map of alliance names ("red" and "blue") to a summary of the statuses of their stations.
+	AllianceStatuses map[string]AllianceStatus `json:"allianceStatuses"`
+
 	// IP address of the syslog server to send logs to (via UDP on port 514).
 	SyslogIpAddress string `json:"syslogIpAddress"`
 
 	// Version of the radio software.
 	Version string `json:"version"`
 
+	// Problems found with the layout of the wireless configuration, which prevent the radio from being configured.
+	// Empty unless the status is MISCONFIGURED_BASELINE.
+	BaselineProblems []string `json:"baselineProblems,omitempty"`
+
+	// Options of the wireless configuration that had drifted from the baseline when the radio booted and were put
+	// back. Empty if there were none, or if the API has been restarted since the radio booted.
+	BootConfigurationFixes []string `json:"bootConfigurationFixes,omitempty"`
+
+	// Progress of the boot watchdog's attempts to recover the radio if it didn't finish starting up in time. Nil if no
+	// recovery was needed.
+	BootRecovery *BootRecoveryStatus `json:"bootRecovery,omitempty"`
+
+	// Source and voltage of the radio's power input, and any brownouts seen. Nil if the hardware doesn't report them.
+	Power *PowerStatus `json:"power,omitempty"`
+
+	// How long the radio has been up and why it last rebooted. Nil if the kernel doesn't report them.
+	Boot *BootStatus `json:"boot,omitempty"`
+
+	// ISO 3166-1 alpha-2 code of the country whose wireless regulatory domain the radio is operating under.
+	Country string `json:"country"`
+
+	// Inconsistencies between the radio's configuration and the regulatory rules it is actually operating under, as of
+	// startup or the last configuration.
+	RegulatoryViolations []RegulatoryViolation `json:"regulatoryViolations"`
+
+	// 802.11ax BSS color the radio is using, or zero if not set. Only applicable to Vivid-Hosting radios.
+	BssColor int `json:"bssColor"`
+
+	// 802.11ax guard interval the radio is using, or blank if not set. Only applicable to Vivid-Hosting radios.
+	HeGuardInterval string `json:"heGuardInterval"`
+
+	// Whether 802.11ax target wake time is enabled. Only applicable to Vivid-Hosting radios.
+	TargetWakeTime bool `json:"targetWakeTime"`
+
+	// Interval between beacons the radio is using, in time units (TU) of 1.024 ms.
+	BeaconIntervalTu int `json:"beaconIntervalTu"`
+
+	// Number of beacons between each delivery of buffered broadcast and multicast traffic to clients in power save.
+	DtimPeriod int `json:"dtimPeriod"`
+
+	// Which layers of isolation between team clients are currently in place.
+	ClientIsolation ClientIsolationStatus `json:"clientIsolation"`
+
+	// Whether the access point serves the time over NTP to the devices on the team VLANs.
+	TimeServer bool `json:"timeServer"`
+
+	// 802.11w management frame protection mode of each station. Empty if the hardware doesn't support configuring it.
+	ManagementFrameProtection map[string]managementFrameProtection `json:"managementFrameProtection"`
+
+	// How stations without a team assigned are currently presented: "BROADCAST", "HIDDEN", or "DISABLED".
+	UnassignedStationMode unassignedStationMode `json:"unassignedStationMode"`
+
 	// Queue for receiving and buffering configuration requests.
 	ConfigurationRequestChannel chan ConfigurationRequest `json:"-"`
 
+	// State of the keepalive contract with the FMS.
+	Heartbeat HeartbeatStatus `json:"heartbeat"`
+
+	// Whether the FMS has signaled that a match is in progress.
+	MatchLock MatchLockStatus `json:"matchLock"`
+
+	// Whether the radio is in maintenance mode, during which changes to its configuration are rejected.
+	Maintenance MaintenanceStatus `json:"maintenance"`
+
+	// Outcome of the first-boot provisioning of a freshly flashed radio.
+	Provisioning ProvisioningStatus `json:"provisioning"`
+
+	// State of the policy for switching to a backup channel under sustained interference.
+	ChannelFailover ChannelFailoverStatus `json:"channelFailover"`
+
+	// Schedule for taking the team networks off the air outside event hours, and whether it is currently doing so.
+	QuietHours QuietHoursStatus `json:"quietHours"`
+
+	// Whether the team networks are being held down by a field-wide emergency stop.
+	EmergencyStop EmergencyStopStatus `json:"emergencyStop"`
+
+	// State of the pairing with a standby access point, or with the primary if this is the standby.
+	Standby StandbyStatus `json:"standby"`
+
+	// Addressing through which the access point is managed, and the progress of any change to it.
+	ManagementNetwork ManagementNetworkStatus `json:"managementNetwork"`
+
+	// Which team VLANs are tagged on the uplink port, and which stations' VLANs aren't.
+	VlanTrunk VlanTrunkStatus `json:"vlanTrunk"`
+
+	// WPA and power save timers of the team networks.
+	StationTimers StationTimers `json:"stationTimers"`
+
+	// BSSID that each enabled station's network is currently broadcasting with, keyed by station name.
+	StationBssids map[string]string `json:"stationBssids"`
+
+	// Tunable parameters controlling the behavior of the API, replaced wholesale when the settings file is reloaded.
+	settings Settings
+
+	// Mutex guarding the settings, which are reloaded from the web server goroutine.
+	settingsMutex sync.RWMutex
+
 	// Hardware type of the radio.
 	Type RadioType `json:"-"`
 
@@ -54,6 +166,180 @@ type Radio struct {
 
 	// Map of team station names to their Wi-Fi interface names, dependent on the hardware type.
 	stationInterfaces map[station]string
+
+	// Mutex guarding the heartbeat state, which is updated from the web server goroutine.
+	heartbeatMutex sync.Mutex
+
+	// Mutex guarding the match lock state, which is updated from the web server goroutine.
+	matchLockMutex sync.Mutex
+
+	// Mutex guarding the quiet hours state, whose schedule is updated from the web server goroutine.
+	quietHoursMutex sync.Mutex
+
+	// Whether the transmit power is currently lowered for quiet hours.
+	quietHoursTxPowerLowered bool
+
+	// Mutex guarding the event schedule, which is updated from the web server goroutine.
+	eventScheduleMutex sync.Mutex
+
+	// Teams expected on each station in each match, against which configuration requests are checked.
+	eventSchedule EventSchedule
+
+	// Mutex guarding the emergency stop state, which is updated from the web server goroutine.
+	emergencyStopMutex sync.Mutex
+
+	// Mutex guarding the standby state, which is updated from the web server and mirroring goroutines.
+	standbyMutex sync.Mutex
+
+	// Configuration waiting to be mirrored to the standby once the one currently being sent has been delivered.
+	pendingStandbyConfiguration *ConfigurationRequest
+
+	// Whether a goroutine is currently mirroring configurations to the standby.
+	isMirroringToStandby bool
+
+	// Mutex guarding the management network state, which is updated from the web server goroutine.
+	managementNetworkMutex sync.Mutex
+
+	// Mutex guarding the VLAN trunk state, whose pending change is updated from the web server goroutine.
+	vlanTrunkMutex sync.Mutex
+
+	// Mutex guarding the maintenance mode state, which is updated from the web server and button goroutines.
+	maintenanceMutex sync.Mutex
+
+	// Mutex guarding the provisioning state, which is updated from the web server goroutine.
+	provisioningMutex sync.Mutex
+
+	// Signaled when the radio is provisioned, releasing it from the PROVISIONING status.
+	provisionedChannel chan struct{}
+
+	// Mutex guarding the station assignments, which are read from the web server goroutine.
+	stationAssignmentsMutex sync.Mutex
+
+	// Copy of the SSID and VLAN of each team station with a team assigned, for use outside the radio goroutine.
+	stationAssignments map[string]stationAssignment
+
+	// Mutex guarding the channel and country, which are read from the web server goroutine to validate requests.
+	regulatoryMutex sync.Mutex
+
+	// Revision of the configuration, which is the identifier of the most recently queued configuration request, for
+	// rejecting changes based on a stale read. Zero if none has been queued since the API started.
+	ConfigurationRevision int `json:"configurationRevision"`
+
+	// Opaque metadata carried by the configuration request that was most recently applied successfully (e.g. the match
+	// number and event code that the current configuration is for). Null if it carried none.
+	ConfigurationMetadata map[string]string `json:"configurationMetadata"`
+
+	// Progress and outcomes of recently queued configuration requests.
+	configurationRequests configurationRequestLog
+
+	// Counters of how configuring the radio has gone on each hardware type and firmware version it has run.
+	configurationStats configurationStatsLog
+
+	// Time at which a configuration request was last applied, used to poll faster for a while afterwards.
+	lastConfiguredAt time.Time
+
+	// Requests to hand the radio over to a new API process, handled between configurations and polls.
+	handoverChannel chan handoverRequest
+
+	// Mutex guarding the restored handover state, which is restored from the web server goroutine.
+	handoverMutex sync.Mutex
+
+	// State carried over from the API process that this one replaced that is yet to be adopted by the radio goroutine.
+	restoredHandoverState *personalityHandoverState
+
+	// Whether the main loop is configuring and polling the radio, as opposed to waiting for it to start up.
+	isLoopRunning atomic.Bool
+
+	// Accumulated channel survey and scan data used to produce the interference report.
+	survey channelSurvey
+
+	// Time at which the monitoring data was last updated.
+	MonitoredAt Timestamp `json:"monitoredAt"`
+
+	// How well the radio's clock is synchronized, for correlating its timestamps with those of other devices.
+	TimeSync TimeSyncStatus `json:"timeSync"`
+
+	// Health of the radio's flash storage.
+	Storage StorageHealth `json:"storage"`
+
+	// Storage health and outcome of the retention policies as of their most recent enforcement.
+	storageReport StorageReport
+
+	// Mutex guarding the storage report, which is read from the web server goroutine.
+	retentionMutex sync.Mutex
+
+	// State of the check for changes made to the UCI configuration by something other than the API.
+	ConfigDrift ConfigDriftStatus `json:"configDrift"`
+
+	// Known failures of the Linksys wireless stack detected while configuring it, and the mitigations attempted.
+	LinksysMitigation LinksysMitigationStatus `json:"linksysMitigation"`
+
+	// Counter that is incremented whenever the externally visible state of the radio changes.
+	statusRevision atomic.Uint64
+
+	// Contents of each watched UCI configuration file as last written or adopted by the API, keyed by configuration.
+	uciSnapshots map[string][]byte
+
+	// Names of watched UCI configuration files that have been modified, as reported by the file watcher.
+	uciChanges chan string
+
+	// Most recent alerts raised by the radio.
+	alerts alertLog
+
+	// Connection over which status transitions and monitoring samples are published to co-resident daemons.
+	eventSocket eventSocketPublisher
+
+	// Worker that pushes monitoring samples to the telemetry sinks.
+	telemetry telemetryPublisher
+
+	// Mutex held while a link test is running, so that only one runs at a time.
+	linkTestMutex sync.Mutex
+
+	// Link telemetry recorded at each of the most recent monitoring polls.
+	monitoringHistory monitoringHistory
+
+	// State of the iperf3 server used for team throughput checks.
+	iperf iperfServer
+
+	// Position up to which the hostapd log has been scanned for handshake failures.
+	handshakeLogCursor systemLogCursor
+
+	// Driver counters for the device linked to each station as of the last time its retry rates were assessed.
+	retryBaselines map[station]retryCounterSample
+
+	// Association history of each station since its team was configured, used to track its uptime and link drops.
+	associationHistories map[station]*associationHistory
+
+	// Hashes of the WPA keys that each team has been configured with at the current event.
+	wpaKeyHistory WpaKeyHistory
+
+	// Total bytes of each tracked connection to or from a team network as of the last traffic mix sample, keyed by the
+	// connection's original direction.
+	trafficFlowBytes map[string]int64
+
+	// Time at which the connection tracking table was last sampled.
+	trafficSampledAt time.Time
+
+	// Jitter of the control traffic captured on each interface.
+	controlJitter controlJitterTracker
+
+	// Most recent log entries received from the robot radio of each team.
+	robotLogs robotLogBuffer
+
+	// Time at which an NTP request was last seen from each device on the team networks, keyed by its IP address.
+	timeServerRequests map[string]Timestamp
+
+	// Number of monitoring polls since the API started, used to schedule storage health checks.
+	storagePollCount int
+
+	// Whether a one-off removal of ghost associations has been requested for the next monitoring poll.
+	ghostClientRemovalRequested atomic.Bool
+
+	// Number of consecutive monitoring polls in which each station's network has failed to be queried.
+	stationFailurePolls map[station]int
+
+	// Whether the temperature has reached the overheating threshold without having cooled down since.
+	isOverheating bool
 }
 
 // AllianceVlans represents which three VLANs are used for the teams of an alliance.
@@ -72,6 +358,9 @@ func NewRadio() *Radio {
 		BlueVlans:                   Vlans405060,
 		Status:                      statusBooting,
 		ConfigurationRequestChannel: make(chan ConfigurationRequest, configurationRequestBufferSize),
+		settings:                    defaultSettings(),
+		handoverChannel:             make(chan handoverRequest),
+		provisionedChannel:          make(chan struct{}, 1),
 	}
 	radio.determineAndSetType()
 	if radio.Type == TypeUnknown {
@@ -79,6 +368,7 @@ func NewRadio() *Radio {
 	}
 	log.Printf("Detected radio hardware type: %v", radio.Type)
 	radio.determineAndSetVersion()
+	radio.loadProvisioningStatus()
 
 	// Initialize the device and station interface names that are dependent on the hardware type.
 	switch radio.Type {
@@ -108,6 +398,7 @@ func NewRadio() *Radio {
 	for station := red1; station <= blue3; station++ {
 		radio.StationStatuses[station.String()] = nil
 	}
+	radio.updateAllianceStatuses()
 
 	return &radio
 }
@@ -149,14 +440,24 @@ func (radio *Radio) determineAndSetType() {
 
 // isStarted returns true if the Wi-Fi interface is up and running.
 func (radio *Radio) isStarted() bool {
-	_, err := shell.runCommand("iwinfo", radio.stationInterfaces[blue3], "info")
-	return err == nil
+	// Check the last station that has a network, since disabled networks have no interface to query.
+	for station := blue3; station >= red1; station-- {
+		if !radio.isStationDisabled(station) {
+			_, err := shell.runCommand("iwinfo", radio.stationInterfaces[station], "info")
+			return err == nil
+		}
+	}
+	return true
 }
 
 // setInitialState initializes the in-memory state to match the radio's current configuration.
 func (radio *Radio) setInitialState() {
 	channel, _ := uciTree.GetLast("wireless", radio.device, "channel")
+	country, _ := uciTree.GetLast("wireless", radio.device, "country")
+	radio.regulatoryMutex.Lock()
 	radio.Channel, _ = strconv.Atoi(channel)
+	radio.Country = country
+	radio.regulatoryMutex.Unlock()
 	htmode, _ := uciTree.GetLast("wireless", radio.device, "htmode")
 	switch htmode {
 	case "HT20":
@@ -167,124 +468,281 @@ func (radio *Radio) setInitialState() {
 		radio.ChannelBandwidth = "INVALID"
 	}
 	_ = radio.updateStationStatuses()
-
+	radio.UnassignedStationMode = radio.appliedUnassignedStationMode()
+	radio.ClientIsolation = readClientIsolation()
+	radio.TimeServer = readTimeServer()
+	radio.ManagementFrameProtection = radio.readManagementFrameProtection()
+
+	if hardwareCapabilityTable[radio.Type].supportsHeOptions {
+		bssColor, _ := uciTree.GetLast("wireless", radio.device, "he_bss_color")
+		radio.BssColor, _ = strconv.Atoi(bssColor)
+		radio.HeGuardInterval, _ = uciTree.GetLast("wireless", radio.device, "he_gi")
+		targetWakeTime, _ := uciTree.GetLast("wireless", radio.device, "he_twt")
+		radio.TargetWakeTime = targetWakeTime == "1"
+	}
+	radio.BeaconIntervalTu, radio.DtimPeriod = readBeaconTiming(radio.device)
+	radio.StationTimers = readStationTimers()
 	radio.SyslogIpAddress, _ = uciTree.GetLast("system", "@system[0]", "log_ip")
+	radio.revertUnconfirmedManagementNetwork()
+	radio.managementNetworkMutex.Lock()
+	radio.ManagementNetwork.Current = readManagementNetwork()
+	radio.managementNetworkMutex.Unlock()
+
+	// A standby's networks may have come up without the API's involvement, such as on boot.
+	radio.updateStandbyNetworks(true)
 }
 
 // configure configures the radio with the given configuration.
 func (radio *Radio) configure(request ConfigurationRequest) error {
+	stationConfigurations := withOmittedStationsUnassigned(request.StationConfigurations)
+	if request.preservesOmittedStations() {
+		stationConfigurations = radio.mergeWithCurrentStations(request.StationConfigurations)
+	}
+	// Decide before any of the request is applied whether it changes nothing but WPA keys.
+	keyChanges := radio.keyOnlyChanges(request, stationConfigurations)
+
+	radio.releaseEmergencyStop()
 	if request.Channel > 0 {
 		uciTree.SetType("wireless", radio.device, "channel", uci.TypeOption, strconv.Itoa(request.Channel))
+		radio.regulatoryMutex.Lock()
 		radio.Channel = request.Channel
+		radio.regulatoryMutex.Unlock()
 	}
 	if request.ChannelBandwidth != "" {
-		var htmode string
-		switch request.ChannelBandwidth {
-		case "20MHz":
-			htmode = "HT20"
-		case "40MHz":
-			htmode = "HT40"
-		default:
+		htmode, ok := htmodeForBandwidth(request.ChannelBandwidth)
+		if !ok {
 			return fmt.Errorf("invalid channel bandwidth: %s", request.ChannelBandwidth)
 		}
 		uciTree.SetType("wireless", radio.device, "htmode", uci.TypeOption, htmode)
 		radio.ChannelBandwidth = request.ChannelBandwidth
 	}
+	if request.Country != "" {
+		uciTree.SetType("wireless", radio.device, "country", uci.TypeOption, request.Country)
+		radio.regulatoryMutex.Lock()
+		radio.Country = request.Country
+		radio.regulatoryMutex.Unlock()
+	}
+	if request.BssColor > 0 {
+		uciTree.SetType("wireless", radio.device, "he_bss_color", uci.TypeOption, strconv.Itoa(request.BssColor))
+		radio.BssColor = request.BssColor
+	}
+	if request.HeGuardInterval != "" {
+		uciTree.SetType("wireless", radio.device, "he_gi", uci.TypeOption, request.HeGuardInterval)
+		radio.HeGuardInterval = request.HeGuardInterval
+	}
+	if request.TargetWakeTime != nil {
+		targetWakeTime := "0"
+		if *request.TargetWakeTime {
+			targetWakeTime = "1"
+		}
+		uciTree.SetType("wireless", radio.device, "he_twt", uci.TypeOption, targetWakeTime)
+		radio.TargetWakeTime = *request.TargetWakeTime
+	}
+	if request.BeaconIntervalTu > 0 || request.DtimPeriod > 0 {
+		radio.configureBeaconTiming(request.BeaconIntervalTu, request.DtimPeriod)
+	}
+	if request.ClientIsolation != nil {
+		if err := radio.configureClientIsolation(*request.ClientIsolation); err != nil {
+			return err
+		}
+	}
+	if request.TimeServer != nil {
+		if err := radio.configureTimeServer(*request.TimeServer); err != nil {
+			return err
+		}
+	}
 	if request.RedVlans != "" && request.BlueVlans != "" {
 		radio.RedVlans = request.RedVlans
 		radio.BlueVlans = request.BlueVlans
 	}
 	if request.SyslogIpAddress != "" {
 		uciTree.SetType("system", "@system[0]", "log_ip", uci.TypeOption, request.SyslogIpAddress)
-		if err := uciTree.Commit(); err != nil {
-			return fmt.Errorf("failed to commit system configuration: %v", err)
+		if err := radio.commitUci("system"); err != nil {
+			return fmt.Errorf("failed to commit system configuration: %w", err)
 		}
 		radio.SyslogIpAddress = request.SyslogIpAddress
 		if _, err := shell.runCommand("/etc/init.d/log", "restart"); err != nil {
-			return fmt.Errorf("failed to restart syslog service: %v", err)
+			return fmt.Errorf("failed to restart syslog service: %w", err)
 		}
 	}
 
-	if radio.Type == TypeLinksys {
-		// Clear the state of the radio before loading teams; the Linksys AP is crash-prone otherwise.
-		if err := radio.configureStations(map[string]*StationConfiguration{}); err != nil {
+	radio.configureManagementFrameProtection(request)
+	radio.configureStationTimers()
+
+	if keyChanges != nil && radio.applyKeyChanges(keyChanges, stationConfigurations) {
+		radio.checkWpaKeyReuse(request)
+		radio.mirrorToStandby()
+		return nil
+	}
+	if radio.driverQuirks().clearBeforeConfigure {
+		// Clear the state of the radio before loading teams; the wireless stack is crash-prone otherwise.
+		if err := radio.configureLinksysStations(stationConfigurations); err != nil {
 			return err
 		}
-		time.Sleep(wifiReloadBackoffDuration)
+	} else if err := radio.configureStations(stationConfigurations); err != nil {
+		return err
+	}
+	if request.ClientIsolation != nil {
+		// The isolate option of each network has now been applied by the wireless reload.
+		radio.ClientIsolation.Wireless = *request.ClientIsolation
+	}
+	if radio.isEmergencyStopActive() {
+		// An emergency stop was activated while this configuration was being applied.
+		radio.setTeamNetworksEnabled(false, "for emergency stop")
+	} else if radio.isQuietHoursActive() {
+		// The wireless reload brought the networks back up, so take them down again until quiet hours end.
+		radio.enterQuietHours(radio.GetQuietHours().Schedule)
+	} else if radio.isHoldingStandbyNetworks() {
+		radio.setTeamNetworksEnabled(false, "while on standby")
+	}
+	radio.checkWpaKeyReuse(request)
+	radio.mirrorToStandby()
+	return nil
+}
+
+// htmodeForBandwidth returns the UCI htmode option value for the given channel bandwidth, and false if the bandwidth
+// isn't supported.
+func htmodeForBandwidth(bandwidth string) (string, bool) {
+	switch bandwidth {
+	case "20MHz":
+		return "HT20", true
+	case "40MHz":
+		return "HT40", true
+	default:
+		return "", false
+	}
+}
+
+// mergeWithCurrentStations returns a full set of station configurations consisting of the given ones plus the current
+// configuration of any stations that are omitted from them.
+func (radio *Radio) mergeWithCurrentStations(
+	stationConfigurations map[string]*StationConfiguration,
+) map[string]*StationConfiguration {
+	merged := make(map[string]*StationConfiguration)
+	for station := red1; station <= blue3; station++ {
+		if config, ok := stationConfigurations[station.String()]; ok {
+			merged[station.String()] = config
+		} else if status := radio.StationStatuses[station.String()]; status != nil {
+			wpaKey, _ := uciTree.GetLast("wireless", fmt.Sprintf("@wifi-iface[%d]", int(station)+1), "key")
+			merged[station.String()] = &StationConfiguration{Ssid: status.Ssid, WpaKey: wpaKey}
+		} else {
+			merged[station.String()] = nil
+		}
+	}
+	return merged
+}
+
+// withOmittedStationsUnassigned returns a full set of station configurations consisting of the given ones plus a null
+// configuration for each station that is omitted from them.
+func withOmittedStationsUnassigned(
+	stationConfigurations map[string]*StationConfiguration,
+) map[string]*StationConfiguration {
+	full := make(map[string]*StationConfiguration)
+	for station := red1; station <= blue3; station++ {
+		full[station.String()] = stationConfigurations[station.String()]
 	}
-	return radio.configureStations(request.StationConfigurations)
+	return full
 }
 
-// configureStations configures the access point with the given team station configurations.
+// configureStations configures the access point with the given team station configurations. Stations with a null
+// configuration are unconfigured, and stations that are absent are left as they are.
 func (radio *Radio) configureStations(stationConfigurations map[string]*StationConfiguration) error {
 	retryCount := 1
 
 	for {
-		// Only configure stations that are in the request
-		for stationName, config := range stationConfigurations {
-			// Skip stations that are being unconfigured (config is nil)
-			if config == nil {
+		for station := red1; station <= blue3; station++ {
+			config, ok := stationConfigurations[station.String()]
+			if !ok {
 				continue
 			}
-
-			// Convert station name to station enum
-			var station station
-			for s := red1; s <= blue3; s++ {
-				if s.String() == stationName {
-					station = s
-					break
-				}
+			var ssid, wpaKey string
+			if config != nil {
+				ssid = config.Ssid
+				wpaKey = config.WpaKey
+			} else {
+				ssid = radio.placeholderSsid(station)
+				wpaKey = ssid
 			}
 
-			position := int(station) + 1
-			wifiInterface := fmt.Sprintf("@wifi-iface[%d]", position)
-
-			// Set the new configuration
-			uciTree.SetType("wireless", wifiInterface, "ssid", uci.TypeOption, config.Ssid)
-			uciTree.SetType("wireless", wifiInterface, "key", uci.TypeOption, config.WpaKey)
-			if radio.Type == TypeVividHosting {
-				uciTree.SetType("wireless", wifiInterface, "sae_password", uci.TypeOption, config.WpaKey)
+			wifiInterface := wifiIfaceSection(station)
+			radio.setUnassignedStationFlags(station, config != nil)
+			radio.configureStationBssid(station, ssid)
+			uciTree.SetType("wireless", wifiInterface, "ssid", uci.TypeOption, ssid)
+			uciTree.SetType("wireless", wifiInterface, "key", uci.TypeOption, wpaKey)
+			if radio.driverQuirks().duplicateSaePassword {
+				uciTree.SetType("wireless", wifiInterface, "sae_password", uci.TypeOption, wpaKey)
 			}
 			vlan := fmt.Sprintf("vlan%d", radio.getStationVlan(station))
 			uciTree.SetType("wireless", wifiInterface, "network", uci.TypeOption, vlan)
 		}
 
 		// Commit all changes at once
-		if err := uciTree.Commit(); err != nil {
-			return fmt.Errorf("failed to commit wireless configuration: %v", err)
+		if err := radio.commitUci("wireless"); err != nil {
+			return fmt.Errorf("failed to commit wireless configuration: %w", err)
 		}
+		radio.recordConfigurationStep(configurationStepUciCommitted, retryCount)
 
 		if _, err := shell.runCommand("wifi", "reload", radio.device); err != nil {
-			return fmt.Errorf("failed to reload configuration for device %s: %v", radio.device, err)
+			return fmt.Errorf("failed to reload configuration for device %s: %w", radio.device, err)
 		}
-		time.Sleep(wifiReloadBackoffDuration)
+		radio.recordConfigurationStep(configurationStepWifiReloaded, retryCount)
+		time.Sleep(radio.reloadBackoffDuration())
 
+		// Read back what was actually committed, rather than what the in-memory tree was asked to write.
+		radio.recordConfigurationStep(configurationStepVerifying, retryCount)
+		if err := uciTree.LoadConfig("wireless", true); err != nil {
+			return fmt.Errorf("failed to reload wireless configuration for verification: %v", err)
+		}
 		err := radio.updateStationStatuses()
-		if err != nil {
-			return fmt.Errorf("error updating station statuses: %v", err)
+		var verificationErr error
+		if err == nil {
+			if verificationErr = radio.verifyStationConfigurations(stationConfigurations); verificationErr == nil {
+				radio.UnassignedStationMode = radio.GetSettings().UnassignedStationMode
+				return nil
+			}
+			log.Printf("Configuration attempt %d failed verification: %v", retryCount, verificationErr)
+			radio.updateConfigurationStats(func(stats *ConfigurationStats) { stats.VerificationFailureCount++ })
 		}
-
-		if radio.stationSsidsAreCorrect(stationConfigurations) {
-			return nil
+		if radio.driverQuirks().detectReloadFailures {
+			// Retrying the reload won't help if the wireless stack has failed in one of the known ways.
+			if failure := radio.detectLinksysFailure(); failure != nil {
+				return failure
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("error updating station statuses: %w", err)
 		}
 
 		if retryCount >= maxRetryCount {
-			return fmt.Errorf("failed to configure stations after %d attempts", retryCount)
+			return withKind(
+				ErrVerificationTimeout,
+				fmt.Errorf("failed to configure stations after %d attempts: %v", retryCount, verificationErr),
+			)
 		}
 		retryCount++
-		time.Sleep(wifiReloadBackoffDuration)
+		radio.updateConfigurationStats(func(stats *ConfigurationStats) { stats.RetryCount++ })
+		time.Sleep(radio.reloadBackoffDuration())
 	}
 }
 
 // updateStationStatuses fetches the current Wi-Fi status (SSID, WPA key, etc.) for each team station and updates the
 // in-memory state.
 func (radio *Radio) updateStationStatuses() error {
+	bssids := make(map[string]string)
 	for station := red1; station <= blue3; station++ {
-		ssid, err := getSsid(radio.stationInterfaces[station])
+		if radio.isStationDisabled(station) {
+			// The interface of a disabled network doesn't exist, so there is nothing to query.
+			radio.StationStatuses[station.String()] = nil
+			continue
+		}
+		ssid, bssid, err := getSsidAndBssid(radio.stationInterfaces[station])
 		if err != nil {
 			return err
 		}
-		if strings.HasPrefix(ssid, "no-team-") {
+		if bssid != "" {
+			bssids[station.String()] = bssid
+		}
+		if radio.isPlaceholderSsid(station, ssid) {
 			radio.StationStatuses[station.String()] = nil
 		} else {
 			var status NetworkStatus
@@ -293,32 +751,122 @@ func (radio *Radio) updateStationStatuses() error {
 			radio.StationStatuses[station.String()] = &status
 		}
 	}
+	radio.StationBssids = bssids
+	radio.updateAllianceStatuses()
+	radio.publishStationAssignments()
 
 	return nil
 }
 
-// stationSsidsAreCorrect returns true if the configured networks as read from the access point match the requested
-// configuration.
-func (radio *Radio) stationSsidsAreCorrect(stationConfigurations map[string]*StationConfiguration) bool {
-	for stationName, stationStatus := range radio.StationStatuses {
-		if config, ok := stationConfigurations[stationName]; ok {
-			if stationStatus == nil || stationStatus.Ssid != config.Ssid {
-				return false
-			}
-		} else {
-			if stationStatus != nil {
-				// This is an error case; we expect the station status to be nil if the station is not configured.
-				return false
-			}
+// stationAssignment represents the team assigned to a station, as seen from outside the radio goroutine.
+type stationAssignment struct {
+	ssid string
+	vlan int
+}
+
+// publishStationAssignments copies the SSID and VLAN of each team station with a team assigned to where the web server
+// goroutine can read them.
+func (radio *Radio) publishStationAssignments() {
+	assignments := make(map[string]stationAssignment)
+	for station := red1; station <= blue3; station++ {
+		if status := radio.StationStatuses[station.String()]; status != nil {
+			assignments[station.String()] = stationAssignment{ssid: status.Ssid, vlan: radio.getStationVlan(station)}
 		}
 	}
 
-	return true
+	radio.stationAssignmentsMutex.Lock()
+	defer radio.stationAssignmentsMutex.Unlock()
+	radio.stationAssignments = assignments
+}
+
+// getStationAssignment returns the SSID and VLAN of the team assigned to the given station, and false if there is none.
+func (radio *Radio) getStationAssignment(station string) (stationAssignment, bool) {
+	radio.stationAssignmentsMutex.Lock()
+	defer radio.stationAssignmentsMutex.Unlock()
+	assignment, ok := radio.stationAssignments[station]
+	return assignment, ok
+}
+
+// getChannelAndCountry returns the channel and country currently configured on the radio.
+func (radio *Radio) getChannelAndCountry() (int, string) {
+	radio.regulatoryMutex.Lock()
+	defer radio.regulatoryMutex.Unlock()
+	return radio.Channel, radio.Country
+}
+
+// lockStatus acquires each mutex guarding part of the status so that it can be marshaled consistently, and returns a
+// function that releases them again.
+func (radio *Radio) lockStatus() func() {
+	radio.provisioningMutex.Lock()
+	radio.heartbeatMutex.Lock()
+	radio.matchLockMutex.Lock()
+	radio.quietHoursMutex.Lock()
+	radio.emergencyStopMutex.Lock()
+	radio.standbyMutex.Lock()
+	radio.managementNetworkMutex.Lock()
+	radio.vlanTrunkMutex.Lock()
+	radio.maintenanceMutex.Lock()
+	radio.configurationRequests.mutex.Lock()
+	radio.regulatoryMutex.Lock()
+	return func() {
+		radio.regulatoryMutex.Unlock()
+		radio.configurationRequests.mutex.Unlock()
+		radio.maintenanceMutex.Unlock()
+		radio.vlanTrunkMutex.Unlock()
+		radio.managementNetworkMutex.Unlock()
+		radio.standbyMutex.Unlock()
+		radio.emergencyStopMutex.Unlock()
+		radio.quietHoursMutex.Unlock()
+		radio.matchLockMutex.Unlock()
+		radio.heartbeatMutex.Unlock()
+		radio.provisioningMutex.Unlock()
+	}
+}
+
+// monitoredNetworks returns the status of each team station that has a team configured, keyed by station name.
+func (radio *Radio) monitoredNetworks() map[string]*NetworkStatus {
+	networks := make(map[string]*NetworkStatus)
+	for name, status := range radio.StationStatuses {
+		if status != nil {
+			networks[name] = status
+		}
+	}
+	return networks
+}
+
+// hasLinkedClients returns true if a device is associated with any of the team stations.
+func (radio *Radio) hasLinkedClients() bool {
+	for _, stationStatus := range radio.StationStatuses {
+		if stationStatus != nil && stationStatus.IsLinked {
+			return true
+		}
+	}
+	return false
+}
+
+// setTeamNetworksEnabled starts or stops each configured team network broadcasting without touching the wireless
+// configuration, so that a stopped network comes back as it was. Failures are logged along with the given reason.
+func (radio *Radio) setTeamNetworksEnabled(enabled bool, reason string) {
+	command, action := "disable", "disabling"
+	if enabled {
+		command, action = "enable", "re-enabling"
+	}
+	for station := red1; station <= blue3; station++ {
+		if radio.isStationDisabled(station) {
+			continue
+		}
+		wifiInterface := radio.stationInterfaces[station]
+		if _, err := shell.runCommand("hostapd_cli", "-i", wifiInterface, command); err != nil {
+			log.Printf("Error %s %s %s: %v", action, wifiInterface, reason, err)
+		}
+	}
 }
 
 // updateMonitoring polls the access point for the current bandwidth usage and link state of each team station and
 // updates the in-memory state.
 func (radio *Radio) updateMonitoring() {
+	radio.adoptRestoredHandoverState()
+	enrichers := statusEnrichersFor(radio.Type)
 	for station := red1; station <= blue3; station++ {
 		stationStatus := radio.StationStatuses[station.String()]
 		if stationStatus == nil {
@@ -326,6 +874,31 @@ func (radio *Radio) updateMonitoring() {
 			continue
 		}
 
-		stationStatus.updateMonitoring(radio.stationInterfaces[station])
+		stationStatus.updateMonitoring(radio.stationInterfaces[station], enrichers)
 	}
+	radio.removeGhostClients()
+	radio.updateRetryCounters()
+	radio.updateClientCapabilities()
+	radio.updateHandshakeFailures()
+	radio.updateAssociationHistories(time.Now())
+	radio.checkAssociationStorms(time.Now())
+	radio.updateTrafficMixes(time.Now())
+	radio.updateControlJitter(time.Now())
+	radio.updateTimeServerClients(newTimestamp())
+	radio.updateQualityScores()
+	radio.updateAllianceStatuses()
+
+	radio.updateChannelSurvey(time.Now())
+
+	// Expire the match lock if necessary so that the status reflects it even if nothing else checks it.
+	radio.isMatchLockHeld()
+	radio.checkChannelFailover()
+	radio.checkHeartbeat()
+	radio.checkQuietHours(time.Now())
+	radio.checkStandby()
+	radio.checkManagementNetwork(time.Now())
+	radio.checkStationFailures()
+	radio.checkVlanTrunk()
+	radio.checkTemperature()
+	radio.updatePowerStatus()
 }
@@ -124,8 +124,9 @@ func TestRadio_setInitialState(t *testing.T) {
 	fakeTree.valuesForGet["wireless.wifi1.channel"] = "23"
 	fakeTree.valuesForGet["wireless.wifi1.htmode"] = "HT20"
 	fakeTree.valuesForGet["system.@system[0].log_ip"] = "10.20.30.40"
-	fakeShell.commandOutput["iwinfo ath1 info"] = "ath1\nESSID: \"1111\"\n"
-	fakeShell.commandOutput["iwinfo ath11 info"] = "ath11\nESSID: \"no-team-2\"\n"
+	fakeTree.valuesForGet["wireless.wifi1.country"] = "CA"
+	fakeShell.commandOutput["iwinfo ath1 info"] = "ath1\nESSID: \"1111\"\nAccess Point: 02:11:22:33:44:01\n"
+	fakeShell.commandOutput["iwinfo ath11 info"] = "ath11\nESSID: \"no-team-2\"\nAccess Point: 02:11:22:33:44:02\n"
 	fakeShell.commandOutput["iwinfo ath12 info"] = "ath12\nESSID: \"no-team-3\"\n"
 	fakeShell.commandOutput["iwinfo ath13 info"] = "ath13\nESSID: \"no-team-4\"\n"
 	fakeShell.commandOutput["iwinfo ath14 info"] = "ath14\nESSID: \"no-team-5\"\n"
@@ -139,7 +140,11 @@ func TestRadio_setInitialState(t *testing.T) {
 	assert.Nil(t, radio.StationStatuses["blue1"])
 	assert.Nil(t, radio.StationStatuses["blue2"])
 	assert.Equal(t, "6666", radio.StationStatuses["blue3"].Ssid)
+	assert.Equal(t, map[string]string{"red1": "02:11:22:33:44:01", "red2": "02:11:22:33:44:02"}, radio.StationBssids)
+	assert.Equal(t, 1, radio.AllianceStatuses["red"].ConfiguredStationCount)
+	assert.Equal(t, 1, radio.AllianceStatuses["blue"].ConfiguredStationCount)
 	assert.Equal(t, "10.20.30.40", radio.SyslogIpAddress)
+	assert.Equal(t, "CA", radio.Country)
 }
 
 func TestRadio_handleConfigurationRequestVividHosting(t *testing.T) {
@@ -162,15 +167,15 @@ func TestRadio_handleConfigurationRequestVividHosting(t *testing.T) {
 	fakeShell.commandOutput["iwinfo ath15 info"] = "ath15\nESSID: \"6666\"\n"
 	dummyRequest1 := ConfigurationRequest{
 		Channel:               1,
-		StationConfigurations: map[string]StationConfiguration{"red1": {Ssid: "1", WpaKey: "foo"}},
+		StationConfigurations: map[string]*StationConfiguration{"red1": {Ssid: "1", WpaKey: "foo"}},
 	}
 	dummyRequest2 := ConfigurationRequest{
 		Channel:               2,
-		StationConfigurations: map[string]StationConfiguration{"blue2": {Ssid: "2", WpaKey: "bar"}},
+		StationConfigurations: map[string]*StationConfiguration{"blue2": {Ssid: "2", WpaKey: "bar"}},
 	}
 	request := ConfigurationRequest{
 		Channel: 5,
-		StationConfigurations: map[string]StationConfiguration{
+		StationConfigurations: map[string]*StationConfiguration{
 			"red1":  {Ssid: "1111", WpaKey: "11111111"},
 			"red3":  {Ssid: "3333", WpaKey: "33333333"},
 			"blue2": {Ssid: "5555", WpaKey: "55555555"},
@@ -181,8 +186,11 @@ func TestRadio_handleConfigurationRequestVividHosting(t *testing.T) {
 	radio.ConfigurationRequestChannel <- dummyRequest2
 	radio.ConfigurationRequestChannel <- request
 	assert.Nil(t, radio.handleConfigurationRequest(dummyRequest1))
-	assert.Equal(t, 26, fakeTree.setCount)
+	assert.Equal(t, 44, fakeTree.setCount)
 	assert.Equal(t, fakeTree.valuesFromSet["wireless.wifi1.channel"], "5")
+	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[1].wpa_group_rekey"], "0")
+	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[6].max_inactivity"], "300")
+	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[6].max_listen_int"], "65535")
 	assert.Equal(t, fakeTree.valuesFromSet["system.@system[0].log_ip"], "12.34.56.78")
 	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[1].ssid"], "1111")
 	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[1].key"], "11111111")
@@ -208,7 +216,7 @@ func TestRadio_handleConfigurationRequestVividHosting(t *testing.T) {
 	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[6].key"], "66666666")
 	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[6].sae_password"], "66666666")
 	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[6].network"], "vlan60")
-	assert.Equal(t, 7, fakeTree.commitCount)
+	assert.Equal(t, 2, fakeTree.commitCount)
 	assert.Equal(t, 9, len(fakeShell.commandsRun))
 	assert.Contains(t, fakeShell.commandsRun, "/etc/init.d/log restart")
 	assert.Contains(t, fakeShell.commandsRun, "wifi reload wifi1")
@@ -234,7 +242,7 @@ func TestRadio_handleConfigurationRequestLinksys(t *testing.T) {
 	fakeTree.valuesForGet["system.@system[0].model"] = ""
 	fakeShell := newFakeShell(t)
 	shell = fakeShell
-	wifiReloadBackoffDuration = 100 * time.Millisecond
+	wifiReloadBackoffDuration = 10 * time.Millisecond
 	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
 	radio := NewRadio()
 
@@ -247,15 +255,15 @@ func TestRadio_handleConfigurationRequestLinksys(t *testing.T) {
 	fakeShell.commandOutput["iwinfo wlan0-5 info"] = "wlan0-5\nESSID: \"no-team-6\"\n"
 	dummyRequest1 := ConfigurationRequest{
 		Channel:               1,
-		StationConfigurations: map[string]StationConfiguration{"red1": {Ssid: "1", WpaKey: "foo"}},
+		StationConfigurations: map[string]*StationConfiguration{"red1": {Ssid: "1", WpaKey: "foo"}},
 	}
 	dummyRequest2 := ConfigurationRequest{
 		Channel:               2,
-		StationConfigurations: map[string]StationConfiguration{"blue2": {Ssid: "2", WpaKey: "bar"}},
+		StationConfigurations: map[string]*StationConfiguration{"blue2": {Ssid: "2", WpaKey: "bar"}},
 	}
 	request := ConfigurationRequest{
 		Channel: 5,
-		StationConfigurations: map[string]StationConfiguration{
+		StationConfigurations: map[string]*StationConfiguration{
 			"red2":  {Ssid: "2222", WpaKey: "22222222"},
 			"red3":  {Ssid: "3333", WpaKey: "33333333"},
 			"blue1": {Ssid: "4444", WpaKey: "44444444"},
@@ -264,11 +272,13 @@ func TestRadio_handleConfigurationRequestLinksys(t *testing.T) {
 	}
 	radio.ConfigurationRequestChannel <- dummyRequest2
 	radio.ConfigurationRequestChannel <- request
-	go func() {
-		// Allow some time for the first config-clearing change to be processed.
-		time.Sleep(150 * time.Millisecond)
+	fakeShell.onRunCommand = func(command string) {
+		// Check the config-clearing change once it has been processed.
+		if command != "iwinfo wlan0-5 info" {
+			return
+		}
 
-		assert.Equal(t, 19, fakeTree.setCount)
+		assert.Equal(t, 37, fakeTree.setCount)
 		assert.Equal(t, fakeTree.valuesFromSet["wireless.radio0.channel"], "5")
 		assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[1].ssid"], "no-team-1")
 		assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[1].key"], "no-team-1")
@@ -288,7 +298,7 @@ func TestRadio_handleConfigurationRequestLinksys(t *testing.T) {
 		assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[6].ssid"], "no-team-6")
 		assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[6].key"], "no-team-6")
 		assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[6].network"], "vlan60")
-		assert.Equal(t, 6, fakeTree.commitCount)
+		assert.Equal(t, 1, fakeTree.commitCount)
 		assert.Equal(t, 8, len(fakeShell.commandsRun))
 		assert.Contains(t, fakeShell.commandsRun, "wifi reload radio0")
 		assert.Contains(t, fakeShell.commandsRun, "iwinfo wlan0 info")
@@ -297,7 +307,10 @@ func TestRadio_handleConfigurationRequestLinksys(t *testing.T) {
 		assert.Contains(t, fakeShell.commandsRun, "iwinfo wlan0-3 info")
 		assert.Contains(t, fakeShell.commandsRun, "iwinfo wlan0-4 info")
 		assert.Contains(t, fakeShell.commandsRun, "iwinfo wlan0-5 info")
+		// The values committed by the config-clearing change are still read back to verify it.
+		committedValues := fakeTree.valuesForGet
 		fakeTree.reset()
+		fakeTree.valuesForGet = committedValues
 		fakeShell.reset()
 
 		// Change the iwinfo output after the configs are cleared.
@@ -308,7 +321,7 @@ func TestRadio_handleConfigurationRequestLinksys(t *testing.T) {
 		fakeShell.commandOutput["iwinfo wlan0-3 info"] = "wlan0-3\nESSID: \"4444\"\n"
 		fakeShell.commandOutput["iwinfo wlan0-4 info"] = "wlan0-4\nESSID: \"5555\"\n"
 		fakeShell.commandOutput["iwinfo wlan0-5 info"] = "wlan0-5\nESSID: \"no-team-6\"\n"
-	}()
+	}
 	assert.Nil(t, radio.handleConfigurationRequest(dummyRequest1))
 	assert.Equal(t, 18, fakeTree.setCount)
 	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[1].ssid"], "no-team-1")
@@ -329,7 +342,7 @@ func TestRadio_handleConfigurationRequestLinksys(t *testing.T) {
 	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[6].ssid"], "no-team-6")
 	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[6].key"], "no-team-6")
 	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[6].network"], "vlan60")
-	assert.Equal(t, 6, fakeTree.commitCount)
+	assert.Equal(t, 1, fakeTree.commitCount)
 	assert.Equal(t, 7, len(fakeShell.commandsRun))
 	assert.Contains(t, fakeShell.commandsRun, "wifi reload radio0")
 	assert.Contains(t, fakeShell.commandsRun, "iwinfo wlan0 info")
@@ -360,7 +373,7 @@ func TestRadio_handleConfigurationRequestSpareVlans(t *testing.T) {
 	request := ConfigurationRequest{
 		RedVlans:  Vlans708090,
 		BlueVlans: Vlans102030,
-		StationConfigurations: map[string]StationConfiguration{
+		StationConfigurations: map[string]*StationConfiguration{
 			"red1":  {Ssid: "1111", WpaKey: "11111111"},
 			"red3":  {Ssid: "3333", WpaKey: "33333333"},
 			"blue2": {Ssid: "5555", WpaKey: "55555555"},
@@ -368,7 +381,7 @@ func TestRadio_handleConfigurationRequestSpareVlans(t *testing.T) {
 		},
 	}
 	assert.Nil(t, radio.handleConfigurationRequest(request))
-	assert.Equal(t, 24, fakeTree.setCount)
+	assert.Equal(t, 42, fakeTree.setCount)
 	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[1].ssid"], "1111")
 	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[1].key"], "11111111")
 	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[1].sae_password"], "11111111")
@@ -393,7 +406,7 @@ func TestRadio_handleConfigurationRequestSpareVlans(t *testing.T) {
 	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[6].key"], "66666666")
 	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[6].sae_password"], "66666666")
 	assert.Equal(t, fakeTree.valuesFromSet["wireless.@wifi-iface[6].network"], "vlan30")
-	assert.Equal(t, 6, fakeTree.commitCount)
+	assert.Equal(t, 1, fakeTree.commitCount)
 	assert.Equal(t, 8, len(fakeShell.commandsRun))
 	assert.Contains(t, fakeShell.commandsRun, "wifi reload wifi1")
 	assert.Contains(t, fakeShell.commandsRun, "iwinfo ath1 info")
@@ -411,6 +424,281 @@ func TestRadio_handleConfigurationRequestSpareVlans(t *testing.T) {
 	assert.Equal(t, "6666", radio.StationStatuses["blue3"].Ssid)
 }
 
+func TestRadio_handleConfigurationRequestCountry(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeTree.valuesForGet["system.@system[0].model"] = "VH-109(AP)"
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	wifiReloadBackoffDuration = 10 * time.Millisecond
+	fakeShell.commandOutput["cat /etc/vh_firmware"] = ""
+	radio := NewRadio()
+
+	fakeShell.commandOutput["wifi reload wifi1"] = ""
+	for _, iface := range []string{"ath1", "ath11", "ath12", "ath13", "ath14", "ath15"} {
+		fakeShell.commandOutput["iwinfo "+iface+" info"] = iface + "\nESSID: \"no-team-1\"\n"
+	}
+	assert.Nil(t, radio.handleConfigurationRequest(ConfigurationRequest{Country: "GB"}))
+	assert.Equal(t, "GB", fakeTree.valuesFromSet["wireless.wifi1.country"])
+	assert.Equal(t, "GB", radio.Country)
+}
+
+func TestRadio_handleConfigurationRequestPreserveOmittedStations(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeTree.valuesForGet["system.@system[0].model"] = "VH-109(AP)"
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	wifiReloadBackoffDuration = 10 * time.Millisecond
+	fakeShell.commandOutput["cat /etc/vh_firmware"] = ""
+	radio := NewRadio()
+	radio.StationStatuses["red1"] = &NetworkStatus{Ssid: "1111"}
+	radio.StationStatuses["red2"] = &NetworkStatus{Ssid: "2222"}
+	fakeTree.valuesForGet["wireless.@wifi-iface[2].key"] = "22222222"
+
+	// Clear red1 and assign blue3 without specifying the other stations.
+	fakeShell.commandOutput["wifi reload wifi1"] = ""
+	fakeShell.commandOutput["iwinfo ath1 info"] = "ath1\nESSID: \"no-team-1\"\n"
+	fakeShell.commandOutput["iwinfo ath11 info"] = "ath11\nESSID: \"2222\"\n"
+	fakeShell.commandOutput["iwinfo ath12 info"] = "ath12\nESSID: \"no-team-3\"\n"
+	fakeShell.commandOutput["iwinfo ath13 info"] = "ath13\nESSID: \"no-team-4\"\n"
+	fakeShell.commandOutput["iwinfo ath14 info"] = "ath14\nESSID: \"no-team-5\"\n"
+	fakeShell.commandOutput["iwinfo ath15 info"] = "ath15\nESSID: \"6666\"\n"
+	request := ConfigurationRequest{
+		StationConfigurations: map[string]*StationConfiguration{
+			"red1": nil, "blue3": {Ssid: "6666", WpaKey: "66666666"},
+		},
+		PreserveOmittedStations: true,
+	}
+	assert.Nil(t, radio.handleConfigurationRequest(request))
+	assert.Equal(t, "no-team-1", fakeTree.valuesFromSet["wireless.@wifi-iface[1].ssid"])
+	assert.Equal(t, "2222", fakeTree.valuesFromSet["wireless.@wifi-iface[2].ssid"])
+	assert.Equal(t, "22222222", fakeTree.valuesFromSet["wireless.@wifi-iface[2].key"])
+	assert.Equal(t, "no-team-3", fakeTree.valuesFromSet["wireless.@wifi-iface[3].ssid"])
+	assert.Equal(t, "6666", fakeTree.valuesFromSet["wireless.@wifi-iface[6].ssid"])
+	assert.Equal(t, "66666666", fakeTree.valuesFromSet["wireless.@wifi-iface[6].key"])
+	assert.Nil(t, radio.StationStatuses["red1"])
+	assert.Equal(t, "2222", radio.StationStatuses["red2"].Ssid)
+	assert.Equal(t, "6666", radio.StationStatuses["blue3"].Ssid)
+	assert.Equal(t, statusActive, radio.Status)
+}
+
+func TestRadio_handleConfigurationRequestChangesOnly(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeTree.valuesForGet["system.@system[0].model"] = "VH-109(AP)"
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	wifiReloadBackoffDuration = 10 * time.Millisecond
+	fakeShell.commandOutput["cat /etc/vh_firmware"] = ""
+	radio := NewRadio()
+	radio.StationStatuses["red2"] = &NetworkStatus{Ssid: "2222"}
+	radio.StationStatuses["blue3"] = &NetworkStatus{Ssid: "6666"}
+	fakeTree.valuesForGet["wireless.@wifi-iface[2].key"] = "22222222"
+	fakeTree.valuesForGet["wireless.@wifi-iface[6].key"] = "66666666"
+
+	// Changing only the channel leaves the assigned stations as they are.
+	desired := radio.EffectiveConfiguration()
+	desired.Channel = 5
+	fakeShell.commandOutput["wifi reload wifi1"] = ""
+	fakeShell.commandOutput["iwinfo ath1 info"] = "ath1\nESSID: \"no-team-1\"\n"
+	fakeShell.commandOutput["iwinfo ath11 info"] = "ath11\nESSID: \"2222\"\n"
+	fakeShell.commandOutput["iwinfo ath12 info"] = "ath12\nESSID: \"no-team-3\"\n"
+	fakeShell.commandOutput["iwinfo ath13 info"] = "ath13\nESSID: \"no-team-4\"\n"
+	fakeShell.commandOutput["iwinfo ath14 info"] = "ath14\nESSID: \"no-team-5\"\n"
+	fakeShell.commandOutput["iwinfo ath15 info"] = "ath15\nESSID: \"6666\"\n"
+	changes := radio.ConfigurationChanges(desired)
+	assert.Nil(t, changes.StationConfigurations)
+	assert.Nil(t, changes.Validate(radio))
+	assert.Nil(t, radio.handleConfigurationRequest(changes))
+	assert.Equal(t, "5", fakeTree.valuesFromSet["wireless.wifi1.channel"])
+	assert.Equal(t, "2222", fakeTree.valuesFromSet["wireless.@wifi-iface[2].ssid"])
+	assert.Equal(t, "22222222", fakeTree.valuesFromSet["wireless.@wifi-iface[2].key"])
+	assert.Equal(t, "6666", fakeTree.valuesFromSet["wireless.@wifi-iface[6].ssid"])
+	assert.Equal(t, "66666666", fakeTree.valuesFromSet["wireless.@wifi-iface[6].key"])
+	assert.Equal(t, "2222", radio.StationStatuses["red2"].Ssid)
+	assert.Equal(t, "6666", radio.StationStatuses["blue3"].Ssid)
+	assert.Equal(t, statusActive, radio.Status)
+
+	// Such a request doesn't supersede an earlier one that configures stations.
+	full := ConfigurationRequest{
+		Channel:               149,
+		StationConfigurations: map[string]*StationConfiguration{"red1": {Ssid: "1111", WpaKey: "11111111"}},
+	}
+	assert.False(t, changes.supersedes(full))
+}
+
+func TestRadio_handleConfigurationRequestRecordsOutcomes(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeTree.valuesForGet["system.@system[0].model"] = "VH-109(AP)"
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	wifiReloadBackoffDuration = 10 * time.Millisecond
+	fakeShell.commandOutput["cat /etc/vh_firmware"] = ""
+	radio := NewRadio()
+	fakeShell.commandOutput["wifi reload wifi1"] = ""
+	fakeShell.commandOutput["iwinfo ath1 info"] = "ath1\nESSID: \"no-team-1\"\n"
+	fakeShell.commandOutput["iwinfo ath11 info"] = "ath11\nESSID: \"no-team-2\"\n"
+	fakeShell.commandOutput["iwinfo ath12 info"] = "ath12\nESSID: \"no-team-3\"\n"
+	fakeShell.commandOutput["iwinfo ath13 info"] = "ath13\nESSID: \"no-team-4\"\n"
+	fakeShell.commandOutput["iwinfo ath14 info"] = "ath14\nESSID: \"5555\"\n"
+	fakeShell.commandOutput["iwinfo ath15 info"] = "ath15\nESSID: \"6666\"\n"
+	fakeShell.commandOutput["sh -c "+hostapdSetKeyScript+" ath15 wpa_passphrase @wifi-iface[6]"] = "OK"
+	fakeShell.commandOutput["sh -c "+hostapdSetKeyScript+" ath15 sae_password @wifi-iface[6]"] = "OK"
+	fakeShell.commandOutput["hostapd_cli -i ath15 reload_wpa_psk"] = "OK"
+
+	// The first request is superseded by the second, while the single-station change is applied after it.
+	supersededId, _ := radio.EnqueueConfigurationRequest(ConfigurationRequest{
+		StationConfigurations: map[string]*StationConfiguration{"red1": {Ssid: "1111", WpaKey: "11111111"}},
+	})
+	fullId, _ := radio.EnqueueConfigurationRequest(ConfigurationRequest{
+		Channel: 5,
+		StationConfigurations: map[string]*StationConfiguration{
+			"blue2": {Ssid: "5555", WpaKey: "55555555"}, "blue3": {Ssid: "6666", WpaKey: "66666666"},
+		},
+		Metadata: map[string]string{"eventCode": "2024casj", "matchNumber": "12"},
+	})
+	cancelledId, _ := radio.EnqueueConfigurationRequest(ConfigurationRequest{Channel: 9})
+	partialId, _ := radio.EnqueueConfigurationRequest(ConfigurationRequest{
+		StationConfigurations:   map[string]*StationConfiguration{"blue3": {Ssid: "6666", WpaKey: "77777777"}},
+		PreserveOmittedStations: true,
+		Metadata:                map[string]string{"eventCode": "2024casj", "matchNumber": "12", "operator": "FTA"},
+	})
+	assert.Nil(t, radio.CancelConfigurationRequest(cancelledId))
+	assert.Nil(t, radio.handleConfigurationRequest(<-radio.ConfigurationRequestChannel))
+	assert.Equal(t, 0, len(radio.ConfigurationRequestChannel))
+	assert.Equal(t, "5", fakeTree.valuesFromSet["wireless.wifi1.channel"])
+	assert.Equal(t, "no-team-1", fakeTree.valuesFromSet["wireless.@wifi-iface[1].ssid"])
+	assert.Equal(t, "5555", fakeTree.valuesFromSet["wireless.@wifi-iface[5].ssid"])
+	assert.Equal(t, "77777777", fakeTree.valuesFromSet["wireless.@wifi-iface[6].key"])
+	assert.Equal(t, statusActive, radio.Status)
+
+	records := radio.GetConfigurationRequests()
+	if assert.Equal(t, 4, len(records)) {
+		assert.Equal(t, supersededId, records[0].Id)
+		assert.Equal(t, requestStateSuperseded, records[0].State)
+		assert.Equal(t, fullId, records[0].SupersededBy)
+		assert.Equal(t, requestStateApplied, records[1].State)
+		assert.Equal(t, map[string]string{"eventCode": "2024casj", "matchNumber": "12"}, records[1].Metadata)
+		assert.Equal(t, requestStateCancelled, records[2].State)
+		assert.Equal(t, partialId, records[3].Id)
+		assert.Equal(t, requestStateApplied, records[3].State)
+		for _, record := range records {
+			assert.NotNil(t, record.FinishedAt)
+		}
+	}
+
+	// The status carries the metadata of the most recently applied request.
+	expectedMetadata := map[string]string{"eventCode": "2024casj", "matchNumber": "12", "operator": "FTA"}
+	assert.Equal(t, expectedMetadata, radio.ConfigurationMetadata)
+	assert.Equal(t, expectedMetadata, radio.EffectiveConfiguration().Metadata)
+
+	// A failed request records the error and leaves the metadata of the configuration still in effect.
+	fakeShell.commandErrors["wifi reload wifi1"] = errors.New("oops")
+	failedId, _ := radio.EnqueueConfigurationRequest(
+		ConfigurationRequest{Channel: 5, Metadata: map[string]string{"matchNumber": "13"}},
+	)
+	err := radio.handleConfigurationRequest(<-radio.ConfigurationRequestChannel)
+	if assert.NotNil(t, err) {
+		record, _ := radio.GetConfigurationRequest(failedId)
+		assert.Equal(t, requestStateFailed, record.State)
+		assert.Equal(t, err.Error(), record.Error)
+		assert.Equal(t, map[string]string{"matchNumber": "13"}, record.Metadata)
+	}
+	assert.Equal(t, expectedMetadata, radio.ConfigurationMetadata)
+}
+
+func TestRadio_handleConfigurationRequestRejectedSupersedingRequest(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeTree.valuesForGet["system.@system[0].model"] = "VH-109(AP)"
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	wifiReloadBackoffDuration = 10 * time.Millisecond
+	fakeShell.commandOutput["cat /etc/vh_firmware"] = ""
+	radio := NewRadio()
+	radio.Channel = 36
+	settings := radio.GetSettings()
+	settings.ChannelChangeGuard = channelChangeGuardReject
+	radio.SetSettings(settings)
+	fakeShell.commandOutput["iwinfo ath15 scan"] = ""
+	fakeShell.commandOutput["iw dev ath15 survey dump"] = testSurveyDump
+	fakeShell.commandOutput["wifi reload wifi1"] = ""
+	fakeShell.commandOutput["iwinfo ath1 info"] = "ath1\nESSID: \"no-team-1\"\n"
+	fakeShell.commandOutput["iwinfo ath11 info"] = "ath11\nESSID: \"no-team-2\"\n"
+	fakeShell.commandOutput["iwinfo ath12 info"] = "ath12\nESSID: \"no-team-3\"\n"
+	fakeShell.commandOutput["iwinfo ath13 info"] = "ath13\nESSID: \"no-team-4\"\n"
+	fakeShell.commandOutput["iwinfo ath14 info"] = "ath14\nESSID: \"no-team-5\"\n"
+	fakeShell.commandOutput["iwinfo ath15 info"] = "ath15\nESSID: \"no-team-6\"\n"
+
+	// The later request would supersede the earlier one, but the guard rejects it, so the earlier one is applied.
+	earlierId, _ := radio.EnqueueConfigurationRequest(ConfigurationRequest{Channel: 44})
+	laterId, _ := radio.EnqueueConfigurationRequest(ConfigurationRequest{Channel: 149})
+	assert.True(t, ConfigurationRequest{Channel: 149}.supersedes(ConfigurationRequest{Channel: 44}))
+	err := radio.handleConfigurationRequest(<-radio.ConfigurationRequestChannel)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "channel 149 is busier than current channel 36")
+	}
+	record, _ := radio.GetConfigurationRequest(earlierId)
+	assert.Equal(t, requestStateApplied, record.State)
+	assert.Zero(t, record.SupersededBy)
+	record, _ = radio.GetConfigurationRequest(laterId)
+	assert.Equal(t, requestStateFailed, record.State)
+	assert.Equal(t, "44", fakeTree.valuesFromSet["wireless.wifi1.channel"])
+	assert.Equal(t, 44, radio.Channel)
+	assert.Equal(t, statusActive, radio.Status)
+
+	// The guard is only checked once for the rejected request.
+	if alerts := radio.GetAlerts(); assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "CHANNEL_CHANGE_REJECTED", alerts[0].Type)
+	}
+}
+
+func TestRadio_handleConfigurationRequestHeOptions(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeTree.valuesForGet["system.@system[0].model"] = "VH-109(AP)"
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	wifiReloadBackoffDuration = 10 * time.Millisecond
+	fakeShell.commandOutput["cat /etc/vh_firmware"] = ""
+	radio := NewRadio()
+
+	fakeShell.commandOutput["wifi reload wifi1"] = ""
+	for _, iface := range []string{"ath1", "ath11", "ath12", "ath13", "ath14", "ath15"} {
+		fakeShell.commandOutput["iwinfo "+iface+" info"] = iface + "\nESSID: \"no-team-1\"\n"
+	}
+	enabled := true
+	request := ConfigurationRequest{BssColor: 12, HeGuardInterval: "1.6us", TargetWakeTime: &enabled}
+	assert.Nil(t, radio.handleConfigurationRequest(request))
+	assert.Equal(t, "12", fakeTree.valuesFromSet["wireless.wifi1.he_bss_color"])
+	assert.Equal(t, "1.6us", fakeTree.valuesFromSet["wireless.wifi1.he_gi"])
+	assert.Equal(t, "1", fakeTree.valuesFromSet["wireless.wifi1.he_twt"])
+	assert.Equal(t, 12, radio.BssColor)
+	assert.Equal(t, "1.6us", radio.HeGuardInterval)
+	assert.True(t, radio.TargetWakeTime)
+
+	// Options that are omitted are left unchanged.
+	fakeTree.reset()
+	enabled = false
+	request = ConfigurationRequest{TargetWakeTime: &enabled}
+	assert.Nil(t, radio.handleConfigurationRequest(request))
+	assert.Equal(t, "", fakeTree.valuesFromSet["wireless.wifi1.he_bss_color"])
+	assert.Equal(t, "0", fakeTree.valuesFromSet["wireless.wifi1.he_twt"])
+	assert.Equal(t, 12, radio.BssColor)
+	assert.False(t, radio.TargetWakeTime)
+
+	// Initial state is read back from the configuration.
+	fakeTree.valuesForGet["wireless.wifi1.he_bss_color"] = "33"
+	fakeTree.valuesForGet["wireless.wifi1.he_gi"] = "3.2us"
+	fakeTree.valuesForGet["wireless.wifi1.he_twt"] = "1"
+	radio.setInitialState()
+	assert.Equal(t, 33, radio.BssColor)
+	assert.Equal(t, "3.2us", radio.HeGuardInterval)
+	assert.True(t, radio.TargetWakeTime)
+}
+
 func TestRadio_handleConfigurationRequestErrors(t *testing.T) {
 	fakeTree := newFakeUciTree()
 	uciTree = fakeTree
@@ -471,12 +759,26 @@ func TestRadio_handleConfigurationRequestErrors(t *testing.T) {
 	fakeShell.commandOutput["iwinfo ath13 info"] = "ath13\nESSID: \"no-team-4\"\n"
 	fakeShell.commandOutput["iwinfo ath14 info"] = "ath14\nESSID: \"no-team-5\"\n"
 	fakeShell.commandOutput["iwinfo ath15 info"] = "ath15\nESSID: \"no-team-6\"\n"
-	go func() {
-		time.Sleep(100 * time.Millisecond)
-		fakeShell.commandOutput["iwinfo ath1 info"] = "ath1\nESSID: \"no-team-1\"\n"
-	}()
+	fakeShell.onRunCommand = func(command string) {
+		if command == "wifi reload wifi1" && fakeTree.commitCount == 2 {
+			fakeShell.commandOutput["iwinfo ath1 info"] = "ath1\nESSID: \"no-team-1\"\n"
+		}
+	}
 	assert.Nil(t, radio.handleConfigurationRequest(request))
-	assert.Greater(t, fakeTree.commitCount, 20)
+	assert.Equal(t, 2, fakeTree.commitCount)
+}
+
+func TestRadio_hasLinkedClients(t *testing.T) {
+	radio := &Radio{
+		settings: defaultSettings(), StationStatuses: map[string]*NetworkStatus{"red1": nil, "blue2": {Ssid: "5555"}},
+	}
+	assert.False(t, radio.hasLinkedClients())
+	assert.Equal(t, 5*time.Second, radio.monitoringPollInterval(time.Now()))
+	radio.StationStatuses["blue2"].IsLinked = true
+	assert.True(t, radio.hasLinkedClients())
+	assert.Equal(t, 2*time.Second, radio.monitoringPollInterval(time.Now()))
+	radio.StationStatuses["blue2"].IsLinked = false
+	assert.Equal(t, 5*time.Second, radio.monitoringPollInterval(time.Now()))
 }
 
 func TestRadio_updateMonitoring(t *testing.T) {
@@ -499,6 +801,10 @@ func TestRadio_updateMonitoring(t *testing.T) {
 		"\tRX: 550.6 MBit/s                                4095 Pkts.\n" +
 		"\tTX: 254.0 MBit/s                                   0 Pkts.\n" +
 		"\texpected throughput: unknown"
+	fakeShell.commandOutput["iw dev wlan0 station dump"] = "Station 48:da:35:b0:00:cf (on wlan0)\n" +
+		"\trx packets:\t4095\n\ttx packets:\t1000\n\ttx retries:\t25\n\ttx failed:\t1\n\trx drop misc:\t3\n"
+	fakeShell.commandOutput["hostapd_cli -i wlan0 sta 48:da:35:b0:00:cf"] = "48:da:35:b0:00:cf\n" +
+		"flags=[AUTH][ASSOC][AUTHORIZED][WMM][HT][VHT]\nht_caps_info=0x09ef\nvht_caps_info=0x338b79b2\n"
 	fakeShell.commandOutput["ifconfig wlan0"] = "wlan0\tLink encap:Ethernet  HWaddr 00:00:00:00:00:00\n" +
 		"\tRX bytes:12345 (12.3 KiB)  TX bytes:98765 (98.7 KiB)"
 	fakeShell.commandOutput["luci-bwc -i wlan0-2"] = "[ 1687496917, 26097, 177, 70454, 846 ],\n" +
@@ -513,6 +819,7 @@ func TestRadio_updateMonitoring(t *testing.T) {
 	fakeShell.commandOutput["luci-bwc -i wlan0-4"] = ""
 	fakeShell.commandErrors["iwinfo wlan0-4 assoclist"] = errors.New("oops")
 	fakeShell.commandErrors["ifconfig wlan0-4"] = errors.New("oops")
+	fakeShell.commandOutput["logread -l 200 -e hostapd"] = ""
 	radio.updateMonitoring()
 	assert.True(t, radio.StationStatuses["red1"].IsLinked)
 	assert.Equal(t, 550.6, radio.StationStatuses["red1"].RxRateMbps)
@@ -520,6 +827,8 @@ func TestRadio_updateMonitoring(t *testing.T) {
 	assert.Equal(t, 12345, radio.StationStatuses["red1"].RxBytes)
 	assert.Equal(t, 98765, radio.StationStatuses["red1"].TxBytes)
 	assert.Equal(t, "excellent", radio.StationStatuses["red1"].ConnectionQuality)
+	assert.Equal(t, 25, radio.StationStatuses["red1"].TxRetries)
+	assert.Equal(t, "VHT", radio.StationStatuses["red1"].ClientCapabilities.BestMode)
 	assert.Equal(
 		t,
 		NetworkStatus{
@@ -541,14 +850,25 @@ func TestRadio_updateMonitoring(t *testing.T) {
 		},
 		*radio.StationStatuses["blue2"],
 	)
-	assert.Equal(t, 9, len(fakeShell.commandsRun))
+	assert.Equal(t, 12, len(fakeShell.commandsRun))
 	assert.Contains(t, fakeShell.commandsRun, "luci-bwc -i wlan0")
 	assert.Contains(t, fakeShell.commandsRun, "iwinfo wlan0 assoclist")
 	assert.Contains(t, fakeShell.commandsRun, "ifconfig wlan0")
+	assert.Contains(t, fakeShell.commandsRun, "iw dev wlan0 station dump")
 	assert.Contains(t, fakeShell.commandsRun, "luci-bwc -i wlan0-2")
 	assert.Contains(t, fakeShell.commandsRun, "iwinfo wlan0-2 assoclist")
 	assert.Contains(t, fakeShell.commandsRun, "ifconfig wlan0-2")
 	assert.Contains(t, fakeShell.commandsRun, "luci-bwc -i wlan0-4")
 	assert.Contains(t, fakeShell.commandsRun, "iwinfo wlan0-4 assoclist")
 	assert.Contains(t, fakeShell.commandsRun, "ifconfig wlan0-4")
+	assert.Contains(t, fakeShell.commandsRun, "logread -l 200 -e hostapd")
+}
+
+func TestRadio_monitoredNetworks(t *testing.T) {
+	radio := &Radio{StationStatuses: map[string]*NetworkStatus{"red1": nil, "blue2": {Ssid: "254", SignalDbm: -53}}}
+	radio.recordMonitoringSample()
+	samples := radio.GetMonitoringHistory(-1)
+	if assert.Equal(t, 1, len(samples)) {
+		assert.Equal(t, map[string]NetworkSample{"blue2": {Ssid: "254", SignalDbm: -53}}, samples[0].Networks)
+	}
 }
@@ -3,6 +3,7 @@ package radio
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"github.com/digineo/go-uci"
 	"log"
@@ -16,9 +17,12 @@ const (
 	// How frequently to poll the radio while waiting for it to finish starting up.
 	bootPollIntervalSec = 3
 
-	// How frequently to poll the radio for its current status between configurations.
+	// Default for how frequently to poll the radio for its current status between configurations while it is idle.
 	monitoringPollIntervalSec = 5
 
+	// Default for how frequently to poll the radio while it is active, if adaptive polling is enabled.
+	activePollIntervalSec = 2
+
 	// How long to wait after reloading the Wi-Fi configuration before polling the status.
 	wifiReloadBackoffSec = 5
 
@@ -34,9 +38,6 @@ const (
 	// Maximum length for WPA keys.
 	maxWpaKeyLength = 16
 
-	// Regex to validate a string as alphanumeric.
-	alphanumericRegex = "^[a-zA-Z0-9]*$"
-
 	// Valid characters in the randomly generated salt used to obscure the WPA key.
 	saltCharacters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
@@ -63,36 +64,91 @@ const (
 	statusConfiguring radioStatus = "CONFIGURING"
 	statusActive      radioStatus = "ACTIVE"
 	statusError       radioStatus = "ERROR"
+
+	// The wireless configuration doesn't have the layout the API expects, so configuration requests are rejected.
+	statusMisconfiguredBaseline radioStatus = "MISCONFIGURED_BASELINE"
+
+	// The radio is freshly flashed and waiting to be provisioned via POST /provision before it starts up.
+	statusProvisioning radioStatus = "PROVISIONING"
 )
 
-var uciTree = uci.NewTree(uci.DefaultTreePath)
+var uciTree uci.Tree = newUciStagingTree(uci.NewTree(uci.DefaultTreePath))
 var shell shellWrapper = execShell{}
-var ssidRe = regexp.MustCompile("ESSID: \"([-\\w ]*)\"")
+var ssidRe = regexp.MustCompile(`ESSID: "(.*)"`)
+var bssidRe = regexp.MustCompile(`Access Point: ((?:[0-9A-Fa-f]{2}:){5}[0-9A-Fa-f]{2})`)
 var retryBackoffDuration = retryBackoffSec * time.Second
 var wifiReloadBackoffDuration = wifiReloadBackoffSec * time.Second
 
 // Run loops indefinitely, handling configuration requests and polling the Wi-Fi status.
 func (radio *Radio) Run() {
-	for !radio.isStarted() {
-		log.Println("Waiting for radio to finish starting up...")
-		time.Sleep(bootPollIntervalSec * time.Second)
-	}
+	go radio.watchMaintenanceButton()
+	radio.recordBoot()
+	radio.waitForValidBaseline()
+	radio.waitForProvisioning()
+	radio.waitForStartup()
+	radio.assertBootConfiguration()
 	log.Println("Radio ready.")
 
 	radio.setInitialState()
-	radio.Status = statusActive
+	radio.loadConfigurationStats()
+	radio.updateRegulatoryViolations()
+	radio.startUciWatcher()
+	radio.startStatusBeacon()
+	radio.startControlJitterSampler()
+	radio.startRobotSyslogReceiver()
+	radio.MonitoredAt = newTimestamp()
+	radio.TimeSync = getTimeSyncStatus()
+	radio.setStatus(statusActive)
+	radio.markStatusChanged()
 
+	radio.isLoopRunning.Store(true)
 	for {
 		// Check if there are any pending configuration requests; if not, periodically poll Wi-Fi status.
 		select {
 		case request := <-radio.ConfigurationRequestChannel:
 			_ = radio.handleConfigurationRequest(request)
-		case <-time.After(monitoringPollIntervalSec * time.Second):
+			radio.updateRegulatoryViolations()
+		case config := <-radio.uciChanges:
+			radio.handleUciChange(config)
+		case request := <-radio.handoverChannel:
+			radio.handleHandoverRequest(request)
+		case <-time.After(radio.monitoringPollInterval(time.Now())):
 			radio.updateMonitoring()
+			radio.updateStorageHealth()
+			radio.updateUptime()
+			radio.MonitoredAt = newTimestamp()
+			radio.TimeSync = getTimeSyncStatus()
+			radio.recordMonitoringSample()
 		}
+		radio.markStatusChanged()
 	}
 }
 
+// MarshalStatus returns the radio status as indented JSON. The parts of the status that are updated from other
+// goroutines are captured under their mutexes.
+func (radio *Radio) MarshalStatus() ([]byte, error) {
+	unlock := radio.lockStatus()
+	defer unlock()
+	return json.MarshalIndent(radio, "", "  ")
+}
+
+// monitoringPollInterval returns how long to wait before the next monitoring poll as of the given time. If adaptive
+// polling is enabled, the radio is polled faster while any station has a linked client or shortly after a
+// configuration, so that telemetry is fresh during matches without loading the radio between them.
+func (radio *Radio) monitoringPollInterval(now time.Time) time.Duration {
+	settings := radio.GetSettings()
+	idleInterval := time.Duration(settings.MonitoringPollIntervalSec) * time.Second
+	polling := settings.AdaptivePolling
+	if polling.ActiveIntervalSec == 0 {
+		return idleInterval
+	}
+	configurationWindow := time.Duration(polling.ConfigurationWindowSec) * time.Second
+	if radio.hasLinkedClients() || now.Sub(radio.lastConfiguredAt) < configurationWindow {
+		return time.Duration(polling.ActiveIntervalSec) * time.Second
+	}
+	return idleInterval
+}
+
 // TriggerFirmwareUpdate initiates the firmware update process using the given firmware file. This method may not return
 // cleanly even if successful since the update utility will terminate this process.
 func TriggerFirmwareUpdate(firmwarePath string) {
@@ -108,6 +164,7 @@ func TriggerFirmwareUpdate(firmwarePath string) {
 		)
 	}
 
+	recordShutdownIntent(rebootCauseSysupgrade)
 	if err := shell.startCommand("sysupgrade", "-n", firmwarePath); err != nil {
 		log.Printf("Error running sysupgrade: %v", err)
 	}
@@ -132,23 +189,15 @@ func (radio *Radio) determineAndSetVersion() {
 	}
 }
 
-func (radio *Radio) handleConfigurationRequest(request ConfigurationRequest) error {
-	// If there are multiple requests queued up, only consider the latest one.
-	numExtraRequests := len(radio.ConfigurationRequestChannel)
-	for i := 0; i < numExtraRequests; i++ {
-		request = <-radio.ConfigurationRequestChannel
-	}
+// StatusRevision returns a counter that changes whenever the externally visible state of the radio may have changed,
+// so that callers can cache derived representations of it.
+func (radio *Radio) StatusRevision() uint64 {
+	return radio.statusRevision.Load()
+}
 
-	radio.Status = statusConfiguring
-	log.Printf("Processing configuration request: %+v", request)
-	if err := radio.configure(request); err != nil {
-		log.Printf("Error configuring radio: %v", err)
-		radio.Status = statusError
-		return err
-	} else if len(radio.ConfigurationRequestChannel) == 0 {
-		radio.Status = statusActive
-	}
-	return nil
+// markStatusChanged invalidates any cached representations of the radio state.
+func (radio *Radio) markStatusChanged() {
+	radio.statusRevision.Add(1)
 }
 
 // getHashedWpaKeyAndSalt fetches the WPA key for the given station and returns its hashed value and the salt used for
@@ -158,32 +207,48 @@ func (radio *Radio) getHashedWpaKeyAndSalt(position int) (string, string) {
 	if !ok {
 		return "", ""
 	}
-	// Generate a random string of 16 characters to use as the salt.
+	salt := newWpaKeySalt()
+	return hashWpaKey(wpaKey, salt), salt
+}
+
+// newWpaKeySalt returns a random string of 16 characters to use as the salt when hashing a WPA key.
+func newWpaKeySalt() string {
 	saltBytes := make([]byte, saltLength)
 	for i := 0; i < saltLength; i++ {
 		saltBytes[i] = saltCharacters[rand.Intn(len(saltCharacters))]
 	}
-	salt := string(saltBytes)
+	return string(saltBytes)
+}
+
+// hashWpaKey returns the hex-encoded SHA-256 hash of the given WPA key combined with the given salt.
+func hashWpaKey(wpaKey, salt string) string {
 	hash := sha256.New()
 	hash.Write([]byte(wpaKey + salt))
-	hashedWpaKey := hex.EncodeToString(hash.Sum(nil))
-
-	return hashedWpaKey, salt
+	return hex.EncodeToString(hash.Sum(nil))
 }
 
 // getSsid fetches the post-configuration SSID of the given Wi-Fi interface using 'iwinfo info'.
 func getSsid(wifiInterface string) (string, error) {
+	ssid, _, err := getSsidAndBssid(wifiInterface)
+	return ssid, err
+}
+
+// getSsidAndBssid fetches the post-configuration SSID and BSSID of the given Wi-Fi interface using 'iwinfo info'. The
+// BSSID is blank if iwinfo doesn't report one.
+func getSsidAndBssid(wifiInterface string) (string, string, error) {
 	output, err := shell.runCommand("iwinfo", wifiInterface, "info")
 	if err != nil {
-		return "", fmt.Errorf("error getting iwinfo for interface %s: %v", wifiInterface, err)
-	} else {
-		matches := ssidRe.FindStringSubmatch(output)
-		if len(matches) > 0 {
-			return matches[1], nil
-		} else {
-			return "", fmt.Errorf("error parsing iwinfo output for interface %s: %s", wifiInterface, output)
-		}
+		return "", "", fmt.Errorf("error getting iwinfo for interface %s: %w", wifiInterface, err)
+	}
+	matches := ssidRe.FindStringSubmatch(output)
+	if len(matches) == 0 {
+		return "", "", fmt.Errorf("error parsing iwinfo output for interface %s: %s", wifiInterface, output)
+	}
+	var bssid string
+	if bssidMatches := bssidRe.FindStringSubmatch(output); len(bssidMatches) > 0 {
+		bssid = bssidMatches[1]
 	}
+	return matches[1], bssid, nil
 }
 
 // isValid6GhzChannel returns true if the given channel is a valid 6GHz channel.
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestTriggerFirmwareUpdate(t *testing.T) {
@@ -84,3 +85,33 @@ func TestRadio_determineAndSetVersion(t *testing.T) {
 	radio.determineAndSetVersion()
 	assert.Equal(t, "unknown", radio.Version)
 }
+
+func TestRadio_StatusRevision(t *testing.T) {
+	radio := Radio{}
+	assert.Equal(t, uint64(0), radio.StatusRevision())
+	radio.markStatusChanged()
+	assert.Equal(t, uint64(1), radio.StatusRevision())
+	radio.markStatusChanged()
+	assert.Equal(t, uint64(2), radio.StatusRevision())
+}
+
+func TestRadio_monitoringPollInterval(t *testing.T) {
+	radio := &Radio{settings: defaultSettings()}
+	now := time.Now()
+
+	// Idle since no configuration has been applied and nothing is linked.
+	assert.Equal(t, 5*time.Second, radio.monitoringPollInterval(now))
+
+	// Polls faster for a while after a configuration, then relaxes again.
+	radio.lastConfiguredAt = now.Add(-time.Minute)
+	assert.Equal(t, 2*time.Second, radio.monitoringPollInterval(now))
+	radio.lastConfiguredAt = now.Add(-2 * time.Minute)
+	assert.Equal(t, 5*time.Second, radio.monitoringPollInterval(now))
+
+	// Always polls at the idle interval if adaptive polling is disabled.
+	radio.lastConfiguredAt = now
+	settings := radio.GetSettings()
+	settings.AdaptivePolling.ActiveIntervalSec = 0
+	radio.SetSettings(settings)
+	assert.Equal(t, 5*time.Second, radio.monitoringPollInterval(now))
+}
@@ -9,6 +9,8 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -58,11 +60,141 @@ type Radio struct {
 	// Enum representing the current configuration stage of the radio.
 	Status radioStatus `json:"status"`
 
+	// Time at which the status last changed.
+	StatusChangedAt Timestamp `json:"statusChangedAt"`
+
+	// Most recent changes of the status, oldest first.
+	StatusTransitions []StatusTransition `json:"statusTransitions"`
+
+	// Progress of the configuration request currently being applied, or of the last one applied if none is. Null if
+	// none has been applied since the API started.
+	ConfigurationTimeline *ConfigurationTimeline `json:"configurationTimeline"`
+
+	// Most recent failure to apply a configuration request, or null if the last request to be applied succeeded.
+	LastError *ConfigurationError `json:"lastError"`
+
 	// Version of the radio software.
 	Version string `json:"version"`
 
+	// Problems found with the layout of the wireless configuration, which prevent the radio from being configured.
+	// Empty unless the status is MISCONFIGURED_BASELINE.
+	BaselineProblems []string `json:"baselineProblems,omitempty"`
+
+	// Progress of the boot watchdog's attempts to recover the radio if it didn't finish starting up in time. Nil if no
+	// recovery was needed.
+	BootRecovery *BootRecoveryStatus `json:"bootRecovery,omitempty"`
+
+	// Source and voltage of the radio's power input, and any brownouts seen. Nil if the hardware doesn't report them.
+	Power *PowerStatus `json:"power,omitempty"`
+
+	// How long the radio has been up and why it last rebooted. Nil if the kernel doesn't report them.
+	Boot *BootStatus `json:"boot,omitempty"`
+
 	// Queue for receiving and buffering configuration requests.
 	ConfigurationRequestChannel chan ConfigurationRequest `json:"-"`
+
+	// Tunable parameters controlling the behavior of the API, replaced wholesale when the settings file is reloaded.
+	settings Settings
+
+	// Mutex guarding the settings, which are reloaded from the web server goroutine.
+	settingsMutex sync.RWMutex
+
+	// Whether the radio is in maintenance mode, during which changes to its configuration are rejected.
+	Maintenance MaintenanceStatus `json:"maintenance"`
+
+	// Mutex guarding the maintenance mode state, which is updated from the web server and button goroutines.
+	maintenanceMutex sync.Mutex
+
+	// Outcome of the first-boot provisioning of a freshly flashed radio.
+	Provisioning ProvisioningStatus `json:"provisioning"`
+
+	// Mutex guarding the provisioning state, which is updated from the web server goroutine.
+	provisioningMutex sync.Mutex
+
+	// Signaled when the radio is provisioned, releasing it from the PROVISIONING status.
+	provisionedChannel chan struct{}
+
+	// Time at which the monitoring data was last updated.
+	MonitoredAt Timestamp `json:"monitoredAt"`
+
+	// How well the radio's clock is synchronized, for correlating its timestamps with those of other devices.
+	TimeSync TimeSyncStatus `json:"timeSync"`
+
+	// Health of the radio's flash storage.
+	Storage StorageHealth `json:"storage"`
+
+	// Storage health and outcome of the retention policies as of their most recent enforcement.
+	storageReport StorageReport
+
+	// Mutex guarding the storage report, which is read from the web server goroutine.
+	retentionMutex sync.Mutex
+
+	// State of the check for changes made to the UCI configuration by something other than the API.
+	ConfigDrift ConfigDriftStatus `json:"configDrift"`
+
+	// Reachability of the driver station and FMS through the radio's current network path.
+	Connectivity ConnectivityStatus `json:"connectivity"`
+
+	// What has been written to the hardware status LEDs reflecting the link and field connection state.
+	linkLed  statusLed
+	fieldLed statusLed
+
+	// Mutex guarding the status LED state, which is read from the web server goroutine.
+	statusIndicatorsMutex sync.Mutex
+
+	// Whether the temperature has reached the overheating threshold without having cooled down since.
+	isOverheating bool
+
+	// Inconsistencies between the radio's configuration and the regulatory rules it is actually operating under, as of
+	// startup or the last configuration.
+	RegulatoryViolations []RegulatoryViolation `json:"regulatoryViolations"`
+
+	// Counter that is incremented whenever the externally visible state of the radio changes.
+	statusRevision atomic.Uint64
+
+	// Contents of each watched UCI configuration file as last written or adopted by the API, keyed by configuration.
+	uciSnapshots map[string][]byte
+
+	// Names of watched UCI configuration files that have been modified, as reported by the file watcher.
+	uciChanges chan string
+
+	// Most recent alerts raised by the radio.
+	alerts alertLog
+
+	// Connection over which status transitions and monitoring samples are published to co-resident daemons.
+	eventSocket eventSocketPublisher
+
+	// Worker that pushes monitoring samples to the telemetry sinks.
+	telemetry telemetryPublisher
+
+	// Link telemetry recorded at each of the most recent monitoring polls.
+	monitoringHistory monitoringHistory
+
+	// Revision of the configuration, which is the identifier of the most recently queued configuration request, for
+	// rejecting changes based on a stale read. Zero if none has been queued since the API started.
+	ConfigurationRevision int `json:"configurationRevision"`
+
+	// Opaque metadata carried by the configuration request that was most recently applied successfully (e.g. the match
+	// number and event code that the current configuration is for). Null if it carried none.
+	ConfigurationMetadata map[string]string `json:"configurationMetadata"`
+
+	// Progress and outcomes of recently queued configuration requests.
+	configurationRequests configurationRequestLog
+
+	// Counters of how configuring the radio has gone on each hardware type and firmware version it has run.
+	configurationStats configurationStatsLog
+
+	// Time at which a configuration request was last applied, used to poll faster for a while afterwards.
+	lastConfiguredAt time.Time
+
+	// Requests to hand the radio over to a new API process, handled between configurations and polls.
+	handoverChannel chan handoverRequest
+
+	// Whether the main loop is configuring and polling the radio, as opposed to waiting for it to start up.
+	isLoopRunning atomic.Bool
+
+	// Number of monitoring polls since the API started, used to schedule storage health checks.
+	storagePollCount int
 }
 
 // radioMode represents the configuration mode of the radio.
@@ -82,8 +214,12 @@ func NewRadio() *Radio {
 	radio := Radio{
 		Status:                      statusBooting,
 		ConfigurationRequestChannel: make(chan ConfigurationRequest, configurationRequestBufferSize),
+		settings:                    defaultSettings(),
+		handoverChannel:             make(chan handoverRequest),
+		provisionedChannel:          make(chan struct{}, 1),
 	}
 	radio.determineAndSetVersion()
+	radio.loadProvisioningStatus()
 
 	return &radio
 }
@@ -122,6 +258,7 @@ func (radio *Radio) setInitialState() {
 
 // configure configures the radio with the given configuration.
 func (radio *Radio) configure(request ConfigurationRequest) error {
+	radio.updateStatusIndicators()
 	retryCount := 1
 
 	for {
@@ -192,14 +329,17 @@ func (radio *Radio) configure(request ConfigurationRequest) error {
 		uciTree.SetType("dhcp", "@host[0]", "name", uci.TypeOption, fmt.Sprintf("roboRIO-%d-FRC", request.TeamNumber))
 		uciTree.SetType("dhcp", "@host[0]", "ip", uci.TypeOption, fmt.Sprintf("10.%s.2", teamPartialIp))
 
-		if err := uciTree.Commit(); err != nil {
-			return fmt.Errorf("failed to commit configuration: %v", err)
+		if err := radio.commitUci("wireless", "network", "dhcp"); err != nil {
+			return fmt.Errorf("failed to commit configuration: %w", err)
 		}
+		radio.recordConfigurationStep(configurationStepUciCommitted, retryCount)
 		if _, err := shell.runCommand("wifi", "reload"); err != nil {
-			return fmt.Errorf("failed to reload Wi-Fi configuration: %v", err)
+			return fmt.Errorf("failed to reload Wi-Fi configuration: %w", err)
 		}
+		radio.recordConfigurationStep(configurationStepWifiReloaded, retryCount)
 		time.Sleep(wifiReloadBackoffDuration)
 
+		radio.recordConfigurationStep(configurationStepVerifying, retryCount)
 		var err error
 		radio.NetworkStatus6.Ssid, err = getSsid(radioInterface6)
 		if err != nil {
@@ -216,6 +356,10 @@ func (radio *Radio) configure(request ConfigurationRequest) error {
 		}
 
 		log.Printf("Wi-Fi configuration still incorrect after %d attempts; trying again.", retryCount)
+		radio.updateConfigurationStats(func(stats *ConfigurationStats) {
+			stats.VerificationFailureCount++
+			stats.RetryCount++
+		})
 		time.Sleep(retryBackoffDuration)
 		retryCount++
 	}
@@ -223,9 +367,56 @@ func (radio *Radio) configure(request ConfigurationRequest) error {
 	return nil
 }
 
+// lockStatus acquires each mutex guarding part of the status so that it can be marshaled consistently, and returns a
+// function that releases them again.
+func (radio *Radio) lockStatus() func() {
+	radio.provisioningMutex.Lock()
+	radio.maintenanceMutex.Lock()
+	radio.configurationRequests.mutex.Lock()
+	return func() {
+		radio.configurationRequests.mutex.Unlock()
+		radio.maintenanceMutex.Unlock()
+		radio.provisioningMutex.Unlock()
+	}
+}
+
+// assertBootConfiguration does nothing on the robot radio, whose configuration is set by the kiosk or the FMS rather
+// than a baseline.
+func (radio *Radio) assertBootConfiguration() {}
+
+// startStatusBeacon does nothing on the robot radio, which has no team stations to summarize.
+func (radio *Radio) startStatusBeacon() {}
+
+// scheduleMismatches always returns nil on the robot radio, which isn't configured per match.
+func (radio *Radio) scheduleMismatches(request ConfigurationRequest) []string {
+	return nil
+}
+
+// startRobotSyslogReceiver does nothing on the robot radio, whose own logs are the ones being collected.
+func (radio *Radio) startRobotSyslogReceiver() {}
+
+// startControlJitterSampler does nothing on the robot radio, which has no team stations to measure.
+func (radio *Radio) startControlJitterSampler() {}
+
+// monitoredNetworks returns the status of each of the radio's networks, keyed by its name in the status.
+func (radio *Radio) monitoredNetworks() map[string]*NetworkStatus {
+	return map[string]*NetworkStatus{"networkStatus6": &radio.NetworkStatus6, "networkStatus24": &radio.NetworkStatus24}
+}
+
+// hasLinkedClients returns true if a device is associated with either of the radio's networks.
+func (radio *Radio) hasLinkedClients() bool {
+	return radio.NetworkStatus6.IsLinked || radio.NetworkStatus24.IsLinked
+}
+
 // updateMonitoring polls the access point for the current bandwidth usage and link state of each network and updates
 // the in-memory state.
 func (radio *Radio) updateMonitoring() {
-	radio.NetworkStatus6.updateMonitoring(radioInterface6)
-	radio.NetworkStatus24.updateMonitoring(radioInterface24)
+	// The robot radio is always Vivid-Hosting hardware.
+	enrichers := statusEnrichersFor(TypeVividHosting)
+	radio.NetworkStatus6.updateMonitoring(radioInterface6, enrichers)
+	radio.NetworkStatus24.updateMonitoring(radioInterface24, enrichers)
+	radio.updateConnectivity()
+	radio.updateStatusIndicators()
+	radio.checkTemperature()
+	radio.updatePowerStatus()
 }
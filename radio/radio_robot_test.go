@@ -135,6 +135,11 @@ func TestRadio_handleConfigurationRequest(t *testing.T) {
 	assert.Equal(t, 1, fakeTree.commitCount)
 	assert.Contains(t, fakeShell.commandsRun, "wifi reload")
 	assert.Contains(t, fakeShell.commandsRun, "iwinfo ath1 info")
+	if assert.NotNil(t, radio.ConfigurationTimeline) && assert.Equal(t, 5, len(radio.ConfigurationTimeline.Steps)) {
+		assert.Equal(t, configurationStepVerifying, radio.ConfigurationTimeline.Steps[3].Step)
+		assert.Equal(t, 1, radio.ConfigurationTimeline.Steps[3].Attempt)
+		assert.Equal(t, configurationStepApplied, radio.ConfigurationTimeline.Steps[4].Step)
+	}
 	assert.Equal(t, 12345, radio.TeamNumber)
 	assert.Equal(t, "12345", radio.NetworkStatus6.Ssid)
 	assert.Equal(
@@ -253,14 +258,33 @@ func TestRadio_handleConfigurationRequestErrors(t *testing.T) {
 	fakeShell.reset()
 	fakeShell.commandOutput["wifi reload"] = ""
 	fakeShell.commandOutput["iwinfo ath1 info"] = "ath0\nESSID: \"2\"\n"
-	go func() {
-		time.Sleep(100 * time.Millisecond)
-		fakeShell.commandOutput["iwinfo ath1 info"] = "ath0\nESSID: \"1\"\n"
-	}()
+	reloadCount := 0
+	fakeShell.onRunCommand = func(command string) {
+		if command == "wifi reload" {
+			reloadCount++
+		}
+		if reloadCount == 6 {
+			fakeShell.commandOutput["iwinfo ath1 info"] = "ath0\nESSID: \"1\"\n"
+		}
+	}
 	assert.Nil(t, radio.handleConfigurationRequest(request))
 	assert.Greater(t, fakeTree.commitCount, 5)
 }
 
+func TestRadio_hasLinkedClients(t *testing.T) {
+	radio := &Radio{settings: defaultSettings()}
+	assert.False(t, radio.hasLinkedClients())
+	assert.Equal(t, 5*time.Second, radio.monitoringPollInterval(time.Now()))
+	radio.NetworkStatus24.IsLinked = true
+	assert.True(t, radio.hasLinkedClients())
+	assert.Equal(t, 2*time.Second, radio.monitoringPollInterval(time.Now()))
+	radio.NetworkStatus24.IsLinked = false
+	radio.NetworkStatus6.IsLinked = true
+	assert.True(t, radio.hasLinkedClients())
+	radio.NetworkStatus6.IsLinked = false
+	assert.Equal(t, 5*time.Second, radio.monitoringPollInterval(time.Now()))
+}
+
 func TestRadio_updateMonitoring(t *testing.T) {
 	fakeShell := newFakeShell(t)
 	shell = fakeShell
@@ -305,4 +329,5 @@ func TestRadio_updateMonitoring(t *testing.T) {
 	assert.Contains(t, fakeShell.commandsRun, "luci-bwc -i ath1")
 	assert.Contains(t, fakeShell.commandsRun, "iwinfo ath1 assoclist")
 	assert.Contains(t, fakeShell.commandsRun, "ifconfig ath1")
+	assert.Equal(t, diagnosisNotApplicable, radio.Connectivity.Diagnosis)
 }
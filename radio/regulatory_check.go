@@ -0,0 +1,214 @@
+package radio
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	regulatoryDomainOwnerRe   = regexp.MustCompile(`^(global|phy#\d+)`)
+	regulatoryDomainCountryRe = regexp.MustCompile(`^country (\w\w):`)
+	txPowerRe                 = regexp.MustCompile(`Tx-Power: (\d+) dBm`)
+)
+
+// regulatoryViolationType represents a kind of inconsistency between the radio's configuration and the regulatory
+// rules it is actually operating under.
+type regulatoryViolationType string
+
+const (
+	// The Wi-Fi devices are configured for different countries.
+	violationMixedCountry regulatoryViolationType = "MIXED_COUNTRY"
+
+	// The kernel is applying a different regulatory domain than the one configured.
+	violationKernelDomainMismatch regulatoryViolationType = "KERNEL_DOMAIN_MISMATCH"
+
+	// The operating channel isn't permitted in the configured country.
+	violationChannelNotPermitted regulatoryViolationType = "CHANNEL_NOT_PERMITTED"
+
+	// The operating channel can't be bonded into a channel of the configured bandwidth in the configured country.
+	violationBandwidthNotPermitted regulatoryViolationType = "BANDWIDTH_NOT_PERMITTED"
+
+	// The interface is transmitting at less power than configured, typically because the regulatory domain caps it.
+	violationTxPowerCapped regulatoryViolationType = "TX_POWER_CAPPED"
+)
+
+// RegulatoryViolation represents a single inconsistency between the radio's configuration and the regulatory rules it
+// is actually operating under, which can silently cap transmit power or take networks off the air.
+type RegulatoryViolation struct {
+	// Kind of inconsistency.
+	Type regulatoryViolationType `json:"type"`
+
+	// Wi-Fi device (e.g. "wifi1") or kernel regulatory domain owner (e.g. "phy#1") that the violation pertains to.
+	Device string `json:"device"`
+
+	// Human-readable description of the violation.
+	Message string `json:"message"`
+}
+
+// regulatoryTarget describes a Wi-Fi device whose operation is checked against the regulatory rules.
+type regulatoryTarget struct {
+	// Name of the Wi-Fi device section in the wireless configuration.
+	device string
+
+	// Name of an active network interface on the device through which its transmit power can be queried, or blank to
+	// skip the check.
+	networkInterface string
+
+	// Type of radio hardware operating the device, which determines the band its channels are in.
+	radioType RadioType
+
+	// Channel the device is operating on, or zero to skip the channel checks.
+	channel int
+
+	// Width of the channel the device is operating on, in megahertz, or zero if unknown.
+	channelWidthMhz int
+}
+
+// updateRegulatoryViolations checks the configuration of every Wi-Fi device against the regulatory rules in effect and
+// raises an alert for each violation that wasn't already present.
+func (radio *Radio) updateRegulatoryViolations() {
+	violations := radio.checkRegulatoryCompliance()
+	for _, violation := range violations {
+		isNew := true
+		for _, previous := range radio.RegulatoryViolations {
+			if previous.Type == violation.Type && previous.Device == violation.Device {
+				isNew = false
+				break
+			}
+		}
+		if isNew {
+			radio.raiseAlert(
+				"REGULATORY_VIOLATION", "%s on %s: %s", violation.Type, violation.Device, violation.Message,
+			)
+		}
+	}
+	radio.RegulatoryViolations = violations
+}
+
+// checkRegulatoryCompliance returns the violations found between the configured countries, the regulatory domains
+// applied by the kernel, and the channel, bandwidth, and transmit power each Wi-Fi device is operating at.
+func (radio *Radio) checkRegulatoryCompliance() []RegulatoryViolation {
+	violations := []RegulatoryViolation{}
+
+	// All Wi-Fi devices must be configured for the same country, which is then the one expected to be in effect.
+	devices, _ := uciTree.GetSections("wireless", "wifi-device")
+	sort.Strings(devices)
+	var country, countryDevice string
+	for _, device := range devices {
+		deviceCountry, _ := uciTree.GetLast("wireless", device, "country")
+		if deviceCountry == "" {
+			continue
+		}
+		if country == "" {
+			country, countryDevice = deviceCountry, device
+		} else if deviceCountry != country {
+			violations = append(violations, RegulatoryViolation{
+				Type:   violationMixedCountry,
+				Device: device,
+				Message: fmt.Sprintf(
+					"configured for country %s but %s is configured for %s", deviceCountry, countryDevice, country,
+				),
+			})
+		}
+	}
+
+	violations = append(violations, checkKernelRegulatoryDomains(country)...)
+
+	for _, target := range radio.regulatoryTargets() {
+		if target.channel != 0 && !isChannelPermitted(country, target.radioType, target.channel) {
+			violations = append(violations, RegulatoryViolation{
+				Type:    violationChannelNotPermitted,
+				Device:  target.device,
+				Message: fmt.Sprintf("channel %d is not permitted in regulatory domain %s", target.channel, country),
+			})
+		} else if target.channel != 0 &&
+			!isChannelWidthPermitted(country, target.radioType, target.channel, target.channelWidthMhz) {
+			violations = append(violations, RegulatoryViolation{
+				Type:   violationBandwidthNotPermitted,
+				Device: target.device,
+				Message: fmt.Sprintf(
+					"channel %d cannot be used at %dMHz in regulatory domain %s",
+					target.channel,
+					target.channelWidthMhz,
+					country,
+				),
+			})
+		}
+		if violation, ok := checkTxPower(target); ok {
+			violations = append(violations, violation)
+		}
+	}
+	return violations
+}
+
+// checkKernelRegulatoryDomains returns a violation for each regulatory domain applied by the kernel, either globally or
+// to a single PHY, that differs from the given configured country. If no country is configured, the domains are
+// instead expected to agree with each other.
+func checkKernelRegulatoryDomains(country string) []RegulatoryViolation {
+	output, err := shell.runCommand("iw", "reg", "get")
+	if err != nil {
+		log.Printf("Error running 'iw reg get': %v", err)
+		return nil
+	}
+
+	var violations []RegulatoryViolation
+	var owner string
+	expectedCountry, expectedOwner := country, "the configuration"
+	for _, line := range strings.Split(output, "\n") {
+		if match := regulatoryDomainOwnerRe.FindStringSubmatch(line); match != nil {
+			owner = match[1]
+			continue
+		}
+		match := regulatoryDomainCountryRe.FindStringSubmatch(line)
+		if match == nil || owner == "" {
+			continue
+		}
+		if expectedCountry == "" {
+			expectedCountry, expectedOwner = match[1], owner
+		} else if match[1] != expectedCountry {
+			violations = append(violations, RegulatoryViolation{
+				Type:   violationKernelDomainMismatch,
+				Device: owner,
+				Message: fmt.Sprintf(
+					"operating under regulatory domain %s instead of %s as set by %s",
+					match[1],
+					expectedCountry,
+					expectedOwner,
+				),
+			})
+		}
+		owner = ""
+	}
+	return violations
+}
+
+// checkTxPower returns a violation if the given target's network interface is transmitting at less power than its
+// device is configured for.
+func checkTxPower(target regulatoryTarget) (RegulatoryViolation, bool) {
+	configuredTxPower, _ := uciTree.GetLast("wireless", target.device, "txpower")
+	configuredDbm, err := strconv.Atoi(configuredTxPower)
+	if err != nil || target.networkInterface == "" {
+		return RegulatoryViolation{}, false
+	}
+	output, err := shell.runCommand("iwinfo", target.networkInterface, "info")
+	if err != nil {
+		log.Printf("Error running 'iwinfo %s info': %v", target.networkInterface, err)
+		return RegulatoryViolation{}, false
+	}
+	match := txPowerRe.FindStringSubmatch(output)
+	if match == nil {
+		return RegulatoryViolation{}, false
+	}
+	if actualDbm, _ := strconv.Atoi(match[1]); actualDbm < configuredDbm {
+		return RegulatoryViolation{
+			Type:    violationTxPowerCapped,
+			Device:  target.device,
+			Message: fmt.Sprintf("transmitting at %d dBm instead of the configured %d dBm", actualDbm, configuredDbm),
+		}, true
+	}
+	return RegulatoryViolation{}, false
+}
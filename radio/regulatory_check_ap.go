@@ -0,0 +1,24 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+// regulatoryTargets returns the Wi-Fi device that the access point operates its team networks on.
+func (radio *Radio) regulatoryTargets() []regulatoryTarget {
+	target := regulatoryTarget{device: radio.device, radioType: radio.Type, channel: radio.Channel}
+	switch radio.ChannelBandwidth {
+	case "20MHz":
+		target.channelWidthMhz = 20
+	case "40MHz":
+		target.channelWidthMhz = 40
+	}
+
+	// Disabled networks have no interface to query.
+	for station := red1; station <= blue3; station++ {
+		if !radio.isStationDisabled(station) {
+			target.networkInterface = radio.stationInterfaces[station]
+			break
+		}
+	}
+	return []regulatoryTarget{target}
+}
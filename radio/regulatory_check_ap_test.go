@@ -0,0 +1,98 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRadio_updateRegulatoryViolations(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeTree.valuesForGet["system.@system[0].model"] = "VH-109(AP)"
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["cat /etc/vh_firmware"] = ""
+	radio := NewRadio()
+	fakeShell.reset()
+
+	radio.Channel = 233
+	radio.ChannelBandwidth = "20MHz"
+	fakeTree.sectionsForGet["wireless.wifi-device"] = []string{"wifi1", "wifi0"}
+	fakeTree.valuesForGet["wireless.wifi0.country"] = "US"
+	fakeTree.valuesForGet["wireless.wifi1.country"] = "US"
+	fakeShell.commandOutput["iw reg get"] = "global\ncountry US: DFS-FCC\n"
+	radio.updateRegulatoryViolations()
+	assert.Equal(t, []RegulatoryViolation{}, radio.RegulatoryViolations)
+	assert.Empty(t, radio.GetAlerts())
+
+	// A mixed image leaves a PHY in a different domain and caps the transmit power.
+	radio.ChannelBandwidth = "40MHz"
+	fakeTree.valuesForGet["wireless.wifi0.country"] = "DE"
+	fakeTree.valuesForGet["wireless.wifi1.txpower"] = "23"
+	fakeShell.commandOutput["iw reg get"] = regulatoryDomainsOutput
+	fakeShell.commandOutput["iwinfo ath1 info"] = "ath1      ESSID: \"1111\"\n          Tx-Power: 12 dBm\n"
+	radio.updateRegulatoryViolations()
+	assert.Equal(
+		t,
+		[]RegulatoryViolation{
+			{
+				Type:    violationMixedCountry,
+				Device:  "wifi1",
+				Message: "configured for country US but wifi0 is configured for DE",
+			},
+			{
+				Type:    violationKernelDomainMismatch,
+				Device:  "global",
+				Message: "operating under regulatory domain US instead of DE as set by the configuration",
+			},
+			{
+				Type:    violationKernelDomainMismatch,
+				Device:  "phy#1",
+				Message: "operating under regulatory domain 00 instead of DE as set by the configuration",
+			},
+			{
+				Type:    violationKernelDomainMismatch,
+				Device:  "phy#0",
+				Message: "operating under regulatory domain US instead of DE as set by the configuration",
+			},
+			{
+				Type:    violationChannelNotPermitted,
+				Device:  "wifi1",
+				Message: "channel 233 is not permitted in regulatory domain DE",
+			},
+			{
+				Type:    violationTxPowerCapped,
+				Device:  "wifi1",
+				Message: "transmitting at 12 dBm instead of the configured 23 dBm",
+			},
+		},
+		radio.RegulatoryViolations,
+	)
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 6, len(alerts)) {
+		assert.Equal(t, "REGULATORY_VIOLATION", alerts[0].Type)
+		assert.Equal(
+			t, "MIXED_COUNTRY on wifi1: configured for country US but wifi0 is configured for DE", alerts[0].Message,
+		)
+	}
+
+	// Violations that persist aren't alerted on again.
+	fakeTree.valuesForGet["wireless.wifi0.country"] = "US"
+	fakeShell.commandOutput["iw reg get"] = "global\ncountry US: DFS-FCC\n"
+	fakeShell.commandOutput["iwinfo ath1 info"] = "ath1      ESSID: \"1111\"\n          Tx-Power: 14 dBm\n"
+	radio.updateRegulatoryViolations()
+	if assert.Equal(t, 2, len(radio.RegulatoryViolations)) {
+		assert.Equal(t, violationBandwidthNotPermitted, radio.RegulatoryViolations[0].Type)
+		assert.Equal(
+			t, "channel 233 cannot be used at 40MHz in regulatory domain US", radio.RegulatoryViolations[0].Message,
+		)
+		assert.Equal(t, violationTxPowerCapped, radio.RegulatoryViolations[1].Type)
+	}
+	alerts = radio.GetAlerts()
+	if assert.Equal(t, 7, len(alerts)) {
+		assert.Contains(t, alerts[6].Message, "BANDWIDTH_NOT_PERMITTED on wifi1")
+	}
+}
@@ -0,0 +1,16 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package radio
+
+import "strconv"
+
+// regulatoryTargets returns the robot radio's 6GHz and 2.4GHz Wi-Fi devices. Only the channel of the 6GHz device is
+// checked, and only when the radio broadcasts its own network, since it otherwise follows the access point.
+func (radio *Radio) regulatoryTargets() []regulatoryTarget {
+	channel6, _ := strconv.Atoi(radio.Channel)
+	return []regulatoryTarget{
+		{device: radioDevice6, networkInterface: radioInterface6, radioType: TypeVividHosting, channel: channel6},
+		{device: radioDevice24, networkInterface: radioInterface24},
+	}
+}
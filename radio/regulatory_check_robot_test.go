@@ -0,0 +1,25 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRadio_regulatoryTargets(t *testing.T) {
+	radio := &Radio{Mode: modeTeamAccessPoint, Channel: "93"}
+	assert.Equal(
+		t,
+		[]regulatoryTarget{
+			{device: "wifi1", networkInterface: "ath1", radioType: TypeVividHosting, channel: 93},
+			{device: "wifi0", networkInterface: "ath0"},
+		},
+		radio.regulatoryTargets(),
+	)
+
+	// The channel follows the access point in robot radio mode, so it isn't checked.
+	radio = &Radio{Mode: modeTeamRobotRadio}
+	assert.Equal(t, 0, radio.regulatoryTargets()[0].channel)
+}
@@ -0,0 +1,98 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const regulatoryDomainsOutput = "global\n" +
+	"country US: DFS-FCC\n" +
+	"\t(2400 - 2472 @ 40), (N/A, 30), (N/A)\n" +
+	"\t(5730 - 5850 @ 80), (N/A, 30), (N/A), AUTO-BW\n" +
+	"\n" +
+	"phy#1 (self-managed)\n" +
+	"country 00: DFS-UNSET\n" +
+	"\t(2402 - 2472 @ 40), (6, 20), (N/A)\n" +
+	"\n" +
+	"phy#0\n" +
+	"country US: DFS-FCC\n" +
+	"\t(2400 - 2472 @ 40), (N/A, 30), (N/A)\n"
+
+func TestCheckKernelRegulatoryDomains(t *testing.T) {
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+
+	fakeShell.commandOutput["iw reg get"] = regulatoryDomainsOutput
+	assert.Equal(
+		t,
+		[]RegulatoryViolation{
+			{
+				Type:    violationKernelDomainMismatch,
+				Device:  "phy#1",
+				Message: "operating under regulatory domain 00 instead of US as set by the configuration",
+			},
+		},
+		checkKernelRegulatoryDomains("US"),
+	)
+	violations := checkKernelRegulatoryDomains("GB")
+	if assert.Equal(t, 3, len(violations)) {
+		assert.Equal(t, "global", violations[0].Device)
+		assert.Equal(t, "phy#0", violations[2].Device)
+	}
+
+	// Without a configured country, the domains are expected to agree with the first one listed.
+	assert.Equal(
+		t,
+		[]RegulatoryViolation{
+			{
+				Type:    violationKernelDomainMismatch,
+				Device:  "phy#1",
+				Message: "operating under regulatory domain 00 instead of US as set by global",
+			},
+		},
+		checkKernelRegulatoryDomains(""),
+	)
+
+	fakeShell.commandOutput["iw reg get"] = "global\ncountry US: DFS-FCC\n\t(2400 - 2472 @ 40), (N/A, 30), (N/A)\n"
+	assert.Empty(t, checkKernelRegulatoryDomains("US"))
+
+	delete(fakeShell.commandOutput, "iw reg get")
+	fakeShell.commandErrors["iw reg get"] = assert.AnError
+	assert.Empty(t, checkKernelRegulatoryDomains("US"))
+}
+
+func TestCheckTxPower(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	target := regulatoryTarget{device: "wifi1", networkInterface: "ath1"}
+
+	// No transmit power is configured, so there is nothing to compare against.
+	_, ok := checkTxPower(target)
+	assert.False(t, ok)
+	assert.Empty(t, fakeShell.commandsRun)
+
+	fakeTree.valuesForGet["wireless.wifi1.txpower"] = "23"
+	fakeShell.commandOutput["iwinfo ath1 info"] = "ath1      ESSID: \"1111\"\n          Tx-Power: 23 dBm\n"
+	_, ok = checkTxPower(target)
+	assert.False(t, ok)
+
+	fakeShell.commandOutput["iwinfo ath1 info"] = "ath1      ESSID: \"1111\"\n          Tx-Power: 14 dBm\n"
+	violation, ok := checkTxPower(target)
+	assert.True(t, ok)
+	assert.Equal(
+		t,
+		RegulatoryViolation{
+			Type:    violationTxPowerCapped,
+			Device:  "wifi1",
+			Message: "transmitting at 14 dBm instead of the configured 23 dBm",
+		},
+		violation,
+	)
+
+	// The interface can't be queried.
+	target.networkInterface = ""
+	_, ok = checkTxPower(target)
+	assert.False(t, ok)
+}
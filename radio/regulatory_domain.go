@@ -0,0 +1,103 @@
+package radio
+
+import "sort"
+
+// regulatoryDomain represents the subset of a country's wireless regulations that constrain which channels the radio
+// may operate on.
+type regulatoryDomain struct {
+	// 5GHz channels that are legal to use, limited to the non-DFS channels supported by the Linksys access point.
+	channels5Ghz []int
+
+	// Highest 6GHz channel number that is legal to use, or zero if 6GHz operation is not permitted at all.
+	max6GhzChannel int
+}
+
+var (
+	// 5GHz channels permitted in domains that allow both the UNII-1 and UNII-3 bands.
+	unii1And3Channels = []int{36, 40, 44, 48, 149, 153, 157, 161, 165}
+
+	// 5GHz channels permitted in domains that only allow the UNII-1 band.
+	unii1Channels = []int{36, 40, 44, 48}
+)
+
+// Map of ISO 3166-1 alpha-2 country codes to the regulatory rules that apply there.
+var regulatoryDomains = map[string]regulatoryDomain{
+	"AU": {channels5Ghz: unii1And3Channels, max6GhzChannel: 93},
+	"BR": {channels5Ghz: unii1And3Channels, max6GhzChannel: 233},
+	"CA": {channels5Ghz: unii1And3Channels, max6GhzChannel: 233},
+	"CN": {channels5Ghz: unii1And3Channels, max6GhzChannel: 0},
+	"DE": {channels5Ghz: unii1Channels, max6GhzChannel: 93},
+	"FR": {channels5Ghz: unii1Channels, max6GhzChannel: 93},
+	"GB": {channels5Ghz: unii1Channels, max6GhzChannel: 93},
+	"IL": {channels5Ghz: unii1Channels, max6GhzChannel: 93},
+	"JP": {channels5Ghz: unii1Channels, max6GhzChannel: 93},
+	"MX": {channels5Ghz: unii1And3Channels, max6GhzChannel: 93},
+	"NL": {channels5Ghz: unii1Channels, max6GhzChannel: 93},
+	"TR": {channels5Ghz: unii1Channels, max6GhzChannel: 93},
+	"US": {channels5Ghz: unii1And3Channels, max6GhzChannel: 233},
+}
+
+// isValidCountry returns true if the given country code has a known regulatory domain.
+func isValidCountry(country string) bool {
+	_, ok := regulatoryDomains[country]
+	return ok
+}
+
+// supportedCountries returns the sorted list of country codes that have a known regulatory domain.
+func supportedCountries() []string {
+	countries := make([]string, 0, len(regulatoryDomains))
+	for country := range regulatoryDomains {
+		countries = append(countries, country)
+	}
+	sort.Strings(countries)
+	return countries
+}
+
+// isChannelPermitted returns true if the given channel may legally be used by the given radio type in the given
+// country. Returns true if the country is unknown, since no rules can be enforced in that case.
+func isChannelPermitted(country string, radioType RadioType, channel int) bool {
+	domain, ok := regulatoryDomains[country]
+	if !ok {
+		return true
+	}
+
+	switch radioType {
+	case TypeLinksys:
+		for _, permittedChannel := range domain.channels5Ghz {
+			if channel == permittedChannel {
+				return true
+			}
+		}
+		return false
+	case TypeVividHosting:
+		return channel <= domain.max6GhzChannel
+	default:
+		return false
+	}
+}
+
+// isChannelWidthPermitted returns true if the given channel may legally be bonded into a channel of the given width by
+// the given radio type in the given country, which requires each of the channels it spans to be permitted. Returns true
+// if the country is unknown, since no rules can be enforced in that case.
+func isChannelWidthPermitted(country string, radioType RadioType, channel int, widthMhz int) bool {
+	if _, ok := regulatoryDomains[country]; !ok || widthMhz <= 20 {
+		return true
+	}
+	if widthMhz != 40 {
+		return false
+	}
+
+	// 40MHz channels pair each 20MHz channel with its neighbor, starting from the bottom of each band.
+	bandStart := 1
+	if radioType == TypeLinksys {
+		bandStart = 36
+		if channel >= 149 {
+			bandStart = 149
+		}
+	}
+	partner := channel + 4
+	if (channel-bandStart)/4%2 == 1 {
+		partner = channel - 4
+	}
+	return isChannelPermitted(country, radioType, channel) && isChannelPermitted(country, radioType, partner)
+}
@@ -0,0 +1,61 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIsValidCountry(t *testing.T) {
+	assert.True(t, isValidCountry("US"))
+	assert.True(t, isValidCountry("GB"))
+	assert.False(t, isValidCountry("us"))
+	assert.False(t, isValidCountry(""))
+	assert.False(t, isValidCountry("XX"))
+}
+
+func TestSupportedCountries(t *testing.T) {
+	countries := supportedCountries()
+	assert.Equal(t, len(regulatoryDomains), len(countries))
+	assert.Equal(t, "AU", countries[0])
+	assert.Contains(t, countries, "US")
+}
+
+func TestIsChannelPermitted(t *testing.T) {
+	// Unknown country doesn't restrict anything.
+	assert.True(t, isChannelPermitted("", TypeLinksys, 165))
+	assert.True(t, isChannelPermitted("", TypeVividHosting, 229))
+
+	// 5GHz channels.
+	assert.True(t, isChannelPermitted("US", TypeLinksys, 36))
+	assert.True(t, isChannelPermitted("US", TypeLinksys, 165))
+	assert.True(t, isChannelPermitted("GB", TypeLinksys, 48))
+	assert.False(t, isChannelPermitted("GB", TypeLinksys, 149))
+
+	// 6GHz channels.
+	assert.True(t, isChannelPermitted("US", TypeVividHosting, 229))
+	assert.True(t, isChannelPermitted("DE", TypeVividHosting, 93))
+	assert.False(t, isChannelPermitted("DE", TypeVividHosting, 101))
+	assert.False(t, isChannelPermitted("CN", TypeVividHosting, 5))
+
+	// Unknown radio type.
+	assert.False(t, isChannelPermitted("US", TypeUnknown, 36))
+}
+
+func TestIsChannelWidthPermitted(t *testing.T) {
+	// Unknown country or a 20MHz channel doesn't restrict anything beyond the channel itself.
+	assert.True(t, isChannelWidthPermitted("", TypeLinksys, 165, 40))
+	assert.True(t, isChannelWidthPermitted("US", TypeLinksys, 165, 20))
+
+	// 5GHz channels.
+	assert.True(t, isChannelWidthPermitted("US", TypeLinksys, 36, 40))
+	assert.True(t, isChannelWidthPermitted("US", TypeLinksys, 48, 40))
+	assert.True(t, isChannelWidthPermitted("US", TypeLinksys, 161, 40))
+	assert.False(t, isChannelWidthPermitted("US", TypeLinksys, 165, 40))
+	assert.False(t, isChannelWidthPermitted("US", TypeLinksys, 36, 80))
+
+	// 6GHz channels.
+	assert.True(t, isChannelWidthPermitted("DE", TypeVividHosting, 89, 40))
+	assert.True(t, isChannelWidthPermitted("DE", TypeVividHosting, 93, 40))
+	assert.True(t, isChannelWidthPermitted("US", TypeVividHosting, 229, 40))
+	assert.False(t, isChannelWidthPermitted("US", TypeVividHosting, 233, 40))
+}
@@ -0,0 +1,185 @@
+package radio
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// RetentionPolicy limits the age and total size of a set of diagnostic files kept on the radio, so that they can't
+// eventually fill the overlay filesystem.
+type RetentionPolicy struct {
+	// Unique, human-readable name identifying the set of files (e.g. "packetCaptures").
+	Name string `json:"name"`
+
+	// Glob pattern matching the files (e.g. "/tmp/*.pcap"). A relative pattern is resolved within the state directory.
+	Pattern string `json:"pattern"`
+
+	// Age in hours beyond which files are removed. Zero disables the age limit.
+	MaxAgeHours int `json:"maxAgeHours"`
+
+	// Total size in bytes beyond which the oldest files are removed. Zero disables the size limit.
+	MaxTotalBytes int64 `json:"maxTotalBytes"`
+}
+
+// RetentionPolicyReport represents the files covered by a retention policy as of its most recent enforcement.
+type RetentionPolicyReport struct {
+	// Name of the policy.
+	Name string `json:"name"`
+
+	// Glob pattern matching the files, with relative patterns resolved within the state directory.
+	Pattern string `json:"pattern"`
+
+	// Number of files remaining after enforcement.
+	FileCount int `json:"fileCount"`
+
+	// Total size of the files remaining after enforcement, in bytes.
+	TotalBytes int64 `json:"totalBytes"`
+
+	// Number of files removed by the policy since the API started.
+	RemovedFileCount int `json:"removedFileCount"`
+
+	// Total size of the files removed by the policy since the API started, in bytes.
+	RemovedBytes int64 `json:"removedBytes"`
+
+	// Description of the most recent failure to list or remove the files, or blank if there was none.
+	Error string `json:"error"`
+}
+
+// StorageReport represents the state of the radio's flash storage and of the retention policies that keep diagnostic
+// files from filling it.
+type StorageReport struct {
+	// Health of the flash storage as of the most recent enforcement of the retention policies.
+	Storage StorageHealth `json:"storage"`
+
+	// Time at which the retention policies were last enforced.
+	EnforcedAt Timestamp `json:"enforcedAt"`
+
+	// Outcome of each retention policy, in the order in which they are configured.
+	Policies []RetentionPolicyReport `json:"policies"`
+}
+
+// defaultRetentionPolicies returns the retention policies used when none are given in the settings file.
+func defaultRetentionPolicies() []RetentionPolicy {
+	return []RetentionPolicy{
+		{Name: "apiLogs", Pattern: OldLogFileName, MaxAgeHours: 30 * 24},
+		{Name: "packetCaptures", Pattern: "/tmp/*.pcap", MaxAgeHours: 24, MaxTotalBytes: 4 * 1024 * 1024},
+	}
+}
+
+// validateRetentionPolicies checks that the given retention policies have unique names and valid limits.
+func validateRetentionPolicies(policies []RetentionPolicy) error {
+	policyNames := make(map[string]struct{})
+	for _, policy := range policies {
+		if policy.Name == "" {
+			return errors.New("retention policy name cannot be blank")
+		}
+		if _, ok := policyNames[policy.Name]; ok {
+			return fmt.Errorf("duplicate retention policy name: %s", policy.Name)
+		}
+		policyNames[policy.Name] = struct{}{}
+		if _, err := filepath.Match(policy.Pattern, ""); policy.Pattern == "" || err != nil {
+			return fmt.Errorf("invalid pattern for retention policy %s: %q", policy.Name, policy.Pattern)
+		}
+		if policy.MaxAgeHours < 0 {
+			return fmt.Errorf("invalid maxAgeHours for retention policy %s: %d", policy.Name, policy.MaxAgeHours)
+		}
+		if policy.MaxTotalBytes < 0 {
+			return fmt.Errorf("invalid maxTotalBytes for retention policy %s: %d", policy.Name, policy.MaxTotalBytes)
+		}
+	}
+	return nil
+}
+
+// GetStorageReport returns a snapshot of the storage report as of the most recent enforcement of the retention
+// policies.
+func (radio *Radio) GetStorageReport() StorageReport {
+	radio.retentionMutex.Lock()
+	defer radio.retentionMutex.Unlock()
+	report := radio.storageReport
+	report.Policies = append([]RetentionPolicyReport{}, radio.storageReport.Policies...)
+	return report
+}
+
+// enforceRetentionPolicies removes the diagnostic files that exceed the age or size limits of their retention policy
+// as of the given time and updates the storage report.
+func (radio *Radio) enforceRetentionPolicies(now time.Time) {
+	settings := radio.GetSettings()
+	radio.retentionMutex.Lock()
+	defer radio.retentionMutex.Unlock()
+
+	previousReports := make(map[string]RetentionPolicyReport)
+	for _, report := range radio.storageReport.Policies {
+		previousReports[report.Name] = report
+	}
+	reports := []RetentionPolicyReport{}
+	for _, policy := range settings.RetentionPolicies {
+		pattern := policy.Pattern
+		if !filepath.IsAbs(pattern) {
+			pattern = settings.StateFilePath(pattern)
+		}
+		report := RetentionPolicyReport{Name: policy.Name, Pattern: pattern}
+		if previous, ok := previousReports[policy.Name]; ok && previous.Pattern == pattern {
+			report.RemovedFileCount = previous.RemovedFileCount
+			report.RemovedBytes = previous.RemovedBytes
+		}
+		if err := report.enforce(policy, settings.StateFilePath(LogFileName), now); err != nil {
+			log.Printf("Error enforcing retention policy %s: %v", policy.Name, err)
+			report.Error = err.Error()
+		}
+		reports = append(reports, report)
+	}
+	radio.storageReport = StorageReport{Storage: radio.Storage, EnforcedAt: newTimestamp(), Policies: reports}
+}
+
+// enforce removes the files matching the report's pattern that exceed the given policy's limits as of the given time,
+// never touching the given log file that the API is currently writing to, and tallies the files that remain.
+func (report *RetentionPolicyReport) enforce(policy RetentionPolicy, currentLogFilePath string, now time.Time) error {
+	paths, err := filepath.Glob(report.Pattern)
+	if err != nil {
+		return err
+	}
+	type retainedFile struct {
+		path      string
+		size      int64
+		modTime   time.Time
+		isExpired bool
+	}
+	var files []retainedFile
+	for _, path := range paths {
+		if path == currentLogFilePath {
+			continue
+		}
+		fileInfo, err := os.Stat(path)
+		if err != nil || !fileInfo.Mode().IsRegular() {
+			continue
+		}
+		isExpired := policy.MaxAgeHours > 0 && now.Sub(fileInfo.ModTime()) > time.Duration(policy.MaxAgeHours)*time.Hour
+		files = append(files, retainedFile{path, fileInfo.Size(), fileInfo.ModTime(), isExpired})
+		report.TotalBytes += fileInfo.Size()
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	var lastErr error
+	for _, file := range files {
+		isOversize := policy.MaxTotalBytes > 0 && report.TotalBytes > policy.MaxTotalBytes
+		if !file.isExpired && !isOversize {
+			report.FileCount++
+			continue
+		}
+		if err := os.Remove(file.path); err != nil {
+			lastErr = err
+			report.FileCount++
+			continue
+		}
+		log.Printf("Removed %s (%d bytes) under retention policy %s.", file.path, file.size, policy.Name)
+		report.TotalBytes -= file.size
+		report.RemovedFileCount++
+		report.RemovedBytes += file.size
+	}
+	return lastErr
+}
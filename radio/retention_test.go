@@ -0,0 +1,96 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRadio_enforceRetentionPolicies(t *testing.T) {
+	directory := t.TempDir()
+	now := time.Now()
+	writeFile := func(name string, size int, age time.Duration) string {
+		path := filepath.Join(directory, name)
+		assert.Nil(t, os.WriteFile(path, make([]byte, size), 0644))
+		assert.Nil(t, os.Chtimes(path, now.Add(-age), now.Add(-age)))
+		return path
+	}
+	expiredPath := writeFile("a.pcap", 100, 48*time.Hour)
+	oldestPath := writeFile("b.pcap", 300, 3*time.Hour)
+	newerPath := writeFile("c.pcap", 300, 2*time.Hour)
+	newestPath := writeFile("d.pcap", 300, time.Hour)
+	otherPath := writeFile("notes.txt", 1000, 72*time.Hour)
+
+	radio := &Radio{settings: defaultSettings()}
+	radio.settings.RetentionPolicies = []RetentionPolicy{
+		{Name: "packetCaptures", Pattern: filepath.Join(directory, "*.pcap"), MaxAgeHours: 24, MaxTotalBytes: 700},
+	}
+	radio.Storage.OverlayFreeBytes = 12345
+	radio.enforceRetentionPolicies(now)
+
+	// The expired file is removed, followed by the oldest files until the rest fit within the size limit.
+	for _, path := range []string{expiredPath, oldestPath} {
+		_, err := os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+	}
+	for _, path := range []string{newerPath, newestPath, otherPath} {
+		_, err := os.Stat(path)
+		assert.Nil(t, err)
+	}
+	report := radio.GetStorageReport()
+	assert.Equal(t, int64(12345), report.Storage.OverlayFreeBytes)
+	assert.False(t, report.EnforcedAt.Wallclock.IsZero())
+	assert.Equal(
+		t,
+		[]RetentionPolicyReport{
+			{
+				Name:             "packetCaptures",
+				Pattern:          filepath.Join(directory, "*.pcap"),
+				FileCount:        2,
+				TotalBytes:       600,
+				RemovedFileCount: 2,
+				RemovedBytes:     400,
+			},
+		},
+		report.Policies,
+	)
+
+	// Removal counts accumulate across enforcements.
+	writeFile("e.pcap", 300, 0)
+	radio.enforceRetentionPolicies(now)
+	report = radio.GetStorageReport()
+	assert.Equal(t, 2, report.Policies[0].FileCount)
+	assert.Equal(t, 3, report.Policies[0].RemovedFileCount)
+	assert.Equal(t, int64(700), report.Policies[0].RemovedBytes)
+	_, err := os.Stat(newerPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRadio_enforceRetentionPoliciesStateDirectory(t *testing.T) {
+	radio := &Radio{settings: defaultSettings()}
+	radio.settings.RetentionPolicies = []RetentionPolicy{{Name: "apiLogs", Pattern: "frc-radio-api.log*"}}
+	radio.enforceRetentionPolicies(time.Now())
+
+	// Relative patterns are resolved in the state directory.
+	report := radio.GetStorageReport()
+	if assert.Equal(t, 1, len(report.Policies)) {
+		assert.Equal(t, filepath.Join(persistentStateDirectory, "frc-radio-api.log*"), report.Policies[0].Pattern)
+	}
+}
+
+func TestRetentionPolicyReport_enforceSkipsCurrentLogFile(t *testing.T) {
+	directory := t.TempDir()
+	logFilePath := filepath.Join(directory, LogFileName)
+	assert.Nil(t, os.WriteFile(logFilePath, make([]byte, 1000), 0644))
+	assert.Nil(t, os.Mkdir(filepath.Join(directory, "frc-radio-api.logs"), 0755))
+
+	report := RetentionPolicyReport{Pattern: filepath.Join(directory, "frc-radio-api.log*")}
+	policy := RetentionPolicy{Name: "apiLogs", MaxTotalBytes: 1}
+	assert.Nil(t, report.enforce(policy, logFilePath, time.Now()))
+	assert.Equal(t, 0, report.FileCount)
+	assert.Equal(t, 0, report.RemovedFileCount)
+	_, err := os.Stat(logFilePath)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,160 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"log"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Minimum number of packets exchanged with a linked device since the last assessment for its retry and drop rates to
+// be recalculated, so that a handful of packets on an idle link doesn't produce a misleading rate.
+const minRetryRatePackets = 50
+
+var (
+	stationDumpTxPacketsRe = regexp.MustCompile(`tx packets:\s+(\d+)`)
+	stationDumpTxRetriesRe = regexp.MustCompile(`tx retries:\s+(\d+)`)
+	stationDumpTxFailedRe  = regexp.MustCompile(`tx failed:\s+(\d+)`)
+	stationDumpRxPacketsRe = regexp.MustCompile(`rx packets:\s+(\d+)`)
+	stationDumpRxDroppedRe = regexp.MustCompile(`rx drop misc:\s+(\d+)`)
+)
+
+// retryCounterSample represents the cumulative driver counters for a single associated device.
+type retryCounterSample struct {
+	macAddress string
+	txPackets  int
+	txRetries  int
+	txFailed   int
+	rxPackets  int
+	rxDropped  int
+}
+
+// updateRetryCounters reads the driver's retry and drop counters for the device linked to each team station and
+// derives the rates at which transmissions are retried or fail and received packets are dropped, raising an alert for
+// stations whose retry rate crosses the threshold.
+func (radio *Radio) updateRetryCounters() {
+	if radio.retryBaselines == nil {
+		radio.retryBaselines = make(map[station]retryCounterSample)
+	}
+	for station := red1; station <= blue3; station++ {
+		stationStatus := radio.StationStatuses[station.String()]
+		if stationStatus == nil || !stationStatus.IsLinked {
+			delete(radio.retryBaselines, station)
+			if stationStatus != nil {
+				stationStatus.resetRetryCounters()
+			}
+			continue
+		}
+
+		wifiInterface := radio.stationInterfaces[station]
+		output, err := shell.runCommand("iw", "dev", wifiInterface, "station", "dump")
+		if err != nil {
+			log.Printf("Error running 'iw dev %s station dump': %v", wifiInterface, err)
+			delete(radio.retryBaselines, station)
+			stationStatus.resetRetryCounters()
+			stationStatus.TxRetries = monitoringErrorCode
+			stationStatus.TxFailed = monitoringErrorCode
+			stationStatus.RxDropped = monitoringErrorCode
+			continue
+		}
+		sample, ok := parseStationDump(output, stationStatus.MacAddress)
+		if !ok {
+			delete(radio.retryBaselines, station)
+			stationStatus.resetRetryCounters()
+			continue
+		}
+
+		stationStatus.TxRetries = sample.txRetries
+		stationStatus.TxFailed = sample.txFailed
+		stationStatus.RxDropped = sample.rxDropped
+		baseline, hasBaseline := radio.retryBaselines[station]
+		if !hasBaseline || baseline.macAddress != sample.macAddress || sample.txPackets < baseline.txPackets ||
+			sample.rxPackets < baseline.rxPackets {
+			// A new device or a counter reset; start measuring afresh.
+			radio.retryBaselines[station] = sample
+			stationStatus.TxRetryRatePercent = 0
+			stationStatus.TxFailureRatePercent = 0
+			stationStatus.RxDropRatePercent = 0
+			stationStatus.HasHighRetryRate = false
+			continue
+		}
+
+		txPackets := sample.txPackets - baseline.txPackets
+		rxPackets := sample.rxPackets - baseline.rxPackets
+		if txPackets+rxPackets < minRetryRatePackets {
+			// Keep reporting the previous rates until there is enough traffic to assess them again.
+			continue
+		}
+		radio.retryBaselines[station] = sample
+		stationStatus.TxRetryRatePercent = ratePercent(sample.txRetries-baseline.txRetries, txPackets)
+		stationStatus.TxFailureRatePercent = ratePercent(sample.txFailed-baseline.txFailed, txPackets)
+		rxDropped := sample.rxDropped - baseline.rxDropped
+		stationStatus.RxDropRatePercent = ratePercent(rxDropped, rxPackets+rxDropped)
+
+		wasHigh := stationStatus.HasHighRetryRate
+		threshold := radio.GetSettings().RetryRateThresholdPercent
+		stationStatus.HasHighRetryRate = threshold > 0 && stationStatus.TxRetryRatePercent > threshold
+		if stationStatus.HasHighRetryRate && !wasHigh {
+			radio.raiseStationAlert(
+				station.String(),
+				"HIGH_RETRY_RATE",
+				"Station %s (SSID \"%s\") is retrying %.1f%% of transmissions, above the %.1f%% threshold.",
+				station,
+				stationStatus.Ssid,
+				stationStatus.TxRetryRatePercent,
+				threshold,
+			)
+		}
+	}
+}
+
+// resetRetryCounters clears the retry and drop counters and rates of the network.
+func (status *NetworkStatus) resetRetryCounters() {
+	status.TxRetries = 0
+	status.TxFailed = 0
+	status.RxDropped = 0
+	status.TxRetryRatePercent = 0
+	status.TxFailureRatePercent = 0
+	status.RxDropRatePercent = 0
+	status.HasHighRetryRate = false
+}
+
+// parseStationDump parses the output of 'iw dev [interface] station dump' and returns the counters for the device with
+// the given MAC address, if it is present.
+func parseStationDump(response string, macAddress string) (retryCounterSample, bool) {
+	for _, block := range strings.Split(response, "Station ")[1:] {
+		fields := strings.Fields(block)
+		if len(fields) == 0 || !strings.EqualFold(fields[0], macAddress) {
+			continue
+		}
+		sample := retryCounterSample{macAddress: strings.ToUpper(fields[0])}
+		for _, counter := range []struct {
+			re    *regexp.Regexp
+			value *int
+		}{
+			{stationDumpTxPacketsRe, &sample.txPackets},
+			{stationDumpTxRetriesRe, &sample.txRetries},
+			{stationDumpTxFailedRe, &sample.txFailed},
+			{stationDumpRxPacketsRe, &sample.rxPackets},
+			{stationDumpRxDroppedRe, &sample.rxDropped},
+		} {
+			if match := counter.re.FindStringSubmatch(block); match != nil {
+				*counter.value, _ = strconv.Atoi(match[1])
+			}
+		}
+		return sample, true
+	}
+	return retryCounterSample{}, false
+}
+
+// ratePercent returns the given count as a percentage of the given total, rounded to one decimal place.
+func ratePercent(count, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return math.Round(1000*float64(count)/float64(total)) / 10
+}
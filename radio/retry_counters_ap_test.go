@@ -0,0 +1,149 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// stationDump returns fake 'iw dev [interface] station dump' output for a single device with the given counters.
+func stationDump(macAddress string, txPackets, txRetries, txFailed, rxPackets, rxDropped int) string {
+	return fmt.Sprintf(
+		"Station %s (on wlan0)\n\tinactive time:\t10 ms\n\trx bytes:\t123456\n\trx packets:\t%d\n"+
+			"\ttx bytes:\t654321\n\ttx packets:\t%d\n\ttx retries:\t%d\n\ttx failed:\t%d\n\trx drop misc:\t%d\n"+
+			"\tsignal:  \t-45 dBm\n",
+		macAddress,
+		rxPackets,
+		txPackets,
+		txRetries,
+		txFailed,
+		rxDropped,
+	)
+}
+
+func TestParseStationDump(t *testing.T) {
+	response := stationDump("12:34:56:78:9a:bc", 1, 2, 3, 4, 5) + stationDump("48:da:35:b0:00:cf", 1000, 25, 1, 4095, 3)
+	sample, ok := parseStationDump(response, "48:DA:35:B0:00:CF")
+	assert.True(t, ok)
+	assert.Equal(
+		t,
+		retryCounterSample{
+			macAddress: "48:DA:35:B0:00:CF", txPackets: 1000, txRetries: 25, txFailed: 1, rxPackets: 4095, rxDropped: 3,
+		},
+		sample,
+	)
+
+	_, ok = parseStationDump(response, "37:DA:35:B0:00:BE")
+	assert.False(t, ok)
+	_, ok = parseStationDump("", "48:DA:35:B0:00:CF")
+	assert.False(t, ok)
+}
+
+func TestRadio_updateRetryCounters(t *testing.T) {
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+	fakeShell.reset()
+	radio.SetSettings(defaultSettings())
+	radio.StationStatuses["red1"] = &NetworkStatus{Ssid: "254", IsLinked: true, MacAddress: "48:DA:35:B0:00:CF"}
+	radio.StationStatuses["blue3"] = &NetworkStatus{Ssid: "1503"}
+
+	// The first sample only establishes the baseline.
+	fakeShell.commandOutput["iw dev wlan0 station dump"] = stationDump("48:da:35:b0:00:cf", 1000, 25, 1, 4000, 3)
+	radio.updateRetryCounters()
+	assert.Contains(t, fakeShell.commandsRun, "iw dev wlan0 station dump")
+	status := radio.StationStatuses["red1"]
+	assert.Equal(t, 25, status.TxRetries)
+	assert.Equal(t, 1, status.TxFailed)
+	assert.Equal(t, 3, status.RxDropped)
+	assert.Equal(t, 0.0, status.TxRetryRatePercent)
+
+	// Rates are derived from the change in counters since the baseline.
+	fakeShell.reset()
+	fakeShell.commandOutput["iw dev wlan0 station dump"] = stationDump("48:da:35:b0:00:cf", 1200, 45, 3, 4098, 5)
+	radio.updateRetryCounters()
+	assert.Equal(t, 45, status.TxRetries)
+	assert.Equal(t, 10.0, status.TxRetryRatePercent)
+	assert.Equal(t, 1.0, status.TxFailureRatePercent)
+	assert.Equal(t, 2.0, status.RxDropRatePercent)
+	assert.False(t, status.HasHighRetryRate)
+	assert.Empty(t, radio.GetAlerts())
+
+	// Too little traffic leaves the previous rates in place.
+	fakeShell.reset()
+	fakeShell.commandOutput["iw dev wlan0 station dump"] = stationDump("48:da:35:b0:00:cf", 1210, 55, 3, 4100, 5)
+	radio.updateRetryCounters()
+	assert.Equal(t, 55, status.TxRetries)
+	assert.Equal(t, 10.0, status.TxRetryRatePercent)
+
+	// Crossing the threshold raises a single alert.
+	for i := 0; i < 2; i++ {
+		fakeShell.reset()
+		fakeShell.commandOutput["iw dev wlan0 station dump"] = stationDump(
+			"48:da:35:b0:00:cf", 1300+100*i, 95+50*i, 3, 4100, 5,
+		)
+		fakeShell.commandOutput["iw dev wlan0-5 survey dump"] = ""
+		fakeShell.commandOutput["iwinfo wlan0 assoclist"] = ""
+		fakeShell.commandOutput["iwinfo wlan0-5 assoclist"] = ""
+		radio.updateRetryCounters()
+		assert.Equal(t, 50.0, status.TxRetryRatePercent)
+		assert.True(t, status.HasHighRetryRate)
+	}
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "HIGH_RETRY_RATE", alerts[0].Type)
+		assert.Equal(
+			t,
+			"Station red1 (SSID \"254\") is retrying 50.0% of transmissions, above the 30.0% threshold.",
+			alerts[0].Message,
+		)
+		// The alert is accompanied by a snapshot of the RF conditions for each station with a team assigned.
+		if assert.NotNil(t, alerts[0].RfSnapshot) {
+			assert.Equal(
+				t,
+				map[string][]AssociationSnapshot{"red1": {}, "blue3": {}},
+				alerts[0].RfSnapshot.Associations,
+			)
+		}
+	}
+
+	// A threshold of zero disables the flag.
+	settings := radio.GetSettings()
+	settings.RetryRateThresholdPercent = 0
+	radio.SetSettings(settings)
+	fakeShell.reset()
+	fakeShell.commandOutput["iw dev wlan0 station dump"] = stationDump("48:da:35:b0:00:cf", 1500, 195, 3, 4100, 5)
+	radio.updateRetryCounters()
+	assert.Equal(t, 50.0, status.TxRetryRatePercent)
+	assert.False(t, status.HasHighRetryRate)
+
+	// A counter reset starts a new baseline.
+	fakeShell.reset()
+	fakeShell.commandOutput["iw dev wlan0 station dump"] = stationDump("48:da:35:b0:00:cf", 10, 1, 0, 20, 0)
+	radio.updateRetryCounters()
+	assert.Equal(t, 1, status.TxRetries)
+	assert.Equal(t, 0.0, status.TxRetryRatePercent)
+	assert.Equal(t, 0.0, status.RxDropRatePercent)
+
+	// Errors are reported using the monitoring error code.
+	fakeShell.reset()
+	fakeShell.commandErrors["iw dev wlan0 station dump"] = errors.New("oops")
+	radio.updateRetryCounters()
+	assert.Equal(t, monitoringErrorCode, status.TxRetries)
+	assert.Equal(t, monitoringErrorCode, status.TxFailed)
+	assert.Equal(t, monitoringErrorCode, status.RxDropped)
+	assert.NotContains(t, radio.retryBaselines, red1)
+
+	// Unlinking clears the counters without running any commands.
+	fakeShell.reset()
+	status.IsLinked = false
+	radio.updateRetryCounters()
+	assert.Empty(t, fakeShell.commandsRun)
+	assert.Equal(t, 0, status.TxRetries)
+	assert.Empty(t, radio.retryBaselines)
+}
@@ -0,0 +1,47 @@
+package radio
+
+// RfSnapshot captures the RF conditions on the access point at the moment an alert about a link was raised, so that
+// they are preserved for later analysis instead of being overwritten by the next monitoring poll.
+type RfSnapshot struct {
+	// Time at which the snapshot was captured.
+	CapturedAt Timestamp `json:"capturedAt"`
+
+	// Channel the access point was operating on.
+	Channel int `json:"channel"`
+
+	// Noise floor and utilization of each channel as reported by the driver's survey, in increasing order of channel.
+	Survey []ChannelSurveySample `json:"survey"`
+
+	// Devices associated with the network of each station with a team assigned, keyed by station.
+	Associations map[string][]AssociationSnapshot `json:"associations"`
+}
+
+// ChannelSurveySample represents the RF conditions of a single channel at the time of a snapshot.
+type ChannelSurveySample struct {
+	// Channel number.
+	Channel int `json:"channel"`
+
+	// Noise floor, in decibel-milliwatts.
+	NoiseDbm int `json:"noiseDbm"`
+
+	// Percentage of time the channel was sensed as busy.
+	BusyPercent float64 `json:"busyPercent"`
+}
+
+// AssociationSnapshot represents a single device associated with a network at the time of a snapshot.
+type AssociationSnapshot struct {
+	// MAC address of the device.
+	MacAddress string `json:"macAddress"`
+
+	// Signal strength, noise level and signal-to-noise ratio of the link, in dBm, dBm and dB respectively.
+	SignalDbm        int `json:"signalDbm"`
+	NoiseDbm         int `json:"noiseDbm"`
+	SignalNoiseRatio int `json:"signalNoiseRatio"`
+
+	// How long ago data was last received from the device, in milliseconds.
+	DataAgeMs int `json:"dataAgeMs"`
+
+	// Upper-bound link rates, in megabits per second.
+	RxRateMbps float64 `json:"rxRateMbps"`
+	TxRateMbps float64 `json:"txRateMbps"`
+}
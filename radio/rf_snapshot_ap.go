@@ -0,0 +1,141 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"log"
+	"sort"
+	"strconv"
+)
+
+const (
+	// Minimum drop in a link's signal-to-noise ratio between consecutive polls for it to count as collapsing, in dB.
+	snrCollapseDropDb = 15
+
+	// Signal-to-noise ratio below which a link that drops sharply counts as collapsing, in dB.
+	snrCollapseFloorDb = 20
+)
+
+// Types of alerts about a link that are accompanied by a snapshot of the RF conditions.
+var rfSnapshotAlertTypes = map[string]struct{}{
+	"CHANNEL_FAILOVER": {},
+	"HIGH_RETRY_RATE":  {},
+	"LINK_LOST":        {},
+	"SNR_COLLAPSE":     {},
+}
+
+// checkLinkEvents raises an alert if the link of the given station to the device with the given MAC address, which had
+// the given signal-to-noise ratio as of the last poll, has collapsed or been lost. Lost links are only alerted on
+// during a match, since robots routinely disconnect when they are switched off between matches.
+func (radio *Radio) checkLinkEvents(
+	station station, status *NetworkStatus, previousMacAddress string, previousSnr int,
+) {
+	if previousMacAddress == "" {
+		return
+	}
+	if !status.IsLinked {
+		if radio.isMatchLockHeld() {
+			radio.raiseStationAlert(
+				station.String(),
+				"LINK_LOST",
+				"Station %s (SSID \"%s\") lost its link during a match.",
+				station,
+				status.Ssid,
+			)
+		}
+		return
+	}
+	if status.MacAddress != previousMacAddress {
+		// A different device has taken over the link, so its signal isn't comparable.
+		return
+	}
+	snr := status.SignalNoiseRatio
+	if snr != monitoringErrorCode && previousSnr != monitoringErrorCode && snr < snrCollapseFloorDb &&
+		previousSnr-snr >= snrCollapseDropDb {
+		radio.raiseStationAlert(
+			station.String(),
+			"SNR_COLLAPSE",
+			"Station %s (SSID \"%s\") signal-to-noise ratio collapsed from %d dB to %d dB.",
+			station,
+			status.Ssid,
+			previousSnr,
+			snr,
+		)
+	}
+}
+
+// captureRfSnapshot returns a snapshot of the channel survey and the association list of each team network if the
+// given type of alert is about a link, or nil otherwise. The survey is read from the driver's counters rather than by
+// scanning so that capturing it doesn't take the radio off its channel.
+func (radio *Radio) captureRfSnapshot(alertType string) *RfSnapshot {
+	if _, ok := rfSnapshotAlertTypes[alertType]; !ok {
+		return nil
+	}
+	snapshot := RfSnapshot{
+		CapturedAt:   newTimestamp(),
+		Channel:      radio.Channel,
+		Survey:       []ChannelSurveySample{},
+		Associations: make(map[string][]AssociationSnapshot),
+	}
+
+	surveyInterface := radio.stationInterfaces[blue3]
+	if output, err := shell.runCommand("iw", "dev", surveyInterface, "survey", "dump"); err != nil {
+		log.Printf("Error running 'iw dev %s survey dump' for RF snapshot: %v", surveyInterface, err)
+	} else {
+		for channel, sample := range parseSurveyDump(output) {
+			snapshot.Survey = append(
+				snapshot.Survey,
+				ChannelSurveySample{Channel: channel, NoiseDbm: sample.noiseDbm, BusyPercent: sample.busyPercent},
+			)
+		}
+		sort.Slice(snapshot.Survey, func(i, j int) bool {
+			return snapshot.Survey[i].Channel < snapshot.Survey[j].Channel
+		})
+	}
+
+	for station := red1; station <= blue3; station++ {
+		if radio.StationStatuses[station.String()] == nil {
+			continue
+		}
+		wifiInterface := radio.stationInterfaces[station]
+		output, err := shell.runCommand("iwinfo", wifiInterface, "assoclist")
+		if err != nil {
+			log.Printf("Error getting association list for interface %s for RF snapshot: %v", wifiInterface, err)
+			continue
+		}
+		snapshot.Associations[station.String()] = parseAssociations(output)
+	}
+	return &snapshot
+}
+
+// parseAssociations parses the output of 'iwinfo [interface] assoclist' into a snapshot of every associated device,
+// including those whose associations have gone stale.
+func parseAssociations(response string) []AssociationSnapshot {
+	associations := []AssociationSnapshot{}
+	indices := assocListEntryRe.FindAllStringSubmatchIndex(response, -1)
+	for i, index := range indices {
+		end := len(response)
+		if i+1 < len(indices) {
+			end = indices[i+1][0]
+		}
+		entry := response[index[0]:end]
+		match := assocListEntryRe.FindStringSubmatch(entry)
+		if match[1] == "00:00:00:00:00:00" {
+			continue
+		}
+		association := AssociationSnapshot{MacAddress: match[1]}
+		association.SignalDbm, _ = strconv.Atoi(match[2])
+		association.NoiseDbm, _ = strconv.Atoi(match[3])
+		association.SignalNoiseRatio, _ = strconv.Atoi(match[4])
+		association.DataAgeMs, _ = strconv.Atoi(match[5])
+		if rxMatch := assocListRxRe.FindStringSubmatch(entry); rxMatch != nil {
+			association.RxRateMbps, _ = strconv.ParseFloat(rxMatch[1], 64)
+		}
+		if txMatch := assocListTxRe.FindStringSubmatch(entry); txMatch != nil {
+			association.TxRateMbps, _ = strconv.ParseFloat(txMatch[1], 64)
+		}
+		associations = append(associations, association)
+	}
+	return associations
+}
@@ -0,0 +1,157 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+const testSnapshotAssocList = "48:DA:35:B0:00:CF  -53 dBm / -95 dBm (SNR 42)  0 ms ago\n" +
+	"\tRX: 550.6 MBit/s                                4095 Pkts.\n" +
+	"\tTX: 254.0 MBit/s                                 456 Pkts.\n" +
+	"\texpected throughput: unknown\n" +
+	"\n" +
+	"00:00:00:00:00:00  -90 dBm / -95 dBm (SNR 5)  0 ms ago\n" +
+	"\tRX: 0.0 MBit/s                                      0 Pkts.\n" +
+	"\tTX: 0.0 MBit/s                                      0 Pkts.\n" +
+	"\n" +
+	"48:DA:35:B0:00:D0  -71 dBm / -95 dBm (SNR 24)  45000 ms ago\n" +
+	"\tRX: 6.0 MBit/s                                        3 Pkts.\n" +
+	"\tTX: 12.0 MBit/s                                       2 Pkts.\n"
+
+func TestParseAssociations(t *testing.T) {
+	assert.Equal(
+		t,
+		[]AssociationSnapshot{
+			{
+				MacAddress:       "48:DA:35:B0:00:CF",
+				SignalDbm:        -53,
+				NoiseDbm:         -95,
+				SignalNoiseRatio: 42,
+				RxRateMbps:       550.6,
+				TxRateMbps:       254,
+			},
+			{
+				MacAddress:       "48:DA:35:B0:00:D0",
+				SignalDbm:        -71,
+				NoiseDbm:         -95,
+				SignalNoiseRatio: 24,
+				DataAgeMs:        45000,
+				RxRateMbps:       6,
+				TxRateMbps:       12,
+			},
+		},
+		parseAssociations(testSnapshotAssocList),
+	)
+	assert.Equal(t, []AssociationSnapshot{}, parseAssociations("No station connected\n"))
+}
+
+func TestRadio_captureRfSnapshot(t *testing.T) {
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+	fakeShell.reset()
+	radio.Channel = 36
+	radio.StationStatuses["red1"] = &NetworkStatus{Ssid: "254", IsLinked: true, MacAddress: "48:DA:35:B0:00:CF"}
+	radio.StationStatuses["blue2"] = &NetworkStatus{Ssid: "1503"}
+
+	// Alerts that aren't about a link don't capture a snapshot.
+	assert.Nil(t, radio.captureRfSnapshot("CONFIG_DRIFT"))
+	assert.Empty(t, fakeShell.commandsRun)
+
+	fakeShell.commandOutput["iw dev wlan0-5 survey dump"] = testSurveyDump
+	fakeShell.commandOutput["iwinfo wlan0 assoclist"] = testSnapshotAssocList
+	fakeShell.commandErrors["iwinfo wlan0-4 assoclist"] = errors.New("oops")
+	snapshot := radio.captureRfSnapshot("SNR_COLLAPSE")
+	if assert.NotNil(t, snapshot) {
+		assert.Equal(t, 36, snapshot.Channel)
+		assert.Equal(
+			t,
+			[]ChannelSurveySample{
+				{Channel: 36, NoiseDbm: -95, BusyPercent: 25}, {Channel: 149, NoiseDbm: -80, BusyPercent: 60},
+			},
+			snapshot.Survey,
+		)
+		assert.Equal(t, parseAssociations(testSnapshotAssocList), snapshot.Associations["red1"])
+
+		// A station whose association list can't be read is left out.
+		_, ok := snapshot.Associations["blue2"]
+		assert.False(t, ok)
+		assert.Equal(t, 1, len(snapshot.Associations))
+	}
+
+	// A failed survey still leaves the association lists in the snapshot.
+	delete(fakeShell.commandOutput, "iw dev wlan0-5 survey dump")
+	fakeShell.commandErrors["iw dev wlan0-5 survey dump"] = errors.New("oops")
+	snapshot = radio.captureRfSnapshot("LINK_LOST")
+	if assert.NotNil(t, snapshot) {
+		assert.Equal(t, []ChannelSurveySample{}, snapshot.Survey)
+		assert.Equal(t, 1, len(snapshot.Associations))
+	}
+}
+
+func TestRadio_checkLinkEvents(t *testing.T) {
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+	fakeShell.reset()
+	fakeShell.commandOutput["iw dev wlan0-5 survey dump"] = testSurveyDump
+	fakeShell.commandOutput["iwinfo wlan0 assoclist"] = testSnapshotAssocList
+	red1Status := &NetworkStatus{
+		Ssid: "254", IsLinked: true, MacAddress: "48:DA:35:B0:00:CF", SignalNoiseRatio: 40,
+	}
+	radio.StationStatuses["red1"] = red1Status
+	now := time.Now()
+	radio.updateAssociationHistories(now)
+
+	// A gradual decline in SNR isn't a collapse.
+	red1Status.SignalNoiseRatio = 30
+	radio.updateAssociationHistories(now.Add(time.Second))
+	assert.Empty(t, radio.GetAlerts())
+
+	red1Status.SignalNoiseRatio = 12
+	radio.updateAssociationHistories(now.Add(2 * time.Second))
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "SNR_COLLAPSE", alerts[0].Type)
+		assert.Equal(t, "red1", alerts[0].Station)
+		assert.Equal(
+			t, "Station red1 (SSID \"254\") signal-to-noise ratio collapsed from 30 dB to 12 dB.", alerts[0].Message,
+		)
+		if assert.NotNil(t, alerts[0].RfSnapshot) {
+			assert.Equal(t, parseAssociations(testSnapshotAssocList), alerts[0].RfSnapshot.Associations["red1"])
+		}
+	}
+
+	// A sharp drop on a link that is still strong isn't a collapse, and neither is a different device taking over.
+	red1Status.SignalNoiseRatio = 45
+	radio.updateAssociationHistories(now.Add(3 * time.Second))
+	red1Status.SignalNoiseRatio = 28
+	radio.updateAssociationHistories(now.Add(4 * time.Second))
+	red1Status.MacAddress, red1Status.SignalNoiseRatio = "48:DA:35:B0:00:D0", 10
+	radio.updateAssociationHistories(now.Add(5 * time.Second))
+	assert.Equal(t, 1, len(radio.GetAlerts()))
+
+	// Losing the link is only alerted on during a match.
+	red1Status.IsLinked = false
+	radio.updateAssociationHistories(now.Add(6 * time.Second))
+	assert.Equal(t, 1, len(radio.GetAlerts()))
+	red1Status.IsLinked = true
+	radio.updateAssociationHistories(now.Add(7 * time.Second))
+	_, err := radio.AcquireMatchLock(0)
+	assert.Nil(t, err)
+	red1Status.IsLinked = false
+	radio.updateAssociationHistories(now.Add(8 * time.Second))
+	alerts = radio.GetAlerts()
+	if assert.Equal(t, 2, len(alerts)) {
+		assert.Equal(t, "LINK_LOST", alerts[1].Type)
+		assert.Equal(t, "Station red1 (SSID \"254\") lost its link during a match.", alerts[1].Message)
+		assert.NotNil(t, alerts[1].RfSnapshot)
+	}
+}
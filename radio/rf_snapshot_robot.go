@@ -0,0 +1,9 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package radio
+
+// captureRfSnapshot returns nil since the robot radio's alerts aren't accompanied by a snapshot of the RF conditions.
+func (radio *Radio) captureRfSnapshot(alertType string) *RfSnapshot {
+	return nil
+}
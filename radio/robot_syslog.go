@@ -0,0 +1,34 @@
+package radio
+
+import (
+	"fmt"
+)
+
+// Largest number of robot radio log entries that can be kept per team.
+const maxRobotLogEntriesPerTeam = 10000
+
+// RobotSyslogSettings holds the configuration of the syslog receiver that collects the logs of the robot radios on the
+// team networks, so that robot-side errors are visible from the field.
+type RobotSyslogSettings struct {
+	// UDP port on which to receive syslog messages from the robot radios. Zero disables the receiver. Only used on the
+	// access point.
+	Port int `json:"port"`
+
+	// Number of the most recent log entries kept for each team; older ones are discarded.
+	MaxEntriesPerTeam int `json:"maxEntriesPerTeam"`
+}
+
+// validate checks that the port and the number of entries kept are within range.
+func (settings RobotSyslogSettings) validate() error {
+	if settings.Port < 0 || settings.Port > 65535 {
+		return fmt.Errorf("invalid robotSyslog.port: %d (expecting 1-65535, or 0 to disable)", settings.Port)
+	}
+	if settings.MaxEntriesPerTeam < 1 || settings.MaxEntriesPerTeam > maxRobotLogEntriesPerTeam {
+		return fmt.Errorf(
+			"invalid robotSyslog.maxEntriesPerTeam: %d (expecting 1-%d)",
+			settings.MaxEntriesPerTeam,
+			maxRobotLogEntriesPerTeam,
+		)
+	}
+	return nil
+}
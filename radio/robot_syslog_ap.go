@@ -0,0 +1,169 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Longest syslog message accepted from a robot radio, in bytes; longer ones are truncated.
+const maxRobotLogMessageBytes = 1024
+
+// Names of the syslog severity levels, indexed by their numeric value.
+var syslogSeverityNames = []string{"emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"}
+
+// Regular expression matching the priority that prefixes a syslog message (e.g. "<30>").
+var syslogPriorityRe = regexp.MustCompile(`^<(\d{1,3})>`)
+
+// RobotLogEntry represents a syslog message received from a robot radio on one of the team networks.
+type RobotLogEntry struct {
+	// Time at which the message was received.
+	ReceivedAt Timestamp `json:"receivedAt"`
+
+	// Team whose network the message came from, as derived from its 10.TE.AM.x source address.
+	TeamNumber int `json:"teamNumber"`
+
+	// Team station that the team was assigned to when the message was received (e.g. "red1").
+	Station string `json:"station"`
+
+	// VLAN of the team's network when the message was received.
+	Vlan int `json:"vlan"`
+
+	// IP address of the device that sent the message.
+	SourceIp string `json:"sourceIp"`
+
+	// Severity of the message (e.g. "err" or "info"), or blank if it didn't give one.
+	Severity string `json:"severity"`
+
+	// Text of the message following its priority, typically starting with the time and the name of the process that
+	// logged it.
+	Message string `json:"message"`
+}
+
+// robotLogBuffer holds the most recent robot radio log entries of each team; it is shared between the receiver and web
+// goroutines.
+type robotLogBuffer struct {
+	mutex   sync.Mutex
+	entries map[int][]RobotLogEntry
+}
+
+// startRobotSyslogReceiver starts receiving the syslog messages of the robot radios on the configured port, if any, for
+// as long as the process runs. Changes to the port take effect when the API restarts.
+func (radio *Radio) startRobotSyslogReceiver() {
+	port := radio.GetSettings().RobotSyslog.Port
+	if port == 0 {
+		return
+	}
+	conn, err := net.ListenPacket("udp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		log.Printf("Error starting robot syslog receiver; robot radio logs won't be collected: %v", err)
+		return
+	}
+	log.Printf("Receiving robot radio logs on UDP port %d.", port)
+
+	go func() {
+		buffer := make([]byte, maxRobotLogMessageBytes)
+		for {
+			n, address, err := conn.ReadFrom(buffer)
+			if err != nil {
+				log.Printf("Error receiving robot radio logs; no longer collecting them: %v", err)
+				return
+			}
+			if udpAddress, ok := address.(*net.UDPAddr); ok {
+				radio.receiveRobotLog(udpAddress.IP, buffer[:n])
+			}
+		}
+	}()
+}
+
+// receiveRobotLog records the given syslog message from the given address, tagged with the team network that it came
+// from. Messages from addresses that don't belong to the network of a team currently assigned to a station are
+// discarded, so that the buffer can't be filled with entries for arbitrary teams.
+func (radio *Radio) receiveRobotLog(sourceIp net.IP, data []byte) {
+	teamNumber := teamNumberForIp(sourceIp)
+	if teamNumber == 0 {
+		return
+	}
+	station, vlan, ok := radio.teamStation(teamNumber)
+	if !ok {
+		return
+	}
+
+	entry := RobotLogEntry{
+		ReceivedAt: newTimestamp(),
+		TeamNumber: teamNumber,
+		Station:    station,
+		Vlan:       vlan,
+		SourceIp:   sourceIp.String(),
+	}
+	entry.Severity, entry.Message = parseSyslogMessage(data)
+
+	maxEntries := radio.GetSettings().RobotSyslog.MaxEntriesPerTeam
+	buffer := &radio.robotLogs
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	if buffer.entries == nil {
+		buffer.entries = make(map[int][]RobotLogEntry)
+	}
+	entries := append(buffer.entries[teamNumber], entry)
+	if len(entries) > maxEntries {
+		entries = entries[len(entries)-maxEntries:]
+	}
+	buffer.entries[teamNumber] = entries
+}
+
+// GetRobotLogs returns the most recent log entries received from the robot radio of the given team, oldest first.
+func (radio *Radio) GetRobotLogs(teamNumber int) []RobotLogEntry {
+	buffer := &radio.robotLogs
+	buffer.mutex.Lock()
+	defer buffer.mutex.Unlock()
+	entries := make([]RobotLogEntry, len(buffer.entries[teamNumber]))
+	copy(entries, buffer.entries[teamNumber])
+	return entries
+}
+
+// teamStation returns the station that the given team is currently assigned to and the VLAN of its network, or false
+// if the team isn't assigned to any station.
+func (radio *Radio) teamStation(teamNumber int) (string, int, bool) {
+	unlock := radio.lockStatus()
+	defer unlock()
+
+	ssid := strconv.Itoa(teamNumber)
+	for station := red1; station <= blue3; station++ {
+		if status := radio.StationStatuses[station.String()]; status != nil && status.Ssid == ssid {
+			return station.String(), radio.getStationVlan(station), true
+		}
+	}
+	return "", 0, false
+}
+
+// teamNumberForIp returns the number of the team whose 10.TE.AM.0/24 network the given address is on, or zero if it
+// isn't on a team network.
+func teamNumberForIp(ip net.IP) int {
+	ipv4 := ip.To4()
+	if ipv4 == nil || ipv4[0] != 10 || ipv4[1] > 99 || ipv4[2] > 99 {
+		return 0
+	}
+	return int(ipv4[1])*100 + int(ipv4[2])
+}
+
+// parseSyslogMessage splits the given syslog message into the name of its severity and the text following its
+// priority, with any trailing line break removed.
+func parseSyslogMessage(data []byte) (string, string) {
+	message := strings.ToValidUTF8(strings.TrimRight(string(data), "\r\n\x00"), "\uFFFD")
+	severity := ""
+	if match := syslogPriorityRe.FindStringSubmatch(message); match != nil {
+		if priority, _ := strconv.Atoi(match[1]); priority <= 191 {
+			severity = syslogSeverityNames[priority%8]
+			message = message[len(match[0]):]
+		}
+	}
+	return severity, message
+}
@@ -0,0 +1,76 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+)
+
+func TestParseSyslogMessage(t *testing.T) {
+	severity, message := parseSyslogMessage([]byte("<27>Mar  2 10:15:09 netifd: Interface 'wan' is down\n"))
+	assert.Equal(t, "err", severity)
+	assert.Equal(t, "Mar  2 10:15:09 netifd: Interface 'wan' is down", message)
+
+	severity, message = parseSyslogMessage([]byte("<191>debug message"))
+	assert.Equal(t, "debug", severity)
+	assert.Equal(t, "debug message", message)
+
+	// Messages without a valid priority are kept whole.
+	severity, message = parseSyslogMessage([]byte("<192>bogus"))
+	assert.Equal(t, "", severity)
+	assert.Equal(t, "<192>bogus", message)
+	severity, message = parseSyslogMessage([]byte("no priority\x00"))
+	assert.Equal(t, "", severity)
+	assert.Equal(t, "no priority", message)
+	_, message = parseSyslogMessage([]byte("<14>bad \xff byte"))
+	assert.Equal(t, "bad � byte", message)
+}
+
+func TestTeamNumberForIp(t *testing.T) {
+	assert.Equal(t, 254, teamNumberForIp(net.ParseIP("10.2.54.1")))
+	assert.Equal(t, 1114, teamNumberForIp(net.ParseIP("10.11.14.2")))
+	assert.Equal(t, 9, teamNumberForIp(net.ParseIP("10.0.9.1")))
+	assert.Equal(t, 0, teamNumberForIp(net.ParseIP("192.168.1.1")))
+	assert.Equal(t, 0, teamNumberForIp(net.ParseIP("10.2.154.1")))
+	assert.Equal(t, 0, teamNumberForIp(net.ParseIP("fe80::1")))
+}
+
+func TestRadio_receiveRobotLog(t *testing.T) {
+	radio := &Radio{
+		BlueVlans:       Vlans405060,
+		StationStatuses: map[string]*NetworkStatus{"blue2": {Ssid: "254"}},
+		settings:        defaultSettings(),
+	}
+	radio.settings.RobotSyslog.MaxEntriesPerTeam = 2
+
+	radio.receiveRobotLog(net.ParseIP("10.2.54.1"), []byte("<30>first"))
+	entries := radio.GetRobotLogs(254)
+	if assert.Equal(t, 1, len(entries)) {
+		assert.Equal(t, 254, entries[0].TeamNumber)
+		assert.Equal(t, "blue2", entries[0].Station)
+		assert.Equal(t, 50, entries[0].Vlan)
+		assert.Equal(t, "10.2.54.1", entries[0].SourceIp)
+		assert.Equal(t, "info", entries[0].Severity)
+		assert.Equal(t, "first", entries[0].Message)
+		assert.False(t, entries[0].ReceivedAt.Wallclock.IsZero())
+	}
+
+	// Only the most recent entries are kept.
+	radio.receiveRobotLog(net.ParseIP("10.2.54.1"), []byte("<30>second"))
+	radio.receiveRobotLog(net.ParseIP("10.2.54.1"), []byte("<30>third"))
+	entries = radio.GetRobotLogs(254)
+	if assert.Equal(t, 2, len(entries)) {
+		assert.Equal(t, "second", entries[0].Message)
+		assert.Equal(t, "third", entries[1].Message)
+	}
+
+	// Messages from outside the networks of the assigned teams are discarded.
+	radio.receiveRobotLog(net.ParseIP("10.11.14.1"), []byte("<30>unassigned team"))
+	radio.receiveRobotLog(net.ParseIP("192.168.1.1"), []byte("<30>not a team network"))
+	assert.Empty(t, radio.GetRobotLogs(1114))
+	assert.Empty(t, radio.robotLogs.entries[0])
+	assert.Equal(t, 1, len(radio.robotLogs.entries))
+}
@@ -0,0 +1,123 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"regexp"
+	"sort"
+	"time"
+)
+
+// How long a rogue network must go unseen before it is forgotten.
+const rogueNetworkExpiry = 10 * time.Minute
+
+// Regex matching SSIDs that look like a team network (i.e. a bare team number).
+var teamSsidRe = regexp.MustCompile(`^\d{1,5}$`)
+
+// RogueNetwork represents a foreign network on the field channel that appears to impersonate a team network.
+type RogueNetwork struct {
+	// SSID broadcast by the rogue network.
+	Ssid string `json:"ssid"`
+
+	// MAC address of the rogue access point.
+	Bssid string `json:"bssid"`
+
+	// Channel the rogue network was seen on.
+	Channel int `json:"channel"`
+
+	// Signal strength of the rogue network as seen by the access point in the most recent scan, in decibel-milliwatts.
+	SignalDbm int `json:"signalDbm"`
+
+	// Team station whose configured SSID the rogue network is using, or an empty string if it merely resembles a team
+	// network.
+	ImpersonatedStation string `json:"impersonatedStation"`
+
+	// Time at which the rogue network was first seen.
+	FirstSeen time.Time `json:"firstSeen"`
+
+	// Time at which the rogue network was most recently seen.
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// detectRogueNetworks records any networks from the given scan results that are on the operating channel and are using
+// the SSID of a configured team station or one resembling a team number, raising an alert for each newly seen one. Must
+// be called with the survey mutex held.
+func (radio *Radio) detectRogueNetworks(bsss []neighborBss) {
+	if radio.survey.rogueNetworks == nil {
+		radio.survey.rogueNetworks = make(map[string]*RogueNetwork)
+	}
+	stationsBySsid := make(map[string]string)
+	for stationName, stationStatus := range radio.StationStatuses {
+		if stationStatus != nil {
+			stationsBySsid[stationStatus.Ssid] = stationName
+		}
+	}
+
+	now := time.Now()
+	for _, bss := range bsss {
+		if bss.channel != radio.Channel {
+			continue
+		}
+		impersonatedStation, isConfiguredSsid := stationsBySsid[bss.ssid]
+		if !isConfiguredSsid && !teamSsidRe.MatchString(bss.ssid) {
+			continue
+		}
+
+		rogue, ok := radio.survey.rogueNetworks[bss.bssid]
+		if !ok {
+			rogue = &RogueNetwork{Bssid: bss.bssid, FirstSeen: now}
+			radio.survey.rogueNetworks[bss.bssid] = rogue
+			if isConfiguredSsid {
+				radio.raiseStationAlert(
+					impersonatedStation,
+					"ROGUE_NETWORK",
+					"Foreign network %s is impersonating station %s (SSID %q) on channel %d at %d dBm.",
+					bss.bssid,
+					impersonatedStation,
+					bss.ssid,
+					bss.channel,
+					bss.signalDbm,
+				)
+			} else {
+				radio.raiseAlert(
+					"ROGUE_NETWORK",
+					"Foreign network %s is broadcasting team-like SSID %q on channel %d at %d dBm.",
+					bss.bssid,
+					bss.ssid,
+					bss.channel,
+					bss.signalDbm,
+				)
+			}
+		}
+		rogue.Ssid = bss.ssid
+		rogue.Channel = bss.channel
+		rogue.SignalDbm = bss.signalDbm
+		rogue.ImpersonatedStation = impersonatedStation
+		rogue.LastSeen = now
+	}
+
+	for bssid, rogue := range radio.survey.rogueNetworks {
+		if now.Sub(rogue.LastSeen) > rogueNetworkExpiry {
+			delete(radio.survey.rogueNetworks, bssid)
+		}
+	}
+}
+
+// GetRogueNetworks returns the rogue networks seen recently on the operating channel, most recently seen first.
+func (radio *Radio) GetRogueNetworks() []RogueNetwork {
+	radio.survey.mutex.Lock()
+	defer radio.survey.mutex.Unlock()
+
+	rogues := make([]RogueNetwork, 0, len(radio.survey.rogueNetworks))
+	for _, rogue := range radio.survey.rogueNetworks {
+		rogues = append(rogues, *rogue)
+	}
+	sort.Slice(rogues, func(i, j int) bool {
+		if rogues[i].LastSeen.Equal(rogues[j].LastSeen) {
+			return rogues[i].Bssid < rogues[j].Bssid
+		}
+		return rogues[i].LastSeen.After(rogues[j].LastSeen)
+	})
+	return rogues
+}
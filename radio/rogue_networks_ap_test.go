@@ -0,0 +1,58 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRadio_detectRogueNetworks(t *testing.T) {
+	radio := Radio{Channel: 36, StationStatuses: map[string]*NetworkStatus{"red1": {Ssid: "1111"}, "red2": nil}}
+	assert.Equal(t, []RogueNetwork{}, radio.GetRogueNetworks())
+
+	bsss := []neighborBss{
+		{ssid: "VenueWiFi", bssid: "00:11:22:33:44:55", channel: 36, signalDbm: -60},
+		{ssid: "1111", bssid: "AA:BB:CC:DD:EE:FF", channel: 36, signalDbm: -55},
+		{ssid: "254", bssid: "AA:BB:CC:DD:EE:00", channel: 36, signalDbm: -70},
+		{ssid: "1234", bssid: "AA:BB:CC:DD:EE:11", channel: 149, signalDbm: -40},
+	}
+	radio.detectRogueNetworks(bsss)
+	rogues := radio.GetRogueNetworks()
+	if assert.Equal(t, 2, len(rogues)) {
+		assert.Equal(t, "AA:BB:CC:DD:EE:00", rogues[0].Bssid)
+		assert.Equal(t, "254", rogues[0].Ssid)
+		assert.Equal(t, "", rogues[0].ImpersonatedStation)
+		assert.Equal(t, "AA:BB:CC:DD:EE:FF", rogues[1].Bssid)
+		assert.Equal(t, "1111", rogues[1].Ssid)
+		assert.Equal(t, "red1", rogues[1].ImpersonatedStation)
+		assert.Equal(t, -55, rogues[1].SignalDbm)
+	}
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 2, len(alerts)) {
+		assert.Equal(t, "ROGUE_NETWORK", alerts[0].Type)
+		assert.Contains(t, alerts[0].Message, "impersonating station red1")
+		assert.Contains(t, alerts[1].Message, "team-like SSID \"254\"")
+	}
+
+	// Seeing the same networks again updates them without raising further alerts.
+	bsss[1].signalDbm = -50
+	radio.detectRogueNetworks(bsss)
+	assert.Equal(t, 2, len(radio.GetRogueNetworks()))
+	assert.Equal(t, 2, len(radio.GetAlerts()))
+	for _, rogue := range radio.GetRogueNetworks() {
+		if rogue.Bssid == "AA:BB:CC:DD:EE:FF" {
+			assert.Equal(t, -50, rogue.SignalDbm)
+		}
+	}
+
+	// Networks that haven't been seen for a while are forgotten.
+	radio.survey.rogueNetworks["AA:BB:CC:DD:EE:00"].LastSeen = time.Now().Add(-rogueNetworkExpiry - time.Second)
+	radio.detectRogueNetworks(nil)
+	rogues = radio.GetRogueNetworks()
+	if assert.Equal(t, 1, len(rogues)) {
+		assert.Equal(t, "AA:BB:CC:DD:EE:FF", rogues[0].Bssid)
+	}
+}
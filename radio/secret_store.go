@@ -0,0 +1,207 @@
+package radio
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/digineo/go-uci"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// UCI configuration in which the UCI backend keeps secrets. It is separate from those written by the radio
+	// configuration so that storing a secret never races with or triggers a reload of the radio.
+	secretsUciConfig = "frc_radio_api"
+
+	// Section of the UCI configuration under which secrets are kept, one option per secret.
+	secretsUciSection = "secrets"
+
+	// Prefix of environment variables from which the environment backend reads secrets.
+	secretsEnvironmentPrefix = "FRC_RADIO_API_"
+
+	// Prefix marking a stored secret as the hex-encoded SHA-256 hash of the actual secret.
+	hashedSecretPrefix = "sha256:"
+)
+
+// ErrSecretStoreReadOnly is returned when attempting to save a secret to a backend that can't be written to.
+var ErrSecretStoreReadOnly = errors.New("secret storage backend is read-only")
+
+// secretBackend represents where secrets such as the API password are kept.
+type secretBackend string
+
+const (
+	// Keep each secret in its own file on flash.
+	secretBackendFile secretBackend = "FILE"
+
+	// Keep secrets as options in a dedicated UCI configuration, so that they are included in UCI backups.
+	secretBackendUci secretBackend = "UCI"
+
+	// Read secrets from environment variables set by the init script; they can't be rotated via the API.
+	secretBackendEnvironment secretBackend = "ENVIRONMENT"
+)
+
+// SecretStorageSettings holds the policy for storing the API password and firmware decryption key.
+type SecretStorageSettings struct {
+	// Where secrets are kept.
+	Backend secretBackend `json:"backend"`
+
+	// Directory in which the file backend keeps secrets.
+	Directory string `json:"directory"`
+
+	// Whether the password is stored as a hash rather than in plain text. A plain text password is hashed in place the
+	// next time the secrets are loaded.
+	HashPasswordAtRest bool `json:"hashPasswordAtRest"`
+}
+
+// SecretStore represents the location of a single secret within a storage backend.
+type SecretStore interface {
+	// Load returns the stored secret, or an empty string if none has been stored.
+	Load() (string, error)
+
+	// Save replaces the stored secret with the given one.
+	Save(secret string) error
+
+	// String describes where the secret is kept, for logging.
+	String() string
+}
+
+// validate checks that all parameters within the secret storage settings have valid values.
+func (settings SecretStorageSettings) validate() error {
+	switch settings.Backend {
+	case secretBackendFile, secretBackendUci, secretBackendEnvironment:
+	default:
+		return fmt.Errorf("invalid secrets.backend: %s", settings.Backend)
+	}
+	if !filepath.IsAbs(settings.Directory) {
+		return fmt.Errorf("invalid secrets.directory: %q (expecting an absolute path)", settings.Directory)
+	}
+	return nil
+}
+
+// Store returns the location of the secret with the given name (e.g. "password") within the configured backend.
+func (settings SecretStorageSettings) Store(name string) SecretStore {
+	key := strings.ReplaceAll(name, "-", "_")
+	switch settings.Backend {
+	case secretBackendUci:
+		return uciSecretStore{directory: uciConfigDirectory, option: key}
+	case secretBackendEnvironment:
+		return environmentSecretStore{variable: secretsEnvironmentPrefix + strings.ToUpper(key)}
+	default:
+		return fileSecretStore{filePath: filepath.Join(settings.Directory, "frc-radio-api-"+name+".txt")}
+	}
+}
+
+// HashSecret returns the form in which the given secret is stored when it is hashed at rest.
+func HashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hashedSecretPrefix + hex.EncodeToString(hash[:])
+}
+
+// IsHashedSecret returns true if the given stored secret is a hash rather than the secret itself.
+func IsHashedSecret(storedSecret string) bool {
+	return strings.HasPrefix(storedSecret, hashedSecretPrefix)
+}
+
+// SecretMatches returns true if the given presented secret matches the stored one, which may be either in plain text
+// or hashed. The comparison takes constant time to avoid leaking the secret through response timing.
+func SecretMatches(storedSecret, presentedSecret string) bool {
+	if IsHashedSecret(storedSecret) {
+		presentedSecret = HashSecret(presentedSecret)
+	}
+	return subtle.ConstantTimeCompare([]byte(storedSecret), []byte(presentedSecret)) == 1
+}
+
+// fileSecretStore keeps a secret in a file readable only by its owner.
+type fileSecretStore struct {
+	filePath string
+}
+
+func (store fileSecretStore) Load() (string, error) {
+	secretBytes, err := os.ReadFile(store.filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(secretBytes)), nil
+}
+
+func (store fileSecretStore) Save(secret string) error {
+	tempPath := store.filePath + ".tmp"
+	if err := os.WriteFile(tempPath, []byte(secret+"\n"), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, store.filePath); err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+	return nil
+}
+
+func (store fileSecretStore) String() string {
+	return "file " + store.filePath
+}
+
+// uciSecretStore keeps a secret as an option in the dedicated UCI configuration. The configuration is read and written
+// directly rather than via the uci command so that the secret never appears in a command line.
+type uciSecretStore struct {
+	directory string
+	option    string
+}
+
+func (store uciSecretStore) Load() (string, error) {
+	tree := uci.NewTree(store.directory)
+	if err := tree.LoadConfig(secretsUciConfig, false); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+	secret, _ := tree.GetLast(secretsUciConfig, secretsUciSection, store.option)
+	return secret, nil
+}
+
+func (store uciSecretStore) Save(secret string) error {
+	// UCI values are written between single quotes without escaping.
+	if strings.ContainsAny(secret, "'\n") {
+		return errors.New("secret cannot contain single quotes or newlines when stored in UCI")
+	}
+	tree := uci.NewTree(store.directory)
+	if err := tree.AddSection(secretsUciConfig, secretsUciSection, secretsUciSection); err != nil {
+		return err
+	}
+	if !tree.Set(secretsUciConfig, secretsUciSection, store.option, secret) {
+		return fmt.Errorf("failed to set %s", store)
+	}
+	if err := tree.Commit(); err != nil {
+		return err
+	}
+	return os.Chmod(filepath.Join(store.directory, secretsUciConfig), 0600)
+}
+
+func (store uciSecretStore) String() string {
+	return fmt.Sprintf("UCI option %s.%s.%s", secretsUciConfig, secretsUciSection, store.option)
+}
+
+// environmentSecretStore reads a secret from an environment variable.
+type environmentSecretStore struct {
+	variable string
+}
+
+func (store environmentSecretStore) Load() (string, error) {
+	return strings.TrimSpace(os.Getenv(store.variable)), nil
+}
+
+func (store environmentSecretStore) Save(secret string) error {
+	return ErrSecretStoreReadOnly
+}
+
+func (store environmentSecretStore) String() string {
+	return "environment variable " + store.variable
+}
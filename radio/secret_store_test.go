@@ -0,0 +1,115 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSecretStorageSettings_Store(t *testing.T) {
+	uciConfigDirectory = "/etc/config"
+
+	store := SecretStorageSettings{Backend: secretBackendFile, Directory: "/root"}.Store("firmware-key")
+	assert.Equal(t, fileSecretStore{filePath: "/root/frc-radio-api-firmware-key.txt"}, store)
+	assert.Equal(t, "file /root/frc-radio-api-firmware-key.txt", store.String())
+
+	store = SecretStorageSettings{Backend: secretBackendUci}.Store("firmware-key")
+	assert.Equal(t, uciSecretStore{directory: "/etc/config", option: "firmware_key"}, store)
+	assert.Equal(t, "UCI option frc_radio_api.secrets.firmware_key", store.String())
+
+	store = SecretStorageSettings{Backend: secretBackendEnvironment}.Store("firmware-key")
+	assert.Equal(t, environmentSecretStore{variable: "FRC_RADIO_API_FIRMWARE_KEY"}, store)
+	assert.Equal(t, "environment variable FRC_RADIO_API_FIRMWARE_KEY", store.String())
+}
+
+func TestSecretMatches(t *testing.T) {
+	assert.True(t, SecretMatches("mypassword", "mypassword"))
+	assert.False(t, SecretMatches("mypassword", "mypassword2"))
+	assert.False(t, SecretMatches("mypassword", ""))
+
+	hashedPassword := HashSecret("mypassword")
+	assert.Equal(t, "sha256:89e01536ac207279409d4de1e5253e01f4a1769e696db0d6062ca9b8f56767c8", hashedPassword)
+	assert.True(t, IsHashedSecret(hashedPassword))
+	assert.False(t, IsHashedSecret("mypassword"))
+	assert.True(t, SecretMatches(hashedPassword, "mypassword"))
+	assert.False(t, SecretMatches(hashedPassword, "mypassword2"))
+	assert.False(t, SecretMatches(hashedPassword, hashedPassword))
+}
+
+func TestFileSecretStore(t *testing.T) {
+	store := fileSecretStore{filePath: filepath.Join(t.TempDir(), "frc-radio-api-password.txt")}
+
+	// A missing file means that no secret has been stored.
+	secret, err := store.Load()
+	assert.Nil(t, err)
+	assert.Equal(t, "", secret)
+
+	assert.Nil(t, store.Save("mypassword"))
+	secret, err = store.Load()
+	assert.Nil(t, err)
+	assert.Equal(t, "mypassword", secret)
+	fileInfo, err := os.Stat(store.filePath)
+	if assert.Nil(t, err) {
+		assert.Equal(t, os.FileMode(0600), fileInfo.Mode().Perm())
+	}
+
+	// Surrounding whitespace in a hand-edited file is ignored.
+	assert.Nil(t, os.WriteFile(store.filePath, []byte(" mypassword2\n\n"), 0600))
+	secret, err = store.Load()
+	assert.Nil(t, err)
+	assert.Equal(t, "mypassword2", secret)
+}
+
+func TestUciSecretStore(t *testing.T) {
+	directory := t.TempDir()
+	store := uciSecretStore{directory: directory, option: "password"}
+
+	// A missing configuration means that no secret has been stored.
+	secret, err := store.Load()
+	assert.Nil(t, err)
+	assert.Equal(t, "", secret)
+
+	assert.Nil(t, store.Save("mypassword"))
+	secret, err = store.Load()
+	assert.Nil(t, err)
+	assert.Equal(t, "mypassword", secret)
+	path := filepath.Join(directory, "frc_radio_api")
+	fileInfo, err := os.Stat(path)
+	if assert.Nil(t, err) {
+		assert.Equal(t, os.FileMode(0600), fileInfo.Mode().Perm())
+	}
+
+	// Saving one secret leaves the others in the configuration untouched.
+	keyStore := uciSecretStore{directory: directory, option: "firmware_key"}
+	assert.Nil(t, keyStore.Save("AGE-SECRET-KEY-1"))
+	assert.Nil(t, store.Save("mypassword2"))
+	secret, _ = store.Load()
+	assert.Equal(t, "mypassword2", secret)
+	secret, _ = keyStore.Load()
+	assert.Equal(t, "AGE-SECRET-KEY-1", secret)
+	contents, _ := os.ReadFile(path)
+	assert.Contains(t, string(contents), "option password 'mypassword2'")
+
+	assert.EqualError(
+		t, store.Save("my'password"), "secret cannot contain single quotes or newlines when stored in UCI",
+	)
+	secret, _ = store.Load()
+	assert.Equal(t, "mypassword2", secret)
+}
+
+func TestEnvironmentSecretStore(t *testing.T) {
+	store := environmentSecretStore{variable: "FRC_RADIO_API_PASSWORD"}
+
+	t.Setenv("FRC_RADIO_API_PASSWORD", "")
+	secret, err := store.Load()
+	assert.Nil(t, err)
+	assert.Equal(t, "", secret)
+
+	t.Setenv("FRC_RADIO_API_PASSWORD", "mypassword\n")
+	secret, err = store.Load()
+	assert.Nil(t, err)
+	assert.Equal(t, "mypassword", secret)
+
+	assert.Equal(t, ErrSecretStoreReadOnly, store.Save("mypassword2"))
+}
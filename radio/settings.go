@@ -0,0 +1,753 @@
+package radio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// Path to the optional file containing tunable settings for the API, in JSON format.
+	settingsFilePath = "/root/frc-radio-api-settings.json"
+
+	// Directory in which frequently rewritten state is stored by default; it resides on flash and survives reboots.
+	persistentStateDirectory = "/root"
+
+	// Directory in which frequently rewritten state is stored if stateOnTmpfs is enabled; it resides in RAM and is
+	// lost on reboot.
+	volatileStateDirectory = "/tmp"
+
+	// Maximum length of any SSID permitted by the 802.11 standard.
+	maxSsidLength = 32
+)
+
+// Settings holds tunable parameters that control the behavior of the API rather than the radio configuration itself.
+// Any fields omitted from the settings file take on their default values.
+type Settings struct {
+	// How frequently to poll the radio for its current status between configurations. This is the idle interval if
+	// adaptive polling is enabled.
+	MonitoringPollIntervalSec int `json:"monitoringPollIntervalSec"`
+
+	// Policy for polling the radio more frequently while its telemetry is most likely to be watched.
+	AdaptivePolling AdaptivePollingSettings `json:"adaptivePolling"`
+
+	// How long to wait after the last heartbeat from the FMS before taking the heartbeat action. Zero disables
+	// heartbeat monitoring.
+	HeartbeatTimeoutSec int `json:"heartbeatTimeoutSec"`
+
+	// Action to take when heartbeats from the FMS stop arriving.
+	HeartbeatAction heartbeatAction `json:"heartbeatAction"`
+
+	// How long to wait for the radio to finish starting up before attempting to recover it by reloading the Wi-Fi,
+	// restarting the network, and finally rebooting. Zero disables the boot watchdog.
+	BootWatchdogTimeoutMin int `json:"bootWatchdogTimeoutMin"`
+
+	// Whether a radio that hasn't been provisioned yet must be provisioned via POST /provision before it starts up.
+	// Intended to be enabled in images for freshly flashed radios.
+	RequireProvisioning bool `json:"requireProvisioning"`
+
+	// Whether to check the utilization of the target channel before accepting a channel change.
+	ChannelChangeGuard channelChangeGuard `json:"channelChangeGuard"`
+
+	// Policy for automatically switching to a backup channel when the current one suffers sustained interference.
+	ChannelFailover ChannelFailoverSettings `json:"channelFailover"`
+
+	// URL to which each alert is POSTed as JSON when it is raised. Blank disables the webhook.
+	AlertWebhookUrl string `json:"alertWebhookUrl"`
+
+	// Email and text message delivery of critical alerts, for monitoring the radio while nobody is at the venue.
+	AlertForwarding AlertForwardingSettings `json:"alertForwarding"`
+
+	// Temperature of the hottest thermal zone at or above which an OVERHEATING alert is raised, in degrees Celsius.
+	// Zero disables the check.
+	OverheatThresholdC float64 `json:"overheatThresholdC"`
+
+	// Percentage by which the radio's input voltage must fall below its nominal voltage for a BROWNOUT alert to be
+	// raised, where the hardware reports it. Zero disables the check.
+	BrownoutDropPercent float64 `json:"brownoutDropPercent"`
+
+	// Path of a Unix datagram socket bound by another daemon on the radio, to which status transitions and monitoring
+	// samples are sent as JSON lines. Blank disables the event socket.
+	EventSocketPath string `json:"eventSocketPath"`
+
+	// Collectors to which the link telemetry of each network is pushed after every monitoring poll, for venues with an
+	// existing telemetry pipeline. Empty disables push telemetry.
+	TelemetrySinks []TelemetrySink `json:"telemetrySinks"`
+
+	// Compact binary summary of the team stations sent over UDP, so that the field monitor stays live even if the HTTP
+	// API can't be reached.
+	StatusBeacon StatusBeaconSettings `json:"statusBeacon"`
+
+	// Receiver for the syslog messages of the robot radios on the team networks.
+	RobotSyslog RobotSyslogSettings `json:"robotSyslog"`
+
+	// Pattern for the SSID broadcast by stations without a team assigned, containing a single %d that is replaced with
+	// the station's position (1-6). The SSID is also used as the network's WPA key.
+	PlaceholderSsidPattern string `json:"placeholderSsidPattern"`
+
+	// How stations without a team assigned are presented on the field channel.
+	UnassignedStationMode unassignedStationMode `json:"unassignedStationMode"`
+
+	// Which characters the SSIDs and WPA keys given in configuration requests may contain.
+	CredentialCharset credentialCharset `json:"credentialCharset"`
+
+	// Percentage of transmissions to a station's linked device that may be retried before the station is flagged as
+	// having a high retry rate. Zero disables the flag.
+	RetryRateThresholdPercent float64 `json:"retryRateThresholdPercent"`
+
+	// Number of times per minute that clients may connect to a station's network before it is flagged as being in an
+	// association storm. Zero disables the flag.
+	AssociationStormThresholdPerMin int `json:"associationStormThresholdPerMin"`
+
+	// Relative weights of the components of each station's connection quality score. Only used on the access point.
+	QualityScoreWeights QualityScoreWeights `json:"qualityScoreWeights"`
+
+	// WPA and power save timers set on every team network at each configuration. Only used on the access point.
+	StationTimers StationTimers `json:"stationTimers"`
+
+	// How the BSSID of each team network is chosen at each configuration. Only used on the access point.
+	StationBssids StationBssidSettings `json:"stationBssids"`
+
+	// Whether to automatically deauthenticate stale associations for devices that are no longer present, which can
+	// otherwise make a robot appear linked when it isn't.
+	AutoRemoveGhostClients bool `json:"autoRemoveGhostClients"`
+
+	// Other radios (e.g. robot radios or a secondary access point) whose status is aggregated by this one.
+	FleetMembers []FleetMember `json:"fleetMembers"`
+
+	// Pairing with another access point that is kept ready to take over from this one, or that this one is kept ready
+	// to take over from. Only used on the access point.
+	Standby StandbySettings `json:"standby"`
+
+	// Wired device through which the team VLANs reach the field network, whose tagged sub-interfaces (e.g. "eth0.10")
+	// are bridged with the team networks. Only used on the access point.
+	VlanTrunkUplinkDevice string `json:"vlanTrunkUplinkDevice"`
+
+	// Whether to restore the API's configuration when a watched UCI configuration file is changed by something else,
+	// rather than adopting the change.
+	ReassertConfigurationOnDrift bool `json:"reassertConfigurationOnDrift"`
+
+	// Whether to store frequently rewritten state (e.g. the API log file) on tmpfs instead of flash, at the cost of
+	// losing it on reboot.
+	StateOnTmpfs bool `json:"stateOnTmpfs"`
+
+	// Limits on the age and total size of diagnostic files kept on the radio, enforced whenever the storage health is
+	// checked.
+	RetentionPolicies []RetentionPolicy `json:"retentionPolicies"`
+
+	// Scheduling priority and resource limits applied to the shell commands run to monitor the radio.
+	MonitoringCommandLimits CommandLimits `json:"monitoringCommandLimits"`
+
+	// Rules for rendering the status in the legacy formats expected by older FMS releases, keyed by the profile name
+	// that clients select them with. Profiles here replace any built-in profile of the same name.
+	StatusCompatProfiles map[string][]StatusCompatRule `json:"statusCompatProfiles"`
+
+	// Limits and cross-origin policy applied to the API's HTTP server.
+	HttpServer HttpServerSettings `json:"httpServer"`
+
+	// Where the API password and firmware decryption key are kept, and whether the password is hashed at rest.
+	Secrets SecretStorageSettings `json:"secrets"`
+
+	// Values substituted for the template variables in configuration requests.
+	EventVariables EventVariables `json:"eventVariables"`
+
+	// Whether to serve the simplified provisioning page for an event's radio-flashing station in place of the regular
+	// configuration page. Only used on the robot radio.
+	KioskMode bool `json:"kioskMode"`
+
+	// Hardware button that toggles maintenance mode each time it is pressed.
+	MaintenanceButton MaintenanceButtonSettings `json:"maintenanceButton"`
+
+	// Hardware status LEDs driven to reflect the link and field connection, and GPIOs reporting hardware conditions.
+	// Only used on the robot radio.
+	StatusIndicators StatusIndicatorSettings `json:"statusIndicators"`
+}
+
+// HttpServerSettings holds the limits applied to the API's HTTP server to protect the radio from slow or abusive
+// clients. A zero value disables the corresponding limit.
+type HttpServerSettings struct {
+	// Maximum time allowed for reading an entire request, including its body.
+	ReadTimeoutSec int `json:"readTimeoutSec"`
+
+	// Maximum time allowed for writing a response. Streams of followed log entries are exempt.
+	WriteTimeoutSec int `json:"writeTimeoutSec"`
+
+	// Maximum size of a request body. Firmware uploads are exempt since they are subject to their own, larger limit.
+	MaxRequestBodyBytes int64 `json:"maxRequestBodyBytes"`
+
+	// Maximum number of client connections that are served at once; further connections wait until one closes.
+	MaxConcurrentConnections int `json:"maxConcurrentConnections"`
+
+	// Maximum number of requests accepted from each client per minute, allowing bursts of up to that many; further
+	// requests are rejected until the allowance refills. Zero disables the limit.
+	MaxRequestsPerMinPerClient int `json:"maxRequestsPerMinPerClient"`
+
+	// Origins (e.g. "http://10.0.100.5:8080") from which browser-based clients such as a remote dashboard may call the
+	// API, or "*" to allow any origin. Empty disallows all cross-origin requests.
+	CorsAllowedOrigins []string `json:"corsAllowedOrigins"`
+
+	// Address (e.g. ":8082") on which to additionally serve the team status endpoint on its own, without
+	// authorization, so that it can be exposed to the driver stations. Blank disables it. Only used on the access
+	// point.
+	TeamStatusListenAddress string `json:"teamStatusListenAddress"`
+
+	// Path (e.g. "/radios/field-ap") under which every route is served, for running behind a reverse proxy that fronts
+	// several radios on one host. Blank serves the routes from the root.
+	BasePath string `json:"basePath"`
+
+	// IP addresses or CIDR ranges (e.g. "10.0.100.0/24") of the reverse proxies whose X-Forwarded-For and
+	// X-Forwarded-Proto headers are honored. The headers are ignored on requests from anywhere else.
+	TrustedProxies []string `json:"trustedProxies"`
+}
+
+// TrustedProxyNetworks returns the networks of the trusted reverse proxies, with a single IP address given as a
+// network of just that address.
+func (settings HttpServerSettings) TrustedProxyNetworks() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, proxy := range settings.TrustedProxies {
+		if network, err := parseNetwork(proxy); err == nil {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
+// parseNetwork parses the given CIDR range, or the given IP address as a network of just that address.
+func parseNetwork(value string) (*net.IPNet, error) {
+	if ip := net.ParseIP(value); ip != nil {
+		if ipv4 := ip.To4(); ipv4 != nil {
+			return &net.IPNet{IP: ipv4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+	_, network, err := net.ParseCIDR(value)
+	return network, err
+}
+
+// AdaptivePollingSettings holds the parameters for polling the radio faster while any station has a linked client or
+// shortly after a configuration, and relaxing to the regular monitoring interval otherwise.
+type AdaptivePollingSettings struct {
+	// How frequently to poll the radio while it is active. Zero disables adaptive polling.
+	ActiveIntervalSec int `json:"activeIntervalSec"`
+
+	// How long after a configuration is applied the radio continues to count as active.
+	ConfigurationWindowSec int `json:"configurationWindowSec"`
+}
+
+// FleetMember represents another radio running the API whose status can be fetched by this one.
+type FleetMember struct {
+	// Unique, human-readable name identifying the radio (e.g. "secondary-ap").
+	Name string `json:"name"`
+
+	// Base URL of the radio's API (e.g. "http://10.0.100.3").
+	Url string `json:"url"`
+
+	// Password or token to authorize requests to the radio's API, if it requires one.
+	Token string `json:"token"`
+}
+
+// StandbySettings holds the parameters for pairing the field access point with a standby that mirrors its
+// configuration, so that a failed access point can be replaced without having to configure its replacement.
+type StandbySettings struct {
+	// Part this access point plays in the pair: "PRIMARY", "STANDBY", or blank if it isn't paired.
+	Role standbyRole `json:"role"`
+
+	// Base URL of the standby's API (e.g. "http://10.0.100.3"), to which the primary mirrors its configuration.
+	StandbyUrl string `json:"standbyUrl"`
+
+	// Password or token to authorize requests to the standby's API, if it requires one.
+	StandbyToken string `json:"standbyToken"`
+}
+
+// ChannelFailoverSettings holds the thresholds for automatically switching channels under sustained interference.
+type ChannelFailoverSettings struct {
+	// Pre-approved channels to switch to, in order of preference. Empty disables failover.
+	BackupChannels []int `json:"backupChannels"`
+
+	// Percentage of time the current channel must be sensed as busy for a poll to count towards failover.
+	BusyPercentThreshold float64 `json:"busyPercentThreshold"`
+
+	// Noise floor, in decibel-milliwatts, above which a poll counts towards failover.
+	NoiseThresholdDbm int `json:"noiseThresholdDbm"`
+
+	// Number of consecutive polls over either threshold that trigger a failover.
+	ConsecutivePolls int `json:"consecutivePolls"`
+}
+
+// QualityScoreWeights holds the relative weights of the components of the connection quality score. Only their
+// proportions matter; a zero weight leaves that component out of the score.
+type QualityScoreWeights struct {
+	// Weight of the signal-to-noise ratio.
+	SignalNoiseRatio float64 `json:"signalNoiseRatio"`
+
+	// Weight of the rate at which transmissions are retried.
+	RetryRate float64 `json:"retryRate"`
+
+	// Weight of the link capacity left unused by the current bandwidth.
+	BandwidthHeadroom float64 `json:"bandwidthHeadroom"`
+
+	// Weight of how rarely the link has dropped recently.
+	AssociationStability float64 `json:"associationStability"`
+}
+
+// heartbeatAction represents what the radio does when the FMS heartbeat times out.
+type heartbeatAction string
+
+const (
+	// Log the timeout and flag it in the status, but leave the configuration untouched.
+	heartbeatActionAlert heartbeatAction = "ALERT"
+
+	// Additionally revert the radio to a safe default configuration with no teams assigned.
+	heartbeatActionRevert heartbeatAction = "REVERT"
+)
+
+// channelChangeGuard represents how a requested channel change onto a busier channel is handled.
+type channelChangeGuard string
+
+const (
+	// Accept channel changes without checking the target channel.
+	channelChangeGuardOff channelChangeGuard = "OFF"
+
+	// Raise an alert if the target channel is busier than the current one, but proceed with the change.
+	channelChangeGuardWarn channelChangeGuard = "WARN"
+
+	// Reject the request if the target channel is busier than the current one.
+	channelChangeGuardReject channelChangeGuard = "REJECT"
+)
+
+// unassignedStationMode represents how the network of a station without a team assigned is presented.
+type unassignedStationMode string
+
+const (
+	// Broadcast a network with the placeholder SSID.
+	unassignedStationModeBroadcast unassignedStationMode = "BROADCAST"
+
+	// Keep the placeholder network but hide its SSID from beacons.
+	unassignedStationModeHidden unassignedStationMode = "HIDDEN"
+
+	// Disable the network entirely.
+	unassignedStationModeDisabled unassignedStationMode = "DISABLED"
+)
+
+// standbyRole represents the part an access point plays in an active/standby pair.
+type standbyRole string
+
+const (
+	// The access point isn't paired with another.
+	standbyRoleNone standbyRole = ""
+
+	// The access point is in service and mirrors every configuration it applies to the standby.
+	standbyRolePrimary standbyRole = "PRIMARY"
+
+	// The access point tracks the primary's configuration with its team networks off the air until it takes over.
+	standbyRoleStandby standbyRole = "STANDBY"
+)
+
+// credentialCharset represents which characters may appear in the SSIDs and WPA keys given in configuration requests.
+type credentialCharset string
+
+const (
+	// Letters and digits only, plus hyphens in access point SSIDs.
+	credentialCharsetAlphanumeric credentialCharset = "ALPHANUMERIC"
+
+	// Any printable ASCII character, including spaces and punctuation.
+	credentialCharsetPrintableAscii credentialCharset = "PRINTABLE_ASCII"
+
+	// Additionally any non-ASCII UTF-8 character in SSIDs. WPA keys remain limited to printable ASCII, since WPA
+	// passphrases can't contain anything else.
+	credentialCharsetUtf8 credentialCharset = "UTF8"
+)
+
+// defaultSettings returns the settings used when no settings file is present.
+func defaultSettings() Settings {
+	return Settings{
+		MonitoringPollIntervalSec: monitoringPollIntervalSec,
+		AdaptivePolling: AdaptivePollingSettings{
+			ActiveIntervalSec:      activePollIntervalSec,
+			ConfigurationWindowSec: 120,
+		},
+		HeartbeatTimeoutSec:    0,
+		HeartbeatAction:        heartbeatActionAlert,
+		BootWatchdogTimeoutMin: 5,
+		ChannelChangeGuard:     channelChangeGuardOff,
+		ChannelFailover: ChannelFailoverSettings{
+			BusyPercentThreshold: 80,
+			NoiseThresholdDbm:    -70,
+			ConsecutivePolls:     6,
+		},
+		AlertForwarding: AlertForwardingSettings{
+			AlertTypes:     []string{"RADIO_ERROR", "STATION_FAILURE", "OVERHEATING"},
+			MinIntervalMin: 30,
+			MaxPerHour:     10,
+		},
+		OverheatThresholdC:              95,
+		BrownoutDropPercent:             10,
+		StatusBeacon:                    StatusBeaconSettings{IntervalMs: 1000},
+		RobotSyslog:                     RobotSyslogSettings{MaxEntriesPerTeam: 500},
+		VlanTrunkUplinkDevice:           "eth0",
+		PlaceholderSsidPattern:          "no-team-%d",
+		UnassignedStationMode:           unassignedStationModeBroadcast,
+		CredentialCharset:               credentialCharsetAlphanumeric,
+		RetryRateThresholdPercent:       30,
+		AssociationStormThresholdPerMin: 6,
+		QualityScoreWeights: QualityScoreWeights{
+			SignalNoiseRatio:     40,
+			RetryRate:            25,
+			BandwidthHeadroom:    15,
+			AssociationStability: 20,
+		},
+		StationTimers: StationTimers{
+			// Group rekeying is disabled by default, since each renewal causes a stall that teams notice in their
+			// control loops and the team keys are changed for every match anyway.
+			GroupRekeyIntervalSec:    0,
+			InactivityTimeoutSec:     300,
+			MaxListenIntervalBeacons: 65535,
+		},
+		StationBssids:     StationBssidSettings{Mode: bssidModeDriver},
+		RetentionPolicies: defaultRetentionPolicies(),
+		MonitoringCommandLimits: CommandLimits{
+			Commands:        []string{"luci-bwc", "iwinfo", "iw"},
+			Niceness:        10,
+			CpuTimeLimitSec: 10,
+			MemoryLimitMb:   64,
+		},
+		HttpServer: HttpServerSettings{
+			ReadTimeoutSec:           60,
+			WriteTimeoutSec:          60,
+			MaxRequestBodyBytes:      64 * 1024,
+			MaxConcurrentConnections: 32,
+		},
+		Secrets: SecretStorageSettings{
+			Backend:   secretBackendFile,
+			Directory: persistentStateDirectory,
+		},
+	}
+}
+
+// ReadSettings reads the settings file, if it exists. Returns the default settings along with the error if the file is
+// missing or invalid.
+func ReadSettings() (Settings, error) {
+	settings, err := readSettingsFile(settingsFilePath)
+	if err != nil {
+		return defaultSettings(), err
+	}
+	return settings, nil
+}
+
+// ReloadSettings re-reads the settings file and applies it to the radio without interrupting its operation. Reverts
+// to the default settings if the file has been removed, and leaves the current settings in place if it is invalid.
+func (radio *Radio) ReloadSettings() error {
+	return radio.reloadSettingsFrom(settingsFilePath)
+}
+
+// reloadSettingsFrom re-reads the settings file at the given path and applies it to the radio.
+func (radio *Radio) reloadSettingsFrom(path string) error {
+	settings, err := readSettingsFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	radio.SetSettings(settings)
+	log.Printf("Reloaded settings: %+v", settings)
+	return nil
+}
+
+// GetSettings returns a snapshot of the settings currently in effect. Callers that use several fields should take a
+// single snapshot so that a concurrent reload can't leave them with a mix of old and new values.
+func (radio *Radio) GetSettings() Settings {
+	radio.settingsMutex.RLock()
+	defer radio.settingsMutex.RUnlock()
+	return radio.settings
+}
+
+// SetSettings replaces the settings in effect with the given ones.
+func (radio *Radio) SetSettings(settings Settings) {
+	radio.settingsMutex.Lock()
+	defer radio.settingsMutex.Unlock()
+	radio.settings = settings
+	setCommandLimits(settings.MonitoringCommandLimits)
+}
+
+// readSettingsFile parses and validates the settings file at the given path.
+func readSettingsFile(path string) (Settings, error) {
+	settings := defaultSettings()
+	settingsBytes, err := os.ReadFile(path)
+	if err != nil {
+		return settings, err
+	}
+	if err = json.Unmarshal(settingsBytes, &settings); err != nil {
+		return settings, fmt.Errorf("invalid JSON: %v", err)
+	}
+	if err = settings.Validate(); err != nil {
+		return settings, err
+	}
+	return settings, nil
+}
+
+// StateFilePath returns the path at which the frequently rewritten state file with the given name should be stored.
+func (settings Settings) StateFilePath(fileName string) string {
+	if settings.StateOnTmpfs {
+		return filepath.Join(volatileStateDirectory, fileName)
+	}
+	return filepath.Join(persistentStateDirectory, fileName)
+}
+
+// Validate checks that all parameters within the settings have valid values.
+func (settings Settings) Validate() error {
+	if settings.MonitoringPollIntervalSec < 1 {
+		return fmt.Errorf("invalid monitoringPollIntervalSec: %d", settings.MonitoringPollIntervalSec)
+	}
+	if polling := settings.AdaptivePolling; polling.ActiveIntervalSec < 0 ||
+		polling.ActiveIntervalSec > settings.MonitoringPollIntervalSec {
+		return fmt.Errorf(
+			"invalid adaptivePolling.activeIntervalSec: %d (expecting 0-%d)",
+			polling.ActiveIntervalSec,
+			settings.MonitoringPollIntervalSec,
+		)
+	}
+	if settings.AdaptivePolling.ConfigurationWindowSec < 0 {
+		return fmt.Errorf(
+			"invalid adaptivePolling.configurationWindowSec: %d", settings.AdaptivePolling.ConfigurationWindowSec,
+		)
+	}
+	if settings.HeartbeatTimeoutSec < 0 {
+		return fmt.Errorf("invalid heartbeatTimeoutSec: %d", settings.HeartbeatTimeoutSec)
+	}
+	if settings.HeartbeatAction != heartbeatActionAlert && settings.HeartbeatAction != heartbeatActionRevert {
+		return fmt.Errorf("invalid heartbeatAction: %s", settings.HeartbeatAction)
+	}
+	if settings.BootWatchdogTimeoutMin < 0 {
+		return fmt.Errorf("invalid bootWatchdogTimeoutMin: %d", settings.BootWatchdogTimeoutMin)
+	}
+	switch settings.ChannelChangeGuard {
+	case channelChangeGuardOff, channelChangeGuardWarn, channelChangeGuardReject:
+	default:
+		return fmt.Errorf("invalid channelChangeGuard: %s", settings.ChannelChangeGuard)
+	}
+	if failover := settings.ChannelFailover; failover.BusyPercentThreshold <= 0 || failover.BusyPercentThreshold > 100 {
+		return fmt.Errorf("invalid channelFailover.busyPercentThreshold: %v", failover.BusyPercentThreshold)
+	}
+	if settings.ChannelFailover.ConsecutivePolls < 1 {
+		return fmt.Errorf("invalid channelFailover.consecutivePolls: %d", settings.ChannelFailover.ConsecutivePolls)
+	}
+	if err := validatePlaceholderSsidPattern(settings.PlaceholderSsidPattern); err != nil {
+		return err
+	}
+	switch settings.UnassignedStationMode {
+	case unassignedStationModeBroadcast, unassignedStationModeHidden, unassignedStationModeDisabled:
+	default:
+		return fmt.Errorf("invalid unassignedStationMode: %s", settings.UnassignedStationMode)
+	}
+	switch settings.CredentialCharset {
+	case credentialCharsetAlphanumeric, credentialCharsetPrintableAscii, credentialCharsetUtf8:
+	default:
+		return fmt.Errorf("invalid credentialCharset: %s", settings.CredentialCharset)
+	}
+	if settings.RetryRateThresholdPercent < 0 || settings.RetryRateThresholdPercent > 100 {
+		return fmt.Errorf("invalid retryRateThresholdPercent: %v", settings.RetryRateThresholdPercent)
+	}
+	if settings.AssociationStormThresholdPerMin < 0 || settings.AssociationStormThresholdPerMin > 1000 {
+		return fmt.Errorf(
+			"invalid associationStormThresholdPerMin: %d (expecting 1-1000, or 0 to disable)",
+			settings.AssociationStormThresholdPerMin,
+		)
+	}
+	if err := settings.QualityScoreWeights.validate(); err != nil {
+		return err
+	}
+	if err := settings.StationTimers.validate(); err != nil {
+		return err
+	}
+	if err := settings.StationBssids.validate(); err != nil {
+		return err
+	}
+	if settings.AlertWebhookUrl != "" && !isValidHttpUrl(settings.AlertWebhookUrl) {
+		return fmt.Errorf("invalid alertWebhookUrl: %s", settings.AlertWebhookUrl)
+	}
+	if err := settings.AlertForwarding.validate(); err != nil {
+		return err
+	}
+	if err := validateStatusCompatProfiles(settings.StatusCompatProfiles); err != nil {
+		return err
+	}
+	if settings.OverheatThresholdC < 0 || settings.OverheatThresholdC > maxOverheatThresholdC {
+		return fmt.Errorf(
+			"invalid overheatThresholdC: %v (expecting 0-%d)", settings.OverheatThresholdC, maxOverheatThresholdC,
+		)
+	}
+	if settings.BrownoutDropPercent < 0 || settings.BrownoutDropPercent > maxBrownoutDropPercent {
+		return fmt.Errorf(
+			"invalid brownoutDropPercent: %v (expecting 0-%d)", settings.BrownoutDropPercent, maxBrownoutDropPercent,
+		)
+	}
+	uplinkDevice := settings.VlanTrunkUplinkDevice
+	if uplinkDevice == "" || len(uplinkDevice) > 15 || strings.ContainsAny(uplinkDevice, "./ \t") {
+		return fmt.Errorf(
+			"invalid vlanTrunkUplinkDevice: %q (expecting a network device name of up to 15 characters)", uplinkDevice,
+		)
+	}
+	if err := validateEventSocketPath(settings.EventSocketPath); err != nil {
+		return err
+	}
+	for i, sink := range settings.TelemetrySinks {
+		if err := sink.validate(i); err != nil {
+			return err
+		}
+	}
+	if err := settings.StatusBeacon.validate(); err != nil {
+		return err
+	}
+	if err := settings.RobotSyslog.validate(); err != nil {
+		return err
+	}
+	fleetMemberNames := make(map[string]struct{})
+	for _, member := range settings.FleetMembers {
+		if member.Name == "" {
+			return errors.New("fleet member name cannot be blank")
+		}
+		if _, ok := fleetMemberNames[member.Name]; ok {
+			return fmt.Errorf("duplicate fleet member name: %s", member.Name)
+		}
+		fleetMemberNames[member.Name] = struct{}{}
+		if !isValidHttpUrl(member.Url) {
+			return fmt.Errorf("invalid URL for fleet member %s: %s", member.Name, member.Url)
+		}
+	}
+	if err := settings.Standby.validate(); err != nil {
+		return err
+	}
+	if err := validateRetentionPolicies(settings.RetentionPolicies); err != nil {
+		return err
+	}
+	if err := settings.MonitoringCommandLimits.validate(); err != nil {
+		return err
+	}
+	if err := settings.HttpServer.validate(); err != nil {
+		return err
+	}
+	if err := settings.Secrets.validate(); err != nil {
+		return err
+	}
+	if err := settings.MaintenanceButton.validate(); err != nil {
+		return err
+	}
+	if err := settings.StatusIndicators.validate(); err != nil {
+		return err
+	}
+	return settings.EventVariables.validate()
+}
+
+// validate checks that the standby role is valid and that the primary knows where to find the standby.
+func (settings StandbySettings) validate() error {
+	switch settings.Role {
+	case standbyRoleNone, standbyRoleStandby:
+	case standbyRolePrimary:
+		if !isValidHttpUrl(settings.StandbyUrl) {
+			return fmt.Errorf("invalid standby.standbyUrl: %q", settings.StandbyUrl)
+		}
+	default:
+		return fmt.Errorf("invalid standby.role: %s", settings.Role)
+	}
+	return nil
+}
+
+// validate checks that all parameters within the HTTP server settings have valid values.
+func (settings HttpServerSettings) validate() error {
+	if settings.ReadTimeoutSec < 0 {
+		return fmt.Errorf("invalid httpServer.readTimeoutSec: %d", settings.ReadTimeoutSec)
+	}
+	if settings.WriteTimeoutSec < 0 {
+		return fmt.Errorf("invalid httpServer.writeTimeoutSec: %d", settings.WriteTimeoutSec)
+	}
+	if settings.MaxRequestBodyBytes < 0 {
+		return fmt.Errorf("invalid httpServer.maxRequestBodyBytes: %d", settings.MaxRequestBodyBytes)
+	}
+	if settings.MaxConcurrentConnections < 0 {
+		return fmt.Errorf("invalid httpServer.maxConcurrentConnections: %d", settings.MaxConcurrentConnections)
+	}
+	if settings.MaxRequestsPerMinPerClient < 0 {
+		return fmt.Errorf("invalid httpServer.maxRequestsPerMinPerClient: %d", settings.MaxRequestsPerMinPerClient)
+	}
+	for _, origin := range settings.CorsAllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		if parsedUrl, err := url.Parse(origin); err != nil || !isValidHttpUrl(origin) || parsedUrl.Path != "" {
+			return fmt.Errorf("invalid httpServer.corsAllowedOrigins entry: %s", origin)
+		}
+	}
+	if settings.TeamStatusListenAddress != "" {
+		if _, port, err := net.SplitHostPort(settings.TeamStatusListenAddress); err != nil || port == "" {
+			return fmt.Errorf("invalid httpServer.teamStatusListenAddress: %s", settings.TeamStatusListenAddress)
+		}
+	}
+	if basePath := settings.BasePath; basePath != "" {
+		if parsedUrl, err := url.Parse(basePath); err != nil || !strings.HasPrefix(basePath, "/") ||
+			strings.HasSuffix(basePath, "/") || strings.Contains(basePath, "//") || parsedUrl.Path != basePath {
+			return fmt.Errorf(
+				"invalid httpServer.basePath: %q (expecting a path starting with and not ending with a slash)",
+				basePath,
+			)
+		}
+	}
+	for _, proxy := range settings.TrustedProxies {
+		if _, err := parseNetwork(proxy); err != nil {
+			return fmt.Errorf(
+				"invalid httpServer.trustedProxies entry: %q (expecting an IP address or CIDR range)", proxy,
+			)
+		}
+	}
+	return nil
+}
+
+// validate checks that all the quality score weights are non-negative and that at least one of them is positive.
+func (weights QualityScoreWeights) validate() error {
+	for _, weight := range []struct {
+		name  string
+		value float64
+	}{
+		{"signalNoiseRatio", weights.SignalNoiseRatio},
+		{"retryRate", weights.RetryRate},
+		{"bandwidthHeadroom", weights.BandwidthHeadroom},
+		{"associationStability", weights.AssociationStability},
+	} {
+		if weight.value < 0 {
+			return fmt.Errorf("invalid qualityScoreWeights.%s: %v", weight.name, weight.value)
+		}
+	}
+	if weights.total() == 0 {
+		return errors.New("invalid qualityScoreWeights: at least one weight must be positive")
+	}
+	return nil
+}
+
+// total returns the sum of the quality score weights.
+func (weights QualityScoreWeights) total() float64 {
+	return weights.SignalNoiseRatio + weights.RetryRate + weights.BandwidthHeadroom + weights.AssociationStability
+}
+
+// validatePlaceholderSsidPattern checks that the given pattern yields an SSID for each station that is also usable as a
+// WPA key.
+func validatePlaceholderSsidPattern(pattern string) error {
+	if strings.Count(pattern, "%") != 1 || strings.Count(pattern, "%d") != 1 {
+		return fmt.Errorf("invalid placeholderSsidPattern: %q (expecting a single %%d)", pattern)
+	}
+	if length := len(fmt.Sprintf(pattern, 1)); length < minWpaKeyLength || length > maxSsidLength {
+		return fmt.Errorf(
+			"invalid placeholderSsidPattern length: %d (expecting %d-%d)", length, minWpaKeyLength, maxSsidLength,
+		)
+	}
+	return nil
+}
+
+// isValidHttpUrl returns true if the given string is an absolute HTTP or HTTPS URL.
+func isValidHttpUrl(rawUrl string) bool {
+	parsedUrl, err := url.Parse(rawUrl)
+	return err == nil && (parsedUrl.Scheme == "http" || parsedUrl.Scheme == "https") && parsedUrl.Host != ""
+}
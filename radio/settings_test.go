@@ -0,0 +1,555 @@
+package radio
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadSettingsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+
+	// Missing file.
+	settings, err := readSettingsFile(path)
+	assert.NotNil(t, err)
+	assert.Equal(t, defaultSettings(), settings)
+
+	// Partial file falls back to defaults for omitted fields.
+	assert.Nil(t, os.WriteFile(path, []byte(`{"heartbeatTimeoutSec": 15}`), 0644))
+	settings, err = readSettingsFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 15, settings.HeartbeatTimeoutSec)
+	assert.Equal(t, heartbeatActionAlert, settings.HeartbeatAction)
+
+	// Full file.
+	fullSettings := `{"monitoringPollIntervalSec": 3, "adaptivePolling": {"activeIntervalSec": 2, ` +
+		`"configurationWindowSec": 60}, "heartbeatTimeoutSec": 30, "heartbeatAction": "REVERT", ` +
+		`"bootWatchdogTimeoutMin": 0, "requireProvisioning": true, "channelChangeGuard": "REJECT", ` +
+		`"channelFailover": {"backupChannels": [5, 21], "busyPercentThreshold": 60, "noiseThresholdDbm": -80, ` +
+		`"consecutivePolls": 3}, ` +
+		`"alertWebhookUrl": "http://10.0.100.5/alerts", "eventSocketPath": "/var/run/events.sock", ` +
+		`"telemetrySinks": [{"protocol": "STATSD", "address": "10.0.100.6:8125", "tags": {"venue": "SJ"}}], ` +
+		`"placeholderSsidPattern": "unassigned-%d", ` +
+		`"unassignedStationMode": "HIDDEN", "credentialCharset": "UTF8", ` +
+		`"qualityScoreWeights": {"signalNoiseRatio": 50, "retryRate": 50, "bandwidthHeadroom": 0, ` +
+		`"associationStability": 0}, "retentionPolicies": [{"name": "captures", "pattern": "/tmp/*.pcap", ` +
+		`"maxAgeHours": 12}], "monitoringCommandLimits": {"commands": ["luci-bwc"], "niceness": 15, ` +
+		`"cpuTimeLimitSec": 5, "memoryLimitMb": 0}, "secrets": {"backend": "UCI", "hashPasswordAtRest": true}}`
+	assert.Nil(t, os.WriteFile(path, []byte(fullSettings), 0644))
+	settings, err = readSettingsFile(path)
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		Settings{
+			MonitoringPollIntervalSec: 3,
+			AdaptivePolling:           AdaptivePollingSettings{ActiveIntervalSec: 2, ConfigurationWindowSec: 60},
+			HeartbeatTimeoutSec:       30,
+			HeartbeatAction:           heartbeatActionRevert,
+			BootWatchdogTimeoutMin:    0,
+			RequireProvisioning:       true,
+			ChannelChangeGuard:        channelChangeGuardReject,
+			ChannelFailover: ChannelFailoverSettings{
+				BackupChannels:       []int{5, 21},
+				BusyPercentThreshold: 60,
+				NoiseThresholdDbm:    -80,
+				ConsecutivePolls:     3,
+			},
+			AlertWebhookUrl: "http://10.0.100.5/alerts",
+			EventSocketPath: "/var/run/events.sock",
+			TelemetrySinks: []TelemetrySink{
+				{Protocol: telemetryProtocolStatsd, Address: "10.0.100.6:8125", Tags: map[string]string{"venue": "SJ"}},
+			},
+			StatusBeacon:              defaultSettings().StatusBeacon,
+			RobotSyslog:               defaultSettings().RobotSyslog,
+			PlaceholderSsidPattern:    "unassigned-%d",
+			UnassignedStationMode:     unassignedStationModeHidden,
+			CredentialCharset:         credentialCharsetUtf8,
+			RetryRateThresholdPercent: 30,
+			QualityScoreWeights:       QualityScoreWeights{SignalNoiseRatio: 50, RetryRate: 50},
+			StationTimers:             defaultSettings().StationTimers,
+			StationBssids:             defaultSettings().StationBssids,
+			RetentionPolicies:         []RetentionPolicy{{Name: "captures", Pattern: "/tmp/*.pcap", MaxAgeHours: 12}},
+			MonitoringCommandLimits:   CommandLimits{Commands: []string{"luci-bwc"}, Niceness: 15, CpuTimeLimitSec: 5},
+			AlertForwarding:           defaultSettings().AlertForwarding,
+			OverheatThresholdC:        95,
+			BrownoutDropPercent:       10,
+			VlanTrunkUplinkDevice:     "eth0",
+			HttpServer:                defaultSettings().HttpServer,
+			Secrets: SecretStorageSettings{
+				Backend: secretBackendUci, Directory: persistentStateDirectory, HashPasswordAtRest: true,
+			},
+			AssociationStormThresholdPerMin: defaultSettings().AssociationStormThresholdPerMin,
+		},
+		settings,
+	)
+
+	// Partial nested settings fall back to defaults for omitted fields.
+	assert.Nil(t, os.WriteFile(path, []byte(`{"channelFailover": {"backupChannels": [5]}}`), 0644))
+	settings, err = readSettingsFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{5}, settings.ChannelFailover.BackupChannels)
+	assert.Equal(t, 6, settings.ChannelFailover.ConsecutivePolls)
+	assert.Nil(t, os.WriteFile(path, []byte(`{"httpServer": {"corsAllowedOrigins": ["*"]}}`), 0644))
+	settings, err = readSettingsFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"*"}, settings.HttpServer.CorsAllowedOrigins)
+	assert.Equal(t, int64(64*1024), settings.HttpServer.MaxRequestBodyBytes)
+
+	// Invalid JSON.
+	assert.Nil(t, os.WriteFile(path, []byte("not JSON"), 0644))
+	_, err = readSettingsFile(path)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "invalid JSON")
+	}
+
+	// Invalid values.
+	assert.Nil(t, os.WriteFile(path, []byte(`{"heartbeatAction": "PANIC"}`), 0644))
+	_, err = readSettingsFile(path)
+	assert.EqualError(t, err, "invalid heartbeatAction: PANIC")
+}
+
+func TestSettings_Validate(t *testing.T) {
+	assert.Nil(t, defaultSettings().Validate())
+
+	settings := defaultSettings()
+	settings.HeartbeatTimeoutSec = -1
+	assert.EqualError(t, settings.Validate(), "invalid heartbeatTimeoutSec: -1")
+
+	settings = defaultSettings()
+	settings.MonitoringPollIntervalSec = 0
+	assert.EqualError(t, settings.Validate(), "invalid monitoringPollIntervalSec: 0")
+
+	settings = defaultSettings()
+	settings.AdaptivePolling.ActiveIntervalSec = 0
+	assert.Nil(t, settings.Validate())
+	settings.AdaptivePolling.ActiveIntervalSec = 6
+	assert.EqualError(t, settings.Validate(), "invalid adaptivePolling.activeIntervalSec: 6 (expecting 0-5)")
+	settings.AdaptivePolling.ActiveIntervalSec = -1
+	assert.EqualError(t, settings.Validate(), "invalid adaptivePolling.activeIntervalSec: -1 (expecting 0-5)")
+
+	settings = defaultSettings()
+	settings.AdaptivePolling.ConfigurationWindowSec = -1
+	assert.EqualError(t, settings.Validate(), "invalid adaptivePolling.configurationWindowSec: -1")
+
+	settings = defaultSettings()
+	settings.HeartbeatAction = ""
+	assert.EqualError(t, settings.Validate(), "invalid heartbeatAction: ")
+
+	settings = defaultSettings()
+	settings.ChannelChangeGuard = "MAYBE"
+	assert.EqualError(t, settings.Validate(), "invalid channelChangeGuard: MAYBE")
+
+	settings = defaultSettings()
+	settings.ChannelFailover.BusyPercentThreshold = 101
+	assert.EqualError(t, settings.Validate(), "invalid channelFailover.busyPercentThreshold: 101")
+
+	settings = defaultSettings()
+	settings.ChannelFailover.ConsecutivePolls = 0
+	assert.EqualError(t, settings.Validate(), "invalid channelFailover.consecutivePolls: 0")
+
+	settings = defaultSettings()
+	settings.PlaceholderSsidPattern = "unassigned"
+	assert.EqualError(t, settings.Validate(), "invalid placeholderSsidPattern: \"unassigned\" (expecting a single %d)")
+	settings.PlaceholderSsidPattern = "%s-%d"
+	assert.EqualError(t, settings.Validate(), "invalid placeholderSsidPattern: \"%s-%d\" (expecting a single %d)")
+	settings.PlaceholderSsidPattern = "nt-%d"
+	assert.EqualError(t, settings.Validate(), "invalid placeholderSsidPattern length: 4 (expecting 8-32)")
+	settings.PlaceholderSsidPattern = "field-station-%d"
+	assert.Nil(t, settings.Validate())
+
+	settings = defaultSettings()
+	settings.UnassignedStationMode = "INVISIBLE"
+	assert.EqualError(t, settings.Validate(), "invalid unassignedStationMode: INVISIBLE")
+
+	settings = defaultSettings()
+	settings.RetryRateThresholdPercent = 101
+	assert.EqualError(t, settings.Validate(), "invalid retryRateThresholdPercent: 101")
+	settings.RetryRateThresholdPercent = 0
+	assert.Nil(t, settings.Validate())
+
+	settings = defaultSettings()
+	settings.AssociationStormThresholdPerMin = -1
+	assert.EqualError(
+		t,
+		settings.Validate(),
+		"invalid associationStormThresholdPerMin: -1 (expecting 1-1000, or 0 to disable)",
+	)
+	settings.AssociationStormThresholdPerMin = 0
+	assert.Nil(t, settings.Validate())
+
+	settings = defaultSettings()
+	settings.QualityScoreWeights.BandwidthHeadroom = -1
+	assert.EqualError(t, settings.Validate(), "invalid qualityScoreWeights.bandwidthHeadroom: -1")
+	settings.QualityScoreWeights = QualityScoreWeights{}
+	assert.EqualError(t, settings.Validate(), "invalid qualityScoreWeights: at least one weight must be positive")
+	settings.QualityScoreWeights.AssociationStability = 1
+	assert.Nil(t, settings.Validate())
+
+	settings = defaultSettings()
+	settings.AlertWebhookUrl = "ftp://10.0.100.5"
+	assert.EqualError(t, settings.Validate(), "invalid alertWebhookUrl: ftp://10.0.100.5")
+
+	settings = defaultSettings()
+	settings.EventSocketPath = "/var/run/frc-radio-api-events.sock"
+	assert.Nil(t, settings.Validate())
+	settings.EventSocketPath = "events.sock"
+	assert.EqualError(
+		t,
+		settings.Validate(),
+		"invalid eventSocketPath: events.sock (expecting an absolute path of up to 107 characters)",
+	)
+
+	settings = defaultSettings()
+	settings.TelemetrySinks = []TelemetrySink{
+		{Protocol: telemetryProtocolInfluxUdp, Address: "10.0.100.6:8089"},
+		{Protocol: telemetryProtocolInfluxHttp, Address: "http://10.0.100.6:8086/api/v2/write?bucket=radios"},
+	}
+	assert.Nil(t, settings.Validate())
+	settings.TelemetrySinks[0].Address = "10.0.100.6"
+	assert.EqualError(t, settings.Validate(), `invalid telemetrySinks[0].address: "10.0.100.6" (expecting host:port)`)
+	settings.TelemetrySinks[0] = TelemetrySink{Protocol: "GRAPHITE", Address: "10.0.100.6:2003"}
+	assert.EqualError(
+		t,
+		settings.Validate(),
+		`invalid telemetrySinks[0].protocol: "GRAPHITE" (expecting STATSD, INFLUX_UDP, or INFLUX_HTTP)`,
+	)
+	settings.TelemetrySinks = settings.TelemetrySinks[1:]
+	settings.TelemetrySinks[0].Tags = map[string]string{"venue": ""}
+	assert.EqualError(
+		t,
+		settings.Validate(),
+		`invalid telemetrySinks[0].tags: "venue"="" (expecting a non-blank key and value)`,
+	)
+
+	settings = defaultSettings()
+	settings.MonitoringCommandLimits.Niceness = 20
+	assert.EqualError(t, settings.Validate(), "invalid monitoringCommandLimits.niceness: 20 (expecting 0-19)")
+	settings.MonitoringCommandLimits.Niceness = 0
+	settings.MonitoringCommandLimits.Commands = []string{"/usr/bin/luci-bwc"}
+	assert.EqualError(
+		t,
+		settings.Validate(),
+		`invalid monitoringCommandLimits.commands entry: "/usr/bin/luci-bwc" (expecting a command name)`,
+	)
+
+	settings = defaultSettings()
+	settings.FleetMembers = []FleetMember{{Name: "robot", Url: "http://10.12.34.1"}, {Name: "ap2", Url: "https://ap2"}}
+	assert.Nil(t, settings.Validate())
+	settings.FleetMembers[1].Name = ""
+	assert.EqualError(t, settings.Validate(), "fleet member name cannot be blank")
+	settings.FleetMembers[1].Name = "robot"
+	assert.EqualError(t, settings.Validate(), "duplicate fleet member name: robot")
+	settings.FleetMembers[1].Name = "ap2"
+	settings.FleetMembers[1].Url = "10.0.100.3"
+	assert.EqualError(t, settings.Validate(), "invalid URL for fleet member ap2: 10.0.100.3")
+
+	settings = defaultSettings()
+	settings.Standby.Role = "STANDBY"
+	assert.Nil(t, settings.Validate())
+	settings.Standby.Role = "PRIMARY"
+	assert.EqualError(t, settings.Validate(), "invalid standby.standbyUrl: \"\"")
+	settings.Standby.StandbyUrl = "http://10.0.100.3"
+	assert.Nil(t, settings.Validate())
+	settings.Standby.Role = "SECONDARY"
+	assert.EqualError(t, settings.Validate(), "invalid standby.role: SECONDARY")
+
+	settings = defaultSettings()
+	settings.HttpServer.ReadTimeoutSec = -1
+	assert.EqualError(t, settings.Validate(), "invalid httpServer.readTimeoutSec: -1")
+	settings = defaultSettings()
+	settings.HttpServer.WriteTimeoutSec = -1
+	assert.EqualError(t, settings.Validate(), "invalid httpServer.writeTimeoutSec: -1")
+	settings = defaultSettings()
+	settings.HttpServer.MaxRequestBodyBytes = -1
+	assert.EqualError(t, settings.Validate(), "invalid httpServer.maxRequestBodyBytes: -1")
+	settings = defaultSettings()
+	settings.HttpServer.MaxConcurrentConnections = -1
+	assert.EqualError(t, settings.Validate(), "invalid httpServer.maxConcurrentConnections: -1")
+	settings = defaultSettings()
+	settings.HttpServer.MaxRequestsPerMinPerClient = -1
+	assert.EqualError(t, settings.Validate(), "invalid httpServer.maxRequestsPerMinPerClient: -1")
+
+	settings = defaultSettings()
+	settings.HttpServer.CorsAllowedOrigins = []string{"*", "http://10.0.100.5:8080"}
+	assert.Nil(t, settings.Validate())
+	settings.HttpServer.CorsAllowedOrigins = []string{"http://10.0.100.5:8080/dashboard"}
+	assert.EqualError(
+		t, settings.Validate(), "invalid httpServer.corsAllowedOrigins entry: http://10.0.100.5:8080/dashboard",
+	)
+	settings.HttpServer.CorsAllowedOrigins = []string{"10.0.100.5"}
+	assert.EqualError(t, settings.Validate(), "invalid httpServer.corsAllowedOrigins entry: 10.0.100.5")
+
+	settings = defaultSettings()
+	settings.HttpServer.TeamStatusListenAddress = ":8082"
+	assert.Nil(t, settings.Validate())
+	settings.HttpServer.TeamStatusListenAddress = "8082"
+	assert.EqualError(t, settings.Validate(), "invalid httpServer.teamStatusListenAddress: 8082")
+
+	settings = defaultSettings()
+	settings.HttpServer.BasePath = "/radios/field-ap"
+	assert.Nil(t, settings.Validate())
+	for _, basePath := range []string{"radios", "/radios/", "/", "//radios", "/radios?ap=1"} {
+		settings.HttpServer.BasePath = basePath
+		assert.EqualError(
+			t,
+			settings.Validate(),
+			fmt.Sprintf(
+				"invalid httpServer.basePath: %q (expecting a path starting with and not ending with a slash)",
+				basePath,
+			),
+		)
+	}
+
+	settings = defaultSettings()
+	settings.HttpServer.TrustedProxies = []string{"10.0.100.9", "10.0.200.0/24", "fd00::1"}
+	assert.Nil(t, settings.Validate())
+	settings.HttpServer.TrustedProxies = []string{"10.0.100.0/33"}
+	assert.EqualError(
+		t,
+		settings.Validate(),
+		"invalid httpServer.trustedProxies entry: \"10.0.100.0/33\" (expecting an IP address or CIDR range)",
+	)
+
+	settings = defaultSettings()
+	settings.Secrets.Backend = "VAULT"
+	assert.EqualError(t, settings.Validate(), "invalid secrets.backend: VAULT")
+	settings = defaultSettings()
+	settings.Secrets.Directory = "secrets"
+	assert.EqualError(t, settings.Validate(), "invalid secrets.directory: \"secrets\" (expecting an absolute path)")
+
+	settings = defaultSettings()
+	settings.EventVariables.EventCode = "2024-CASJ"
+	assert.EqualError(
+		t, settings.Validate(), "invalid eventVariables.eventCode: \"2024-CASJ\" (expecting up to 16 letters and digits)",
+	)
+	settings = defaultSettings()
+	settings.EventVariables.FieldNumber = 100
+	assert.EqualError(t, settings.Validate(), "invalid eventVariables.fieldNumber: 100 (expecting 0-99)")
+
+	settings = defaultSettings()
+	settings.BootWatchdogTimeoutMin = -1
+	assert.EqualError(t, settings.Validate(), "invalid bootWatchdogTimeoutMin: -1")
+
+	settings = defaultSettings()
+	settings.RetentionPolicies = []RetentionPolicy{{Pattern: "*.pcap"}}
+	assert.EqualError(t, settings.Validate(), "retention policy name cannot be blank")
+	settings.RetentionPolicies = []RetentionPolicy{{Name: "a", Pattern: "*.pcap"}, {Name: "a", Pattern: "*.txt"}}
+	assert.EqualError(t, settings.Validate(), "duplicate retention policy name: a")
+	settings.RetentionPolicies = []RetentionPolicy{{Name: "a", Pattern: "[.pcap"}}
+	assert.EqualError(t, settings.Validate(), "invalid pattern for retention policy a: \"[.pcap\"")
+	settings.RetentionPolicies = []RetentionPolicy{{Name: "a", Pattern: "*.pcap", MaxAgeHours: -1}}
+	assert.EqualError(t, settings.Validate(), "invalid maxAgeHours for retention policy a: -1")
+	settings.RetentionPolicies = []RetentionPolicy{{Name: "a", Pattern: "*.pcap", MaxTotalBytes: -1}}
+	assert.EqualError(t, settings.Validate(), "invalid maxTotalBytes for retention policy a: -1")
+
+	settings = defaultSettings()
+	settings.CredentialCharset = "EMOJI"
+	assert.EqualError(t, settings.Validate(), "invalid credentialCharset: EMOJI")
+
+	settings = defaultSettings()
+	settings.MaintenanceButton.GpioValuePath = "gpio12/value"
+	assert.EqualError(
+		t, settings.Validate(), "invalid maintenanceButton.gpioValuePath: \"gpio12/value\" (expecting an absolute path)",
+	)
+
+	settings = defaultSettings()
+	settings.StatusIndicators = StatusIndicatorSettings{
+		LinkLedPath:  "/sys/class/leds/link",
+		FieldLedPath: "/sys/class/leds/field",
+		Inputs:       []GpioInputSettings{{Name: "poePower", GpioValuePath: "/sys/class/gpio/gpio17/value"}},
+	}
+	assert.Nil(t, settings.Validate())
+	settings.StatusIndicators.FieldLedPath = "leds/field"
+	assert.EqualError(
+		t, settings.Validate(), "invalid statusIndicators.fieldLedPath: \"leds/field\" (expecting an absolute path)",
+	)
+	settings.StatusIndicators.FieldLedPath = ""
+	settings.StatusIndicators.Inputs = append(settings.StatusIndicators.Inputs, GpioInputSettings{Name: "poePower"})
+	assert.EqualError(t, settings.Validate(), "duplicate status indicator input name: poePower")
+	settings.StatusIndicators.Inputs[1] = GpioInputSettings{Name: "barrelPower", GpioValuePath: "gpio18/value"}
+	assert.EqualError(
+		t,
+		settings.Validate(),
+		"invalid gpioValuePath for status indicator input barrelPower: \"gpio18/value\" (expecting an absolute path)",
+	)
+	settings.StatusIndicators.Inputs[1] = GpioInputSettings{GpioValuePath: "/sys/class/gpio/gpio18/value"}
+	assert.EqualError(t, settings.Validate(), "status indicator input name cannot be blank")
+
+	settings = defaultSettings()
+	settings.AlertForwarding.Smtp = SmtpRelaySettings{
+		Address: "smtp.example.org:587", From: "radio@example.org", To: []string{"fta@example.org"},
+	}
+	settings.AlertForwarding.SmsGateway = SmsGatewaySettings{
+		Url: "https://sms.example.org/send", Recipients: []string{"+15555550100"},
+	}
+	settings.AlertForwarding.QuietHours = AlertQuietHours{StartTime: "22:00", EndTime: "07:00"}
+	assert.Nil(t, settings.Validate())
+	settings.AlertForwarding.Smtp.Address = "smtp.example.org"
+	assert.EqualError(
+		t, settings.Validate(), "invalid alertForwarding.smtp.address: \"smtp.example.org\" (expecting host:port)",
+	)
+	settings.AlertForwarding.Smtp.Address = "smtp.example.org:25"
+	settings.AlertForwarding.Smtp.To = nil
+	assert.EqualError(t, settings.Validate(), "alertForwarding.smtp.to cannot be empty")
+	settings.AlertForwarding.Smtp = SmtpRelaySettings{}
+	settings.AlertForwarding.SmsGateway.Url = "sms.example.org"
+	assert.EqualError(
+		t, settings.Validate(), "invalid alertForwarding.smsGateway.url: \"sms.example.org\" (expecting an HTTP URL)",
+	)
+	settings.AlertForwarding.SmsGateway = SmsGatewaySettings{}
+	settings.AlertForwarding.QuietHours.EndTime = "7am"
+	assert.EqualError(
+		t, settings.Validate(), "invalid alertForwarding.quietHours.endTime: \"7am\" (expecting HH:MM)",
+	)
+	settings.AlertForwarding.QuietHours = AlertQuietHours{}
+	settings.AlertForwarding.MaxPerHour = -1
+	assert.EqualError(t, settings.Validate(), "invalid alertForwarding.maxPerHour: -1")
+
+	settings = defaultSettings()
+	settings.StatusCompatProfiles = map[string][]StatusCompatRule{
+		"fms2023": {{Path: "stationStatuses.*.signalNoiseRatio", LegacyName: "snr"}},
+	}
+	assert.Nil(t, settings.Validate())
+	settings.StatusCompatProfiles["fms2023"][0].Path = "stationStatuses..snr"
+	assert.EqualError(t, settings.Validate(), "invalid statusCompatProfiles.fms2023[0].path: \"stationStatuses..snr\"")
+	settings.StatusCompatProfiles["fms2023"][0] = StatusCompatRule{Path: "channel", Conversion: "HEX"}
+	assert.EqualError(
+		t,
+		settings.Validate(),
+		"invalid statusCompatProfiles.fms2023[0].conversion: \"HEX\" (expecting OMIT, WALLCLOCK, MESSAGE, STRING, "+
+			"NUMBER, or blank)",
+	)
+	settings.StatusCompatProfiles = map[string][]StatusCompatRule{"": {}}
+	assert.EqualError(t, settings.Validate(), "status compatibility profile name cannot be blank")
+
+	settings = defaultSettings()
+	settings.OverheatThresholdC = 200
+	assert.EqualError(t, settings.Validate(), "invalid overheatThresholdC: 200 (expecting 0-150)")
+
+	settings = defaultSettings()
+	settings.BrownoutDropPercent = -5
+	assert.EqualError(t, settings.Validate(), "invalid brownoutDropPercent: -5 (expecting 0-50)")
+
+	settings = defaultSettings()
+	settings.StationTimers.GroupRekeyIntervalSec = 30
+	assert.EqualError(
+		t,
+		settings.Validate(),
+		"invalid stationTimers.groupRekeyIntervalSec: 30 (expecting 60-86400, or 0 to disable rekeying)",
+	)
+	settings.StationTimers.GroupRekeyIntervalSec = 3600
+	assert.Nil(t, settings.Validate())
+	settings.StationTimers.InactivityTimeoutSec = 5
+	assert.EqualError(t, settings.Validate(), "invalid stationTimers.inactivityTimeoutSec: 5 (expecting 10-86400)")
+	settings.StationTimers.InactivityTimeoutSec = 60
+	settings.StationTimers.MaxListenIntervalBeacons = 0
+	assert.EqualError(t, settings.Validate(), "invalid stationTimers.maxListenIntervalBeacons: 0 (expecting 1-65535)")
+
+	settings = defaultSettings()
+	settings.VlanTrunkUplinkDevice = "eth0.10"
+	assert.EqualError(
+		t,
+		settings.Validate(),
+		"invalid vlanTrunkUplinkDevice: \"eth0.10\" (expecting a network device name of up to 15 characters)",
+	)
+	settings.VlanTrunkUplinkDevice = ""
+	assert.EqualError(
+		t,
+		settings.Validate(),
+		"invalid vlanTrunkUplinkDevice: \"\" (expecting a network device name of up to 15 characters)",
+	)
+
+	settings = defaultSettings()
+	settings.StationBssids = StationBssidSettings{
+		Mode: bssidModePinned, Addresses: map[string]string{"red1": "02:11:22:33:44:55", "blue3": "00:0A:95:9D:68:16"},
+	}
+	assert.Nil(t, settings.Validate())
+	settings.StationBssids.Addresses["blue3"] = "01:00:5e:00:00:01"
+	assert.EqualError(
+		t,
+		settings.Validate(),
+		"invalid stationBssids.addresses entry for blue3: \"01:00:5e:00:00:01\" (expecting a unicast MAC address)",
+	)
+	settings.StationBssids.Addresses = map[string]string{"red4": "02:11:22:33:44:55"}
+	assert.EqualError(t, settings.Validate(), "invalid stationBssids.addresses station: red4")
+	settings.StationBssids = StationBssidSettings{Mode: "STATIC"}
+	assert.EqualError(t, settings.Validate(), "invalid stationBssids.mode: STATIC")
+
+	settings = defaultSettings()
+	settings.StatusBeacon.Address = "10.0.100.255:1161"
+	assert.Nil(t, settings.Validate())
+	for _, address := range []string{"10.0.100.5", "fms:1161", "10.0.100.5:0", "10.0.100.5:http"} {
+		settings.StatusBeacon.Address = address
+		assert.EqualError(
+			t,
+			settings.Validate(),
+			fmt.Sprintf("invalid statusBeacon.address: %q (expecting an IP address and port)", address),
+		)
+	}
+	settings = defaultSettings()
+	settings.StatusBeacon.IntervalMs = 50
+	assert.EqualError(t, settings.Validate(), "invalid statusBeacon.intervalMs: 50 (expecting 100-10000)")
+
+	settings = defaultSettings()
+	settings.RobotSyslog.Port = 5514
+	assert.Nil(t, settings.Validate())
+	settings.RobotSyslog.Port = 65536
+	assert.EqualError(t, settings.Validate(), "invalid robotSyslog.port: 65536 (expecting 1-65535, or 0 to disable)")
+	settings = defaultSettings()
+	settings.RobotSyslog.MaxEntriesPerTeam = 0
+	assert.EqualError(t, settings.Validate(), "invalid robotSyslog.maxEntriesPerTeam: 0 (expecting 1-10000)")
+}
+
+func TestRadio_reloadSettingsFrom(t *testing.T) {
+	radio := Radio{settings: defaultSettings()}
+	path := filepath.Join(t.TempDir(), "settings.json")
+
+	assert.Nil(t, os.WriteFile(path, []byte(`{"monitoringPollIntervalSec": 8, "heartbeatTimeoutSec": 20}`), 0644))
+	assert.Nil(t, radio.reloadSettingsFrom(path))
+	assert.Equal(t, 8, radio.GetSettings().MonitoringPollIntervalSec)
+	assert.Equal(t, 20, radio.GetSettings().HeartbeatTimeoutSec)
+
+	// Invalid file leaves the current settings in place.
+	assert.Nil(t, os.WriteFile(path, []byte(`{"monitoringPollIntervalSec": -1}`), 0644))
+	assert.EqualError(t, radio.reloadSettingsFrom(path), "invalid monitoringPollIntervalSec: -1")
+	assert.Equal(t, 8, radio.GetSettings().MonitoringPollIntervalSec)
+
+	// Removed file reverts to the defaults.
+	assert.Nil(t, os.Remove(path))
+	assert.Nil(t, radio.reloadSettingsFrom(path))
+	assert.Equal(t, defaultSettings(), radio.GetSettings())
+
+	// Reloading is safe while the settings are being read from another goroutine.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			assert.Equal(t, monitoringPollIntervalSec, radio.GetSettings().MonitoringPollIntervalSec)
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, radio.reloadSettingsFrom(path))
+	}
+	<-done
+}
+
+func TestSettings_StateFilePath(t *testing.T) {
+	settings := defaultSettings()
+	assert.Equal(t, "/root/frc-radio-api.log", settings.StateFilePath("frc-radio-api.log"))
+
+	settings.StateOnTmpfs = true
+	assert.Equal(t, "/tmp/frc-radio-api.log", settings.StateFilePath("frc-radio-api.log"))
+}
+
+func TestHttpServerSettings_TrustedProxyNetworks(t *testing.T) {
+	settings := HttpServerSettings{TrustedProxies: []string{"10.0.100.9", "10.0.200.0/24", "fd00::1"}}
+	networks := settings.TrustedProxyNetworks()
+	if assert.Equal(t, 3, len(networks)) {
+		assert.Equal(t, "10.0.100.9/32", networks[0].String())
+		assert.Equal(t, "10.0.200.0/24", networks[1].String())
+		assert.Equal(t, "fd00::1/128", networks[2].String())
+	}
+	assert.Nil(t, HttpServerSettings{}.TrustedProxyNetworks())
+}
@@ -0,0 +1,189 @@
+package radio
+
+import (
+	"errors"
+	"math"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Maximum number of recent shell command failures to retain; older ones are discarded first.
+	maxShellCommandFailures = 50
+
+	// Maximum length of command output to retain for a failure, in bytes.
+	maxShellFailureOutputLength = 500
+)
+
+var (
+	shellInterfaceArgRe  = regexp.MustCompile(`^(?:wlan\d+(?:-\d+)?|ath\d+|phy\d+|wifi\d+)$`)
+	shellMacAddressArgRe = regexp.MustCompile(`^(?:[0-9A-Fa-f]{2}:){5}[0-9A-Fa-f]{2}$`)
+	shellNumberArgRe     = regexp.MustCompile(`^-?\d+[KMG]?$`)
+)
+
+// Statistics on the shell commands run by the API since it started.
+var shellTelemetry commandTelemetry
+
+// ShellCommandStats summarizes all runs of a single class of shell command.
+type ShellCommandStats struct {
+	// Command and arguments, with variable arguments such as interface names replaced by placeholders (e.g.
+	// "iwinfo <interface> assoclist").
+	CommandClass string `json:"commandClass"`
+
+	// Number of times the command has been run.
+	RunCount int `json:"runCount"`
+
+	// Number of times the command has failed.
+	FailureCount int `json:"failureCount"`
+
+	// Mean time taken by the command to complete, in milliseconds.
+	AverageDurationMs float64 `json:"averageDurationMs"`
+
+	// Longest time taken by the command to complete, in milliseconds.
+	MaxDurationMs float64 `json:"maxDurationMs"`
+
+	// Time at which the command was last run.
+	LastRun Timestamp `json:"lastRun"`
+
+	totalDuration time.Duration
+}
+
+// ShellCommandFailure represents a single failed run of a shell command.
+type ShellCommandFailure struct {
+	// Time at which the command finished.
+	Time Timestamp `json:"time"`
+
+	// Full command line that was run.
+	Command string `json:"command"`
+
+	// Exit status of the command, or -1 if it couldn't be started or was killed.
+	ExitStatus int `json:"exitStatus"`
+
+	// Description of the error.
+	Error string `json:"error"`
+
+	// Time taken by the command, in milliseconds.
+	DurationMs float64 `json:"durationMs"`
+
+	// Beginning of the command's output, if any.
+	Output string `json:"output"`
+}
+
+// ShellTelemetry represents the aggregate statistics and recent failures of the shell commands run by the API.
+type ShellTelemetry struct {
+	// Statistics for each class of command, ordered by command class.
+	Commands []ShellCommandStats `json:"commands"`
+
+	// Most recent failures, oldest first.
+	RecentFailures []ShellCommandFailure `json:"recentFailures"`
+}
+
+// commandTelemetry accumulates statistics on shell commands; it is shared between all goroutines that run commands.
+type commandTelemetry struct {
+	mutex    sync.Mutex
+	stats    map[string]*ShellCommandStats
+	failures []ShellCommandFailure
+}
+
+// record notes that the given command took the given duration and finished with the given error and output.
+func (telemetry *commandTelemetry) record(
+	command string, args []string, duration time.Duration, output string, err error,
+) {
+	now := newTimestamp()
+	telemetry.mutex.Lock()
+	defer telemetry.mutex.Unlock()
+	if telemetry.stats == nil {
+		telemetry.stats = make(map[string]*ShellCommandStats)
+	}
+
+	commandClass := classifyShellCommand(command, args)
+	stats, ok := telemetry.stats[commandClass]
+	if !ok {
+		stats = &ShellCommandStats{CommandClass: commandClass}
+		telemetry.stats[commandClass] = stats
+	}
+	stats.RunCount++
+	stats.totalDuration += duration
+	stats.AverageDurationMs = durationToMs(stats.totalDuration / time.Duration(stats.RunCount))
+	stats.MaxDurationMs = math.Max(stats.MaxDurationMs, durationToMs(duration))
+	stats.LastRun = now
+	if err == nil {
+		return
+	}
+
+	stats.FailureCount++
+	exitStatus := -1
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		exitStatus = exitErr.ExitCode()
+	}
+	if len(output) > maxShellFailureOutputLength {
+		output = output[:maxShellFailureOutputLength]
+	}
+	telemetry.failures = append(
+		telemetry.failures,
+		ShellCommandFailure{
+			Time:       now,
+			Command:    strings.Join(append([]string{command}, args...), " "),
+			ExitStatus: exitStatus,
+			Error:      err.Error(),
+			DurationMs: durationToMs(duration),
+			Output:     output,
+		},
+	)
+	if len(telemetry.failures) > maxShellCommandFailures {
+		telemetry.failures = telemetry.failures[len(telemetry.failures)-maxShellCommandFailures:]
+	}
+}
+
+// get returns a snapshot of the accumulated statistics.
+func (telemetry *commandTelemetry) get() ShellTelemetry {
+	telemetry.mutex.Lock()
+	defer telemetry.mutex.Unlock()
+	snapshot := ShellTelemetry{
+		Commands:       make([]ShellCommandStats, 0, len(telemetry.stats)),
+		RecentFailures: make([]ShellCommandFailure, len(telemetry.failures)),
+	}
+	for _, stats := range telemetry.stats {
+		snapshot.Commands = append(snapshot.Commands, *stats)
+	}
+	sort.Slice(snapshot.Commands, func(i, j int) bool {
+		return snapshot.Commands[i].CommandClass < snapshot.Commands[j].CommandClass
+	})
+	copy(snapshot.RecentFailures, telemetry.failures)
+	return snapshot
+}
+
+// GetShellTelemetry returns the aggregate statistics and recent failures of the shell commands run by the API.
+func GetShellTelemetry() ShellTelemetry {
+	return shellTelemetry.get()
+}
+
+// classifyShellCommand returns the given command line with its variable arguments replaced by placeholders, so that
+// runs of the same command against e.g. different interfaces are aggregated together.
+func classifyShellCommand(command string, args []string) string {
+	parts := []string{command}
+	for _, arg := range args {
+		switch {
+		case shellInterfaceArgRe.MatchString(arg):
+			arg = "<interface>"
+		case shellMacAddressArgRe.MatchString(arg):
+			arg = "<mac>"
+		case shellNumberArgRe.MatchString(arg):
+			arg = "<number>"
+		case strings.Contains(arg, " "):
+			arg = "<script>"
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+// durationToMs converts the given duration to milliseconds, truncated to the microsecond.
+func durationToMs(duration time.Duration) float64 {
+	return float64(duration.Microseconds()) / 1000
+}
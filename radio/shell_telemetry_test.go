@@ -0,0 +1,85 @@
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandTelemetry(t *testing.T) {
+	var telemetry commandTelemetry
+	assert.Equal(
+		t, ShellTelemetry{Commands: []ShellCommandStats{}, RecentFailures: []ShellCommandFailure{}}, telemetry.get(),
+	)
+
+	telemetry.record("iwinfo", []string{"wlan0", "assoclist"}, 10*time.Millisecond, "", nil)
+	telemetry.record("iwinfo", []string{"wlan0-1", "assoclist"}, 30*time.Millisecond, "", nil)
+	telemetry.record(
+		"iwinfo", []string{"wlan0-2", "assoclist"}, 2*time.Millisecond, "No such wireless device", errors.New("oops"),
+	)
+	telemetry.record("wifi", []string{"reload", "wifi1"}, 1500*time.Millisecond, "", nil)
+
+	snapshot := telemetry.get()
+	if assert.Equal(t, 2, len(snapshot.Commands)) {
+		stats := snapshot.Commands[0]
+		assert.Equal(t, "iwinfo <interface> assoclist", stats.CommandClass)
+		assert.Equal(t, 3, stats.RunCount)
+		assert.Equal(t, 1, stats.FailureCount)
+		assert.Equal(t, 14.0, stats.AverageDurationMs)
+		assert.Equal(t, 30.0, stats.MaxDurationMs)
+		assert.False(t, stats.LastRun.Wallclock.IsZero())
+		assert.Equal(t, "wifi reload <interface>", snapshot.Commands[1].CommandClass)
+		assert.Equal(t, 1500.0, snapshot.Commands[1].MaxDurationMs)
+	}
+	if assert.Equal(t, 1, len(snapshot.RecentFailures)) {
+		failure := snapshot.RecentFailures[0]
+		assert.Equal(t, "iwinfo wlan0-2 assoclist", failure.Command)
+		assert.Equal(t, -1, failure.ExitStatus)
+		assert.Equal(t, "oops", failure.Error)
+		assert.Equal(t, 2.0, failure.DurationMs)
+		assert.Equal(t, "No such wireless device", failure.Output)
+	}
+
+	// Only the most recent failures should be retained, with their output truncated.
+	for i := 0; i < maxShellCommandFailures+5; i++ {
+		telemetry.record("logread", []string{"-e", "hostapd"}, 0, strings.Repeat("x", 1000), errors.New("oops"))
+	}
+	snapshot = telemetry.get()
+	assert.Equal(t, maxShellCommandFailures, len(snapshot.RecentFailures))
+	assert.Equal(t, "logread -e hostapd", snapshot.RecentFailures[0].Command)
+	assert.Equal(t, maxShellFailureOutputLength, len(snapshot.RecentFailures[0].Output))
+}
+
+func TestExecShell_telemetry(t *testing.T) {
+	shellTelemetry = commandTelemetry{}
+	t.Cleanup(func() { shellTelemetry = commandTelemetry{} })
+
+	_, err := execShell{}.runCommand("sh", "-c", "echo failing && exit 3")
+	assert.NotNil(t, err)
+	snapshot := GetShellTelemetry()
+	if assert.Equal(t, 1, len(snapshot.Commands)) {
+		assert.Equal(t, "sh -c <script>", snapshot.Commands[0].CommandClass)
+		assert.Equal(t, 1, snapshot.Commands[0].FailureCount)
+	}
+	if assert.Equal(t, 1, len(snapshot.RecentFailures)) {
+		assert.Equal(t, 3, snapshot.RecentFailures[0].ExitStatus)
+		assert.Equal(t, "failing\n", snapshot.RecentFailures[0].Output)
+	}
+}
+
+func TestClassifyShellCommand(t *testing.T) {
+	assert.Equal(t, "iwinfo <interface> info", classifyShellCommand("iwinfo", []string{"ath1", "info"}))
+	assert.Equal(
+		t,
+		"hostapd_cli -i <interface> deauthenticate <mac>",
+		classifyShellCommand("hostapd_cli", []string{"-i", "wlan0-3", "deauthenticate", "48:DA:35:B0:00:CF"}),
+	)
+	assert.Equal(t, "df -k /overlay", classifyShellCommand("df", []string{"-k", "/overlay"}))
+	assert.Equal(
+		t,
+		"iperf3 --server-bitrate-limit <number>",
+		classifyShellCommand("iperf3", []string{"--server-bitrate-limit", "100M"}),
+	)
+}
@@ -1,6 +1,17 @@
 package radio
 
-import "os/exec"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"time"
+)
+
+// How long a command run to completion may take before it is killed. Commands that are expected to run for longer are
+// streamed instead.
+var shellCommandTimeout = 2 * time.Minute
 
 // shellWrapper is an interface to wrap running CLI commands, to facilitate testing.
 type shellWrapper interface {
@@ -9,16 +20,49 @@ type shellWrapper interface {
 
 	// startCommand starts the given command with the given arguments without waiting for it to finish.
 	startCommand(command string, args ...string) error
+
+	// streamCommand runs the given command with the given arguments, copying its output to the given writer as it is
+	// produced, until the command exits or the context is cancelled.
+	streamCommand(ctx context.Context, output io.Writer, command string, args ...string) error
 }
 
-// execShell is an implementation of the shellWrapper interface that runs commands using the exec package.
+// execShell is an implementation of the shellWrapper interface that runs commands using the exec package. Every
+// command is recorded in the shell telemetry, and monitoring commands are run under the configured command limits.
 type execShell struct{}
 
 func (shell execShell) runCommand(command string, args ...string) (string, error) {
-	outputBytes, err := exec.Command(command, args...).CombinedOutput()
+	startTime := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), shellCommandTimeout)
+	defer cancel()
+	limitedCommand, limitedArgs := limitCommand(command, args)
+	outputBytes, err := exec.CommandContext(ctx, limitedCommand, limitedArgs...).CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%w: %s killed after %v", ErrShellTimeout, command, shellCommandTimeout)
+	}
+	shellTelemetry.record(command, args, time.Since(startTime), string(outputBytes), err)
 	return string(outputBytes), err
 }
 
 func (shell execShell) startCommand(command string, args ...string) error {
-	return exec.Command(command, args...).Start()
+	startTime := time.Now()
+	limitedCommand, limitedArgs := limitCommand(command, args)
+	err := exec.Command(limitedCommand, limitedArgs...).Start()
+	shellTelemetry.record(command, args, time.Since(startTime), "", err)
+	return err
+}
+
+func (shell execShell) streamCommand(ctx context.Context, output io.Writer, command string, args ...string) error {
+	startTime := time.Now()
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	err := cmd.Run()
+
+	// A command that was deliberately stopped by cancelling the context didn't fail.
+	telemetryErr := err
+	if ctx.Err() != nil {
+		telemetryErr = nil
+	}
+	shellTelemetry.record(command, args, time.Since(startTime), "", telemetryErr)
+	return err
 }
@@ -0,0 +1,147 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"fmt"
+	"github.com/digineo/go-uci"
+	"log"
+	"strings"
+)
+
+// Script that sets the given hostapd option on the given interface to the WPA key of the given wireless section, read
+// back from the committed UCI configuration so that the key never appears in a command line.
+const hostapdSetKeyScript = `hostapd_cli -i "$0" set "$1" "$(uci -q get "wireless.$2.key")"`
+
+// keyOnlyChanges returns the new WPA key of each station whose key the given request changes, if that is all that it
+// changes and the networks can be rekeyed in place; otherwise it returns nil. Fields of the request that are equal to
+// the current configuration count as unchanged. Must be called before any of the request is applied.
+func (radio *Radio) keyOnlyChanges(
+	request ConfigurationRequest, stationConfigurations map[string]*StationConfiguration,
+) map[station]string {
+	if !radio.driverQuirks().softKeyChange || radio.isEmergencyStopActive() {
+		return nil
+	}
+	settings := radio.GetSettings()
+	if radio.StationTimers != settings.StationTimers || radio.UnassignedStationMode != settings.UnassignedStationMode {
+		return nil
+	}
+
+	others := request
+	others.StationConfigurations = nil
+	if others.Channel == radio.Channel {
+		others.Channel = 0
+	}
+	if others.ChannelBandwidth == radio.ChannelBandwidth {
+		others.ChannelBandwidth = ""
+	}
+	if others.RedVlans == radio.RedVlans && others.BlueVlans == radio.BlueVlans {
+		others.RedVlans, others.BlueVlans = "", ""
+	}
+	if others.Country == radio.Country {
+		others.Country = ""
+	}
+	if others.SyslogIpAddress == radio.SyslogIpAddress {
+		others.SyslogIpAddress = ""
+	}
+	if !others.IsEmpty() {
+		return nil
+	}
+
+	changes := make(map[station]string)
+	for station := red1; station <= blue3; station++ {
+		config := stationConfigurations[station.String()]
+		status := radio.StationStatuses[station.String()]
+		if config == nil && status == nil {
+			continue
+		}
+		if config == nil || status == nil || config.Ssid != status.Ssid || config.ManagementFrameProtection != "" ||
+			!radio.isStationBssidCurrent(station) {
+			return nil
+		}
+		if currentKey, _ := uciTree.GetLast("wireless", wifiIfaceSection(station), "key"); currentKey != config.WpaKey {
+			changes[station] = config.WpaKey
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+// applyKeyChanges sets the given WPA keys on the running networks through hostapd's control interface, leaving the
+// other networks and the beacons of the rekeyed ones uninterrupted, and persists them in the wireless configuration.
+// Returns false if the keys couldn't be changed in place, in which case the full configuration must be applied with a
+// reload instead.
+func (radio *Radio) applyKeyChanges(
+	changes map[station]string, stationConfigurations map[string]*StationConfiguration,
+) bool {
+	for station, wpaKey := range changes {
+		uciTree.SetType("wireless", wifiIfaceSection(station), "key", uci.TypeOption, wpaKey)
+		if radio.driverQuirks().duplicateSaePassword {
+			uciTree.SetType("wireless", wifiIfaceSection(station), "sae_password", uci.TypeOption, wpaKey)
+		}
+	}
+	if err := radio.commitUci("wireless"); err != nil {
+		log.Printf("Error committing WPA key changes; falling back to a full reload: %v", err)
+		return false
+	}
+	radio.recordConfigurationStep(configurationStepUciCommitted, 1)
+
+	var rekeyedStations []string
+	for station := red1; station <= blue3; station++ {
+		if _, ok := changes[station]; !ok {
+			continue
+		}
+		if err := radio.rekeyStation(station); err != nil {
+			log.Printf("Error changing WPA key of station %s in place; falling back to a full reload: %v", station, err)
+			return false
+		}
+		rekeyedStations = append(rekeyedStations, station.String())
+	}
+
+	radio.recordConfigurationStep(configurationStepVerifying, 1)
+	if err := uciTree.LoadConfig("wireless", true); err != nil {
+		log.Printf("Error reloading wireless configuration for verification; falling back to a full reload: %v", err)
+		return false
+	}
+	if err := radio.updateStationStatuses(); err != nil {
+		log.Printf("Error updating station statuses; falling back to a full reload: %v", err)
+		return false
+	}
+	if err := radio.verifyStationConfigurations(stationConfigurations); err != nil {
+		log.Printf("WPA key changes failed verification; falling back to a full reload: %v", err)
+		return false
+	}
+	log.Printf("Changed WPA key of %s without reloading.", strings.Join(rekeyedStations, ", "))
+	return true
+}
+
+// rekeyStation loads the WPA key committed for the given station into its running network and derives the new
+// pairwise keys from it, disconnecting any clients still using the old key.
+func (radio *Radio) rekeyStation(station station) error {
+	wifiInterface := radio.stationInterfaces[station]
+	section := wifiIfaceSection(station)
+	options := []string{"wpa_passphrase"}
+	if radio.driverQuirks().duplicateSaePassword {
+		options = append(options, "sae_password")
+	}
+	for _, option := range options {
+		output, err := shell.runCommand("sh", "-c", hostapdSetKeyScript, wifiInterface, option, section)
+		if err == nil && strings.TrimSpace(output) != "OK" {
+			err = fmt.Errorf("unexpected response %q", strings.TrimSpace(output))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to set %s on %s: %v", option, wifiInterface, err)
+		}
+	}
+	output, err := shell.runCommand("hostapd_cli", "-i", wifiInterface, "reload_wpa_psk")
+	if err == nil && strings.TrimSpace(output) != "OK" {
+		err = fmt.Errorf("unexpected response %q", strings.TrimSpace(output))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reload WPA PSK on %s: %v", wifiInterface, err)
+	}
+	return nil
+}
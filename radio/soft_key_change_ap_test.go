@@ -0,0 +1,92 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRadio_configureKeyOnlyChange(t *testing.T) {
+	radio, fakeTree, fakeShell := newVerificationTestRadio(t)
+	request := ConfigurationRequest{
+		Channel:               149,
+		StationConfigurations: map[string]*StationConfiguration{"red1": {Ssid: "1111", WpaKey: "11111111"}},
+	}
+	assert.Nil(t, radio.handleConfigurationRequest(request))
+	setKeyCommand := "sh -c " + hostapdSetKeyScript + " ath1 wpa_passphrase @wifi-iface[1]"
+	setSaePasswordCommand := "sh -c " + hostapdSetKeyScript + " ath1 sae_password @wifi-iface[1]"
+	assert.NotContains(t, fakeShell.commandsRun, setKeyCommand)
+
+	// Changing only the key of an assigned station rekeys its running network without a reload.
+	fakeShell.commandOutput[setKeyCommand] = "OK\n"
+	fakeShell.commandOutput[setSaePasswordCommand] = "OK\n"
+	fakeShell.commandOutput["hostapd_cli -i ath1 reload_wpa_psk"] = "OK\n"
+	delete(fakeShell.commandsRun, "wifi reload wifi1")
+	request.StationConfigurations["red1"].WpaKey = "12121212"
+	assert.Nil(t, radio.handleConfigurationRequest(request))
+	assert.NotContains(t, fakeShell.commandsRun, "wifi reload wifi1")
+	assert.Contains(t, fakeShell.commandsRun, setKeyCommand)
+	assert.Contains(t, fakeShell.commandsRun, setSaePasswordCommand)
+	assert.Contains(t, fakeShell.commandsRun, "hostapd_cli -i ath1 reload_wpa_psk")
+	assert.Equal(t, "12121212", fakeTree.valuesForGet["wireless.@wifi-iface[1].key"])
+	assert.Equal(t, "12121212", fakeTree.valuesForGet["wireless.@wifi-iface[1].sae_password"])
+	status := radio.StationStatuses["red1"]
+	assert.Equal(t, hashWpaKey("12121212", status.WpaKeySalt), status.HashedWpaKey)
+	assert.Equal(t, statusActive, radio.Status)
+
+	// Falls back to a full reload if hostapd doesn't support changing the key in place.
+	fakeShell.commandOutput[setKeyCommand] = "UNKNOWN COMMAND\n"
+	request.StationConfigurations["red1"].WpaKey = "13131313"
+	assert.Nil(t, radio.handleConfigurationRequest(request))
+	assert.Contains(t, fakeShell.commandsRun, "wifi reload wifi1")
+	assert.Equal(t, "13131313", fakeTree.valuesForGet["wireless.@wifi-iface[1].key"])
+	assert.Equal(t, statusActive, radio.Status)
+}
+
+func TestRadio_keyOnlyChanges(t *testing.T) {
+	radio, fakeTree, _ := newVerificationTestRadio(t)
+	request := ConfigurationRequest{
+		Channel:               149,
+		RedVlans:              "10_20_30",
+		BlueVlans:             "40_50_60",
+		StationConfigurations: map[string]*StationConfiguration{"red1": {Ssid: "1111", WpaKey: "11111111"}},
+	}
+	assert.Nil(t, radio.handleConfigurationRequest(request))
+	stationConfigurations := func() map[string]*StationConfiguration {
+		return withOmittedStationsUnassigned(request.StationConfigurations)
+	}
+	assert.Nil(t, radio.keyOnlyChanges(request, stationConfigurations()))
+
+	// Fields equal to the current configuration don't count as changes.
+	request.StationConfigurations["red1"].WpaKey = "12121212"
+	assert.Equal(t, map[station]string{red1: "12121212"}, radio.keyOnlyChanges(request, stationConfigurations()))
+
+	// Any other change requires a full reload.
+	request.Channel = 153
+	assert.Nil(t, radio.keyOnlyChanges(request, stationConfigurations()))
+	request.Channel = 149
+	request.RedVlans = "70_80_90"
+	assert.Nil(t, radio.keyOnlyChanges(request, stationConfigurations()))
+	request.RedVlans = "10_20_30"
+	request.BeaconIntervalTu = 200
+	assert.Nil(t, radio.keyOnlyChanges(request, stationConfigurations()))
+	request.BeaconIntervalTu = 0
+	request.StationConfigurations["red1"].ManagementFrameProtection = "REQUIRED"
+	assert.Nil(t, radio.keyOnlyChanges(request, stationConfigurations()))
+	request.StationConfigurations["red1"].ManagementFrameProtection = ""
+	request.StationConfigurations["red2"] = &StationConfiguration{Ssid: "2222", WpaKey: "22222222"}
+	assert.Nil(t, radio.keyOnlyChanges(request, stationConfigurations()))
+	delete(request.StationConfigurations, "red2")
+	request.StationConfigurations["red1"].Ssid = "1112"
+	assert.Nil(t, radio.keyOnlyChanges(request, stationConfigurations()))
+	request.StationConfigurations["red1"].Ssid = "1111"
+	fakeTree.valuesForGet["wireless.@wifi-iface[1].macaddr"] = "02:00:00:00:00:01"
+	assert.Nil(t, radio.keyOnlyChanges(request, stationConfigurations()))
+	delete(fakeTree.valuesForGet, "wireless.@wifi-iface[1].macaddr")
+
+	// The Linksys wireless stack is always reloaded.
+	radio.Type = TypeLinksys
+	assert.Nil(t, radio.keyOnlyChanges(request, stationConfigurations()))
+}
@@ -0,0 +1,230 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// How long to wait for the standby to accept a mirrored configuration before giving up until the next poll.
+const standbyMirrorTimeout = 10 * time.Second
+
+// HTTP client used to mirror configurations to the standby.
+var standbyMirrorClient = &http.Client{Timeout: standbyMirrorTimeout}
+
+// StandbyStatus represents the state of the pairing between the field access point and a standby that can take over
+// from it.
+type StandbyStatus struct {
+	// Part this access point plays in the pair: "PRIMARY", "STANDBY", or blank if it isn't paired.
+	Role standbyRole `json:"role"`
+
+	// Whether the standby has been told to take over from the primary.
+	HasTakenOver bool `json:"hasTakenOver"`
+
+	// Whether the team networks are currently kept off the air because this is a standby that hasn't taken over.
+	IsHoldingNetworks bool `json:"isHoldingNetworks"`
+
+	// Time at which the standby last accepted a configuration mirrored from the primary. Null if it hasn't yet.
+	LastMirroredAt *Timestamp `json:"lastMirroredAt"`
+
+	// Whether the standby has accepted the configuration currently in effect on the primary. Only set on the primary.
+	IsInSync bool `json:"isInSync"`
+
+	// Description of why the configuration couldn't be mirrored to the standby the last time it was attempted, or an
+	// empty string if it could. Only set on the primary.
+	MirrorError string `json:"mirrorError"`
+}
+
+// GetStandby returns the current state of the pairing with the standby or primary.
+func (radio *Radio) GetStandby() StandbyStatus {
+	radio.standbyMutex.Lock()
+	defer radio.standbyMutex.Unlock()
+	return radio.Standby
+}
+
+// Failover has the standby take over from the primary with the configuration last mirrored to it, bringing its team
+// networks on the air on the next monitoring poll.
+func (radio *Radio) Failover() error {
+	radio.standbyMutex.Lock()
+	defer radio.standbyMutex.Unlock()
+	if err := radio.checkStandbyAvailable(); err != nil {
+		return err
+	}
+	radio.Standby.HasTakenOver = true
+	radio.markStatusChanged()
+	log.Println("Standby will take over from the primary on the next monitoring poll.")
+	return nil
+}
+
+// ReceiveMirroredConfiguration records that the primary has mirrored its configuration to this standby. Returns an
+// error if the configuration shouldn't be applied because this isn't a standby or it has already taken over.
+func (radio *Radio) ReceiveMirroredConfiguration() error {
+	radio.standbyMutex.Lock()
+	defer radio.standbyMutex.Unlock()
+	if err := radio.checkStandbyAvailable(); err != nil {
+		return err
+	}
+	receivedAt := newTimestamp()
+	radio.Standby.LastMirroredAt = &receivedAt
+	radio.markStatusChanged()
+	return nil
+}
+
+// checkStandbyAvailable returns an error unless this is a standby that has yet to take over. The mutex must be held.
+func (radio *Radio) checkStandbyAvailable() error {
+	if radio.GetSettings().Standby.Role != standbyRoleStandby {
+		return errors.New("radio is not configured as a standby")
+	}
+	if radio.Standby.HasTakenOver {
+		return errors.New("standby has already taken over from the primary")
+	}
+	return nil
+}
+
+// isHoldingStandbyNetworks returns true if the team networks are being kept off the air because this is a standby that
+// hasn't taken over.
+func (radio *Radio) isHoldingStandbyNetworks() bool {
+	radio.standbyMutex.Lock()
+	defer radio.standbyMutex.Unlock()
+	return radio.Standby.IsHoldingNetworks
+}
+
+// checkStandby brings the team networks in line with the standby role and, on the primary, mirrors the configuration
+// to the standby again if it isn't known to have accepted it.
+func (radio *Radio) checkStandby() {
+	radio.updateStandbyNetworks(false)
+
+	radio.standbyMutex.Lock()
+	shouldRetry := radio.Standby.Role == standbyRolePrimary && !radio.Standby.IsInSync && !radio.isMirroringToStandby
+	radio.standbyMutex.Unlock()
+	if shouldRetry {
+		radio.mirrorToStandby()
+	}
+}
+
+// updateStandbyNetworks takes the team networks off the air if this is a standby that hasn't taken over, and brings
+// them back once it takes over or stops being a standby. If force is set, the networks are taken down even if they
+// should already be down, in case something other than the API has brought them back up.
+func (radio *Radio) updateStandbyNetworks(force bool) {
+	radio.standbyMutex.Lock()
+	status := &radio.Standby
+	status.Role = radio.GetSettings().Standby.Role
+	if status.Role != standbyRoleStandby {
+		status.HasTakenOver = false
+	}
+	wasHolding := status.IsHoldingNetworks
+	status.IsHoldingNetworks = status.Role == standbyRoleStandby && !status.HasTakenOver
+	isHolding := status.IsHoldingNetworks
+	hasTakenOver := status.HasTakenOver
+	radio.standbyMutex.Unlock()
+
+	if isHolding && (!wasHolding || force) {
+		radio.setTeamNetworksEnabled(false, "while on standby")
+	} else if !isHolding && wasHolding {
+		if !radio.isQuietHoursActive() && !radio.isEmergencyStopActive() {
+			radio.setTeamNetworksEnabled(true, "after leaving standby")
+		}
+		if hasTakenOver {
+			radio.raiseAlert("STANDBY_TOOK_OVER", "Standby took over from the primary access point.")
+		}
+	}
+}
+
+// mirrorToStandby sends the configuration now in effect to the standby in the background if this is the primary. If
+// several configurations are applied while an earlier one is still being delivered, only the latest is sent.
+func (radio *Radio) mirrorToStandby() {
+	settings := radio.GetSettings().Standby
+	if settings.Role != standbyRolePrimary {
+		return
+	}
+	configuration := radio.EffectiveConfiguration()
+	// The primary has already vetted any channel change, so the standby should follow it regardless.
+	configuration.OverrideChannelGuard = true
+
+	radio.standbyMutex.Lock()
+	defer radio.standbyMutex.Unlock()
+	radio.pendingStandbyConfiguration = &configuration
+	radio.Standby.IsInSync = false
+	if !radio.isMirroringToStandby {
+		radio.isMirroringToStandby = true
+		go radio.deliverToStandby(settings)
+	}
+}
+
+// deliverToStandby posts each pending configuration to the standby until there are none left, recording the outcome.
+// An alert is raised when mirroring starts failing; it is retried on the next monitoring poll.
+func (radio *Radio) deliverToStandby(settings StandbySettings) {
+	for {
+		radio.standbyMutex.Lock()
+		configuration := radio.pendingStandbyConfiguration
+		radio.pendingStandbyConfiguration = nil
+		if configuration == nil {
+			radio.isMirroringToStandby = false
+			radio.standbyMutex.Unlock()
+			return
+		}
+		radio.standbyMutex.Unlock()
+
+		err := postConfigurationToStandby(settings, *configuration)
+
+		radio.standbyMutex.Lock()
+		status := &radio.Standby
+		wasFailing := status.MirrorError != ""
+		if err != nil {
+			status.MirrorError = err.Error()
+		} else {
+			mirroredAt := newTimestamp()
+			status.LastMirroredAt = &mirroredAt
+			status.MirrorError = ""
+			status.IsInSync = radio.pendingStandbyConfiguration == nil
+		}
+		radio.standbyMutex.Unlock()
+		radio.markStatusChanged()
+
+		if err != nil && !wasFailing {
+			radio.raiseAlert(
+				"STANDBY_OUT_OF_SYNC",
+				"Unable to mirror configuration to standby at %s: %v.",
+				settings.StandbyUrl,
+				err,
+			)
+		}
+	}
+}
+
+// postConfigurationToStandby sends the given configuration to the standby's API.
+func postConfigurationToStandby(settings StandbySettings, configuration ConfigurationRequest) error {
+	body, err := json.Marshal(configuration)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest(
+		"POST", strings.TrimSuffix(settings.StandbyUrl, "/")+"/standby/configuration", bytes.NewReader(body),
+	)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if settings.StandbyToken != "" {
+		request.Header.Set("Authorization", "Bearer "+settings.StandbyToken)
+	}
+	response, err := standbyMirrorClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusAccepted {
+		responseBody, _ := io.ReadAll(response.Body)
+		return fmt.Errorf("unexpected response %d: %s", response.StatusCode, strings.TrimSpace(string(responseBody)))
+	}
+	return nil
+}
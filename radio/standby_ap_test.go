@@ -0,0 +1,191 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRadio_updateStandbyNetworks(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+	fakeShell.reset()
+	fakeTree.valuesForGet["wireless.@wifi-iface[6].disabled"] = "1"
+	expectHostapdCommands := func(action string) {
+		for _, wifiInterface := range []string{"wlan0", "wlan0-1", "wlan0-2", "wlan0-3", "wlan0-4"} {
+			fakeShell.commandOutput["hostapd_cli -i "+wifiInterface+" "+action] = "OK"
+		}
+	}
+
+	// Nothing happens unless the radio is a standby.
+	radio.updateStandbyNetworks(true)
+	assert.Empty(t, fakeShell.commandsRun)
+	assert.Equal(t, StandbyStatus{}, radio.GetStandby())
+	assert.EqualError(t, radio.Failover(), "radio is not configured as a standby")
+
+	// A standby takes each network that exists off the air, but only once unless forced.
+	settings := radio.GetSettings()
+	settings.Standby.Role = standbyRoleStandby
+	radio.SetSettings(settings)
+	expectHostapdCommands("disable")
+	radio.updateStandbyNetworks(false)
+	assert.Equal(t, 5, len(fakeShell.commandsRun))
+	assert.True(t, radio.GetStandby().IsHoldingNetworks)
+	assert.Equal(t, standbyRoleStandby, radio.GetStandby().Role)
+	fakeShell.reset()
+	radio.updateStandbyNetworks(false)
+	assert.Empty(t, fakeShell.commandsRun)
+	expectHostapdCommands("disable")
+	radio.updateStandbyNetworks(true)
+	assert.Equal(t, 5, len(fakeShell.commandsRun))
+
+	// Ending quiet hours leaves the networks of a standby down.
+	fakeShell.reset()
+	radio.exitQuietHours()
+	assert.Empty(t, fakeShell.commandsRun)
+
+	// Failing over brings the networks back up on the next poll.
+	assert.Nil(t, radio.Failover())
+	assert.True(t, radio.GetStandby().HasTakenOver)
+	assert.True(t, radio.GetStandby().IsHoldingNetworks)
+	assert.EqualError(t, radio.Failover(), "standby has already taken over from the primary")
+	assert.EqualError(t, radio.ReceiveMirroredConfiguration(), "standby has already taken over from the primary")
+	expectHostapdCommands("enable")
+	radio.checkStandby()
+	assert.Equal(t, 5, len(fakeShell.commandsRun))
+	assert.False(t, radio.GetStandby().IsHoldingNetworks)
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "STANDBY_TOOK_OVER", alerts[0].Type)
+	}
+	fakeShell.reset()
+	radio.updateStandbyNetworks(true)
+	assert.Empty(t, fakeShell.commandsRun)
+
+	// No longer being a standby resets the failover, and doesn't bring the networks up during quiet hours.
+	settings = radio.GetSettings()
+	settings.Standby.Role = standbyRoleNone
+	radio.SetSettings(settings)
+	radio.updateStandbyNetworks(false)
+	assert.False(t, radio.GetStandby().HasTakenOver)
+	settings = radio.GetSettings()
+	settings.Standby.Role = standbyRoleStandby
+	radio.SetSettings(settings)
+	expectHostapdCommands("disable")
+	radio.updateStandbyNetworks(false)
+	assert.True(t, radio.GetStandby().IsHoldingNetworks)
+	fakeShell.reset()
+	radio.QuietHours.IsActive = true
+	settings = radio.GetSettings()
+	settings.Standby.Role = standbyRolePrimary
+	radio.SetSettings(settings)
+	radio.updateStandbyNetworks(false)
+	assert.Empty(t, fakeShell.commandsRun)
+	assert.False(t, radio.GetStandby().IsHoldingNetworks)
+}
+
+func TestRadio_ReceiveMirroredConfiguration(t *testing.T) {
+	radio := &Radio{settings: defaultSettings()}
+	assert.EqualError(t, radio.ReceiveMirroredConfiguration(), "radio is not configured as a standby")
+	assert.Nil(t, radio.GetStandby().LastMirroredAt)
+
+	settings := radio.GetSettings()
+	settings.Standby.Role = standbyRoleStandby
+	radio.SetSettings(settings)
+	assert.Nil(t, radio.ReceiveMirroredConfiguration())
+	assert.NotNil(t, radio.GetStandby().LastMirroredAt)
+}
+
+func TestRadio_mirrorToStandby(t *testing.T) {
+	uciTree = newFakeUciTree()
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+	radio.Channel = 149
+
+	var mutex sync.Mutex
+	var requests []ConfigurationRequest
+	responseCode := http.StatusConflict
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/standby/configuration", r.URL.Path)
+		assert.Equal(t, "Bearer standby-token", r.Header.Get("Authorization"))
+		var request ConfigurationRequest
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&request))
+		mutex.Lock()
+		defer mutex.Unlock()
+		requests = append(requests, request)
+		w.WriteHeader(responseCode)
+		_, _ = w.Write([]byte("standby has already taken over from the primary\n"))
+	}))
+	defer server.Close()
+	isIdle := func() bool {
+		radio.standbyMutex.Lock()
+		defer radio.standbyMutex.Unlock()
+		return !radio.isMirroringToStandby
+	}
+
+	// Nothing is mirrored unless the radio is the primary.
+	radio.mirrorToStandby()
+	assert.False(t, radio.isMirroringToStandby)
+
+	// A failure raises an alert, and is retried on each poll until it succeeds.
+	settings := radio.GetSettings()
+	settings.Standby = StandbySettings{
+		Role: standbyRolePrimary, StandbyUrl: server.URL + "/", StandbyToken: "standby-token",
+	}
+	radio.SetSettings(settings)
+	radio.mirrorToStandby()
+	assert.Eventually(t, isIdle, time.Second, time.Millisecond)
+	status := radio.GetStandby()
+	assert.False(t, status.IsInSync)
+	assert.Nil(t, status.LastMirroredAt)
+	assert.Equal(t, "unexpected response 409: standby has already taken over from the primary", status.MirrorError)
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "STANDBY_OUT_OF_SYNC", alerts[0].Type)
+	}
+	radio.checkStandby()
+	assert.Eventually(t, isIdle, time.Second, time.Millisecond)
+	assert.Equal(t, 1, len(radio.GetAlerts()))
+
+	mutex.Lock()
+	responseCode = http.StatusAccepted
+	mutex.Unlock()
+	radio.Channel = 157
+	radio.checkStandby()
+	assert.Eventually(t, isIdle, time.Second, time.Millisecond)
+	status = radio.GetStandby()
+	assert.True(t, status.IsInSync)
+	assert.NotNil(t, status.LastMirroredAt)
+	assert.Equal(t, "", status.MirrorError)
+	mutex.Lock()
+	if assert.Equal(t, 3, len(requests)) {
+		assert.Equal(t, 157, requests[2].Channel)
+		assert.True(t, requests[2].OverrideChannelGuard)
+		assert.Equal(t, Vlans102030, requests[2].RedVlans)
+		assert.Contains(t, requests[2].StationConfigurations, "blue3")
+	}
+	mutex.Unlock()
+
+	// Once in sync, the configuration is only mirrored again when it changes.
+	radio.checkStandby()
+	assert.True(t, isIdle())
+	radio.mirrorToStandby()
+	assert.Eventually(t, isIdle, time.Second, time.Millisecond)
+	mutex.Lock()
+	assert.Equal(t, 4, len(requests))
+	mutex.Unlock()
+}
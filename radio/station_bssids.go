@@ -0,0 +1,62 @@
+package radio
+
+import (
+	"fmt"
+	"net"
+)
+
+// bssidMode represents how the BSSID of each team network is chosen.
+type bssidMode string
+
+const (
+	// The driver assigns the BSSIDs, usually deriving them from the radio's own MAC address.
+	bssidModeDriver bssidMode = "DRIVER"
+
+	// Each station keeps the same BSSID across configurations: the one given for it in the settings, or otherwise a
+	// locally administered address generated the first time it is configured.
+	bssidModePinned bssidMode = "PINNED"
+
+	// Each station is given a new random locally administered BSSID whenever a different team is assigned to it, and
+	// keeps it for as long as the team stays.
+	bssidModeRandomized bssidMode = "RANDOMIZED"
+)
+
+// StationBssidSettings holds how the BSSID of each team network is chosen.
+type StationBssidSettings struct {
+	// Mode for choosing the BSSIDs: "DRIVER", "PINNED", or "RANDOMIZED".
+	Mode bssidMode `json:"mode"`
+
+	// BSSID to pin each station to in the PINNED mode, keyed by station name (e.g. "red1"). Stations that are omitted
+	// are pinned to a generated address.
+	Addresses map[string]string `json:"addresses"`
+}
+
+// validate checks that the mode is valid and that every pinned address is a unicast MAC address for a team station.
+func (settings StationBssidSettings) validate() error {
+	switch settings.Mode {
+	case bssidModeDriver, bssidModePinned, bssidModeRandomized:
+	default:
+		return fmt.Errorf("invalid stationBssids.mode: %s", settings.Mode)
+	}
+	for stationName, address := range settings.Addresses {
+		isStation := false
+		for station := red1; station <= blue3; station++ {
+			isStation = isStation || stationName == station.String()
+		}
+		if !isStation {
+			return fmt.Errorf("invalid stationBssids.addresses station: %s", stationName)
+		}
+		if !isUnicastMacAddress(address) {
+			return fmt.Errorf(
+				"invalid stationBssids.addresses entry for %s: %q (expecting a unicast MAC address)", stationName, address,
+			)
+		}
+	}
+	return nil
+}
+
+// isUnicastMacAddress returns true if the given string is a 48-bit MAC address that isn't a multicast address.
+func isUnicastMacAddress(address string) bool {
+	mac, err := net.ParseMAC(address)
+	return err == nil && len(mac) == 6 && mac[0]&0x01 == 0
+}
@@ -0,0 +1,77 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"crypto/rand"
+	"github.com/digineo/go-uci"
+	"log"
+	"net"
+	"strings"
+)
+
+// configureStationBssid sets the BSSID of the given station's network according to the configured mode, given the
+// SSID that the network is about to be configured with. Must be called before the new SSID is set, since the SSID
+// currently set is used to tell whether a randomized BSSID can be kept. The BSSID is kept in the wireless
+// configuration, so that it survives reloads and reboots.
+func (radio *Radio) configureStationBssid(station station, ssid string) {
+	settings := radio.GetSettings().StationBssids
+	wifiInterface := wifiIfaceSection(station)
+	currentBssid, _ := uciTree.GetLast("wireless", wifiInterface, "macaddr")
+
+	var bssid string
+	switch settings.Mode {
+	case bssidModePinned:
+		bssid = settings.Addresses[station.String()]
+		if bssid == "" {
+			bssid = currentBssid
+		}
+	case bssidModeRandomized:
+		if currentSsid, _ := uciTree.GetLast("wireless", wifiInterface, "ssid"); currentSsid == ssid {
+			bssid = currentBssid
+		}
+	default:
+		if currentBssid != "" {
+			uciTree.Del("wireless", wifiInterface, "macaddr")
+		}
+		return
+	}
+	if bssid == "" {
+		var err error
+		if bssid, err = newLocallyAdministeredMacAddress(); err != nil {
+			log.Printf("Error generating BSSID for station %s; leaving it unchanged: %v", station, err)
+			return
+		}
+	}
+	if !strings.EqualFold(bssid, currentBssid) {
+		uciTree.SetType("wireless", wifiInterface, "macaddr", uci.TypeOption, strings.ToLower(bssid))
+	}
+}
+
+// isStationBssidCurrent returns true if configuring the given station with the SSID it already has would leave its
+// BSSID as it is.
+func (radio *Radio) isStationBssidCurrent(station station) bool {
+	settings := radio.GetSettings().StationBssids
+	currentBssid, _ := uciTree.GetLast("wireless", wifiIfaceSection(station), "macaddr")
+	switch settings.Mode {
+	case bssidModePinned:
+		pinnedBssid := settings.Addresses[station.String()]
+		return pinnedBssid == "" || strings.EqualFold(pinnedBssid, currentBssid)
+	case bssidModeRandomized:
+		return currentBssid != ""
+	default:
+		return currentBssid == ""
+	}
+}
+
+// newLocallyAdministeredMacAddress returns a random unicast MAC address with the locally administered bit set, so that
+// it can't clash with any manufacturer-assigned address.
+func newLocallyAdministeredMacAddress() (string, error) {
+	mac := make(net.HardwareAddr, 6)
+	if _, err := rand.Read(mac); err != nil {
+		return "", err
+	}
+	mac[0] = mac[0]&^0x01 | 0x02
+	return mac.String(), nil
+}
@@ -0,0 +1,65 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+)
+
+func TestRadio_configureStationBssid(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	radio := NewRadio()
+	setMode := func(mode bssidMode, addresses map[string]string) {
+		settings := radio.GetSettings()
+		settings.StationBssids = StationBssidSettings{Mode: mode, Addresses: addresses}
+		radio.SetSettings(settings)
+		fakeTree.valuesFromSet = make(map[string]string)
+		fakeTree.setCount = 0
+	}
+
+	// The driver's BSSIDs are left alone, and any previously set one is removed.
+	radio.configureStationBssid(red1, "254")
+	assert.Equal(t, 0, fakeTree.setCount)
+	fakeTree.valuesForGet["wireless.@wifi-iface[1].macaddr"] = "02:11:22:33:44:55"
+	radio.configureStationBssid(red1, "254")
+	assert.Equal(t, "***DELETED***", fakeTree.valuesFromSet["wireless.@wifi-iface[1].macaddr"])
+
+	// A pinned station uses the given address, or keeps the one it has.
+	setMode(bssidModePinned, map[string]string{"red2": "02:AA:BB:CC:DD:EE"})
+	radio.configureStationBssid(red1, "254")
+	assert.Equal(t, 0, fakeTree.setCount)
+	radio.configureStationBssid(red2, "1114")
+	assert.Equal(t, "02:aa:bb:cc:dd:ee", fakeTree.valuesFromSet["wireless.@wifi-iface[2].macaddr"])
+
+	// A pinned station without an address is given a locally administered one.
+	radio.configureStationBssid(red3, "1678")
+	bssid := fakeTree.valuesFromSet["wireless.@wifi-iface[3].macaddr"]
+	mac, err := net.ParseMAC(bssid)
+	if assert.Nil(t, err) {
+		assert.Equal(t, byte(0x02), mac[0]&0x03)
+	}
+
+	// A randomized station keeps its BSSID while the team stays and gets a new one when the team changes.
+	setMode(bssidModeRandomized, nil)
+	fakeTree.valuesForGet["wireless.@wifi-iface[1].ssid"] = "254"
+	radio.configureStationBssid(red1, "254")
+	assert.Equal(t, 0, fakeTree.setCount)
+	radio.configureStationBssid(red1, "1114")
+	bssid = fakeTree.valuesFromSet["wireless.@wifi-iface[1].macaddr"]
+	assert.NotEqual(t, "", bssid)
+	assert.NotEqual(t, "02:11:22:33:44:55", bssid)
+	assert.True(t, isUnicastMacAddress(bssid))
+}
+
+func TestNewLocallyAdministeredMacAddress(t *testing.T) {
+	first, err := newLocallyAdministeredMacAddress()
+	assert.Nil(t, err)
+	second, _ := newLocallyAdministeredMacAddress()
+	assert.NotEqual(t, first, second)
+	mac, _ := net.ParseMAC(first)
+	assert.Equal(t, byte(0x02), mac[0]&0x03)
+}
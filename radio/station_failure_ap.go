@@ -0,0 +1,34 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+// Number of consecutive monitoring polls in which a station's network must fail to be queried before the station
+// counts as having failed, rather than having suffered a momentary glitch.
+const stationFailureConsecutivePolls = 5
+
+// checkStationFailures raises an alert for each station with a team assigned whose network has failed to be queried at
+// the last several monitoring polls, which indicates that its interface has gone down.
+func (radio *Radio) checkStationFailures() {
+	if radio.stationFailurePolls == nil {
+		radio.stationFailurePolls = make(map[station]int)
+	}
+	for station := red1; station <= blue3; station++ {
+		status := radio.StationStatuses[station.String()]
+		if status == nil || status.SignalNoiseRatio != monitoringErrorCode {
+			delete(radio.stationFailurePolls, station)
+			continue
+		}
+		radio.stationFailurePolls[station]++
+		if radio.stationFailurePolls[station] == stationFailureConsecutivePolls {
+			radio.raiseStationAlert(
+				station.String(),
+				"STATION_FAILURE",
+				"Station %s (SSID \"%s\") has failed to be monitored for %d consecutive polls.",
+				station,
+				status.Ssid,
+				stationFailureConsecutivePolls,
+			)
+		}
+	}
+}
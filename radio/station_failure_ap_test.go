@@ -0,0 +1,43 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRadio_checkStationFailures(t *testing.T) {
+	radio := NewRadio()
+	radio.StationStatuses["red2"] = &NetworkStatus{Ssid: "2222", SignalNoiseRatio: monitoringErrorCode}
+	radio.StationStatuses["blue1"] = &NetworkStatus{Ssid: "4444", SignalNoiseRatio: monitoringErrorCode}
+
+	for i := 0; i < stationFailureConsecutivePolls-1; i++ {
+		radio.checkStationFailures()
+	}
+	assert.Empty(t, radio.GetAlerts())
+
+	// A station that recovers before failing persistently starts counting again.
+	radio.StationStatuses["blue1"].SignalNoiseRatio = 35
+	radio.checkStationFailures()
+	radio.StationStatuses["blue1"].SignalNoiseRatio = monitoringErrorCode
+	radio.checkStationFailures()
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "STATION_FAILURE", alerts[0].Type)
+		assert.Equal(t, "red2", alerts[0].Station)
+		assert.Equal(
+			t, "Station red2 (SSID \"2222\") has failed to be monitored for 5 consecutive polls.", alerts[0].Message,
+		)
+	}
+
+	// The alert isn't repeated while the station remains failed.
+	for i := 0; i < stationFailureConsecutivePolls; i++ {
+		radio.checkStationFailures()
+	}
+	alerts = radio.GetAlerts()
+	if assert.Equal(t, 2, len(alerts)) {
+		assert.Equal(t, "blue1", alerts[1].Station)
+	}
+}
@@ -0,0 +1,61 @@
+package radio
+
+import "fmt"
+
+const (
+	// Range of group rekey intervals accepted, in seconds, other than zero to disable rekeying.
+	minGroupRekeyIntervalSec = 60
+	maxGroupRekeyIntervalSec = 86400
+
+	// Range of inactivity timeouts accepted, in seconds.
+	minInactivityTimeoutSec = 10
+	maxInactivityTimeoutSec = 86400
+
+	// Largest listen interval that a client can be allowed to request, in beacons.
+	maxListenIntervalBeacons = 65535
+)
+
+// StationTimers holds the WPA and power save timers of the team networks.
+type StationTimers struct {
+	// Interval at which the WPA group key is renewed, in seconds, or zero to never renew it. Each renewal briefly
+	// holds up broadcast and multicast traffic to the clients while they install the new key.
+	GroupRekeyIntervalSec int `json:"groupRekeyIntervalSec"`
+
+	// Time after which a client that hasn't sent anything is probed and disconnected if it doesn't respond, in
+	// seconds.
+	InactivityTimeoutSec int `json:"inactivityTimeoutSec"`
+
+	// Largest number of beacons that a client may sleep between waking to receive traffic buffered for it; a client
+	// asking for more is refused association.
+	MaxListenIntervalBeacons int `json:"maxListenIntervalBeacons"`
+}
+
+// validate checks that the station timers have values that hostapd accepts and that won't interfere with the team
+// networks.
+func (timers StationTimers) validate() error {
+	rekeyInterval := timers.GroupRekeyIntervalSec
+	if rekeyInterval != 0 && (rekeyInterval < minGroupRekeyIntervalSec || rekeyInterval > maxGroupRekeyIntervalSec) {
+		return fmt.Errorf(
+			"invalid stationTimers.groupRekeyIntervalSec: %d (expecting %d-%d, or 0 to disable rekeying)",
+			rekeyInterval,
+			minGroupRekeyIntervalSec,
+			maxGroupRekeyIntervalSec,
+		)
+	}
+	if timers.InactivityTimeoutSec < minInactivityTimeoutSec || timers.InactivityTimeoutSec > maxInactivityTimeoutSec {
+		return fmt.Errorf(
+			"invalid stationTimers.inactivityTimeoutSec: %d (expecting %d-%d)",
+			timers.InactivityTimeoutSec,
+			minInactivityTimeoutSec,
+			maxInactivityTimeoutSec,
+		)
+	}
+	if timers.MaxListenIntervalBeacons < 1 || timers.MaxListenIntervalBeacons > maxListenIntervalBeacons {
+		return fmt.Errorf(
+			"invalid stationTimers.maxListenIntervalBeacons: %d (expecting 1-%d)",
+			timers.MaxListenIntervalBeacons,
+			maxListenIntervalBeacons,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/digineo/go-uci"
+	"strconv"
+)
+
+// Station timers used by hostapd for any that aren't configured.
+var hostapdDefaultStationTimers = StationTimers{
+	GroupRekeyIntervalSec:    86400,
+	InactivityTimeoutSec:     300,
+	MaxListenIntervalBeacons: 65535,
+}
+
+// readStationTimers returns the station timers configured on the team stations, or the values hostapd uses by default
+// for any that aren't configured.
+func readStationTimers() StationTimers {
+	timers := hostapdDefaultStationTimers
+	for _, option := range []struct {
+		name  string
+		value *int
+	}{
+		{"wpa_group_rekey", &timers.GroupRekeyIntervalSec},
+		{"max_inactivity", &timers.InactivityTimeoutSec},
+		{"max_listen_int", &timers.MaxListenIntervalBeacons},
+	} {
+		if value, ok := uciTree.GetLast("wireless", wifiIfaceSection(red1), option.name); ok && value != "" {
+			if parsed, err := strconv.Atoi(value); err == nil {
+				*option.value = parsed
+			}
+		}
+	}
+	return timers
+}
+
+// configureStationTimers sets the station timers given in the settings on every team station. The new values take
+// effect once the stations are next reloaded.
+func (radio *Radio) configureStationTimers() {
+	timers := radio.GetSettings().StationTimers
+	for station := red1; station <= blue3; station++ {
+		wifiInterface := wifiIfaceSection(station)
+		uciTree.SetType(
+			"wireless", wifiInterface, "wpa_group_rekey", uci.TypeOption, strconv.Itoa(timers.GroupRekeyIntervalSec),
+		)
+		uciTree.SetType(
+			"wireless", wifiInterface, "max_inactivity", uci.TypeOption, strconv.Itoa(timers.InactivityTimeoutSec),
+		)
+		uciTree.SetType(
+			"wireless", wifiInterface, "max_listen_int", uci.TypeOption, strconv.Itoa(timers.MaxListenIntervalBeacons),
+		)
+	}
+	radio.StationTimers = timers
+}
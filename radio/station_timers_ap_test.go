@@ -0,0 +1,45 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestReadStationTimers(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+
+	// The values hostapd uses by default are reported for any that aren't configured.
+	assert.Equal(t, hostapdDefaultStationTimers, readStationTimers())
+
+	fakeTree.valuesForGet["wireless.@wifi-iface[1].wpa_group_rekey"] = "0"
+	fakeTree.valuesForGet["wireless.@wifi-iface[1].max_inactivity"] = "120"
+	assert.Equal(
+		t,
+		StationTimers{GroupRekeyIntervalSec: 0, InactivityTimeoutSec: 120, MaxListenIntervalBeacons: 65535},
+		readStationTimers(),
+	)
+}
+
+func TestRadio_configureStationTimers(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	radio := Radio{}
+	settings := defaultSettings()
+	settings.StationTimers = StationTimers{
+		GroupRekeyIntervalSec: 3600, InactivityTimeoutSec: 60, MaxListenIntervalBeacons: 20,
+	}
+	radio.SetSettings(settings)
+
+	radio.configureStationTimers()
+	assert.Equal(t, 18, fakeTree.setCount)
+	for _, section := range []string{"@wifi-iface[1]", "@wifi-iface[6]"} {
+		assert.Equal(t, "3600", fakeTree.valuesFromSet["wireless."+section+".wpa_group_rekey"])
+		assert.Equal(t, "60", fakeTree.valuesFromSet["wireless."+section+".max_inactivity"])
+		assert.Equal(t, "20", fakeTree.valuesFromSet["wireless."+section+".max_listen_int"])
+	}
+	assert.Equal(t, settings.StationTimers, radio.StationTimers)
+}
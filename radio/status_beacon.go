@@ -0,0 +1,43 @@
+package radio
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+const (
+	// Range of intervals between status beacons accepted, in milliseconds.
+	minStatusBeaconIntervalMs = 100
+	maxStatusBeaconIntervalMs = 10000
+)
+
+// StatusBeaconSettings holds where and how often the compact binary status beacon is sent over UDP.
+type StatusBeaconSettings struct {
+	// IP address and port to which the beacon is sent (e.g. "10.0.100.5:1161"), which may be a broadcast address.
+	// Blank disables the beacon. Only used on the access point.
+	Address string `json:"address"`
+
+	// Interval between beacons, in milliseconds.
+	IntervalMs int `json:"intervalMs"`
+}
+
+// validate checks that the beacon address is an IP address and port and that the interval is within range.
+func (settings StatusBeaconSettings) validate() error {
+	if settings.Address != "" {
+		host, port, err := net.SplitHostPort(settings.Address)
+		portNumber, portErr := strconv.Atoi(port)
+		if err != nil || net.ParseIP(host) == nil || portErr != nil || portNumber < 1 || portNumber > 65535 {
+			return fmt.Errorf("invalid statusBeacon.address: %q (expecting an IP address and port)", settings.Address)
+		}
+	}
+	if settings.IntervalMs < minStatusBeaconIntervalMs || settings.IntervalMs > maxStatusBeaconIntervalMs {
+		return fmt.Errorf(
+			"invalid statusBeacon.intervalMs: %d (expecting %d-%d)",
+			settings.IntervalMs,
+			minStatusBeaconIntervalMs,
+			maxStatusBeaconIntervalMs,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"encoding/binary"
+	"log"
+	"math"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	// Version of the status beacon format, incremented whenever the layout changes.
+	statusBeaconVersion = 1
+
+	// Length of the status beacon header and of the entry for each station, in bytes.
+	statusBeaconHeaderLength  = 14
+	statusBeaconStationLength = 15
+
+	// Flag set in a station entry if a robot radio is associated with the station's network.
+	statusBeaconFlagLinked = 0x01
+)
+
+// Codes with which the radio's configuration stage is given in the status beacon.
+var statusBeaconStatusCodes = map[radioStatus]byte{
+	statusBooting:               0,
+	statusConfiguring:           1,
+	statusActive:                2,
+	statusError:                 3,
+	statusMisconfiguredBaseline: 4,
+	statusProvisioning:          5,
+}
+
+// statusBeacon sends the compact binary status of the team stations over UDP. It runs on its own goroutine, apart
+// from both the radio loop and the HTTP server, so that it keeps going if either is held up.
+type statusBeacon struct {
+	// Connection to the address that the beacon is sent to, re-established whenever the address changes.
+	conn    net.Conn
+	address string
+
+	// Sequence number of the most recently sent beacon, so that the receiver can detect lost packets.
+	sequence uint32
+
+	// Whether the most recent beacon couldn't be sent, so that an unreachable receiver is only logged once.
+	isFailing bool
+}
+
+// startStatusBeacon starts sending the status beacon at the configured interval, for as long as the process runs.
+// Changes to the settings take effect on the next beacon.
+func (radio *Radio) startStatusBeacon() {
+	go func() {
+		var beacon statusBeacon
+		for {
+			settings := radio.GetSettings().StatusBeacon
+			time.Sleep(time.Duration(settings.IntervalMs) * time.Millisecond)
+			beacon.send(radio, settings.Address)
+		}
+	}()
+}
+
+// send sends the current status to the given address, or closes the connection if the address is blank.
+func (beacon *statusBeacon) send(radio *Radio, address string) {
+	if address != beacon.address && beacon.conn != nil {
+		_ = beacon.conn.Close()
+		beacon.conn = nil
+	}
+	beacon.address = address
+	if address == "" {
+		return
+	}
+
+	var err error
+	if beacon.conn == nil {
+		beacon.conn, err = net.Dial("udp", address)
+	}
+	if err == nil {
+		beacon.sequence++
+		_, err = beacon.conn.Write(radio.statusBeaconPacket(beacon.sequence, time.Now()))
+	}
+	if err != nil && !beacon.isFailing {
+		log.Printf("Error sending status beacon to %s: %v", address, err)
+	} else if err == nil && beacon.isFailing {
+		log.Printf("Sending status beacon to %s again.", address)
+	}
+	beacon.isFailing = err != nil
+}
+
+// statusBeaconPacket returns the status beacon with the given sequence number, summarizing each station with a team
+// assigned as of the most recent monitoring poll. All values are big-endian and out-of-range values are saturated.
+//
+// The header consists of the ASCII characters "FR", the format version, the radio status code, the sequence number
+// (uint32), the age of the monitoring data in milliseconds (uint16), the channel (uint16), the field number, and the
+// number of station entries that follow. Each station entry consists of the station's position (0-5 for red1 through
+// blue3), the team number (uint16, or zero if the SSID isn't a number), the flags, the quality score, the signal and
+// noise levels in dBm (int8), the receive and transmit rates in tenths of a Mbps (uint16), the bandwidth used in
+// hundredths of a Mbps (uint16), the transmit retry rate in percent, and the number of recent link drops.
+func (radio *Radio) statusBeaconPacket(sequence uint32, now time.Time) []byte {
+	fieldNumber := radio.GetEventVariables().FieldNumber
+	unlock := radio.lockStatus()
+	defer unlock()
+
+	dataAgeMs := math.MaxUint16
+	if !radio.MonitoredAt.Wallclock.IsZero() {
+		dataAgeMs = int(now.Sub(radio.MonitoredAt.Wallclock).Milliseconds())
+	}
+	statusCode, ok := statusBeaconStatusCodes[radio.Status]
+	if !ok {
+		statusCode = math.MaxUint8
+	}
+	packet := make([]byte, 0, statusBeaconHeaderLength+int(blue3+1)*statusBeaconStationLength)
+	packet = append(packet, 'F', 'R', statusBeaconVersion, statusCode)
+	packet = binary.BigEndian.AppendUint32(packet, sequence)
+	packet = binary.BigEndian.AppendUint16(packet, uint16(saturate(dataAgeMs, 0, math.MaxUint16)))
+	packet = binary.BigEndian.AppendUint16(packet, uint16(saturate(radio.Channel, 0, math.MaxUint16)))
+	packet = append(packet, byte(saturate(fieldNumber, 0, math.MaxUint8)), 0)
+
+	stationCount := 0
+	for station := red1; station <= blue3; station++ {
+		status := radio.StationStatuses[station.String()]
+		if status == nil {
+			continue
+		}
+		stationCount++
+		teamNumber, err := strconv.Atoi(status.Ssid)
+		if err != nil {
+			teamNumber = 0
+		}
+		var flags byte
+		if status.IsLinked {
+			flags |= statusBeaconFlagLinked
+		}
+		packet = append(packet, byte(station))
+		packet = binary.BigEndian.AppendUint16(packet, uint16(saturate(teamNumber, 0, math.MaxUint16)))
+		packet = append(
+			packet,
+			flags,
+			byte(saturate(status.QualityScore, 0, 100)),
+			byte(int8(saturate(status.SignalDbm, math.MinInt8, math.MaxInt8))),
+			byte(int8(saturate(status.NoiseDbm, math.MinInt8, math.MaxInt8))),
+		)
+		packet = binary.BigEndian.AppendUint16(packet, scaledUint16(status.RxRateMbps, 10))
+		packet = binary.BigEndian.AppendUint16(packet, scaledUint16(status.TxRateMbps, 10))
+		packet = binary.BigEndian.AppendUint16(packet, scaledUint16(status.BandwidthUsedMbps, 100))
+		packet = append(
+			packet,
+			byte(saturate(int(math.Round(status.TxRetryRatePercent)), 0, 100)),
+			byte(saturate(status.RecentLinkDropCount, 0, math.MaxUint8)),
+		)
+	}
+	packet[statusBeaconHeaderLength-1] = byte(stationCount)
+	return packet
+}
+
+// saturate returns the given value limited to the given range.
+func saturate(value, low, high int) int {
+	if value < low {
+		return low
+	}
+	if value > high {
+		return high
+	}
+	return value
+}
+
+// scaledUint16 returns the given value multiplied by the given scale and rounded, saturated to the range of a uint16.
+func scaledUint16(value, scale float64) uint16 {
+	return uint16(math.Max(0, math.Min(math.MaxUint16, math.Round(value*scale))))
+}
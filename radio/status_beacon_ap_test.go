@@ -0,0 +1,95 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRadio_statusBeaconPacket(t *testing.T) {
+	radio := NewRadio()
+	radio.Status = statusActive
+	radio.Channel = 37
+	settings := radio.GetSettings()
+	settings.EventVariables.FieldNumber = 2
+	radio.SetSettings(settings)
+	now := time.Now()
+	radio.MonitoredAt = Timestamp{Wallclock: now.Add(-1500 * time.Millisecond)}
+	radio.StationStatuses["red2"] = &NetworkStatus{
+		Ssid:                "254",
+		IsLinked:            true,
+		QualityScore:        92,
+		SignalDbm:           -53,
+		NoiseDbm:            -95,
+		RxRateMbps:          864.8,
+		TxRateMbps:          729.6,
+		BandwidthUsedMbps:   4.217,
+		TxRetryRatePercent:  2.5,
+		RecentLinkDropCount: 300,
+	}
+	radio.StationStatuses["blue3"] = &NetworkStatus{Ssid: "no-team-6", SignalDbm: -200}
+
+	packet := radio.statusBeaconPacket(42, now)
+	assert.Equal(t, statusBeaconHeaderLength+2*statusBeaconStationLength, len(packet))
+	assert.Less(t, len(packet), 200)
+	assert.Equal(t, []byte{'F', 'R', 1, 2}, packet[0:4])
+	assert.Equal(t, uint32(42), binary.BigEndian.Uint32(packet[4:8]))
+	assert.Equal(t, uint16(1500), binary.BigEndian.Uint16(packet[8:10]))
+	assert.Equal(t, uint16(37), binary.BigEndian.Uint16(packet[10:12]))
+	assert.Equal(t, []byte{2, 2}, packet[12:14])
+
+	red2 := packet[14:29]
+	assert.Equal(t, byte(1), red2[0])
+	assert.Equal(t, uint16(254), binary.BigEndian.Uint16(red2[1:3]))
+	assert.Equal(t, byte(statusBeaconFlagLinked), red2[3])
+	assert.Equal(t, byte(92), red2[4])
+	assert.Equal(t, int8(-53), int8(red2[5]))
+	assert.Equal(t, int8(-95), int8(red2[6]))
+	assert.Equal(t, uint16(8648), binary.BigEndian.Uint16(red2[7:9]))
+	assert.Equal(t, uint16(7296), binary.BigEndian.Uint16(red2[9:11]))
+	assert.Equal(t, uint16(422), binary.BigEndian.Uint16(red2[11:13]))
+	assert.Equal(t, byte(3), red2[13])
+	assert.Equal(t, byte(255), red2[14])
+
+	blue3 := packet[29:44]
+	assert.Equal(t, byte(5), blue3[0])
+	assert.Equal(t, uint16(0), binary.BigEndian.Uint16(blue3[1:3]))
+	assert.Equal(t, byte(0), blue3[3])
+	assert.Equal(t, int8(-128), int8(blue3[5]))
+
+	// Data from before the first monitoring poll is given the largest age.
+	radio.MonitoredAt = Timestamp{}
+	packet = radio.statusBeaconPacket(43, now)
+	assert.Equal(t, uint16(65535), binary.BigEndian.Uint16(packet[8:10]))
+}
+
+func TestStatusBeacon_send(t *testing.T) {
+	radio := NewRadio()
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer listener.Close()
+
+	var beacon statusBeacon
+	buffer := make([]byte, 1024)
+	for i := 1; i <= 2; i++ {
+		beacon.send(radio, listener.LocalAddr().String())
+		_ = listener.SetReadDeadline(time.Now().Add(time.Second))
+		length, _, err := listener.ReadFrom(buffer)
+		if assert.Nil(t, err) {
+			assert.Equal(t, statusBeaconHeaderLength, length)
+			assert.Equal(t, uint32(i), binary.BigEndian.Uint32(buffer[4:8]))
+		}
+	}
+	assert.False(t, beacon.isFailing)
+
+	// A blank address stops the beacon.
+	beacon.send(radio, "")
+	assert.Nil(t, beacon.conn)
+}
@@ -0,0 +1,193 @@
+package radio
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// statusCompatConversion represents how the value of a status field is converted for a client expecting a legacy
+// format.
+type statusCompatConversion string
+
+const (
+	// Leave the value as is.
+	statusCompatConversionNone statusCompatConversion = ""
+
+	// Leave the field out altogether.
+	statusCompatConversionOmit statusCompatConversion = "OMIT"
+
+	// Render a timestamp as its wall-clock time alone, as an RFC 3339 string.
+	statusCompatConversionWallclock statusCompatConversion = "WALLCLOCK"
+
+	// Render a structured error as its message alone, or a blank string if there is no error.
+	statusCompatConversionMessage statusCompatConversion = "MESSAGE"
+
+	// Render a number or boolean as a string.
+	statusCompatConversionString statusCompatConversion = "STRING"
+
+	// Render a numeric string as a number.
+	statusCompatConversionNumber statusCompatConversion = "NUMBER"
+)
+
+// StatusCompatRule describes how a single field of the status is rendered for a client expecting a legacy format.
+type StatusCompatRule struct {
+	// Dot-separated path of the field in the current status (e.g. "stationStatuses.*.signalNoiseRatio"), in which "*"
+	// matches every entry of a map or array.
+	Path string `json:"path"`
+
+	// Name under which the field appears in the legacy format, or blank to keep its current name.
+	LegacyName string `json:"legacyName"`
+
+	// Conversion applied to the field's value: "OMIT", "WALLCLOCK", "MESSAGE", "STRING", "NUMBER", or blank to leave
+	// it as is.
+	Conversion statusCompatConversion `json:"conversion"`
+}
+
+// Compatibility profiles that are always available, keyed by name. Profiles of the same name in the settings take
+// precedence.
+var builtInStatusCompatProfiles = map[string][]StatusCompatRule{
+	// Releases of the FMS that decode the status change time and the last error into plain strings, from before they
+	// were structured.
+	"v1": {
+		{Path: "statusChangedAt", Conversion: statusCompatConversionWallclock},
+		{Path: "statusTransitions.*.at", Conversion: statusCompatConversionWallclock},
+		{Path: "monitoredAt", Conversion: statusCompatConversionWallclock},
+		{Path: "lastError", Conversion: statusCompatConversionMessage},
+	},
+}
+
+// StatusCompatProfile returns the rules of the status compatibility profile with the given name, from the settings if
+// it is defined there or otherwise from the built-in profiles, and false if there is no such profile.
+func (settings Settings) StatusCompatProfile(name string) ([]StatusCompatRule, bool) {
+	if rules, ok := settings.StatusCompatProfiles[name]; ok {
+		return rules, true
+	}
+	rules, ok := builtInStatusCompatProfiles[name]
+	return rules, ok
+}
+
+// validateStatusCompatProfiles checks that each of the given status compatibility profiles has a name and valid rules.
+func validateStatusCompatProfiles(profiles map[string][]StatusCompatRule) error {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name == "" {
+			return errors.New("status compatibility profile name cannot be blank")
+		}
+		for i, rule := range profiles[name] {
+			for _, segment := range strings.Split(rule.Path, ".") {
+				if segment == "" {
+					return fmt.Errorf("invalid statusCompatProfiles.%s[%d].path: %q", name, i, rule.Path)
+				}
+			}
+			switch rule.Conversion {
+			case statusCompatConversionNone, statusCompatConversionOmit, statusCompatConversionWallclock,
+				statusCompatConversionMessage, statusCompatConversionString, statusCompatConversionNumber:
+			default:
+				return fmt.Errorf(
+					"invalid statusCompatProfiles.%s[%d].conversion: %q (expecting OMIT, WALLCLOCK, MESSAGE, STRING, "+
+						"NUMBER, or blank)",
+					name,
+					i,
+					rule.Conversion,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyStatusCompatRules converts the given status JSON into the legacy format described by the given rules, which
+// are applied in order.
+func ApplyStatusCompatRules(statusJson []byte, rules []StatusCompatRule) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(statusJson))
+	decoder.UseNumber()
+	var status any
+	if err := decoder.Decode(&status); err != nil {
+		return nil, fmt.Errorf("error decoding status for compatibility conversion: %v", err)
+	}
+	for _, rule := range rules {
+		applyStatusCompatRule(status, strings.Split(rule.Path, "."), rule)
+	}
+	return json.MarshalIndent(status, "", "  ")
+}
+
+// applyStatusCompatRule applies the given rule to the field at the given remaining path within the given value.
+// Paths that don't exist in the value are ignored.
+func applyStatusCompatRule(value any, path []string, rule StatusCompatRule) {
+	if path[0] == "*" {
+		switch typedValue := value.(type) {
+		case map[string]any:
+			for _, element := range typedValue {
+				applyStatusCompatRule(element, path[1:], rule)
+			}
+		case []any:
+			for _, element := range typedValue {
+				applyStatusCompatRule(element, path[1:], rule)
+			}
+		}
+		return
+	}
+	object, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+	fieldValue, ok := object[path[0]]
+	if !ok {
+		return
+	}
+	if len(path) > 1 {
+		applyStatusCompatRule(fieldValue, path[1:], rule)
+		return
+	}
+
+	delete(object, path[0])
+	if rule.Conversion == statusCompatConversionOmit {
+		return
+	}
+	name := path[0]
+	if rule.LegacyName != "" {
+		name = rule.LegacyName
+	}
+	object[name] = convertStatusCompatValue(fieldValue, rule.Conversion)
+}
+
+// convertStatusCompatValue returns the given generic JSON value converted as per the given conversion, or unchanged if
+// it isn't of a type that the conversion applies to.
+func convertStatusCompatValue(value any, conversion statusCompatConversion) any {
+	switch conversion {
+	case statusCompatConversionWallclock:
+		if timestamp, ok := value.(map[string]any); ok {
+			return timestamp["wallclock"]
+		}
+	case statusCompatConversionMessage:
+		if value == nil {
+			return ""
+		}
+		if structuredError, ok := value.(map[string]any); ok {
+			return structuredError["message"]
+		}
+	case statusCompatConversionString:
+		switch typedValue := value.(type) {
+		case json.Number:
+			return typedValue.String()
+		case bool:
+			return strconv.FormatBool(typedValue)
+		}
+	case statusCompatConversionNumber:
+		if stringValue, ok := value.(string); ok {
+			if _, err := strconv.ParseFloat(stringValue, 64); err == nil {
+				return json.Number(stringValue)
+			}
+		}
+	}
+	return value
+}
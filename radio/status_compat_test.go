@@ -0,0 +1,70 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestApplyStatusCompatRules(t *testing.T) {
+	statusJson := `{
+		"channel": "36",
+		"txPower": 20,
+		"lastError": null,
+		"statusChangedAt": {"wallclock": "2024-03-02T10:14:58-08:00", "monotonicNs": 41207641872},
+		"stationStatuses": {
+			"red1": {"ssid": "254", "isLinked": true, "signalNoiseRatio": 38},
+			"red2": null
+		},
+		"statusTransitions": [
+			{"to": "ACTIVE", "at": {"wallclock": "2024-03-02T10:14:58-08:00", "monotonicNs": 41207641872}}
+		]
+	}`
+	rules := []StatusCompatRule{
+		{Path: "channel", Conversion: statusCompatConversionNumber},
+		{Path: "txPower", LegacyName: "txPowerDbm", Conversion: statusCompatConversionString},
+		{Path: "lastError", Conversion: statusCompatConversionMessage},
+		{Path: "statusChangedAt", Conversion: statusCompatConversionWallclock},
+		{Path: "stationStatuses.*.signalNoiseRatio", LegacyName: "snr"},
+		{Path: "stationStatuses.*.isLinked", Conversion: statusCompatConversionOmit},
+		{Path: "statusTransitions.*.at", Conversion: statusCompatConversionWallclock},
+		{Path: "configurationTimeline.steps", Conversion: statusCompatConversionOmit},
+	}
+
+	legacyJson, err := ApplyStatusCompatRules([]byte(statusJson), rules)
+	assert.Nil(t, err)
+	assert.JSONEq(
+		t,
+		`{
+			"channel": 36,
+			"txPowerDbm": "20",
+			"lastError": "",
+			"statusChangedAt": "2024-03-02T10:14:58-08:00",
+			"stationStatuses": {"red1": {"ssid": "254", "snr": 38}, "red2": null},
+			"statusTransitions": [{"to": "ACTIVE", "at": "2024-03-02T10:14:58-08:00"}]
+		}`,
+		string(legacyJson),
+	)
+
+	_, err = ApplyStatusCompatRules([]byte("not JSON"), rules)
+	assert.ErrorContains(t, err, "error decoding status for compatibility conversion")
+}
+
+func TestSettings_StatusCompatProfile(t *testing.T) {
+	settings := defaultSettings()
+	rules, ok := settings.StatusCompatProfile("v1")
+	assert.True(t, ok)
+	assert.Equal(t, builtInStatusCompatProfiles["v1"], rules)
+	_, ok = settings.StatusCompatProfile("fms2023")
+	assert.False(t, ok)
+
+	// Profiles in the settings take precedence over the built-in ones.
+	settings.StatusCompatProfiles = map[string][]StatusCompatRule{
+		"v1":      {{Path: "lastError", Conversion: statusCompatConversionOmit}},
+		"fms2023": {},
+	}
+	rules, ok = settings.StatusCompatProfile("v1")
+	assert.True(t, ok)
+	assert.Equal(t, settings.StatusCompatProfiles["v1"], rules)
+	_, ok = settings.StatusCompatProfile("fms2023")
+	assert.True(t, ok)
+}
@@ -0,0 +1,137 @@
+package radio
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	assocListRateRe  = regexp.MustCompile(`^\s*([RT]X):\s+(\d+\.\d+)\s+MBit/s(.*?)\s+\d+ Pkts\.`)
+	assocListMcsRe   = regexp.MustCompile(`\b(HE|VHT)?-?MCS (\d+)`)
+	assocListNssRe   = regexp.MustCompile(`\b(?:HE|VHT)-NSS (\d+)`)
+	assocListWidthRe = regexp.MustCompile(`\b(\d+)MHz`)
+	assocListHeGiRe  = regexp.MustCompile(`\bHE-GI (\d)`)
+)
+
+// Guard intervals in nanoseconds, indexed by the HE-GI value reported for 802.11ax rates.
+var heGuardIntervalsNs = []int{800, 1600, 3200}
+
+// StatusEnricher is implemented by hardware-specific modules to add extra fields to the status of each network
+// without modifying the common monitoring code. The fields appear in the JSON output of the network status under
+// "extensions", namespaced by the enricher.
+type StatusEnricher interface {
+	// Namespace returns the key under which the enricher's fields appear in the extensions (e.g. "vividHosting").
+	Namespace() string
+
+	// Supports returns whether the enricher applies to the given type of radio hardware.
+	Supports(radioType RadioType) bool
+
+	// Enrich returns the extra fields for the given network interface, or nil if there are none. The association list
+	// entry is the raw 'iwinfo [interface] assoclist' output for the linked device, or blank if none is linked.
+	Enrich(networkInterface string, assocListEntry string) map[string]any
+}
+
+// Enrichers that are applied to the network statuses, in order of registration.
+var statusEnrichers = []StatusEnricher{vividHostingStatusEnricher{}, linksysStatusEnricher{}}
+
+// RegisterStatusEnricher adds the given enricher to those applied to the network statuses. Must be called before the
+// radio starts running.
+func RegisterStatusEnricher(enricher StatusEnricher) {
+	statusEnrichers = append(statusEnrichers, enricher)
+}
+
+// statusEnrichersFor returns the registered enrichers that apply to the given type of radio hardware.
+func statusEnrichersFor(radioType RadioType) []StatusEnricher {
+	var enrichers []StatusEnricher
+	for _, enricher := range statusEnrichers {
+		if enricher.Supports(radioType) {
+			enrichers = append(enrichers, enricher)
+		}
+	}
+	return enrichers
+}
+
+// enrich replaces the extensions of the status with the fields provided by the given enrichers.
+func (status *NetworkStatus) enrich(networkInterface string, assocList string, enrichers []StatusEnricher) {
+	status.Extensions = nil
+	var entry string
+	if status.IsLinked {
+		entry = assocListEntry(assocList, status.MacAddress)
+	}
+	for _, enricher := range enrichers {
+		if fields := enricher.Enrich(networkInterface, entry); len(fields) > 0 {
+			if status.Extensions == nil {
+				status.Extensions = make(map[string]map[string]any)
+			}
+			status.Extensions[enricher.Namespace()] = fields
+		}
+	}
+}
+
+// assocListEntry returns the portion of the given 'iwinfo [interface] assoclist' output that pertains to the device
+// with the given MAC address, or blank if it isn't present.
+func assocListEntry(assocList string, macAddress string) string {
+	start := strings.Index(assocList, macAddress)
+	if start < 0 {
+		return ""
+	}
+	entry := assocList[start:]
+	if end := strings.Index(entry, "\n\n"); end >= 0 {
+		entry = entry[:end]
+	}
+	return entry
+}
+
+// assocListRate represents the link parameters in use in one direction, as reported in an association list entry.
+type assocListRate struct {
+	rateMbps        float64
+	mode            string
+	mcs             int
+	nss             int
+	channelWidthMhz int
+	guardIntervalNs int
+}
+
+// parseAssocListRates parses the receive and transmit link parameters from the given association list entry, keyed by
+// "rx" and "tx".
+func parseAssocListRates(entry string) map[string]assocListRate {
+	rates := make(map[string]assocListRate)
+	for _, line := range strings.Split(entry, "\n") {
+		match := assocListRateRe.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		rate := assocListRate{mode: "LEGACY", guardIntervalNs: 800}
+		rate.rateMbps, _ = strconv.ParseFloat(match[2], 64)
+		details := match[3]
+		if mcsMatch := assocListMcsRe.FindStringSubmatch(details); mcsMatch != nil {
+			rate.mode = "HT"
+			if mcsMatch[1] != "" {
+				rate.mode = mcsMatch[1]
+			}
+			rate.mcs, _ = strconv.Atoi(mcsMatch[2])
+
+			// HT MCS indices encode the number of spatial streams, eight indices per stream.
+			rate.nss = 1
+			if rate.mode == "HT" {
+				rate.nss = rate.mcs/8 + 1
+			}
+		}
+		if nssMatch := assocListNssRe.FindStringSubmatch(details); nssMatch != nil {
+			rate.nss, _ = strconv.Atoi(nssMatch[1])
+		}
+		if widthMatch := assocListWidthRe.FindStringSubmatch(details); widthMatch != nil {
+			rate.channelWidthMhz, _ = strconv.Atoi(widthMatch[1])
+		}
+		if strings.Contains(details, "short GI") {
+			rate.guardIntervalNs = 400
+		} else if giMatch := assocListHeGiRe.FindStringSubmatch(details); giMatch != nil {
+			if gi, _ := strconv.Atoi(giMatch[1]); gi < len(heGuardIntervalsNs) {
+				rate.guardIntervalNs = heGuardIntervalsNs[gi]
+			}
+		}
+		rates[strings.ToLower(match[1])] = rate
+	}
+	return rates
+}
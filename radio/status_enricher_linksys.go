@@ -0,0 +1,26 @@
+package radio
+
+// linksysStatusEnricher reports whether the link is using legacy (pre-802.11n) rates on Linksys access points, which
+// indicates a badly degraded or misconfigured connection.
+type linksysStatusEnricher struct{}
+
+func (enricher linksysStatusEnricher) Namespace() string {
+	return "linksys"
+}
+
+func (enricher linksysStatusEnricher) Supports(radioType RadioType) bool {
+	return radioType == TypeLinksys
+}
+
+func (enricher linksysStatusEnricher) Enrich(networkInterface string, assocListEntry string) map[string]any {
+	fields := make(map[string]any)
+	for direction, rate := range parseAssocListRates(assocListEntry) {
+		fields[direction+"IsLegacyRate"] = rate.mode == "LEGACY"
+		if rate.mode == "LEGACY" {
+			fields[direction+"LegacyRateMbps"] = rate.rateMbps
+		} else {
+			fields[direction+"Mcs"] = rate.mcs
+		}
+	}
+	return fields
+}
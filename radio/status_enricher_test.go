@@ -0,0 +1,114 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// fakeStatusEnricher is a status enricher that reports the interface it was called with, for testing purposes.
+type fakeStatusEnricher struct{}
+
+func (enricher fakeStatusEnricher) Namespace() string {
+	return "fake"
+}
+
+func (enricher fakeStatusEnricher) Supports(radioType RadioType) bool {
+	return radioType == TypeLinksys
+}
+
+func (enricher fakeStatusEnricher) Enrich(networkInterface string, assocListEntry string) map[string]any {
+	return map[string]any{"interface": networkInterface, "isLinked": assocListEntry != ""}
+}
+
+const enrichedAssocList = "37:DA:35:B0:00:BE  -64 dBm / -84 dBm (SNR 7)  60000 ms ago\n" +
+	"\tRX: 6.0 MBit/s                                     12 Pkts.\n" +
+	"\tTX: 6.0 MBit/s                                      3 Pkts.\n" +
+	"\texpected throughput: unknown\n" +
+	"\n" +
+	"48:DA:35:B0:00:CF  -53 dBm / -95 dBm (SNR 42)  0 ms ago\n" +
+	"\tRX: 1200.9 MBit/s, HE-MCS 11, 80MHz, HE-NSS 2, HE-GI 0, HE-DCM 0      4095 Pkts.\n" +
+	"\tTX: 54.0 MBit/s                                 123 Pkts.\n" +
+	"\texpected throughput: unknown"
+
+func TestRegisterStatusEnricher(t *testing.T) {
+	originalEnrichers := statusEnrichers
+	t.Cleanup(func() { statusEnrichers = originalEnrichers })
+
+	RegisterStatusEnricher(fakeStatusEnricher{})
+	assert.Equal(t, []StatusEnricher{linksysStatusEnricher{}, fakeStatusEnricher{}}, statusEnrichersFor(TypeLinksys))
+	assert.Equal(t, []StatusEnricher{vividHostingStatusEnricher{}}, statusEnrichersFor(TypeVividHosting))
+	assert.Nil(t, statusEnrichersFor(TypeUnknown))
+}
+
+func TestNetworkStatus_enrich(t *testing.T) {
+	var status NetworkStatus
+	status.parseAssocList(enrichedAssocList)
+	status.enrich("wlan0", enrichedAssocList, []StatusEnricher{fakeStatusEnricher{}, linksysStatusEnricher{}})
+	assert.Equal(
+		t,
+		map[string]map[string]any{
+			"fake": {"interface": "wlan0", "isLinked": true},
+			"linksys": {
+				"rxIsLegacyRate":   false,
+				"rxMcs":            11,
+				"txIsLegacyRate":   true,
+				"txLegacyRateMbps": 54.0,
+			},
+		},
+		status.Extensions,
+	)
+
+	// Enrichers that provide no fields are omitted.
+	status.parseAssocList("")
+	status.enrich("wlan0", "", []StatusEnricher{fakeStatusEnricher{}, linksysStatusEnricher{}})
+	assert.Equal(t, map[string]map[string]any{"fake": {"interface": "wlan0", "isLinked": false}}, status.Extensions)
+	status.enrich("wlan0", "", []StatusEnricher{linksysStatusEnricher{}})
+	assert.Nil(t, status.Extensions)
+}
+
+func TestVividHostingStatusEnricher(t *testing.T) {
+	entry := assocListEntry(enrichedAssocList, "48:DA:35:B0:00:CF")
+	assert.Equal(
+		t,
+		map[string]any{"rxMode": "HE", "rxMcs": 11, "rxNss": 2, "rxChannelWidthMhz": 80},
+		vividHostingStatusEnricher{}.Enrich("ath1", entry),
+	)
+	assert.Empty(t, vividHostingStatusEnricher{}.Enrich("ath1", ""))
+}
+
+func TestParseAssocListRates(t *testing.T) {
+	rates := parseAssocListRates(
+		"48:DA:35:B0:00:CF  -53 dBm / -95 dBm (SNR 42)  0 ms ago\n" +
+			"\tRX: 866.7 MBit/s, VHT-MCS 9, 80MHz, VHT-NSS 2                3875 Pkts.\n" +
+			"\tTX: 144.4 MBit/s, MCS 15, 20MHz, short GI                     512 Pkts.\n",
+	)
+	assert.Equal(
+		t,
+		map[string]assocListRate{
+			"rx": {rateMbps: 866.7, mode: "VHT", mcs: 9, nss: 2, channelWidthMhz: 80, guardIntervalNs: 800},
+			"tx": {rateMbps: 144.4, mode: "HT", mcs: 15, nss: 2, channelWidthMhz: 20, guardIntervalNs: 400},
+		},
+		rates,
+	)
+	assert.Empty(t, parseAssocListRates(""))
+
+	rates = parseAssocListRates(
+		"\tRX: 1200.9 MBit/s, HE-MCS 11, 80MHz, HE-NSS 2, HE-GI 2, HE-DCM 0      4095 Pkts.\n" +
+			"\tTX: 6.0 MBit/s                                      3 Pkts.\n",
+	)
+	assert.Equal(
+		t,
+		map[string]assocListRate{
+			"rx": {rateMbps: 1200.9, mode: "HE", mcs: 11, nss: 2, channelWidthMhz: 80, guardIntervalNs: 3200},
+			"tx": {rateMbps: 6.0, mode: "LEGACY", guardIntervalNs: 800},
+		},
+		rates,
+	)
+}
+
+func TestAssocListEntry(t *testing.T) {
+	entry := assocListEntry(enrichedAssocList, "37:DA:35:B0:00:BE")
+	assert.Contains(t, entry, "RX: 6.0 MBit/s")
+	assert.NotContains(t, entry, "48:DA:35:B0:00:CF")
+	assert.Equal(t, "", assocListEntry(enrichedAssocList, "12:34:56:78:9A:BC"))
+}
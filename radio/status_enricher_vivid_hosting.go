@@ -0,0 +1,27 @@
+package radio
+
+// vividHostingStatusEnricher reports the 802.11ax modulation and coding parameters of the link on Vivid-Hosting
+// radios, which are key to diagnosing 6GHz link quality.
+type vividHostingStatusEnricher struct{}
+
+func (enricher vividHostingStatusEnricher) Namespace() string {
+	return "vividHosting"
+}
+
+func (enricher vividHostingStatusEnricher) Supports(radioType RadioType) bool {
+	return radioType == TypeVividHosting
+}
+
+func (enricher vividHostingStatusEnricher) Enrich(networkInterface string, assocListEntry string) map[string]any {
+	fields := make(map[string]any)
+	for direction, rate := range parseAssocListRates(assocListEntry) {
+		if rate.mode == "LEGACY" {
+			continue
+		}
+		fields[direction+"Mode"] = rate.mode
+		fields[direction+"Mcs"] = rate.mcs
+		fields[direction+"Nss"] = rate.nss
+		fields[direction+"ChannelWidthMhz"] = rate.channelWidthMhz
+	}
+	return fields
+}
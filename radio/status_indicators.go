@@ -0,0 +1,67 @@
+package radio
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// StatusIndicatorSettings holds the parameters for driving the hardware status LEDs of the robot radio and reading the
+// conditions reported by its GPIOs, so that teams can diagnose the radio by its lights.
+type StatusIndicatorSettings struct {
+	// Path to the sysfs directory of the LED reflecting the link state (e.g. "/sys/class/leds/link"). Blank leaves the
+	// LED alone.
+	LinkLedPath string `json:"linkLedPath"`
+
+	// Path to the sysfs directory of the LED reflecting whether the field can be reached through the link (e.g.
+	// "/sys/class/leds/field"). Blank leaves the LED alone.
+	FieldLedPath string `json:"fieldLedPath"`
+
+	// GPIOs reporting hardware conditions, such as whether the radio is powered over PoE or from its barrel jack.
+	Inputs []GpioInputSettings `json:"inputs"`
+}
+
+// GpioInputSettings holds the parameters for reading a single GPIO that reports a hardware condition.
+type GpioInputSettings struct {
+	// Unique, human-readable name of the condition (e.g. "poePower").
+	Name string `json:"name"`
+
+	// Path to the sysfs value file of the GPIO (e.g. "/sys/class/gpio/gpio17/value").
+	GpioValuePath string `json:"gpioValuePath"`
+
+	// Whether the GPIO reads as 0 while the condition holds.
+	ActiveLow bool `json:"activeLow"`
+}
+
+// validate checks that the status indicator settings have valid values.
+func (settings StatusIndicatorSettings) validate() error {
+	for _, path := range []struct {
+		name  string
+		value string
+	}{
+		{"linkLedPath", settings.LinkLedPath},
+		{"fieldLedPath", settings.FieldLedPath},
+	} {
+		if path.value != "" && !filepath.IsAbs(path.value) {
+			return fmt.Errorf("invalid statusIndicators.%s: %q (expecting an absolute path)", path.name, path.value)
+		}
+	}
+	names := make(map[string]struct{})
+	for _, input := range settings.Inputs {
+		if input.Name == "" {
+			return errors.New("status indicator input name cannot be blank")
+		}
+		if _, ok := names[input.Name]; ok {
+			return fmt.Errorf("duplicate status indicator input name: %s", input.Name)
+		}
+		names[input.Name] = struct{}{}
+		if !filepath.IsAbs(input.GpioValuePath) {
+			return fmt.Errorf(
+				"invalid gpioValuePath for status indicator input %s: %q (expecting an absolute path)",
+				input.Name,
+				input.GpioValuePath,
+			)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package radio
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Time that a blinking status LED spends on and off in each cycle, in milliseconds.
+const statusLedBlinkIntervalMs = "250"
+
+// ledState represents what a hardware status LED is showing.
+type ledState string
+
+const (
+	ledStateOff      ledState = "OFF"
+	ledStateOn       ledState = "ON"
+	ledStateBlinking ledState = "BLINKING"
+)
+
+// GpioInputStatus represents the current reading of a GPIO that reports a hardware condition.
+type GpioInputStatus struct {
+	// Name of the condition, as given in the settings.
+	Name string `json:"name"`
+
+	// Whether the condition currently holds.
+	IsActive bool `json:"isActive"`
+
+	// Why the GPIO couldn't be read, or blank if it was read successfully.
+	Error string `json:"error"`
+}
+
+// StatusIndicatorStatus represents the state of the hardware status indicators of the robot radio.
+type StatusIndicatorStatus struct {
+	// What the link LED is showing: "ON" while linked, "BLINKING" while configuring, or "OFF" otherwise. Blank if no
+	// link LED is configured.
+	LinkLed ledState `json:"linkLed"`
+
+	// What the field LED is showing: "ON" while the driver station and FMS can be reached, "BLINKING" while linked but
+	// traffic isn't getting through, or "OFF" otherwise. Blank if no field LED is configured.
+	FieldLed ledState `json:"fieldLed"`
+
+	// Current reading of each configured GPIO input, in the order given in the settings.
+	Inputs []GpioInputStatus `json:"inputs"`
+}
+
+// statusLed tracks what has been written to a single hardware status LED, so that it is only rewritten when its state
+// changes rather than restarting its blink cycle at every poll.
+type statusLed struct {
+	path  string
+	state ledState
+}
+
+// GetStatusIndicators returns what the status LEDs are showing, along with a fresh reading of each GPIO input.
+func (radio *Radio) GetStatusIndicators() StatusIndicatorStatus {
+	settings := radio.GetSettings().StatusIndicators
+	radio.statusIndicatorsMutex.Lock()
+	status := StatusIndicatorStatus{Inputs: []GpioInputStatus{}}
+	if settings.LinkLedPath != "" {
+		status.LinkLed = radio.linkLed.state
+	}
+	if settings.FieldLedPath != "" {
+		status.FieldLed = radio.fieldLed.state
+	}
+	radio.statusIndicatorsMutex.Unlock()
+
+	for _, input := range settings.Inputs {
+		inputStatus := GpioInputStatus{Name: input.Name}
+		if contents, err := os.ReadFile(input.GpioValuePath); err != nil {
+			inputStatus.Error = err.Error()
+		} else {
+			inputStatus.IsActive = (strings.TrimSpace(string(contents)) == "1") != input.ActiveLow
+		}
+		status.Inputs = append(status.Inputs, inputStatus)
+	}
+	return status
+}
+
+// updateStatusIndicators drives the status LEDs to reflect the current link and field connection state.
+func (radio *Radio) updateStatusIndicators() {
+	settings := radio.GetSettings().StatusIndicators
+
+	linkState := ledStateOff
+	if radio.Status == statusConfiguring || radio.Status == statusBooting {
+		linkState = ledStateBlinking
+	} else if radio.NetworkStatus6.IsLinked {
+		linkState = ledStateOn
+	}
+
+	fieldState := ledStateOff
+	switch radio.Connectivity.Diagnosis {
+	case diagnosisOk:
+		fieldState = ledStateOn
+	case diagnosisFieldUnreachable, diagnosisDriverStationUnreachable, diagnosisFmsUnreachable:
+		fieldState = ledStateBlinking
+	}
+
+	radio.statusIndicatorsMutex.Lock()
+	defer radio.statusIndicatorsMutex.Unlock()
+	radio.linkLed.set(settings.LinkLedPath, linkState)
+	radio.fieldLed.set(settings.FieldLedPath, fieldState)
+}
+
+// set drives the LED at the given sysfs path to the given state if it isn't already showing it. Nothing is written if
+// the path is blank.
+func (led *statusLed) set(path string, state ledState) {
+	if path == "" || path == led.path && state == led.state {
+		led.path = path
+		return
+	}
+
+	var values [][2]string
+	switch state {
+	case ledStateBlinking:
+		values = [][2]string{
+			{"trigger", "timer"}, {"delay_on", statusLedBlinkIntervalMs}, {"delay_off", statusLedBlinkIntervalMs},
+		}
+	case ledStateOn:
+		values = [][2]string{{"trigger", "none"}, {"brightness", "1"}}
+	default:
+		values = [][2]string{{"trigger", "none"}, {"brightness", "0"}}
+	}
+	for _, value := range values {
+		if err := os.WriteFile(filepath.Join(path, value[0]), []byte(value[1]), 0644); err != nil {
+			// Try again at the next poll.
+			log.Printf("Error setting status LED %s to %s: %v", path, state, err)
+			led.path = ""
+			return
+		}
+	}
+	led.path = path
+	led.state = state
+}
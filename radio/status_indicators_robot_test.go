@@ -0,0 +1,89 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readLed returns the contents of each attribute file written to the fake sysfs LED directory at the given path.
+func readLed(t *testing.T, path string) map[string]string {
+	attributes := make(map[string]string)
+	entries, err := os.ReadDir(path)
+	assert.Nil(t, err)
+	for _, entry := range entries {
+		contents, _ := os.ReadFile(filepath.Join(path, entry.Name()))
+		attributes[entry.Name()] = string(contents)
+	}
+	return attributes
+}
+
+func TestRadio_updateStatusIndicators(t *testing.T) {
+	linkLedPath, fieldLedPath := t.TempDir(), t.TempDir()
+	radio := &Radio{Status: statusActive, settings: defaultSettings()}
+	radio.settings.StatusIndicators = StatusIndicatorSettings{LinkLedPath: linkLedPath, FieldLedPath: fieldLedPath}
+
+	radio.Connectivity.Diagnosis = diagnosisNotLinked
+	radio.updateStatusIndicators()
+	assert.Equal(t, map[string]string{"trigger": "none", "brightness": "0"}, readLed(t, linkLedPath))
+	assert.Equal(t, map[string]string{"trigger": "none", "brightness": "0"}, readLed(t, fieldLedPath))
+
+	radio.NetworkStatus6.IsLinked = true
+	radio.Connectivity.Diagnosis = diagnosisFmsUnreachable
+	radio.updateStatusIndicators()
+	assert.Equal(t, map[string]string{"trigger": "none", "brightness": "1"}, readLed(t, linkLedPath))
+	assert.Equal(
+		t,
+		map[string]string{"trigger": "timer", "brightness": "0", "delay_on": "250", "delay_off": "250"},
+		readLed(t, fieldLedPath),
+	)
+	status := radio.GetStatusIndicators()
+	assert.Equal(t, ledStateOn, status.LinkLed)
+	assert.Equal(t, ledStateBlinking, status.FieldLed)
+
+	// An LED that is already showing the right state isn't rewritten, so its blink cycle isn't restarted.
+	assert.Nil(t, os.Remove(filepath.Join(fieldLedPath, "delay_on")))
+	radio.updateStatusIndicators()
+	_, ok := readLed(t, fieldLedPath)["delay_on"]
+	assert.False(t, ok)
+
+	radio.Connectivity.Diagnosis = diagnosisOk
+	radio.Status = statusConfiguring
+	radio.updateStatusIndicators()
+	assert.Equal(t, "timer", readLed(t, linkLedPath)["trigger"])
+	assert.Equal(
+		t, map[string]string{"trigger": "none", "brightness": "1", "delay_off": "250"}, readLed(t, fieldLedPath),
+	)
+
+	// LEDs that aren't configured are left alone and reported as blank.
+	radio.settings.StatusIndicators = StatusIndicatorSettings{}
+	status = radio.GetStatusIndicators()
+	assert.Equal(t, StatusIndicatorStatus{Inputs: []GpioInputStatus{}}, status)
+}
+
+func TestRadio_GetStatusIndicatorsInputs(t *testing.T) {
+	directory := t.TempDir()
+	poePath := filepath.Join(directory, "gpio17")
+	barrelPath := filepath.Join(directory, "gpio18")
+	assert.Nil(t, os.WriteFile(poePath, []byte("0\n"), 0644))
+	assert.Nil(t, os.WriteFile(barrelPath, []byte("1\n"), 0644))
+	radio := &Radio{settings: defaultSettings()}
+	radio.settings.StatusIndicators.Inputs = []GpioInputSettings{
+		{Name: "poePower", GpioValuePath: poePath, ActiveLow: true},
+		{Name: "barrelPower", GpioValuePath: barrelPath},
+		{Name: "fanFault", GpioValuePath: filepath.Join(directory, "missing")},
+	}
+
+	inputs := radio.GetStatusIndicators().Inputs
+	if assert.Equal(t, 3, len(inputs)) {
+		assert.Equal(t, GpioInputStatus{Name: "poePower", IsActive: true}, inputs[0])
+		assert.Equal(t, GpioInputStatus{Name: "barrelPower", IsActive: true}, inputs[1])
+		assert.Equal(t, "fanFault", inputs[2].Name)
+		assert.False(t, inputs[2].IsActive)
+		assert.Contains(t, inputs[2].Error, "no such file or directory")
+	}
+}
@@ -0,0 +1,92 @@
+package radio
+
+import (
+	"log"
+	"time"
+)
+
+// Maximum number of status transitions to retain; older ones are discarded first.
+const maxStatusTransitions = 20
+
+// Map of each configuration stage of the radio to the stages it may move to directly.
+var allowedStatusTransitions = map[radioStatus][]radioStatus{
+	statusBooting:               {statusMisconfiguredBaseline, statusProvisioning, statusConfiguring, statusActive},
+	statusMisconfiguredBaseline: {statusBooting},
+	statusProvisioning:          {statusBooting},
+	statusActive:                {statusConfiguring},
+	statusConfiguring:           {statusActive, statusError},
+	statusError:                 {statusConfiguring},
+}
+
+// StatusTransition represents a single change of the configuration stage of the radio.
+type StatusTransition struct {
+	// Stage that the radio left.
+	From radioStatus `json:"from"`
+
+	// Stage that the radio entered.
+	To radioStatus `json:"to"`
+
+	// Time at which the change happened.
+	At Timestamp `json:"at"`
+
+	// How long the radio had been in the stage that it left, in seconds.
+	TimeInPreviousStatusSec float64 `json:"timeInPreviousStatusSec"`
+}
+
+// StatusTransitionHook is a function called on the radio goroutine whenever the configuration stage of the radio
+// changes, e.g. to notify a webhook, record a metric, or drive an LED. It must return quickly since it holds up the
+// radio.
+type StatusTransitionHook func(transition StatusTransition)
+
+// Hooks called whenever the configuration stage of the radio changes, in order of registration.
+var statusTransitionHooks []StatusTransitionHook
+
+// RegisterStatusTransitionHook adds the given hook to those called whenever the configuration stage of the radio
+// changes. Must be called before the radio starts running.
+func RegisterStatusTransitionHook(hook StatusTransitionHook) {
+	statusTransitionHooks = append(statusTransitionHooks, hook)
+}
+
+// setStatus moves the radio to the given configuration stage, recording the transition and calling the registered
+// hooks. Moves that the state machine doesn't allow are logged and ignored so that the status stays consistent.
+func (radio *Radio) setStatus(status radioStatus) {
+	if status == radio.Status {
+		return
+	}
+	from := radio.Status
+	if from == "" {
+		// A radio that hasn't been initialized is considered to be booting.
+		from = statusBooting
+	}
+	if !isStatusTransitionAllowed(from, status) {
+		log.Printf("Ignoring invalid radio status transition from %s to %s.", from, status)
+		return
+	}
+
+	// A radio that has never changed status has been in its initial one since the process started.
+	transition := StatusTransition{From: from, To: status, At: newTimestamp()}
+	transition.TimeInPreviousStatusSec = time.Duration(
+		transition.At.MonotonicNs - radio.StatusChangedAt.MonotonicNs,
+	).Seconds()
+	radio.Status = status
+	radio.StatusChangedAt = transition.At
+	radio.StatusTransitions = append(radio.StatusTransitions, transition)
+	if len(radio.StatusTransitions) > maxStatusTransitions {
+		radio.StatusTransitions = radio.StatusTransitions[len(radio.StatusTransitions)-maxStatusTransitions:]
+	}
+	for _, hook := range statusTransitionHooks {
+		hook(transition)
+	}
+	radio.publishEvent(eventTypeStatusTransition, transition)
+}
+
+// isStatusTransitionAllowed returns whether the radio may move directly from the first configuration stage to the
+// second.
+func isStatusTransitionAllowed(from, to radioStatus) bool {
+	for _, allowed := range allowedStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRadio_setStatusTransitions(t *testing.T) {
+	var transitions []StatusTransition
+	statusTransitionHooks = []StatusTransitionHook{func(transition StatusTransition) {
+		transitions = append(transitions, transition)
+	}}
+	defer func() { statusTransitionHooks = nil }()
+
+	radio := Radio{Status: statusBooting}
+	radio.setStatus(statusActive)
+	radio.setStatus(statusConfiguring)
+	radio.setStatus(statusError)
+	if assert.Equal(t, 3, len(radio.StatusTransitions)) {
+		assert.Equal(t, statusBooting, radio.StatusTransitions[0].From)
+		assert.Equal(t, statusActive, radio.StatusTransitions[0].To)
+		assert.Greater(t, radio.StatusTransitions[0].TimeInPreviousStatusSec, 0.0)
+		assert.Equal(t, statusConfiguring, radio.StatusTransitions[2].From)
+		assert.Equal(t, statusError, radio.StatusTransitions[2].To)
+		assert.Equal(t, radio.StatusChangedAt, radio.StatusTransitions[2].At)
+	}
+	assert.Equal(t, radio.StatusTransitions, transitions)
+
+	// Transitions that the state machine doesn't allow are ignored.
+	radio.setStatus(statusActive)
+	assert.Equal(t, statusError, radio.Status)
+	radio.setStatus(statusBooting)
+	assert.Equal(t, statusError, radio.Status)
+	assert.Equal(t, 3, len(transitions))
+
+	// Only the most recent transitions are retained.
+	for i := 0; i < maxStatusTransitions; i++ {
+		radio.setStatus(statusConfiguring)
+		radio.setStatus(statusActive)
+	}
+	assert.Equal(t, maxStatusTransitions, len(radio.StatusTransitions))
+	assert.Equal(t, statusActive, radio.StatusTransitions[maxStatusTransitions-1].To)
+}
+
+func TestIsStatusTransitionAllowed(t *testing.T) {
+	assert.True(t, isStatusTransitionAllowed(statusBooting, statusMisconfiguredBaseline))
+	assert.True(t, isStatusTransitionAllowed(statusMisconfiguredBaseline, statusBooting))
+	assert.False(t, isStatusTransitionAllowed(statusMisconfiguredBaseline, statusActive))
+	assert.True(t, isStatusTransitionAllowed(statusProvisioning, statusBooting))
+	assert.False(t, isStatusTransitionAllowed(statusProvisioning, statusConfiguring))
+	assert.True(t, isStatusTransitionAllowed(statusError, statusConfiguring))
+	assert.False(t, isStatusTransitionAllowed(statusActive, statusError))
+
+	// Every status can be left for some other one.
+	for _, status := range []radioStatus{
+		statusBooting, statusConfiguring, statusActive, statusError, statusMisconfiguredBaseline, statusProvisioning,
+	} {
+		assert.NotEmpty(t, allowedStatusTransitions[status], status)
+	}
+}
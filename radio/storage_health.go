@@ -0,0 +1,184 @@
+package radio
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// How many monitoring polls to wait between storage health checks, since flash state changes slowly.
+	storageCheckIntervalPolls = 60
+
+	// Mount point of the writable overlay filesystem, which holds all persistent changes made to the radio.
+	overlayMountPoint = "/overlay"
+
+	// Percentage of free space on the overlay filesystem below which an alert is raised.
+	lowOverlayFreePercent = 10.0
+)
+
+// Directory in which the UCI configuration files are stored; variable to facilitate testing.
+var uciConfigDirectory = "/etc/config"
+
+// Directory in which the kernel exposes the wear statistics of the UBI flash device; variable to facilitate testing.
+var ubiSysfsDirectory = "/sys/class/ubi/ubi0"
+
+// StorageHealth represents the state of the radio's flash storage, which can be exhausted by excessive writes over a
+// long event.
+type StorageHealth struct {
+	// Time at which the storage health was last checked.
+	CheckedAt Timestamp `json:"checkedAt"`
+
+	// Total size of the overlay filesystem, in bytes.
+	OverlayTotalBytes int64 `json:"overlayTotalBytes"`
+
+	// Free space remaining on the overlay filesystem, in bytes.
+	OverlayFreeBytes int64 `json:"overlayFreeBytes"`
+
+	// Free space remaining on the overlay filesystem, as a percentage of its total size.
+	OverlayFreePercent float64 `json:"overlayFreePercent"`
+
+	// Whether the free space on the overlay filesystem is below the alert threshold.
+	IsOverlayLow bool `json:"isOverlayLow"`
+
+	// Whether the flash wear statistics below are available; they are only reported for UBI flash devices.
+	IsFlashWearAvailable bool `json:"isFlashWearAvailable"`
+
+	// Highest number of times any flash erase block has been erased.
+	FlashMaxEraseCount int `json:"flashMaxEraseCount"`
+
+	// Average number of times each flash erase block has been erased.
+	FlashMeanEraseCount int `json:"flashMeanEraseCount"`
+
+	// Number of flash erase blocks that have gone bad.
+	FlashBadBlockCount int `json:"flashBadBlockCount"`
+
+	// Number of spare flash erase blocks remaining to replace ones that go bad.
+	FlashReservedBlockCount int `json:"flashReservedBlockCount"`
+
+	// Number of times the UCI configuration has been committed to flash since the API started.
+	UciCommitCount int `json:"uciCommitCount"`
+
+	// Approximate number of bytes written to flash by UCI commits since the API started.
+	UciBytesWritten int64 `json:"uciBytesWritten"`
+}
+
+// commitUci commits pending changes to the given UCI configurations and accounts for the resulting flash writes.
+func (radio *Radio) commitUci(configs ...string) error {
+	fileInfos := uciFileInfos()
+	for _, change := range stagedUciChanges() {
+		log.Printf("Committing UCI change: %s", change)
+	}
+	if err := uciTree.Commit(); err != nil {
+		return withKind(ErrCommitFailed, err)
+	}
+	radio.recordUciSnapshots(fileInfos)
+	radio.Storage.UciCommitCount++
+	for _, config := range configs {
+		// The whole file is rewritten on commit, so its size approximates the volume written.
+		if fileInfo, err := os.Stat(filepath.Join(uciConfigDirectory, config)); err == nil {
+			radio.Storage.UciBytesWritten += fileInfo.Size()
+		}
+	}
+	return nil
+}
+
+// updateStorageHealth periodically checks the free space and wear of the flash storage, raising alerts as it degrades,
+// and enforces the retention policies for diagnostic files.
+func (radio *Radio) updateStorageHealth() {
+	radio.storagePollCount++
+	if (radio.storagePollCount-1)%storageCheckIntervalPolls != 0 {
+		return
+	}
+	storage := &radio.Storage
+	storage.CheckedAt = newTimestamp()
+
+	if output, err := shell.runCommand("df", "-k", overlayMountPoint); err != nil {
+		log.Printf("Error running 'df -k %s': %v", overlayMountPoint, err)
+	} else if totalBytes, freeBytes, err := parseDf(output); err != nil {
+		log.Printf("Error parsing 'df -k %s' output: %v", overlayMountPoint, err)
+	} else {
+		storage.OverlayTotalBytes = totalBytes
+		storage.OverlayFreeBytes = freeBytes
+		storage.OverlayFreePercent = 0
+		if totalBytes > 0 {
+			storage.OverlayFreePercent = float64(int(1000*float64(freeBytes)/float64(totalBytes))) / 10
+		}
+		wasLow := storage.IsOverlayLow
+		storage.IsOverlayLow = storage.OverlayFreePercent < lowOverlayFreePercent
+		if storage.IsOverlayLow && !wasLow {
+			radio.raiseAlert(
+				"STORAGE_LOW",
+				"Only %.1f%% (%d KB) of the overlay filesystem is free.",
+				storage.OverlayFreePercent,
+				freeBytes/1024,
+			)
+		}
+	}
+
+	previousBadBlockCount := storage.FlashBadBlockCount
+	wasFlashWearAvailable := storage.IsFlashWearAvailable
+	storage.IsFlashWearAvailable = storage.readFlashWear() == nil
+	if storage.IsFlashWearAvailable && wasFlashWearAvailable && storage.FlashBadBlockCount > previousBadBlockCount {
+		radio.raiseAlert(
+			"FLASH_WEAR",
+			"Flash bad block count increased from %d to %d; %d spare blocks remain.",
+			previousBadBlockCount,
+			storage.FlashBadBlockCount,
+			storage.FlashReservedBlockCount,
+		)
+	}
+
+	radio.enforceRetentionPolicies(time.Now())
+}
+
+// readFlashWear populates the flash wear statistics from the UBI sysfs attributes.
+func (storage *StorageHealth) readFlashWear() error {
+	attributes := []struct {
+		name  string
+		value *int
+	}{
+		{"max_ec", &storage.FlashMaxEraseCount},
+		{"mean_ec", &storage.FlashMeanEraseCount},
+		{"bad_peb_count", &storage.FlashBadBlockCount},
+		{"reserved_for_bad", &storage.FlashReservedBlockCount},
+	}
+	for _, attribute := range attributes {
+		valueBytes, err := os.ReadFile(filepath.Join(ubiSysfsDirectory, attribute.name))
+		if err != nil {
+			return err
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(string(valueBytes)))
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: %v", attribute.name, err)
+		}
+		*attribute.value = value
+	}
+	return nil
+}
+
+// parseDf parses the output of 'df -k [mount point]' into the total and free size of the filesystem, in bytes.
+func parseDf(response string) (int64, int64, error) {
+	lines := strings.Split(strings.TrimSpace(response), "\n")
+	if len(lines) < 2 {
+		return 0, 0, errors.New("missing filesystem line")
+	}
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return 0, 0, fmt.Errorf("unexpected filesystem line: %s", lines[len(lines)-1])
+	}
+	totalKb, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid total size: %v", err)
+	}
+	freeKb, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid free size: %v", err)
+	}
+	return totalKb * 1024, freeKb * 1024, nil
+}
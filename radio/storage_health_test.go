@@ -0,0 +1,105 @@
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeUbiAttributes(t *testing.T, directory string, maxEc, meanEc, badPebCount, reservedForBad string) {
+	for name, value := range map[string]string{
+		"max_ec": maxEc, "mean_ec": meanEc, "bad_peb_count": badPebCount, "reserved_for_bad": reservedForBad,
+	} {
+		assert.Nil(t, os.WriteFile(filepath.Join(directory, name), []byte(value+"\n"), 0644))
+	}
+}
+
+func TestRadio_updateStorageHealth(t *testing.T) {
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	ubiSysfsDirectory = t.TempDir()
+	t.Cleanup(func() { ubiSysfsDirectory = "/sys/class/ubi/ubi0" })
+	radio := Radio{}
+
+	dfCommand := "df -k /overlay"
+	fakeShell.commandOutput[dfCommand] = "Filesystem           1K-blocks      Used Available Use% Mounted on\n" +
+		"/dev/ubi0_5              54124     20000     30000  40% /overlay\n"
+	writeUbiAttributes(t, ubiSysfsDirectory, "120", "45", "2", "18")
+	radio.updateStorageHealth()
+	storage := radio.Storage
+	assert.False(t, storage.CheckedAt.Wallclock.IsZero())
+	assert.Equal(t, int64(54124*1024), storage.OverlayTotalBytes)
+	assert.Equal(t, int64(30000*1024), storage.OverlayFreeBytes)
+	assert.Equal(t, 55.4, storage.OverlayFreePercent)
+	assert.False(t, storage.IsOverlayLow)
+	assert.True(t, storage.IsFlashWearAvailable)
+	assert.Equal(t, 120, storage.FlashMaxEraseCount)
+	assert.Equal(t, 45, storage.FlashMeanEraseCount)
+	assert.Equal(t, 2, storage.FlashBadBlockCount)
+	assert.Equal(t, 18, storage.FlashReservedBlockCount)
+	assert.Empty(t, radio.GetAlerts())
+
+	// Subsequent polls shouldn't check again until the interval has elapsed.
+	fakeShell.reset()
+	for i := 1; i < storageCheckIntervalPolls; i++ {
+		radio.updateStorageHealth()
+	}
+	assert.Empty(t, fakeShell.commandsRun)
+
+	// Low free space and an increase in bad blocks should each raise an alert.
+	fakeShell.commandOutput[dfCommand] = "Filesystem           1K-blocks      Used Available Use% Mounted on\n" +
+		"/dev/ubi0_5              54124     50000      4000  93% /overlay\n"
+	writeUbiAttributes(t, ubiSysfsDirectory, "150", "60", "3", "17")
+	radio.updateStorageHealth()
+	assert.True(t, radio.Storage.IsOverlayLow)
+	assert.Equal(t, 3, radio.Storage.FlashBadBlockCount)
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 2, len(alerts)) {
+		assert.Equal(t, "STORAGE_LOW", alerts[0].Type)
+		assert.Equal(t, "Only 7.3% (4000 KB) of the overlay filesystem is free.", alerts[0].Message)
+		assert.Equal(t, "FLASH_WEAR", alerts[1].Type)
+		assert.Equal(t, "Flash bad block count increased from 2 to 3; 17 spare blocks remain.", alerts[1].Message)
+	}
+
+	// A condition that persists shouldn't raise repeated alerts.
+	for i := 0; i < storageCheckIntervalPolls; i++ {
+		radio.updateStorageHealth()
+	}
+	assert.Equal(t, 2, len(radio.GetAlerts()))
+
+	// Missing flash wear statistics and a failing df command should be tolerated.
+	assert.Nil(t, os.Remove(filepath.Join(ubiSysfsDirectory, "max_ec")))
+	delete(fakeShell.commandOutput, dfCommand)
+	fakeShell.commandErrors[dfCommand] = errors.New("oops")
+	for i := 0; i < storageCheckIntervalPolls; i++ {
+		radio.updateStorageHealth()
+	}
+	assert.False(t, radio.Storage.IsFlashWearAvailable)
+	assert.Equal(t, int64(4000*1024), radio.Storage.OverlayFreeBytes)
+}
+
+func TestParseDf(t *testing.T) {
+	totalBytes, freeBytes, err := parseDf(
+		"Filesystem           1K-blocks      Used Available Use% Mounted on\n" +
+			"overlayfs:/overlay       54124      1236     50132   2% /overlay\n",
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(54124*1024), totalBytes)
+	assert.Equal(t, int64(50132*1024), freeBytes)
+
+	_, _, err = parseDf("Filesystem           1K-blocks      Used Available Use% Mounted on\n")
+	assert.EqualError(t, err, "missing filesystem line")
+
+	_, _, err = parseDf("Filesystem 1K-blocks\n/dev/ubi0_5 54124\n")
+	assert.EqualError(t, err, "unexpected filesystem line: /dev/ubi0_5 54124")
+}
+
+func TestRadio_commitUci(t *testing.T) {
+	uciTree = newFakeUciTree()
+	radio := Radio{}
+	assert.Nil(t, radio.commitUci("wireless"))
+	assert.Nil(t, radio.commitUci("system"))
+	assert.Equal(t, 2, radio.Storage.UciCommitCount)
+}
@@ -0,0 +1,141 @@
+package radio
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+)
+
+const (
+	// Name of the current API log file within the state directory.
+	LogFileName = "frc-radio-api.log"
+
+	// Name of the previous API log file within the state directory, which is rotated out when the current one gets too
+	// big.
+	OldLogFileName = "frc-radio-api.log.old"
+)
+
+// Matches UCI options holding secrets in the output of 'uci show', capturing the option so that the value can be
+// redacted.
+var uciSecretRe = regexp.MustCompile(
+	`(?m)^(\S+\.(?:key|sae_password|password|auth_secret|acct_secret|priv_key\w*))=.*$`,
+)
+
+// SupportFile represents a single diagnostic file collected for inclusion in a support bundle.
+type SupportFile struct {
+	// Name of the file within the bundle.
+	Name string
+
+	// Contents of the file.
+	Data []byte
+}
+
+// supportBundleVersionInfo represents the identifying information about the radio included in a support bundle.
+type supportBundleVersionInfo struct {
+	Version      string    `json:"version"`
+	HardwareType string    `json:"hardwareType,omitempty"`
+	CollectedAt  Timestamp `json:"collectedAt"`
+}
+
+// CollectSupportFiles gathers the API logs, system logs, redacted configuration, and other diagnostic information
+// needed to troubleshoot the radio. Sources that can't be read are noted in an errors file rather than failing the
+// collection.
+func (radio *Radio) CollectSupportFiles() []SupportFile {
+	var files []SupportFile
+	var errors []string
+	addJson := func(name string, value any) {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", name, err))
+			return
+		}
+		files = append(files, SupportFile{Name: name, Data: data})
+	}
+	addCommand := func(name string, transform func(string) string, command string, args ...string) {
+		output, err := shell.runCommand(command, args...)
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("%s: %v", name, err))
+			return
+		}
+		if transform != nil {
+			output = transform(output)
+		}
+		files = append(files, SupportFile{Name: name, Data: []byte(output)})
+	}
+
+	addJson(
+		"version.json",
+		supportBundleVersionInfo{
+			Version:      radio.Version,
+			HardwareType: radio.hardwareTypeName(),
+			CollectedAt:  newTimestamp(),
+		},
+	)
+	settings := radio.GetSettings()
+	addJson("settings.json", settings.redacted())
+	addJson("alerts.json", radio.GetAlerts())
+	addJson("shell-telemetry.json", GetShellTelemetry())
+	for name, value := range radio.personalitySupportData() {
+		addJson(name, value)
+	}
+
+	for _, fileName := range []string{OldLogFileName, LogFileName} {
+		data, err := os.ReadFile(settings.StateFilePath(fileName))
+		if err != nil {
+			if !os.IsNotExist(err) {
+				errors = append(errors, fmt.Sprintf("logs/%s: %v", fileName, err))
+			}
+			continue
+		}
+		files = append(files, SupportFile{Name: "logs/" + fileName, Data: data})
+	}
+	addCommand("logs/dmesg.txt", nil, "dmesg")
+	addCommand("logs/hostapd.txt", nil, "logread", "-e", "hostapd")
+	for _, config := range []string{"wireless", "network", "system"} {
+		addCommand("uci/"+config+".txt", redactUciSecrets, "uci", "show", config)
+	}
+
+	if len(errors) > 0 {
+		errorLines := ""
+		for _, line := range errors {
+			errorLines += line + "\n"
+		}
+		files = append(files, SupportFile{Name: "errors.txt", Data: []byte(errorLines)})
+	}
+	return files
+}
+
+// redactUciSecrets replaces the values of any secret options in the given 'uci show' output.
+func redactUciSecrets(output string) string {
+	return uciSecretRe.ReplaceAllString(output, "$1='REDACTED'")
+}
+
+// redacted returns a copy of the settings with any credentials removed.
+func (settings Settings) redacted() Settings {
+	redacted := settings
+	redacted.FleetMembers = make([]FleetMember, len(settings.FleetMembers))
+	for i, member := range settings.FleetMembers {
+		if member.Token != "" {
+			member.Token = "REDACTED"
+		}
+		redacted.FleetMembers[i] = member
+	}
+	if redacted.Standby.StandbyToken != "" {
+		redacted.Standby.StandbyToken = "REDACTED"
+	}
+	if redacted.AlertWebhookUrl != "" {
+		if parsedUrl, err := url.Parse(redacted.AlertWebhookUrl); err == nil && parsedUrl.User != nil {
+			parsedUrl.User = url.User("REDACTED")
+			redacted.AlertWebhookUrl = parsedUrl.String()
+		}
+	}
+	if redacted.AlertForwarding.Smtp.Password != "" {
+		redacted.AlertForwarding.Smtp.Password = "REDACTED"
+	}
+	if redacted.AlertForwarding.SmsGateway.Token != "" {
+		redacted.AlertForwarding.SmsGateway.Token = "REDACTED"
+	}
+	return redacted
+}
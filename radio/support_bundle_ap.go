@@ -0,0 +1,17 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+// hardwareTypeName returns the name of the radio's hardware type for inclusion in a support bundle.
+func (radio *Radio) hardwareTypeName() string {
+	return radio.Type.String()
+}
+
+// personalitySupportData returns the access point-specific state to include in a support bundle, keyed by file name.
+func (radio *Radio) personalitySupportData() map[string]any {
+	return map[string]any{
+		"channel-report.json": radio.GetChannelReport(),
+		"rogue-networks.json": radio.GetRogueNetworks(),
+	}
+}
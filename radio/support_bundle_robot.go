@@ -0,0 +1,15 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package radio
+
+// hardwareTypeName returns the name of the radio's hardware type for inclusion in a support bundle; the robot radio
+// doesn't distinguish between hardware types.
+func (radio *Radio) hardwareTypeName() string {
+	return ""
+}
+
+// personalitySupportData returns the robot radio-specific state to include in a support bundle, keyed by file name.
+func (radio *Radio) personalitySupportData() map[string]any {
+	return map[string]any{}
+}
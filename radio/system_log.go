@@ -0,0 +1,59 @@
+package radio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// Maximum number of system log streams that can be followed at once, each of which keeps a logread process running.
+const maxSystemLogFollowers = 4
+
+// ErrTooManySystemLogFollowers is returned when a system log stream can't be followed because the maximum number of
+// followed streams are already running.
+var ErrTooManySystemLogFollowers = errors.New("too many system log streams are already being followed")
+
+// Map of system log sources that can be streamed to the pattern used to filter the system log for them.
+var systemLogSourcePatterns = map[string]string{
+	"hostapd": "hostapd",
+	"kernel":  "kernel:",
+}
+
+// Number of system log streams currently being followed.
+var systemLogFollowerCount atomic.Int32
+
+// ValidateSystemLogSource returns an error if the given source (e.g. "hostapd" or "kernel") can't be streamed.
+func ValidateSystemLogSource(source string) error {
+	if _, ok := systemLogSourcePatterns[source]; !ok {
+		return fmt.Errorf("invalid log source: %s (expecting hostapd or kernel)", source)
+	}
+	return nil
+}
+
+// StreamSystemLog writes the system log entries from the given source (e.g. "hostapd" or "kernel") to the given
+// writer. If follow is true, it keeps streaming new entries as they are logged until the context is cancelled, unless
+// too many streams are already being followed, in which case ErrTooManySystemLogFollowers is returned before anything
+// is written.
+func StreamSystemLog(ctx context.Context, source string, follow bool, output io.Writer) error {
+	if err := ValidateSystemLogSource(source); err != nil {
+		return err
+	}
+
+	args := []string{"-e", systemLogSourcePatterns[source]}
+	if follow {
+		if systemLogFollowerCount.Add(1) > maxSystemLogFollowers {
+			systemLogFollowerCount.Add(-1)
+			return ErrTooManySystemLogFollowers
+		}
+		defer systemLogFollowerCount.Add(-1)
+		args = append(args, "-f")
+	}
+	err := shell.streamCommand(ctx, output, "logread", args...)
+	if ctx.Err() != nil {
+		// The caller stopped the stream; this isn't an error.
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package radio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStreamSystemLog(t *testing.T) {
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["logread -e hostapd"] = "hostapd: wlan0: STA 48:da:35:b0:01:cf associated\n"
+	fakeShell.commandOutput["logread -e kernel: -f"] = "kernel: [123.456] ath: phy0: oops\n"
+	fakeShell.commandErrors["logread -e kernel:"] = errors.New("oops")
+
+	var output bytes.Buffer
+	assert.Nil(t, StreamSystemLog(context.Background(), "hostapd", false, &output))
+	assert.Equal(t, "hostapd: wlan0: STA 48:da:35:b0:01:cf associated\n", output.String())
+
+	output.Reset()
+	assert.Nil(t, StreamSystemLog(context.Background(), "kernel", true, &output))
+	assert.Equal(t, "kernel: [123.456] ath: phy0: oops\n", output.String())
+
+	assert.EqualError(t, StreamSystemLog(context.Background(), "kernel", false, &output), "oops")
+
+	// Errors after the stream has been cancelled are ignored.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Nil(t, StreamSystemLog(ctx, "kernel", false, &output))
+
+	assert.EqualError(
+		t,
+		StreamSystemLog(context.Background(), "dnsmasq", false, &output),
+		"invalid log source: dnsmasq (expecting hostapd or kernel)",
+	)
+	assert.Equal(t, 3, len(fakeShell.commandsRun))
+}
+
+func TestStreamSystemLogFollowerLimit(t *testing.T) {
+	shell = blockingShell{newFakeShell(t)}
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for i := 0; i < maxSystemLogFollowers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, StreamSystemLog(ctx, "hostapd", true, io.Discard))
+		}()
+	}
+	assert.Eventually(
+		t,
+		func() bool { return systemLogFollowerCount.Load() == maxSystemLogFollowers },
+		time.Second,
+		time.Millisecond,
+	)
+
+	// Further followed streams are refused, but reading the log without following it still works.
+	var output bytes.Buffer
+	assert.Equal(t, ErrTooManySystemLogFollowers, StreamSystemLog(context.Background(), "kernel", true, &output))
+	assert.Equal(t, 0, output.Len())
+	stoppedCtx, stop := context.WithCancel(context.Background())
+	stop()
+	assert.Nil(t, StreamSystemLog(stoppedCtx, "kernel", false, &output))
+	assert.Equal(t, int32(maxSystemLogFollowers), systemLogFollowerCount.Load())
+
+	// The count drops back down once the existing streams stop.
+	cancel()
+	wg.Wait()
+	assert.Equal(t, int32(0), systemLogFollowerCount.Load())
+}
@@ -0,0 +1,99 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"strconv"
+)
+
+// TeamStatus represents the subset of the status of a single team station that is safe to show to the team itself, so
+// that it can diagnose the quality of its link without seeing the other teams' telemetry or any network credentials.
+type TeamStatus struct {
+	// Team number whose network the status is for.
+	TeamNumber int `json:"teamNumber"`
+
+	// Team station that the team is assigned to (e.g. "red1").
+	Station string `json:"station"`
+
+	// Channel that the access point is currently broadcasting on.
+	Channel int `json:"channel"`
+
+	// Whether the team's network is currently associated with a robot radio.
+	IsLinked bool `json:"isLinked"`
+
+	// MAC address of the robot radio currently associated with the network. Blank if not associated.
+	MacAddress string `json:"macAddress"`
+
+	// Signal strength and noise level of the link, in decibel-milliwatts, and the resulting signal-to-noise ratio in
+	// decibels. Zero if not associated.
+	SignalDbm        int `json:"signalDbm"`
+	NoiseDbm         int `json:"noiseDbm"`
+	SignalNoiseRatio int `json:"signalNoiseRatio"`
+
+	// Upper-bound link rates to and from the robot radio, in megabits per second. Zero if not associated.
+	RxRateMbps float64 `json:"rxRateMbps"`
+	TxRateMbps float64 `json:"txRateMbps"`
+
+	// Current five-second average total (rx + tx) bandwidth in megabits per second.
+	BandwidthUsedMbps float64 `json:"bandwidthUsedMbps"`
+
+	// Human-readable string describing connection quality to the robot radio. Blank if not associated.
+	ConnectionQuality string `json:"connectionQuality"`
+
+	// Overall connection quality from 0 (unusable) to 100 (ideal). Zero if not associated.
+	QualityScore int `json:"qualityScore"`
+
+	// Retried transmissions as a percentage of packets transmitted since the rates were last assessed.
+	TxRetryRatePercent float64 `json:"txRetryRatePercent"`
+
+	// Number of times the link has dropped in the last ten minutes.
+	RecentLinkDropCount int `json:"recentLinkDropCount"`
+
+	// Total time a robot radio has been associated since the team was configured on the station, in seconds.
+	AssociatedTimeSec int `json:"associatedTimeSec"`
+
+	// Number of times the associated device has disconnected or been replaced by another since the team was configured
+	// on the station.
+	DisconnectCount int `json:"disconnectCount"`
+
+	// Number of failed authentication or key handshake attempts (e.g. due to a wrong WPA key) since the network was
+	// configured.
+	HandshakeFailureCount int `json:"handshakeFailureCount"`
+}
+
+// GetTeamStatus returns the status of the network of the given team, or false if the team isn't configured on any
+// station.
+func (radio *Radio) GetTeamStatus(teamNumber int) (TeamStatus, bool) {
+	unlock := radio.lockStatus()
+	defer unlock()
+
+	ssid := strconv.Itoa(teamNumber)
+	for station := red1; station <= blue3; station++ {
+		status := radio.StationStatuses[station.String()]
+		if status == nil || status.Ssid != ssid {
+			continue
+		}
+		return TeamStatus{
+			TeamNumber:            teamNumber,
+			Station:               station.String(),
+			Channel:               radio.Channel,
+			IsLinked:              status.IsLinked,
+			MacAddress:            status.MacAddress,
+			SignalDbm:             status.SignalDbm,
+			NoiseDbm:              status.NoiseDbm,
+			SignalNoiseRatio:      status.SignalNoiseRatio,
+			RxRateMbps:            status.RxRateMbps,
+			TxRateMbps:            status.TxRateMbps,
+			BandwidthUsedMbps:     status.BandwidthUsedMbps,
+			ConnectionQuality:     status.ConnectionQuality,
+			QualityScore:          status.QualityScore,
+			TxRetryRatePercent:    status.TxRetryRatePercent,
+			RecentLinkDropCount:   status.RecentLinkDropCount,
+			AssociatedTimeSec:     status.AssociatedTimeSec,
+			DisconnectCount:       status.DisconnectCount,
+			HandshakeFailureCount: status.HandshakeFailureCount,
+		}, true
+	}
+	return TeamStatus{}, false
+}
@@ -0,0 +1,58 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRadio_GetTeamStatus(t *testing.T) {
+	radio := &Radio{Channel: 149, StationStatuses: make(map[string]*NetworkStatus)}
+	radio.StationStatuses["red1"] = &NetworkStatus{Ssid: "254", HashedWpaKey: "abc", WpaKeySalt: "def"}
+	radio.StationStatuses["blue2"] = &NetworkStatus{
+		Ssid:                "1114",
+		HashedWpaKey:        "123",
+		WpaKeySalt:          "456",
+		IsLinked:            true,
+		MacAddress:          "48:DA:35:B0:00:CF",
+		SignalDbm:           -53,
+		NoiseDbm:            -95,
+		SignalNoiseRatio:    42,
+		RxRateMbps:          550.6,
+		TxRateMbps:          254,
+		QualityScore:        92,
+		RecentLinkDropCount: 1,
+		GhostMacAddresses:   []string{"48:DA:35:B0:00:D0"},
+	}
+
+	status, ok := radio.GetTeamStatus(1114)
+	assert.True(t, ok)
+	assert.Equal(
+		t,
+		TeamStatus{
+			TeamNumber:          1114,
+			Station:             "blue2",
+			Channel:             149,
+			IsLinked:            true,
+			MacAddress:          "48:DA:35:B0:00:CF",
+			SignalDbm:           -53,
+			NoiseDbm:            -95,
+			SignalNoiseRatio:    42,
+			RxRateMbps:          550.6,
+			TxRateMbps:          254,
+			QualityScore:        92,
+			RecentLinkDropCount: 1,
+		},
+		status,
+	)
+
+	status, ok = radio.GetTeamStatus(254)
+	assert.True(t, ok)
+	assert.Equal(t, "red1", status.Station)
+	assert.False(t, status.IsLinked)
+
+	_, ok = radio.GetTeamStatus(1503)
+	assert.False(t, ok)
+}
@@ -0,0 +1,394 @@
+package radio
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// How long to wait for a telemetry sink to accept a sample before giving up on it.
+	telemetrySinkTimeout = 2 * time.Second
+
+	// Maximum number of monitoring samples waiting to be pushed to the telemetry sinks; further samples are dropped
+	// until the backlog clears.
+	maxQueuedTelemetrySamples = 10
+
+	// Name of the measurement under which the link telemetry of each network is pushed.
+	telemetryMeasurement = "frc_radio_network"
+
+	// Name of the measurement under which the configuration statistics of the radio are pushed.
+	configurationStatsMeasurement = "frc_radio_configuration"
+)
+
+// HTTP client used to push samples to InfluxDB over HTTP.
+var telemetryHttpClient = &http.Client{Timeout: telemetrySinkTimeout}
+
+// telemetryProtocol represents the wire format used to push samples to a telemetry sink.
+type telemetryProtocol string
+
+const (
+	// Gauges in the statsd format with DogStatsD-style tags, sent over UDP.
+	telemetryProtocolStatsd telemetryProtocol = "STATSD"
+
+	// InfluxDB line protocol, sent over UDP.
+	telemetryProtocolInfluxUdp telemetryProtocol = "INFLUX_UDP"
+
+	// InfluxDB line protocol, POSTed to an HTTP write endpoint.
+	telemetryProtocolInfluxHttp telemetryProtocol = "INFLUX_HTTP"
+)
+
+// TelemetrySink represents a collector in the venue's telemetry pipeline to which the link telemetry of each network
+// is pushed after every monitoring poll.
+type TelemetrySink struct {
+	// Wire format of the samples: "STATSD", "INFLUX_UDP", or "INFLUX_HTTP".
+	Protocol telemetryProtocol `json:"protocol"`
+
+	// Host and port of the collector for the UDP protocols (e.g. "10.0.100.6:8125"), or the full URL of the write
+	// endpoint for InfluxDB over HTTP (e.g. "http://10.0.100.6:8086/api/v2/write?org=frc&bucket=radios").
+	Address string `json:"address"`
+
+	// Token to authorize writes to InfluxDB over HTTP, if it requires one.
+	Token string `json:"token"`
+
+	// Additional tags to attach to every sample, which override the default event, field, and radio tags.
+	Tags map[string]string `json:"tags"`
+}
+
+// telemetryPublisher pushes monitoring samples to the telemetry sinks from a single worker goroutine, so that a slow
+// collector can't hold up the radio.
+type telemetryPublisher struct {
+	// Queue of samples awaiting delivery by the worker goroutine, which is started on first use.
+	queue     chan telemetryDelivery
+	queueOnce sync.Once
+
+	// Addresses of the sinks to which the most recent sample couldn't be delivered, so that an unreachable collector
+	// is only logged once. Only accessed by the worker goroutine.
+	failingSinks map[string]bool
+}
+
+// telemetryDelivery represents a monitoring sample to be pushed to the sinks that were configured when it was taken.
+type telemetryDelivery struct {
+	sinks  []TelemetrySink
+	tags   map[string]string
+	sample MonitoringSample
+
+	// Configuration statistics of the radio's current hardware type and firmware version, or nil if there are none.
+	configurationStats *ConfigurationStats
+}
+
+// telemetryMetric represents a single named value within the telemetry of a network.
+type telemetryMetric struct {
+	name  string
+	value any
+}
+
+// validate checks that the telemetry sink at the given index of the settings has a known protocol and a usable
+// address.
+func (sink TelemetrySink) validate(index int) error {
+	switch sink.Protocol {
+	case telemetryProtocolStatsd, telemetryProtocolInfluxUdp:
+		if _, _, err := net.SplitHostPort(sink.Address); err != nil {
+			return fmt.Errorf("invalid telemetrySinks[%d].address: %q (expecting host:port)", index, sink.Address)
+		}
+	case telemetryProtocolInfluxHttp:
+		if !isValidHttpUrl(sink.Address) {
+			return fmt.Errorf("invalid telemetrySinks[%d].address: %q (expecting an HTTP URL)", index, sink.Address)
+		}
+	default:
+		return fmt.Errorf(
+			"invalid telemetrySinks[%d].protocol: %q (expecting STATSD, INFLUX_UDP, or INFLUX_HTTP)",
+			index,
+			sink.Protocol,
+		)
+	}
+	for key, value := range sink.Tags {
+		if key == "" || value == "" {
+			return fmt.Errorf(
+				"invalid telemetrySinks[%d].tags: %q=%q (expecting a non-blank key and value)", index, key, value,
+			)
+		}
+	}
+	return nil
+}
+
+// pushTelemetry hands the given monitoring sample to the telemetry worker for delivery to each configured sink.
+func (radio *Radio) pushTelemetry(sample MonitoringSample) {
+	sinks := radio.GetSettings().TelemetrySinks
+	if len(sinks) == 0 {
+		return
+	}
+	radio.telemetry.queueDelivery(
+		telemetryDelivery{
+			sinks:              sinks,
+			tags:               radio.defaultTelemetryTags(),
+			sample:             sample,
+			configurationStats: radio.currentConfigurationStats(),
+		},
+	)
+}
+
+// defaultTelemetryTags returns the tags identifying the event, field, and radio that every sample is attached to,
+// leaving out any that aren't known.
+func (radio *Radio) defaultTelemetryTags() map[string]string {
+	tags := make(map[string]string)
+	variables := radio.GetEventVariables()
+	if variables.EventCode != "" {
+		tags["event"] = variables.EventCode
+	}
+	if variables.FieldNumber > 0 {
+		tags["field"] = strconv.Itoa(variables.FieldNumber)
+	}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		tags["radio"] = hostname
+	}
+	return tags
+}
+
+// queueDelivery hands the given sample to the worker goroutine, dropping it if the worker has fallen too far behind.
+func (publisher *telemetryPublisher) queueDelivery(delivery telemetryDelivery) {
+	publisher.queueOnce.Do(func() {
+		publisher.queue = make(chan telemetryDelivery, maxQueuedTelemetrySamples)
+		publisher.failingSinks = make(map[string]bool)
+		go func() {
+			for delivery := range publisher.queue {
+				publisher.deliver(delivery)
+			}
+		}()
+	})
+	select {
+	case publisher.queue <- delivery:
+	default:
+		log.Println("Dropping monitoring sample for telemetry sinks since too many are already queued")
+	}
+}
+
+// deliver pushes the given sample to each of its sinks, logging the first of any consecutive failures of a sink.
+func (publisher *telemetryPublisher) deliver(delivery telemetryDelivery) {
+	for _, sink := range delivery.sinks {
+		err := sink.push(delivery.sample, delivery.configurationStats, mergeTelemetryTags(delivery.tags, sink.Tags))
+		if err != nil && !publisher.failingSinks[sink.Address] {
+			log.Printf(
+				"Error pushing telemetry to %s; dropping samples until it accepts them again: %v", sink.Address, err,
+			)
+		} else if err == nil && publisher.failingSinks[sink.Address] {
+			log.Printf("Pushing telemetry to %s again.", sink.Address)
+		}
+		publisher.failingSinks[sink.Address] = err != nil
+	}
+}
+
+// push sends the given sample and configuration statistics, if any, to the sink with the given tags attached to each
+// network.
+func (sink TelemetrySink) push(
+	sample MonitoringSample, configurationStats *ConfigurationStats, tags map[string]string,
+) error {
+	var lines []string
+	formatLine := func(measurement string, tags map[string]string, metrics []telemetryMetric) string {
+		if sink.Protocol == telemetryProtocolStatsd {
+			return formatStatsdLines(measurement, tags, metrics)
+		}
+		return formatInfluxLine(measurement, tags, metrics, sample.MonitoredAt)
+	}
+	for _, name := range sortedNetworkNames(sample) {
+		networkTags := networkTelemetryTags(tags, name, sample.Networks[name])
+		lines = append(
+			lines, formatLine(telemetryMeasurement, networkTags, networkTelemetryMetrics(sample.Networks[name])),
+		)
+	}
+	if configurationStats != nil {
+		statsTags := mergeTelemetryTags(
+			tags,
+			map[string]string{
+				"hardware_type":    configurationStats.HardwareType,
+				"firmware_version": configurationStats.FirmwareVersion,
+			},
+		)
+		lines = append(
+			lines,
+			formatLine(configurationStatsMeasurement, statsTags, configurationStatsMetrics(*configurationStats)),
+		)
+	}
+
+	if sink.Protocol == telemetryProtocolInfluxHttp {
+		return sink.postLines(lines)
+	}
+	connection, err := net.DialTimeout("udp", sink.Address, telemetrySinkTimeout)
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+	if err = connection.SetWriteDeadline(time.Now().Add(telemetrySinkTimeout)); err != nil {
+		return err
+	}
+	// Each network goes in its own datagram to keep them within a typical MTU.
+	for _, line := range lines {
+		if _, err = connection.Write([]byte(line + "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// postLines writes the given InfluxDB lines to the sink's HTTP write endpoint in a single request.
+func (sink TelemetrySink) postLines(lines []string) error {
+	request, err := http.NewRequest(http.MethodPost, sink.Address, strings.NewReader(strings.Join(lines, "\n")+"\n"))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if sink.Token != "" {
+		request.Header.Set("Authorization", "Token "+sink.Token)
+	}
+	response, err := telemetryHttpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("write endpoint returned status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// mergeTelemetryTags returns the union of the given default and sink-specific tags, with the latter taking precedence.
+func mergeTelemetryTags(defaultTags, sinkTags map[string]string) map[string]string {
+	tags := make(map[string]string, len(defaultTags)+len(sinkTags))
+	for key, value := range defaultTags {
+		tags[key] = value
+	}
+	for key, value := range sinkTags {
+		tags[key] = value
+	}
+	return tags
+}
+
+// sortedNetworkNames returns the names of the networks in the given sample in a stable order.
+func sortedNetworkNames(sample MonitoringSample) []string {
+	names := make([]string, 0, len(sample.Networks))
+	for name := range sample.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// networkTelemetryTags returns the given tags along with those identifying the given network and its team's SSID.
+func networkTelemetryTags(tags map[string]string, name string, network NetworkSample) map[string]string {
+	networkTags := mergeTelemetryTags(tags, map[string]string{"network": name})
+	if network.Ssid != "" {
+		networkTags["ssid"] = network.Ssid
+	}
+	return networkTags
+}
+
+// networkTelemetryMetrics returns the values pushed for the given network, in a stable order.
+func networkTelemetryMetrics(network NetworkSample) []telemetryMetric {
+	return []telemetryMetric{
+		{"is_linked", network.IsLinked},
+		{"signal_dbm", network.SignalDbm},
+		{"noise_dbm", network.NoiseDbm},
+		{"signal_noise_ratio", network.SignalNoiseRatio},
+		{"rx_rate_mbps", network.RxRateMbps},
+		{"tx_rate_mbps", network.TxRateMbps},
+		{"bandwidth_used_mbps", network.BandwidthUsedMbps},
+		{"tx_retry_rate_percent", network.TxRetryRatePercent},
+		{"quality_score", network.QualityScore},
+	}
+}
+
+// configurationStatsMetrics returns the values pushed for the given configuration statistics, in a stable order.
+func configurationStatsMetrics(stats ConfigurationStats) []telemetryMetric {
+	return []telemetryMetric{
+		{"attempt_count", stats.AttemptCount},
+		{"success_count", stats.SuccessCount},
+		{"failure_count", stats.FailureCount},
+		{"success_rate_percent", stats.SuccessRatePercent},
+		{"retry_count", stats.RetryCount},
+		{"verification_failure_count", stats.VerificationFailureCount},
+		{"mitigation_count", stats.MitigationCount},
+	}
+}
+
+// formatInfluxLine renders the given metrics under the given measurement as a single line of the InfluxDB line
+// protocol.
+func formatInfluxLine(
+	measurement string, tags map[string]string, metrics []telemetryMetric, monitoredAt Timestamp,
+) string {
+	var line strings.Builder
+	line.WriteString(measurement)
+	for _, key := range sortedKeys(tags) {
+		fmt.Fprintf(&line, ",%s=%s", escapeInfluxTag(key), escapeInfluxTag(tags[key]))
+	}
+	for i, metric := range metrics {
+		separator := ","
+		if i == 0 {
+			separator = " "
+		}
+		switch value := metric.value.(type) {
+		case int:
+			fmt.Fprintf(&line, "%s%s=%di", separator, metric.name, value)
+		default:
+			fmt.Fprintf(&line, "%s%s=%v", separator, metric.name, value)
+		}
+	}
+	fmt.Fprintf(&line, " %d", monitoredAt.Wallclock.UnixNano())
+	return line.String()
+}
+
+// formatStatsdLines renders the given metrics under the given measurement as newline-separated statsd gauges.
+func formatStatsdLines(measurement string, tags map[string]string, metrics []telemetryMetric) string {
+	tagList := make([]string, 0, len(tags))
+	for _, key := range sortedKeys(tags) {
+		tagList = append(tagList, escapeStatsdTag(key)+":"+escapeStatsdTag(tags[key]))
+	}
+	suffix := "|g|#" + strings.Join(tagList, ",")
+
+	lines := make([]string, 0, len(metrics))
+	for _, metric := range metrics {
+		name := measurement + "." + metric.name
+		var value string
+		switch typedValue := metric.value.(type) {
+		case bool:
+			value = "0"
+			if typedValue {
+				value = "1"
+			}
+		default:
+			value = fmt.Sprint(typedValue)
+		}
+		if strings.HasPrefix(value, "-") {
+			// A signed gauge value is taken as a change to the current value, so the gauge must be zeroed first.
+			lines = append(lines, name+":0"+suffix)
+		}
+		lines = append(lines, name+":"+value+suffix)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sortedKeys returns the keys of the given tags in a stable order.
+func sortedKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// escapeInfluxTag escapes the characters that delimit tags in the InfluxDB line protocol.
+func escapeInfluxTag(value string) string {
+	return strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `).Replace(value)
+}
+
+// escapeStatsdTag replaces the characters that delimit tags in the statsd format, which can't be escaped.
+func escapeStatsdTag(value string) string {
+	return strings.NewReplacer("|", "_", ",", "_", "#", "_", ":", "_").Replace(value)
+}
@@ -0,0 +1,164 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTelemetrySample returns a monitoring sample with one linked and one idle network.
+func newTelemetrySample() MonitoringSample {
+	return MonitoringSample{
+		MonitoredAt: Timestamp{Wallclock: time.Unix(1709403342, 5)},
+		Networks: map[string]NetworkSample{
+			"red1": {
+				Ssid:               "254",
+				IsLinked:           true,
+				SignalDbm:          -53,
+				NoiseDbm:           -95,
+				SignalNoiseRatio:   42,
+				RxRateMbps:         864.8,
+				TxRateMbps:         432.4,
+				BandwidthUsedMbps:  3.5,
+				TxRetryRatePercent: 1.25,
+				QualityScore:       91,
+			},
+			"blue2": {},
+		},
+	}
+}
+
+func TestFormatInfluxLine(t *testing.T) {
+	sample := newTelemetrySample()
+	tags := networkTelemetryTags(
+		map[string]string{"event": "2024CASJ", "radio": "field ap"}, "red1", sample.Networks["red1"],
+	)
+	assert.Equal(
+		t,
+		`frc_radio_network,event=2024CASJ,network=red1,radio=field\ ap,ssid=254 is_linked=true,signal_dbm=-53i,`+
+			`noise_dbm=-95i,signal_noise_ratio=42i,rx_rate_mbps=864.8,tx_rate_mbps=432.4,bandwidth_used_mbps=3.5,`+
+			`tx_retry_rate_percent=1.25,quality_score=91i 1709403342000000005`,
+		formatInfluxLine(
+			telemetryMeasurement, tags, networkTelemetryMetrics(sample.Networks["red1"]), sample.MonitoredAt,
+		),
+	)
+}
+
+func TestFormatStatsdLines(t *testing.T) {
+	tags := networkTelemetryTags(map[string]string{"field": "1", "venue": "San Jose|CA"}, "blue2", NetworkSample{})
+	metrics := []telemetryMetric{{"is_linked", false}, {"signal_dbm", -53}, {"rx_rate_mbps", 864.8}}
+	assert.Equal(
+		t,
+		"frc_radio_network.is_linked:0|g|#field:1,network:blue2,venue:San Jose_CA\n"+
+			"frc_radio_network.signal_dbm:0|g|#field:1,network:blue2,venue:San Jose_CA\n"+
+			"frc_radio_network.signal_dbm:-53|g|#field:1,network:blue2,venue:San Jose_CA\n"+
+			"frc_radio_network.rx_rate_mbps:864.8|g|#field:1,network:blue2,venue:San Jose_CA",
+		formatStatsdLines(telemetryMeasurement, tags, metrics),
+	)
+}
+
+func TestRadio_pushTelemetry(t *testing.T) {
+	statsdListener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer statsdListener.Close()
+	influxRequests := make(chan *http.Request, 1)
+	influxBodies := make(chan string, 1)
+	influxServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		influxRequests <- r
+		influxBodies <- string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer influxServer.Close()
+
+	radio := &Radio{settings: defaultSettings()}
+	radio.settings.EventVariables = EventVariables{EventCode: "2024CASJ", FieldNumber: 1}
+	radio.settings.TelemetrySinks = []TelemetrySink{
+		{Protocol: telemetryProtocolStatsd, Address: statsdListener.LocalAddr().String()},
+		{
+			Protocol: telemetryProtocolInfluxHttp,
+			Address:  influxServer.URL + "/api/v2/write?bucket=radios",
+			Token:    "secret",
+			Tags:     map[string]string{"radio": "field-ap"},
+		},
+	}
+	radio.pushTelemetry(newTelemetrySample())
+
+	// Each network is sent to the statsd sink in its own datagram, in a stable order.
+	buffer := make([]byte, 65536)
+	_ = statsdListener.SetReadDeadline(time.Now().Add(time.Second))
+	length, _, err := statsdListener.ReadFrom(buffer)
+	assert.Nil(t, err)
+	assert.Contains(
+		t, string(buffer[:length]), "frc_radio_network.quality_score:0|g|#event:2024CASJ,field:1,network:blue2",
+	)
+	length, _, err = statsdListener.ReadFrom(buffer)
+	assert.Nil(t, err)
+	assert.Contains(
+		t, string(buffer[:length]), "frc_radio_network.quality_score:91|g|#event:2024CASJ,field:1,network:red1",
+	)
+
+	select {
+	case request := <-influxRequests:
+		assert.Equal(t, "/api/v2/write", request.URL.Path)
+		assert.Equal(t, "radios", request.URL.Query().Get("bucket"))
+		assert.Equal(t, "Token secret", request.Header.Get("Authorization"))
+		body := <-influxBodies
+		assert.Contains(
+			t, body, "frc_radio_network,event=2024CASJ,field=1,network=blue2,radio=field-ap is_linked=false",
+		)
+		assert.Contains(t, body, "frc_radio_network,event=2024CASJ,field=1,network=red1,radio=field-ap,ssid=254 ")
+	case <-time.After(time.Second):
+		assert.Fail(t, "InfluxDB sink didn't receive the sample")
+	}
+}
+
+func TestTelemetryPublisher_deliverTracksFailingSinks(t *testing.T) {
+	influxServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer influxServer.Close()
+
+	publisher := telemetryPublisher{failingSinks: make(map[string]bool)}
+	sink := TelemetrySink{Protocol: telemetryProtocolInfluxHttp, Address: influxServer.URL}
+	publisher.deliver(telemetryDelivery{sinks: []TelemetrySink{sink}, sample: newTelemetrySample()})
+	assert.True(t, publisher.failingSinks[influxServer.URL])
+	assert.EqualError(t, sink.push(newTelemetrySample(), nil, nil), "write endpoint returned status 401")
+}
+
+func TestTelemetrySink_pushConfigurationStats(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer listener.Close()
+	sink := TelemetrySink{Protocol: telemetryProtocolInfluxUdp, Address: listener.LocalAddr().String()}
+	stats := ConfigurationStats{
+		HardwareType:       "TypeLinksys",
+		FirmwareVersion:    "1.2.3",
+		AttemptCount:       4,
+		SuccessCount:       3,
+		FailureCount:       1,
+		SuccessRatePercent: 75,
+		RetryCount:         2,
+	}
+	assert.Nil(t, sink.push(MonitoringSample{MonitoredAt: newTelemetrySample().MonitoredAt}, &stats, nil))
+
+	buffer := make([]byte, 65536)
+	_ = listener.SetReadDeadline(time.Now().Add(time.Second))
+	length, _, err := listener.ReadFrom(buffer)
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		"frc_radio_configuration,firmware_version=1.2.3,hardware_type=TypeLinksys attempt_count=4i,success_count=3i,"+
+			"failure_count=1i,success_rate_percent=75,retry_count=2i,verification_failure_count=0i,"+
+			"mitigation_count=0i 1709403342000000005\n",
+		string(buffer[:length]),
+	)
+}
@@ -0,0 +1,71 @@
+package radio
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Highest overheating threshold that may be configured, in degrees Celsius.
+	maxOverheatThresholdC = 150
+
+	// How far the temperature must fall below the overheating threshold for the radio to count as having cooled down,
+	// so that a temperature hovering around the threshold doesn't raise an alert at every poll.
+	overheatHysteresisC = 5
+)
+
+// Pattern matching the sysfs files through which each thermal zone reports its temperature, in thousandths of a degree
+// Celsius.
+var thermalZoneTemperatureGlob = "/sys/class/thermal/thermal_zone*/temp"
+
+// readTemperatureC returns the temperature of the hottest thermal zone in degrees Celsius, or false if none of them
+// could be read.
+func readTemperatureC() (float64, bool) {
+	paths, _ := filepath.Glob(thermalZoneTemperatureGlob)
+	var highest float64
+	ok := false
+	for _, path := range paths {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		milliDegrees, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+		if err != nil {
+			continue
+		}
+		if temperature := float64(milliDegrees) / 1000; !ok || temperature > highest {
+			highest = temperature
+			ok = true
+		}
+	}
+	return highest, ok
+}
+
+// checkTemperature raises an alert when the temperature of the radio rises to the overheating threshold, and notes
+// when it has cooled down again.
+func (radio *Radio) checkTemperature() {
+	threshold := radio.GetSettings().OverheatThresholdC
+	if threshold == 0 {
+		radio.isOverheating = false
+		return
+	}
+	temperature, ok := readTemperatureC()
+	if !ok {
+		return
+	}
+	if !radio.isOverheating && temperature >= threshold {
+		radio.isOverheating = true
+		radio.raiseAlert(
+			"OVERHEATING",
+			"Radio temperature reached %.1f°C, above the threshold of %.1f°C.",
+			temperature,
+			threshold,
+		)
+	} else if radio.isOverheating && temperature < threshold-overheatHysteresisC {
+		radio.isOverheating = false
+		log.Printf("Radio temperature has fallen back to %.1f°C.", temperature)
+	}
+}
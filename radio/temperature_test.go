@@ -0,0 +1,59 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRadio_checkTemperature(t *testing.T) {
+	directory := t.TempDir()
+	originalGlob := thermalZoneTemperatureGlob
+	t.Cleanup(func() { thermalZoneTemperatureGlob = originalGlob })
+	thermalZoneTemperatureGlob = filepath.Join(directory, "thermal_zone*", "temp")
+	setTemperature := func(zone string, value string) {
+		assert.Nil(t, os.MkdirAll(filepath.Join(directory, zone), 0755))
+		assert.Nil(t, os.WriteFile(filepath.Join(directory, zone, "temp"), []byte(value), 0644))
+	}
+	var radio Radio
+	radio.SetSettings(defaultSettings())
+
+	// Nothing happens if there are no readable thermal zones.
+	radio.checkTemperature()
+	setTemperature("thermal_zone0", "garbage")
+	radio.checkTemperature()
+	assert.False(t, radio.isOverheating)
+
+	// The hottest zone is compared against the threshold.
+	setTemperature("thermal_zone0", "62000\n")
+	setTemperature("thermal_zone1", "95500\n")
+	radio.checkTemperature()
+	assert.True(t, radio.isOverheating)
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "OVERHEATING", alerts[0].Type)
+		assert.Equal(t, "Radio temperature reached 95.5°C, above the threshold of 95.0°C.", alerts[0].Message)
+	}
+
+	// The alert isn't repeated until the radio has cooled down well below the threshold.
+	setTemperature("thermal_zone1", "91000")
+	radio.checkTemperature()
+	setTemperature("thermal_zone1", "96000")
+	radio.checkTemperature()
+	assert.Equal(t, 1, len(radio.GetAlerts()))
+	setTemperature("thermal_zone1", "89000")
+	radio.checkTemperature()
+	assert.False(t, radio.isOverheating)
+	setTemperature("thermal_zone1", "96000")
+	radio.checkTemperature()
+	assert.Equal(t, 2, len(radio.GetAlerts()))
+
+	// The check can be disabled.
+	settings := defaultSettings()
+	settings.OverheatThresholdC = 0
+	radio.SetSettings(settings)
+	radio.checkTemperature()
+	assert.False(t, radio.isOverheating)
+	assert.Equal(t, 2, len(radio.GetAlerts()))
+}
@@ -0,0 +1,23 @@
+package radio
+
+import "time"
+
+// parseTimeOfDay parses the given 24-hour "HH:MM" time into the offset from midnight that it represents.
+func parseTimeOfDay(value string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// isWithinTimesOfDay returns true if the given time falls within the daily window between the given offsets from
+// midnight, which spans midnight if the end is earlier than the start.
+func isWithinTimesOfDay(now time.Time, start, end time.Duration) bool {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	offset := now.Sub(midnight)
+	if start < end {
+		return offset >= start && offset < end
+	}
+	return offset >= start || offset < end
+}
@@ -0,0 +1,16 @@
+package radio
+
+// TimeServerClientStatus represents the devices on a team's network that synchronize their clocks with the access
+// point's time server.
+type TimeServerClientStatus struct {
+	// IP addresses of the devices on the team's network that have requested the time within the last 20 minutes, in
+	// ascending order.
+	ClientIpAddresses []string `json:"clientIpAddresses"`
+
+	// Time at which a request for the time from a device on the team's network was last seen, or null if none has been
+	// seen since the time server was enabled.
+	LastRequestAt *Timestamp `json:"lastRequestAt"`
+
+	// Whether any device on the team's network has requested the time within the last 20 minutes.
+	IsSynchronizing bool `json:"isSynchronizing"`
+}
@@ -0,0 +1,114 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/digineo/go-uci"
+	"log"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// UDP port on which NTP requests are served.
+	ntpPort = 123
+
+	// How long after its last request for the time a device still counts as keeping its clock synchronized; longer
+	// than the maximum polling interval of common NTP clients (1024 seconds).
+	timeServerClientStaleDuration = 20 * time.Minute
+)
+
+// readTimeServer returns whether the access point is configured to serve the time over NTP.
+func readTimeServer() bool {
+	enabled, _ := uciTree.GetLast("system", "ntp", "enable_server")
+	return enabled == "1"
+}
+
+// configureTimeServer enables or disables serving the time over NTP to the devices on the team VLANs, for venues
+// without internet access where the clocks of the robot radios would otherwise drift apart.
+func (radio *Radio) configureTimeServer(enabled bool) error {
+	serverEnabled := "0"
+	if enabled {
+		serverEnabled = "1"
+	}
+	uciTree.SetType("system", "ntp", "enable_server", uci.TypeOption, serverEnabled)
+	if err := radio.commitUci("system"); err != nil {
+		return fmt.Errorf("failed to commit system configuration: %w", err)
+	}
+	if _, err := shell.runCommand("/etc/init.d/sysntpd", "restart"); err != nil {
+		return fmt.Errorf("failed to restart NTP service: %w", err)
+	}
+	radio.TimeServer = enabled
+	if !enabled {
+		radio.timeServerRequests = nil
+	}
+	return nil
+}
+
+// updateTimeServerClients samples the connection tracking table as of the given time for NTP requests from the team
+// networks and updates the time synchronization status of each team station.
+func (radio *Radio) updateTimeServerClients(now Timestamp) {
+	subnets := make(map[station]*net.IPNet)
+	for station := red1; station <= blue3; station++ {
+		stationStatus := radio.StationStatuses[station.String()]
+		if stationStatus == nil {
+			continue
+		}
+		stationStatus.TimeServerClients = nil
+		if !radio.TimeServer {
+			continue
+		}
+		if teamNumber, err := strconv.Atoi(stationStatus.Ssid); err == nil {
+			_, subnet, _ := net.ParseCIDR(fmt.Sprintf("10.%d.%d.0/24", teamNumber/100, teamNumber%100))
+			subnets[station] = subnet
+		}
+	}
+	if len(subnets) == 0 {
+		return
+	}
+
+	contents, err := os.ReadFile(conntrackFilePath)
+	if err != nil {
+		log.Printf("Error reading connection tracking table: %v", err)
+		return
+	}
+	if radio.timeServerRequests == nil {
+		radio.timeServerRequests = make(map[string]Timestamp)
+	}
+	for _, line := range strings.Split(string(contents), "\n") {
+		flow, ok := parseConntrackLine(line)
+		if !ok || flow.destPort != ntpPort || !strings.HasPrefix(flow.key, "udp ") {
+			continue
+		}
+		radio.timeServerRequests[flow.sourceIp.String()] = now
+	}
+
+	for station, subnet := range subnets {
+		timeSync := &TimeServerClientStatus{ClientIpAddresses: []string{}}
+		for ipAddress, lastRequestAt := range radio.timeServerRequests {
+			if !subnet.Contains(net.ParseIP(ipAddress)) {
+				continue
+			}
+			if timeSync.LastRequestAt == nil || lastRequestAt.MonotonicNs > timeSync.LastRequestAt.MonotonicNs {
+				requestAt := lastRequestAt
+				timeSync.LastRequestAt = &requestAt
+			}
+			if time.Duration(now.MonotonicNs-lastRequestAt.MonotonicNs) <= timeServerClientStaleDuration {
+				timeSync.ClientIpAddresses = append(timeSync.ClientIpAddresses, ipAddress)
+			}
+		}
+		sort.Slice(timeSync.ClientIpAddresses, func(i, j int) bool {
+			ipAddresses := timeSync.ClientIpAddresses
+			return bytes.Compare(net.ParseIP(ipAddresses[i]), net.ParseIP(ipAddresses[j])) < 0
+		})
+		timeSync.IsSynchronizing = len(timeSync.ClientIpAddresses) > 0
+		radio.StationStatuses[station.String()].TimeServerClients = timeSync
+	}
+}
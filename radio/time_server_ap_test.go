@@ -0,0 +1,127 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRadio_handleConfigurationRequestTimeServer(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeTree.valuesForGet["system.@system[0].model"] = "VH-109(AP)"
+	fakeTree.valuesForGet["system.ntp.enable_server"] = "0"
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	wifiReloadBackoffDuration = 10 * time.Millisecond
+	fakeShell.commandOutput["cat /etc/vh_firmware"] = ""
+	radio := NewRadio()
+	assert.False(t, radio.TimeServer)
+
+	fakeShell.commandOutput["/etc/init.d/sysntpd restart"] = ""
+	fakeShell.commandOutput["wifi reload wifi1"] = ""
+	for _, iface := range []string{"ath1", "ath11", "ath12", "ath13", "ath14", "ath15"} {
+		fakeShell.commandOutput["iwinfo "+iface+" info"] = iface + "\nESSID: \"no-team-1\"\n"
+	}
+	enabled := true
+	assert.Nil(t, radio.handleConfigurationRequest(ConfigurationRequest{TimeServer: &enabled}))
+	assert.Equal(t, "1", fakeTree.valuesFromSet["system.ntp.enable_server"])
+	assert.Contains(t, fakeShell.commandsRun, "/etc/init.d/sysntpd restart")
+	assert.True(t, radio.TimeServer)
+	assert.True(t, *radio.EffectiveConfiguration().TimeServer)
+	assert.True(t, radio.ConfigurationChanges(ConfigurationRequest{TimeServer: &enabled}).IsEmpty())
+
+	// Requests that omit the option leave the time server untouched.
+	fakeTree.valuesFromSet = make(map[string]string)
+	fakeShell.commandsRun = make(map[string]struct{})
+	assert.Nil(t, radio.handleConfigurationRequest(ConfigurationRequest{Channel: 5}))
+	_, ok := fakeTree.valuesFromSet["system.ntp.enable_server"]
+	assert.False(t, ok)
+	assert.NotContains(t, fakeShell.commandsRun, "/etc/init.d/sysntpd restart")
+
+	enabled = false
+	assert.Nil(t, radio.handleConfigurationRequest(ConfigurationRequest{TimeServer: &enabled}))
+	assert.Equal(t, "0", fakeTree.valuesFromSet["system.ntp.enable_server"])
+	assert.False(t, radio.TimeServer)
+
+	// A failure to restart the NTP service is reported.
+	delete(fakeShell.commandOutput, "/etc/init.d/sysntpd restart")
+	fakeShell.commandErrors["/etc/init.d/sysntpd restart"] = errors.New("oops")
+	enabled = true
+	assert.EqualError(
+		t,
+		radio.handleConfigurationRequest(ConfigurationRequest{TimeServer: &enabled}),
+		"failed to restart NTP service: oops",
+	)
+	assert.False(t, radio.TimeServer)
+}
+
+func TestRadio_updateTimeServerClients(t *testing.T) {
+	conntrackFilePath = filepath.Join(t.TempDir(), "nf_conntrack")
+	radio := &Radio{
+		StationStatuses: map[string]*NetworkStatus{"red1": {Ssid: "254"}, "red2": {Ssid: "1114"}, "blue3": {Ssid: "x"}},
+	}
+	writeTable := func(lines ...string) {
+		contents := ""
+		for _, line := range lines {
+			contents += line + "\n"
+		}
+		assert.Nil(t, os.WriteFile(conntrackFilePath, []byte(contents), 0644))
+	}
+	writeTable(
+		"ipv4 2 udp 17 29 src=10.2.54.2 dst=10.0.100.2 sport=123 dport=123 packets=1 bytes=76 "+
+			"src=10.0.100.2 dst=10.2.54.2 sport=123 dport=123 packets=1 bytes=76",
+		"ipv4 2 udp 17 29 src=10.2.54.1 dst=10.0.100.2 sport=40000 dport=123 packets=1 bytes=76 "+
+			"src=10.0.100.2 dst=10.2.54.1 sport=123 dport=40000 packets=1 bytes=76",
+		"ipv4 2 tcp 6 7440 ESTABLISHED src=10.11.14.2 dst=10.0.100.2 sport=40000 dport=123 "+
+			"src=10.0.100.2 dst=10.11.14.2 sport=123 dport=40000",
+		"ipv4 2 udp 17 29 src=10.11.14.2 dst=10.0.100.5 sport=5800 dport=1140 packets=1 bytes=7 "+
+			"src=10.0.100.5 dst=10.11.14.2 sport=1140 dport=5800 packets=1 bytes=0",
+	)
+
+	// Nothing is tracked while the time server is disabled.
+	now := newTimestamp()
+	radio.updateTimeServerClients(now)
+	assert.Nil(t, radio.StationStatuses["red1"].TimeServerClients)
+	assert.Nil(t, radio.timeServerRequests)
+
+	radio.TimeServer = true
+	radio.updateTimeServerClients(now)
+	if timeSync := radio.StationStatuses["red1"].TimeServerClients; assert.NotNil(t, timeSync) {
+		assert.Equal(t, []string{"10.2.54.1", "10.2.54.2"}, timeSync.ClientIpAddresses)
+		assert.Equal(t, &now, timeSync.LastRequestAt)
+		assert.True(t, timeSync.IsSynchronizing)
+	}
+	assert.Equal(
+		t, &TimeServerClientStatus{ClientIpAddresses: []string{}}, radio.StationStatuses["red2"].TimeServerClients,
+	)
+	assert.Nil(t, radio.StationStatuses["blue3"].TimeServerClients)
+
+	// Devices that stop requesting the time drop out of the list of clients once their last request goes stale.
+	writeTable(
+		"ipv4 2 udp 17 29 src=10.2.54.2 dst=10.0.100.2 sport=123 dport=123 packets=2 bytes=152 " +
+			"src=10.0.100.2 dst=10.2.54.2 sport=123 dport=123 packets=2 bytes=152",
+	)
+	later := Timestamp{
+		Wallclock:   now.Wallclock.Add(10 * time.Minute),
+		MonotonicNs: now.MonotonicNs + (10 * time.Minute).Nanoseconds(),
+	}
+	radio.updateTimeServerClients(later)
+	writeTable()
+	muchLater := Timestamp{
+		Wallclock:   now.Wallclock.Add(25 * time.Minute),
+		MonotonicNs: now.MonotonicNs + (25 * time.Minute).Nanoseconds(),
+	}
+	radio.updateTimeServerClients(muchLater)
+	if timeSync := radio.StationStatuses["red1"].TimeServerClients; assert.NotNil(t, timeSync) {
+		assert.Equal(t, []string{"10.2.54.2"}, timeSync.ClientIpAddresses)
+		assert.Equal(t, &later, timeSync.LastRequestAt)
+		assert.True(t, timeSync.IsSynchronizing)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build linux
+
+package radio
+
+import "syscall"
+
+const (
+	// Clock state returned by adjtimex when the clock is not synchronized.
+	adjtimexTimeError = 5
+
+	// Status flag set by adjtimex when the clock is not synchronized.
+	adjtimexStatusUnsync = 0x0040
+)
+
+// getTimeSyncStatus queries the kernel's NTP discipline for the synchronization state of the system clock.
+func getTimeSyncStatus() TimeSyncStatus {
+	var timex syscall.Timex
+	state, err := syscall.Adjtimex(&timex)
+	if err != nil {
+		return TimeSyncStatus{}
+	}
+	return TimeSyncStatus{
+		IsSynchronized:   state != adjtimexTimeError && timex.Status&adjtimexStatusUnsync == 0,
+		EstimatedErrorUs: int64(timex.Esterror),
+		MaxErrorUs:       int64(timex.Maxerror),
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package radio
+
+// getTimeSyncStatus reports the clock as unsynchronized since the synchronization state can't be queried on this
+// platform.
+func getTimeSyncStatus() TimeSyncStatus {
+	return TimeSyncStatus{}
+}
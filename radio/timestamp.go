@@ -0,0 +1,36 @@
+package radio
+
+import "time"
+
+// Time at which the API started, used as the reference point for monotonic timestamps.
+var processStartTime = time.Now()
+
+// Timestamp records when something happened in a form that can be correlated both across devices and precisely within
+// this one.
+type Timestamp struct {
+	// Wall-clock time, which can be correlated with logs from other devices to the extent that their clocks are
+	// synchronized (see TimeSyncStatus).
+	Wallclock time.Time `json:"wallclock"`
+
+	// Nanoseconds elapsed since the API started according to the monotonic clock. Unaffected by clock adjustments, so
+	// it can be used to precisely order and measure intervals between events on this device.
+	MonotonicNs int64 `json:"monotonicNs"`
+}
+
+// TimeSyncStatus represents how well the system clock is synchronized with NTP.
+type TimeSyncStatus struct {
+	// Whether the kernel considers the clock to be synchronized.
+	IsSynchronized bool `json:"isSynchronized"`
+
+	// Estimated error of the clock, in microseconds.
+	EstimatedErrorUs int64 `json:"estimatedErrorUs"`
+
+	// Maximum error of the clock, in microseconds.
+	MaxErrorUs int64 `json:"maxErrorUs"`
+}
+
+// newTimestamp returns a timestamp representing the current time.
+func newTimestamp() Timestamp {
+	now := time.Now()
+	return Timestamp{Wallclock: now, MonotonicNs: now.Sub(processStartTime).Nanoseconds()}
+}
@@ -0,0 +1,33 @@
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewTimestamp(t *testing.T) {
+	before := time.Now()
+	first := newTimestamp()
+	second := newTimestamp()
+	assert.False(t, first.Wallclock.Before(before))
+	assert.Greater(t, first.MonotonicNs, int64(0))
+	assert.GreaterOrEqual(t, second.MonotonicNs, first.MonotonicNs)
+	assert.Equal(t, first.Wallclock.Sub(processStartTime).Nanoseconds(), first.MonotonicNs)
+}
+
+func TestRadio_setStatus(t *testing.T) {
+	radio := Radio{}
+	radio.setStatus(statusConfiguring)
+	assert.Equal(t, statusConfiguring, radio.Status)
+	changedAt := radio.StatusChangedAt
+	assert.False(t, changedAt.Wallclock.IsZero())
+
+	// Setting the same status again shouldn't count as a transition.
+	radio.setStatus(statusConfiguring)
+	assert.Equal(t, changedAt, radio.StatusChangedAt)
+
+	radio.setStatus(statusActive)
+	assert.Equal(t, statusActive, radio.Status)
+	assert.Greater(t, radio.StatusChangedAt.MonotonicNs, changedAt.MonotonicNs)
+}
@@ -0,0 +1,26 @@
+package radio
+
+// TrafficMix represents a breakdown of the traffic to and from a team's network by the kind of traffic, derived from
+// the destination port of each tracked connection.
+type TrafficMix struct {
+	// Driver station control and status traffic and robot dashboards.
+	Control TrafficClassStats `json:"control"`
+
+	// Camera streams.
+	Camera TrafficClassStats `json:"camera"`
+
+	// Everything else.
+	Other TrafficClassStats `json:"other"`
+}
+
+// TrafficClassStats represents the traffic of a single kind to and from a team's network.
+type TrafficClassStats struct {
+	// Number of connections of this kind currently being tracked.
+	FlowCount int `json:"flowCount"`
+
+	// Total bytes transferred in both directions since the team was configured on the station.
+	Bytes int64 `json:"bytes"`
+
+	// Average rate at which bytes were transferred since the previous sample, in megabits per second.
+	Mbps float64 `json:"mbps"`
+}
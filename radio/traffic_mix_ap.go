@@ -0,0 +1,165 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Path to the kernel's connection tracking table; variable to facilitate testing.
+var conntrackFilePath = "/proc/net/nf_conntrack"
+
+// conntrackFlow represents a single connection from the connection tracking table.
+type conntrackFlow struct {
+	key      string
+	sourceIp net.IP
+	destIp   net.IP
+	destPort int
+	bytes    int64
+}
+
+// updateTrafficMixes samples the connection tracking table as of the given time and updates the breakdown of each team
+// station's traffic by kind.
+func (radio *Radio) updateTrafficMixes(now time.Time) {
+	subnets := make(map[station]*net.IPNet)
+	for station := red1; station <= blue3; station++ {
+		stationStatus := radio.StationStatuses[station.String()]
+		if stationStatus == nil {
+			continue
+		}
+		if teamNumber, err := strconv.Atoi(stationStatus.Ssid); err == nil {
+			_, subnet, _ := net.ParseCIDR(fmt.Sprintf("10.%d.%d.0/24", teamNumber/100, teamNumber%100))
+			subnets[station] = subnet
+		}
+	}
+	if len(subnets) == 0 {
+		radio.trafficFlowBytes = nil
+		return
+	}
+
+	contents, err := os.ReadFile(conntrackFilePath)
+	if err != nil {
+		log.Printf("Error reading connection tracking table: %v", err)
+		for station := range subnets {
+			radio.StationStatuses[station.String()].TrafficMix = nil
+		}
+		return
+	}
+
+	mixes := make(map[station]*TrafficMix)
+	for station := range subnets {
+		mix := &TrafficMix{}
+		if previous := radio.StationStatuses[station.String()].TrafficMix; previous != nil {
+			mix.Control.Bytes = previous.Control.Bytes
+			mix.Camera.Bytes = previous.Camera.Bytes
+			mix.Other.Bytes = previous.Other.Bytes
+		}
+		mixes[station] = mix
+	}
+	deltas := make(map[*TrafficClassStats]int64)
+	flowBytes := make(map[string]int64)
+	for _, line := range strings.Split(string(contents), "\n") {
+		flow, ok := parseConntrackLine(line)
+		if !ok {
+			continue
+		}
+		for station, subnet := range subnets {
+			if !subnet.Contains(flow.sourceIp) && !subnet.Contains(flow.destIp) {
+				continue
+			}
+			stats := mixes[station].classStats(flow.destPort)
+			stats.FlowCount++
+			delta := flow.bytes
+			if previousBytes, ok := radio.trafficFlowBytes[flow.key]; ok && previousBytes <= flow.bytes {
+				delta -= previousBytes
+			}
+			stats.Bytes += delta
+			deltas[stats] += delta
+			flowBytes[flow.key] = flow.bytes
+		}
+	}
+
+	elapsedSec := now.Sub(radio.trafficSampledAt).Seconds()
+	for station, mix := range mixes {
+		for _, stats := range []*TrafficClassStats{&mix.Control, &mix.Camera, &mix.Other} {
+			if radio.trafficFlowBytes != nil && elapsedSec > 0 {
+				stats.Mbps = math.Round(float64(deltas[stats])*8/1e6/elapsedSec*100) / 100
+			}
+		}
+		radio.StationStatuses[station.String()].TrafficMix = mix
+	}
+	radio.trafficFlowBytes = flowBytes
+	radio.trafficSampledAt = now
+}
+
+// classStats returns the statistics for the kind of traffic sent to the given destination port.
+func (mix *TrafficMix) classStats(destPort int) *TrafficClassStats {
+	switch destPort {
+	case 1110, 1115, 1130, 1140, 1150, 1160, 1735, 1740, 1750:
+		// Driver station control and status traffic and robot dashboards.
+		return &mix.Control
+	case 554, 1180, 1181, 1182, 1183, 1184, 1185, 1186, 1187, 1188, 1189, 1190:
+		// RTSP and the ports reserved for camera streams.
+		return &mix.Camera
+	default:
+		return &mix.Other
+	}
+}
+
+// parseConntrackLine parses a single line of the connection tracking table, e.g.
+// "ipv4 2 udp 17 29 src=10.2.54.2 dst=10.0.100.5 sport=5800 dport=1140 packets=10 bytes=1000 src=10.0.100.5 ...".
+// Byte counts are only present if connection tracking accounting is enabled, and are otherwise taken to be zero.
+func parseConntrackLine(line string) (conntrackFlow, bool) {
+	var flow conntrackFlow
+	var keyFields []string
+	seenFields := make(map[string]int)
+	for _, field := range strings.Fields(line) {
+		name, value, ok := strings.Cut(field, "=")
+		if !ok {
+			continue
+		}
+		seenFields[name]++
+		isOriginal := seenFields[name] == 1
+		switch name {
+		case "src":
+			if isOriginal {
+				flow.sourceIp = net.ParseIP(value)
+				keyFields = append(keyFields, field)
+			}
+		case "dst":
+			if isOriginal {
+				flow.destIp = net.ParseIP(value)
+				keyFields = append(keyFields, field)
+			}
+		case "sport":
+			if isOriginal {
+				keyFields = append(keyFields, field)
+			}
+		case "dport":
+			if isOriginal {
+				flow.destPort, _ = strconv.Atoi(value)
+				keyFields = append(keyFields, field)
+			}
+		case "bytes":
+			// The first count is for the original direction and the second for the reply.
+			bytes, _ := strconv.ParseInt(value, 10, 64)
+			flow.bytes += bytes
+		}
+	}
+	if flow.sourceIp == nil || flow.destIp == nil {
+		return flow, false
+	}
+	if fields := strings.Fields(line); len(fields) > 2 {
+		keyFields = append([]string{fields[2]}, keyFields...)
+	}
+	flow.key = strings.Join(keyFields, " ")
+	return flow, true
+}
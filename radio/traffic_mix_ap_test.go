@@ -0,0 +1,90 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseConntrackLine(t *testing.T) {
+	flow, ok := parseConntrackLine(
+		"ipv4     2 udp      17 29 src=10.2.54.2 dst=10.0.100.5 sport=5800 dport=1140 packets=10 bytes=1000 " +
+			"src=10.0.100.5 dst=10.2.54.2 sport=1140 dport=5800 packets=5 bytes=500 mark=0 zone=0 use=2",
+	)
+	if assert.True(t, ok) {
+		assert.Equal(t, "udp src=10.2.54.2 dst=10.0.100.5 sport=5800 dport=1140", flow.key)
+		assert.Equal(t, "10.2.54.2", flow.sourceIp.String())
+		assert.Equal(t, "10.0.100.5", flow.destIp.String())
+		assert.Equal(t, 1140, flow.destPort)
+		assert.Equal(t, int64(1500), flow.bytes)
+	}
+
+	// Without accounting enabled there are no byte counts.
+	flow, ok = parseConntrackLine(
+		"ipv4     2 tcp      6 7440 ESTABLISHED src=10.0.100.5 dst=10.2.54.2 sport=40000 dport=1740 " +
+			"src=10.2.54.2 dst=10.0.100.5 sport=1740 dport=40000 [ASSURED] mark=0 zone=0 use=2",
+	)
+	if assert.True(t, ok) {
+		assert.Equal(t, 1740, flow.destPort)
+		assert.Equal(t, int64(0), flow.bytes)
+	}
+
+	_, ok = parseConntrackLine("")
+	assert.False(t, ok)
+}
+
+func TestRadio_updateTrafficMixes(t *testing.T) {
+	conntrackFilePath = filepath.Join(t.TempDir(), "nf_conntrack")
+	radio := &Radio{StationStatuses: map[string]*NetworkStatus{"red1": {Ssid: "254"}, "blue3": {Ssid: "1114"}}}
+	writeTable := func(lines ...string) {
+		contents := ""
+		for _, line := range lines {
+			contents += line + "\n"
+		}
+		assert.Nil(t, os.WriteFile(conntrackFilePath, []byte(contents), 0644))
+	}
+
+	// The table can't be read.
+	now := time.Now()
+	radio.updateTrafficMixes(now)
+	assert.Nil(t, radio.StationStatuses["red1"].TrafficMix)
+
+	writeTable(
+		"ipv4 2 udp 17 29 src=10.2.54.2 dst=10.0.100.5 sport=5800 dport=1140 packets=1 bytes=1000 "+
+			"src=10.0.100.5 dst=10.2.54.2 sport=1140 dport=5800 packets=1 bytes=0",
+		"ipv4 2 udp 17 29 src=10.2.54.2 dst=10.0.100.5 sport=5801 dport=1181 packets=1 bytes=100000 "+
+			"src=10.0.100.5 dst=10.2.54.2 sport=1181 dport=5801 packets=1 bytes=0",
+		"ipv4 2 tcp 6 7440 ESTABLISHED src=10.0.100.5 dst=10.2.54.2 sport=40000 dport=22 packets=1 bytes=500 "+
+			"src=10.2.54.2 dst=10.0.100.5 sport=22 dport=40000 packets=1 bytes=500",
+		"ipv4 2 udp 17 29 src=10.3.33.2 dst=10.0.100.5 sport=5800 dport=1140 packets=1 bytes=7 "+
+			"src=10.0.100.5 dst=10.3.33.2 sport=1140 dport=5800 packets=1 bytes=0",
+	)
+	radio.updateTrafficMixes(now)
+	mix := radio.StationStatuses["red1"].TrafficMix
+	if assert.NotNil(t, mix) {
+		assert.Equal(t, TrafficClassStats{FlowCount: 1, Bytes: 1000}, mix.Control)
+		assert.Equal(t, TrafficClassStats{FlowCount: 1, Bytes: 100000}, mix.Camera)
+		assert.Equal(t, TrafficClassStats{FlowCount: 1, Bytes: 1000}, mix.Other)
+	}
+	assert.Equal(t, &TrafficMix{}, radio.StationStatuses["blue3"].TrafficMix)
+
+	// Only the bytes transferred since the last sample are added, and rates are derived from them.
+	writeTable(
+		"ipv4 2 udp 17 29 src=10.2.54.2 dst=10.0.100.5 sport=5800 dport=1140 packets=1 bytes=2000 "+
+			"src=10.0.100.5 dst=10.2.54.2 sport=1140 dport=5800 packets=1 bytes=0",
+		"ipv4 2 udp 17 29 src=10.2.54.2 dst=10.0.100.5 sport=5801 dport=1181 packets=1 bytes=1350000 "+
+			"src=10.0.100.5 dst=10.2.54.2 sport=1181 dport=5801 packets=1 bytes=0",
+	)
+	radio.updateTrafficMixes(now.Add(5 * time.Second))
+	mix = radio.StationStatuses["red1"].TrafficMix
+	if assert.NotNil(t, mix) {
+		assert.Equal(t, TrafficClassStats{FlowCount: 1, Bytes: 2000, Mbps: 0}, mix.Control)
+		assert.Equal(t, TrafficClassStats{FlowCount: 1, Bytes: 1350000, Mbps: 2}, mix.Camera)
+		assert.Equal(t, TrafficClassStats{FlowCount: 0, Bytes: 1000, Mbps: 0}, mix.Other)
+	}
+}
@@ -0,0 +1,348 @@
+package radio
+
+import (
+	"fmt"
+	"github.com/digineo/go-uci"
+	"log"
+	"strings"
+	"sync"
+)
+
+// uciChangeAction represents the kind of change made to the UCI configuration.
+type uciChangeAction string
+
+const (
+	uciChangeSet           uciChangeAction = "SET"
+	uciChangeDelete        uciChangeAction = "DELETE"
+	uciChangeAddSection    uciChangeAction = "ADD_SECTION"
+	uciChangeDeleteSection uciChangeAction = "DELETE_SECTION"
+)
+
+// UciChange represents a single change staged to the UCI configuration, relative to the committed configuration.
+type UciChange struct {
+	// Configuration file, section, and option that the change applies to. The option is blank for changes to a whole
+	// section.
+	Config  string `json:"config"`
+	Section string `json:"section"`
+	Option  string `json:"option"`
+
+	// Kind of change: "SET", "DELETE", "ADD_SECTION", or "DELETE_SECTION".
+	Action uciChangeAction `json:"action"`
+
+	// Committed value of the option before the change, or blank if it wasn't set. Multiple values of a list are
+	// separated by spaces.
+	OldValue string `json:"oldValue"`
+
+	// Value of the option after the change, or the type of the section for an added section. Blank for deletions.
+	NewValue string `json:"newValue"`
+
+	// Values and type to apply to the underlying tree, and the committed values to restore if committing fails, which
+	// can't be recovered from the space-separated OldValue and NewValue.
+	values     []string
+	optionType uci.OptionType
+	oldValues  []string
+}
+
+// uciStagingTree is an implementation of the uci.Tree interface that accumulates changes in memory rather than making
+// them to the wrapped tree as they come in, so that the changes for a configuration request can be diffed against the
+// committed configuration before they are applied, and rolled back together if committing them fails. Reads reflect
+// the staged changes.
+type uciStagingTree struct {
+	base    uci.Tree
+	mutex   sync.Mutex
+	changes []UciChange
+}
+
+// newUciStagingTree returns a staging layer on top of the given tree.
+func newUciStagingTree(base uci.Tree) *uciStagingTree {
+	return &uciStagingTree{base: base}
+}
+
+// stagedUciChanges returns the changes currently staged to the UCI configuration, or nil if the tree in use doesn't
+// stage them.
+func stagedUciChanges() []UciChange {
+	if staging, ok := uciTree.(*uciStagingTree); ok {
+		return staging.Diff()
+	}
+	return nil
+}
+
+// String returns a human-readable description of the change, for logging.
+func (change UciChange) String() string {
+	switch change.Action {
+	case uciChangeAddSection:
+		return fmt.Sprintf("add %s.%s (%s)", change.Config, change.Section, change.NewValue)
+	case uciChangeDeleteSection:
+		return fmt.Sprintf("delete %s.%s", change.Config, change.Section)
+	case uciChangeDelete:
+		return fmt.Sprintf("delete %s.%s.%s (was %q)", change.Config, change.Section, change.Option, change.OldValue)
+	default:
+		return fmt.Sprintf(
+			"set %s.%s.%s to %q (was %q)",
+			change.Config,
+			change.Section,
+			change.Option,
+			change.NewValue,
+			change.OldValue,
+		)
+	}
+}
+
+// stage records the given change, superseding any earlier change to the same option.
+func (tree *uciStagingTree) stage(change UciChange) {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+	if change.Option != "" {
+		for i, existing := range tree.changes {
+			if existing.Option != "" && existing.Config == change.Config && existing.Section == change.Section &&
+				existing.Option == change.Option {
+				tree.changes = append(tree.changes[:i], tree.changes[i+1:]...)
+				break
+			}
+		}
+	}
+	tree.changes = append(tree.changes, change)
+}
+
+// stagedOption returns the latest staged change to the given option or its section, if there is one.
+func (tree *uciStagingTree) stagedOption(config, section, option string) (UciChange, bool) {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+	for i := len(tree.changes) - 1; i >= 0; i-- {
+		change := tree.changes[i]
+		if change.Config != config || change.Section != section {
+			continue
+		}
+		if change.Option == option || change.Action == uciChangeDeleteSection {
+			return change, true
+		}
+	}
+	return UciChange{}, false
+}
+
+// Diff returns the staged changes in the order in which they were made, leaving out those that set an option to the
+// value it already has.
+func (tree *uciStagingTree) Diff() []UciChange {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+	return tree.diff()
+}
+
+// diff returns the staged changes as per Diff. The mutex must be held by the caller.
+func (tree *uciStagingTree) diff() []UciChange {
+	diff := []UciChange{}
+	for _, change := range tree.changes {
+		if change.Option != "" {
+			change.oldValues, _ = tree.base.Get(change.Config, change.Section, change.Option)
+			change.OldValue = strings.Join(change.oldValues, " ")
+			if change.Action == uciChangeSet && len(change.oldValues) > 0 && change.OldValue == change.NewValue {
+				continue
+			}
+			if change.Action == uciChangeDelete && len(change.oldValues) == 0 {
+				continue
+			}
+		}
+		diff = append(diff, change)
+	}
+	return diff
+}
+
+// Commit applies the staged changes to the underlying tree and commits them. If committing fails, the underlying tree
+// is reloaded from the configuration files and any of the changes that may already have been written to them are
+// undone, so that the configuration is left as it was; deleted sections can't be restored, however.
+func (tree *uciStagingTree) Commit() error {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+	diff := tree.diff()
+	tree.changes = nil
+	configs := make(map[string]struct{})
+	for _, change := range diff {
+		configs[change.Config] = struct{}{}
+		tree.apply(change)
+	}
+	err := tree.base.Commit()
+	if err == nil || len(diff) == 0 {
+		return err
+	}
+
+	for config := range configs {
+		if loadErr := tree.base.LoadConfig(config, true); loadErr != nil {
+			log.Printf("Error reloading UCI configuration %s after failed commit: %v", config, loadErr)
+		}
+	}
+	for i := len(diff) - 1; i >= 0; i-- {
+		tree.undo(diff[i])
+	}
+	if rollbackErr := tree.base.Commit(); rollbackErr != nil {
+		log.Printf("Error rolling back UCI changes after failed commit: %v", rollbackErr)
+	}
+	return err
+}
+
+// apply makes the given change to the underlying tree.
+func (tree *uciStagingTree) apply(change UciChange) {
+	switch change.Action {
+	case uciChangeSet:
+		tree.base.SetType(change.Config, change.Section, change.Option, change.optionType, change.values...)
+	case uciChangeDelete:
+		tree.base.Del(change.Config, change.Section, change.Option)
+	case uciChangeAddSection:
+		if err := tree.base.AddSection(change.Config, change.Section, change.NewValue); err != nil {
+			log.Printf("Error adding UCI section %s.%s: %v", change.Config, change.Section, err)
+		}
+	case uciChangeDeleteSection:
+		tree.base.DelSection(change.Config, change.Section)
+	}
+}
+
+// undo reverses the given change in the underlying tree, as far as possible.
+func (tree *uciStagingTree) undo(change UciChange) {
+	switch change.Action {
+	case uciChangeSet, uciChangeDelete:
+		if len(change.oldValues) > 1 {
+			tree.base.SetType(change.Config, change.Section, change.Option, uci.TypeList, change.oldValues...)
+		} else if len(change.oldValues) == 1 {
+			tree.base.SetType(change.Config, change.Section, change.Option, uci.TypeOption, change.oldValues...)
+		} else {
+			tree.base.Del(change.Config, change.Section, change.Option)
+		}
+	case uciChangeAddSection:
+		tree.base.DelSection(change.Config, change.Section)
+	case uciChangeDeleteSection:
+		log.Printf("Unable to restore deleted UCI section %s.%s after failed commit", change.Config, change.Section)
+	}
+}
+
+// Revert discards the staged changes to the given configuration files, or to all of them if none are given, along
+// with any uncommitted changes in the underlying tree.
+func (tree *uciStagingTree) Revert(configs ...string) {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+	if len(configs) == 0 {
+		tree.changes = nil
+	} else {
+		var remaining []UciChange
+		for _, change := range tree.changes {
+			isReverted := false
+			for _, config := range configs {
+				isReverted = isReverted || change.Config == config
+			}
+			if !isReverted {
+				remaining = append(remaining, change)
+			}
+		}
+		tree.changes = remaining
+	}
+	tree.base.Revert(configs...)
+}
+
+// LoadConfig reads the given configuration file into the underlying tree. Reloading it discards any changes staged to
+// it, as it does in the underlying tree.
+func (tree *uciStagingTree) LoadConfig(name string, forceReload bool) error {
+	if forceReload {
+		tree.Revert(name)
+	}
+	return tree.base.LoadConfig(name, forceReload)
+}
+
+func (tree *uciStagingTree) GetSections(config, secType string) ([]string, bool) {
+	sections, ok := tree.base.GetSections(config, secType)
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+	for _, change := range tree.changes {
+		if change.Config != config {
+			continue
+		}
+		switch change.Action {
+		case uciChangeAddSection:
+			if change.NewValue == secType {
+				sections = append(sections, change.Section)
+				ok = true
+			}
+		case uciChangeDeleteSection:
+			for i, section := range sections {
+				if section == change.Section {
+					sections = append(sections[:i:i], sections[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+	return sections, ok
+}
+
+func (tree *uciStagingTree) Get(config, section, option string) ([]string, bool) {
+	if change, ok := tree.stagedOption(config, section, option); ok {
+		if change.Action == uciChangeSet {
+			return change.values, true
+		}
+		return nil, change.Action == uciChangeDelete
+	}
+	return tree.base.Get(config, section, option)
+}
+
+func (tree *uciStagingTree) GetLast(config, section, option string) (string, bool) {
+	if change, ok := tree.stagedOption(config, section, option); ok {
+		if change.Action == uciChangeSet && len(change.values) > 0 {
+			return change.values[len(change.values)-1], true
+		}
+		return "", false
+	}
+	return tree.base.GetLast(config, section, option)
+}
+
+func (tree *uciStagingTree) GetBool(config, section, option string) (bool, bool) {
+	if _, ok := tree.stagedOption(config, section, option); ok {
+		value, _ := tree.GetLast(config, section, option)
+		switch value {
+		case "1", "on", "true", "yes", "enabled":
+			return true, true
+		case "0", "off", "false", "no", "disabled":
+			return false, true
+		}
+		return false, false
+	}
+	return tree.base.GetBool(config, section, option)
+}
+
+// Set stages the given values for the option, as a list if there is more than one. Like SetType, it always returns
+// true since whether the section exists is only established when the changes are applied.
+func (tree *uciStagingTree) Set(config, section, option string, values ...string) bool {
+	optionType := uci.TypeOption
+	if len(values) > 1 {
+		optionType = uci.TypeList
+	}
+	return tree.SetType(config, section, option, optionType, values...)
+}
+
+// SetType stages the given values for the option. It always returns true since whether the section exists is only
+// established when the changes are applied.
+func (tree *uciStagingTree) SetType(config, section, option string, typ uci.OptionType, values ...string) bool {
+	tree.stage(
+		UciChange{
+			Config:     config,
+			Section:    section,
+			Option:     option,
+			Action:     uciChangeSet,
+			NewValue:   strings.Join(values, " "),
+			values:     values,
+			optionType: typ,
+		},
+	)
+	return true
+}
+
+func (tree *uciStagingTree) Del(config, section, option string) {
+	tree.stage(UciChange{Config: config, Section: section, Option: option, Action: uciChangeDelete})
+}
+
+// AddSection stages the addition of the given section. It never fails since the section is only added when the
+// changes are applied.
+func (tree *uciStagingTree) AddSection(config, section, typ string) error {
+	tree.stage(UciChange{Config: config, Section: section, Action: uciChangeAddSection, NewValue: typ})
+	return nil
+}
+
+func (tree *uciStagingTree) DelSection(config, section string) {
+	tree.stage(UciChange{Config: config, Section: section, Action: uciChangeDeleteSection})
+}
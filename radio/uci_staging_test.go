@@ -0,0 +1,140 @@
+package radio
+
+import (
+	"errors"
+	"github.com/digineo/go-uci"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUciStagingTree_StagesChangesUntilCommit(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	fakeTree.valuesForGet["wireless.wifi1.channel"] = "36"
+	fakeTree.valuesForGet["wireless.@wifi-iface[1].ssid"] = "254"
+	staging := newUciStagingTree(fakeTree)
+
+	staging.SetType("wireless", "wifi1", "channel", uci.TypeOption, "149")
+	staging.SetType("wireless", "@wifi-iface[1]", "ssid", uci.TypeOption, "1114")
+	staging.Del("wireless", "wifi1", "htmode")
+	assert.NoError(t, staging.AddSection("firewall", "isolate", "rule"))
+	staging.SetType("firewall", "isolate", "target", uci.TypeOption, "REJECT")
+
+	// Nothing reaches the underlying tree before the commit, but reads reflect the staged changes.
+	assert.Equal(t, 0, fakeTree.setCount)
+	value, ok := staging.GetLast("wireless", "wifi1", "channel")
+	assert.True(t, ok)
+	assert.Equal(t, "149", value)
+	value, _ = staging.GetLast("wireless", "@wifi-iface[1]", "key")
+	assert.Equal(t, "", value)
+	_, ok = staging.GetLast("wireless", "wifi1", "htmode")
+	assert.False(t, ok)
+
+	// Changing the same option again supersedes the earlier change.
+	staging.SetType("wireless", "wifi1", "channel", uci.TypeOption, "5")
+	diff := staging.Diff()
+	if assert.Equal(t, 4, len(diff)) {
+		assert.Equal(t, "set wireless.@wifi-iface[1].ssid to \"1114\" (was \"254\")", diff[0].String())
+		assert.Equal(t, "add firewall.isolate (rule)", diff[1].String())
+		assert.Equal(t, "set firewall.isolate.target to \"REJECT\" (was \"\")", diff[2].String())
+		assert.Equal(t, "set wireless.wifi1.channel to \"5\" (was \"36\")", diff[3].String())
+	}
+
+	assert.Nil(t, staging.Commit())
+	assert.Equal(t, 1, fakeTree.commitCount)
+	assert.Equal(t, "5", fakeTree.valuesForGet["wireless.wifi1.channel"])
+	assert.Equal(t, "REJECT", fakeTree.valuesForGet["firewall.isolate.target"])
+	assert.Equal(t, "***ADDED***", fakeTree.valuesFromSet["firewall.isolate"])
+
+	// Deleting an option that isn't set is a no-op.
+	_, ok = fakeTree.valuesFromSet["wireless.wifi1.htmode"]
+	assert.False(t, ok)
+	assert.Empty(t, staging.Diff())
+}
+
+func TestUciStagingTree_DiffOmitsNoOpChanges(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	fakeTree.valuesForGet["wireless.wifi1.channel"] = "36"
+	fakeTree.valuesForGet["wireless.wifi1.country"] = "US"
+	staging := newUciStagingTree(fakeTree)
+
+	staging.SetType("wireless", "wifi1", "channel", uci.TypeOption, "36")
+	staging.Set("wireless", "wifi1", "country", "CA")
+	staging.Del("wireless", "wifi1", "country")
+	assert.Equal(
+		t,
+		[]UciChange{
+			{
+				Config:    "wireless",
+				Section:   "wifi1",
+				Option:    "country",
+				Action:    uciChangeDelete,
+				OldValue:  "US",
+				oldValues: []string{"US"},
+			},
+		},
+		staging.Diff(),
+	)
+
+	assert.Nil(t, staging.Commit())
+	assert.Equal(t, map[string]string{"wireless.wifi1.country": "***DELETED***"}, fakeTree.valuesFromSet)
+}
+
+func TestUciStagingTree_RollsBackFailedCommit(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	fakeTree.valuesForGet["wireless.wifi1.channel"] = "36"
+	staging := newUciStagingTree(fakeTree)
+	staging.SetType("wireless", "wifi1", "channel", uci.TypeOption, "149")
+	staging.SetType("system", "@system[0]", "log_ip", uci.TypeOption, "10.0.100.40")
+	assert.NoError(t, staging.AddSection("firewall", "isolate", "rule"))
+
+	fakeTree.commitError = errors.New("disk full")
+	assert.EqualError(t, staging.Commit(), "disk full")
+	assert.ElementsMatch(t, []string{"wireless", "system", "firewall"}, fakeTree.loadedConfigs)
+
+	// Each change is undone in case it was already written before the commit failed.
+	assert.Equal(
+		t,
+		map[string]string{
+			"wireless.wifi1.channel":   "36",
+			"system.@system[0].log_ip": "***DELETED***",
+			"firewall.isolate":         "***DELETED***",
+		},
+		fakeTree.valuesFromSet,
+	)
+	assert.Equal(t, "36", fakeTree.valuesForGet["wireless.wifi1.channel"])
+
+	// The failed changes are no longer staged.
+	assert.Empty(t, staging.Diff())
+	value, _ := staging.GetLast("wireless", "wifi1", "channel")
+	assert.Equal(t, "36", value)
+}
+
+func TestUciStagingTree_Revert(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	fakeTree.sectionsForGet["wireless.wifi-iface"] = []string{"default_radio0", "default_radio1"}
+	staging := newUciStagingTree(fakeTree)
+	staging.SetType("wireless", "wifi1", "channel", uci.TypeOption, "149")
+	staging.SetType("system", "@system[0]", "log_ip", uci.TypeOption, "10.0.100.40")
+	assert.NoError(t, staging.AddSection("wireless", "default_radio2", "wifi-iface"))
+	staging.DelSection("wireless", "default_radio0")
+
+	sections, ok := staging.GetSections("wireless", "wifi-iface")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"default_radio1", "default_radio2"}, sections)
+	assert.Equal(t, []string{"default_radio0", "default_radio1"}, fakeTree.sectionsForGet["wireless.wifi-iface"])
+	_, ok = staging.GetLast("wireless", "default_radio0", "ssid")
+	assert.False(t, ok)
+
+	staging.Revert("wireless")
+	if diff := staging.Diff(); assert.Equal(t, 1, len(diff)) {
+		assert.Equal(t, "system", diff[0].Config)
+	}
+	sections, _ = staging.GetSections("wireless", "wifi-iface")
+	assert.Equal(t, []string{"default_radio0", "default_radio1"}, sections)
+
+	// Forcibly reloading a configuration file also discards the changes staged to it.
+	assert.Nil(t, staging.LoadConfig("system", true))
+	assert.Empty(t, staging.Diff())
+	assert.Nil(t, staging.Commit())
+	assert.Equal(t, 0, fakeTree.setCount)
+}
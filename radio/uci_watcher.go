@@ -0,0 +1,170 @@
+package radio
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// UCI configurations that are watched for changes made by something other than the API (e.g. LuCI or manual edits).
+var watchedUciConfigs = []string{"wireless", "network"}
+
+// Commands to run to apply each watched UCI configuration after it has been restored.
+var uciReloadCommands = map[string][]string{
+	"wireless": {"wifi", "reload"},
+	"network":  {"/etc/init.d/network", "reload"},
+}
+
+// ConfigDriftStatus represents the state of the check for out-of-band changes to the UCI configuration.
+type ConfigDriftStatus struct {
+	// Whether the UCI configuration files are being watched for changes.
+	IsWatching bool `json:"isWatching"`
+
+	// UCI configurations that have been changed out of band and whose changes are currently in effect. Cleared when the
+	// configuration is next written by the API.
+	DriftedConfigs []string `json:"driftedConfigs"`
+
+	// Number of out-of-band changes detected since the API started.
+	DetectedCount int `json:"detectedCount"`
+
+	// Time at which an out-of-band change was last detected. Zero if none has been detected.
+	LastDetectedAt Timestamp `json:"lastDetectedAt"`
+
+	// Number of times the API's configuration has been restored after an out-of-band change.
+	ReassertedCount int `json:"reassertedCount"`
+}
+
+// startUciWatcher records the current contents of the watched UCI configuration files and starts watching them for
+// out-of-band changes, which are then handled by the main loop.
+func (radio *Radio) startUciWatcher() {
+	radio.ConfigDrift.DriftedConfigs = []string{}
+	radio.uciSnapshots = make(map[string][]byte)
+	for _, config := range watchedUciConfigs {
+		radio.recordUciSnapshot(config)
+	}
+	changes := make(chan string, len(watchedUciConfigs))
+	if err := watchUciConfigFiles(uciConfigDirectory, watchedUciConfigs, changes); err != nil {
+		log.Printf("Not watching UCI configuration for out-of-band changes: %v", err)
+		return
+	}
+	radio.uciChanges = changes
+	radio.ConfigDrift.IsWatching = true
+}
+
+// uciFileInfos returns the file information of each watched UCI configuration file.
+func uciFileInfos() map[string]os.FileInfo {
+	fileInfos := make(map[string]os.FileInfo)
+	for _, config := range watchedUciConfigs {
+		if fileInfo, err := os.Stat(filepath.Join(uciConfigDirectory, config)); err == nil {
+			fileInfos[config] = fileInfo
+		}
+	}
+	return fileInfos
+}
+
+// recordUciSnapshots notes the contents of each watched UCI configuration file that has been replaced or modified since
+// the given file information was taken as the API's desired state, so that the API's own writes aren't mistaken for
+// drift.
+func (radio *Radio) recordUciSnapshots(previousFileInfos map[string]os.FileInfo) {
+	if radio.uciSnapshots == nil {
+		return
+	}
+	for config, fileInfo := range uciFileInfos() {
+		previous, ok := previousFileInfos[config]
+		if !ok || !os.SameFile(previous, fileInfo) || !previous.ModTime().Equal(fileInfo.ModTime()) ||
+			previous.Size() != fileInfo.Size() {
+			radio.recordUciSnapshot(config)
+		}
+	}
+}
+
+// recordUciSnapshot notes the current contents of the given UCI configuration file as the API's desired state.
+func (radio *Radio) recordUciSnapshot(config string) {
+	contents, err := os.ReadFile(filepath.Join(uciConfigDirectory, config))
+	if err != nil {
+		log.Printf("Error reading UCI configuration %s: %v", config, err)
+		return
+	}
+	radio.uciSnapshots[config] = contents
+	radio.clearConfigDrift(config)
+}
+
+// handleUciChange checks whether the given UCI configuration file was changed by something other than the API. If so,
+// either restores the API's configuration or adopts the change, depending on the settings.
+func (radio *Radio) handleUciChange(config string) {
+	path := filepath.Join(uciConfigDirectory, config)
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("Error reading UCI configuration %s: %v", config, err)
+		return
+	}
+	snapshot, ok := radio.uciSnapshots[config]
+	if !ok || bytes.Equal(contents, snapshot) {
+		// The file was written by the API or restored to what the API last wrote.
+		return
+	}
+
+	radio.ConfigDrift.DetectedCount++
+	radio.ConfigDrift.LastDetectedAt = newTimestamp()
+	// Changes made during maintenance are always adopted so that the API doesn't undo work being done on the radio.
+	if radio.GetSettings().ReassertConfigurationOnDrift && !radio.isInMaintenance() {
+		if err = radio.reassertUciConfig(config, snapshot); err != nil {
+			log.Printf("Error restoring UCI configuration %s: %v", config, err)
+		} else {
+			radio.ConfigDrift.ReassertedCount++
+			radio.raiseAlert(
+				"CONFIG_DRIFT",
+				"UCI configuration %s was changed outside the API; restored the API's configuration.",
+				config,
+			)
+			return
+		}
+	}
+
+	// Adopt the change so that the reported state reflects what is actually configured.
+	radio.raiseAlert("CONFIG_DRIFT", "UCI configuration %s was changed outside the API.", config)
+	radio.uciSnapshots[config] = contents
+	if err = uciTree.LoadConfig(config, true); err != nil {
+		log.Printf("Error reloading UCI configuration %s: %v", config, err)
+	}
+	radio.setInitialState()
+	for _, driftedConfig := range radio.ConfigDrift.DriftedConfigs {
+		if driftedConfig == config {
+			return
+		}
+	}
+	radio.ConfigDrift.DriftedConfigs = append(radio.ConfigDrift.DriftedConfigs, config)
+}
+
+// reassertUciConfig overwrites the given UCI configuration file with the given contents last written by the API and
+// reloads it.
+func (radio *Radio) reassertUciConfig(config string, contents []byte) error {
+	path := filepath.Join(uciConfigDirectory, config)
+	tempPath := filepath.Join(uciConfigDirectory, "."+config+".frc-radio-api")
+	if err := os.WriteFile(tempPath, contents, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		_ = os.Remove(tempPath)
+		return err
+	}
+	command := uciReloadCommands[config]
+	if _, err := shell.runCommand(command[0], command[1:]...); err != nil {
+		return fmt.Errorf("failed to reload %s configuration: %v", config, err)
+	}
+	return nil
+}
+
+// clearConfigDrift removes the given UCI configuration from the list of those with out-of-band changes in effect.
+func (radio *Radio) clearConfigDrift(config string) {
+	for i, driftedConfig := range radio.ConfigDrift.DriftedConfigs {
+		if driftedConfig == config {
+			radio.ConfigDrift.DriftedConfigs = append(
+				radio.ConfigDrift.DriftedConfigs[:i], radio.ConfigDrift.DriftedConfigs[i+1:]...,
+			)
+			return
+		}
+	}
+}
@@ -0,0 +1,51 @@
+//go:build linux
+
+package radio
+
+import (
+	"bytes"
+	"log"
+	"syscall"
+	"unsafe"
+)
+
+// watchUciConfigFiles uses inotify to watch the given directory and sends the name of each of the given files on the
+// given channel whenever it is written or replaced.
+func watchUciConfigFiles(directory string, fileNames []string, changes chan<- string) error {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC)
+	if err != nil {
+		return err
+	}
+	// UCI commits replace the file by renaming a temporary one over it, while editors usually rewrite it in place.
+	if _, err = syscall.InotifyAddWatch(fd, directory, syscall.IN_CLOSE_WRITE|syscall.IN_MOVED_TO); err != nil {
+		_ = syscall.Close(fd)
+		return err
+	}
+	watched := make(map[string]struct{})
+	for _, fileName := range fileNames {
+		watched[fileName] = struct{}{}
+	}
+
+	go func() {
+		defer syscall.Close(fd)
+		buffer := make([]byte, 4096)
+		for {
+			n, err := syscall.Read(fd, buffer)
+			if err != nil {
+				log.Printf("Error reading inotify events; no longer watching UCI configuration: %v", err)
+				return
+			}
+			for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+				event := (*syscall.InotifyEvent)(unsafe.Pointer(&buffer[offset]))
+				nameStart := offset + syscall.SizeofInotifyEvent
+				nameBytes := buffer[nameStart : nameStart+int(event.Len)]
+				offset = nameStart + int(event.Len)
+				name := string(bytes.TrimRight(nameBytes, "\x00"))
+				if _, ok := watched[name]; ok {
+					changes <- name
+				}
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,34 @@
+//go:build linux
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchUciConfigFiles(t *testing.T) {
+	directory := t.TempDir()
+	changes := make(chan string, 10)
+	assert.Nil(t, watchUciConfigFiles(directory, []string{"wireless", "network"}, changes))
+
+	// Unwatched files are ignored, while both in-place writes and renames are reported.
+	assert.Nil(t, os.WriteFile(filepath.Join(directory, "system"), []byte("system"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(directory, "wireless"), []byte("wireless"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(directory, ".network.tmp"), []byte("network"), 0644))
+	assert.Nil(t, os.Rename(filepath.Join(directory, ".network.tmp"), filepath.Join(directory, "network")))
+	for _, expected := range []string{"wireless", "network"} {
+		select {
+		case config := <-changes:
+			assert.Equal(t, expected, config)
+		case <-time.After(time.Second):
+			assert.Fail(t, "timed out waiting for change to "+expected)
+		}
+	}
+	assert.Equal(t, 0, len(changes))
+
+	assert.NotNil(t, watchUciConfigFiles(filepath.Join(directory, "nonexistent"), []string{"wireless"}, changes))
+}
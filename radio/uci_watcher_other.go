@@ -0,0 +1,10 @@
+//go:build !linux
+
+package radio
+
+import "errors"
+
+// watchUciConfigFiles always fails since inotify is only available on Linux.
+func watchUciConfigFiles(directory string, fileNames []string, changes chan<- string) error {
+	return errors.New("file watching is not supported on this platform")
+}
@@ -0,0 +1,126 @@
+package radio
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newUciWatcherTestRadio(t *testing.T) (*Radio, *fakeShell, *fakeUciTree) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	fakeShell.commandOutput["sh -c source /etc/openwrt_release && echo $DISTRIB_DESCRIPTION"] = ""
+	radio := NewRadio()
+	fakeShell.reset()
+	for _, iface := range []string{"wlan0", "wlan0-1", "wlan0-2", "wlan0-3", "wlan0-4", "wlan0-5"} {
+		fakeShell.commandOutput["iwinfo "+iface+" info"] = iface + "\nESSID: \"no-team-1\"\n"
+	}
+
+	uciConfigDirectory = t.TempDir()
+	t.Cleanup(func() { uciConfigDirectory = "/etc/config" })
+	writeUciConfig(t, "wireless", "config wifi-device 'radio0'\n\toption channel '36'\n")
+	writeUciConfig(t, "network", "config interface 'lan'\n")
+	radio.uciSnapshots = make(map[string][]byte)
+	radio.ConfigDrift.DriftedConfigs = []string{}
+	for _, config := range watchedUciConfigs {
+		radio.recordUciSnapshot(config)
+	}
+	return radio, fakeShell, fakeTree
+}
+
+// writeUciConfig replaces the given UCI configuration file in the same way as a UCI commit.
+func writeUciConfig(t *testing.T, config, contents string) {
+	tempPath := filepath.Join(uciConfigDirectory, ".tmp."+config)
+	assert.Nil(t, os.WriteFile(tempPath, []byte(contents), 0644))
+	assert.Nil(t, os.Rename(tempPath, filepath.Join(uciConfigDirectory, config)))
+}
+
+func readUciConfig(t *testing.T, config string) string {
+	contents, err := os.ReadFile(filepath.Join(uciConfigDirectory, config))
+	assert.Nil(t, err)
+	return string(contents)
+}
+
+func TestRadio_handleUciChangeOwnWrite(t *testing.T) {
+	radio, _, fakeTree := newUciWatcherTestRadio(t)
+
+	// A change made by the API's own commit (simulated here by writing the file directly) is not considered drift.
+	fileInfos := uciFileInfos()
+	writeUciConfig(t, "wireless", "config wifi-device 'radio0'\n\toption channel '149'\n")
+	radio.recordUciSnapshots(fileInfos)
+	radio.handleUciChange("wireless")
+	assert.Equal(t, 0, radio.ConfigDrift.DetectedCount)
+	assert.Empty(t, radio.ConfigDrift.DriftedConfigs)
+	assert.Empty(t, fakeTree.loadedConfigs)
+	assert.Equal(t, "config wifi-device 'radio0'\n\toption channel '149'\n", string(radio.uciSnapshots["wireless"]))
+	assert.Empty(t, radio.GetAlerts())
+}
+
+func TestRadio_handleUciChangeAdopt(t *testing.T) {
+	radio, _, fakeTree := newUciWatcherTestRadio(t)
+
+	writeUciConfig(t, "wireless", "config wifi-device 'radio0'\n\toption channel '149'\n")
+	radio.handleUciChange("wireless")
+	assert.Equal(t, 1, radio.ConfigDrift.DetectedCount)
+	assert.Equal(t, 0, radio.ConfigDrift.ReassertedCount)
+	assert.Equal(t, []string{"wireless"}, radio.ConfigDrift.DriftedConfigs)
+	assert.False(t, radio.ConfigDrift.LastDetectedAt.Wallclock.IsZero())
+	assert.Equal(t, []string{"wireless"}, fakeTree.loadedConfigs)
+	if alerts := radio.GetAlerts(); assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "CONFIG_DRIFT", alerts[0].Type)
+	}
+
+	// Further events for the same change are ignored.
+	radio.handleUciChange("wireless")
+	assert.Equal(t, 1, radio.ConfigDrift.DetectedCount)
+
+	// The drift is cleared once the API writes the configuration again.
+	fileInfos := uciFileInfos()
+	writeUciConfig(t, "wireless", "config wifi-device 'radio0'\n\toption channel '36'\n")
+	radio.recordUciSnapshots(fileInfos)
+	assert.Empty(t, radio.ConfigDrift.DriftedConfigs)
+	assert.Equal(t, 1, radio.ConfigDrift.DetectedCount)
+}
+
+func TestRadio_handleUciChangeReassert(t *testing.T) {
+	radio, fakeShell, fakeTree := newUciWatcherTestRadio(t)
+	settings := radio.GetSettings()
+	settings.ReassertConfigurationOnDrift = true
+	radio.SetSettings(settings)
+
+	fakeShell.commandOutput["/etc/init.d/network reload"] = ""
+	writeUciConfig(t, "network", "config interface 'lan'\n\toption proto 'dhcp'\n")
+	radio.handleUciChange("network")
+	assert.Equal(t, "config interface 'lan'\n", readUciConfig(t, "network"))
+	assert.Contains(t, fakeShell.commandsRun, "/etc/init.d/network reload")
+	assert.Equal(t, 1, radio.ConfigDrift.DetectedCount)
+	assert.Equal(t, 1, radio.ConfigDrift.ReassertedCount)
+	assert.Empty(t, radio.ConfigDrift.DriftedConfigs)
+	assert.Empty(t, fakeTree.loadedConfigs)
+
+	// The event caused by restoring the file is ignored.
+	radio.handleUciChange("network")
+	assert.Equal(t, 1, radio.ConfigDrift.DetectedCount)
+
+	// The change is adopted instead if the configuration can't be reloaded.
+	fakeShell.commandErrors["wifi reload"] = errors.New("oops")
+	writeUciConfig(t, "wireless", "config wifi-device 'radio0'\n\toption channel '149'\n")
+	radio.handleUciChange("wireless")
+	assert.Equal(t, 2, radio.ConfigDrift.DetectedCount)
+	assert.Equal(t, 1, radio.ConfigDrift.ReassertedCount)
+	assert.Equal(t, []string{"wireless"}, radio.ConfigDrift.DriftedConfigs)
+	assert.Equal(t, []string{"wireless"}, fakeTree.loadedConfigs)
+
+	// The change is also adopted while the radio is in maintenance mode.
+	radio.setMaintenanceMode(true, "", maintenanceSourceApi)
+	writeUciConfig(t, "network", "config interface 'lan'\n\toption proto 'static'\n")
+	radio.handleUciChange("network")
+	assert.Equal(t, 3, radio.ConfigDrift.DetectedCount)
+	assert.Equal(t, 1, radio.ConfigDrift.ReassertedCount)
+	assert.Equal(t, "config interface 'lan'\n\toption proto 'static'\n", readUciConfig(t, "network"))
+	assert.Equal(t, []string{"wireless", "network"}, radio.ConfigDrift.DriftedConfigs)
+}
@@ -0,0 +1,71 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"fmt"
+	"github.com/digineo/go-uci"
+	"strings"
+)
+
+// Prefix of the SSID that unassigned stations broadcast by default, which is always recognized as a placeholder so that
+// networks configured before the pattern was customized are still treated as unassigned.
+const defaultPlaceholderSsidPrefix = "no-team-"
+
+// placeholderSsid returns the SSID to use for the given station when it has no team assigned.
+func (radio *Radio) placeholderSsid(station station) string {
+	return fmt.Sprintf(radio.GetSettings().PlaceholderSsidPattern, int(station)+1)
+}
+
+// isPlaceholderSsid returns true if the given SSID read from the given station indicates it has no team assigned.
+func (radio *Radio) isPlaceholderSsid(station station, ssid string) bool {
+	return ssid == radio.placeholderSsid(station) || strings.HasPrefix(ssid, defaultPlaceholderSsidPrefix)
+}
+
+// isStationDisabled returns true if the given station's network is disabled in the wireless configuration.
+func (radio *Radio) isStationDisabled(station station) bool {
+	disabled, _ := uciTree.GetLast("wireless", wifiIfaceSection(station), "disabled")
+	return disabled == "1"
+}
+
+// setUnassignedStationFlags hides or disables the given station's network according to the settings if it has no team
+// assigned, and restores it otherwise. Options are only written if they need to change.
+func (radio *Radio) setUnassignedStationFlags(station station, isAssigned bool) {
+	mode := radio.GetSettings().UnassignedStationMode
+	setUciFlag(wifiIfaceSection(station), "hidden", !isAssigned && mode == unassignedStationModeHidden)
+	setUciFlag(wifiIfaceSection(station), "disabled", !isAssigned && mode == unassignedStationModeDisabled)
+}
+
+// appliedUnassignedStationMode infers from the wireless configuration how unassigned stations are currently presented.
+func (radio *Radio) appliedUnassignedStationMode() unassignedStationMode {
+	for station := red1; station <= blue3; station++ {
+		if radio.StationStatuses[station.String()] != nil {
+			continue
+		}
+		if radio.isStationDisabled(station) {
+			return unassignedStationModeDisabled
+		}
+		if hidden, _ := uciTree.GetLast("wireless", wifiIfaceSection(station), "hidden"); hidden == "1" {
+			return unassignedStationModeHidden
+		}
+		return unassignedStationModeBroadcast
+	}
+	return radio.GetSettings().UnassignedStationMode
+}
+
+// wifiIfaceSection returns the name of the wireless configuration section for the given station's network.
+func wifiIfaceSection(station station) string {
+	return fmt.Sprintf("@wifi-iface[%d]", int(station)+1)
+}
+
+// setUciFlag sets the given boolean option in the wireless configuration, deleting it when false so that the driver
+// default applies. Does nothing if the option already has the desired value.
+func setUciFlag(section, option string, value bool) {
+	current, _ := uciTree.GetLast("wireless", section, option)
+	if value && current != "1" {
+		uciTree.SetType("wireless", section, option, uci.TypeOption, "1")
+	} else if !value && current != "" && current != "0" {
+		uciTree.Del("wireless", section, option)
+	}
+}
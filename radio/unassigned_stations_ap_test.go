@@ -0,0 +1,130 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newUnassignedStationsTestRadio(t *testing.T) (*Radio, *fakeShell, *fakeUciTree) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	fakeTree.valuesForGet["system.@system[0].model"] = "VH-109(AP)"
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	wifiReloadBackoffDuration = 10 * time.Millisecond
+	fakeShell.commandOutput["cat /etc/vh_firmware"] = ""
+	radio := NewRadio()
+	fakeShell.reset()
+	fakeTree.reset()
+	return radio, fakeShell, fakeTree
+}
+
+func TestRadio_configureStationsHidden(t *testing.T) {
+	radio, fakeShell, fakeTree := newUnassignedStationsTestRadio(t)
+	settings := radio.GetSettings()
+	settings.PlaceholderSsidPattern = "unassigned-%d"
+	settings.UnassignedStationMode = unassignedStationModeHidden
+	radio.SetSettings(settings)
+	fakeTree.valuesForGet["wireless.@wifi-iface[1].hidden"] = "1"
+
+	fakeShell.commandOutput["wifi reload wifi1"] = ""
+	fakeShell.commandOutput["iwinfo ath1 info"] = "ath1\nESSID: \"1111\"\n"
+	fakeShell.commandOutput["iwinfo ath11 info"] = "ath11\nESSID: \"unassigned-2\"\n"
+	fakeShell.commandOutput["iwinfo ath12 info"] = "ath12\nESSID: \"unassigned-3\"\n"
+	fakeShell.commandOutput["iwinfo ath13 info"] = "ath13\nESSID: \"unassigned-4\"\n"
+	fakeShell.commandOutput["iwinfo ath14 info"] = "ath14\nESSID: \"no-team-5\"\n"
+	fakeShell.commandOutput["iwinfo ath15 info"] = "ath15\nESSID: \"unassigned-6\"\n"
+	err := radio.configureStations(
+		withOmittedStationsUnassigned(map[string]*StationConfiguration{"red1": {Ssid: "1111", WpaKey: "11111111"}}),
+	)
+	assert.Nil(t, err)
+
+	// The assigned station is unhidden, and the others are hidden with the custom placeholder SSID.
+	assert.Equal(t, "***DELETED***", fakeTree.valuesFromSet["wireless.@wifi-iface[1].hidden"])
+	assert.Equal(t, "1111", fakeTree.valuesFromSet["wireless.@wifi-iface[1].ssid"])
+	for _, position := range []string{"2", "3", "4", "5", "6"} {
+		assert.Equal(t, "1", fakeTree.valuesFromSet["wireless.@wifi-iface["+position+"].hidden"])
+		assert.Equal(t, "unassigned-"+position, fakeTree.valuesFromSet["wireless.@wifi-iface["+position+"].ssid"])
+		assert.Equal(t, "unassigned-"+position, fakeTree.valuesFromSet["wireless.@wifi-iface["+position+"].key"])
+		_, ok := fakeTree.valuesFromSet["wireless.@wifi-iface["+position+"].disabled"]
+		assert.False(t, ok)
+	}
+
+	// Networks with either the custom or the default placeholder SSID are treated as unassigned.
+	assert.NotNil(t, radio.StationStatuses["red1"])
+	for _, stationName := range []string{"red2", "red3", "blue1", "blue2", "blue3"} {
+		assert.Nil(t, radio.StationStatuses[stationName])
+	}
+	assert.Equal(t, unassignedStationModeHidden, radio.UnassignedStationMode)
+}
+
+func TestRadio_configureStationsDisabled(t *testing.T) {
+	radio, fakeShell, fakeTree := newUnassignedStationsTestRadio(t)
+	settings := radio.GetSettings()
+	settings.UnassignedStationMode = unassignedStationModeDisabled
+	radio.SetSettings(settings)
+
+	fakeShell.commandOutput["wifi reload wifi1"] = ""
+	fakeShell.commandOutput["iwinfo ath1 info"] = "ath1\nESSID: \"1111\"\n"
+	for _, wifiInterface := range []string{"ath11", "ath12", "ath13", "ath14", "ath15"} {
+		fakeShell.commandOutput["iwinfo "+wifiInterface+" info"] = wifiInterface + "\nESSID: \"no-team-1\"\n"
+	}
+	err := radio.configureStations(
+		withOmittedStationsUnassigned(map[string]*StationConfiguration{"red1": {Ssid: "1111", WpaKey: "11111111"}}),
+	)
+	assert.Nil(t, err)
+	_, ok := fakeTree.valuesFromSet["wireless.@wifi-iface[1].disabled"]
+	assert.False(t, ok)
+	for _, position := range []string{"2", "3", "4", "5", "6"} {
+		assert.Equal(t, "1", fakeTree.valuesFromSet["wireless.@wifi-iface["+position+"].disabled"])
+		_, ok = fakeTree.valuesFromSet["wireless.@wifi-iface["+position+"].hidden"]
+		assert.False(t, ok)
+	}
+	assert.Equal(t, unassignedStationModeDisabled, radio.UnassignedStationMode)
+}
+
+func TestRadio_updateStationStatusesDisabled(t *testing.T) {
+	radio, fakeShell, fakeTree := newUnassignedStationsTestRadio(t)
+	for _, position := range []string{"2", "3", "4", "5", "6"} {
+		fakeTree.valuesForGet["wireless.@wifi-iface["+position+"].disabled"] = "1"
+	}
+	fakeShell.commandOutput["iwinfo ath1 info"] = "ath1\nESSID: \"1111\"\n"
+
+	// Disabled stations are not queried since their interfaces don't exist.
+	assert.Nil(t, radio.updateStationStatuses())
+	assert.Equal(t, 1, len(fakeShell.commandsRun))
+	assert.NotNil(t, radio.StationStatuses["red1"])
+	assert.Nil(t, radio.StationStatuses["blue3"])
+	assert.Equal(t, unassignedStationModeDisabled, radio.appliedUnassignedStationMode())
+
+	// The readiness check falls back to the last station that has a network.
+	assert.True(t, radio.isStarted())
+	assert.Contains(t, fakeShell.commandsRun, "iwinfo ath1 info")
+	fakeTree.valuesForGet["wireless.@wifi-iface[1].disabled"] = "1"
+	fakeShell.reset()
+	assert.True(t, radio.isStarted())
+	assert.Equal(t, 0, len(fakeShell.commandsRun))
+}
+
+func TestRadio_appliedUnassignedStationMode(t *testing.T) {
+	radio, _, fakeTree := newUnassignedStationsTestRadio(t)
+	radio.StationStatuses["red1"] = &NetworkStatus{Ssid: "1111"}
+	fakeTree.valuesForGet["wireless.@wifi-iface[1].disabled"] = "1"
+	assert.Equal(t, unassignedStationModeBroadcast, radio.appliedUnassignedStationMode())
+
+	fakeTree.valuesForGet["wireless.@wifi-iface[2].hidden"] = "1"
+	assert.Equal(t, unassignedStationModeHidden, radio.appliedUnassignedStationMode())
+
+	// Falls back to the settings if every station has a team assigned.
+	for stationName := range radio.StationStatuses {
+		radio.StationStatuses[stationName] = &NetworkStatus{}
+	}
+	settings := radio.GetSettings()
+	settings.UnassignedStationMode = unassignedStationModeDisabled
+	radio.SetSettings(settings)
+	assert.Equal(t, unassignedStationModeDisabled, radio.appliedUnassignedStationMode())
+}
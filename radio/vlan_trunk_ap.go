@@ -0,0 +1,302 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"fmt"
+	"github.com/digineo/go-uci"
+	"log"
+	"sort"
+	"strings"
+)
+
+// VLANs that the team networks can be assigned to (see AllianceVlans).
+var teamVlans = []int{10, 20, 30, 40, 50, 60, 70, 80, 90}
+
+// VlanTrunkStatus represents which team VLANs are tagged on the access point's uplink port, and whether the network
+// of every station with a team assigned can actually reach the field network through it.
+type VlanTrunkStatus struct {
+	// Device through which the team VLANs reach the field network (e.g. "eth0"), as given in the settings.
+	UplinkDevice string `json:"uplinkDevice"`
+
+	// Team VLANs tagged on the uplink port, in ascending order.
+	TrunkedVlans []int `json:"trunkedVlans"`
+
+	// VLAN of each station with a team assigned whose VLAN isn't tagged on the uplink port, keyed by station name.
+	// Such a station's robot can link but none of its traffic reaches the FMS.
+	UntrunkedStations map[string]int `json:"untrunkedStations"`
+
+	// Team VLANs that a requested change will tag on the uplink port on the next monitoring poll, or null if no
+	// change is pending.
+	PendingVlans []int `json:"pendingVlans"`
+
+	// Description of why the most recent change couldn't be applied, or an empty string if it could.
+	Error string `json:"error"`
+}
+
+// VlanTrunkChangeRequest represents a request to change which team VLANs are tagged on the uplink port.
+type VlanTrunkChangeRequest struct {
+	// Team VLANs to tag on the uplink port; any others are removed from it.
+	TrunkedVlans []int `json:"trunkedVlans"`
+}
+
+// Validate checks that only team VLANs are requested, each at most once.
+func (request VlanTrunkChangeRequest) Validate() error {
+	requested := make(map[int]struct{})
+	for _, vlan := range request.TrunkedVlans {
+		if !isTeamVlan(vlan) {
+			return fmt.Errorf("invalid VLAN: %d (expecting 10, 20, 30, 40, 50, 60, 70, 80, or 90)", vlan)
+		}
+		if _, ok := requested[vlan]; ok {
+			return fmt.Errorf("duplicate VLAN: %d", vlan)
+		}
+		requested[vlan] = struct{}{}
+	}
+	return nil
+}
+
+// isTeamVlan returns true if the given VLAN is one that the team networks can be assigned to.
+func isTeamVlan(vlan int) bool {
+	for _, teamVlan := range teamVlans {
+		if vlan == teamVlan {
+			return true
+		}
+	}
+	return false
+}
+
+// GetVlanTrunk returns the VLAN trunking of the uplink port and any change to it that is pending.
+func (radio *Radio) GetVlanTrunk() VlanTrunkStatus {
+	radio.vlanTrunkMutex.Lock()
+	defer radio.vlanTrunkMutex.Unlock()
+	status := radio.VlanTrunk
+	status.TrunkedVlans = append([]int{}, status.TrunkedVlans...)
+	untrunkedStations := make(map[string]int)
+	for stationName, vlan := range status.UntrunkedStations {
+		untrunkedStations[stationName] = vlan
+	}
+	status.UntrunkedStations = untrunkedStations
+	if status.PendingVlans != nil {
+		status.PendingVlans = append([]int{}, status.PendingVlans...)
+	}
+	return status
+}
+
+// RequestVlanTrunkChange validates the given change to the VLAN trunking of the uplink port and schedules it to be
+// applied on the next monitoring poll. A VLAN that is assigned to a station with a team can't be removed from the
+// trunk, since that would silently cut the station's traffic off from the FMS.
+func (radio *Radio) RequestVlanTrunkChange(request VlanTrunkChangeRequest) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	for station := red1; station <= blue3; station++ {
+		assignment, ok := radio.getStationAssignment(station.String())
+		if !ok {
+			continue
+		}
+		if !containsVlan(request.TrunkedVlans, assignment.vlan) {
+			return fmt.Errorf(
+				"VLAN %d is assigned to station %s and can't be removed from the trunk", assignment.vlan, station,
+			)
+		}
+	}
+
+	radio.vlanTrunkMutex.Lock()
+	defer radio.vlanTrunkMutex.Unlock()
+	radio.VlanTrunk.PendingVlans = append([]int{}, request.TrunkedVlans...)
+	sort.Ints(radio.VlanTrunk.PendingVlans)
+	radio.markStatusChanged()
+	return nil
+}
+
+// checkVlanTrunk applies any pending change to the VLAN trunking of the uplink port, re-reads the trunking, and raises
+// an alert for each station with a team assigned whose VLAN has newly been found not to be trunked.
+func (radio *Radio) checkVlanTrunk() {
+	uplinkDevice := radio.GetSettings().VlanTrunkUplinkDevice
+
+	radio.vlanTrunkMutex.Lock()
+	defer radio.vlanTrunkMutex.Unlock()
+	status := &radio.VlanTrunk
+	if status.PendingVlans != nil {
+		if err := radio.writeTrunkedVlans(uplinkDevice, status.PendingVlans); err != nil {
+			status.Error = fmt.Sprintf("failed to apply change: %v", err)
+			log.Printf("Error changing VLAN trunk to %v: %v", status.PendingVlans, err)
+		} else {
+			status.Error = ""
+			log.Printf("Changed VLAN trunk on %s to %v.", uplinkDevice, status.PendingVlans)
+		}
+		status.PendingVlans = nil
+	}
+
+	trunkedVlans := readTrunkedVlans(uplinkDevice)
+	untrunkedStations := make(map[string]int)
+	isChanged := false
+	for station := red1; station <= blue3; station++ {
+		if radio.StationStatuses[station.String()] == nil {
+			continue
+		}
+		vlan := radio.getStationVlan(station)
+		if containsVlan(trunkedVlans, vlan) {
+			continue
+		}
+		untrunkedStations[station.String()] = vlan
+		if previousVlan, ok := status.UntrunkedStations[station.String()]; !ok || previousVlan != vlan {
+			isChanged = true
+			radio.raiseStationAlert(
+				station.String(),
+				"VLAN_NOT_TRUNKED",
+				"Station %s (SSID \"%s\") is on VLAN %d, which isn't tagged on uplink %s; its traffic can't reach "+
+					"the FMS.",
+				station,
+				radio.StationStatuses[station.String()].Ssid,
+				vlan,
+				uplinkDevice,
+			)
+		}
+	}
+
+	if isChanged || uplinkDevice != status.UplinkDevice || !equalVlans(trunkedVlans, status.TrunkedVlans) ||
+		len(untrunkedStations) != len(status.UntrunkedStations) {
+		radio.markStatusChanged()
+	}
+	status.UplinkDevice = uplinkDevice
+	status.TrunkedVlans = trunkedVlans
+	status.UntrunkedStations = untrunkedStations
+}
+
+// readTrunkedVlans returns the team VLANs whose network is bridged with the tagged sub-interface of the given uplink
+// device, in ascending order.
+func readTrunkedVlans(uplinkDevice string) []int {
+	trunkedVlans := []int{}
+	for _, vlan := range teamVlans {
+		device, _ := uciTree.GetLast("network", fmt.Sprintf("vlan%d", vlan), "device")
+		if device == "" {
+			continue
+		}
+		port := fmt.Sprintf("%s.%d", uplinkDevice, vlan)
+		if device == port {
+			trunkedVlans = append(trunkedVlans, vlan)
+			continue
+		}
+		if section, ok := findNetworkDeviceSection(device); ok {
+			ports, _ := uciTree.Get("network", section, "ports")
+			if containsPort(ports, port) {
+				trunkedVlans = append(trunkedVlans, vlan)
+			}
+		}
+	}
+	return trunkedVlans
+}
+
+// writeTrunkedVlans adds the tagged sub-interface of the given uplink device to the bridge of each of the given team
+// VLANs, removes it from the bridges of the others, and reloads the network.
+func (radio *Radio) writeTrunkedVlans(uplinkDevice string, vlans []int) error {
+	// Check every VLAN before changing any, so that a change is either applied completely or not at all.
+	sections := make(map[int]string)
+	for _, vlan := range teamVlans {
+		isWanted := containsVlan(vlans, vlan)
+		device, _ := uciTree.GetLast("network", fmt.Sprintf("vlan%d", vlan), "device")
+		if device == fmt.Sprintf("%s.%d", uplinkDevice, vlan) {
+			// The network sits directly on the tagged sub-interface rather than on a bridge with it.
+			if !isWanted {
+				return fmt.Errorf(
+					"network vlan%d is on uplink %s directly and can't be removed from the trunk", vlan, uplinkDevice,
+				)
+			}
+			continue
+		}
+		if section, ok := findNetworkDeviceSection(device); ok {
+			sections[vlan] = section
+		} else if isWanted {
+			return fmt.Errorf("network vlan%d has no bridge device to trunk it through", vlan)
+		}
+	}
+
+	for _, vlan := range teamVlans {
+		section, ok := sections[vlan]
+		if !ok {
+			continue
+		}
+		isWanted := containsVlan(vlans, vlan)
+		port := fmt.Sprintf("%s.%d", uplinkDevice, vlan)
+		values, _ := uciTree.Get("network", section, "ports")
+		if containsPort(values, port) == isWanted {
+			continue
+		}
+		var ports []string
+		for _, value := range values {
+			for _, currentPort := range strings.Fields(value) {
+				if currentPort != port {
+					ports = append(ports, currentPort)
+				}
+			}
+		}
+		if isWanted {
+			ports = append(ports, port)
+		}
+		if len(ports) > 0 {
+			uciTree.SetType("network", section, "ports", uci.TypeList, ports...)
+		} else {
+			uciTree.Del("network", section, "ports")
+		}
+	}
+	if err := radio.commitUci("network"); err != nil {
+		return fmt.Errorf("failed to commit network configuration: %v", err)
+	}
+	if _, err := shell.runCommand("/etc/init.d/network", "reload"); err != nil {
+		return fmt.Errorf("failed to reload network configuration: %v", err)
+	}
+	return nil
+}
+
+// findNetworkDeviceSection returns the name of the UCI section defining the network device with the given name, and
+// false if there is none.
+func findNetworkDeviceSection(name string) (string, bool) {
+	if name == "" {
+		return "", false
+	}
+	sections, _ := uciTree.GetSections("network", "device")
+	for _, section := range sections {
+		if sectionName, _ := uciTree.GetLast("network", section, "name"); sectionName == name {
+			return section, true
+		}
+	}
+	return "", false
+}
+
+// containsVlan returns true if the given list of VLANs includes the given one.
+func containsVlan(vlans []int, vlan int) bool {
+	for _, candidate := range vlans {
+		if candidate == vlan {
+			return true
+		}
+	}
+	return false
+}
+
+// containsPort returns true if the given list of bridge ports includes the given one, allowing for the ports to be
+// given as a single space-separated value.
+func containsPort(ports []string, port string) bool {
+	for _, value := range ports {
+		for _, candidate := range strings.Fields(value) {
+			if candidate == port {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// equalVlans returns true if the given lists of VLANs are the same.
+func equalVlans(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
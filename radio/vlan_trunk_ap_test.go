@@ -0,0 +1,138 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// setTeamVlanBridges configures the fake UCI tree with a bridge for each team VLAN, trunked on eth0 for the given
+// VLANs only.
+func setTeamVlanBridges(fakeTree *fakeUciTree, trunkedVlans ...int) {
+	var sections []string
+	for _, vlan := range teamVlans {
+		section := fmt.Sprintf("device_vlan%d", vlan)
+		sections = append(sections, section)
+		fakeTree.valuesForGet[fmt.Sprintf("network.vlan%d.device", vlan)] = fmt.Sprintf("br-vlan%d", vlan)
+		fakeTree.valuesForGet[fmt.Sprintf("network.%s.name", section)] = fmt.Sprintf("br-vlan%d", vlan)
+		if containsVlan(trunkedVlans, vlan) {
+			fakeTree.valuesForGet[fmt.Sprintf("network.%s.ports", section)] = fmt.Sprintf("lan1 eth0.%d", vlan)
+		} else {
+			fakeTree.valuesForGet[fmt.Sprintf("network.%s.ports", section)] = "lan1"
+		}
+	}
+	fakeTree.sectionsForGet["network.device"] = sections
+}
+
+func TestVlanTrunkChangeRequest_Validate(t *testing.T) {
+	assert.Nil(t, VlanTrunkChangeRequest{}.Validate())
+	assert.Nil(t, VlanTrunkChangeRequest{TrunkedVlans: []int{10, 20, 30, 40, 50, 60, 70, 80, 90}}.Validate())
+	assert.EqualError(
+		t,
+		VlanTrunkChangeRequest{TrunkedVlans: []int{10, 15}}.Validate(),
+		"invalid VLAN: 15 (expecting 10, 20, 30, 40, 50, 60, 70, 80, or 90)",
+	)
+	assert.EqualError(t, VlanTrunkChangeRequest{TrunkedVlans: []int{10, 20, 10}}.Validate(), "duplicate VLAN: 10")
+}
+
+func TestReadTrunkedVlans(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	setTeamVlanBridges(fakeTree, 10, 30, 50)
+	assert.Equal(t, []int{10, 30, 50}, readTrunkedVlans("eth0"))
+	assert.Equal(t, []int{}, readTrunkedVlans("eth1"))
+
+	// A network sitting directly on the tagged sub-interface is trunked too.
+	fakeTree.valuesForGet["network.vlan60.device"] = "eth0.60"
+	assert.Equal(t, []int{10, 30, 50, 60}, readTrunkedVlans("eth0"))
+
+	// A network whose bridge can't be found isn't.
+	fakeTree.valuesForGet["network.vlan10.device"] = "br-missing"
+	assert.Equal(t, []int{30, 50, 60}, readTrunkedVlans("eth0"))
+}
+
+func TestRadio_RequestVlanTrunkChange(t *testing.T) {
+	radio := NewRadio()
+	radio.StationStatuses["red2"] = &NetworkStatus{Ssid: "2222"}
+	radio.publishStationAssignments()
+
+	assert.EqualError(
+		t,
+		radio.RequestVlanTrunkChange(VlanTrunkChangeRequest{TrunkedVlans: []int{25}}),
+		"invalid VLAN: 25 (expecting 10, 20, 30, 40, 50, 60, 70, 80, or 90)",
+	)
+	assert.EqualError(
+		t,
+		radio.RequestVlanTrunkChange(VlanTrunkChangeRequest{TrunkedVlans: []int{10, 30}}),
+		"VLAN 20 is assigned to station red2 and can't be removed from the trunk",
+	)
+	assert.Nil(t, radio.GetVlanTrunk().PendingVlans)
+
+	assert.Nil(t, radio.RequestVlanTrunkChange(VlanTrunkChangeRequest{TrunkedVlans: []int{60, 20, 10}}))
+	assert.Equal(t, []int{10, 20, 60}, radio.GetVlanTrunk().PendingVlans)
+}
+
+func TestRadio_checkVlanTrunk(t *testing.T) {
+	fakeTree := newFakeUciTree()
+	uciTree = fakeTree
+	radio := NewRadio()
+	fakeShell := newFakeShell(t)
+	shell = fakeShell
+	radio.StationStatuses["red1"] = &NetworkStatus{Ssid: "1111"}
+	radio.StationStatuses["red3"] = &NetworkStatus{Ssid: "3333"}
+	radio.publishStationAssignments()
+	setTeamVlanBridges(fakeTree, 10, 20)
+
+	// A station whose VLAN isn't trunked raises an alert.
+	radio.checkVlanTrunk()
+	status := radio.GetVlanTrunk()
+	assert.Equal(t, "eth0", status.UplinkDevice)
+	assert.Equal(t, []int{10, 20}, status.TrunkedVlans)
+	assert.Equal(t, map[string]int{"red3": 30}, status.UntrunkedStations)
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "VLAN_NOT_TRUNKED", alerts[0].Type)
+		assert.Equal(t, "red3", alerts[0].Station)
+		assert.Equal(
+			t,
+			"Station red3 (SSID \"3333\") is on VLAN 30, which isn't tagged on uplink eth0; its traffic can't reach "+
+				"the FMS.",
+			alerts[0].Message,
+		)
+	}
+
+	// The alert isn't repeated while the station remains untrunked.
+	radio.checkVlanTrunk()
+	assert.Equal(t, 1, len(radio.GetAlerts()))
+
+	// A requested change is applied on the next poll.
+	assert.Nil(t, radio.RequestVlanTrunkChange(VlanTrunkChangeRequest{TrunkedVlans: []int{10, 30}}))
+	fakeShell.commandOutput["/etc/init.d/network reload"] = ""
+	radio.checkVlanTrunk()
+	assert.Equal(t, "lan1", fakeTree.valuesFromSet["network.device_vlan20.ports"])
+	assert.Equal(t, "lan1 eth0.30", fakeTree.valuesFromSet["network.device_vlan30.ports"])
+	assert.Equal(t, 2, fakeTree.setCount)
+	assert.Equal(t, 1, fakeTree.commitCount)
+	assert.Contains(t, fakeShell.commandsRun, "/etc/init.d/network reload")
+	status = radio.GetVlanTrunk()
+	assert.Nil(t, status.PendingVlans)
+	assert.Equal(t, "", status.Error)
+	assert.Equal(t, []int{10, 30}, status.TrunkedVlans)
+	assert.Empty(t, status.UntrunkedStations)
+	assert.Equal(t, 1, len(radio.GetAlerts()))
+
+	// A change that can't be applied leaves the configuration untouched.
+	fakeTree.valuesForGet["network.vlan70.device"] = "br-missing"
+	fakeTree.setCount = 0
+	assert.Nil(t, radio.RequestVlanTrunkChange(VlanTrunkChangeRequest{TrunkedVlans: []int{10, 30, 60, 70}}))
+	radio.checkVlanTrunk()
+	assert.Equal(t, 0, fakeTree.setCount)
+	assert.Equal(t, 1, fakeTree.commitCount)
+	status = radio.GetVlanTrunk()
+	assert.Nil(t, status.PendingVlans)
+	assert.Equal(t, "failed to apply change: network vlan70 has no bridge device to trunk it through", status.Error)
+	assert.Equal(t, []int{10, 30}, status.TrunkedVlans)
+}
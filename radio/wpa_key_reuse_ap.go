@@ -0,0 +1,139 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import "fmt"
+
+// Number of distinct WPA keys remembered for each team; the oldest is forgotten beyond this.
+const maxWpaKeysPerTeam = 8
+
+// WpaKeyHistory holds the hashes of the WPA keys that each team has been configured with at the current event, so that
+// a configuration reusing an old key or giving one team's key to another can be caught. The keys themselves are never
+// kept. Must only be accessed from the radio goroutine.
+type WpaKeyHistory struct {
+	// Code of the event that the history is for; the history is started afresh when the event changes.
+	EventCode string `json:"eventCode"`
+
+	// Salt combined with each key before hashing it, generated when the history is started.
+	Salt string `json:"salt"`
+
+	// Keys that each team has been configured with, oldest first, keyed by the team's SSID.
+	Teams map[string][]WpaKeyUse `json:"teams"`
+
+	// Number of configurations with team stations that have been checked at the event, used to identify those that
+	// don't give their match slot.
+	ConfigurationCount int `json:"configurationCount"`
+}
+
+// WpaKeyUse represents a WPA key that a team has been configured with.
+type WpaKeyUse struct {
+	// Salted hash of the key.
+	KeyHash string `json:"keyHash"`
+
+	// Station that the team was on when last configured with the key.
+	Station string `json:"station"`
+
+	// Match slot in which the team was first configured with the key.
+	FirstSlot string `json:"firstSlot"`
+
+	// Match slot in which the team was most recently configured with the key.
+	LastSlot string `json:"lastSlot"`
+}
+
+// checkWpaKeyReuse records the WPA keys given to the team stations by the given configuration request, raising an alert
+// for each team that is given a key it had earlier been moved off of, and for each key that has already been given to
+// a different team at the event. Either usually means that the FMS roster has been mixed up. Must be called from the
+// radio goroutine once the request has been applied.
+func (radio *Radio) checkWpaKeyReuse(request ConfigurationRequest) {
+	history := &radio.wpaKeyHistory
+	eventCode := radio.GetEventVariables().EventCode
+	if history.Teams == nil || history.EventCode != eventCode {
+		*history = WpaKeyHistory{EventCode: eventCode, Salt: newWpaKeySalt(), Teams: make(map[string][]WpaKeyUse)}
+	}
+
+	hasStations := false
+	for station := red1; station <= blue3; station++ {
+		if request.StationConfigurations[station.String()] != nil {
+			hasStations = true
+		}
+	}
+	if !hasStations {
+		return
+	}
+	history.ConfigurationCount++
+	slot := request.MatchSlot
+	if slot == "" {
+		slot = fmt.Sprintf("configuration %d", history.ConfigurationCount)
+		if request.id != 0 {
+			slot = fmt.Sprintf("configuration request %d", request.id)
+		}
+	}
+
+	for station := red1; station <= blue3; station++ {
+		config := request.StationConfigurations[station.String()]
+		if config == nil {
+			continue
+		}
+		keyHash := hashWpaKey(config.WpaKey, history.Salt)
+
+		for ssid, uses := range history.Teams {
+			if ssid == config.Ssid {
+				continue
+			}
+			for _, use := range uses {
+				if use.KeyHash == keyHash {
+					radio.raiseStationAlert(
+						station.String(),
+						"WPA_KEY_SHARED",
+						"Station %s is being configured for team %s in %s with the same WPA key that team %s was "+
+							"given in %s on station %s; check the FMS roster for a mix-up.",
+						station,
+						config.Ssid,
+						slot,
+						ssid,
+						use.LastSlot,
+						use.Station,
+					)
+					break
+				}
+			}
+		}
+
+		uses := history.Teams[config.Ssid]
+		index := -1
+		for i, use := range uses {
+			if use.KeyHash == keyHash {
+				index = i
+			}
+		}
+		switch {
+		case index == -1:
+			uses = append(uses, WpaKeyUse{KeyHash: keyHash, Station: station.String(), FirstSlot: slot, LastSlot: slot})
+			if len(uses) > maxWpaKeysPerTeam {
+				uses = uses[len(uses)-maxWpaKeysPerTeam:]
+			}
+		case index == len(uses)-1:
+			uses[index].Station = station.String()
+			uses[index].LastSlot = slot
+		default:
+			// The team is going back to a key it had been moved off of, as would happen with a stale roster.
+			radio.raiseStationAlert(
+				station.String(),
+				"WPA_KEY_REUSED",
+				"Team %s on station %s is being configured in %s with the WPA key it was given in %s rather than the "+
+					"key it was last given in %s; check that the FMS roster is current.",
+				config.Ssid,
+				station,
+				slot,
+				uses[index].LastSlot,
+				uses[len(uses)-1].LastSlot,
+			)
+			use := uses[index]
+			use.Station = station.String()
+			use.LastSlot = slot
+			uses = append(append(uses[:index], uses[index+1:]...), use)
+		}
+		history.Teams[config.Ssid] = uses
+	}
+}
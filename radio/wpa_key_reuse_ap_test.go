@@ -0,0 +1,101 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package radio
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRadio_checkWpaKeyReuse(t *testing.T) {
+	radio := NewRadio()
+	configure := func(slot string, configurations map[string]*StationConfiguration) {
+		radio.checkWpaKeyReuse(ConfigurationRequest{MatchSlot: slot, StationConfigurations: configurations})
+	}
+
+	configure("Q1", map[string]*StationConfiguration{
+		"red1": {Ssid: "254", WpaKey: "key254aa"}, "blue1": {Ssid: "1114", WpaKey: "key1114a"},
+	})
+	configure("Q2", map[string]*StationConfiguration{"red2": {Ssid: "254", WpaKey: "key254aa"}})
+	assert.Empty(t, radio.GetAlerts())
+	assert.Equal(t, 2, len(radio.wpaKeyHistory.Teams))
+	assert.NotContains(t, radio.wpaKeyHistory.Teams["254"][0].KeyHash, "key254aa")
+	assert.Equal(t, "Q1", radio.wpaKeyHistory.Teams["254"][0].FirstSlot)
+	assert.Equal(t, "Q2", radio.wpaKeyHistory.Teams["254"][0].LastSlot)
+
+	// A team moving to a new key is expected, but going back to an old one isn't.
+	configure("Q3", map[string]*StationConfiguration{"red3": {Ssid: "254", WpaKey: "key254bb"}})
+	assert.Empty(t, radio.GetAlerts())
+	configure("Q4", map[string]*StationConfiguration{"red3": {Ssid: "254", WpaKey: "key254aa"}})
+	alerts := radio.GetAlerts()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "WPA_KEY_REUSED", alerts[0].Type)
+		assert.Equal(t, "red3", alerts[0].Station)
+		assert.Equal(
+			t,
+			"Team 254 on station red3 is being configured in Q4 with the WPA key it was given in Q2 rather than the "+
+				"key it was last given in Q3; check that the FMS roster is current.",
+			alerts[0].Message,
+		)
+	}
+
+	// Once taken back, the old key counts as the latest one.
+	configure("Q5", map[string]*StationConfiguration{"red3": {Ssid: "254", WpaKey: "key254aa"}})
+	assert.Equal(t, 1, len(radio.GetAlerts()))
+
+	// A key given to two teams is caught, whether within the same configuration or across configurations.
+	configure("Q6", map[string]*StationConfiguration{"blue2": {Ssid: "1678", WpaKey: "key1114a"}})
+	alerts = radio.GetAlerts()
+	if assert.Equal(t, 2, len(alerts)) {
+		assert.Equal(t, "WPA_KEY_SHARED", alerts[1].Type)
+		assert.Equal(t, "blue2", alerts[1].Station)
+		assert.Equal(
+			t,
+			"Station blue2 is being configured for team 1678 in Q6 with the same WPA key that team 1114 was given in "+
+				"Q1 on station blue1; check the FMS roster for a mix-up.",
+			alerts[1].Message,
+		)
+	}
+	configure("", map[string]*StationConfiguration{
+		"red1": {Ssid: "971", WpaKey: "key971aa"}, "red2": {Ssid: "973", WpaKey: "key971aa"},
+	})
+	alerts = radio.GetAlerts()
+	if assert.Equal(t, 3, len(alerts)) {
+		assert.Equal(t, "WPA_KEY_SHARED", alerts[2].Type)
+		assert.Equal(t, "red2", alerts[2].Station)
+		assert.Contains(t, alerts[2].Message, "team 971 was given in configuration 7 on station red1")
+	}
+
+	// Configurations without team stations aren't counted.
+	radio.checkWpaKeyReuse(ConfigurationRequest{Channel: 5})
+	assert.Equal(t, 7, radio.wpaKeyHistory.ConfigurationCount)
+
+	// The history is started afresh at a new event.
+	settings := radio.GetSettings()
+	settings.EventVariables.EventCode = "2024CASJ"
+	radio.SetSettings(settings)
+	configure("Q1", map[string]*StationConfiguration{"red1": {Ssid: "1114", WpaKey: "key254aa"}})
+	assert.Equal(t, 3, len(radio.GetAlerts()))
+	assert.Equal(t, "2024CASJ", radio.wpaKeyHistory.EventCode)
+	assert.Equal(t, 1, len(radio.wpaKeyHistory.Teams))
+}
+
+func TestRadio_checkWpaKeyReuseForgetsOldestKeys(t *testing.T) {
+	radio := NewRadio()
+	for i := 0; i <= maxWpaKeysPerTeam; i++ {
+		radio.checkWpaKeyReuse(ConfigurationRequest{
+			StationConfigurations: map[string]*StationConfiguration{
+				"red1": {Ssid: "254", WpaKey: "key254a" + string(rune('a'+i))},
+			},
+		})
+	}
+	assert.Equal(t, maxWpaKeysPerTeam, len(radio.wpaKeyHistory.Teams["254"]))
+	assert.Equal(t, "configuration 2", radio.wpaKeyHistory.Teams["254"][0].FirstSlot)
+
+	// The forgotten key is treated as new.
+	radio.checkWpaKeyReuse(ConfigurationRequest{
+		StationConfigurations: map[string]*StationConfiguration{"red1": {Ssid: "254", WpaKey: "key254aa"}},
+	})
+	assert.Empty(t, radio.GetAlerts())
+}
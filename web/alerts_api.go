@@ -0,0 +1,30 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// alertsHandler returns a JSON list of the most recent alerts raised by the radio, oldest first, subject to the common
+// pagination and filtering parameters.
+func (web *WebServer) alertsHandler(w http.ResponseWriter, r *http.Request) {
+	query, err := parseListQuery(r)
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusBadRequest)
+		return
+	}
+	var entries []listEntry
+	for _, alert := range web.radio.GetAlerts() {
+		if query.station == "" || alert.Station == query.station {
+			entries = append(entries, listEntry{seq: alert.Time.MonotonicNs, at: alert.Time.Wallclock, item: alert})
+		}
+	}
+
+	jsonData, err := json.MarshalIndent(query.render(entries), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
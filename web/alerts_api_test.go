@@ -0,0 +1,41 @@
+package web
+
+import (
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_alertsHandler(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+
+	recorder := web.getHttpResponse("/alerts")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "[]", recorder.Body.String())
+
+	// Compact binary encoding requested via the Accept header.
+	recorder = web.getHttpResponseWithHeaders("/alerts", map[string]string{"Accept": "application/msgpack"})
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/msgpack", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, []byte{0x90}, recorder.Body.Bytes())
+
+	// The common pagination and filtering parameters wrap the alerts in a page envelope.
+	recorder = web.getHttpResponse("/alerts?station=red1&limit=10")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "{\n  \"items\": [],\n  \"nextCursor\": \"\"\n}", recorder.Body.String())
+	recorder = web.getHttpResponse("/alerts?from=today")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid from: today (expecting an RFC 3339 time)")
+}
+
+func TestWeb_alertsHandlerAuthorization(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.password = "mypassword"
+
+	recorder := web.getHttpResponse("/alerts")
+	assert.Equal(t, 401, recorder.Code)
+
+	recorder = web.getHttpResponseWithHeaders("/alerts", map[string]string{"Authorization": "Bearer mypassword"})
+	assert.Equal(t, 200, recorder.Code)
+}
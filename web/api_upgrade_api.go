@@ -0,0 +1,200 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+const (
+	// Path of the API binary upgrade endpoint, which is exempt from the general request body size limit.
+	apiUpgradePath = "/system/upgrade-api"
+
+	// Maximum size of the API binary that can be uploaded.
+	maxApiBinarySizeBytes = 32 * 1024 * 1024 // 32 MB
+
+	// Environment variable through which a new API process is told the file descriptor of the HTTP listener it
+	// inherited from the process it replaced.
+	listenerFdEnvVar = "FRC_RADIO_API_LISTENER_FD"
+
+	// Path of the file through which in-memory state is carried over to a new API process. It resides in RAM since it
+	// only needs to outlive the handover.
+	handoverStateFilePath = "/tmp/frc-radio-api-handover.json"
+)
+
+// SelfCheckFlag is the command-line flag with which the API binary only checks that it can run and exits, without
+// reading the settings or touching the radio. New binaries are run with it before being swapped in.
+const SelfCheckFlag = "-self-check"
+
+// webHandoverState holds the in-memory state carried over to a new API process when the API binary is upgraded.
+type webHandoverState struct {
+	Radio          radio.HandoverState  `json:"radio"`
+	RequestOrigins []RequestOriginStats `json:"requestOrigins"`
+}
+
+// apiUpgradeHandler replaces the running API binary with an uploaded one without restarting the radio or closing the
+// HTTP listener. The new binary is exec'd in place of the current process once the radio is idle, inheriting the
+// listener and the in-memory state so that the radio stays configured and monitored throughout.
+func (web *WebServer) apiUpgradeHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxApiBinarySizeBytes)
+	if err := r.ParseMultipartForm(maxMemorySizeBytes); err != nil {
+		handleWebErr(w, r, fmt.Errorf("error parsing multipart form: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		handleWebErr(w, r, fmt.Errorf("missing or invalid API binary: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	checksum := r.FormValue("checksum")
+	if !checksumRe.MatchString(checksum) {
+		handleWebErr(
+			w,
+			r,
+			errors.New(
+				"missing or invalid checksum; expecting a 64-character hexadecimal-encoded SHA-256 hash of the "+
+					"decrypted API binary",
+			),
+			http.StatusBadRequest,
+		)
+		return
+	}
+
+	// Stage the new binary alongside the current one so that it can be swapped in with an atomic rename.
+	stagedPath := web.apiBinaryPath + ".new"
+	defer os.Remove(stagedPath)
+	if err = web.decryptAndSaveFile(file, stagedPath, 0755); err != nil {
+		handleWebErr(w, r, fmt.Errorf("error saving API binary: %v", err), http.StatusUnprocessableEntity)
+		return
+	}
+	fileChecksum, err := hashFile(stagedPath)
+	if err != nil {
+		handleWebErr(w, r, fmt.Errorf("error hashing API binary: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if fileChecksum != checksum {
+		handleWebErr(
+			w, r, fmt.Errorf("checksum mismatch; expected %s, got %s", checksum, fileChecksum), http.StatusBadRequest,
+		)
+		return
+	}
+
+	// Make sure that the binary actually runs on this radio before committing to it.
+	if output, err := exec.Command(stagedPath, SelfCheckFlag).CombinedOutput(); err != nil {
+		handleWebErr(
+			w,
+			r,
+			fmt.Errorf("API binary failed to run on this radio: %v: %s", err, output),
+			http.StatusUnprocessableEntity,
+		)
+		return
+	}
+
+	isHandingOver := false
+	err = web.radio.HandOver(func(state radio.HandoverState) error {
+		isHandingOver = true
+		return web.handOverTo(stagedPath, state, w)
+	})
+	if err != nil && !isHandingOver {
+		handleWebErr(w, r, fmt.Errorf("unable to hand over to new API binary: %v", err), http.StatusConflict)
+	} else if err != nil {
+		radio.LogWithCorrelationId(
+			requestCorrelationId(r),
+			"Error handing over to new API binary; continuing with the current one: %v",
+			err,
+		)
+	}
+}
+
+// handOverTo swaps the binary at the given path in for the current one and replaces the current process with it,
+// passing along the given radio state. The response is sent just beforehand since the connection is closed by the
+// handover. Only returns if the handover fails, in which case the current binary is restored.
+func (web *WebServer) handOverTo(binaryPath string, radioState radio.HandoverState, w http.ResponseWriter) error {
+	stateBytes, err := json.Marshal(
+		webHandoverState{Radio: radioState, RequestOrigins: web.requestOrigins.list()},
+	)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(web.handoverStateFilePath, stateBytes, 0600); err != nil {
+		return err
+	}
+	previousBinaryPath := web.apiBinaryPath + ".previous"
+	if err = os.Rename(web.apiBinaryPath, previousBinaryPath); err != nil {
+		_ = os.Remove(web.handoverStateFilePath)
+		return err
+	}
+	if err = os.Rename(binaryPath, web.apiBinaryPath); err != nil {
+		_ = os.Rename(previousBinaryPath, web.apiBinaryPath)
+		_ = os.Remove(web.handoverStateFilePath)
+		return err
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprintln(
+		w, "New API binary received; handing over to it now. The radio configuration is unaffected.",
+	)
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	// Add a short delay to give the HTTP response time to be sent.
+	time.Sleep(10 * time.Millisecond)
+
+	log.Println("Handing over to new API binary.")
+	if err = execApiBinary(web.apiBinaryPath, web.listener); err != nil {
+		_ = os.Rename(previousBinaryPath, web.apiBinaryPath)
+		_ = os.Remove(web.handoverStateFilePath)
+		return err
+	}
+	return nil
+}
+
+// inheritedListener returns the HTTP listener passed down by the API process that this one replaced, or nil if this
+// process wasn't started by a handover.
+func inheritedListener() (net.Listener, error) {
+	fdValue, ok := os.LookupEnv(listenerFdEnvVar)
+	if !ok {
+		return nil, nil
+	}
+	// Don't pass the variable on to any processes that this one starts.
+	_ = os.Unsetenv(listenerFdEnvVar)
+	fd, err := strconv.Atoi(fdValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", listenerFdEnvVar, fdValue)
+	}
+	listenerFile := os.NewFile(uintptr(fd), "listener")
+	defer listenerFile.Close()
+	return net.FileListener(listenerFile)
+}
+
+// restoreHandoverState takes on the in-memory state carried over from the API process that this one replaced, if any.
+func (web *WebServer) restoreHandoverState() {
+	stateBytes, err := os.ReadFile(web.handoverStateFilePath)
+	if err != nil {
+		log.Printf("Error reading handover state; starting afresh: %v", err)
+		return
+	}
+	_ = os.Remove(web.handoverStateFilePath)
+	var state webHandoverState
+	if err = json.Unmarshal(stateBytes, &state); err != nil {
+		log.Printf("Error parsing handover state; starting afresh: %v", err)
+		return
+	}
+	web.radio.RestoreHandoverState(state.Radio)
+	web.requestOrigins.restore(state.RequestOrigins)
+	log.Printf(
+		"Restored %d alerts and %d configuration requests from the previous API process.",
+		len(state.Radio.Alerts),
+		len(state.Radio.ConfigurationRequests),
+	)
+}
@@ -0,0 +1,164 @@
+package web
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestWeb_apiUpgradeHandler(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	directory := t.TempDir()
+	web.apiBinaryPath = filepath.Join(directory, "frc-radio-api")
+	web.handoverStateFilePath = filepath.Join(directory, "handover.json")
+	assert.Nil(t, os.WriteFile(web.apiBinaryPath, []byte("old binary"), 0755))
+	var execedPaths []string
+	var execErr error
+	execApiBinary = func(binaryPath string, listener net.Listener) error {
+		contents, _ := os.ReadFile(binaryPath)
+		execedPaths = append(execedPaths, string(contents))
+		return execErr
+	}
+	// The stand-in binary only succeeds if it is probed with the self-check flag.
+	newBinary := []byte("#!/bin/sh\n[ \"$1\" = \"" + SelfCheckFlag + "\" ]\n")
+	hash := sha256.Sum256(newBinary)
+	checksum := hex.EncodeToString(hash[:])
+
+	// Invalid requests.
+	recorder := web.postFileHttpResponse(apiUpgradePath, "file", newBinary, map[string]string{"checksum": "abc"})
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "missing or invalid checksum")
+	recorder = web.postFileHttpResponse(
+		apiUpgradePath,
+		"file",
+		newBinary,
+		map[string]string{"checksum": "a3dfab891e82d64aeb510b1d4281ceb3c5057c7a9129957c56223a5f93d54315"},
+	)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "checksum mismatch")
+	brokenBinary := []byte("#!/bin/sh\necho 'exec format error'\nexit 1\n")
+	brokenHash := sha256.Sum256(brokenBinary)
+	recorder = web.postFileHttpResponse(
+		apiUpgradePath, "file", brokenBinary, map[string]string{"checksum": hex.EncodeToString(brokenHash[:])},
+	)
+	assert.Equal(t, 422, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "API binary failed to run on this radio")
+	assert.Empty(t, execedPaths)
+	_, err := os.Stat(web.apiBinaryPath + ".new")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	// The handover is refused while configuration requests are still queued.
+	_, err = web.radio.EnqueueConfigurationRequest(radio.ConfigurationRequest{})
+	assert.Nil(t, err)
+	recorder = web.postFileHttpResponse(apiUpgradePath, "file", newBinary, map[string]string{"checksum": checksum})
+	assert.Equal(t, 409, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "configuration requests are still queued")
+	assert.Empty(t, execedPaths)
+	<-web.radio.ConfigurationRequestChannel
+
+	// A failed exec restores the current binary.
+	execErr = errors.New("exec format error")
+	recorder = web.postFileHttpResponse(apiUpgradePath, "file", newBinary, map[string]string{"checksum": checksum})
+	assert.Equal(t, 202, recorder.Code)
+	assert.Equal(t, []string{string(newBinary)}, execedPaths)
+	contents, _ := os.ReadFile(web.apiBinaryPath)
+	assert.Equal(t, "old binary", string(contents))
+	_, err = os.Stat(web.handoverStateFilePath)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	// A successful exec leaves the new binary in place, with the previous one kept alongside it.
+	execErr = nil
+	execedPaths = nil
+	recorder = web.postFileHttpResponse(apiUpgradePath, "file", newBinary, map[string]string{"checksum": checksum})
+	assert.Equal(t, 202, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "handing over to it now")
+	assert.Equal(t, []string{string(newBinary)}, execedPaths)
+	contents, _ = os.ReadFile(web.apiBinaryPath)
+	assert.Equal(t, string(newBinary), string(contents))
+	contents, _ = os.ReadFile(web.apiBinaryPath + ".previous")
+	assert.Equal(t, "old binary", string(contents))
+	stateBytes, err := os.ReadFile(web.handoverStateFilePath)
+	if assert.Nil(t, err) {
+		var state webHandoverState
+		assert.Nil(t, json.Unmarshal(stateBytes, &state))
+		assert.Equal(t, 1, state.Radio.LastConfigurationRequestId)
+		assert.Equal(t, 1, len(state.Radio.ConfigurationRequests))
+	}
+}
+
+func TestWeb_apiUpgradeHandlerAuthorization(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.password = "mypassword"
+
+	recorder := web.postHttpResponse(apiUpgradePath, "")
+	assert.Equal(t, 401, recorder.Code)
+	recorder = web.postHttpResponseWithHeaders(
+		apiUpgradePath, "", map[string]string{"Authorization": "Bearer mypassword"},
+	)
+	assert.Equal(t, 400, recorder.Code)
+}
+
+func TestWeb_restoreHandoverState(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.handoverStateFilePath = filepath.Join(t.TempDir(), "handover.json")
+
+	// A missing file leaves the state untouched.
+	web.restoreHandoverState()
+	assert.Empty(t, web.radio.GetAlerts())
+
+	state := webHandoverState{
+		Radio: radio.HandoverState{
+			Alerts:                     []radio.Alert{{Type: "ROGUE_NETWORK", Message: "Rogue network detected."}},
+			LastConfigurationRequestId: 7,
+			ConfigurationRequests:      []radio.ConfigurationRequestRecord{{Id: 7, State: "APPLIED"}},
+		},
+		RequestOrigins: []RequestOriginStats{{Origin: "10.0.100.5", RequestCount: 3, AcceptedCount: 3}},
+	}
+	stateBytes, _ := json.Marshal(state)
+	assert.Nil(t, os.WriteFile(web.handoverStateFilePath, stateBytes, 0600))
+	web.restoreHandoverState()
+	assert.Equal(t, state.Radio.Alerts, web.radio.GetAlerts())
+	assert.Equal(t, state.Radio.ConfigurationRequests, web.radio.GetConfigurationRequests())
+	assert.Equal(t, state.RequestOrigins, web.requestOrigins.list())
+	_, err := os.Stat(web.handoverStateFilePath)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	// Identifiers of new configuration requests carry on from those of the previous process.
+	id, err := web.radio.EnqueueConfigurationRequest(radio.ConfigurationRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, 8, id)
+}
+
+func TestInheritedListener(t *testing.T) {
+	t.Setenv(listenerFdEnvVar, "")
+	assert.Nil(t, os.Unsetenv(listenerFdEnvVar))
+	listener, err := inheritedListener()
+	assert.Nil(t, err)
+	assert.Nil(t, listener)
+
+	t.Setenv(listenerFdEnvVar, "foo")
+	_, err = inheritedListener()
+	assert.EqualError(t, err, "invalid FRC_RADIO_API_LISTENER_FD: foo")
+
+	originalListener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer originalListener.Close()
+	listenerFile, err := originalListener.(*net.TCPListener).File()
+	assert.Nil(t, err)
+	t.Setenv(listenerFdEnvVar, strconv.Itoa(int(listenerFile.Fd())))
+	listener, err = inheritedListener()
+	if assert.Nil(t, err) && assert.NotNil(t, listener) {
+		assert.Equal(t, originalListener.Addr(), listener.Addr())
+		listener.Close()
+	}
+	_, ok := os.LookupEnv(listenerFdEnvVar)
+	assert.False(t, ok)
+}
@@ -0,0 +1,34 @@
+//go:build linux
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+)
+
+// execApiBinary replaces the current process with the API binary at the given path, passing it the given HTTP
+// listener so that connections waiting to be accepted aren't dropped. Only returns if the exec fails. Variable to
+// facilitate testing.
+var execApiBinary = func(binaryPath string, listener net.Listener) error {
+	tcpListener, ok := listener.(*net.TCPListener)
+	if !ok {
+		return errors.New("HTTP listener can't be passed to a new process")
+	}
+	listenerFile, err := tcpListener.File()
+	if err != nil {
+		return err
+	}
+	defer listenerFile.Close()
+
+	// Files are opened with close-on-exec set, which would close the listener before the new binary could use it.
+	fd := listenerFile.Fd()
+	if _, _, errno := syscall.Syscall(syscall.SYS_FCNTL, fd, syscall.F_SETFD, 0); errno != 0 {
+		return fmt.Errorf("failed to pass HTTP listener to new process: %v", errno)
+	}
+	env := append(os.Environ(), fmt.Sprintf("%s=%d", listenerFdEnvVar, fd))
+	return syscall.Exec(binaryPath, os.Args, env)
+}
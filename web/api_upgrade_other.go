@@ -0,0 +1,14 @@
+//go:build !linux
+
+package web
+
+import (
+	"errors"
+	"net"
+)
+
+// execApiBinary reports that the API binary can't be upgraded in place since passing the HTTP listener to a new
+// process isn't supported on this platform. Variable to facilitate testing.
+var execApiBinary = func(binaryPath string, listener net.Listener) error {
+	return errors.New("upgrading the API binary in place is not supported on this platform")
+}
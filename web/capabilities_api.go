@@ -0,0 +1,25 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// capabilitiesHandler returns a JSON dump of the configuration values supported by the radio.
+func (web *WebServer) capabilitiesHandler(w http.ResponseWriter, r *http.Request) {
+	jsonData, err := json.MarshalIndent(web.radio.GetCapabilities(), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(jsonData)
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,37 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_capabilitiesHandler(t *testing.T) {
+	ap := radio.NewRadio()
+	ap.Country = "GB"
+	web := NewWebServer(ap)
+
+	recorder := web.getHttpResponse("/capabilities")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	var capabilities radio.Capabilities
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &capabilities))
+	assert.Equal(t, ap.GetCapabilities(), capabilities)
+	assert.Equal(t, "GB", capabilities.Country)
+}
+
+func TestWeb_capabilitiesHandlerAuthorization(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+	web.password = "mypassword"
+
+	recorder := web.getHttpResponse("/capabilities")
+	assert.Equal(t, 401, recorder.Code)
+
+	recorder = web.getHttpResponseWithHeaders("/capabilities", map[string]string{"Authorization": "Bearer mypassword"})
+	assert.Equal(t, 200, recorder.Code)
+}
@@ -0,0 +1,20 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// channelReportHandler returns a JSON list of the interference observed on each channel, ranked from best to worst.
+func (web *WebServer) channelReportHandler(w http.ResponseWriter, r *http.Request) {
+	jsonData, err := json.MarshalIndent(web.radio.GetChannelReport(), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
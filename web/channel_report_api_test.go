@@ -0,0 +1,34 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_channelReportHandler(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+
+	recorder := web.getHttpResponse("/channels/report")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "[]", recorder.Body.String())
+}
+
+func TestWeb_channelReportHandlerAuthorization(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+	web.password = "mypassword"
+
+	recorder := web.getHttpResponse("/channels/report")
+	assert.Equal(t, 401, recorder.Code)
+
+	recorder = web.getHttpResponseWithHeaders(
+		"/channels/report", map[string]string{"Authorization": "Bearer mypassword"},
+	)
+	assert.Equal(t, 200, recorder.Code)
+}
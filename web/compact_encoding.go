@@ -0,0 +1,298 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/vmihailenco/msgpack/v5"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Media type for plain JSON responses, which are served unless the client asks for something else.
+	mediaTypeJson = "application/json"
+
+	// Media type for the same document as the JSON response but encoded in MessagePack.
+	mediaTypeMsgpack = "application/msgpack"
+
+	// Media type for MessagePack with lists of records delta-encoded against the preceding record; see recordTable.
+	mediaTypeDeltaMsgpack = "application/vnd.frc-radio-api.delta+msgpack"
+)
+
+// MessagePack extension types used by the delta encoding.
+const (
+	// A list of records, encoded as an array of the field paths followed by one array of values per record.
+	msgpackExtRecordTable = 1
+
+	// A field value that is null, since a plain nil marks a value that is the same as in the preceding record. Has no
+	// payload.
+	msgpackExtNull = 2
+
+	// An integer field value encoded as the difference from the value in the preceding record.
+	msgpackExtIntegerDelta = 3
+
+	// A field that is absent from the record. Has no payload.
+	msgpackExtAbsent = 4
+)
+
+// negotiateMediaType returns the response media type to use for the given request based on its Accept header, falling
+// back to JSON if none of the acceptable types is supported.
+func negotiateMediaType(r *http.Request) string {
+	bestMediaType, bestQuality := mediaTypeJson, 0.0
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case "application/x-msgpack":
+			mediaType = mediaTypeMsgpack
+		case mediaTypeJson, mediaTypeMsgpack, mediaTypeDeltaMsgpack:
+		default:
+			continue
+		}
+		if quality > bestQuality {
+			bestMediaType, bestQuality = mediaType, quality
+		}
+	}
+	return bestMediaType
+}
+
+// writeNegotiatedResponse writes the given JSON document to the response, transcoding it into MessagePack first if the
+// client asked for it via the Accept header.
+func writeNegotiatedResponse(w http.ResponseWriter, r *http.Request, jsonData []byte) {
+	mediaType := negotiateMediaType(r)
+	data := jsonData
+	if mediaType != mediaTypeJson {
+		var err error
+		if data, err = transcodeJsonToMsgpack(jsonData, mediaType == mediaTypeDeltaMsgpack); err != nil {
+			handleWebErr(w, r, err, http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Add("Vary", "Accept")
+	if _, err := w.Write(data); err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// transcodeJsonToMsgpack converts the given JSON document into MessagePack with its map keys sorted, so that the
+// binary form has exactly the same structure as the JSON one unless lists of records are to be delta-encoded.
+func transcodeJsonToMsgpack(jsonData []byte, delta bool) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("error decoding JSON for transcoding: %v", err)
+	}
+	value = withNativeNumbers(value)
+	if delta {
+		value = withRecordTables(value)
+	}
+
+	var buffer bytes.Buffer
+	if err := newMsgpackEncoder(&buffer).Encode(value); err != nil {
+		return nil, fmt.Errorf("error encoding MessagePack: %v", err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// newMsgpackEncoder returns an encoder that writes to the given writer with map keys sorted and each integer in its
+// smallest representation.
+func newMsgpackEncoder(writer io.Writer) *msgpack.Encoder {
+	encoder := msgpack.NewEncoder(writer)
+	encoder.SetSortMapKeys(true)
+	encoder.UseCompactInts(true)
+	return encoder
+}
+
+// withNativeNumbers returns the given generic JSON value with each number converted into an int64 if it is an integer
+// or a float64 otherwise, rather than being left as a string.
+func withNativeNumbers(value any) any {
+	switch typedValue := value.(type) {
+	case json.Number:
+		if integer, err := typedValue.Int64(); err == nil {
+			return integer
+		}
+		float, _ := typedValue.Float64()
+		return float
+	case []any:
+		for i, element := range typedValue {
+			typedValue[i] = withNativeNumbers(element)
+		}
+	case map[string]any:
+		for key, element := range typedValue {
+			typedValue[key] = withNativeNumbers(element)
+		}
+	}
+	return value
+}
+
+// withRecordTables returns the given generic JSON value, with native numbers, with each non-empty list consisting
+// solely of objects replaced by a record table.
+func withRecordTables(value any) any {
+	switch typedValue := value.(type) {
+	case []any:
+		for i, element := range typedValue {
+			typedValue[i] = withRecordTables(element)
+		}
+		if isRecordList(typedValue) {
+			return newRecordTable(typedValue)
+		}
+	case map[string]any:
+		for key, element := range typedValue {
+			typedValue[key] = withRecordTables(element)
+		}
+	}
+	return value
+}
+
+// isRecordList returns true if the given list is non-empty and consists only of JSON objects.
+func isRecordList(list []any) bool {
+	for _, element := range list {
+		if _, ok := element.(map[string]any); !ok {
+			return false
+		}
+	}
+	return len(list) > 0
+}
+
+// recordTable is a list of records that is encoded as a MessagePack extension whose payload is an array. Its first
+// element is the array of field paths, which are the JSON Pointers (RFC 6901) of the leaf values within each record
+// (e.g. "/time/monotonicNs"), in sorted order. Each following element is the array of one value per field for one
+// record, in list order. Values are written in full for the first record; for each later record, a value that is
+// unchanged from the preceding record is written as nil and an integer value may be written as the difference from the
+// preceding one.
+type recordTable struct {
+	fields []string
+	rows   [][]any
+}
+
+// newRecordTable flattens the given records, whose lists of records must already have been replaced by record tables,
+// into a record table.
+func newRecordTable(records []any) recordTable {
+	flattenedRecords := make([]map[string]any, len(records))
+	fieldSet := make(map[string]struct{})
+	for i, record := range records {
+		flattenedRecords[i] = make(map[string]any)
+		flattenRecord("", record.(map[string]any), flattenedRecords[i])
+		for field := range flattenedRecords[i] {
+			fieldSet[field] = struct{}{}
+		}
+	}
+	table := recordTable{fields: make([]string, 0, len(fieldSet))}
+	for field := range fieldSet {
+		table.fields = append(table.fields, field)
+	}
+	sort.Strings(table.fields)
+
+	var previous map[string]any
+	for _, current := range flattenedRecords {
+		row := make([]any, len(table.fields))
+		for i, field := range table.fields {
+			row[i] = recordFieldValue(field, current, previous)
+		}
+		table.rows = append(table.rows, row)
+		previous = current
+	}
+	return table
+}
+
+// flattenRecord adds the leaf values of the given record to the given map, keyed by their JSON Pointer with the given
+// prefix. Empty objects are treated as leaf values so that they aren't lost.
+func flattenRecord(prefix string, record map[string]any, flattened map[string]any) {
+	for key, value := range record {
+		field := prefix + "/" + strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			flattenRecord(field, nested, flattened)
+		} else {
+			flattened[field] = value
+		}
+	}
+}
+
+// recordFieldValue returns the value to write for the given field of the current record relative to the previous
+// record, which is nil for the first record.
+func recordFieldValue(field string, current, previous map[string]any) any {
+	value, ok := current[field]
+	previousValue, hadPrevious := previous[field]
+	if previous != nil && ok == hadPrevious && reflect.DeepEqual(value, previousValue) {
+		return nil
+	}
+	if !ok {
+		return msgpackExtension{extensionType: msgpackExtAbsent}
+	}
+	if value == nil {
+		return msgpackExtension{extensionType: msgpackExtNull}
+	}
+	if integer, ok := value.(int64); ok {
+		if previousInteger, ok := previousValue.(int64); ok {
+			// Small integers are shorter written in full than as an extension.
+			delta := msgpackExtension{extensionType: msgpackExtIntegerDelta, payload: integer - previousInteger}
+			if encodedLength(delta) < encodedLength(value) {
+				return delta
+			}
+		}
+	}
+	return value
+}
+
+// encodedLength returns the length of the given value once encoded in MessagePack.
+func encodedLength(value any) int {
+	var buffer bytes.Buffer
+	_ = newMsgpackEncoder(&buffer).Encode(value)
+	return buffer.Len()
+}
+
+func (table recordTable) EncodeMsgpack(encoder *msgpack.Encoder) error {
+	var payload bytes.Buffer
+	payloadEncoder := newMsgpackEncoder(&payload)
+	if err := payloadEncoder.EncodeArrayLen(len(table.rows) + 1); err != nil {
+		return err
+	}
+	if err := payloadEncoder.Encode(table.fields); err != nil {
+		return err
+	}
+	for _, row := range table.rows {
+		if err := payloadEncoder.Encode(row); err != nil {
+			return err
+		}
+	}
+	return msgpackExtension{extensionType: msgpackExtRecordTable, payload: msgpack.RawMessage(payload.Bytes())}.
+		EncodeMsgpack(encoder)
+}
+
+// msgpackExtension is a MessagePack extension value of the given type whose payload is the MessagePack encoding of the
+// given value, or empty if it is nil.
+type msgpackExtension struct {
+	extensionType int8
+	payload       any
+}
+
+func (extension msgpackExtension) EncodeMsgpack(encoder *msgpack.Encoder) error {
+	var payload bytes.Buffer
+	if extension.payload != nil {
+		if err := newMsgpackEncoder(&payload).Encode(extension.payload); err != nil {
+			return err
+		}
+	}
+	if err := encoder.EncodeExtHeader(extension.extensionType, payload.Len()); err != nil || payload.Len() == 0 {
+		return err
+	}
+	return encoder.Encode(msgpack.RawMessage(payload.Bytes()))
+}
@@ -0,0 +1,339 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNegotiateMediaType(t *testing.T) {
+	negotiate := func(accept string) string {
+		r := httptest.NewRequest("GET", "/status", nil)
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		return negotiateMediaType(r)
+	}
+	assert.Equal(t, mediaTypeJson, negotiate(""))
+	assert.Equal(t, mediaTypeJson, negotiate("*/*"))
+	assert.Equal(t, mediaTypeJson, negotiate("text/html"))
+	assert.Equal(t, mediaTypeMsgpack, negotiate("application/msgpack"))
+	assert.Equal(t, mediaTypeMsgpack, negotiate("application/x-msgpack"))
+	assert.Equal(t, mediaTypeMsgpack, negotiate("application/vnd.unknown+msgpack, application/msgpack"))
+	assert.Equal(t, mediaTypeDeltaMsgpack, negotiate("application/vnd.frc-radio-api.delta+msgpack, application/json"))
+	assert.Equal(t, mediaTypeJson, negotiate("application/msgpack;q=0.5, application/json"))
+	assert.Equal(t, mediaTypeMsgpack, negotiate("application/json;q=0.1, application/msgpack;q=0.9"))
+	assert.Equal(t, mediaTypeJson, negotiate("application/msgpack;q=blorpy"))
+}
+
+func TestTranscodeJsonToMsgpack(t *testing.T) {
+	data, err := transcodeJsonToMsgpack([]byte(`{"c":"x","a":1,"b":[true,null,false]}`), false)
+	assert.Nil(t, err)
+	// Keys are sorted and small integers take a single byte.
+	assert.Equal(
+		t,
+		[]byte{0x83, 0xa1, 'a', 0x01, 0xa1, 'b', 0x93, 0xc3, 0xc0, 0xc2, 0xa1, 'c', 0xa1, 'x'},
+		data,
+	)
+
+	data, err = transcodeJsonToMsgpack([]byte(`[-1, -33, 200, -200, 70000, 5000000000, 1.5]`), false)
+	assert.Nil(t, err)
+	var values []any
+	assert.Nil(t, msgpack.Unmarshal(data, &values))
+	assert.Equal(
+		t,
+		[]any{int8(-1), int8(-33), uint8(200), int16(-200), uint32(70000), uint64(5000000000), 1.5},
+		values,
+	)
+
+	_, err = transcodeJsonToMsgpack([]byte("{blorpy"), false)
+	assert.NotNil(t, err)
+}
+
+func TestTranscodeJsonToMsgpackDelta(t *testing.T) {
+	records := `[{"t":5000000000,"s":{"x":"y"}},{"t":5000000005,"s":{"x":"y"}},{"t":4999999990,"n":null}]`
+
+	// Without delta encoding, lists of records are plain arrays.
+	data, err := transcodeJsonToMsgpack([]byte(records), false)
+	assert.Nil(t, err)
+	assert.Equal(t, byte(0x93), data[0])
+
+	data, err = transcodeJsonToMsgpack([]byte(records), true)
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		[]byte{
+			0xc7, 44, msgpackExtRecordTable,
+			0x94,
+			0x93, 0xa2, '/', 'n', 0xa4, '/', 's', '/', 'x', 0xa2, '/', 't',
+			0x93, 0xc7, 0x00, msgpackExtAbsent, 0xa1, 'y', 0xcf, 0, 0, 0, 0x01, 0x2a, 0x05, 0xf2, 0x00,
+			0x93, 0xc0, 0xc0, 0xd4, msgpackExtIntegerDelta, 5,
+			0x93, 0xc7, 0x00, msgpackExtNull, 0xc7, 0x00, msgpackExtAbsent, 0xd4, msgpackExtIntegerDelta, 0xf1,
+		},
+		data,
+	)
+	decoded, err := decodeDeltaMsgpack(data)
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		[]any{
+			map[string]any{"t": int64(5000000000), "s": map[string]any{"x": "y"}},
+			map[string]any{"t": int64(5000000005), "s": map[string]any{"x": "y"}},
+			map[string]any{"t": int64(4999999990), "n": nil},
+		},
+		decoded,
+	)
+
+	// Empty nested objects and special characters in keys are preserved.
+	data, err = transcodeJsonToMsgpack([]byte(`[{"a/b~":{}}]`), true)
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		[]byte{
+			0xc7, 12, msgpackExtRecordTable,
+			0x92,
+			0x91, 0xa7, '/', 'a', '~', '1', 'b', '~', '0',
+			0x91, 0x80,
+		},
+		data,
+	)
+	decoded, err = decodeDeltaMsgpack(data)
+	assert.Nil(t, err)
+	assert.Equal(t, []any{map[string]any{"a/b~": map[string]any{}}}, decoded)
+
+	// Lists that aren't entirely records are left as arrays, while nested lists of records are delta-encoded too.
+	document := `{"a":[{"t":1},2],"b":[{"c":[{"d":"e"},{"d":"e"}]},{"c":[]},{"c":[]},{"d":null},{"d":null}]}`
+	data, err = transcodeJsonToMsgpack([]byte(document), true)
+	assert.Nil(t, err)
+	decoded, err = decodeDeltaMsgpack(data)
+	assert.Nil(t, err)
+	decodedJson, _ := json.Marshal(decoded)
+	assert.JSONEq(t, document, string(decodedJson))
+}
+
+func TestWriteNegotiatedResponse(t *testing.T) {
+	wallclock, _ := time.Parse(time.RFC3339Nano, "2024-03-02T10:15:04.123456789-08:00")
+	samples := make([]radio.MonitoringSample, 360)
+	for i := range samples {
+		samples[i] = radio.MonitoringSample{
+			MonitoredAt: radio.Timestamp{
+				Wallclock:   wallclock.Add(time.Duration(i) * 5 * time.Second),
+				MonotonicNs: 47122382729 + int64(i)*5000000000,
+			},
+			Networks: make(map[string]radio.NetworkSample),
+		}
+		for j, station := range []string{"red1", "red2", "red3", "blue1", "blue2", "blue3"} {
+			samples[i].Networks[station] = radio.NetworkSample{
+				Ssid:               fmt.Sprint(254 + j),
+				IsLinked:           true,
+				SignalDbm:          -53 - i%20/10,
+				NoiseDbm:           -95,
+				SignalNoiseRatio:   42 - i%20/10,
+				RxRateMbps:         864.8,
+				TxRateMbps:         729.6,
+				BandwidthUsedMbps:  float64(i%50) / 10,
+				TxRetryRatePercent: 2.5,
+				QualityScore:       92,
+			}
+		}
+	}
+	jsonData, _ := json.MarshalIndent(samples, "", "  ")
+
+	request := httptest.NewRequest("GET", "/status/history", nil)
+	recorder := httptest.NewRecorder()
+	writeNegotiatedResponse(recorder, request, jsonData)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, mediaTypeJson, recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "Accept", recorder.Header().Get("Vary"))
+	assert.Equal(t, jsonData, recorder.Body.Bytes())
+
+	// The MessagePack response decodes to the same document as the JSON one, and is smaller.
+	request.Header.Set("Accept", mediaTypeMsgpack)
+	recorder = httptest.NewRecorder()
+	writeNegotiatedResponse(recorder, request, jsonData)
+	assert.Equal(t, mediaTypeMsgpack, recorder.Header().Get("Content-Type"))
+	assert.Less(t, recorder.Body.Len(), len(jsonData))
+	var decoded any
+	assert.Nil(t, msgpack.Unmarshal(recorder.Body.Bytes(), &decoded))
+	decodedJson, err := json.Marshal(decoded)
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(jsonData), string(decodedJson))
+
+	// The delta-encoded response also decodes to the same document, and is around a tenth of the size.
+	request.Header.Set("Accept", mediaTypeDeltaMsgpack)
+	recorder = httptest.NewRecorder()
+	writeNegotiatedResponse(recorder, request, jsonData)
+	assert.Equal(t, mediaTypeDeltaMsgpack, recorder.Header().Get("Content-Type"))
+	assert.Less(t, recorder.Body.Len()*10, len(jsonData))
+	decoded, err = decodeDeltaMsgpack(recorder.Body.Bytes())
+	assert.Nil(t, err)
+	decodedJson, err = json.Marshal(decoded)
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(jsonData), string(decodedJson))
+}
+
+// decodeDeltaMsgpack decodes the given delta-encoded MessagePack document into the generic value that it was encoded
+// from, as a client would, with integers as int64 values.
+func decodeDeltaMsgpack(data []byte) (any, error) {
+	return decodeDeltaMsgpackValue(msgpack.NewDecoder(bytes.NewReader(data)))
+}
+
+func decodeDeltaMsgpackValue(decoder *msgpack.Decoder) (any, error) {
+	code, err := decoder.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case msgpcode.IsExt(code):
+		extensionType, length, err := decoder.DecodeExtHeader()
+		if err != nil {
+			return nil, err
+		}
+		if extensionType != msgpackExtRecordTable {
+			return nil, fmt.Errorf("unexpected extension type %d outside of a record table", extensionType)
+		}
+		payload := make([]byte, length)
+		if err = decoder.ReadFull(payload); err != nil {
+			return nil, err
+		}
+		return decodeRecordTable(msgpack.NewDecoder(bytes.NewReader(payload)))
+	case msgpcode.IsFixedArray(code) || code == msgpcode.Array16 || code == msgpcode.Array32:
+		length, err := decoder.DecodeArrayLen()
+		if err != nil {
+			return nil, err
+		}
+		list := make([]any, length)
+		for i := range list {
+			if list[i], err = decodeDeltaMsgpackValue(decoder); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case msgpcode.IsFixedMap(code) || code == msgpcode.Map16 || code == msgpcode.Map32:
+		length, err := decoder.DecodeMapLen()
+		if err != nil {
+			return nil, err
+		}
+		object := make(map[string]any)
+		for i := 0; i < length; i++ {
+			key, err := decoder.DecodeString()
+			if err != nil {
+				return nil, err
+			}
+			if object[key], err = decodeDeltaMsgpackValue(decoder); err != nil {
+				return nil, err
+			}
+		}
+		return object, nil
+	}
+	value, err := decoder.DecodeInterfaceLoose()
+	if unsigned, ok := value.(uint64); ok {
+		value = int64(unsigned)
+	}
+	return value, err
+}
+
+// decodeRecordTable decodes the payload of a record table extension into the list of records that it represents.
+func decodeRecordTable(decoder *msgpack.Decoder) ([]any, error) {
+	length, err := decoder.DecodeArrayLen()
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	if err = decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+	records := make([]any, 0, length-1)
+	previous := make([]any, len(fields))
+	for i := 1; i < length; i++ {
+		if _, err = decoder.DecodeArrayLen(); err != nil {
+			return nil, err
+		}
+		record := make(map[string]any)
+		for j, field := range fields {
+			value, err := decodeRecordFieldValue(decoder, previous[j])
+			if err != nil {
+				return nil, err
+			}
+			previous[j] = value
+			if !isAbsent(value) {
+				setJsonPointer(record, field, value)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Placeholder for the value of a field that is absent from a record.
+type absentField struct{}
+
+func isAbsent(value any) bool {
+	_, ok := value.(absentField)
+	return ok
+}
+
+// decodeRecordFieldValue decodes a single field value of a record, given the value of the same field in the preceding
+// record.
+func decodeRecordFieldValue(decoder *msgpack.Decoder, previous any) (any, error) {
+	code, err := decoder.PeekCode()
+	if err != nil {
+		return nil, err
+	}
+	if code == msgpcode.Nil {
+		return previous, decoder.DecodeNil()
+	}
+	if !msgpcode.IsExt(code) {
+		return decodeDeltaMsgpackValue(decoder)
+	}
+	data, err := decoder.DecodeRaw()
+	if err != nil {
+		return nil, err
+	}
+	extensionDecoder := msgpack.NewDecoder(bytes.NewReader(data))
+	extensionType, _, err := extensionDecoder.DecodeExtHeader()
+	if err != nil {
+		return nil, err
+	}
+	switch extensionType {
+	case msgpackExtRecordTable:
+		return decodeDeltaMsgpackValue(msgpack.NewDecoder(bytes.NewReader(data)))
+	case msgpackExtNull:
+		return nil, nil
+	case msgpackExtIntegerDelta:
+		delta, err := extensionDecoder.DecodeInt64()
+		return reflect.ValueOf(previous).Int() + delta, err
+	case msgpackExtAbsent:
+		return absentField{}, nil
+	}
+	return nil, fmt.Errorf("unexpected extension type %d", extensionType)
+}
+
+// setJsonPointer sets the value at the given JSON Pointer within the given object, creating intermediate objects as
+// needed.
+func setJsonPointer(object map[string]any, pointer string, value any) {
+	keys := strings.Split(pointer, "/")[1:]
+	for i, key := range keys {
+		key = strings.ReplaceAll(strings.ReplaceAll(key, "~1", "/"), "~0", "~")
+		if i == len(keys)-1 {
+			object[key] = value
+			return
+		}
+		nested, ok := object[key].(map[string]any)
+		if !ok {
+			nested = make(map[string]any)
+			object[key] = nested
+		}
+		object = nested
+	}
+}
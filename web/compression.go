@@ -0,0 +1,116 @@
+package web
+
+import (
+	"compress/gzip"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// compressResponses wraps the given handler so that JSON and text responses are gzipped for clients that accept it,
+// shrinking the larger status and history documents polled over the field network. Other responses, such as firmware
+// and support bundle downloads that are already compressed, are passed through as they are.
+func compressResponses(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead || !acceptsGzip(r) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		writer := &compressingResponseWriter{ResponseWriter: w}
+		defer writer.close()
+		handler.ServeHTTP(writer, r)
+	})
+}
+
+// acceptsGzip returns true if the given request's Accept-Encoding header allows a gzipped response.
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), "gzip") {
+			continue
+		}
+		if quality, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			value, err := strconv.ParseFloat(quality, 64)
+			return err == nil && value > 0
+		}
+		return true
+	}
+	return false
+}
+
+// isCompressibleResponse returns true if a response with the given status code and headers has a body that is worth
+// compressing and hasn't been encoded already.
+func isCompressibleResponse(statusCode int, header http.Header) bool {
+	switch statusCode {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+		return false
+	}
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(mediaType, "text/") || mediaType == mediaTypeJson || mediaType == "application/javascript"
+}
+
+// compressingResponseWriter is a response writer that gzips the body if the response turns out to be compressible
+// once its headers are written.
+type compressingResponseWriter struct {
+	http.ResponseWriter
+	isHeaderWritten bool
+
+	// Writer compressing the body, or nil if it is being passed through.
+	gzipWriter *gzip.Writer
+}
+
+func (writer *compressingResponseWriter) WriteHeader(statusCode int) {
+	if writer.isHeaderWritten {
+		writer.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	writer.isHeaderWritten = true
+	header := writer.Header()
+	if isCompressibleResponse(statusCode, header) {
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", "gzip")
+		header.Add("Vary", "Accept-Encoding")
+		writer.gzipWriter = gzip.NewWriter(writer.ResponseWriter)
+	}
+	writer.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (writer *compressingResponseWriter) Write(data []byte) (int, error) {
+	if !writer.isHeaderWritten {
+		writer.WriteHeader(http.StatusOK)
+	}
+	if writer.gzipWriter != nil {
+		return writer.gzipWriter.Write(data)
+	}
+	return writer.ResponseWriter.Write(data)
+}
+
+// Flush sends what has been compressed so far to the client, so that streaming endpoints keep working behind the
+// wrapper.
+func (writer *compressingResponseWriter) Flush() {
+	if writer.gzipWriter != nil {
+		_ = writer.gzipWriter.Flush()
+	}
+	if flusher, ok := writer.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying response writer, for use by http.ResponseController.
+func (writer *compressingResponseWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+// close finishes the compressed body, if the response is being compressed.
+func (writer *compressingResponseWriter) close() {
+	if writer.gzipWriter != nil {
+		_ = writer.gzipWriter.Close()
+	}
+}
@@ -0,0 +1,67 @@
+package web
+
+import (
+	"compress/gzip"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompressResponses(t *testing.T) {
+	body := strings.Repeat(`{"ssid": "1234"}`, 100)
+	handler := compressResponses(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", r.URL.Query().Get("type"))
+		w.Header().Set("Content-Length", "1600")
+		_, _ = w.Write([]byte(body))
+	}))
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", path, nil)
+		request.Header.Set("Accept-Encoding", acceptEncoding)
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// JSON and text responses are compressed for clients that accept it.
+	recorder := get("/status?type=application/json", "deflate, gzip")
+	assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"))
+	assert.Empty(t, recorder.Header().Get("Content-Length"))
+	assert.Less(t, recorder.Body.Len(), len(body))
+	reader, err := gzip.NewReader(recorder.Body)
+	if assert.Nil(t, err) {
+		decompressed, _ := io.ReadAll(reader)
+		assert.Equal(t, body, string(decompressed))
+	}
+	assert.Equal(t, "gzip", get("/logs?type=text/plain", "gzip").Header().Get("Content-Encoding"))
+
+	// Anything else is passed through as it is.
+	for _, recorder = range []*httptest.ResponseRecorder{
+		get("/status?type=application/json", ""),
+		get("/status?type=application/json", "gzip;q=0"),
+		get("/support-bundle?type=application/gzip", "gzip"),
+	} {
+		assert.Empty(t, recorder.Header().Get("Content-Encoding"))
+		assert.Equal(t, "1600", recorder.Header().Get("Content-Length"))
+		assert.Equal(t, body, recorder.Body.String())
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	for acceptEncoding, expected := range map[string]bool{
+		"":                  false,
+		"gzip":              true,
+		"GZIP":              true,
+		"br, gzip;q=0.5":    true,
+		"gzip; q=0":         false,
+		"gzip;q=abc":        false,
+		"deflate, identity": false,
+	} {
+		request := httptest.NewRequest("GET", "/status", nil)
+		request.Header.Set("Accept-Encoding", acceptEncoding)
+		assert.Equal(t, expected, acceptsGzip(request), acceptEncoding)
+	}
+}
@@ -2,36 +2,112 @@ package web
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"github.com/patfair/frc-radio-api/radio"
-	"log"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 // configurationHandler receives a JSON request to configure the radio and adds it to the asynchronous queue.
 func (web *WebServer) configurationHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.isAuthorized(r) {
-		handleWebErr(
-			w,
-			errors.New("not authorized; must provide 'Authorization: Bearer [password]' header"),
-			http.StatusUnauthorized,
-		)
+	origin := web.requestOrigin(r)
+	if !web.checkBaseline(w, r, origin) {
 		return
 	}
 
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(w, r, fmt.Errorf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	body, err = web.radio.GetEventVariables().ResolveConfigurationTemplate(body)
+	if err != nil {
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(w, r, err, http.StatusBadRequest)
+		return
+	}
 	var request radio.ConfigurationRequest
-	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		handleWebErr(w, fmt.Errorf("invalid JSON: %v", err), http.StatusBadRequest)
+	if err = json.Unmarshal(body, &request); err != nil {
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(w, r, fmt.Errorf("invalid JSON: %v", err), http.StatusBadRequest)
 		return
 	}
 	if err := request.Validate(web.radio); err != nil {
-		handleWebErr(w, fmt.Errorf("invalid configuration: %v", err), http.StatusBadRequest)
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(w, r, fmt.Errorf("invalid configuration: %w", err), statusCodeForError(err, http.StatusBadRequest))
 		return
 	}
 
-	log.Printf("Received configuration request: %+v", request)
-	web.radio.ConfigurationRequestChannel <- request
+	id, statusCode, err := web.enqueueConfigurationRequest(r, request)
+	if err != nil {
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(w, r, err, statusCode)
+		return
+	}
+	web.requestOrigins.record(origin, outcomeAccepted)
+	radio.LogWithCorrelationId(
+		requestCorrelationId(r),
+		"Received configuration request %d from %s: %+v",
+		id,
+		origin,
+		request,
+	)
+	w.Header().Set("Location", web.externalPath(fmt.Sprintf("/configuration/requests/%d", id)))
 	w.WriteHeader(http.StatusAccepted)
-	_, _ = fmt.Fprintln(w, "New configuration received and will be applied asynchronously.")
+	_, _ = fmt.Fprintf(w, "New configuration received as request %d and will be applied asynchronously.\n", id)
+	web.writeScheduleMismatches(w, id)
+}
+
+// enqueueConfigurationRequest queues the given configuration request on behalf of the given HTTP request, which may
+// make it conditional on the configuration still being at the revision given in its If-Match header. Returns the
+// identifier of the queued request, or the HTTP status code to respond with if it wasn't queued.
+func (web *WebServer) enqueueConfigurationRequest(
+	r *http.Request, request radio.ConfigurationRequest,
+) (int, int, error) {
+	request = request.WithCorrelationId(requestCorrelationId(r))
+	var id int
+	var err error
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		revision, parseErr := strconv.Atoi(strings.Trim(ifMatch, `"`))
+		if parseErr != nil || revision < 0 {
+			return 0, http.StatusBadRequest, fmt.Errorf(
+				"invalid If-Match header: %q (expecting a configuration revision number)", ifMatch,
+			)
+		}
+		id, err = web.radio.EnqueueConfigurationRequestIfRevision(request, revision)
+	} else {
+		id, err = web.radio.EnqueueConfigurationRequest(request)
+	}
+	if err != nil {
+		return 0, statusCodeForError(err, http.StatusServiceUnavailable), err
+	}
+	return id, 0, nil
+}
+
+// writeScheduleMismatches adds a warning to the response for each way in which the configuration request with the given
+// identifier disagreed with the event schedule when it was queued.
+func (web *WebServer) writeScheduleMismatches(w http.ResponseWriter, id int) {
+	record, _ := web.radio.GetConfigurationRequest(id)
+	for _, mismatch := range record.ScheduleMismatches {
+		_, _ = fmt.Fprintf(w, "Warning: %s.\n", mismatch)
+	}
+}
+
+// checkBaseline rejects the request from the given origin and returns false if the radio can't currently be configured
+// because its wireless configuration doesn't have the expected layout.
+func (web *WebServer) checkBaseline(w http.ResponseWriter, r *http.Request, origin string) bool {
+	if problems := web.radio.BaselineProblems; len(problems) > 0 {
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(
+			w,
+			r,
+			fmt.Errorf("radio cannot be configured until its baseline is fixed: %s", strings.Join(problems, "; ")),
+			http.StatusServiceUnavailable,
+		)
+		return false
+	}
+	return true
 }
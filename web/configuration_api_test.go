@@ -25,13 +25,14 @@ func TestWeb_configurationHandler(t *testing.T) {
 		"/configuration", `{"stationConfigurations": {"blue1": {"ssid": "254", "wpaKey": "12345678"}}}`,
 	)
 	assert.Equal(t, 202, recorder.Code)
-	assert.Contains(t, recorder.Body.String(), "configuration received")
+	assert.Contains(t, recorder.Body.String(), "configuration received as request 1")
+	assert.Equal(t, "/configuration/requests/1", recorder.Header().Get("Location"))
 	if assert.Equal(t, 1, len(ap.ConfigurationRequestChannel)) {
 		request := <-ap.ConfigurationRequestChannel
 		assert.Equal(t, 0, request.Channel)
 		assert.Equal(t, 1, len(request.StationConfigurations))
 		assert.Equal(
-			t, radio.StationConfiguration{Ssid: "254", WpaKey: "12345678"}, request.StationConfigurations["blue1"],
+			t, &radio.StationConfiguration{Ssid: "254", WpaKey: "12345678"}, request.StationConfigurations["blue1"],
 		)
 	}
 
@@ -61,22 +62,22 @@ func TestWeb_configurationHandler(t *testing.T) {
 		assert.Equal(t, "20MHz", request.ChannelBandwidth)
 		assert.Equal(t, 6, len(request.StationConfigurations))
 		assert.Equal(
-			t, radio.StationConfiguration{Ssid: "9991", WpaKey: "11111111"}, request.StationConfigurations["red1"],
+			t, &radio.StationConfiguration{Ssid: "9991", WpaKey: "11111111"}, request.StationConfigurations["red1"],
 		)
 		assert.Equal(
-			t, radio.StationConfiguration{Ssid: "9992", WpaKey: "22222222"}, request.StationConfigurations["red2"],
+			t, &radio.StationConfiguration{Ssid: "9992", WpaKey: "22222222"}, request.StationConfigurations["red2"],
 		)
 		assert.Equal(
-			t, radio.StationConfiguration{Ssid: "9993", WpaKey: "33333333"}, request.StationConfigurations["red3"],
+			t, &radio.StationConfiguration{Ssid: "9993", WpaKey: "33333333"}, request.StationConfigurations["red3"],
 		)
 		assert.Equal(
-			t, radio.StationConfiguration{Ssid: "9994", WpaKey: "44444444"}, request.StationConfigurations["blue1"],
+			t, &radio.StationConfiguration{Ssid: "9994", WpaKey: "44444444"}, request.StationConfigurations["blue1"],
 		)
 		assert.Equal(
-			t, radio.StationConfiguration{Ssid: "9995", WpaKey: "55555555"}, request.StationConfigurations["blue2"],
+			t, &radio.StationConfiguration{Ssid: "9995", WpaKey: "55555555"}, request.StationConfigurations["blue2"],
 		)
 		assert.Equal(
-			t, radio.StationConfiguration{Ssid: "9996", WpaKey: "66666666"}, request.StationConfigurations["blue3"],
+			t, &radio.StationConfiguration{Ssid: "9996", WpaKey: "66666666"}, request.StationConfigurations["blue3"],
 		)
 	}
 }
@@ -119,3 +120,99 @@ func TestWeb_configurationHandlerAuthorization(t *testing.T) {
 	)
 	assert.Equal(t, 202, recorder.Code)
 }
+
+func TestWeb_configurationHandlerIfMatch(t *testing.T) {
+	ap := radio.NewRadio()
+	ap.Type = radio.TypeVividHosting
+	web := NewWebServer(ap)
+	body := `{"stationConfigurations": {"blue1": {"ssid": "254", "wpaKey": "12345678"}}}`
+
+	recorder := web.postHttpResponseWithHeaders("/configuration", body, map[string]string{"If-Match": "0"})
+	assert.Equal(t, 202, recorder.Code)
+	assert.Equal(t, 1, ap.ConfigurationRevision)
+	recorder = web.getHttpResponse("/status")
+	assert.Contains(t, recorder.Body.String(), `"configurationRevision": 1`)
+
+	// A write based on a stale read is rejected.
+	recorder = web.postHttpResponseWithHeaders("/configuration", body, map[string]string{"If-Match": "0"})
+	assert.Equal(t, 409, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "configuration has changed since it was read")
+	recorder = web.patchHttpResponseWithHeaders(
+		"/configuration", `[{"op": "replace", "path": "/channel", "value": 149}]`, map[string]string{"If-Match": "0"},
+	)
+	assert.Equal(t, 409, recorder.Code)
+	assert.Equal(t, 1, len(ap.ConfigurationRequestChannel))
+
+	// The revision may be given as a quoted entity tag.
+	recorder = web.postHttpResponseWithHeaders("/configuration", body, map[string]string{"If-Match": `"1"`})
+	assert.Equal(t, 202, recorder.Code)
+	assert.Equal(t, 2, ap.ConfigurationRevision)
+
+	recorder = web.postHttpResponseWithHeaders("/configuration", body, map[string]string{"If-Match": "*"})
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(
+		t, recorder.Body.String(), `invalid If-Match header: "*" (expecting a configuration revision number)`,
+	)
+	assert.Equal(t, 2, len(ap.ConfigurationRequestChannel))
+}
+
+func TestWeb_configurationHandlerMisconfiguredBaseline(t *testing.T) {
+	ap := radio.NewRadio()
+	ap.BaselineProblems = []string{"expected at least 7 wifi-iface sections but found 3"}
+	web := NewWebServer(ap)
+
+	recorder := web.postHttpResponse(
+		"/configuration", `{"stationConfigurations": {"blue1": {"ssid": "254", "wpaKey": "12345678"}}}`,
+	)
+	assert.Equal(t, 503, recorder.Code)
+	assert.Contains(
+		t,
+		recorder.Body.String(),
+		"radio cannot be configured until its baseline is fixed: expected at least 7 wifi-iface sections but found 3",
+	)
+	assert.Equal(t, 0, len(ap.ConfigurationRequestChannel))
+
+	recorder = web.patchHttpResponse("/configuration", `[{"op": "replace", "path": "/channel", "value": 149}]`)
+	assert.Equal(t, 503, recorder.Code)
+	assert.Equal(t, 0, len(ap.ConfigurationRequestChannel))
+}
+
+func TestWeb_configurationHandlerTemplate(t *testing.T) {
+	ap := radio.NewRadio()
+	ap.Type = radio.TypeVividHosting
+	web := NewWebServer(ap)
+	body := `{"stationConfigurations": {"red1": {"ssid": "{{.EventCode}}F{{.FieldNumber}}", "wpaKey": "12345678"}}}`
+
+	// The variables must be set on the radio before a templated request can be applied.
+	recorder := web.postHttpResponse("/configuration", body)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "unable to resolve configuration template")
+	assert.Equal(t, 0, len(ap.ConfigurationRequestChannel))
+
+	settings := ap.GetSettings()
+	settings.EventVariables = radio.EventVariables{EventCode: "2024CASJ", FieldNumber: 2}
+	ap.SetSettings(settings)
+	recorder = web.postHttpResponse("/configuration", body)
+	assert.Equal(t, 202, recorder.Code)
+	if assert.Equal(t, 1, len(ap.ConfigurationRequestChannel)) {
+		request := <-ap.ConfigurationRequestChannel
+		assert.Equal(
+			t, &radio.StationConfiguration{Ssid: "2024CASJF2", WpaKey: "12345678"}, request.StationConfigurations["red1"],
+		)
+	}
+}
+
+func TestWeb_correlationIdInConfigurationRequest(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+
+	recorder := web.postHttpResponseWithHeaders(
+		"/configuration", `{"channel": 149}`, map[string]string{"X-Request-Id": "fms-match-42"},
+	)
+	assert.Equal(t, 202, recorder.Code)
+	<-ap.ConfigurationRequestChannel
+	record, ok := ap.GetConfigurationRequest(1)
+	if assert.True(t, ok) {
+		assert.Equal(t, "fms-match-42", record.CorrelationId)
+	}
+}
@@ -0,0 +1,78 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"net/http"
+)
+
+// configurationPatchHandler receives an RFC 6902 JSON Patch to apply against the effective configuration of the radio
+// and adds a request for just the resulting changes to the asynchronous queue.
+func (web *WebServer) configurationPatchHandler(w http.ResponseWriter, r *http.Request) {
+	origin := web.requestOrigin(r)
+	if !web.checkBaseline(w, r, origin) {
+		return
+	}
+
+	var operations []jsonPatchOperation
+	if err := json.NewDecoder(r.Body).Decode(&operations); err != nil {
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(w, r, fmt.Errorf("invalid JSON patch: %v", err), http.StatusBadRequest)
+		return
+	}
+	currentBytes, err := json.Marshal(web.radio.EffectiveConfiguration())
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	patchedBytes, err := applyJsonPatch(currentBytes, operations)
+	if err != nil {
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(w, r, fmt.Errorf("failed to apply JSON patch: %v", err), http.StatusUnprocessableEntity)
+		return
+	}
+	var desired radio.ConfigurationRequest
+	decoder := json.NewDecoder(bytes.NewReader(patchedBytes))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&desired); err != nil {
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(w, r, fmt.Errorf("invalid patched configuration: %v", err), http.StatusUnprocessableEntity)
+		return
+	}
+
+	request := web.radio.ConfigurationChanges(desired)
+	if request.IsEmpty() {
+		web.requestOrigins.record(origin, outcomeAccepted)
+		_, _ = fmt.Fprintln(w, "Patch results in no configuration changes.")
+		return
+	}
+	if err = request.Validate(web.radio); err != nil {
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(w, r, fmt.Errorf("invalid configuration: %w", err), statusCodeForError(err, http.StatusBadRequest))
+		return
+	}
+
+	id, statusCode, err := web.enqueueConfigurationRequest(r, request)
+	if err != nil {
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(w, r, err, statusCode)
+		return
+	}
+	web.requestOrigins.record(origin, outcomeAccepted)
+	radio.LogWithCorrelationId(
+		requestCorrelationId(r),
+		"Received configuration patch as request %d from %s: %+v",
+		id,
+		origin,
+		request,
+	)
+	w.Header().Set("Location", web.externalPath(fmt.Sprintf("/configuration/requests/%d", id)))
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprintf(w, "Configuration patch received as request %d and will be applied asynchronously.\n", id)
+	web.writeScheduleMismatches(w, id)
+}
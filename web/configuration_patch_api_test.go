@@ -0,0 +1,91 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_configurationPatchHandler(t *testing.T) {
+	ap := radio.NewRadio()
+	ap.Type = radio.TypeLinksys
+	ap.Channel = 149
+	ap.StationStatuses["red2"] = &radio.NetworkStatus{Ssid: "254"}
+	web := NewWebServer(ap)
+
+	// Patch that doesn't change anything.
+	recorder := web.patchHttpResponse("/configuration", `[{"op":"test","path":"/channel","value":149}]`)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "no configuration changes")
+	assert.Equal(t, 0, len(ap.ConfigurationRequestChannel))
+
+	// Configuring a single station and removing another.
+	recorder = web.patchHttpResponse(
+		"/configuration",
+		`[
+			{"op":"replace","path":"/stationConfigurations/blue3","value":{"ssid":"1114","wpaKey":"abcdefgh"}},
+			{"op":"remove","path":"/stationConfigurations/red2"}
+		]`,
+	)
+	assert.Equal(t, 202, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "patch received as request 1")
+	assert.Equal(t, "/configuration/requests/1", recorder.Header().Get("Location"))
+	if assert.Equal(t, 1, len(ap.ConfigurationRequestChannel)) {
+		request := <-ap.ConfigurationRequestChannel
+		assert.Equal(t, 0, request.Channel)
+		assert.True(t, request.PreserveOmittedStations)
+		assert.Equal(
+			t,
+			map[string]*radio.StationConfiguration{"red2": nil, "blue3": {Ssid: "1114", WpaKey: "abcdefgh"}},
+			request.StationConfigurations,
+		)
+	}
+
+	// Changing the channel.
+	recorder = web.patchHttpResponse("/configuration", `[{"op":"replace","path":"/channel","value":36}]`)
+	assert.Equal(t, 202, recorder.Code)
+	if assert.Equal(t, 1, len(ap.ConfigurationRequestChannel)) {
+		request := <-ap.ConfigurationRequestChannel
+		assert.Equal(t, 36, request.Channel)
+		assert.Nil(t, request.StationConfigurations)
+	}
+}
+
+func TestWeb_configurationPatchHandlerErrors(t *testing.T) {
+	ap := radio.NewRadio()
+	ap.Type = radio.TypeLinksys
+	ap.Channel = 149
+	web := NewWebServer(ap)
+
+	recorder := web.patchHttpResponse("/configuration", "{blorpy}")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid JSON patch")
+
+	recorder = web.patchHttpResponse("/configuration", `[{"op":"test","path":"/channel","value":36}]`)
+	assert.Equal(t, 422, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "failed to apply JSON patch: operation 0 (test /channel): test failed")
+
+	recorder = web.patchHttpResponse("/configuration", `[{"op":"add","path":"/blorpy","value":1}]`)
+	assert.Equal(t, 422, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid patched configuration")
+
+	recorder = web.patchHttpResponse(
+		"/configuration",
+		`[{"op":"add","path":"/stationConfigurations/red4","value":{"ssid":"1","wpaKey":"12345678"}}]`,
+	)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid station: red4")
+
+	recorder = web.patchHttpResponse("/configuration", `[{"op":"replace","path":"/channel","value":37}]`)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid channel for TypeLinksys: 37")
+	assert.Equal(t, 0, len(ap.ConfigurationRequestChannel))
+
+	web.password = "mypassword"
+	recorder = web.patchHttpResponse("/configuration", `[{"op":"replace","path":"/channel","value":36}]`)
+	assert.Equal(t, 401, recorder.Code)
+	assert.Equal(t, 0, len(ap.ConfigurationRequestChannel))
+}
@@ -0,0 +1,78 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+)
+
+// configurationRequestsHandler returns the progress and outcome of each recently queued configuration request, subject
+// to the common pagination and filtering parameters. Records don't say which stations a request changed, so they can't
+// be filtered by station.
+func (web *WebServer) configurationRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	query, err := parseListQuery(r)
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if query.station != "" {
+		handleWebErr(w, r, errors.New("configuration requests can't be filtered by station"), http.StatusBadRequest)
+		return
+	}
+	var entries []listEntry
+	for _, record := range web.radio.GetConfigurationRequests() {
+		entries = append(entries, listEntry{seq: int64(record.Id), at: record.SubmittedAt.Wallclock, item: record})
+	}
+
+	jsonData, err := json.MarshalIndent(query.render(entries), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
+
+// configurationRequestHandler returns the progress or outcome of the queued configuration request with the given ID.
+func (web *WebServer) configurationRequestHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid configuration request ID: %s", mux.Vars(r)["id"]), http.StatusBadRequest)
+		return
+	}
+	record, ok := web.radio.GetConfigurationRequest(id)
+	if !ok {
+		handleWebErr(w, r, fmt.Errorf("no configuration request with ID %d", id), http.StatusNotFound)
+		return
+	}
+
+	jsonData, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
+
+// configurationRequestCancelHandler cancels the queued configuration request with the given ID before it is applied.
+func (web *WebServer) configurationRequestCancelHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid configuration request ID: %s", mux.Vars(r)["id"]), http.StatusBadRequest)
+		return
+	}
+	if _, ok := web.radio.GetConfigurationRequest(id); !ok {
+		handleWebErr(w, r, fmt.Errorf("no configuration request with ID %d", id), http.StatusNotFound)
+		return
+	}
+	if err = web.radio.CancelConfigurationRequest(id); err != nil {
+		handleWebErr(w, r, err, http.StatusConflict)
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "Configuration request %d cancelled.\n", id)
+}
@@ -0,0 +1,93 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_configurationRequestsHandlers(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+	id1, _ := ap.EnqueueConfigurationRequest(radio.ConfigurationRequest{})
+	id2, _ := ap.EnqueueConfigurationRequest(radio.ConfigurationRequest{})
+
+	recorder := web.getHttpResponse("/configuration/requests")
+	assert.Equal(t, 200, recorder.Code)
+	var records []radio.ConfigurationRequestRecord
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &records))
+	if assert.Equal(t, 2, len(records)) {
+		assert.Equal(t, id1, records[0].Id)
+		assert.Equal(t, id2, records[1].Id)
+	}
+
+	// Pages of records are wrapped in an envelope with a cursor for the next page.
+	recorder = web.getHttpResponse("/configuration/requests?limit=1")
+	assert.Equal(t, 200, recorder.Code)
+	var page struct {
+		Items      []radio.ConfigurationRequestRecord `json:"items"`
+		NextCursor string                             `json:"nextCursor"`
+	}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	if assert.Equal(t, 1, len(page.Items)) {
+		assert.Equal(t, id1, page.Items[0].Id)
+	}
+	assert.Equal(t, "1", page.NextCursor)
+	recorder = web.getHttpResponse("/configuration/requests?limit=1&cursor=" + page.NextCursor)
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	if assert.Equal(t, 1, len(page.Items)) {
+		assert.Equal(t, id2, page.Items[0].Id)
+	}
+	assert.Equal(t, "", page.NextCursor)
+	recorder = web.getHttpResponse("/configuration/requests?to=2000-01-01T00:00:00Z")
+	assert.Equal(t, "{\n  \"items\": [],\n  \"nextCursor\": \"\"\n}", recorder.Body.String())
+	recorder = web.getHttpResponse("/configuration/requests?station=red1")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "configuration requests can't be filtered by station")
+	recorder = web.getHttpResponse("/configuration/requests?limit=-1")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid limit: -1 (expecting 1-1000)")
+
+	recorder = web.getHttpResponse("/configuration/requests/2")
+	assert.Equal(t, 200, recorder.Code)
+	var record radio.ConfigurationRequestRecord
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &record))
+	assert.Equal(t, id2, record.Id)
+	assert.Equal(t, "PENDING", string(record.State))
+
+	recorder = web.getHttpResponse("/configuration/requests/3")
+	assert.Equal(t, 404, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "no configuration request with ID 3")
+	recorder = web.getHttpResponse("/configuration/requests/latest")
+	assert.Equal(t, 400, recorder.Code)
+
+	// Cancel a pending request.
+	recorder = web.deleteHttpResponseWithHeaders("/configuration/requests/2", nil)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Configuration request 2 cancelled.")
+	record, _ = ap.GetConfigurationRequest(id2)
+	assert.Equal(t, "CANCELLED", string(record.State))
+	recorder = web.deleteHttpResponseWithHeaders("/configuration/requests/2", nil)
+	assert.Equal(t, 409, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "cannot be cancelled since it is CANCELLED")
+	recorder = web.deleteHttpResponseWithHeaders("/configuration/requests/3", nil)
+	assert.Equal(t, 404, recorder.Code)
+}
+
+func TestWeb_configurationRequestsHandlersUnauthorized(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+	web.password = "mypassword"
+	id, _ := ap.EnqueueConfigurationRequest(radio.ConfigurationRequest{})
+
+	recorder := web.getHttpResponse("/configuration/requests")
+	assert.Equal(t, 401, recorder.Code)
+	recorder = web.getHttpResponse("/configuration/requests/1")
+	assert.Equal(t, 401, recorder.Code)
+	recorder = web.deleteHttpResponseWithHeaders("/configuration/requests/1", nil)
+	assert.Equal(t, 401, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "not authorized")
+	record, _ := ap.GetConfigurationRequest(id)
+	assert.Equal(t, "PENDING", string(record.State))
+}
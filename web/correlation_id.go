@@ -0,0 +1,80 @@
+package web
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+)
+
+// Header in which a client may supply its own correlation ID for a request, and in which the ID in use is returned.
+const correlationIdHeader = "X-Request-Id"
+
+// Pattern that a client-supplied correlation ID must match to be adopted; anything else is replaced with a generated
+// ID so that arbitrary text can't be injected into the logs.
+var correlationIdRe = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// correlationIdContextKey is the key under which the correlation ID of a request is stored in its context.
+type correlationIdContextKey struct{}
+
+// assignCorrelationIds wraps the given handler so that every request carries a correlation ID, which is returned in
+// the response headers and prefixes the log lines about the request.
+func (web *WebServer) assignCorrelationIds(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		correlationId := r.Header.Get(correlationIdHeader)
+		if !correlationIdRe.MatchString(correlationId) {
+			correlationId = newCorrelationId()
+		}
+		w.Header().Set(correlationIdHeader, correlationId)
+		r = r.WithContext(context.WithValue(r.Context(), correlationIdContextKey{}, correlationId))
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// newCorrelationId returns a random correlation ID for a request that didn't supply one.
+func newCorrelationId() string {
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(idBytes)
+}
+
+// requestCorrelationId returns the correlation ID assigned to the given request, or an empty string if it has none.
+func requestCorrelationId(r *http.Request) string {
+	correlationId, _ := r.Context().Value(correlationIdContextKey{}).(string)
+	return correlationId
+}
+
+// statusRecordingResponseWriter is a response writer that notes the status code of the response for logging.
+type statusRecordingResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+
+	// Whether the response has been started, after which its status code can no longer be changed.
+	isHeaderWritten bool
+}
+
+func (writer *statusRecordingResponseWriter) WriteHeader(statusCode int) {
+	writer.statusCode = statusCode
+	writer.isHeaderWritten = true
+	writer.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (writer *statusRecordingResponseWriter) Write(data []byte) (int, error) {
+	writer.isHeaderWritten = true
+	return writer.ResponseWriter.Write(data)
+}
+
+// Flush sends any buffered data to the client, so that streaming endpoints keep working behind the wrapper.
+func (writer *statusRecordingResponseWriter) Flush() {
+	if flusher, ok := writer.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying response writer, for use by http.ResponseController.
+func (writer *statusRecordingResponseWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
@@ -0,0 +1,58 @@
+package web
+
+import (
+	"bytes"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestWeb_assignCorrelationIds(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	var logOutput bytes.Buffer
+	log.SetOutput(&logOutput)
+	defer log.SetOutput(os.Stderr)
+
+	// Each request is assigned its own ID if it doesn't supply one.
+	recorder := web.getHttpResponse("/health")
+	assert.Equal(t, 200, recorder.Code)
+	correlationId := recorder.Header().Get("X-Request-Id")
+	assert.Regexp(t, "^[0-9a-f]{16}$", correlationId)
+	assert.NotEqual(t, correlationId, web.getHttpResponse("/health").Header().Get("X-Request-Id"))
+	assert.Empty(t, logOutput.String())
+
+	// A well-formed ID supplied by the client is adopted and prefixes the log lines about the request.
+	recorder = web.getHttpResponseWithHeaders("/nonexistent", map[string]string{"X-Request-Id": "fms-match-42"})
+	assert.Equal(t, 404, recorder.Code)
+	assert.Equal(t, "fms-match-42", recorder.Header().Get("X-Request-Id"))
+	assert.Contains(t, logOutput.String(), "[request fms-match-42] GET /nonexistent from unknown: 404 in ")
+
+	// A malformed ID is replaced so that it can't be used to inject text into the logs.
+	recorder = web.getHttpResponseWithHeaders("/health", map[string]string{"X-Request-Id": "bad id\nforged line"})
+	assert.Regexp(t, "^[0-9a-f]{16}$", recorder.Header().Get("X-Request-Id"))
+
+	// Errors are logged with the ID, and requests that change something are logged along with their outcome.
+	logOutput.Reset()
+	web.password = "mypassword"
+	recorder = web.postHttpResponseWithHeaders("/configuration", "{}", map[string]string{"X-Request-Id": "abc123"})
+	assert.Equal(t, 401, recorder.Code)
+	assert.Equal(t, "abc123", recorder.Header().Get("X-Request-Id"))
+	assert.Contains(t, logOutput.String(), "[request abc123] HTTP request error 401: not authorized")
+	assert.Contains(t, logOutput.String(), "[request abc123] POST /configuration from unknown: 401 in ")
+}
+
+func TestStatusRecordingResponseWriter(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	writer := &statusRecordingResponseWriter{ResponseWriter: recorder, statusCode: http.StatusOK}
+	_, _ = writer.Write([]byte("OK"))
+	assert.Equal(t, http.StatusOK, writer.statusCode)
+	writer.WriteHeader(http.StatusTeapot)
+	assert.Equal(t, http.StatusTeapot, writer.statusCode)
+	writer.Flush()
+	assert.True(t, recorder.Flushed)
+	assert.Equal(t, recorder, writer.Unwrap())
+}
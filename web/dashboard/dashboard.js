@@ -0,0 +1,331 @@
+// Client-side logic for the access point dashboard. Polls the /status endpoint, renders per-station graphs from the
+// recorded monitoring history followed by the samples gathered since the page was opened, and submits guarded
+// configuration requests.
+"use strict";
+
+const stations = ["red1", "red2", "red3", "blue1", "blue2", "blue3"];
+
+// How frequently to poll the status endpoint.
+const pollIntervalMs = 2000;
+
+// Maximum number of samples to retain per station for graphing.
+const maxSamples = 150;
+
+// Session storage key under which the API password or token is kept.
+const passwordStorageKey = "frcRadioApiPassword";
+
+const history = {};
+stations.forEach(station => history[station] = []);
+let latestStatus = null;
+let pendingRequest = null;
+
+// Returns the headers to send with each API request, including the stored credentials if there are any.
+function apiHeaders() {
+	const headers = {"Accept": "application/json"};
+	const password = sessionStorage.getItem(passwordStorageKey);
+	if (password) {
+		headers["Authorization"] = "Bearer " + password;
+	}
+	return headers;
+}
+
+// Shows or hides the sign-in form.
+function setAuthRequired(required) {
+	document.getElementById("authSection").hidden = !required;
+}
+
+// Fetches the current status and updates the page with it.
+async function pollStatus() {
+	try {
+		const response = await fetch("status", {headers: apiHeaders()});
+		if (response.status === 401) {
+			setAuthRequired(true);
+			return;
+		}
+		setAuthRequired(false);
+		if (!response.ok) {
+			showBanner("banner", "Error fetching status: " + (await response.text()), "error");
+			return;
+		}
+		latestStatus = await response.json();
+		hideBanner("banner");
+		recordSamples(latestStatus);
+		renderStatus(latestStatus);
+	} catch (error) {
+		showBanner("banner", "Unable to reach the radio: " + error, "error");
+	}
+}
+
+// Seeds the graphs with the monitoring history recorded by the radio, so that they aren't empty when the page is first
+// opened. Does nothing if samples have already been gathered or the history can't be fetched.
+async function loadHistory() {
+	if (history[stations[0]].length > 0) {
+		return;
+	}
+	try {
+		const response = await fetch("status/history", {headers: apiHeaders()});
+		if (!response.ok) {
+			return;
+		}
+		const monitoringSamples = await response.json();
+		monitoringSamples.slice(-maxSamples).forEach(monitoringSample => {
+			const time = Date.parse(monitoringSample.monitoredAt.wallclock);
+			stations.forEach(station => appendSample(station, time, (monitoringSample.networks || {})[station]));
+		});
+	} catch (error) {
+		// The graphs just start out empty; any connectivity problem is reported by the status poll.
+	}
+}
+
+// Appends the signal-to-noise ratio and bandwidth of each station to its history.
+function recordSamples(status) {
+	const now = Date.now();
+	stations.forEach(station => appendSample(station, now, (status.stationStatuses || {})[station]));
+}
+
+// Appends a sample of the given network status, or of an unlinked station if it is absent, to the history of the given
+// station, discarding the oldest sample if the history is full.
+function appendSample(station, time, networkStatus) {
+	const samples = history[station];
+	samples.push({
+		time: time,
+		snr: networkStatus && networkStatus.isLinked ? networkStatus.signalNoiseRatio : null,
+		bandwidth: networkStatus && networkStatus.isLinked ? networkStatus.bandwidthUsedMbps : null,
+	});
+	if (samples.length > maxSamples) {
+		samples.splice(0, samples.length - maxSamples);
+	}
+}
+
+// Updates the summary and station cards from the given status.
+function renderStatus(status) {
+	const badge = document.getElementById("radioStatus");
+	badge.textContent = status.status;
+	badge.className = "badge " + status.status.toLowerCase();
+	document.getElementById("channel").textContent = status.channel;
+	document.getElementById("channelBandwidth").textContent = status.channelBandwidth;
+	document.getElementById("version").textContent = status.version;
+	document.getElementById("matchLock").textContent = isMatchLockHeld(status) ?
+		"Held until " + new Date(status.matchLock.expiresAt).toLocaleTimeString() : "Not held";
+	if (status.monitoredAt) {
+		document.getElementById("monitoredAt").textContent = new Date(status.monitoredAt).toLocaleTimeString();
+	}
+	if (status.baselineProblems && status.baselineProblems.length > 0) {
+		showBanner("banner", "Baseline configuration problems: " + status.baselineProblems.join("; "), "error");
+	}
+
+	const container = document.getElementById("stations");
+	stations.forEach(station => {
+		let card = document.getElementById("station-" + station);
+		if (!card) {
+			card = createStationCard(station);
+			container.appendChild(card);
+		}
+		const networkStatus = (status.stationStatuses || {})[station];
+		const details = card.querySelector(".details");
+		if (!networkStatus) {
+			details.textContent = "Not configured";
+		} else {
+			const linked = networkStatus.isLinked ? "Linked" : "Not linked";
+			details.textContent = networkStatus.ssid + " — " + linked;
+			if (networkStatus.isLinked) {
+				details.textContent += " — SNR " + networkStatus.signalNoiseRatio + " dB, " +
+					networkStatus.bandwidthUsedMbps.toFixed(2) + " Mbps";
+			}
+		}
+		drawGraph(card.querySelector("canvas"), history[station]);
+	});
+}
+
+// Creates the card displaying the status and graph for the given station.
+function createStationCard(station) {
+	const card = document.createElement("div");
+	card.id = "station-" + station;
+	card.className = "station " + (station.startsWith("red") ? "red" : "blue");
+	const heading = document.createElement("h3");
+	heading.textContent = station.charAt(0).toUpperCase() + station.slice(1, -1) + " " + station.slice(-1);
+	const details = document.createElement("div");
+	details.className = "details";
+	const legend = document.createElement("div");
+	legend.className = "note";
+	legend.innerHTML = '<span class="legend-snr">&#9632; SNR (dB)</span> ' +
+		'<span class="legend-bandwidth">&#9632; Bandwidth (Mbps)</span>';
+	const canvas = document.createElement("canvas");
+	card.append(heading, details, canvas, legend);
+	return card;
+}
+
+// Draws the signal-to-noise ratio and bandwidth series of the given samples, each scaled to its own maximum.
+function drawGraph(canvas, samples) {
+	const width = canvas.clientWidth;
+	const height = canvas.clientHeight;
+	canvas.width = width * window.devicePixelRatio;
+	canvas.height = height * window.devicePixelRatio;
+	const context = canvas.getContext("2d");
+	context.scale(window.devicePixelRatio, window.devicePixelRatio);
+	context.clearRect(0, 0, width, height);
+	context.strokeStyle = "#dee2e6";
+	context.strokeRect(0, 0, width, height);
+
+	const drawSeries = (field, color, minimumScale) => {
+		const scale = Math.max(minimumScale, ...samples.map(sample => sample[field] || 0));
+		context.strokeStyle = color;
+		context.lineWidth = 1.5;
+		context.beginPath();
+		let drawing = false;
+		samples.forEach((sample, i) => {
+			if (sample[field] === null) {
+				drawing = false;
+				return;
+			}
+			const x = width * i / (maxSamples - 1);
+			const y = height - 2 - (height - 4) * sample[field] / scale;
+			if (drawing) {
+				context.lineTo(x, y);
+			} else {
+				context.moveTo(x, y);
+				drawing = true;
+			}
+		});
+		context.stroke();
+	};
+	drawSeries("snr", "#198754", 50);
+	drawSeries("bandwidth", "#6f42c1", 1);
+}
+
+// Returns true if the FMS currently holds the match lock according to the given status.
+function isMatchLockHeld(status) {
+	return status && status.matchLock && status.matchLock.isHeld;
+}
+
+// Shows the given message in the banner with the given element ID.
+function showBanner(id, message, type) {
+	const banner = document.getElementById(id);
+	banner.textContent = message;
+	banner.className = "banner " + (type || "");
+	banner.hidden = false;
+}
+
+// Hides the banner with the given element ID.
+function hideBanner(id) {
+	document.getElementById(id).hidden = true;
+}
+
+// Adds a row to the configuration form for each station.
+function createStationConfigRows() {
+	const body = document.getElementById("stationConfigRows");
+	stations.forEach(station => {
+		const row = document.createElement("tr");
+		row.innerHTML = "<td>" + station + "</td>" +
+			'<td><input type="text" maxlength="14" id="ssid-' + station + '"></td>' +
+			'<td><input type="password" maxlength="16" id="wpaKey-' + station + '"></td>' +
+			'<td><input type="checkbox" id="clear-' + station + '"></td>';
+		body.appendChild(row);
+	});
+}
+
+// Builds a configuration request from the form, returning null if nothing would change.
+function buildConfigurationRequest() {
+	const request = {};
+	const channel = parseInt(document.getElementById("configChannel").value, 10);
+	if (channel > 0) {
+		request.channel = channel;
+	}
+	const stationConfigurations = {};
+	stations.forEach(station => {
+		const ssid = document.getElementById("ssid-" + station).value.trim();
+		const wpaKey = document.getElementById("wpaKey-" + station).value;
+		if (document.getElementById("clear-" + station).checked) {
+			stationConfigurations[station] = null;
+		} else if (ssid !== "" || wpaKey !== "") {
+			stationConfigurations[station] = {ssid: ssid, wpaKey: wpaKey};
+		}
+	});
+	if (Object.keys(stationConfigurations).length > 0) {
+		request.stationConfigurations = stationConfigurations;
+		request.preserveOmittedStations = true;
+	}
+	return Object.keys(request).length > 0 ? request : null;
+}
+
+// Validates the form and shows the resulting request for confirmation before anything is sent.
+function reviewConfiguration(event) {
+	event.preventDefault();
+	hideBanner("configurationResult");
+	if (isMatchLockHeld(latestStatus)) {
+		showBanner("configurationResult", "A match is in progress; configuration is disabled until it ends.", "error");
+		return;
+	}
+	pendingRequest = buildConfigurationRequest();
+	if (pendingRequest === null) {
+		showBanner("configurationResult", "No changes entered.", "error");
+		return;
+	}
+
+	// Mask the WPA keys in the preview so that they aren't left on screen.
+	const preview = JSON.parse(JSON.stringify(pendingRequest));
+	Object.values(preview.stationConfigurations || {}).forEach(config => {
+		if (config) {
+			config.wpaKey = "*".repeat(config.wpaKey.length);
+		}
+	});
+	document.getElementById("configurationJson").textContent = JSON.stringify(preview, null, 2);
+	document.getElementById("configurationConfirm").checked = false;
+	document.getElementById("configurationPreview").hidden = false;
+	document.getElementById("configurationReview").hidden = true;
+}
+
+// Hides the confirmation step and discards the pending request.
+function cancelConfiguration() {
+	pendingRequest = null;
+	document.getElementById("configurationPreview").hidden = true;
+	document.getElementById("configurationReview").hidden = false;
+}
+
+// Sends the confirmed configuration request to the radio.
+async function sendConfiguration() {
+	if (!document.getElementById("configurationConfirm").checked) {
+		showBanner("configurationResult", "Confirm the change before applying it.", "error");
+		return;
+	}
+	if (isMatchLockHeld(latestStatus)) {
+		showBanner("configurationResult", "A match is in progress; configuration is disabled until it ends.", "error");
+		cancelConfiguration();
+		return;
+	}
+	const headers = apiHeaders();
+	headers["Content-Type"] = "application/json";
+	try {
+		const response = await fetch("configuration", {
+			method: "POST",
+			headers: headers,
+			body: JSON.stringify(pendingRequest),
+		});
+		const message = await response.text();
+		if (response.status === 401) {
+			setAuthRequired(true);
+		}
+		showBanner("configurationResult", message, response.ok ? "success" : "error");
+		if (response.ok) {
+			document.getElementById("configurationForm").reset();
+		}
+	} catch (error) {
+		showBanner("configurationResult", "Unable to reach the radio: " + error, "error");
+	}
+	cancelConfiguration();
+}
+
+document.getElementById("authForm").addEventListener("submit", event => {
+	event.preventDefault();
+	sessionStorage.setItem(passwordStorageKey, document.getElementById("password").value);
+	document.getElementById("password").value = "";
+	loadHistory().finally(pollStatus);
+});
+document.getElementById("configurationForm").addEventListener("submit", reviewConfiguration);
+document.getElementById("configurationCancel").addEventListener("click", cancelConfiguration);
+document.getElementById("configurationSend").addEventListener("click", sendConfiguration);
+createStationConfigRows();
+loadHistory().finally(() => {
+	pollStatus();
+	setInterval(pollStatus, pollIntervalMs);
+});
@@ -0,0 +1,33 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// Static assets for the browser dashboard served at the root URL.
+//
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+// dashboardAssetHandler serves the dashboard's static assets from under the /dashboard/ path. The assets themselves
+// contain no radio data, so they are served without authorization; the dashboard provides credentials when it calls
+// the API.
+func dashboardAssetHandler() http.Handler {
+	return http.FileServer(http.FS(dashboardFiles))
+}
+
+// dashboardPageHandler serves the dashboard's single page.
+func (web *WebServer) dashboardPageHandler(w http.ResponseWriter, r *http.Request) {
+	indexContents, err := fs.ReadFile(dashboardFiles, "dashboard/index.html")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(indexContents)
+}
@@ -0,0 +1,42 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"testing"
+
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeb_dashboardPageHandler(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+
+	recorder := web.getHttpResponse("/")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "text/html; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "</html>")
+	assert.Contains(t, recorder.Body.String(), `src="dashboard/dashboard.js"`)
+
+	// Check that the page is served without a password so that it can prompt for one.
+	web.password = "mypassword"
+	recorder = web.getHttpResponse("/")
+	assert.Equal(t, 200, recorder.Code)
+}
+
+func TestWeb_dashboardAssetHandler(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+
+	recorder := web.getHttpResponse("/dashboard/dashboard.js")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Content-Type"), "javascript")
+	assert.Contains(t, recorder.Body.String(), "function pollStatus()")
+
+	recorder = web.getHttpResponse("/dashboard/dashboard.css")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Content-Type"), "text/css")
+
+	recorder = web.getHttpResponse("/dashboard/missing.js")
+	assert.Equal(t, 404, recorder.Code)
+}
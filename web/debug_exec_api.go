@@ -0,0 +1,47 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"net/http"
+	"strings"
+)
+
+// debugExecHandler runs one of the allowed read-only diagnostic commands on the radio and returns its output, so that
+// remote support can investigate without SSH access to the radio.
+func (web *WebServer) debugExecHandler(w http.ResponseWriter, r *http.Request) {
+	var command radio.DebugCommand
+	if err := json.NewDecoder(r.Body).Decode(&command); err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := command.Validate(); err != nil {
+		handleWebErr(w, r, err, http.StatusBadRequest)
+		return
+	}
+	radio.LogWithCorrelationId(
+		requestCorrelationId(r),
+		"Running debug command: %s %s",
+		command.Command,
+		strings.Join(command.Args, " "),
+	)
+	result, err := radio.RunDebugCommand(command)
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(jsonData)
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,46 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_debugExecHandler(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+
+	// The command is run even if it isn't present, with the failure given in the result.
+	recorder := web.postHttpResponse("/debug/exec", `{"command": "iw", "args": ["reg", "get"]}`)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	var result radio.DebugCommandResult
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &result))
+	assert.Equal(t, "iw", result.Command)
+	assert.Equal(t, []string{"reg", "get"}, result.Args)
+
+	recorder = web.postHttpResponse("/debug/exec", `{"command": "iw", "args": ["dev", "wlan0", "scan"]}`)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(
+		t,
+		recorder.Body.String(),
+		`invalid arguments for iw: "dev wlan0 scan" (expecting one of its allowed read-only forms)`,
+	)
+
+	recorder = web.postHttpResponse("/debug/exec", `{"command": "reboot"}`)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `invalid command: "reboot"`)
+
+	recorder = web.postHttpResponse("/debug/exec", "{")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid JSON")
+}
+
+func TestWeb_debugExecHandlerAuthorization(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.password = "mypassword"
+
+	recorder := web.postHttpResponse("/debug/exec", `{"command": "iw", "args": ["dev"]}`)
+	assert.Equal(t, 401, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "not authorized")
+}
@@ -0,0 +1,15 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// clearAllHandler takes all the team networks off the air at once, ahead of any queued configuration requests.
+func (web *WebServer) clearAllHandler(w http.ResponseWriter, r *http.Request) {
+	cancelledCount := web.radio.ClearAllNetworks()
+	_, _ = fmt.Fprintf(w, "All team networks disabled; cancelled %d queued configuration requests.\n", cancelledCount)
+}
@@ -0,0 +1,61 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_clearAllHandler(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+	id, err := ap.EnqueueConfigurationRequest(radio.ConfigurationRequest{Channel: 149})
+	assert.Nil(t, err)
+
+	recorder := web.postHttpResponse("/configuration/clear-all", "")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "All team networks disabled; cancelled 1 queued configuration requests.")
+	record, ok := ap.GetConfigurationRequest(id)
+	assert.True(t, ok)
+	assert.Equal(t, "CANCELLED", string(record.State))
+
+	recorder = web.getHttpResponse("/status")
+	assert.Equal(t, 200, recorder.Code)
+	var status struct {
+		EmergencyStop radio.EmergencyStopStatus `json:"emergencyStop"`
+	}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+	assert.True(t, status.EmergencyStop.IsActive)
+	assert.NotNil(t, status.EmergencyStop.ActivatedAt)
+	assert.Equal(t, 1, status.EmergencyStop.CancelledRequestCount)
+}
+
+func TestWeb_clearAllHandlerAuthorization(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+	web.password = "mypassword"
+
+	recorder := web.postHttpResponse("/configuration/clear-all", "")
+	assert.Equal(t, 401, recorder.Code)
+	assert.False(t, ap.EmergencyStop.IsActive)
+
+	recorder = web.postHttpResponseWithHeaders(
+		"/configuration/clear-all", "", map[string]string{"Authorization": "Bearer mypassword"},
+	)
+	assert.Equal(t, 200, recorder.Code)
+	assert.True(t, ap.EmergencyStop.IsActive)
+}
+
+func TestWeb_clearAllHandlerDuringMaintenance(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+	assert.Nil(t, ap.SetMaintenanceMode(true, "replacing antennas"))
+
+	recorder := web.postHttpResponse("/configuration/clear-all", "")
+	assert.Equal(t, 200, recorder.Code)
+	assert.True(t, ap.EmergencyStop.IsActive)
+}
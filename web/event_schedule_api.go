@@ -0,0 +1,72 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+)
+
+// eventScheduleHandler returns the event schedule that configuration requests are checked against.
+func (web *WebServer) eventScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	jsonData, err := json.MarshalIndent(web.radio.GetEventSchedule(), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
+
+// eventScheduleUpdateHandler replaces the event schedule and persists it so that it survives a restart.
+func (web *WebServer) eventScheduleUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	var schedule radio.EventSchedule
+	if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := schedule.Validate(); err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid event schedule: %v", err), http.StatusBadRequest)
+		return
+	}
+	scheduleJson, err := json.MarshalIndent(schedule, "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	if err = os.WriteFile(web.eventScheduleFilePath, scheduleJson, 0600); err != nil {
+		handleWebErr(w, r, fmt.Errorf("failed to save event schedule: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err = web.radio.SetEventSchedule(schedule); err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	_, _ = fmt.Fprintln(w, "Event schedule saved and will be checked against new configuration requests.")
+}
+
+// loadEventSchedule restores the event schedule persisted on disk, if there is one.
+func (web *WebServer) loadEventSchedule() {
+	scheduleJson, err := os.ReadFile(web.eventScheduleFilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	var schedule radio.EventSchedule
+	if err == nil {
+		err = json.Unmarshal(scheduleJson, &schedule)
+	}
+	if err == nil {
+		err = web.radio.SetEventSchedule(schedule)
+	}
+	if err != nil {
+		log.Printf("Error loading event schedule; configuration requests won't be checked against it: %v", err)
+	}
+}
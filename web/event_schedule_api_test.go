@@ -0,0 +1,97 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWeb_eventScheduleHandlers(t *testing.T) {
+	ap := radio.NewRadio()
+	ap.Type = radio.TypeVividHosting
+	web := NewWebServer(ap)
+	web.eventScheduleFilePath = filepath.Join(t.TempDir(), "event-schedule.json")
+
+	recorder := web.getHttpResponse("/schedule")
+	assert.Equal(t, 200, recorder.Code)
+	var schedule radio.EventSchedule
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &schedule))
+	assert.Equal(t, radio.EventSchedule{}, schedule)
+
+	recorder = web.postHttpResponse(
+		"/schedule", `{"matches": [{"slot": "Q12", "teams": {"red1": 254, "blue1": 1114}}, {"slot": "Q13"}]}`,
+	)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Event schedule saved")
+	expectedSchedule := radio.EventSchedule{
+		Matches: []radio.ScheduledMatch{
+			{Slot: "Q12", Teams: map[string]int{"red1": 254, "blue1": 1114}},
+			{Slot: "Q13"},
+		},
+	}
+	assert.Equal(t, expectedSchedule, ap.GetEventSchedule())
+
+	recorder = web.getHttpResponse("/schedule")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &schedule))
+	assert.Equal(t, expectedSchedule, schedule)
+
+	// Configuration requests for a scheduled match are accepted, but mismatches are flagged in the response and in the
+	// request's record.
+	recorder = web.postHttpResponse(
+		"/configuration",
+		`{"matchSlot": "Q12", "stationConfigurations": {"red1": {"ssid": "1114", "wpaKey": "12345678"}}, `+
+			`"preserveOmittedStations": true}`,
+	)
+	assert.Equal(t, 202, recorder.Code)
+	assert.Contains(
+		t,
+		recorder.Body.String(),
+		"Warning: station red1 has team 1114 but the schedule has team 254 (team 1114 is scheduled on blue1).",
+	)
+	record, ok := ap.GetConfigurationRequest(1)
+	if assert.True(t, ok) {
+		assert.Equal(t, 1, len(record.ScheduleMismatches))
+	}
+	recorder = web.postHttpResponse(
+		"/configuration",
+		`{"matchSlot": "Q12", "stationConfigurations": {"red1": {"ssid": "254", "wpaKey": "12345678"}}, `+
+			`"preserveOmittedStations": true}`,
+	)
+	assert.Equal(t, 202, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "Warning")
+
+	// The schedule is persisted and restored on startup.
+	otherAp := radio.NewRadio()
+	otherWeb := NewWebServer(otherAp)
+	otherWeb.eventScheduleFilePath = web.eventScheduleFilePath
+	otherWeb.loadPersistedState()
+	assert.Equal(t, expectedSchedule, otherAp.GetEventSchedule())
+
+	// Invalid schedules are rejected without being saved.
+	recorder = web.postHttpResponse("/schedule", `{"matches": [{"slot": "Q1", "teams": {"red1": 0}}]}`)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(
+		t,
+		recorder.Body.String(),
+		"invalid event schedule: invalid team number for red1 in match Q1: 0 (expecting 1-25499)",
+	)
+	recorder = web.postHttpResponse("/schedule", "{blorpy}")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid JSON")
+	assert.Equal(t, expectedSchedule, ap.GetEventSchedule())
+
+	// A corrupt file leaves the checks disabled.
+	assert.Nil(t, os.WriteFile(web.eventScheduleFilePath, []byte("not JSON"), 0600))
+	otherAp = radio.NewRadio()
+	otherWeb = NewWebServer(otherAp)
+	otherWeb.eventScheduleFilePath = web.eventScheduleFilePath
+	otherWeb.loadPersistedState()
+	assert.Equal(t, radio.EventSchedule{}, otherAp.GetEventSchedule())
+}
@@ -0,0 +1,51 @@
+// This file is only included in builds with fault injection enabled, which are meant for integration testing and must
+// never be deployed at an event.
+//go:build faultinjection
+
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"net/http"
+)
+
+// addFaultInjectionRoutes sets up the endpoints for arming and clearing injected faults.
+func addFaultInjectionRoutes(routes *routeTable, web *WebServer) {
+	routes.handle("GET", "/debug/faults", roleAdmin, web.faultsHandler)
+	routes.handle("POST", "/debug/faults", roleAdmin, web.faultInjectHandler)
+	routes.handle("DELETE", "/debug/faults", roleAdmin, web.faultsClearHandler)
+}
+
+// faultsHandler returns a JSON list of the faults that are currently armed.
+func (web *WebServer) faultsHandler(w http.ResponseWriter, r *http.Request) {
+	jsonData, err := json.MarshalIndent(radio.GetFaults(), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
+
+// faultInjectHandler arms the requested fault, to be injected the next time the corresponding operation is performed.
+func (web *WebServer) faultInjectHandler(w http.ResponseWriter, r *http.Request) {
+	var fault radio.Fault
+	if err := json.NewDecoder(r.Body).Decode(&fault); err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := radio.InjectFault(fault); err != nil {
+		handleWebErr(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	_, _ = fmt.Fprintln(w, "Fault armed.")
+}
+
+// faultsClearHandler disarms all faults.
+func (web *WebServer) faultsClearHandler(w http.ResponseWriter, r *http.Request) {
+	radio.ClearFaults()
+	_, _ = fmt.Fprintln(w, "Faults cleared.")
+}
@@ -0,0 +1,53 @@
+// This file is only included in builds with fault injection enabled, which are meant for integration testing and must
+// never be deployed at an event.
+//go:build faultinjection
+
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_faultsHandlers(t *testing.T) {
+	t.Cleanup(radio.ClearFaults)
+	web := NewWebServer(radio.NewRadio())
+	web.password = "mypassword"
+	headers := map[string]string{"Authorization": "Bearer mypassword"}
+
+	recorder := web.getHttpResponse("/debug/faults")
+	assert.Equal(t, 401, recorder.Code)
+	recorder = web.postHttpResponse("/debug/faults", `{"type": "UCI_COMMIT_FAILURE"}`)
+	assert.Equal(t, 401, recorder.Code)
+	recorder = web.deleteHttpResponseWithHeaders("/debug/faults", nil)
+	assert.Equal(t, 401, recorder.Code)
+
+	recorder = web.postHttpResponseWithHeaders("/debug/faults", "not JSON", headers)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid JSON")
+	recorder = web.postHttpResponseWithHeaders("/debug/faults", `{"type": "BLUE_SMOKE"}`, headers)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid fault type: BLUE_SMOKE")
+
+	recorder = web.postHttpResponseWithHeaders(
+		"/debug/faults", `{"type": "WIFI_RELOAD_TIMEOUT", "count": 1, "delayMs": 5000}`, headers,
+	)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "Fault armed.\n", recorder.Body.String())
+	recorder = web.getHttpResponseWithHeaders("/debug/faults", headers)
+	assert.Equal(t, 200, recorder.Code)
+	var faults []radio.Fault
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &faults))
+	if assert.Equal(t, 1, len(faults)) {
+		assert.Equal(t, "WIFI_RELOAD_TIMEOUT", string(faults[0].Type))
+		assert.Equal(t, 1, faults[0].Count)
+		assert.Equal(t, 5000, faults[0].DelayMs)
+	}
+
+	recorder = web.deleteHttpResponseWithHeaders("/debug/faults", headers)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "Faults cleared.\n", recorder.Body.String())
+	assert.Empty(t, radio.GetFaults())
+}
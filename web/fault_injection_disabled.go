@@ -0,0 +1,6 @@
+//go:build !faultinjection
+
+package web
+
+// addFaultInjectionRoutes does nothing since fault injection is only available in builds made for integration testing.
+func addFaultInjectionRoutes(routes *routeTable, web *WebServer) {}
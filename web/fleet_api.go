@@ -0,0 +1,125 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Maximum time to wait for a fleet member to respond with its status.
+const fleetRequestTimeout = 3 * time.Second
+
+// fleetMemberStatus represents the outcome of fetching the status of a single fleet member.
+type fleetMemberStatus struct {
+	// Name of the fleet member, as configured in the settings.
+	Name string `json:"name"`
+
+	// Base URL of the fleet member's API.
+	Url string `json:"url"`
+
+	// Whether the status was fetched successfully.
+	IsReachable bool `json:"isReachable"`
+
+	// Description of why the status couldn't be fetched, or an empty string if it was.
+	Error string `json:"error"`
+
+	// Time taken to fetch the status, in milliseconds.
+	LatencyMs int64 `json:"latencyMs"`
+
+	// Status payload returned by the fleet member, or null if it couldn't be fetched.
+	Status json.RawMessage `json:"status"`
+}
+
+// fleetStatus represents the merged status of this radio and its fleet members.
+type fleetStatus struct {
+	// Status of this radio.
+	Self json.RawMessage `json:"self"`
+
+	// Status of each configured fleet member, in the order they are configured.
+	Members []fleetMemberStatus `json:"members"`
+}
+
+// fleetStatusHandler concurrently fetches the status of each configured fleet member and returns it merged with the
+// status of this radio.
+func (web *WebServer) fleetStatusHandler(w http.ResponseWriter, r *http.Request) {
+	selfJson, _, err := web.getStatusJson()
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	members := web.radio.GetSettings().FleetMembers
+	status := fleetStatus{Self: selfJson, Members: fetchFleetStatuses(r.Context(), members)}
+	jsonData, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
+
+// fetchFleetStatuses fetches the status of each of the given fleet members in parallel.
+func fetchFleetStatuses(ctx context.Context, members []radio.FleetMember) []fleetMemberStatus {
+	statuses := make([]fleetMemberStatus, len(members))
+	var waitGroup sync.WaitGroup
+	for i, member := range members {
+		waitGroup.Add(1)
+		go func(i int, member radio.FleetMember) {
+			defer waitGroup.Done()
+			statuses[i] = fetchFleetStatus(ctx, member)
+		}(i, member)
+	}
+	waitGroup.Wait()
+	return statuses
+}
+
+// fetchFleetStatus fetches the status of the given fleet member.
+func fetchFleetStatus(ctx context.Context, member radio.FleetMember) fleetMemberStatus {
+	status := fleetMemberStatus{Name: member.Name, Url: member.Url}
+	startTime := time.Now()
+	body, err := getFleetMemberStatusJson(ctx, member)
+	status.LatencyMs = time.Since(startTime).Milliseconds()
+	if err != nil {
+		status.Error = err.Error()
+	} else {
+		status.IsReachable = true
+		status.Status = body
+	}
+	return status
+}
+
+// getFleetMemberStatusJson performs the HTTP request for the status of the given fleet member and returns the body.
+func getFleetMemberStatusJson(ctx context.Context, member radio.FleetMember) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, fleetRequestTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(member.Url, "/")+"/status", nil)
+	if err != nil {
+		return nil, err
+	}
+	if member.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+member.Token)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response %d: %s", response.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if !json.Valid(body) {
+		return nil, errors.New("invalid JSON in status response")
+	}
+	return body, nil
+}
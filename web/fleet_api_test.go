@@ -0,0 +1,74 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWeb_fleetStatusHandler(t *testing.T) {
+	robotServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/status" || r.Header.Get("Authorization") != "Bearer robotpassword" {
+			http.Error(w, "not authorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"teamNumber": 254}`)
+	}))
+	defer robotServer.Close()
+	brokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "not JSON")
+	}))
+	defer brokenServer.Close()
+
+	r := radio.NewRadio()
+	settings := r.GetSettings()
+	settings.FleetMembers = []radio.FleetMember{
+		{Name: "robot", Url: robotServer.URL + "/", Token: "robotpassword"},
+		{Name: "wrong-token", Url: robotServer.URL, Token: "bogus"},
+		{Name: "broken", Url: brokenServer.URL},
+		{Name: "offline", Url: "http://127.0.0.1:1"},
+	}
+	r.SetSettings(settings)
+	web := NewWebServer(r)
+
+	recorder := web.getHttpResponse("/fleet/status")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	var status fleetStatus
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+	selfJson, _, _ := web.getStatusJson()
+	assert.JSONEq(t, string(selfJson), string(status.Self))
+	if assert.Equal(t, 4, len(status.Members)) {
+		assert.Equal(t, "robot", status.Members[0].Name)
+		assert.True(t, status.Members[0].IsReachable)
+		assert.Equal(t, "", status.Members[0].Error)
+		assert.JSONEq(t, `{"teamNumber": 254}`, string(status.Members[0].Status))
+
+		assert.False(t, status.Members[1].IsReachable)
+		assert.Equal(t, "unexpected response 401: not authorized", status.Members[1].Error)
+		assert.Equal(t, "null", string(status.Members[1].Status))
+
+		assert.False(t, status.Members[2].IsReachable)
+		assert.Equal(t, "invalid JSON in status response", status.Members[2].Error)
+
+		assert.Equal(t, "offline", status.Members[3].Name)
+		assert.False(t, status.Members[3].IsReachable)
+		assert.NotEqual(t, "", status.Members[3].Error)
+	}
+}
+
+func TestWeb_fleetStatusHandlerAuthorization(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.password = "mypassword"
+
+	recorder := web.getHttpResponse("/fleet/status")
+	assert.Equal(t, 401, recorder.Code)
+
+	recorder = web.getHttpResponseWithHeaders("/fleet/status", map[string]string{"Authorization": "Bearer mypassword"})
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"members": []`)
+}
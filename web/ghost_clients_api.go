@@ -0,0 +1,16 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ghostClientsRemoveHandler schedules the removal of any stale associations on the next monitoring poll.
+func (web *WebServer) ghostClientsRemoveHandler(w http.ResponseWriter, r *http.Request) {
+	web.radio.RequestGhostClientRemoval()
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprintln(w, "Ghost clients will be removed on the next monitoring poll.")
+}
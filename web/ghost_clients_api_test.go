@@ -0,0 +1,24 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_ghostClientsRemoveHandler(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.password = "mypassword"
+
+	recorder := web.postHttpResponse("/clients/ghosts/remove", "")
+	assert.Equal(t, 401, recorder.Code)
+
+	recorder = web.postHttpResponseWithHeaders(
+		"/clients/ghosts/remove", "", map[string]string{"Authorization": "Bearer mypassword"},
+	)
+	assert.Equal(t, 202, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Ghost clients will be removed")
+}
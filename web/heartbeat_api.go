@@ -0,0 +1,15 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// heartbeatHandler receives a keepalive from the FMS indicating that it is still in control of the access point.
+func (web *WebServer) heartbeatHandler(w http.ResponseWriter, r *http.Request) {
+	web.radio.RecordHeartbeat()
+	_, _ = fmt.Fprintln(w, "Heartbeat received.")
+}
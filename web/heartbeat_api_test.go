@@ -0,0 +1,37 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_heartbeatHandler(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+
+	assert.Nil(t, ap.Heartbeat.LastReceived)
+	assert.Contains(t, web.getHttpResponse("/status").Body.String(), `"lastReceived": null`)
+	recorder := web.postHttpResponse("/heartbeat", "")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Heartbeat received")
+	assert.NotNil(t, ap.Heartbeat.LastReceived)
+	assert.Contains(t, web.getHttpResponse("/status").Body.String(), `"lastReceived": {`)
+}
+
+func TestWeb_heartbeatHandlerAuthorization(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+	web.password = "mypassword"
+
+	recorder := web.postHttpResponse("/heartbeat", "")
+	assert.Equal(t, 401, recorder.Code)
+	assert.Nil(t, ap.Heartbeat.LastReceived)
+
+	recorder = web.postHttpResponseWithHeaders("/heartbeat", "", map[string]string{"Authorization": "Bearer mypassword"})
+	assert.Equal(t, 200, recorder.Code)
+	assert.NotNil(t, ap.Heartbeat.LastReceived)
+}
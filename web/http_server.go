@@ -0,0 +1,250 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Path of the firmware upload endpoint, which is exempt from the general request body size limit along with the API
+// binary upgrade endpoint.
+const firmwareUploadPath = "/firmware"
+
+// newHttpServer creates the HTTP server listening on the given address, applying the configured timeouts.
+func (web *WebServer) newHttpServer(listenAddress string) *http.Server {
+	return &http.Server{
+		Addr:         listenAddress,
+		Handler:      web.newRouter(),
+		ReadTimeout:  time.Duration(web.httpSettings.ReadTimeoutSec) * time.Second,
+		WriteTimeout: time.Duration(web.httpSettings.WriteTimeoutSec) * time.Second,
+	}
+}
+
+// limitConnections wraps the given listener so that it accepts at most the configured number of concurrent
+// connections, if there is a limit.
+func (web *WebServer) limitConnections(listener net.Listener) net.Listener {
+	if web.httpSettings.MaxConcurrentConnections == 0 {
+		return listener
+	}
+	return &connectionLimitListener{
+		Listener: listener, slots: make(chan struct{}, web.httpSettings.MaxConcurrentConnections),
+	}
+}
+
+// limitRequestBodySize wraps the given handler so that request bodies larger than the configured limit are rejected.
+func (web *WebServer) limitRequestBodySize(handler http.Handler) http.Handler {
+	maxBytes := web.httpSettings.MaxRequestBodyBytes
+	if maxBytes == 0 {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if path := unversionedPath(r); path != firmwareUploadPath && path != apiUpgradePath {
+			if r.ContentLength > maxBytes {
+				err := fmt.Errorf("request body too large (limit is %d bytes)", maxBytes)
+				handleWebErr(w, r, err, http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// applyCorsPolicy wraps the given handler so that browser-based clients on the configured origins may call the API,
+// answering their preflight requests directly.
+func (web *WebServer) applyCorsPolicy(handler http.Handler) http.Handler {
+	if len(web.httpSettings.CorsAllowedOrigins) == 0 {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !web.isCorsOriginAllowed(origin) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Set("Access-Control-Expose-Headers", "Location, "+correlationIdHeader)
+		w.Header().Add("Vary", "Origin")
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
+			w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, "+correlationIdHeader)
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// isCorsOriginAllowed returns true if the given origin is permitted to make cross-origin requests to the API.
+func (web *WebServer) isCorsOriginAllowed(origin string) bool {
+	for _, allowedOrigin := range web.httpSettings.CorsAllowedOrigins {
+		if allowedOrigin == "*" || allowedOrigin == origin {
+			return true
+		}
+	}
+	return false
+}
+
+// serveUnderBasePath wraps the given handler so that it serves the routes under the configured base path, if there is
+// one, stripping it from the request path before routing. Requests outside the base path get a 404.
+func (web *WebServer) serveUnderBasePath(handler http.Handler) http.Handler {
+	basePath := web.httpSettings.BasePath
+	if basePath == "" {
+		return handler
+	}
+	strippedHandler := http.StripPrefix(basePath, handler)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == basePath {
+			http.Redirect(w, r, basePath+"/", http.StatusMovedPermanently)
+			return
+		}
+		if !strings.HasPrefix(r.URL.Path, basePath+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		strippedHandler.ServeHTTP(w, r)
+	})
+}
+
+// externalPath returns the given route path as a client has to request it, under the configured base path.
+func (web *WebServer) externalPath(path string) string {
+	return web.httpSettings.BasePath + path
+}
+
+// forwardedRequest describes how a request reached the API through a trusted reverse proxy.
+type forwardedRequest struct {
+	// IP address of the reverse proxy that the request was received from.
+	proxyAddress string
+
+	// Scheme (i.e. "http" or "https") with which the client made the request to the proxy, or blank if not given.
+	scheme string
+}
+
+// forwardedRequestContextKey is the key under which the forwardedRequest of a request is stored in its context.
+type forwardedRequestContextKey struct{}
+
+// applyForwardedHeaders wraps the given handler so that requests received from a trusted reverse proxy are treated as
+// coming from the client given in the X-Forwarded-For header, with the scheme given in the X-Forwarded-Proto header.
+// The headers are ignored on requests from anywhere else, since any client could set them.
+func (web *WebServer) applyForwardedHeaders(handler http.Handler) http.Handler {
+	trustedNetworks := web.httpSettings.TrustedProxyNetworks()
+	if len(trustedNetworks) == 0 {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxyAddress := remoteIp(r.RemoteAddr)
+		if !isInNetworks(proxyAddress, trustedNetworks) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		forwarded := forwardedRequest{proxyAddress: proxyAddress}
+		proto := strings.ToLower(strings.TrimSpace(strings.Split(r.Header.Get("X-Forwarded-Proto"), ",")[0]))
+		if proto == "http" || proto == "https" {
+			forwarded.scheme = proto
+		}
+		r = r.WithContext(context.WithValue(r.Context(), forwardedRequestContextKey{}, forwarded))
+		clientAddress := forwardedClientAddress(r.Header.Values("X-Forwarded-For"), trustedNetworks)
+		if clientAddress != "" {
+			r.RemoteAddr = clientAddress
+		}
+		if forwarded.scheme != "" {
+			forwardedUrl := *r.URL
+			forwardedUrl.Scheme = forwarded.scheme
+			r.URL = &forwardedUrl
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// forwardedClientAddress returns the IP address of the client that made a request, given the X-Forwarded-For headers
+// of the request. The addresses are read from the right, skipping over those of trusted proxies, since anything to the
+// left of the first untrusted address may have been made up by the client. Returns blank if the headers don't give an
+// address.
+func forwardedClientAddress(headerValues []string, trustedNetworks []*net.IPNet) string {
+	var addresses []string
+	for _, value := range headerValues {
+		for _, address := range strings.Split(value, ",") {
+			if address = strings.TrimSpace(address); address != "" {
+				addresses = append(addresses, address)
+			}
+		}
+	}
+	clientAddress := ""
+	for i := len(addresses) - 1; i >= 0; i-- {
+		address := remoteIp(addresses[i])
+		if net.ParseIP(address) == nil {
+			break
+		}
+		clientAddress = address
+		if !isInNetworks(address, trustedNetworks) {
+			break
+		}
+	}
+	return clientAddress
+}
+
+// remoteIp returns the IP address part of the given address, which may or may not include a port.
+func remoteIp(address string) string {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+}
+
+// isInNetworks returns true if the given IP address is within any of the given networks.
+func isInNetworks(address string, networks []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestForwarding returns how the given request reached the API through a trusted reverse proxy, and false if it
+// was received directly.
+func requestForwarding(r *http.Request) (forwardedRequest, bool) {
+	forwarded, ok := r.Context().Value(forwardedRequestContextKey{}).(forwardedRequest)
+	return forwarded, ok
+}
+
+// connectionLimitListener is a listener that blocks in Accept while the maximum number of connections is open.
+type connectionLimitListener struct {
+	net.Listener
+	slots chan struct{}
+}
+
+// Accept waits for a free connection slot and then for the next connection.
+func (listener *connectionLimitListener) Accept() (net.Conn, error) {
+	listener.slots <- struct{}{}
+	conn, err := listener.Listener.Accept()
+	if err != nil {
+		<-listener.slots
+		return nil, err
+	}
+	return &limitedConn{Conn: conn, release: func() { <-listener.slots }}, nil
+}
+
+// limitedConn is a connection that frees its slot in the connectionLimitListener when it is closed.
+type limitedConn struct {
+	net.Conn
+	releaseOnce sync.Once
+	release     func()
+}
+
+// Close closes the connection and frees its slot, tolerating repeated calls.
+func (conn *limitedConn) Close() error {
+	err := conn.Conn.Close()
+	conn.releaseOnce.Do(conn.release)
+	return err
+}
@@ -0,0 +1,247 @@
+package web
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWebServer_httpSettings(t *testing.T) {
+	ap := radio.NewRadio()
+	settings := ap.GetSettings()
+	settings.HttpServer.ReadTimeoutSec = 5
+	settings.HttpServer.WriteTimeoutSec = 7
+	ap.SetSettings(settings)
+	web := NewWebServer(ap)
+
+	server := web.newHttpServer("127.0.0.1:8081")
+	assert.Equal(t, "127.0.0.1:8081", server.Addr)
+	assert.Equal(t, 5*time.Second, server.ReadTimeout)
+	assert.Equal(t, 7*time.Second, server.WriteTimeout)
+
+	// Check that later changes to the settings don't affect the server once it has been constructed.
+	settings = ap.GetSettings()
+	settings.HttpServer.ReadTimeoutSec = 10
+	ap.SetSettings(settings)
+	assert.Equal(t, 5*time.Second, web.newHttpServer("127.0.0.1:8081").ReadTimeout)
+}
+
+func TestWeb_limitRequestBodySize(t *testing.T) {
+	ap := radio.NewRadio()
+	settings := ap.GetSettings()
+	settings.HttpServer.MaxRequestBodyBytes = 16
+	ap.SetSettings(settings)
+	web := NewWebServer(ap)
+
+	recorder := web.postHttpResponse("/configuration", strings.Repeat("x", 17))
+	assert.Equal(t, 413, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "request body too large (limit is 16 bytes)")
+
+	// Bodies without a declared length are cut off while they are read.
+	recorder = httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/tokens", strings.NewReader(`{"name":"dashboard","role":"READ_ONLY"}`))
+	request.ContentLength = -1
+	web.newRouter().ServeHTTP(recorder, request)
+	assert.Equal(t, 400, recorder.Code)
+
+	// Firmware uploads are exempt.
+	recorder = web.postFileHttpResponse("/firmware", "file", []byte(strings.Repeat("x", 1024)), nil)
+	assert.NotEqual(t, 413, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "too large")
+	recorder = web.postFileHttpResponse("/v1/firmware", "file", []byte(strings.Repeat("x", 1024)), nil)
+	assert.NotEqual(t, 413, recorder.Code)
+
+	// Zero disables the limit.
+	settings = ap.GetSettings()
+	settings.HttpServer.MaxRequestBodyBytes = 0
+	ap.SetSettings(settings)
+	web = NewWebServer(ap)
+	recorder = web.postHttpResponse("/configuration", strings.Repeat("x", 17))
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid character")
+}
+
+func TestWeb_applyCorsPolicy(t *testing.T) {
+	ap := radio.NewRadio()
+	sendRequest := func(web *WebServer, method, origin string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest(method, "/health", nil)
+		request.Header.Set("Origin", origin)
+		if method == http.MethodOptions {
+			request.Header.Set("Access-Control-Request-Method", "GET")
+		}
+		web.newRouter().ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// No origins are allowed by default.
+	web := NewWebServer(ap)
+	recorder := sendRequest(web, "GET", "http://10.0.100.5:8080")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, 405, sendRequest(web, http.MethodOptions, "http://10.0.100.5:8080").Code)
+
+	settings := ap.GetSettings()
+	settings.HttpServer.CorsAllowedOrigins = []string{"http://10.0.100.5:8080"}
+	ap.SetSettings(settings)
+	web = NewWebServer(ap)
+	recorder = sendRequest(web, "GET", "http://10.0.100.5:8080")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "http://10.0.100.5:8080", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Origin", recorder.Header().Get("Vary"))
+	recorder = sendRequest(web, http.MethodOptions, "http://10.0.100.5:8080")
+	assert.Equal(t, 204, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+	assert.Contains(t, recorder.Header().Get("Access-Control-Allow-Methods"), "POST")
+	assert.Equal(t, "", sendRequest(web, "GET", "http://10.0.100.6").Header().Get("Access-Control-Allow-Origin"))
+
+	settings = ap.GetSettings()
+	settings.HttpServer.CorsAllowedOrigins = []string{"*"}
+	ap.SetSettings(settings)
+	web = NewWebServer(ap)
+	recorder = sendRequest(web, "GET", "http://10.0.100.6")
+	assert.Equal(t, "http://10.0.100.6", recorder.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestWeb_limitConnections(t *testing.T) {
+	ap := radio.NewRadio()
+	settings := ap.GetSettings()
+	settings.HttpServer.MaxConcurrentConnections = 1
+	ap.SetSettings(settings)
+	web := NewWebServer(ap)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	limitedListener := web.limitConnections(listener)
+	defer limitedListener.Close()
+
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := limitedListener.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+	for i := 0; i < 2; i++ {
+		client, err := net.Dial("tcp", listener.Addr().String())
+		if assert.Nil(t, err) {
+			defer client.Close()
+		}
+	}
+
+	// Only the first connection is accepted until it is closed.
+	firstConn := <-accepted
+	select {
+	case <-accepted:
+		assert.Fail(t, "second connection accepted while the first was still open")
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.Nil(t, firstConn.Close())
+	_ = firstConn.Close()
+	select {
+	case secondConn := <-accepted:
+		assert.Nil(t, secondConn.Close())
+	case <-time.After(time.Second):
+		assert.Fail(t, "second connection not accepted after the first was closed")
+	}
+
+	// Zero disables the limit.
+	settings = ap.GetSettings()
+	settings.HttpServer.MaxConcurrentConnections = 0
+	ap.SetSettings(settings)
+	assert.Equal(t, listener, NewWebServer(ap).limitConnections(listener))
+}
+
+func TestWeb_serveUnderBasePath(t *testing.T) {
+	ap := radio.NewRadio()
+	settings := ap.GetSettings()
+	settings.HttpServer.BasePath = "/radios/field-ap"
+	ap.SetSettings(settings)
+	web := NewWebServer(ap)
+
+	recorder := web.getHttpResponse("/radios/field-ap/health")
+	assert.Equal(t, 200, recorder.Code)
+
+	// The bare base path is redirected to its root, so that the relative links in the pages resolve under it.
+	recorder = web.getHttpResponse("/radios/field-ap")
+	assert.Equal(t, 301, recorder.Code)
+	assert.Equal(t, "/radios/field-ap/", recorder.Header().Get("Location"))
+
+	// Routes outside the base path aren't served.
+	assert.Equal(t, 404, web.getHttpResponse("/health").Code)
+	assert.Equal(t, 404, web.getHttpResponse("/radios/field-apx/health").Code)
+
+	// Links to new resources point under the base path.
+	assert.Equal(t, "/radios/field-ap/configuration/requests/1", web.externalPath("/configuration/requests/1"))
+	web = NewWebServer(radio.NewRadio())
+	assert.Equal(t, "/configuration/requests/1", web.externalPath("/configuration/requests/1"))
+}
+
+func TestWeb_applyForwardedHeaders(t *testing.T) {
+	ap := radio.NewRadio()
+	settings := ap.GetSettings()
+	settings.HttpServer.TrustedProxies = []string{"10.0.100.9", "192.168.1.0/24"}
+	ap.SetSettings(settings)
+	web := NewWebServer(ap)
+
+	sendRequest := func(remoteAddr string, forwardedFor []string, forwardedProto string) *http.Request {
+		var handledRequest *http.Request
+		handler := web.applyForwardedHeaders(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handledRequest = r
+		}))
+		request, _ := http.NewRequest("GET", "/status", nil)
+		request.RemoteAddr = remoteAddr
+		for _, value := range forwardedFor {
+			request.Header.Add("X-Forwarded-For", value)
+		}
+		if forwardedProto != "" {
+			request.Header.Set("X-Forwarded-Proto", forwardedProto)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+		return handledRequest
+	}
+
+	// Headers from a trusted proxy are honored.
+	request := sendRequest("10.0.100.9:51000", []string{"10.0.100.5"}, "https")
+	assert.Equal(t, "10.0.100.5", request.RemoteAddr)
+	assert.Equal(t, "https", request.URL.Scheme)
+	forwarded, ok := requestForwarding(request)
+	assert.True(t, ok)
+	assert.Equal(t, forwardedRequest{proxyAddress: "10.0.100.9", scheme: "https"}, forwarded)
+	assert.Equal(t, "10.0.100.5", web.requestOrigin(request))
+
+	// Addresses added by a client in front of the proxies are skipped.
+	request = sendRequest("10.0.100.9:51000", []string{"1.2.3.4, 10.0.100.5", "192.168.1.20"}, "")
+	assert.Equal(t, "10.0.100.5", request.RemoteAddr)
+	assert.Equal(t, "", request.URL.Scheme)
+
+	// The proxy itself is used if the header gives no address.
+	request = sendRequest("10.0.100.9:51000", nil, "gopher")
+	assert.Equal(t, "10.0.100.9:51000", request.RemoteAddr)
+	assert.Equal(t, "", request.URL.Scheme)
+	request = sendRequest("10.0.100.9:51000", []string{"unknown"}, "")
+	assert.Equal(t, "10.0.100.9:51000", request.RemoteAddr)
+
+	// Headers from anywhere else are ignored.
+	request = sendRequest("10.0.100.5:51000", []string{"10.0.100.6"}, "https")
+	assert.Equal(t, "10.0.100.5:51000", request.RemoteAddr)
+	assert.Equal(t, "", request.URL.Scheme)
+	_, ok = requestForwarding(request)
+	assert.False(t, ok)
+
+	// No proxies are trusted by default.
+	web = NewWebServer(radio.NewRadio())
+	request = sendRequest("10.0.100.9:51000", []string{"10.0.100.5"}, "https")
+	assert.Equal(t, "10.0.100.9:51000", request.RemoteAddr)
+}
@@ -0,0 +1,52 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"net/http"
+)
+
+// iperfHandler returns a JSON dump of the iperf3 server state and past throughput check results.
+func (web *WebServer) iperfHandler(w http.ResponseWriter, r *http.Request) {
+	jsonData, err := json.MarshalIndent(web.radio.GetIperfStatus(), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(jsonData)
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// iperfStartHandler receives a JSON request to start an iperf3 server on a team station's VLAN.
+func (web *WebServer) iperfStartHandler(w http.ResponseWriter, r *http.Request) {
+	var request radio.IperfRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := web.radio.StartIperfServer(request); err != nil {
+		handleWebErr(w, r, fmt.Errorf("failed to start iperf3 server: %v", err), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprintf(w, "iperf3 server started for station %s.\n", request.Station)
+}
+
+// iperfStopHandler stops the running iperf3 server, if any.
+func (web *WebServer) iperfStopHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.radio.StopIperfServer() {
+		handleWebErr(w, r, errors.New("no iperf3 server is running"), http.StatusConflict)
+		return
+	}
+	_, _ = fmt.Fprintln(w, "iperf3 server stopped.")
+}
@@ -0,0 +1,57 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_iperfHandler(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+
+	recorder := web.getHttpResponse("/iperf")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	var status radio.IperfStatus
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+	assert.False(t, status.IsRunning)
+	assert.Equal(t, []radio.IperfResult{}, status.Results)
+}
+
+func TestWeb_iperfStartAndStopHandlers(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+
+	recorder := web.postHttpResponse("/iperf/start", "not JSON")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid JSON")
+
+	recorder = web.postHttpResponse("/iperf/start", `{"station": "red1"}`)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "station red1 does not have a team assigned")
+
+	recorder = web.postHttpResponse("/iperf/stop", "")
+	assert.Equal(t, 409, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "no iperf3 server is running")
+}
+
+func TestWeb_iperfHandlersAuthorization(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.password = "mypassword"
+
+	recorder := web.getHttpResponse("/iperf")
+	assert.Equal(t, 401, recorder.Code)
+	recorder = web.postHttpResponse("/iperf/start", `{"station": "red1"}`)
+	assert.Equal(t, 401, recorder.Code)
+	recorder = web.postHttpResponse("/iperf/stop", "")
+	assert.Equal(t, 401, recorder.Code)
+
+	headers := map[string]string{"Authorization": "Bearer mypassword"}
+	recorder = web.getHttpResponseWithHeaders("/iperf", headers)
+	assert.Equal(t, 200, recorder.Code)
+	recorder = web.postHttpResponseWithHeaders("/iperf/stop", "", headers)
+	assert.Equal(t, 409, recorder.Code)
+}
@@ -0,0 +1,257 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonPatchOperation represents a single operation within an RFC 6902 JSON Patch document.
+type jsonPatchOperation struct {
+	// Operation to perform: "add", "remove", "replace", "move", "copy", or "test".
+	Op string `json:"op"`
+
+	// JSON Pointer (RFC 6901) to the location in the target document that the operation applies to.
+	Path string `json:"path"`
+
+	// JSON Pointer to the location to move or copy the value from. Only used by "move" and "copy".
+	From string `json:"from"`
+
+	// Value to add, replace, or test against. Not used by "remove", "move", or "copy".
+	Value json.RawMessage `json:"value"`
+}
+
+// applyJsonPatch applies the given RFC 6902 patch operations in order to the given JSON document and returns the
+// patched document. The patch is atomic: if any operation fails, an error is returned and no result is produced.
+func applyJsonPatch(document []byte, operations []jsonPatchOperation) ([]byte, error) {
+	var root any
+	if err := json.Unmarshal(document, &root); err != nil {
+		return nil, fmt.Errorf("invalid target document: %v", err)
+	}
+
+	for i, operation := range operations {
+		var err error
+		root, err = applyJsonPatchOperation(root, operation)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %v", i, operation.Op, operation.Path, err)
+		}
+	}
+	return json.Marshal(root)
+}
+
+// applyJsonPatchOperation applies a single patch operation to the given decoded document and returns the result.
+func applyJsonPatchOperation(root any, operation jsonPatchOperation) (any, error) {
+	path, err := parseJsonPointer(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation.Op {
+	case "add", "replace", "test":
+		if operation.Value == nil {
+			return nil, errors.New("missing value")
+		}
+		var value any
+		if err = json.Unmarshal(operation.Value, &value); err != nil {
+			return nil, fmt.Errorf("invalid value: %v", err)
+		}
+		switch operation.Op {
+		case "add":
+			return jsonPointerAdd(root, path, value)
+		case "replace":
+			if len(path) == 0 {
+				return value, nil
+			}
+			if root, err = jsonPointerRemove(root, path); err != nil {
+				return nil, err
+			}
+			return jsonPointerAdd(root, path, value)
+		default:
+			current, err := jsonPointerGet(root, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, errors.New("test failed")
+			}
+			return root, nil
+		}
+	case "remove":
+		return jsonPointerRemove(root, path)
+	case "move", "copy":
+		from, err := parseJsonPointer(operation.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from: %v", err)
+		}
+		value, err := jsonPointerGet(root, from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from: %v", err)
+		}
+		if operation.Op == "move" {
+			if isJsonPointerPrefix(from, path) && len(from) < len(path) {
+				return nil, errors.New("cannot move a value into one of its own children")
+			}
+			if root, err = jsonPointerRemove(root, from); err != nil {
+				return nil, err
+			}
+		} else {
+			// Round-trip the value so that later operations on the copy don't also affect the original.
+			copiedBytes, _ := json.Marshal(value)
+			_ = json.Unmarshal(copiedBytes, &value)
+		}
+		return jsonPointerAdd(root, path, value)
+	default:
+		return nil, fmt.Errorf("unsupported operation: %q", operation.Op)
+	}
+}
+
+// parseJsonPointer splits the given RFC 6901 JSON Pointer into its unescaped reference tokens.
+func parseJsonPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid path: %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// isJsonPointerPrefix returns true if the given prefix path refers to the given path or one of its ancestors.
+func isJsonPointerPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonArrayIndex parses the given reference token as an index into an array of the given length. The index may be
+// equal to the length only if allowEnd is set, which is the case when adding an element.
+func jsonArrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if allowEnd && token == "-" {
+		return length, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("invalid array index: %q", token)
+	}
+	if index > length || index == length && !allowEnd {
+		return 0, fmt.Errorf("array index out of bounds: %d", index)
+	}
+	return index, nil
+}
+
+// jsonPointerGet returns the value at the given path within the given document.
+func jsonPointerGet(node any, path []string) (any, error) {
+	for _, token := range path {
+		switch typedNode := node.(type) {
+		case map[string]any:
+			value, ok := typedNode[token]
+			if !ok {
+				return nil, fmt.Errorf("path not found: %q", token)
+			}
+			node = value
+		case []any:
+			index, err := jsonArrayIndex(token, len(typedNode), false)
+			if err != nil {
+				return nil, err
+			}
+			node = typedNode[index]
+		default:
+			return nil, fmt.Errorf("path not found: %q", token)
+		}
+	}
+	return node, nil
+}
+
+// jsonPointerAdd returns the given document with the given value added at the given path.
+func jsonPointerAdd(root any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := jsonPointerGet(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch typedParent := parent.(type) {
+	case map[string]any:
+		typedParent[token] = value
+	case []any:
+		index, err := jsonArrayIndex(token, len(typedParent), true)
+		if err != nil {
+			return nil, err
+		}
+		typedParent = append(typedParent, nil)
+		copy(typedParent[index+1:], typedParent[index:])
+		typedParent[index] = value
+		return jsonPointerSet(root, path[:len(path)-1], typedParent)
+	default:
+		return nil, fmt.Errorf("path not found: %q", token)
+	}
+	return root, nil
+}
+
+// jsonPointerRemove returns the given document with the value at the given path removed.
+func jsonPointerRemove(root any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, errors.New("cannot remove the whole document")
+	}
+	parent, err := jsonPointerGet(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch typedParent := parent.(type) {
+	case map[string]any:
+		if _, ok := typedParent[token]; !ok {
+			return nil, fmt.Errorf("path not found: %q", token)
+		}
+		delete(typedParent, token)
+	case []any:
+		index, err := jsonArrayIndex(token, len(typedParent), false)
+		if err != nil {
+			return nil, err
+		}
+		typedParent = append(typedParent[:index], typedParent[index+1:]...)
+		return jsonPointerSet(root, path[:len(path)-1], typedParent)
+	default:
+		return nil, fmt.Errorf("path not found: %q", token)
+	}
+	return root, nil
+}
+
+// jsonPointerSet returns the given document with the existing value at the given path overwritten by the given value.
+// Used to store an array that has grown or shrunk back into its parent.
+func jsonPointerSet(root any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	parent, err := jsonPointerGet(root, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	token := path[len(path)-1]
+	switch typedParent := parent.(type) {
+	case map[string]any:
+		typedParent[token] = value
+	case []any:
+		index, err := jsonArrayIndex(token, len(typedParent), false)
+		if err != nil {
+			return nil, err
+		}
+		typedParent[index] = value
+	}
+	return root, nil
+}
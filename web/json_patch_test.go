@@ -0,0 +1,118 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func applyJsonPatchString(t *testing.T, document, patch string) (string, error) {
+	var operations []jsonPatchOperation
+	if err := json.Unmarshal([]byte(patch), &operations); !assert.Nil(t, err) {
+		return "", err
+	}
+	result, err := applyJsonPatch([]byte(document), operations)
+	return string(result), err
+}
+
+func TestApplyJsonPatch(t *testing.T) {
+	// Examples adapted from RFC 6902 appendix A.
+	result, err := applyJsonPatchString(t, `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"baz":"qux","foo":"bar"}`, result)
+
+	result, err = applyJsonPatchString(
+		t, `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`,
+	)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"foo":["bar","qux","baz"]}`, result)
+
+	result, err = applyJsonPatchString(t, `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":["abc"]}]`)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"foo":["bar",["abc"]]}`, result)
+
+	result, err = applyJsonPatchString(t, `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"foo":"bar"}`, result)
+
+	result, err = applyJsonPatchString(t, `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"foo":["bar","baz"]}`, result)
+
+	result, err = applyJsonPatchString(
+		t, `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`,
+	)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"baz":"boo","foo":"bar"}`, result)
+
+	result, err = applyJsonPatchString(
+		t,
+		`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+		`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+	)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`, result)
+
+	result, err = applyJsonPatchString(
+		t, `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+	)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"foo":["all","cows","eat","grass"]}`, result)
+
+	result, err = applyJsonPatchString(
+		t,
+		`{"foo":{"bar":1}}`,
+		`[{"op":"copy","from":"/foo","path":"/baz"},{"op":"replace","path":"/baz/bar","value":2}]`,
+	)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"foo":{"bar":1},"baz":{"bar":2}}`, result)
+
+	result, err = applyJsonPatchString(
+		t, `{"/":9,"~1":10}`, `[{"op":"test","path":"/~01","value":10},{"op":"remove","path":"/~1"}]`,
+	)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"~1":10}`, result)
+
+	result, err = applyJsonPatchString(t, `{"foo":"bar"}`, `[{"op":"replace","path":"","value":{"baz":1}}]`)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"baz":1}`, result)
+}
+
+func TestApplyJsonPatchErrors(t *testing.T) {
+	_, err := applyJsonPatchString(t, `{"baz":"qux"}`, `[{"op":"test","path":"/baz","value":"bar"}]`)
+	assert.EqualError(t, err, "operation 0 (test /baz): test failed")
+
+	_, err = applyJsonPatchString(t, `{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`)
+	assert.EqualError(t, err, "operation 0 (add /baz/bat): path not found: \"baz\"")
+
+	_, err = applyJsonPatchString(t, `{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`)
+	assert.EqualError(t, err, "operation 0 (remove /baz): path not found: \"baz\"")
+
+	_, err = applyJsonPatchString(t, `{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`)
+	assert.EqualError(t, err, "operation 0 (replace /baz): path not found: \"baz\"")
+
+	_, err = applyJsonPatchString(t, `{"foo":[1]}`, `[{"op":"add","path":"/foo/2","value":1}]`)
+	assert.EqualError(t, err, "operation 0 (add /foo/2): array index out of bounds: 2")
+
+	_, err = applyJsonPatchString(t, `{"foo":[1]}`, `[{"op":"remove","path":"/foo/01"}]`)
+	assert.EqualError(t, err, "operation 0 (remove /foo/01): invalid array index: \"01\"")
+
+	_, err = applyJsonPatchString(t, `{"foo":{"bar":1}}`, `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`)
+	assert.EqualError(t, err, "operation 0 (move /foo/bar/baz): cannot move a value into one of its own children")
+
+	_, err = applyJsonPatchString(t, `{"foo":"bar"}`, `[{"op":"add","path":"/baz"}]`)
+	assert.EqualError(t, err, "operation 0 (add /baz): missing value")
+
+	_, err = applyJsonPatchString(t, `{"foo":"bar"}`, `[{"op":"frob","path":"/foo"}]`)
+	assert.EqualError(t, err, "operation 0 (frob /foo): unsupported operation: \"frob\"")
+
+	_, err = applyJsonPatchString(t, `{"foo":"bar"}`, `[{"op":"remove","path":"foo"}]`)
+	assert.EqualError(t, err, "operation 0 (remove foo): invalid path: \"foo\"")
+
+	// Later failures cause the whole patch to fail.
+	result, err := applyJsonPatchString(
+		t, `{"foo":"bar"}`, `[{"op":"remove","path":"/foo"},{"op":"remove","path":"/foo"}]`,
+	)
+	assert.EqualError(t, err, "operation 1 (remove /foo): path not found: \"foo\"")
+	assert.Equal(t, "", result)
+}
@@ -0,0 +1,74 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package web
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"net/http"
+)
+
+//go:embed kiosk_page.html
+var kioskHtmlContents string
+
+// kioskPageHandler returns the simplified provisioning page served in kiosk mode.
+func (web *WebServer) kioskPageHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.radio.GetSettings().KioskMode {
+		handleWebErr(w, r, errors.New("kiosk mode is not enabled"), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprintln(w, kioskHtmlContents)
+}
+
+// kioskProvisionHandler receives a JSON request giving just a team number and WPA key, or a scanned QR code payload,
+// and queues the standard FRC robot radio configuration for that team.
+func (web *WebServer) kioskProvisionHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.radio.GetSettings().KioskMode {
+		handleWebErr(w, r, errors.New("kiosk mode is not enabled"), http.StatusNotFound)
+		return
+	}
+	origin := web.requestOrigin(r)
+	if !web.checkBaseline(w, r, origin) {
+		return
+	}
+
+	var provisioningRequest radio.KioskProvisioningRequest
+	if err := json.NewDecoder(r.Body).Decode(&provisioningRequest); err != nil {
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(w, r, fmt.Errorf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	request, err := provisioningRequest.ConfigurationRequest()
+	if err == nil {
+		err = request.Validate(web.radio)
+	}
+	if err != nil {
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(w, r, fmt.Errorf("invalid configuration: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	id, err := web.radio.EnqueueConfigurationRequest(request.WithCorrelationId(requestCorrelationId(r)))
+	if err != nil {
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(w, r, err, http.StatusServiceUnavailable)
+		return
+	}
+	web.requestOrigins.record(origin, outcomeAccepted)
+	radio.LogWithCorrelationId(
+		requestCorrelationId(r),
+		"Received kiosk provisioning request %d from %s for team %d",
+		id,
+		origin,
+		request.TeamNumber,
+	)
+	w.Header().Set("Location", web.externalPath(fmt.Sprintf("/configuration/requests/%d", id)))
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprintf(w, "Provisioning for team %d received as request %d.\n", request.TeamNumber, id)
+}
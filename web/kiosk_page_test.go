@@ -0,0 +1,76 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package web
+
+import (
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newKioskTestWebServer() *WebServer {
+	r := &radio.Radio{ConfigurationRequestChannel: make(chan radio.ConfigurationRequest, 2)}
+	settings := r.GetSettings()
+	settings.KioskMode = true
+	r.SetSettings(settings)
+	return &WebServer{radio: r}
+}
+
+func TestWeb_kioskPageHandler(t *testing.T) {
+	web := newKioskTestWebServer()
+	recorder := web.getHttpResponse("/kiosk")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "text/html; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "Robot Radio Provisioning")
+
+	recorder = web.getHttpResponse("/")
+	assert.Equal(t, 302, recorder.Code)
+	assert.Equal(t, "/kiosk", recorder.Header().Get("Location"))
+
+	// The page isn't served outside kiosk mode.
+	web.radio.SetSettings(radio.Settings{})
+	recorder = web.getHttpResponse("/kiosk")
+	assert.Equal(t, 404, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "kiosk mode is not enabled")
+}
+
+func TestWeb_kioskProvisionHandler(t *testing.T) {
+	web := newKioskTestWebServer()
+
+	recorder := web.postHttpResponse("/kiosk/provision", `{"teamNumber":254,"wpaKey":"12345678"}`)
+	assert.Equal(t, 202, recorder.Code)
+	assert.Equal(t, "/configuration/requests/1", recorder.Header().Get("Location"))
+	assert.Contains(t, recorder.Body.String(), "Provisioning for team 254 received as request 1.")
+	if assert.Equal(t, 1, len(web.radio.ConfigurationRequestChannel)) {
+		request := <-web.radio.ConfigurationRequestChannel
+		assert.Equal(t, 254, request.TeamNumber)
+		assert.Equal(t, "12345678", request.WpaKey6)
+		assert.Equal(t, "12345678", request.WpaKey24)
+	}
+
+	recorder = web.postHttpResponse("/kiosk/provision", `{"payload":"FRC:1114:abcdefgh"}`)
+	assert.Equal(t, 202, recorder.Code)
+	if assert.Equal(t, 1, len(web.radio.ConfigurationRequestChannel)) {
+		request := <-web.radio.ConfigurationRequestChannel
+		assert.Equal(t, 1114, request.TeamNumber)
+		assert.Equal(t, "abcdefgh", request.WpaKey6)
+	}
+
+	recorder = web.postHttpResponse("/kiosk/provision", `{"payload":"1114"}`)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid configuration: invalid payload")
+
+	recorder = web.postHttpResponse("/kiosk/provision", `{"teamNumber":254,"wpaKey":"short"}`)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid wpaKey6 length: 5")
+
+	recorder = web.postHttpResponse("/kiosk/provision", "{")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid JSON")
+	assert.Equal(t, 0, len(web.radio.ConfigurationRequestChannel))
+
+	web.radio.SetSettings(radio.Settings{})
+	recorder = web.postHttpResponse("/kiosk/provision", `{"teamNumber":254,"wpaKey":"12345678"}`)
+	assert.Equal(t, 404, recorder.Code)
+}
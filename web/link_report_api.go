@@ -0,0 +1,39 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/patfair/frc-radio-api/radio"
+	"net/http"
+)
+
+// linkTestHandler runs an active test of the link to the robot at the given team station and returns a JSON report of
+// the result.
+func (web *WebServer) linkTestHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := web.radio.RunLinkTest(mux.Vars(r)["station"])
+	if errors.Is(err, radio.ErrLinkTestInProgress) {
+		handleWebErr(w, r, err, http.StatusConflict)
+		return
+	} else if err != nil {
+		handleWebErr(w, r, fmt.Errorf("failed to run link test: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(jsonData)
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,37 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_linkTestHandler(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+
+	recorder := web.postHttpResponse("/stations/green1/link-test", "")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid station: green1")
+
+	recorder = web.postHttpResponse("/stations/red1/link-test", "")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "station red1 does not have a team assigned")
+
+	recorder = web.getHttpResponse("/stations/red1/link-test")
+	assert.Equal(t, 405, recorder.Code)
+}
+
+func TestWeb_linkTestHandlerAuthorization(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.password = "mypassword"
+
+	recorder := web.postHttpResponse("/stations/red1/link-test", "")
+	assert.Equal(t, 401, recorder.Code)
+
+	headers := map[string]string{"Authorization": "Bearer mypassword"}
+	recorder = web.postHttpResponseWithHeaders("/stations/red1/link-test", "", headers)
+	assert.Equal(t, 400, recorder.Code)
+}
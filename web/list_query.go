@@ -0,0 +1,143 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// Number of items returned per page if the client doesn't specify a limit.
+	defaultListPageSize = 100
+
+	// Largest number of items that may be requested per page.
+	maxListPageSize = 1000
+)
+
+// listQuery holds the pagination and filtering parameters common to the list endpoints.
+type listQuery struct {
+	// Maximum number of items to return.
+	limit int
+
+	// Position in the list after which to start the page, taken from the cursor given by the previous page.
+	afterSeq  int64
+	hasCursor bool
+
+	// Range of wall-clock times within which items must fall; the start is inclusive and the end is exclusive. Zero
+	// values leave the corresponding end of the range open.
+	from time.Time
+	to   time.Time
+
+	// Name of the team station that items must concern, or blank to include all of them.
+	station string
+
+	// Whether any of the parameters were given, in which case the response is wrapped in a page envelope rather than
+	// being returned as a bare list as it was before pagination was introduced.
+	isPaginated bool
+}
+
+// listEntry represents a single item of a list along with the attributes by which it is filtered and paginated.
+type listEntry struct {
+	// Position of the item in the list, which must increase strictly from one item to the next and stay the same for
+	// the item as others are added or discarded.
+	seq int64
+
+	// Wall-clock time associated with the item, against which the time range is applied.
+	at time.Time
+
+	// The item itself, as it is to be rendered in the response.
+	item any
+}
+
+// listPage represents a single page of a list, as returned when pagination or filtering is requested.
+type listPage struct {
+	// Items in the page, in the list's order.
+	Items []any `json:"items"`
+
+	// Opaque cursor to pass as the "cursor" parameter to get the next page, or blank if this is the last page.
+	NextCursor string `json:"nextCursor"`
+}
+
+// parseListQuery returns the pagination and filtering parameters given in the request, or an error if any of them are
+// invalid.
+func parseListQuery(r *http.Request) (listQuery, error) {
+	query := listQuery{limit: defaultListPageSize}
+	values := r.URL.Query()
+	for _, name := range []string{"limit", "cursor", "from", "to", "station"} {
+		if values.Has(name) {
+			query.isPaginated = true
+		}
+	}
+
+	if limitParam := values.Get("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > maxListPageSize {
+			return query, fmt.Errorf("invalid limit: %s (expecting 1-%d)", limitParam, maxListPageSize)
+		}
+		query.limit = limit
+	}
+	if cursorParam := values.Get("cursor"); cursorParam != "" {
+		afterSeq, err := strconv.ParseInt(cursorParam, 10, 64)
+		if err != nil {
+			return query, fmt.Errorf("invalid cursor: %s", cursorParam)
+		}
+		query.afterSeq = afterSeq
+		query.hasCursor = true
+	}
+	var err error
+	if query.from, err = parseListTime(values.Get("from")); err != nil {
+		return query, fmt.Errorf("invalid from: %v", err)
+	}
+	if query.to, err = parseListTime(values.Get("to")); err != nil {
+		return query, fmt.Errorf("invalid to: %v", err)
+	}
+	query.station = values.Get("station")
+	return query, nil
+}
+
+// parseListTime parses the given time range parameter, returning the zero time if it is blank.
+func parseListTime(param string) (time.Time, error) {
+	if param == "" {
+		return time.Time{}, nil
+	}
+	bound, err := time.Parse(time.RFC3339, param)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s (expecting an RFC 3339 time)", param)
+	}
+	return bound, nil
+}
+
+// page returns the page of the given entries, which must be in increasing order of sequence, that satisfies the query.
+func (query listQuery) page(entries []listEntry) listPage {
+	page := listPage{Items: []any{}}
+	var lastSeq int64
+	for _, entry := range entries {
+		if query.hasCursor && entry.seq <= query.afterSeq {
+			continue
+		}
+		if !query.from.IsZero() && entry.at.Before(query.from) || !query.to.IsZero() && !entry.at.Before(query.to) {
+			continue
+		}
+		if len(page.Items) == query.limit {
+			page.NextCursor = strconv.FormatInt(lastSeq, 10)
+			break
+		}
+		page.Items = append(page.Items, entry.item)
+		lastSeq = entry.seq
+	}
+	return page
+}
+
+// render returns the given entries as the response to the query: a page envelope if pagination or filtering was
+// requested, or the bare list of items otherwise.
+func (query listQuery) render(entries []listEntry) any {
+	if query.isPaginated {
+		return query.page(entries)
+	}
+	items := make([]any, len(entries))
+	for i, entry := range entries {
+		items[i] = entry.item
+	}
+	return items
+}
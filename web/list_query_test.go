@@ -0,0 +1,88 @@
+package web
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseListQuery(t *testing.T) {
+	query, err := parseListQuery(httptest.NewRequest("GET", "/alerts", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, listQuery{limit: defaultListPageSize}, query)
+
+	query, err = parseListQuery(
+		httptest.NewRequest(
+			"GET", "/alerts?limit=5&cursor=42&from=2024-03-02T10:00:00Z&to=2024-03-02T11:00:00-08:00&station=red1", nil,
+		),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, query.limit)
+	assert.Equal(t, int64(42), query.afterSeq)
+	assert.True(t, query.hasCursor)
+	assert.Equal(t, time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC), query.from.UTC())
+	assert.Equal(t, time.Date(2024, 3, 2, 19, 0, 0, 0, time.UTC), query.to.UTC())
+	assert.Equal(t, "red1", query.station)
+	assert.True(t, query.isPaginated)
+
+	// Any one of the parameters asks for the page envelope.
+	query, err = parseListQuery(httptest.NewRequest("GET", "/alerts?station=blue3", nil))
+	assert.Nil(t, err)
+	assert.True(t, query.isPaginated)
+	assert.Equal(t, defaultListPageSize, query.limit)
+
+	for url, message := range map[string]string{
+		"/alerts?limit=0":         "invalid limit: 0 (expecting 1-1000)",
+		"/alerts?limit=1001":      "invalid limit: 1001 (expecting 1-1000)",
+		"/alerts?cursor=abc":      "invalid cursor: abc",
+		"/alerts?from=yesterday":  "invalid from: yesterday (expecting an RFC 3339 time)",
+		"/alerts?to=2024-03-02":   "invalid to: 2024-03-02 (expecting an RFC 3339 time)",
+		"/alerts?limit=ten&to=xx": "invalid limit: ten (expecting 1-1000)",
+	} {
+		_, err = parseListQuery(httptest.NewRequest("GET", url, nil))
+		assert.EqualError(t, err, message, url)
+	}
+}
+
+func TestListQuery_page(t *testing.T) {
+	startTime := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	var entries []listEntry
+	for i := 1; i <= 5; i++ {
+		entries = append(
+			entries, listEntry{seq: int64(10 * i), at: startTime.Add(time.Duration(i) * time.Minute), item: i},
+		)
+	}
+
+	// Pages follow on from each other via the cursor.
+	query := listQuery{limit: 2, isPaginated: true}
+	assert.Equal(t, listPage{Items: []any{1, 2}, NextCursor: "20"}, query.page(entries))
+	query.afterSeq, query.hasCursor = 20, true
+	assert.Equal(t, listPage{Items: []any{3, 4}, NextCursor: "40"}, query.page(entries))
+	query.afterSeq = 40
+	assert.Equal(t, listPage{Items: []any{5}}, query.page(entries))
+	query.afterSeq = 50
+	assert.Equal(t, listPage{Items: []any{}}, query.page(entries))
+
+	// A cursor still works after the items up to it have been discarded.
+	query.afterSeq = 20
+	assert.Equal(t, listPage{Items: []any{4, 5}}, query.page(entries[3:]))
+
+	// The time range includes its start but not its end.
+	query = listQuery{limit: 10, from: startTime.Add(2 * time.Minute), to: startTime.Add(4 * time.Minute)}
+	assert.Equal(t, listPage{Items: []any{2, 3}}, query.page(entries))
+	query = listQuery{limit: 1, from: startTime.Add(3 * time.Minute)}
+	assert.Equal(t, listPage{Items: []any{3}, NextCursor: "30"}, query.page(entries))
+}
+
+func TestListQuery_render(t *testing.T) {
+	entries := []listEntry{{seq: 1, item: "a"}, {seq: 2, item: "b"}}
+
+	// Without any of the parameters the list is returned bare, as it was before pagination was introduced.
+	assert.Equal(t, []any{"a", "b"}, listQuery{limit: 1}.render(entries))
+	assert.Equal(t, []any{}, listQuery{}.render(nil))
+
+	assert.Equal(
+		t, listPage{Items: []any{"a"}, NextCursor: "1"}, listQuery{limit: 1, isPaginated: true}.render(entries),
+	)
+}
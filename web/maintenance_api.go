@@ -0,0 +1,78 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Path of the maintenance mode endpoints.
+const maintenancePath = "/maintenance"
+
+// Paths whose changing requests are still accepted while the radio is in maintenance mode, since they don't alter its
+// configuration and rejecting them would leave maintenance mode stuck on or trip the FMS heartbeat. The emergency stop
+// is also exempt since it must work regardless.
+var maintenanceExemptPaths = map[string]struct{}{
+	maintenancePath:            {},
+	"/configuration/clear-all": {},
+	"/debug/exec":              {},
+	"/heartbeat":               {},
+	"/support-bundle":          {},
+}
+
+// maintenanceRequest represents the body of a request to enable or disable maintenance mode.
+type maintenanceRequest struct {
+	// Whether maintenance mode should be enabled.
+	IsEnabled bool `json:"isEnabled"`
+
+	// Why maintenance mode is being enabled, which is returned to rejected requests.
+	Reason string `json:"reason"`
+}
+
+// rejectChangesDuringMaintenance wraps the given handler so that requests other than GET, HEAD, and OPTIONS are
+// rejected with the reason for maintenance while the radio is in maintenance mode.
+func (web *WebServer) rejectChangesDuringMaintenance(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			if _, ok := maintenanceExemptPaths[unversionedPath(r)]; !ok {
+				if maintenance := web.radio.GetMaintenanceStatus(); maintenance.IsEnabled {
+					err := fmt.Errorf("radio is in maintenance mode: %s", maintenance.Reason)
+					handleWebErr(w, r, err, http.StatusServiceUnavailable)
+					return
+				}
+			}
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// maintenanceHandler returns whether the radio is in maintenance mode and why.
+func (web *WebServer) maintenanceHandler(w http.ResponseWriter, r *http.Request) {
+	jsonData, err := json.MarshalIndent(web.radio.GetMaintenanceStatus(), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
+
+// maintenanceUpdateHandler enables or disables maintenance mode.
+func (web *WebServer) maintenanceUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	var request maintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := web.radio.SetMaintenanceMode(request.IsEnabled, request.Reason); err != nil {
+		handleWebErr(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if request.IsEnabled {
+		_, _ = fmt.Fprintln(w, "Maintenance mode enabled.")
+	} else {
+		_, _ = fmt.Fprintln(w, "Maintenance mode disabled.")
+	}
+}
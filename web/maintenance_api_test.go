@@ -0,0 +1,69 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_maintenanceHandlers(t *testing.T) {
+	r := radio.NewRadio()
+	web := NewWebServer(r)
+
+	recorder := web.getHttpResponse("/maintenance")
+	assert.Equal(t, 200, recorder.Code)
+	var maintenance radio.MaintenanceStatus
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &maintenance))
+	assert.False(t, maintenance.IsEnabled)
+
+	recorder = web.postHttpResponse("/maintenance", `{"isEnabled":true,"reason":"flashing firmware"}`)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Maintenance mode enabled.")
+	assert.True(t, r.GetMaintenanceStatus().IsEnabled)
+
+	recorder = web.getHttpResponse("/maintenance")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &maintenance))
+	assert.True(t, maintenance.IsEnabled)
+	assert.Equal(t, "flashing firmware", maintenance.Reason)
+
+	// Changing requests are rejected with the reason while monitoring carries on.
+	recorder = web.postHttpResponse("/settings/reload", "")
+	assert.Equal(t, 503, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "radio is in maintenance mode: flashing firmware")
+	recorder = web.postHttpResponse("/configuration", "{}")
+	assert.Equal(t, 503, recorder.Code)
+	recorder = web.getHttpResponse("/status")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"reason": "flashing firmware"`)
+
+	recorder = web.postHttpResponse("/maintenance", `{"isEnabled":false}`)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Maintenance mode disabled.")
+	assert.False(t, r.GetMaintenanceStatus().IsEnabled)
+	recorder = web.postHttpResponse("/settings/reload", "")
+	assert.NotEqual(t, 503, recorder.Code)
+
+	recorder = web.postHttpResponse("/maintenance", "{blorpy}")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid JSON")
+}
+
+func TestWeb_maintenanceHandlersAuthorization(t *testing.T) {
+	r := radio.NewRadio()
+	web := NewWebServer(r)
+	web.password = "mypassword"
+
+	recorder := web.getHttpResponse("/maintenance")
+	assert.Equal(t, 401, recorder.Code)
+	recorder = web.postHttpResponse("/maintenance", `{"isEnabled":true}`)
+	assert.Equal(t, 401, recorder.Code)
+	assert.False(t, r.GetMaintenanceStatus().IsEnabled)
+
+	recorder = web.postHttpResponseWithHeaders(
+		"/maintenance", `{"isEnabled":true}`, map[string]string{"Authorization": "Bearer mypassword"},
+	)
+	assert.Equal(t, 200, recorder.Code)
+	assert.True(t, r.GetMaintenanceStatus().IsEnabled)
+}
@@ -0,0 +1,127 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Interval at which the progress of a management network change is checked to open or close the listener on the new
+// address.
+const managementNetworkPollInterval = time.Second
+
+// managementNetworkHandler returns the management network addressing and the progress of any change to it.
+func (web *WebServer) managementNetworkHandler(w http.ResponseWriter, r *http.Request) {
+	jsonData, err := json.MarshalIndent(web.radio.GetManagementNetwork(), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
+
+// managementNetworkUpdateHandler schedules a change to the management network addressing, which is reverted unless it
+// is confirmed via the new address in time.
+func (web *WebServer) managementNetworkUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	var request radio.ManagementNetworkChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := request.Validate(); err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid management network: %v", err), http.StatusBadRequest)
+		return
+	}
+	previousIpAddress := web.radio.GetManagementNetwork().Current.IpAddress
+	if err := web.radio.RequestManagementNetworkChange(request); err != nil {
+		handleWebErr(w, r, err, http.StatusConflict)
+		return
+	}
+	change := web.radio.GetManagementNetwork().Change
+	if request.IpAddress != previousIpAddress && web.listener != nil {
+		go web.serveOnNewManagementAddress(request.IpAddress)
+	}
+
+	radio.LogWithCorrelationId(requestCorrelationId(r), "Management network change requested: %+v", request)
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprintf(
+		w,
+		"Management network will change on the next monitoring poll; confirm via POST to /system/network/confirm on "+
+			"%s within %d seconds or it will be reverted.\n",
+		request.IpAddress,
+		change.RollbackTimeoutSec,
+	)
+}
+
+// managementNetworkConfirmHandler makes the management network change awaiting confirmation permanent. It must be
+// called via the new address, which proves that the client can still reach the access point.
+func (web *WebServer) managementNetworkConfirmHandler(w http.ResponseWriter, r *http.Request) {
+	localAddress, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr)
+	if !ok {
+		handleWebErr(w, r, errors.New("unable to determine the address the request was sent to"), http.StatusBadRequest)
+		return
+	}
+	ipAddress, _, err := net.SplitHostPort(localAddress.String())
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err = web.radio.ConfirmManagementNetworkChange(ipAddress); err != nil {
+		handleWebErr(w, r, err, http.StatusConflict)
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Management network change confirmed.")
+}
+
+// serveOnNewManagementAddress accepts connections on the given new management address once the change to it has been
+// applied, so that it can be confirmed there, and stops again if the change is reverted. The listener on the previous
+// address is left in place, since the address it is bound to is simply no longer reachable.
+func (web *WebServer) serveOnNewManagementAddress(ipAddress string) {
+	_, port, err := net.SplitHostPort(web.listener.Addr().String())
+	if err != nil {
+		log.Printf("Unable to determine port to listen on at new management address %s: %v", ipAddress, err)
+		return
+	}
+	listenAddress := net.JoinHostPort(ipAddress, port)
+
+	var listener net.Listener
+	for {
+		time.Sleep(managementNetworkPollInterval)
+		status := web.radio.GetManagementNetwork()
+		isApplied := status.Current.IpAddress == ipAddress
+		if listener == nil && isApplied {
+			// The address may take a moment to come up after the network is reloaded.
+			if listener, err = net.Listen("tcp", listenAddress); err == nil {
+				log.Printf("Server also listening on %s for the management network change\n", listenAddress)
+				go web.serveListener(listener)
+			}
+		}
+		if status.Change == nil || !status.Change.IsInProgress() {
+			if listener != nil && !isApplied {
+				log.Printf(
+					"Server no longer listening on %s since the management network change was reverted\n",
+					listenAddress,
+				)
+				_ = listener.Close()
+			}
+			return
+		}
+	}
+}
+
+// serveListener serves requests accepted on the given additional listener until it is closed.
+func (web *WebServer) serveListener(listener net.Listener) {
+	server := web.newHttpServer(listener.Addr().String())
+	if err := server.Serve(web.limitConnections(listener)); err != nil && !errors.Is(err, net.ErrClosed) {
+		log.Printf("Error serving on %s: %v", listener.Addr(), err)
+	}
+}
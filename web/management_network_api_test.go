@@ -0,0 +1,115 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWeb_managementNetworkHandler(t *testing.T) {
+	ap := radio.NewRadio()
+	ap.ManagementNetwork.Current = radio.ManagementNetwork{IpAddress: "10.0.100.2", Netmask: "255.255.255.0"}
+	web := NewWebServer(ap)
+
+	recorder := web.getHttpResponse("/system/network")
+	assert.Equal(t, 200, recorder.Code)
+	var status radio.ManagementNetworkStatus
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+	assert.Equal(t, "10.0.100.2", status.Current.IpAddress)
+	assert.Nil(t, status.Change)
+
+	web.password = "mypassword"
+	recorder = web.getHttpResponse("/system/network")
+	assert.Equal(t, 401, recorder.Code)
+}
+
+func TestWeb_managementNetworkUpdateHandler(t *testing.T) {
+	ap := radio.NewRadio()
+	ap.ManagementNetwork.Current = radio.ManagementNetwork{IpAddress: "10.0.100.2", Netmask: "255.255.255.0"}
+	web := NewWebServer(ap)
+
+	recorder := web.putHttpResponse(
+		"/system/network",
+		`{"ipAddress": "10.0.150.2", "netmask": "255.255.255.0", "adminVlan": 150, "rollbackTimeoutSec": 30}`,
+	)
+	assert.Equal(t, 202, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "confirm via POST to /system/network/confirm on 10.0.150.2 within 30")
+	change := ap.GetManagementNetwork().Change
+	if assert.NotNil(t, change) {
+		assert.Equal(t, "PENDING", string(change.State))
+		assert.Equal(
+			t,
+			radio.ManagementNetwork{IpAddress: "10.0.150.2", Netmask: "255.255.255.0", AdminVlan: 150},
+			change.Requested,
+		)
+	}
+
+	// Only one change can be in progress at a time.
+	recorder = web.putHttpResponse("/system/network", `{"ipAddress": "10.0.150.3", "netmask": "255.255.255.0"}`)
+	assert.Equal(t, 409, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "a management network change is already in progress")
+
+	// Invalid requests.
+	recorder = web.putHttpResponse("/system/network", "{")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid JSON")
+	recorder = web.putHttpResponse("/system/network", `{"ipAddress": "10.0.150.2", "netmask": "255.0.255.0"}`)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid management network: invalid netmask")
+
+	web.password = "mypassword"
+	recorder = web.putHttpResponse("/system/network", `{"ipAddress": "10.0.150.2", "netmask": "255.255.255.0"}`)
+	assert.Equal(t, 401, recorder.Code)
+}
+
+func TestWeb_managementNetworkConfirmHandler(t *testing.T) {
+	ap := radio.NewRadio()
+	requested := radio.ManagementNetwork{IpAddress: "10.0.150.2", Netmask: "255.255.255.0"}
+	ap.ManagementNetwork.Current = requested
+	ap.ManagementNetwork.Change = &radio.ManagementNetworkChange{
+		State:     "AWAITING_CONFIRMATION",
+		Requested: requested,
+		Previous:  radio.ManagementNetwork{IpAddress: "10.0.100.2", Netmask: "255.255.255.0"},
+		ConfirmBy: time.Now().Add(time.Minute),
+	}
+	web := NewWebServer(ap)
+	confirm := func(localIpAddress string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/system/network/confirm", nil)
+		if localIpAddress != "" {
+			localAddress := &net.TCPAddr{IP: net.ParseIP(localIpAddress), Port: 8081}
+			req = req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, localAddress))
+		}
+		web.newRouter().ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// The confirmation must arrive via the new address.
+	recorder := confirm("")
+	assert.Equal(t, 400, recorder.Code)
+	recorder = confirm("10.0.100.2")
+	assert.Equal(t, 409, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "confirmation must be sent to the new management address 10.0.150.2")
+
+	recorder = confirm("10.0.150.2")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Management network change confirmed.")
+	assert.Equal(t, "CONFIRMED", string(ap.GetManagementNetwork().Change.State))
+
+	recorder = confirm("10.0.150.2")
+	assert.Equal(t, 409, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "no management network change is awaiting confirmation")
+
+	web.password = "mypassword"
+	recorder = confirm("10.0.150.2")
+	assert.Equal(t, 401, recorder.Code)
+}
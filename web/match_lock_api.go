@@ -0,0 +1,43 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// matchLockRequest represents the optional body of a request to acquire the match lock.
+type matchLockRequest struct {
+	// How long to hold the lock for, in seconds. Zero uses the default duration.
+	DurationSec int `json:"durationSec"`
+}
+
+// matchLockHandler acquires or renews the match lock, suppressing disruptive automatic actions during a match.
+func (web *WebServer) matchLockHandler(w http.ResponseWriter, r *http.Request) {
+	var request matchLockRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		handleWebErr(w, r, fmt.Errorf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	expiresAt, err := web.radio.AcquireMatchLock(request.DurationSec)
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusBadRequest)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "Match lock held until %s.\n", expiresAt.Format(time.RFC3339))
+}
+
+// matchUnlockHandler releases the match lock.
+func (web *WebServer) matchUnlockHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.radio.ReleaseMatchLock() {
+		_, _ = fmt.Fprintln(w, "Match lock was not held.")
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Match lock released.")
+}
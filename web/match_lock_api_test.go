@@ -0,0 +1,61 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_matchLockHandler(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+
+	recorder := web.postHttpResponse("/match/lock", "")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Match lock held until")
+	assert.True(t, ap.MatchLock.IsHeld)
+
+	recorder = web.postHttpResponse("/match/lock", "{\"durationSec\":60}")
+	assert.Equal(t, 200, recorder.Code)
+	assert.True(t, ap.MatchLock.IsHeld)
+
+	recorder = web.postHttpResponse("/match/lock", "{\"durationSec\":1000}")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid durationSec: 1000")
+
+	recorder = web.postHttpResponse("/match/lock", "{blorpy}")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid JSON")
+
+	recorder = web.postHttpResponse("/match/unlock", "")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Match lock released.")
+	assert.False(t, ap.MatchLock.IsHeld)
+
+	recorder = web.postHttpResponse("/match/unlock", "")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Match lock was not held.")
+}
+
+func TestWeb_matchLockHandlerAuthorization(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+	web.password = "mypassword"
+
+	recorder := web.postHttpResponse("/match/lock", "")
+	assert.Equal(t, 401, recorder.Code)
+	assert.False(t, ap.MatchLock.IsHeld)
+	recorder = web.postHttpResponse("/match/unlock", "")
+	assert.Equal(t, 401, recorder.Code)
+
+	headers := map[string]string{"Authorization": "Bearer mypassword"}
+	recorder = web.postHttpResponseWithHeaders("/match/lock", "", headers)
+	assert.Equal(t, 200, recorder.Code)
+	assert.True(t, ap.MatchLock.IsHeld)
+	recorder = web.postHttpResponseWithHeaders("/match/unlock", "", headers)
+	assert.Equal(t, 200, recorder.Code)
+	assert.False(t, ap.MatchLock.IsHeld)
+}
@@ -0,0 +1,99 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// Error returned for requests that don't present a password or token granting the role that an endpoint requires.
+var errNotAuthorized = errors.New("not authorized; must provide 'Authorization: Bearer [password]' header")
+
+// middleware wraps a handler with behavior that applies to every request reaching it.
+type middleware func(handler http.Handler) http.Handler
+
+// chainMiddleware wraps the given handler in the given middleware, with the first one outermost so that it sees each
+// request first.
+func chainMiddleware(handler http.Handler, middlewares ...middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// requireRole returns middleware that rejects requests that aren't authorized for the given role, or that does nothing
+// if the role is blank. Rejected attempts to issue configuration requests are counted against the client.
+func (web *WebServer) requireRole(role tokenRole, issuesConfiguration bool) middleware {
+	return func(handler http.Handler) http.Handler {
+		if role == "" {
+			return handler
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !web.isAuthorized(r, role) {
+				if issuesConfiguration {
+					web.requestOrigins.record(web.requestOrigin(r), outcomeUnauthorized)
+				}
+				handleWebErr(w, r, errNotAuthorized, http.StatusUnauthorized)
+				return
+			}
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
+
+// logRequests wraps the given handler so that requests that change something or fail are logged along with their
+// outcome and the correlation ID of the request.
+func (web *WebServer) logRequests(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startTime := time.Now()
+		writer := &statusRecordingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		handler.ServeHTTP(writer, r)
+		if r.Method != http.MethodGet || writer.statusCode >= http.StatusBadRequest {
+			origin := web.requestOrigin(r)
+			if forwarded, ok := requestForwarding(r); ok {
+				origin += " via proxy " + forwarded.proxyAddress
+				if forwarded.scheme != "" {
+					origin += " over " + forwarded.scheme
+				}
+			}
+			radio.LogWithCorrelationId(
+				requestCorrelationId(r),
+				"%s %s from %s: %d in %dms",
+				r.Method,
+				r.URL.Path,
+				origin,
+				writer.statusCode,
+				time.Since(startTime).Milliseconds(),
+			)
+		}
+	})
+}
+
+// recoverPanics wraps the given handler so that a panic while serving a request fails just that request with a 500
+// status, leaving the radio loop and the other requests unaffected, and raises an alert so that the bug is noticed.
+func (web *WebServer) recoverPanics(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writer := &statusRecordingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				// The handler deliberately aborted the response; let the HTTP server close the connection.
+				panic(recovered)
+			}
+			radio.LogWithCorrelationId(
+				requestCorrelationId(r), "Panic serving %s %s: %v\n%s", r.Method, r.URL.Path, recovered, debug.Stack(),
+			)
+			web.radio.RecordApiPanic(r.Method, r.URL.Path, recovered)
+			if !writer.isHeaderWritten {
+				handleWebErr(writer, r, fmt.Errorf("internal error: %v", recovered), http.StatusInternalServerError)
+			}
+		}()
+		handler.ServeHTTP(writer, r)
+	})
+}
@@ -0,0 +1,76 @@
+package web
+
+import (
+	"bytes"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestChainMiddleware(t *testing.T) {
+	var calls []string
+	tag := func(name string) middleware {
+		return func(handler http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				handler.ServeHTTP(w, r)
+			})
+		}
+	}
+	handler := chainMiddleware(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { calls = append(calls, "handler") }),
+		tag("outer"),
+		tag("inner"),
+	)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, []string{"outer", "inner", "handler"}, calls)
+}
+
+func TestWeb_recoverPanics(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	var logOutput bytes.Buffer
+	log.SetOutput(&logOutput)
+	defer log.SetOutput(os.Stderr)
+
+	// A panic fails just the request and raises an alert.
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("oops")
+	})
+	handler := web.assignCorrelationIds(web.recoverPanics(panicking))
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest("GET", "/status", nil)
+	request.Header.Set("X-Request-Id", "abc123")
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, 500, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "HTTP request error 500: internal error: oops")
+	assert.Contains(t, logOutput.String(), "[request abc123] Panic serving GET /status: oops")
+	assert.Contains(t, logOutput.String(), "goroutine")
+	alerts := web.radio.GetAlerts()
+	if assert.Equal(t, 1, len(alerts)) {
+		assert.Equal(t, "API_PANIC", alerts[0].Type)
+		assert.Equal(t, "Panic serving GET /status: oops", alerts[0].Message)
+	}
+
+	// A response that was already started is left as it is.
+	handler = web.recoverPanics(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		panic("oops")
+	}))
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/configuration", nil))
+	assert.Equal(t, 202, recorder.Code)
+	assert.Empty(t, recorder.Body.String())
+
+	// Deliberately aborted responses are left to the HTTP server.
+	handler = web.recoverPanics(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/status", nil))
+	})
+	assert.Equal(t, 2, len(web.radio.GetAlerts()))
+}
@@ -0,0 +1,21 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// neighborNetworksHandler returns a JSON list of the foreign networks recently seen in the background scans, with those
+// on or next to the field channel first.
+func (web *WebServer) neighborNetworksHandler(w http.ResponseWriter, r *http.Request) {
+	jsonData, err := json.MarshalIndent(web.radio.GetNeighborNetworks(), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
@@ -0,0 +1,60 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"net/http"
+	"regexp"
+)
+
+// Passwords must be presentable as a bearer token, which can't contain whitespace.
+var passwordRe = regexp.MustCompile(`^[!-~]{8,128}$`)
+
+// passwordRotateHandler replaces the API password with the requested one in the configured secret storage backend.
+// The new password takes effect immediately; existing tokens are unaffected.
+func (web *WebServer) passwordRotateHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := validatePassword(request.Password); err != nil {
+		handleWebErr(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if statusCode, err := web.savePassword(request.Password); err != nil {
+		handleWebErr(w, r, err, statusCode)
+		return
+	}
+
+	_, _ = fmt.Fprintln(w, "Password rotated.")
+}
+
+// validatePassword checks that the given password can be used for the API.
+func validatePassword(password string) error {
+	if !passwordRe.MatchString(password) {
+		return errors.New("invalid password (expecting 8-128 printable ASCII characters without whitespace)")
+	}
+	return nil
+}
+
+// savePassword stores the given new API password in the configured secret storage backend and puts it into effect,
+// returning the HTTP status code to respond with if it couldn't be saved.
+func (web *WebServer) savePassword(password string) (int, error) {
+	secretSettings := web.radio.GetSettings().Secrets
+	if secretSettings.HashPasswordAtRest {
+		password = radio.HashSecret(password)
+	}
+	passwordStore := secretSettings.Store(passwordSecretName)
+	if err := passwordStore.Save(password); errors.Is(err, radio.ErrSecretStoreReadOnly) {
+		return http.StatusConflict, fmt.Errorf("cannot rotate password stored in %s: %v", passwordStore, err)
+	} else if err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to save password to %s: %v", passwordStore, err)
+	}
+	web.setPassword(password)
+	return 0, nil
+}
@@ -0,0 +1,72 @@
+package web
+
+import (
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWeb_passwordRotateHandler(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	settings := web.radio.GetSettings()
+	settings.Secrets.Directory = t.TempDir()
+	web.radio.SetSettings(settings)
+	web.tokens.filePath = filepath.Join(t.TempDir(), "tokens.json")
+	web.password = "mypassword"
+	passwordFilePath := filepath.Join(web.radio.GetSettings().Secrets.Directory, "frc-radio-api-password.txt")
+
+	recorder := web.postHttpResponse("/password", `{"password": "newpassword"}`)
+	assert.Equal(t, 401, recorder.Code)
+	recorder = web.postHttpResponseWithHeaders(
+		"/password", `{"password": "newpassword"}`, map[string]string{"Authorization": "Bearer newpassword"},
+	)
+	assert.Equal(t, 401, recorder.Code)
+
+	// Invalid requests.
+	headers := map[string]string{"Authorization": "Bearer mypassword"}
+	recorder = web.postHttpResponseWithHeaders("/password", "not JSON", headers)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid JSON")
+	recorder = web.postHttpResponseWithHeaders("/password", `{"password": "short"}`, headers)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid password")
+	recorder = web.postHttpResponseWithHeaders("/password", `{"password": "new password"}`, headers)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Equal(t, "mypassword", web.password)
+
+	// The new password takes effect immediately and the old one stops working.
+	recorder = web.postHttpResponseWithHeaders("/password", `{"password": "newpassword"}`, headers)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "Password rotated.\n", recorder.Body.String())
+	storedPassword, _ := os.ReadFile(passwordFilePath)
+	assert.Equal(t, "newpassword\n", string(storedPassword))
+	recorder = web.getHttpResponseWithHeaders("/settings", headers)
+	assert.Equal(t, 401, recorder.Code)
+	headers = map[string]string{"Authorization": "Bearer newpassword"}
+	recorder = web.getHttpResponseWithHeaders("/settings", headers)
+	assert.Equal(t, 200, recorder.Code)
+
+	// The password is only stored as a hash if it should be hashed at rest.
+	settings = web.radio.GetSettings()
+	settings.Secrets.HashPasswordAtRest = true
+	web.radio.SetSettings(settings)
+	recorder = web.postHttpResponseWithHeaders("/password", `{"password": "hashedpassword"}`, headers)
+	assert.Equal(t, 200, recorder.Code)
+	storedPassword, _ = os.ReadFile(passwordFilePath)
+	assert.Equal(t, radio.HashSecret("hashedpassword")+"\n", string(storedPassword))
+	assert.Equal(t, radio.HashSecret("hashedpassword"), web.password)
+	headers = map[string]string{"Authorization": "Bearer hashedpassword"}
+	recorder = web.getHttpResponseWithHeaders("/settings", headers)
+	assert.Equal(t, 200, recorder.Code)
+
+	// Passwords read from the environment can't be rotated.
+	settings = web.radio.GetSettings()
+	settings.Secrets.Backend = "ENVIRONMENT"
+	web.radio.SetSettings(settings)
+	recorder = web.postHttpResponseWithHeaders("/password", `{"password": "otherpassword"}`, headers)
+	assert.Equal(t, 409, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "environment variable FRC_RADIO_API_PASSWORD")
+	assert.Equal(t, radio.HashSecret("hashedpassword"), web.password)
+}
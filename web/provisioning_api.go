@@ -0,0 +1,81 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"net/http"
+)
+
+// Path of the first-boot provisioning endpoint, which is the only one served until the radio has been provisioned.
+const provisionPath = "/provision"
+
+// rejectUntilProvisioned wraps the given handler so that every request other than POST /provision is rejected while
+// the radio is awaiting its first-boot provisioning.
+func (web *WebServer) rejectUntilProvisioned(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !(r.Method == http.MethodPost && unversionedPath(r) == provisionPath) && web.radio.IsAwaitingProvisioning() {
+			handleWebErr(
+				w,
+				r,
+				fmt.Errorf("radio is awaiting provisioning via POST %s", provisionPath),
+				http.StatusServiceUnavailable,
+			)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// provisionHandler completes the one-time provisioning of a freshly flashed radio, setting the API password, event
+// code, and management address and confirming the hardware type, after which the radio starts normal operation.
+func (web *WebServer) provisionHandler(w http.ResponseWriter, r *http.Request) {
+	if !web.radio.IsAwaitingProvisioning() {
+		handleWebErr(
+			w, r, errors.New("radio has already been provisioned or doesn't require provisioning"), http.StatusConflict,
+		)
+		return
+	}
+
+	var request radio.ProvisioningRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := validatePassword(request.Password); err != nil {
+		handleWebErr(w, r, err, http.StatusBadRequest)
+		return
+	}
+	if err := request.Validate(web.radio); err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid provisioning request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Set the password first so that the radio never starts up without one.
+	if statusCode, err := web.savePassword(request.Password); err != nil {
+		handleWebErr(w, r, err, statusCode)
+		return
+	}
+	if err := web.radio.Provision(request); err != nil {
+		handleWebErr(w, r, err, statusCodeForError(err, http.StatusConflict))
+		return
+	}
+	web.followProvisionedManagementAddress(request.ManagementIpAddress)
+
+	radio.LogWithCorrelationId(
+		requestCorrelationId(r),
+		"Radio provisioned for event %q with management address %q.",
+		request.EventCode,
+		request.ManagementIpAddress,
+	)
+	_, _ = fmt.Fprintln(w, "Radio provisioned.")
+	if request.ManagementIpAddress != "" {
+		_, _ = fmt.Fprintf(
+			w,
+			"Management network will change once the radio has started; confirm via POST to /system/network/confirm "+
+				"on %s or it will be reverted.\n",
+			request.ManagementIpAddress,
+		)
+	}
+}
@@ -0,0 +1,50 @@
+package web
+
+import (
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_provisionHandlerNotRequired(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+
+	recorder := web.postHttpResponse("/provision", `{"password": "newpassword"}`)
+	assert.Equal(t, 409, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "radio has already been provisioned or doesn't require provisioning")
+}
+
+func TestWeb_provisionHandlerAwaitingProvisioning(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	settings := web.radio.GetSettings()
+	settings.RequireProvisioning = true
+	settings.Secrets.Directory = t.TempDir()
+	web.radio.SetSettings(settings)
+
+	// Everything other than provisioning is rejected.
+	recorder := web.getHttpResponse("/status")
+	assert.Equal(t, 503, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "radio is awaiting provisioning via POST /provision")
+	recorder = web.postHttpResponse("/configuration", "{}")
+	assert.Equal(t, 503, recorder.Code)
+	recorder = web.getHttpResponse("/provision")
+	assert.Equal(t, 503, recorder.Code)
+
+	// Invalid requests.
+	recorder = web.postHttpResponse("/provision", "not JSON")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid JSON")
+	recorder = web.postHttpResponse("/provision", `{"password": "short", "hardwareType": "TypeVividHosting"}`)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid password")
+	recorder = web.postHttpResponse("/provision", `{"password": "newpassword", "hardwareType": "TypeToaster"}`)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid provisioning request: invalid hardwareType: \"TypeToaster\"")
+	assert.Equal(t, "", web.password)
+	assert.True(t, web.radio.IsAwaitingProvisioning())
+
+	// An existing password must still be presented.
+	web.password = "mypassword"
+	recorder = web.postHttpResponse("/provision", `{"password": "newpassword"}`)
+	assert.Equal(t, 401, recorder.Code)
+}
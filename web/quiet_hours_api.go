@@ -0,0 +1,72 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+)
+
+// quietHoursHandler returns the quiet hours schedule and whether the team networks are currently down for it.
+func (web *WebServer) quietHoursHandler(w http.ResponseWriter, r *http.Request) {
+	jsonData, err := json.MarshalIndent(web.radio.GetQuietHours(), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
+
+// quietHoursUpdateHandler replaces the quiet hours schedule and persists it so that it survives a restart.
+func (web *WebServer) quietHoursUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	var schedule radio.QuietHoursSchedule
+	if err := json.NewDecoder(r.Body).Decode(&schedule); err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := schedule.Validate(); err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid quiet hours schedule: %v", err), http.StatusBadRequest)
+		return
+	}
+	scheduleJson, err := json.MarshalIndent(schedule, "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	if err = os.WriteFile(web.quietHoursFilePath, scheduleJson, 0600); err != nil {
+		handleWebErr(w, r, fmt.Errorf("failed to save quiet hours schedule: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if err = web.radio.SetQuietHoursSchedule(schedule); err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	_, _ = fmt.Fprintln(w, "Quiet hours schedule saved and will take effect on the next monitoring poll.")
+}
+
+// loadQuietHoursSchedule restores the quiet hours schedule persisted on disk, if there is one.
+func (web *WebServer) loadQuietHoursSchedule() {
+	scheduleJson, err := os.ReadFile(web.quietHoursFilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+	var schedule radio.QuietHoursSchedule
+	if err == nil {
+		err = json.Unmarshal(scheduleJson, &schedule)
+	}
+	if err == nil {
+		err = web.radio.SetQuietHoursSchedule(schedule)
+	}
+	if err != nil {
+		log.Printf("Error loading quiet hours schedule; quiet hours are disabled: %v", err)
+	}
+}
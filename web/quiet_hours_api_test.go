@@ -0,0 +1,87 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWeb_quietHoursHandlers(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+	web.quietHoursFilePath = filepath.Join(t.TempDir(), "quiet-hours.json")
+
+	recorder := web.getHttpResponse("/quiet-hours")
+	assert.Equal(t, 200, recorder.Code)
+	var status radio.QuietHoursStatus
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+	assert.Equal(t, radio.QuietHoursStatus{}, status)
+
+	recorder = web.postHttpResponse(
+		"/quiet-hours", `{"enabled":true,"startTime":"22:00","endTime":"07:30","txPowerDbm":5}`,
+	)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Quiet hours schedule saved")
+	schedule := radio.QuietHoursSchedule{Enabled: true, StartTime: "22:00", EndTime: "07:30", TxPowerDbm: 5}
+	assert.Equal(t, schedule, ap.GetQuietHours().Schedule)
+
+	recorder = web.getHttpResponse("/quiet-hours")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+	assert.Equal(t, schedule, status.Schedule)
+	assert.False(t, status.NextChangeAt.IsZero())
+
+	// The schedule is persisted and restored on startup.
+	otherAp := radio.NewRadio()
+	otherWeb := NewWebServer(otherAp)
+	otherWeb.quietHoursFilePath = web.quietHoursFilePath
+	otherWeb.loadPersistedState()
+	assert.Equal(t, schedule, otherAp.GetQuietHours().Schedule)
+
+	// Invalid schedules are rejected without being saved.
+	recorder = web.postHttpResponse("/quiet-hours", `{"enabled":true,"startTime":"22:00","endTime":"22:00"}`)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid quiet hours schedule: startTime and endTime cannot be the same")
+	recorder = web.postHttpResponse("/quiet-hours", "{blorpy}")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid JSON")
+	assert.Equal(t, schedule, ap.GetQuietHours().Schedule)
+	fileBytes, err := os.ReadFile(web.quietHoursFilePath)
+	assert.Nil(t, err)
+	assert.Contains(t, string(fileBytes), "07:30")
+
+	// A corrupt file leaves quiet hours disabled.
+	assert.Nil(t, os.WriteFile(web.quietHoursFilePath, []byte("not JSON"), 0600))
+	otherAp = radio.NewRadio()
+	otherWeb = NewWebServer(otherAp)
+	otherWeb.quietHoursFilePath = web.quietHoursFilePath
+	otherWeb.loadPersistedState()
+	assert.Equal(t, radio.QuietHoursSchedule{}, otherAp.GetQuietHours().Schedule)
+}
+
+func TestWeb_quietHoursHandlersAuthorization(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+	web.quietHoursFilePath = filepath.Join(t.TempDir(), "quiet-hours.json")
+	web.password = "mypassword"
+	body := `{"enabled":true,"startTime":"22:00","endTime":"07:30"}`
+
+	recorder := web.getHttpResponse("/quiet-hours")
+	assert.Equal(t, 401, recorder.Code)
+	recorder = web.postHttpResponse("/quiet-hours", body)
+	assert.Equal(t, 401, recorder.Code)
+	assert.False(t, ap.GetQuietHours().Schedule.Enabled)
+
+	headers := map[string]string{"Authorization": "Bearer mypassword"}
+	recorder = web.getHttpResponseWithHeaders("/quiet-hours", headers)
+	assert.Equal(t, 200, recorder.Code)
+	recorder = web.postHttpResponseWithHeaders("/quiet-hours", body, headers)
+	assert.Equal(t, 200, recorder.Code)
+	assert.True(t, ap.GetQuietHours().Schedule.Enabled)
+}
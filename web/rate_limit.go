@@ -0,0 +1,78 @@
+package web
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// requestRateLimiter tracks how many requests each client may still make, as a token bucket per client IP address
+// that holds up to a minute's allowance and refills continuously.
+type requestRateLimiter struct {
+	maxRequestsPerMin int
+	mutex             sync.Mutex
+	buckets           map[string]*requestBucket
+
+	// Time at which buckets that had refilled completely were last discarded.
+	prunedAt time.Time
+}
+
+// requestBucket holds the allowance remaining to a single client.
+type requestBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// limitRequestRate wraps the given handler so that clients making requests faster than the configured rate are
+// rejected with a 429 status saying when to try again, if there is a limit. Clients are told apart by IP address.
+func (web *WebServer) limitRequestRate(handler http.Handler) http.Handler {
+	maxRequestsPerMin := web.httpSettings.MaxRequestsPerMinPerClient
+	if maxRequestsPerMin == 0 {
+		return handler
+	}
+	limiter := &requestRateLimiter{maxRequestsPerMin: maxRequestsPerMin, buckets: make(map[string]*requestBucket)}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if retryAfter, ok := limiter.allow(remoteIp(r.RemoteAddr), time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			err := fmt.Errorf("too many requests (limit is %d per minute)", maxRequestsPerMin)
+			handleWebErr(w, r, err, http.StatusTooManyRequests)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// allow takes one request from the given client's allowance as of the given time, returning false along with how long
+// until the next request will be allowed if none is left.
+func (limiter *requestRateLimiter) allow(client string, now time.Time) (time.Duration, bool) {
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+	capacity := float64(limiter.maxRequestsPerMin)
+	refillPerSec := capacity / 60
+
+	if now.Sub(limiter.prunedAt) >= time.Minute {
+		// A bucket that is back to full is the same as no bucket, so there is no need to keep it around.
+		for otherClient, bucket := range limiter.buckets {
+			if bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*refillPerSec >= capacity {
+				delete(limiter.buckets, otherClient)
+			}
+		}
+		limiter.prunedAt = now
+	}
+
+	bucket, ok := limiter.buckets[client]
+	if !ok {
+		bucket = &requestBucket{tokens: capacity, updatedAt: now}
+		limiter.buckets[client] = bucket
+	}
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*refillPerSec)
+	bucket.updatedAt = now
+	if bucket.tokens < 1 {
+		return time.Duration((1 - bucket.tokens) / refillPerSec * float64(time.Second)), false
+	}
+	bucket.tokens--
+	return 0, true
+}
@@ -0,0 +1,59 @@
+package web
+
+import (
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestRateLimiter_allow(t *testing.T) {
+	limiter := &requestRateLimiter{maxRequestsPerMin: 3, buckets: make(map[string]*requestBucket)}
+	now := time.Now()
+
+	// Each client can make a burst of up to a minute's allowance.
+	for i := 0; i < 3; i++ {
+		_, ok := limiter.allow("10.0.100.5", now)
+		assert.True(t, ok)
+	}
+	retryAfter, ok := limiter.allow("10.0.100.5", now)
+	assert.False(t, ok)
+	assert.Equal(t, 20*time.Second, retryAfter)
+	_, ok = limiter.allow("10.0.100.6", now)
+	assert.True(t, ok)
+
+	// The allowance refills continuously.
+	_, ok = limiter.allow("10.0.100.5", now.Add(19*time.Second))
+	assert.False(t, ok)
+	_, ok = limiter.allow("10.0.100.5", now.Add(20*time.Second))
+	assert.True(t, ok)
+
+	// Clients that have been idle long enough to refill completely are forgotten.
+	_, ok = limiter.allow("10.0.100.7", now.Add(2*time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(limiter.buckets))
+}
+
+func TestWeb_limitRequestRate(t *testing.T) {
+	ap := radio.NewRadio()
+	settings := ap.GetSettings()
+	settings.HttpServer.MaxRequestsPerMinPerClient = 2
+	ap.SetSettings(settings)
+	router := NewWebServer(ap).newRouter()
+	get := func(path, remoteAddr string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest("GET", path, nil)
+		request.RemoteAddr = remoteAddr
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	assert.Equal(t, 200, get("/health", "10.0.100.5:50000").Code)
+	assert.Equal(t, 200, get("/v1/health", "10.0.100.5:50001").Code)
+	recorder := get("/health", "10.0.100.5:50002")
+	assert.Equal(t, 429, recorder.Code)
+	assert.Equal(t, "30", recorder.Header().Get("Retry-After"))
+	assert.Contains(t, recorder.Body.String(), "too many requests (limit is 2 per minute)")
+	assert.Equal(t, 200, get("/health", "10.0.100.6:50000").Code)
+}
@@ -0,0 +1,154 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Maximum number of distinct origins to track; the least recently seen one is discarded first.
+const maxRequestOrigins = 100
+
+// requestOutcome represents how the API responded to a configuration request.
+type requestOutcome int
+
+const (
+	outcomeAccepted requestOutcome = iota
+	outcomeRejected
+	outcomeUnauthorized
+)
+
+// RequestOriginStats summarizes the configuration requests issued by a single client.
+type RequestOriginStats struct {
+	// Identity of the client: "token:[name]" if it authenticated with a named token, or its IP address otherwise.
+	Origin string `json:"origin"`
+
+	// Total number of configuration requests received from the client.
+	RequestCount int `json:"requestCount"`
+
+	// Number of requests that were valid and queued for application.
+	AcceptedCount int `json:"acceptedCount"`
+
+	// Number of requests that were rejected as invalid.
+	RejectedCount int `json:"rejectedCount"`
+
+	// Number of requests that were rejected for lack of authorization.
+	UnauthorizedCount int `json:"unauthorizedCount"`
+
+	// Percentage of requests that were accepted.
+	SuccessPercent float64 `json:"successPercent"`
+
+	// Time at which the most recent request was received from the client.
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// requestOriginTracker accumulates statistics on configuration requests by origin; it is shared between request
+// goroutines.
+type requestOriginTracker struct {
+	mutex   sync.Mutex
+	origins map[string]*RequestOriginStats
+}
+
+// record notes that a configuration request with the given outcome was received from the given origin.
+func (tracker *requestOriginTracker) record(origin string, outcome requestOutcome) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	if tracker.origins == nil {
+		tracker.origins = make(map[string]*RequestOriginStats)
+	}
+
+	stats, ok := tracker.origins[origin]
+	if !ok {
+		if len(tracker.origins) >= maxRequestOrigins {
+			tracker.evictLeastRecentlySeen()
+		}
+		stats = &RequestOriginStats{Origin: origin}
+		tracker.origins[origin] = stats
+	}
+	stats.RequestCount++
+	switch outcome {
+	case outcomeAccepted:
+		stats.AcceptedCount++
+	case outcomeRejected:
+		stats.RejectedCount++
+	case outcomeUnauthorized:
+		stats.UnauthorizedCount++
+	}
+	stats.SuccessPercent = math.Round(1000*float64(stats.AcceptedCount)/float64(stats.RequestCount)) / 10
+	stats.LastSeen = time.Now()
+}
+
+// evictLeastRecentlySeen discards the origin that was seen least recently. Must be called with the mutex held.
+func (tracker *requestOriginTracker) evictLeastRecentlySeen() {
+	var oldest *RequestOriginStats
+	for _, stats := range tracker.origins {
+		if oldest == nil || stats.LastSeen.Before(oldest.LastSeen) {
+			oldest = stats
+		}
+	}
+	if oldest != nil {
+		delete(tracker.origins, oldest.Origin)
+	}
+}
+
+// restore replaces the statistics with the given ones carried over from a previous API process.
+func (tracker *requestOriginTracker) restore(origins []RequestOriginStats) {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	tracker.origins = make(map[string]*RequestOriginStats)
+	for i := range origins {
+		tracker.origins[origins[i].Origin] = &origins[i]
+	}
+}
+
+// list returns the statistics for each origin, ordered from the most requests to the fewest.
+func (tracker *requestOriginTracker) list() []RequestOriginStats {
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	origins := make([]RequestOriginStats, 0, len(tracker.origins))
+	for _, stats := range tracker.origins {
+		origins = append(origins, *stats)
+	}
+	sort.Slice(origins, func(i, j int) bool {
+		if origins[i].RequestCount == origins[j].RequestCount {
+			return origins[i].Origin < origins[j].Origin
+		}
+		return origins[i].RequestCount > origins[j].RequestCount
+	})
+	return origins
+}
+
+// requestOrigin returns the identity of the client that issued the given request: the name of the token it presented,
+// if any, or else its IP address.
+func (web *WebServer) requestOrigin(r *http.Request) string {
+	if token := bearerToken(r); token != "" && !radio.SecretMatches(web.getPassword(), token) {
+		if apiToken, ok := web.tokens.lookup(token); ok {
+			return "token:" + apiToken.Name
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if host = strings.TrimSpace(host); host == "" {
+		return "unknown"
+	}
+	return host
+}
+
+// requestOriginsHandler returns a JSON list of statistics on the configuration requests issued by each client.
+func (web *WebServer) requestOriginsHandler(w http.ResponseWriter, r *http.Request) {
+	jsonData, err := json.MarshalIndent(web.requestOrigins.list(), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
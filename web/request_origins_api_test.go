@@ -0,0 +1,99 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"path/filepath"
+	"testing"
+)
+
+func TestWeb_requestOriginsHandler(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.tokens.filePath = filepath.Join(t.TempDir(), "tokens.json")
+	web.password = "mypassword"
+	fmsToken, err := web.tokens.create("fms", roleAdmin)
+	assert.Nil(t, err)
+	fmsHeaders := map[string]string{"Authorization": "Bearer " + fmsToken}
+
+	// Invalid and unauthorized configuration requests should be counted against their origins.
+	for i := 0; i < 3; i++ {
+		recorder := web.postHttpResponseWithHeaders("/configuration", "not JSON", fmsHeaders)
+		assert.Equal(t, 400, recorder.Code)
+	}
+	recorder := web.postHttpResponseWithHeaders(
+		"/configuration", "{}", map[string]string{"Authorization": "Bearer wrongpassword"},
+	)
+	assert.Equal(t, 401, recorder.Code)
+
+	recorder = web.getHttpResponse("/configuration/origins")
+	assert.Equal(t, 401, recorder.Code)
+	recorder = web.getHttpResponseWithHeaders("/configuration/origins", fmsHeaders)
+	assert.Equal(t, 200, recorder.Code)
+	var origins []RequestOriginStats
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &origins))
+	if assert.Equal(t, 2, len(origins)) {
+		assert.Equal(t, "token:fms", origins[0].Origin)
+		assert.Equal(t, 3, origins[0].RequestCount)
+		assert.Equal(t, 3, origins[0].RejectedCount)
+		assert.Equal(t, 0.0, origins[0].SuccessPercent)
+		assert.False(t, origins[0].LastSeen.IsZero())
+		assert.Equal(t, "unknown", origins[1].Origin)
+		assert.Equal(t, 1, origins[1].UnauthorizedCount)
+	}
+}
+
+func TestRequestOriginTracker(t *testing.T) {
+	var tracker requestOriginTracker
+	assert.Equal(t, []RequestOriginStats{}, tracker.list())
+
+	tracker.record("10.0.100.5", outcomeAccepted)
+	tracker.record("10.0.100.5", outcomeAccepted)
+	tracker.record("10.0.100.5", outcomeRejected)
+	tracker.record("token:fms", outcomeAccepted)
+	origins := tracker.list()
+	if assert.Equal(t, 2, len(origins)) {
+		assert.Equal(t, "10.0.100.5", origins[0].Origin)
+		assert.Equal(t, 3, origins[0].RequestCount)
+		assert.Equal(t, 2, origins[0].AcceptedCount)
+		assert.Equal(t, 1, origins[0].RejectedCount)
+		assert.Equal(t, 66.7, origins[0].SuccessPercent)
+		assert.Equal(t, "token:fms", origins[1].Origin)
+		assert.Equal(t, 100.0, origins[1].SuccessPercent)
+	}
+
+	// The least recently seen origin should be discarded once the limit is reached.
+	for i := 0; i < maxRequestOrigins-1; i++ {
+		tracker.record(fmt.Sprintf("10.0.200.%d", i), outcomeAccepted)
+	}
+	origins = tracker.list()
+	assert.Equal(t, maxRequestOrigins, len(origins))
+	originNames := make([]string, 0, len(origins))
+	for _, stats := range origins {
+		originNames = append(originNames, stats.Origin)
+	}
+	assert.Contains(t, originNames, "token:fms")
+	assert.NotContains(t, originNames, "10.0.100.5")
+}
+
+func TestWebServer_requestOrigin(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.tokens.filePath = filepath.Join(t.TempDir(), "tokens.json")
+	web.password = "mypassword"
+	dashboardToken, err := web.tokens.create("dashboard", roleReadOnly)
+	assert.Nil(t, err)
+
+	request, _ := http.NewRequest("POST", "/configuration", nil)
+	request.RemoteAddr = "10.0.100.5:51234"
+	assert.Equal(t, "10.0.100.5", web.requestOrigin(request))
+	request.Header.Set("Authorization", "Bearer mypassword")
+	assert.Equal(t, "10.0.100.5", web.requestOrigin(request))
+	request.Header.Set("Authorization", "Bearer "+dashboardToken)
+	assert.Equal(t, "token:dashboard", web.requestOrigin(request))
+	request.Header.Set("Authorization", "Bearer bogus")
+	assert.Equal(t, "10.0.100.5", web.requestOrigin(request))
+	request.RemoteAddr = ""
+	assert.Equal(t, "unknown", web.requestOrigin(request))
+}
@@ -0,0 +1,52 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+)
+
+// Path of the endpoint returning the log entries received from a single team's robot radio.
+const robotLogsPath = "/logs/team/{teamNumber}"
+
+// robotLogsHandler returns a JSON list of the most recent log entries received from the given team's robot radio,
+// oldest first, subject to the common pagination and filtering parameters.
+func (web *WebServer) robotLogsHandler(w http.ResponseWriter, r *http.Request) {
+	web.writeRobotLogs(w, r)
+}
+
+// writeRobotLogs writes out the robot radio log entries of the team given in the request's path.
+func (web *WebServer) writeRobotLogs(w http.ResponseWriter, r *http.Request) {
+	teamNumberParam := mux.Vars(r)["teamNumber"]
+	teamNumber, err := strconv.Atoi(teamNumberParam)
+	if err != nil || teamNumber <= 0 {
+		handleWebErr(w, r, fmt.Errorf("invalid team number: %s", teamNumberParam), http.StatusBadRequest)
+		return
+	}
+	query, err := parseListQuery(r)
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusBadRequest)
+		return
+	}
+	var entries []listEntry
+	for _, entry := range web.radio.GetRobotLogs(teamNumber) {
+		if query.station == "" || entry.Station == query.station {
+			entries = append(
+				entries, listEntry{seq: entry.ReceivedAt.MonotonicNs, at: entry.ReceivedAt.Wallclock, item: entry},
+			)
+		}
+	}
+
+	jsonData, err := json.MarshalIndent(query.render(entries), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
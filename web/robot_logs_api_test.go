@@ -0,0 +1,47 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWeb_robotLogsHandler(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+
+	recorder := web.getHttpResponse("/logs/team/254")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "[]", recorder.Body.String())
+
+	recorder = web.getHttpResponse("/logs/team/254?limit=10")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"items": []`)
+
+	recorder = web.getHttpResponse("/logs/team/red1")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid team number: red1")
+	recorder = web.getHttpResponse("/logs/team/254?limit=0")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid limit: 0")
+}
+
+func TestWeb_robotLogsHandlerAuthorization(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.password = "mypassword"
+
+	recorder := web.getHttpResponse("/logs/team/254")
+	assert.Equal(t, 401, recorder.Code)
+	recorder = web.getHttpResponseWithHeaders("/logs/team/254", map[string]string{"Authorization": "Bearer mypassword"})
+	assert.Equal(t, 200, recorder.Code)
+
+	// The team-facing listener serves a team's robot logs without authorization.
+	recorder = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/logs/team/254", nil)
+	web.newTeamStatusRouter().ServeHTTP(recorder, req)
+	assert.Equal(t, 200, recorder.Code)
+}
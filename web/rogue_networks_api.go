@@ -0,0 +1,21 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// rogueNetworksHandler returns a JSON list of the foreign networks recently seen impersonating team networks on the
+// field channel.
+func (web *WebServer) rogueNetworksHandler(w http.ResponseWriter, r *http.Request) {
+	jsonData, err := json.MarshalIndent(web.radio.GetRogueNetworks(), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
@@ -0,0 +1,163 @@
+package web
+
+import (
+	"github.com/gorilla/mux"
+	"net/http"
+	"regexp"
+)
+
+// Regex matching the API version prefix (e.g. "/v1") at the start of a request path.
+var apiVersionPrefixRe = regexp.MustCompile(`^/v[0-9]+(/|$)`)
+
+// apiVersion represents a version of the API, served under its own path prefix, and the routes that make it up.
+type apiVersion struct {
+	// Path prefix under which the version's endpoints are served (e.g. "/v1").
+	prefix string
+
+	// Routes making up the version.
+	routes *routeTable
+}
+
+// route maps a request method and path to the handler serving it, along with the access that it requires.
+type route struct {
+	method string
+	path   string
+
+	// Whether the route matches every path under the given one, rather than just the path itself.
+	isPathPrefix bool
+
+	// Role that a token must have to access the route, or blank if the route is public.
+	role tokenRole
+
+	// Whether the route issues configuration requests, in which case unauthorized attempts to use it are counted in
+	// the statistics on the client's configuration requests.
+	issuesConfiguration bool
+
+	handler http.Handler
+}
+
+// routeTable collects the routes making up a version of the API.
+type routeTable struct {
+	// Endpoints of the API, which are served under the version's path prefix.
+	api []route
+
+	// Pages for browsers, which are only served without a prefix so that the links between them keep working.
+	pages []route
+}
+
+// handle adds an endpoint requiring the given role, or none if it is blank.
+func (routes *routeTable) handle(method, path string, role tokenRole, handler http.HandlerFunc) {
+	routes.api = append(routes.api, route{method: method, path: path, role: role, handler: handler})
+}
+
+// handleConfiguration adds an endpoint issuing configuration requests, which requires the admin role.
+func (routes *routeTable) handleConfiguration(method, path string, handler http.HandlerFunc) {
+	routes.api = append(
+		routes.api,
+		route{method: method, path: path, role: roleAdmin, issuesConfiguration: true, handler: handler},
+	)
+}
+
+// handlePage adds a page requiring the given role, or none if it is blank.
+func (routes *routeTable) handlePage(path string, role tokenRole, handler http.HandlerFunc) {
+	routes.pages = append(routes.pages, route{method: http.MethodGet, path: path, role: role, handler: handler})
+}
+
+// handlePagePrefix adds a public handler for every page under the given path (e.g. static assets).
+func (routes *routeTable) handlePagePrefix(prefix string, handler http.Handler) {
+	routes.pages = append(
+		routes.pages, route{method: http.MethodGet, path: prefix, isPathPrefix: true, handler: handler},
+	)
+}
+
+// apiVersions returns the versions of the API, oldest first. The endpoints of the first version are also served
+// without a prefix, since that is where clients written before the API was versioned expect them.
+func (web *WebServer) apiVersions() []apiVersion {
+	return []apiVersion{
+		{prefix: "/v1", routes: web.v1Routes()},
+	}
+}
+
+// v1Routes returns the routes making up version 1 of the API.
+func (web *WebServer) v1Routes() *routeTable {
+	routes := &routeTable{}
+	routes.handlePage("/", "", web.rootHandler)
+	routes.handle("GET", "/health", "", web.healthHandler)
+	routes.handle("GET", "/status", roleReadOnly, web.statusHandler)
+	routes.handle("GET", "/status/history", roleReadOnly, web.statusHistoryHandler)
+	routes.handle("POST", "/support-bundle", roleAdmin, web.supportBundleHandler)
+	routes.handleConfiguration("POST", "/configuration", web.configurationHandler)
+	routes.handle("GET", "/configuration/origins", roleReadOnly, web.requestOriginsHandler)
+	routes.handle("GET", "/configuration/requests", roleReadOnly, web.configurationRequestsHandler)
+	routes.handle("GET", "/configuration/requests/{id}", roleReadOnly, web.configurationRequestHandler)
+	routes.handle("DELETE", "/configuration/requests/{id}", roleAdmin, web.configurationRequestCancelHandler)
+	routes.handle("POST", firmwareUploadPath, roleAdmin, web.firmwareHandler)
+	routes.handle("GET", "/alerts", roleReadOnly, web.alertsHandler)
+	routes.handle("POST", "/debug/exec", roleAdmin, web.debugExecHandler)
+	routes.handle("GET", "/debug/shell", roleAdmin, web.shellTelemetryHandler)
+	routes.handle("GET", "/fleet/status", roleReadOnly, web.fleetStatusHandler)
+	routes.handle("GET", "/logs/system", roleAdmin, web.systemLogHandler)
+	routes.handle("GET", maintenancePath, roleReadOnly, web.maintenanceHandler)
+	routes.handle("POST", maintenancePath, roleAdmin, web.maintenanceUpdateHandler)
+	routes.handle("POST", "/password", roleAdmin, web.passwordRotateHandler)
+	routes.handle("POST", provisionPath, roleAdmin, web.provisionHandler)
+	routes.handle("GET", "/settings", roleAdmin, web.settingsHandler)
+	routes.handle("POST", "/settings/reload", roleAdmin, web.settingsReloadHandler)
+	routes.handle("GET", "/storage", roleReadOnly, web.storageHandler)
+	routes.handle("GET", "/stats", roleReadOnly, web.statsHandler)
+	routes.handle("POST", apiUpgradePath, roleAdmin, web.apiUpgradeHandler)
+	routes.handle("GET", "/tokens", roleAdmin, web.tokensHandler)
+	routes.handle("POST", "/tokens", roleAdmin, web.tokenCreateHandler)
+	routes.handle("DELETE", "/tokens/{name}", roleAdmin, web.tokenDeleteHandler)
+	addRoutes(routes, web)
+	addFaultInjectionRoutes(routes, web)
+	return routes
+}
+
+// newRouter sets up the mapping between URLs and handlers, behind the middleware that applies to every request.
+func (web *WebServer) newRouter() http.Handler {
+	router := mux.NewRouter()
+	versions := web.apiVersions()
+	for _, version := range versions {
+		web.registerRoutes(router.PathPrefix(version.prefix).Subrouter(), version.routes.api)
+	}
+	unversionedRoutes := versions[0].routes
+	web.registerRoutes(router, unversionedRoutes.pages)
+	web.registerRoutes(router, unversionedRoutes.api)
+
+	return chainMiddleware(
+		router,
+		web.serveUnderBasePath,
+		web.applyForwardedHeaders,
+		web.assignCorrelationIds,
+		web.logRequests,
+		web.recoverPanics,
+		web.applyCorsPolicy,
+		web.limitRequestRate,
+		compressResponses,
+		web.rejectUntilProvisioned,
+		web.rejectChangesDuringMaintenance,
+		web.limitRequestBodySize,
+	)
+}
+
+// registerRoutes adds the given routes to the given router, each behind a check of the role that it requires.
+func (web *WebServer) registerRoutes(router *mux.Router, routes []route) {
+	for _, route := range routes {
+		handler := web.requireRole(route.role, route.issuesConfiguration)(route.handler)
+		if route.isPathPrefix {
+			router.PathPrefix(route.path).Handler(handler).Methods(route.method)
+		} else {
+			router.Handle(route.path, handler).Methods(route.method)
+		}
+	}
+}
+
+// unversionedPath returns the path of the given request without any API version prefix, for checking which endpoint it
+// is for before it has been routed.
+func unversionedPath(r *http.Request) string {
+	if prefix := apiVersionPrefixRe.FindString(r.URL.Path); prefix != "" {
+		return "/" + r.URL.Path[len(prefix):]
+	}
+	return r.URL.Path
+}
@@ -0,0 +1,51 @@
+package web
+
+import (
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestWeb_versionedRoutes(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+
+	// Endpoints are served both under the version prefix and at their original unversioned paths.
+	for _, path := range []string{"/v1/health", "/health"} {
+		recorder := web.getHttpResponse(path)
+		assert.Equal(t, 200, recorder.Code, path)
+		assert.Equal(t, "OK\n", recorder.Body.String(), path)
+	}
+	assert.Equal(t, 200, web.getHttpResponse("/v1/status").Code)
+	assert.Equal(t, 404, web.getHttpResponse("/v2/status").Code)
+	assert.Equal(t, 404, web.getHttpResponse("/v1/nonexistent").Code)
+
+	// Pages are only served at their unversioned paths.
+	assert.NotEqual(t, 404, web.getHttpResponse("/").Code)
+	assert.Equal(t, 404, web.getHttpResponse("/v1/").Code)
+
+	// The role required by each endpoint applies under either path.
+	web.password = "mypassword"
+	for _, path := range []string{"/v1/status", "/status"} {
+		recorder := web.getHttpResponse(path)
+		assert.Equal(t, 401, recorder.Code, path)
+		assert.Contains(t, recorder.Body.String(), "not authorized", path)
+	}
+	recorder := web.getHttpResponseWithHeaders("/v1/status", map[string]string{"Authorization": "Bearer mypassword"})
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, 200, web.getHttpResponse("/v1/health").Code)
+}
+
+func TestUnversionedPath(t *testing.T) {
+	for path, expectedPath := range map[string]string{
+		"/v1/firmware":     "/firmware",
+		"/v12/status":      "/status",
+		"/v1":              "/",
+		"/firmware":        "/firmware",
+		"/video/v1/status": "/video/v1/status",
+		"/v1beta/status":   "/v1beta/status",
+	} {
+		request, _ := http.NewRequest("GET", path, nil)
+		assert.Equal(t, expectedPath, unversionedPath(request), path)
+	}
+}
@@ -0,0 +1,25 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// settingsHandler returns a JSON dump of the API settings currently in effect.
+func (web *WebServer) settingsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(web.radio.GetSettings()); err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+	}
+}
+
+// settingsReloadHandler re-reads the API settings file and applies it without restarting the API or reconfiguring the
+// radio.
+func (web *WebServer) settingsReloadHandler(w http.ResponseWriter, r *http.Request) {
+	if err := web.ReloadSettings(); err != nil {
+		handleWebErr(w, r, fmt.Errorf("failed to reload settings: %v", err), http.StatusBadRequest)
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Settings reloaded.")
+}
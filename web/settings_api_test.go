@@ -0,0 +1,59 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_settingsHandler(t *testing.T) {
+	r := radio.NewRadio()
+	web := NewWebServer(r)
+	settings := r.GetSettings()
+	settings.MonitoringPollIntervalSec = 7
+	r.SetSettings(settings)
+
+	recorder := web.getHttpResponse("/settings")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header()["Content-Type"][0])
+	var responseSettings radio.Settings
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &responseSettings))
+	assert.Equal(t, r.GetSettings(), responseSettings)
+}
+
+func TestWeb_settingsReloadHandler(t *testing.T) {
+	r := radio.NewRadio()
+	web := NewWebServer(r)
+	settings := r.GetSettings()
+	settings.MonitoringPollIntervalSec = 7
+	r.SetSettings(settings)
+
+	// With no settings file present, reloading reverts to the defaults.
+	recorder := web.postHttpResponse("/settings/reload", "")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Settings reloaded")
+	assert.Equal(t, radio.NewRadio().GetSettings(), r.GetSettings())
+}
+
+func TestWeb_settingsHandlersAuthorization(t *testing.T) {
+	r := radio.NewRadio()
+	web := NewWebServer(r)
+	web.password = "mypassword"
+	settings := r.GetSettings()
+	settings.MonitoringPollIntervalSec = 7
+	r.SetSettings(settings)
+
+	recorder := web.getHttpResponse("/settings")
+	assert.Equal(t, 401, recorder.Code)
+	recorder = web.postHttpResponse("/settings/reload", "")
+	assert.Equal(t, 401, recorder.Code)
+	assert.Equal(t, 7, r.GetSettings().MonitoringPollIntervalSec)
+
+	headers := map[string]string{"Authorization": "Bearer mypassword"}
+	recorder = web.getHttpResponseWithHeaders("/settings", headers)
+	assert.Equal(t, 200, recorder.Code)
+	recorder = web.postHttpResponseWithHeaders("/settings/reload", "", headers)
+	assert.Equal(t, 200, recorder.Code)
+	assert.NotEqual(t, 7, r.GetSettings().MonitoringPollIntervalSec)
+}
@@ -0,0 +1,18 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"net/http"
+)
+
+// shellTelemetryHandler returns a JSON dump of the statistics and recent failures of the shell commands run by the API.
+func (web *WebServer) shellTelemetryHandler(w http.ResponseWriter, r *http.Request) {
+	jsonData, err := json.MarshalIndent(radio.GetShellTelemetry(), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
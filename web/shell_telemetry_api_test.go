@@ -0,0 +1,24 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_shellTelemetryHandler(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.password = "mypassword"
+
+	recorder := web.getHttpResponse("/debug/shell")
+	assert.Equal(t, 401, recorder.Code)
+
+	recorder = web.getHttpResponseWithHeaders("/debug/shell", map[string]string{"Authorization": "Bearer mypassword"})
+	assert.Equal(t, 200, recorder.Code)
+	var telemetry radio.ShellTelemetry
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &telemetry))
+
+	// Creating the radio above runs real commands, which should have been recorded.
+	assert.NotEmpty(t, telemetry.Commands)
+}
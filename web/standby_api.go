@@ -0,0 +1,72 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"net/http"
+)
+
+// standbyConfigurationHandler receives the configuration mirrored from the primary access point and adds a request for
+// whatever it takes to bring this standby in line with it to the asynchronous queue.
+func (web *WebServer) standbyConfigurationHandler(w http.ResponseWriter, r *http.Request) {
+	origin := web.requestOrigin(r)
+	if !web.checkBaseline(w, r, origin) {
+		return
+	}
+
+	var desired radio.ConfigurationRequest
+	if err := json.NewDecoder(r.Body).Decode(&desired); err != nil {
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(w, r, fmt.Errorf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := web.radio.ReceiveMirroredConfiguration(); err != nil {
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(w, r, err, http.StatusConflict)
+		return
+	}
+
+	request := web.radio.ConfigurationChanges(desired)
+	if request.IsEmpty() {
+		web.requestOrigins.record(origin, outcomeAccepted)
+		_, _ = fmt.Fprintln(w, "Standby is already in sync with the mirrored configuration.")
+		return
+	}
+	if err := request.Validate(web.radio); err != nil {
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(w, r, fmt.Errorf("invalid configuration: %w", err), statusCodeForError(err, http.StatusBadRequest))
+		return
+	}
+
+	id, err := web.radio.EnqueueConfigurationRequest(request.WithCorrelationId(requestCorrelationId(r)))
+	if err != nil {
+		web.requestOrigins.record(origin, outcomeRejected)
+		handleWebErr(w, r, err, http.StatusServiceUnavailable)
+		return
+	}
+	web.requestOrigins.record(origin, outcomeAccepted)
+	radio.LogWithCorrelationId(
+		requestCorrelationId(r),
+		"Received mirrored configuration as request %d from %s: %+v",
+		id,
+		origin,
+		request,
+	)
+	w.Header().Set("Location", web.externalPath(fmt.Sprintf("/configuration/requests/%d", id)))
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprintf(w, "Mirrored configuration received as request %d and will be applied asynchronously.\n", id)
+}
+
+// failoverHandler has this standby take over from the primary access point.
+func (web *WebServer) failoverHandler(w http.ResponseWriter, r *http.Request) {
+	if err := web.radio.Failover(); err != nil {
+		handleWebErr(w, r, err, http.StatusConflict)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprintln(w, "Standby will take over and bring its team networks up on the next monitoring poll.")
+}
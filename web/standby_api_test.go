@@ -0,0 +1,103 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_standbyConfigurationHandler(t *testing.T) {
+	ap := radio.NewRadio()
+	ap.Type = radio.TypeLinksys
+	ap.Channel = 149
+	web := NewWebServer(ap)
+	desired := ap.EffectiveConfiguration()
+	desiredJson, _ := json.Marshal(desired)
+
+	// Only a standby accepts mirrored configurations.
+	recorder := web.postHttpResponse("/standby/configuration", string(desiredJson))
+	assert.Equal(t, 409, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "radio is not configured as a standby")
+
+	settings := ap.GetSettings()
+	settings.Standby.Role = "STANDBY"
+	ap.SetSettings(settings)
+	recorder = web.postHttpResponse("/standby/configuration", string(desiredJson))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "already in sync")
+	assert.Equal(t, 0, len(ap.ConfigurationRequestChannel))
+	assert.NotNil(t, ap.GetStandby().LastMirroredAt)
+
+	// Only the differences from the standby's current configuration are applied.
+	desired.Channel = 36
+	desired.StationConfigurations["blue3"] = &radio.StationConfiguration{Ssid: "1114", WpaKey: "abcdefgh"}
+	desired.OverrideChannelGuard = true
+	desiredJson, _ = json.Marshal(desired)
+	recorder = web.postHttpResponse("/standby/configuration", string(desiredJson))
+	assert.Equal(t, 202, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Mirrored configuration received as request 1")
+	assert.Equal(t, "/configuration/requests/1", recorder.Header().Get("Location"))
+	if assert.Equal(t, 1, len(ap.ConfigurationRequestChannel)) {
+		request := <-ap.ConfigurationRequestChannel
+		assert.Equal(t, 36, request.Channel)
+		assert.True(t, request.OverrideChannelGuard)
+		assert.True(t, request.PreserveOmittedStations)
+		assert.Equal(
+			t,
+			map[string]*radio.StationConfiguration{"blue3": {Ssid: "1114", WpaKey: "abcdefgh"}},
+			request.StationConfigurations,
+		)
+	}
+
+	// Invalid requests.
+	recorder = web.postHttpResponse("/standby/configuration", "{")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid JSON")
+	recorder = web.postHttpResponse("/standby/configuration", `{"channel": 37}`)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid channel for TypeLinksys: 37")
+
+	// Mirrored configurations are no longer accepted once the standby has taken over.
+	assert.Nil(t, ap.Failover())
+	recorder = web.postHttpResponse("/standby/configuration", string(desiredJson))
+	assert.Equal(t, 409, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "standby has already taken over from the primary")
+}
+
+func TestWeb_standbyConfigurationHandlerUnauthorized(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.password = "mypassword"
+
+	recorder := web.postHttpResponse("/standby/configuration", "{}")
+	assert.Equal(t, 401, recorder.Code)
+}
+
+func TestWeb_failoverHandler(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+	web.password = "mypassword"
+	headers := map[string]string{"Authorization": "Bearer mypassword"}
+
+	recorder := web.postHttpResponse("/failover", "")
+	assert.Equal(t, 401, recorder.Code)
+
+	recorder = web.postHttpResponseWithHeaders("/failover", "", headers)
+	assert.Equal(t, 409, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "radio is not configured as a standby")
+
+	settings := ap.GetSettings()
+	settings.Standby.Role = "STANDBY"
+	ap.SetSettings(settings)
+	recorder = web.postHttpResponseWithHeaders("/failover", "", headers)
+	assert.Equal(t, 202, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Standby will take over")
+	assert.True(t, ap.GetStandby().HasTakenOver)
+
+	recorder = web.postHttpResponseWithHeaders("/failover", "", headers)
+	assert.Equal(t, 409, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "standby has already taken over from the primary")
+}
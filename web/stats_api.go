@@ -0,0 +1,18 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// statsHandler returns a JSON report of how configuring the radio has gone on each hardware type and firmware
+// version it has run, for identifying combinations that misbehave across the fleet.
+func (web *WebServer) statsHandler(w http.ResponseWriter, r *http.Request) {
+	jsonData, err := json.MarshalIndent(web.radio.GetConfigurationStats(), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
@@ -0,0 +1,31 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_statsHandler(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+
+	recorder := web.getHttpResponse("/stats")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	var report radio.ConfigurationStatsReport
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+	assert.Equal(t, web.radio.GetConfigurationStats().HardwareType, report.HardwareType)
+	assert.Empty(t, report.Combinations)
+}
+
+func TestWeb_statsHandlerAuthorization(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.password = "mypassword"
+
+	recorder := web.getHttpResponse("/stats")
+	assert.Equal(t, 401, recorder.Code)
+
+	recorder = web.getHttpResponseWithHeaders("/stats", map[string]string{"Authorization": "Bearer mypassword"})
+	assert.Equal(t, 200, recorder.Code)
+}
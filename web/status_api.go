@@ -1,32 +1,175 @@
 package web
 
 import (
+	"bytes"
 	"encoding/json"
-	"errors"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
 	"net/http"
+	"strconv"
+	"sync"
 )
 
+const (
+	// Query parameter through which a client selects the legacy status format it expects (e.g. "?compat=v1").
+	statusCompatParam = "compat"
+
+	// Header through which a client that can't change the URL it polls selects the legacy status format it expects.
+	statusCompatHeader = "X-Status-Compat"
+
+	// Query parameter through which a client asks for only the stations whose status has changed since the given
+	// revision (e.g. "?changedSince=42").
+	statusChangedSinceParam = "changedSince"
+
+	// Header giving the revision of the status returned, from which a client can then poll for changes.
+	statusRevisionHeader = "X-Status-Revision"
+)
+
+// statusCache holds the most recently marshaled status JSON so that concurrent pollers don't each have to re-marshal
+// the whole radio state when it hasn't changed.
+type statusCache struct {
+	mutex    sync.Mutex
+	revision uint64
+	jsonData []byte
+
+	// Number of times the status has been marshaled, which is served to clients as the revision of the cached status.
+	// It starts from one, leaving zero for a client that hasn't seen any status yet.
+	servedRevision uint64
+
+	// Marshaled status of each station as of the cached revision, keyed by station name.
+	stationJson map[string]json.RawMessage
+
+	// Served revision at which the marshaled status of each station last changed, keyed by station name.
+	stationRevisions map[string]uint64
+}
+
+// stationStatusChanges represents the stations whose status has changed since a revision given by the client.
+type stationStatusChanges struct {
+	// Revision of the status as of which the changes are given, to be passed back by the client when it next polls.
+	Revision uint64 `json:"revision"`
+
+	// Current status of each station that has changed, keyed by station name. A station whose team has been removed
+	// is given as null.
+	StationStatuses map[string]json.RawMessage `json:"stationStatuses"`
+}
+
 // statusHandler returns a JSON dump of the radio status.
 func (web *WebServer) statusHandler(w http.ResponseWriter, r *http.Request) {
-	if !web.isAuthorized(r) {
-		handleWebErr(
-			w,
-			errors.New("not authorized; must provide 'Authorization: Bearer [password]' header"),
-			http.StatusUnauthorized,
-		)
-		return
+	var jsonData []byte
+	var revision uint64
+	var err error
+	if changedSince := r.URL.Query().Get(statusChangedSinceParam); changedSince != "" {
+		since, parseErr := strconv.ParseUint(changedSince, 10, 64)
+		if parseErr != nil {
+			handleWebErr(
+				w,
+				r,
+				fmt.Errorf("invalid %s: %q (expecting a status revision)", statusChangedSinceParam, changedSince),
+				http.StatusBadRequest,
+			)
+			return
+		}
+		jsonData, revision, err = web.getStationChangesJson(since)
+	} else {
+		jsonData, revision, err = web.getStatusJson()
 	}
-
-	jsonData, err := json.MarshalIndent(web.radio, "", "  ")
 	if err != nil {
-		handleWebErr(w, err, http.StatusInternalServerError)
+		handleWebErr(w, r, err, http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_, err = w.Write(jsonData)
+	if profile := requestedStatusCompatProfile(r); profile != "" {
+		rules, ok := web.radio.GetSettings().StatusCompatProfile(profile)
+		if !ok {
+			handleWebErr(
+				w,
+				r,
+				fmt.Errorf("unknown status compatibility profile: %s", profile),
+				http.StatusBadRequest,
+			)
+			return
+		}
+		if jsonData, err = radio.ApplyStatusCompatRules(jsonData, rules); err != nil {
+			handleWebErr(w, r, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	w.Header().Add("Vary", statusCompatHeader)
+	w.Header().Set(statusRevisionHeader, strconv.FormatUint(revision, 10))
+	writeNegotiatedResponse(w, r, jsonData)
+}
+
+// requestedStatusCompatProfile returns the name of the status compatibility profile requested by the client via the
+// query parameter or, failing that, the header, or blank if it expects the current format.
+func requestedStatusCompatProfile(r *http.Request) string {
+	if profile := r.URL.Query().Get(statusCompatParam); profile != "" {
+		return profile
+	}
+	return r.Header.Get(statusCompatHeader)
+}
+
+// getStatusJson returns the marshaled radio status and its revision, re-marshaling it only if the radio state has
+// changed since it was last cached.
+func (web *WebServer) getStatusJson() ([]byte, uint64, error) {
+	web.statusCache.mutex.Lock()
+	defer web.statusCache.mutex.Unlock()
+	if err := web.refreshStatusCache(); err != nil {
+		return nil, 0, err
+	}
+	return web.statusCache.jsonData, web.statusCache.servedRevision, nil
+}
+
+// getStationChangesJson returns the marshaled status of only the stations that have changed since the given revision,
+// along with the current revision. Every station is returned if the given revision is zero or is from before the API
+// last restarted.
+func (web *WebServer) getStationChangesJson(since uint64) ([]byte, uint64, error) {
+	web.statusCache.mutex.Lock()
+	defer web.statusCache.mutex.Unlock()
+	if err := web.refreshStatusCache(); err != nil {
+		return nil, 0, err
+	}
+
+	revision := web.statusCache.servedRevision
+	changes := stationStatusChanges{Revision: revision, StationStatuses: make(map[string]json.RawMessage)}
+	for name, stationJson := range web.statusCache.stationJson {
+		if since == 0 || since > revision || web.statusCache.stationRevisions[name] > since {
+			changes.StationStatuses[name] = stationJson
+		}
+	}
+	jsonData, err := json.MarshalIndent(changes, "", "  ")
+	return jsonData, revision, err
+}
+
+// refreshStatusCache re-marshals the radio status if the radio state has changed since it was last cached, noting the
+// served revision at which each station's status changes. The cache mutex must be held by the caller.
+func (web *WebServer) refreshStatusCache() error {
+	cache := &web.statusCache
+	revision := web.radio.StatusRevision()
+	if cache.jsonData != nil && cache.revision == revision {
+		return nil
+	}
+
+	jsonData, err := web.radio.MarshalStatus()
 	if err != nil {
-		handleWebErr(w, err, http.StatusInternalServerError)
-		return
+		return err
+	}
+	var status struct {
+		StationStatuses map[string]json.RawMessage `json:"stationStatuses"`
+	}
+	if err = json.Unmarshal(jsonData, &status); err != nil {
+		return err
+	}
+	if cache.stationRevisions == nil {
+		cache.stationRevisions = make(map[string]uint64)
+	}
+	cache.servedRevision++
+	for name, stationJson := range status.StationStatuses {
+		if previousJson, ok := cache.stationJson[name]; !ok || !bytes.Equal(previousJson, stationJson) {
+			cache.stationRevisions[name] = cache.servedRevision
+		}
 	}
+	cache.revision = revision
+	cache.jsonData = jsonData
+	cache.stationJson = status.StationStatuses
+	return nil
 }
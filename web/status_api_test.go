@@ -5,9 +5,13 @@ package web
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/patfair/frc-radio-api/radio"
 	"github.com/stretchr/testify/assert"
+	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 )
 
 func TestWeb_statusHandler(t *testing.T) {
@@ -55,3 +59,116 @@ func TestWeb_statusHandlerAuthorization(t *testing.T) {
 	recorder = web.getHttpResponseWithHeaders("/status", map[string]string{"Authorization": "Bearer mypassword"})
 	assert.Equal(t, 200, recorder.Code)
 }
+
+func TestWeb_statusHandlerCaching(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+
+	ap.Channel = 36
+	recorder := web.getHttpResponse("/status")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "\"channel\": 36")
+
+	// The cached status is served until the radio signals that its state has changed.
+	ap.Channel = 149
+	recorder = web.getHttpResponse("/status")
+	assert.Contains(t, recorder.Body.String(), "\"channel\": 36")
+
+	ap.RecordHeartbeat()
+	recorder = web.getHttpResponse("/status")
+	assert.Contains(t, recorder.Body.String(), "\"channel\": 149")
+}
+
+func TestWeb_statusHandlerChangedSince(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+	ap.StationStatuses["red1"] = &radio.NetworkStatus{Ssid: "1111", SignalNoiseRatio: 30}
+	ap.StationStatuses["blue2"] = &radio.NetworkStatus{Ssid: "5555", SignalNoiseRatio: 40}
+
+	// Polling from zero returns every station, along with the revision to poll from next.
+	recorder := web.getHttpResponse("/status?changedSince=0")
+	assert.Equal(t, 200, recorder.Code)
+	var changes stationStatusChanges
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &changes))
+	assert.Equal(t, 6, len(changes.StationStatuses))
+	assert.Equal(t, "null", string(changes.StationStatuses["red2"]))
+	assert.Equal(t, strconv.FormatUint(changes.Revision, 10), recorder.Header().Get("X-Status-Revision"))
+	revision := changes.Revision
+
+	// Nothing is returned until something changes.
+	ap.RecordHeartbeat()
+	recorder = web.getHttpResponse(fmt.Sprintf("/status?changedSince=%d", revision))
+	assert.Equal(t, 200, recorder.Code)
+	changes = stationStatusChanges{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &changes))
+	assert.Greater(t, changes.Revision, revision)
+	assert.Empty(t, changes.StationStatuses)
+
+	// Only the stations that have changed are returned.
+	ap.StationStatuses["blue2"].SignalNoiseRatio = 35
+	ap.StationStatuses["red2"] = &radio.NetworkStatus{Ssid: "2222"}
+	ap.RecordHeartbeat()
+	recorder = web.getHttpResponse(fmt.Sprintf("/status?changedSince=%d", revision))
+	changes = stationStatusChanges{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &changes))
+	assert.Equal(t, 2, len(changes.StationStatuses))
+	assert.Contains(t, changes.StationStatuses, "red2")
+	var blue2 radio.NetworkStatus
+	assert.Nil(t, json.Unmarshal(changes.StationStatuses["blue2"], &blue2))
+	assert.Equal(t, 35, blue2.SignalNoiseRatio)
+
+	// A revision from before the API restarted returns every station.
+	recorder = web.getHttpResponse(fmt.Sprintf("/status?changedSince=%d", changes.Revision+100))
+	changes = stationStatusChanges{}
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &changes))
+	assert.Equal(t, 6, len(changes.StationStatuses))
+
+	// The full status also gives its revision.
+	recorder = web.getHttpResponse("/status")
+	assert.Equal(t, strconv.FormatUint(changes.Revision, 10), recorder.Header().Get("X-Status-Revision"))
+
+	recorder = web.getHttpResponse("/status?changedSince=abc")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid changedSince: \"abc\" (expecting a status revision)")
+}
+
+func TestWeb_statusHandlerCompatProfile(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+	ap.Channel = 36
+	ap.LastError = &radio.ConfigurationError{RequestId: 3, Kind: "COMMIT_FAILED", Message: "disk full"}
+	ap.StatusChangedAt.Wallclock = time.Date(2024, 3, 2, 10, 14, 58, 0, time.UTC)
+
+	// The current format is served unless the client asks for a legacy one.
+	recorder := web.getHttpResponse("/status")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "\"kind\": \"COMMIT_FAILED\"")
+	assert.Equal(t, []string{"X-Status-Compat", "Accept"}, recorder.Header().Values("Vary"))
+
+	for _, recorder = range []*httptest.ResponseRecorder{
+		web.getHttpResponse("/status?compat=v1"),
+		web.getHttpResponseWithHeaders("/status", map[string]string{"X-Status-Compat": "v1"}),
+	} {
+		assert.Equal(t, 200, recorder.Code)
+		var status map[string]any
+		assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+		assert.Equal(t, 36.0, status["channel"])
+		assert.Equal(t, "disk full", status["lastError"])
+		assert.Equal(t, "2024-03-02T10:14:58Z", status["statusChangedAt"])
+	}
+
+	// Profiles in the settings are also available.
+	settings := ap.GetSettings()
+	settings.StatusCompatProfiles = map[string][]radio.StatusCompatRule{
+		"fms2023": {{Path: "channel", LegacyName: "currentChannel", Conversion: "STRING"}},
+	}
+	ap.SetSettings(settings)
+	recorder = web.getHttpResponse("/status?compat=fms2023")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "\"currentChannel\": \"36\"")
+	assert.NotContains(t, recorder.Body.String(), "\"channel\"")
+
+	recorder = web.getHttpResponse("/status?compat=v0")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "unknown status compatibility profile: v0")
+}
@@ -0,0 +1,51 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"net/http"
+	"strconv"
+)
+
+// statusHistoryHandler returns a JSON list of the link telemetry recorded at each recent monitoring poll, oldest first.
+// If the "since" parameter gives the monotonic timestamp of a sample already received, only later samples are returned
+// so that a client can keep its copy up to date without downloading the whole history again. The common pagination and
+// filtering parameters also apply, with the station filter narrowing each sample down to the given network.
+func (web *WebServer) statusHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	var since int64
+	if sinceParam := r.URL.Query().Get("since"); sinceParam != "" {
+		var err error
+		if since, err = strconv.ParseInt(sinceParam, 10, 64); err != nil {
+			handleWebErr(w, r, fmt.Errorf("invalid value for since: %s", sinceParam), http.StatusBadRequest)
+			return
+		}
+	}
+
+	query, err := parseListQuery(r)
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusBadRequest)
+		return
+	}
+	var entries []listEntry
+	for _, sample := range web.radio.GetMonitoringHistory(since) {
+		if query.station != "" {
+			network, ok := sample.Networks[query.station]
+			if !ok {
+				continue
+			}
+			sample.Networks = map[string]radio.NetworkSample{query.station: network}
+		}
+		entries = append(
+			entries, listEntry{seq: sample.MonitoredAt.MonotonicNs, at: sample.MonitoredAt.Wallclock, item: sample},
+		)
+	}
+
+	jsonData, err := json.MarshalIndent(query.render(entries), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
@@ -0,0 +1,49 @@
+package web
+
+import (
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+	"testing"
+)
+
+func TestWeb_statusHistoryHandler(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+
+	recorder := web.getHttpResponse("/status/history")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "[]", recorder.Body.String())
+
+	recorder = web.getHttpResponseWithHeaders(
+		"/status/history?since=123", map[string]string{"Accept": "application/msgpack"},
+	)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/msgpack", recorder.Header().Get("Content-Type"))
+	var samples []map[string]any
+	assert.Nil(t, msgpack.Unmarshal(recorder.Body.Bytes(), &samples))
+	assert.Empty(t, samples)
+
+	recorder = web.getHttpResponse("/status/history?since=yesterday")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid value for since: yesterday")
+
+	recorder = web.getHttpResponse("/status/history?since=123&station=blue2")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "{\n  \"items\": [],\n  \"nextCursor\": \"\"\n}", recorder.Body.String())
+	recorder = web.getHttpResponse("/status/history?cursor=next")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid cursor: next")
+}
+
+func TestWeb_statusHistoryHandlerAuthorization(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.password = "mypassword"
+
+	recorder := web.getHttpResponse("/status/history")
+	assert.Equal(t, 401, recorder.Code)
+	recorder = web.getHttpResponseWithHeaders(
+		"/status/history", map[string]string{"Authorization": "Bearer mypassword"},
+	)
+	assert.Equal(t, 200, recorder.Code)
+}
@@ -0,0 +1,26 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// statusIndicatorsHandler returns a JSON dump of what the hardware status LEDs are showing and the conditions
+// reported by the GPIO inputs.
+func (web *WebServer) statusIndicatorsHandler(w http.ResponseWriter, r *http.Request) {
+	jsonData, err := json.MarshalIndent(web.radio.GetStatusIndicators(), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(jsonData)
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+}
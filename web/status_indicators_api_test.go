@@ -0,0 +1,36 @@
+// This file is specific to the robot radio version of the API.
+//go:build robot
+
+package web
+
+import (
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWeb_statusIndicatorsHandler(t *testing.T) {
+	r := &radio.Radio{}
+	settings := r.GetSettings()
+	gpioValuePath := filepath.Join(t.TempDir(), "value")
+	assert.Nil(t, os.WriteFile(gpioValuePath, []byte("1\n"), 0644))
+	settings.StatusIndicators.Inputs = []radio.GpioInputSettings{{Name: "poePower", GpioValuePath: gpioValuePath}}
+	r.SetSettings(settings)
+	web := &WebServer{radio: r}
+
+	recorder := web.getHttpResponse("/indicators")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.Equal(
+		t,
+		"{\n  \"linkLed\": \"\",\n  \"fieldLed\": \"\",\n  \"inputs\": [\n    {\n      \"name\": \"poePower\",\n"+
+			"      \"isActive\": true,\n      \"error\": \"\"\n    }\n  ]\n}",
+		recorder.Body.String(),
+	)
+
+	web.password = "mypassword"
+	recorder = web.getHttpResponse("/indicators")
+	assert.Equal(t, 401, recorder.Code)
+}
@@ -0,0 +1,18 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// storageHandler returns a JSON report of the radio's flash storage and of the retention policies that keep diagnostic
+// files from filling it.
+func (web *WebServer) storageHandler(w http.ResponseWriter, r *http.Request) {
+	jsonData, err := json.MarshalIndent(web.radio.GetStorageReport(), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
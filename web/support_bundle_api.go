@@ -0,0 +1,67 @@
+package web
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"io"
+	"net/http"
+	"time"
+)
+
+// supportBundleHandler collects the radio's logs, configuration, and state into a gzipped tarball and returns it as a
+// download, for attaching to bug reports.
+func (web *WebServer) supportBundleHandler(w http.ResponseWriter, r *http.Request) {
+	files := web.radio.CollectSupportFiles()
+	statusJson, _, err := web.getStatusJson()
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	originsJson, err := json.MarshalIndent(web.requestOrigins.list(), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	files = append(
+		files,
+		radio.SupportFile{Name: "status.json", Data: statusJson},
+		radio.SupportFile{Name: "configuration-origins.json", Data: originsJson},
+	)
+
+	now := time.Now()
+	bundleName := fmt.Sprintf("frc-radio-api-support-%s", now.Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", bundleName+".tar.gz"))
+	if err = writeSupportBundle(w, bundleName, now, files); err != nil {
+		// The response has already started, so the error can only be logged.
+		radio.LogWithCorrelationId(requestCorrelationId(r), "Error writing support bundle: %v", err)
+	}
+}
+
+// writeSupportBundle writes the given files to the given writer as a gzipped tarball, within a top-level directory of
+// the given name.
+func writeSupportBundle(w io.Writer, bundleName string, modTime time.Time, files []radio.SupportFile) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, file := range files {
+		header := &tar.Header{
+			Name:    bundleName + "/" + file.Name,
+			Mode:    0644,
+			Size:    int64(len(file.Data)),
+			ModTime: modTime,
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tarWriter.Write(file.Data); err != nil {
+			return err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
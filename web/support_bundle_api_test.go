@@ -0,0 +1,60 @@
+package web
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWeb_supportBundleHandler(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+
+	recorder := web.postHttpResponse("/support-bundle", "")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/gzip", recorder.Header().Get("Content-Type"))
+	assert.Regexp(
+		t,
+		`^attachment; filename="frc-radio-api-support-\d{8}-\d{6}\.tar\.gz"$`,
+		recorder.Header().Get("Content-Disposition"),
+	)
+
+	gzipReader, err := gzip.NewReader(recorder.Body)
+	if !assert.Nil(t, err) {
+		return
+	}
+	tarReader := tar.NewReader(gzipReader)
+	files := make(map[string]string)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.True(t, strings.HasPrefix(header.Name, "frc-radio-api-support-"))
+		data, _ := io.ReadAll(tarReader)
+		files[header.Name[strings.Index(header.Name, "/")+1:]] = string(data)
+	}
+	for _, name := range []string{"version.json", "status.json", "configuration-origins.json", "alerts.json"} {
+		assert.Contains(t, files, name)
+	}
+	assert.Contains(t, files["status.json"], "\"status\"")
+}
+
+func TestWeb_supportBundleHandlerAuthorization(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.password = "mypassword"
+
+	recorder := web.postHttpResponse("/support-bundle", "")
+	assert.Equal(t, 401, recorder.Code)
+
+	recorder = web.postHttpResponseWithHeaders(
+		"/support-bundle", "", map[string]string{"Authorization": "Bearer mypassword"},
+	)
+	assert.Equal(t, 200, recorder.Code)
+}
@@ -0,0 +1,58 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// flushingWriter wraps an HTTP response writer so that each chunk of output is sent to the client immediately.
+type flushingWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (writer flushingWriter) Write(p []byte) (int, error) {
+	n, err := writer.w.Write(p)
+	if writer.flusher != nil {
+		writer.flusher.Flush()
+	}
+	return n, err
+}
+
+// systemLogHandler streams the system log entries from the requested source as plain text, optionally following new
+// entries until the client disconnects.
+func (web *WebServer) systemLogHandler(w http.ResponseWriter, r *http.Request) {
+	source := r.URL.Query().Get("source")
+	if err := radio.ValidateSystemLogSource(source); err != nil {
+		handleWebErr(w, r, err, http.StatusBadRequest)
+		return
+	}
+	follow := false
+	if followParam := r.URL.Query().Get("follow"); followParam != "" {
+		var err error
+		if follow, err = strconv.ParseBool(followParam); err != nil {
+			handleWebErr(w, r, fmt.Errorf("invalid value for follow: %s", followParam), http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if follow {
+		// A followed stream runs until the client disconnects, so it can't be subject to the server's write timeout.
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	}
+	flusher, _ := w.(http.Flusher)
+	err := radio.StreamSystemLog(r.Context(), source, follow, flushingWriter{w: w, flusher: flusher})
+	if errors.Is(err, radio.ErrTooManySystemLogFollowers) {
+		// Nothing has been written yet if the stream was refused.
+		handleWebErr(w, r, err, http.StatusServiceUnavailable)
+	} else if err != nil {
+		// The response has likely already started, so the error can only be logged.
+		radio.LogWithCorrelationId(requestCorrelationId(r), "Error streaming %s log: %v", source, err)
+	}
+}
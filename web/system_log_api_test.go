@@ -0,0 +1,37 @@
+package web
+
+import (
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_systemLogHandlerInvalidInput(t *testing.T) {
+	web := WebServer{radio: &radio.Radio{}}
+
+	recorder := web.getHttpResponse("/logs/system")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid log source:  (expecting hostapd or kernel)")
+
+	recorder = web.getHttpResponse("/logs/system?source=dnsmasq")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid log source: dnsmasq (expecting hostapd or kernel)")
+
+	recorder = web.getHttpResponse("/logs/system?source=kernel&follow=maybe")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid value for follow: maybe")
+}
+
+func TestWeb_systemLogHandlerAuthorization(t *testing.T) {
+	web := WebServer{radio: &radio.Radio{}}
+	web.password = "mypassword"
+
+	recorder := web.getHttpResponse("/logs/system?source=hostapd")
+	assert.Equal(t, 401, recorder.Code)
+
+	recorder = web.getHttpResponseWithHeaders(
+		"/logs/system?source=hostapd", map[string]string{"Authorization": "Bearer mypassword"},
+	)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", recorder.Header().Get("Content-Type"))
+}
@@ -0,0 +1,79 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Path of the endpoint returning the status of a single team's network.
+const teamStatusPath = "/status/team/{teamNumber}"
+
+// teamStatusHandler returns a JSON dump of the status of the given team's network, without any other team's data or
+// network credentials.
+func (web *WebServer) teamStatusHandler(w http.ResponseWriter, r *http.Request) {
+	web.writeTeamStatus(w, r)
+}
+
+// writeTeamStatus writes out the status of the team given in the request's path.
+func (web *WebServer) writeTeamStatus(w http.ResponseWriter, r *http.Request) {
+	teamNumberParam := mux.Vars(r)["teamNumber"]
+	teamNumber, err := strconv.Atoi(teamNumberParam)
+	if err != nil || teamNumber <= 0 {
+		handleWebErr(w, r, fmt.Errorf("invalid team number: %s", teamNumberParam), http.StatusBadRequest)
+		return
+	}
+	status, ok := web.radio.GetTeamStatus(teamNumber)
+	if !ok {
+		handleWebErr(w, r, fmt.Errorf("team %d is not configured on any station", teamNumber), http.StatusNotFound)
+		return
+	}
+
+	jsonData, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(jsonData)
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// newTeamStatusRouter sets up the router for the team-facing listener, which serves only the team status and robot log
+// endpoints and doesn't require authorization since it exposes nothing beyond a team's own link telemetry and logs.
+func (web *WebServer) newTeamStatusRouter() http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc(teamStatusPath, web.writeTeamStatus).Methods("GET")
+	router.HandleFunc(robotLogsPath, web.writeRobotLogs).Methods("GET")
+	return chainMiddleware(router, web.assignCorrelationIds, web.logRequests, web.recoverPanics)
+}
+
+// serveTeamStatus accepts connections on the team-facing address, if one is configured, so that the team status
+// endpoint can be exposed on a port or VLAN reachable from the driver stations without exposing the rest of the API.
+func (web *WebServer) serveTeamStatus() {
+	listenAddress := web.httpSettings.TeamStatusListenAddress
+	if listenAddress == "" {
+		return
+	}
+	log.Printf("Serving team status on %s\n", listenAddress)
+	server := &http.Server{
+		Addr:         listenAddress,
+		Handler:      web.newTeamStatusRouter(),
+		ReadTimeout:  time.Duration(web.httpSettings.ReadTimeoutSec) * time.Second,
+		WriteTimeout: time.Duration(web.httpSettings.WriteTimeoutSec) * time.Second,
+	}
+	if err := server.ListenAndServe(); err != nil {
+		log.Printf("Error serving team status on %s: %v", listenAddress, err)
+	}
+}
@@ -0,0 +1,66 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWeb_teamStatusHandler(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.radio.StationStatuses["red1"] = &radio.NetworkStatus{Ssid: "254", HashedWpaKey: "abc", WpaKeySalt: "def"}
+	web.radio.StationStatuses["blue2"] = &radio.NetworkStatus{Ssid: "1114", IsLinked: true, SignalNoiseRatio: 42}
+
+	recorder := web.getHttpResponse("/status/team/1114")
+	assert.Equal(t, 200, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header()["Content-Type"][0])
+	var status radio.TeamStatus
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+	assert.Equal(t, "blue2", status.Station)
+	assert.True(t, status.IsLinked)
+	assert.Equal(t, 42, status.SignalNoiseRatio)
+
+	// Neither the other teams' data nor any credentials are included.
+	recorder = web.getHttpResponse("/status/team/254")
+	assert.Equal(t, 200, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "1114")
+	assert.NotContains(t, recorder.Body.String(), "abc")
+	assert.NotContains(t, recorder.Body.String(), "def")
+
+	recorder = web.getHttpResponse("/status/team/1503")
+	assert.Equal(t, 404, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "team 1503 is not configured on any station")
+
+	recorder = web.getHttpResponse("/status/team/red1")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid team number: red1")
+}
+
+func TestWeb_teamStatusHandlerAuthorization(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.password = "mypassword"
+	web.radio.StationStatuses["red1"] = &radio.NetworkStatus{Ssid: "254"}
+
+	recorder := web.getHttpResponse("/status/team/254")
+	assert.Equal(t, 401, recorder.Code)
+
+	headers := map[string]string{"Authorization": "Bearer mypassword"}
+	recorder = web.getHttpResponseWithHeaders("/status/team/254", headers)
+	assert.Equal(t, 200, recorder.Code)
+
+	// The team-facing listener serves the team status without authorization, and nothing else.
+	recorder = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/status/team/254", nil)
+	web.newTeamStatusRouter().ServeHTTP(recorder, req)
+	assert.Equal(t, 200, recorder.Code)
+	recorder = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/status", nil)
+	web.newTeamStatusRouter().ServeHTTP(recorder, req)
+	assert.Equal(t, 404, recorder.Code)
+}
@@ -55,6 +55,41 @@ func (web *WebServer) postHttpResponseWithHeaders(
 	return recorder
 }
 
+// patchHttpResponse stubs the webserver, sends a PATCH request to the given path with the given body, and returns the
+// response, for use in testing.
+func (web *WebServer) patchHttpResponse(path string, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	web.newRouter().ServeHTTP(recorder, req)
+	return recorder
+}
+
+// patchHttpResponseWithHeaders stubs the webserver, sends a PATCH request to the given path with the given body and the
+// given headers, and returns the response, for use in testing.
+func (web *WebServer) patchHttpResponseWithHeaders(
+	path string, body string, headers map[string]string,
+) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	web.newRouter().ServeHTTP(recorder, req)
+	return recorder
+}
+
+// putHttpResponse stubs the webserver, sends a PUT request to the given path with the given body, and returns the
+// response, for use in testing.
+func (web *WebServer) putHttpResponse(path string, body string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	web.newRouter().ServeHTTP(recorder, req)
+	return recorder
+}
+
 // postFileHttpResponse stubs the webserver, sends a POST request to the given path with the given file and other
 // fields, and returns the response, for use in testing.
 func (web *WebServer) postFileHttpResponse(
@@ -74,3 +109,15 @@ func (web *WebServer) postFileHttpResponse(
 	web.newRouter().ServeHTTP(recorder, req)
 	return recorder
 }
+
+// deleteHttpResponseWithHeaders stubs the webserver, sends a DELETE request to the given path with the given headers,
+// and returns the response, for use in testing.
+func (web *WebServer) deleteHttpResponseWithHeaders(path string, headers map[string]string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", path, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	web.newRouter().ServeHTTP(recorder, req)
+	return recorder
+}
@@ -0,0 +1,171 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// Path to the file in which API tokens are persisted, in JSON format.
+	tokensFilePath = "/root/frc-radio-api-tokens.json"
+
+	// Number of random bytes in a newly generated token.
+	tokenLengthBytes = 32
+)
+
+var tokenNameRe = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,32}$`)
+
+// tokenRole represents the level of access granted by an API token.
+type tokenRole string
+
+const (
+	// Grants access only to endpoints that report on the state of the radio, for use by dashboards.
+	roleReadOnly tokenRole = "READ_ONLY"
+
+	// Grants access to all endpoints, including those that configure the radio or manage the system.
+	roleAdmin tokenRole = "ADMIN"
+)
+
+// apiToken represents a named credential for accessing the API. Only a hash of the token itself is retained.
+type apiToken struct {
+	Name        string    `json:"name"`
+	Role        tokenRole `json:"role"`
+	HashedToken string    `json:"hashedToken"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// tokenStore holds the API tokens and persists them to disk; it is shared between request goroutines.
+type tokenStore struct {
+	mutex    sync.Mutex
+	filePath string
+	tokens   map[string]apiToken
+}
+
+// load replaces the in-memory tokens with those persisted on disk. A missing file is treated as having no tokens.
+func (store *tokenStore) load() error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	tokens := make(map[string]apiToken)
+	tokensBytes, err := os.ReadFile(store.filePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		var tokenList []apiToken
+		if err = json.Unmarshal(tokensBytes, &tokenList); err != nil {
+			return fmt.Errorf("invalid JSON: %v", err)
+		}
+		for _, token := range tokenList {
+			tokens[token.Name] = token
+		}
+	}
+	store.tokens = tokens
+	return nil
+}
+
+// save persists the in-memory tokens to disk. Must be called with the mutex held.
+func (store *tokenStore) save() error {
+	tokensBytes, err := json.MarshalIndent(store.listLocked(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(store.filePath, tokensBytes, 0600)
+}
+
+// list returns all tokens, sorted by name.
+func (store *tokenStore) list() []apiToken {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	return store.listLocked()
+}
+
+// listLocked returns all tokens, sorted by name. Must be called with the mutex held.
+func (store *tokenStore) listLocked() []apiToken {
+	tokens := make([]apiToken, 0, len(store.tokens))
+	for _, token := range store.tokens {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].Name < tokens[j].Name
+	})
+	return tokens
+}
+
+// create generates and persists a new token with the given name and role, returning the token so that it can be given
+// to the client. The token itself cannot be retrieved again afterward.
+func (store *tokenStore) create(name string, role tokenRole) (string, error) {
+	if !tokenNameRe.MatchString(name) {
+		return "", fmt.Errorf(
+			"invalid token name %q (expecting 1-32 alphanumeric characters, hyphens, or underscores)", name,
+		)
+	}
+	if role != roleReadOnly && role != roleAdmin {
+		return "", fmt.Errorf("invalid token role: %s", role)
+	}
+
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	if _, ok := store.tokens[name]; ok {
+		return "", fmt.Errorf("token %s already exists", name)
+	}
+	tokenBytes := make([]byte, tokenLengthBytes)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(tokenBytes)
+	if store.tokens == nil {
+		store.tokens = make(map[string]apiToken)
+	}
+	store.tokens[name] = apiToken{Name: name, Role: role, HashedToken: hashToken(token), CreatedAt: time.Now()}
+	if err := store.save(); err != nil {
+		delete(store.tokens, name)
+		return "", fmt.Errorf("failed to save tokens: %v", err)
+	}
+	return token, nil
+}
+
+// delete removes the token with the given name. Returns false if no such token exists.
+func (store *tokenStore) delete(name string) (bool, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	token, ok := store.tokens[name]
+	if !ok {
+		return false, nil
+	}
+	delete(store.tokens, name)
+	if err := store.save(); err != nil {
+		store.tokens[name] = token
+		return true, fmt.Errorf("failed to save tokens: %v", err)
+	}
+	return true, nil
+}
+
+// lookup returns the stored token matching the given one, or false if it doesn't match any known token.
+func (store *tokenStore) lookup(token string) (apiToken, bool) {
+	hashedToken := hashToken(token)
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	for _, storedToken := range store.tokens {
+		if storedToken.HashedToken == hashedToken {
+			return storedToken, true
+		}
+	}
+	return apiToken{}, false
+}
+
+// hashToken returns the hex-encoded SHA-256 hash of the given token.
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
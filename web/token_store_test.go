@@ -0,0 +1,80 @@
+package web
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTokenStore(t *testing.T) {
+	store := tokenStore{filePath: filepath.Join(t.TempDir(), "tokens.json")}
+	assert.Nil(t, store.load())
+	assert.Equal(t, []apiToken{}, store.list())
+
+	dashboardToken, err := store.create("dashboard", roleReadOnly)
+	assert.Nil(t, err)
+	assert.Equal(t, 2*tokenLengthBytes, len(dashboardToken))
+	fmsToken, err := store.create("fms", roleAdmin)
+	assert.Nil(t, err)
+	assert.NotEqual(t, dashboardToken, fmsToken)
+
+	token, ok := store.lookup(dashboardToken)
+	assert.True(t, ok)
+	assert.Equal(t, "dashboard", token.Name)
+	assert.Equal(t, roleReadOnly, token.Role)
+	token, ok = store.lookup(fmsToken)
+	assert.True(t, ok)
+	assert.Equal(t, "fms", token.Name)
+	assert.Equal(t, roleAdmin, token.Role)
+	_, ok = store.lookup("bogus")
+	assert.False(t, ok)
+
+	// Tokens are persisted in hashed form only and survive reloading.
+	fileBytes, err := os.ReadFile(store.filePath)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(fileBytes), dashboardToken)
+	reloadedStore := tokenStore{filePath: store.filePath}
+	assert.Nil(t, reloadedStore.load())
+	tokens := reloadedStore.list()
+	if assert.Equal(t, 2, len(tokens)) {
+		assert.Equal(t, "dashboard", tokens[0].Name)
+		assert.Equal(t, "fms", tokens[1].Name)
+	}
+	_, ok = reloadedStore.lookup(fmsToken)
+	assert.True(t, ok)
+
+	// Deleting a token revokes it.
+	found, err := store.delete("dashboard")
+	assert.True(t, found)
+	assert.Nil(t, err)
+	_, ok = store.lookup(dashboardToken)
+	assert.False(t, ok)
+	found, err = store.delete("dashboard")
+	assert.False(t, found)
+	assert.Nil(t, err)
+}
+
+func TestTokenStore_createErrors(t *testing.T) {
+	store := tokenStore{filePath: filepath.Join(t.TempDir(), "tokens.json")}
+
+	_, err := store.create("", roleAdmin)
+	assert.EqualError(
+		t, err, "invalid token name \"\" (expecting 1-32 alphanumeric characters, hyphens, or underscores)",
+	)
+	_, err = store.create("bad name", roleAdmin)
+	assert.NotNil(t, err)
+	_, err = store.create("dashboard", "SUPERUSER")
+	assert.EqualError(t, err, "invalid token role: SUPERUSER")
+
+	_, err = store.create("dashboard", roleReadOnly)
+	assert.Nil(t, err)
+	_, err = store.create("dashboard", roleAdmin)
+	assert.EqualError(t, err, "token dashboard already exists")
+
+	// Invalid file.
+	assert.Nil(t, os.WriteFile(store.filePath, []byte("not JSON"), 0600))
+	if err = store.load(); assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "invalid JSON")
+	}
+}
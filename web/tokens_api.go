@@ -0,0 +1,80 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net/http"
+	"time"
+)
+
+// tokenResponse represents a token as returned by the API; the token itself is only included upon creation.
+type tokenResponse struct {
+	Name      string    `json:"name"`
+	Role      tokenRole `json:"role"`
+	Token     string    `json:"token,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// tokensHandler returns a JSON list of the API tokens, omitting the tokens themselves.
+func (web *WebServer) tokensHandler(w http.ResponseWriter, r *http.Request) {
+	tokens := make([]tokenResponse, 0)
+	for _, token := range web.tokens.list() {
+		tokens = append(tokens, tokenResponse{Name: token.Name, Role: token.Role, CreatedAt: token.CreatedAt})
+	}
+	jsonData, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(jsonData)
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+}
+
+// tokenCreateHandler generates a new API token with the requested name and role and returns it.
+func (web *WebServer) tokenCreateHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Name string    `json:"name"`
+		Role tokenRole `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	token, err := web.tokens.create(request.Name, request.Role)
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	jsonData, err := json.MarshalIndent(
+		tokenResponse{Name: request.Name, Role: request.Role, Token: token, CreatedAt: time.Now()}, "", "  ",
+	)
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(jsonData)
+}
+
+// tokenDeleteHandler revokes the API token with the given name.
+func (web *WebServer) tokenDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	found, err := web.tokens.delete(name)
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		handleWebErr(w, r, fmt.Errorf("token %s does not exist", name), http.StatusNotFound)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "Token %s deleted.\n", name)
+}
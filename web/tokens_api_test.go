@@ -0,0 +1,66 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestWeb_tokensHandlers(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	web.tokens.filePath = filepath.Join(t.TempDir(), "tokens.json")
+	web.password = "mypassword"
+	adminHeaders := map[string]string{"Authorization": "Bearer mypassword"}
+
+	// Create a read-only token.
+	recorder := web.postHttpResponseWithHeaders("/tokens", `{"name": "dashboard", "role": "READ_ONLY"}`, adminHeaders)
+	assert.Equal(t, 201, recorder.Code)
+	var created tokenResponse
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &created))
+	assert.Equal(t, "dashboard", created.Name)
+	assert.Equal(t, roleReadOnly, created.Role)
+	assert.NotEqual(t, "", created.Token)
+	readOnlyHeaders := map[string]string{"Authorization": "Bearer " + created.Token}
+
+	// Invalid requests.
+	recorder = web.postHttpResponseWithHeaders("/tokens", "not JSON", adminHeaders)
+	assert.Equal(t, 400, recorder.Code)
+	recorder = web.postHttpResponseWithHeaders("/tokens", `{"name": "dashboard", "role": "ADMIN"}`, adminHeaders)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "already exists")
+
+	// The read-only token can view the status but not change the configuration or manage tokens.
+	recorder = web.getHttpResponseWithHeaders("/status", readOnlyHeaders)
+	assert.Equal(t, 200, recorder.Code)
+	recorder = web.postHttpResponseWithHeaders("/configuration", `{"channel": 5}`, readOnlyHeaders)
+	assert.Equal(t, 401, recorder.Code)
+	recorder = web.getHttpResponseWithHeaders("/tokens", readOnlyHeaders)
+	assert.Equal(t, 401, recorder.Code)
+
+	// Listing tokens omits the tokens themselves.
+	recorder = web.getHttpResponseWithHeaders("/tokens", adminHeaders)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "dashboard")
+	assert.NotContains(t, recorder.Body.String(), created.Token)
+
+	// An admin token can manage tokens too.
+	recorder = web.postHttpResponseWithHeaders("/tokens", `{"name": "fms", "role": "ADMIN"}`, adminHeaders)
+	assert.Equal(t, 201, recorder.Code)
+	var adminToken tokenResponse
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &adminToken))
+	recorder = web.getHttpResponseWithHeaders("/tokens", map[string]string{"Authorization": "Bearer " + adminToken.Token})
+	assert.Equal(t, 200, recorder.Code)
+
+	// Delete the read-only token.
+	recorder = web.deleteHttpResponseWithHeaders("/tokens/dashboard", readOnlyHeaders)
+	assert.Equal(t, 401, recorder.Code)
+	recorder = web.deleteHttpResponseWithHeaders("/tokens/dashboard", adminHeaders)
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "Token dashboard deleted")
+	recorder = web.deleteHttpResponseWithHeaders("/tokens/dashboard", adminHeaders)
+	assert.Equal(t, 404, recorder.Code)
+	recorder = web.getHttpResponseWithHeaders("/status", readOnlyHeaders)
+	assert.Equal(t, 401, recorder.Code)
+}
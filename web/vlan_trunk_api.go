@@ -0,0 +1,43 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
+	"net/http"
+)
+
+// vlanTrunkHandler returns which team VLANs are tagged on the uplink port and which stations' VLANs aren't.
+func (web *WebServer) vlanTrunkHandler(w http.ResponseWriter, r *http.Request) {
+	jsonData, err := json.MarshalIndent(web.radio.GetVlanTrunk(), "", "  ")
+	if err != nil {
+		handleWebErr(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeNegotiatedResponse(w, r, jsonData)
+}
+
+// vlanTrunkUpdateHandler schedules a change to which team VLANs are tagged on the uplink port.
+func (web *WebServer) vlanTrunkUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	var request radio.VlanTrunkChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := request.Validate(); err != nil {
+		handleWebErr(w, r, fmt.Errorf("invalid VLAN trunk: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := web.radio.RequestVlanTrunkChange(request); err != nil {
+		handleWebErr(w, r, err, http.StatusConflict)
+		return
+	}
+
+	radio.LogWithCorrelationId(requestCorrelationId(r), "VLAN trunk change requested: %+v", request)
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprintln(w, "VLAN trunk will be reconfigured on the next monitoring poll.")
+}
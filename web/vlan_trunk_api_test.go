@@ -0,0 +1,51 @@
+// This file is specific to the access point version of the API.
+//go:build !robot
+
+package web
+
+import (
+	"encoding/json"
+	"github.com/patfair/frc-radio-api/radio"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWeb_vlanTrunkHandler(t *testing.T) {
+	ap := radio.NewRadio()
+	ap.VlanTrunk = radio.VlanTrunkStatus{
+		UplinkDevice: "eth0", TrunkedVlans: []int{10, 20}, UntrunkedStations: map[string]int{"red3": 30},
+	}
+	web := NewWebServer(ap)
+
+	recorder := web.getHttpResponse("/system/network/trunk")
+	assert.Equal(t, 200, recorder.Code)
+	var status radio.VlanTrunkStatus
+	assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &status))
+	assert.Equal(t, ap.VlanTrunk, status)
+
+	web.password = "mypassword"
+	recorder = web.getHttpResponse("/system/network/trunk")
+	assert.Equal(t, 401, recorder.Code)
+}
+
+func TestWeb_vlanTrunkUpdateHandler(t *testing.T) {
+	ap := radio.NewRadio()
+	web := NewWebServer(ap)
+
+	recorder := web.putHttpResponse("/system/network/trunk", `{"trunkedVlans": [40, 10, 20]}`)
+	assert.Equal(t, 202, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "VLAN trunk will be reconfigured on the next monitoring poll.")
+	assert.Equal(t, []int{10, 20, 40}, ap.GetVlanTrunk().PendingVlans)
+
+	// Invalid requests.
+	recorder = web.putHttpResponse("/system/network/trunk", "{")
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid JSON")
+	recorder = web.putHttpResponse("/system/network/trunk", `{"trunkedVlans": [10, 100]}`)
+	assert.Equal(t, 400, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "invalid VLAN trunk: invalid VLAN: 100")
+
+	web.password = "mypassword"
+	recorder = web.putHttpResponse("/system/network/trunk", `{"trunkedVlans": [10]}`)
+	assert.Equal(t, 401, recorder.Code)
+}
@@ -5,7 +5,6 @@ package web
 
 import (
 	"fmt"
-	"github.com/gorilla/mux"
 	"github.com/patfair/frc-radio-api/radio"
 	"log"
 	"net"
@@ -32,7 +31,7 @@ func getListenAddress(r *radio.Radio) string {
 	var ipAddress string
 	for {
 		var err error
-		ipAddress, err = getVlan100IpAddress()
+		ipAddress, err = getManagementIpAddress(r.GetManagementNetwork().Current.IpAddress)
 		if err != nil {
 			log.Printf("Error getting radio IP address; trying again later: %v", err)
 			time.Sleep(ipAddressPollIntervalSec * time.Second)
@@ -44,6 +43,23 @@ func getListenAddress(r *radio.Radio) string {
 	return fmt.Sprintf("%s:%d", ipAddress, port)
 }
 
+// getManagementIpAddress returns the given configured management IP address if an interface has it, falling back to
+// the address on the 10.0.100.x VLAN otherwise (e.g. if the configuration hasn't been read yet).
+func getManagementIpAddress(configuredIpAddress string) (string, error) {
+	if configuredIpAddress != "" {
+		ifaceAddrs, err := net.InterfaceAddrs()
+		if err != nil {
+			return "", err
+		}
+		for _, addr := range ifaceAddrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.String() == configuredIpAddress {
+				return configuredIpAddress, nil
+			}
+		}
+	}
+	return getVlan100IpAddress()
+}
+
 // getVlan100IpAddress returns the IP address of the first interface that has an IP address on the 10.0.100.x VLAN.
 func getVlan100IpAddress() (string, error) {
 	ipRe := regexp.MustCompile("^(10\\.0\\.100\\.\\d+)")
@@ -67,10 +83,53 @@ func getVlan100IpAddress() (string, error) {
 	return "", fmt.Errorf("no IP address found on VLAN 100 (i.e. matching %v)", ipRe)
 }
 
-// addRoutes adds additional route handlers to the router if needed.
-func addRoutes(router *mux.Router, web *WebServer) {}
+// addRoutes adds the routes specific to the access point to the given table.
+func addRoutes(routes *routeTable, web *WebServer) {
+	routes.handle("GET", "/capabilities", roleReadOnly, web.capabilitiesHandler)
+	routes.handle("GET", "/channels/report", roleReadOnly, web.channelReportHandler)
+	routes.handle("POST", "/clients/ghosts/remove", roleAdmin, web.ghostClientsRemoveHandler)
+	routes.handleConfiguration("PATCH", "/configuration", web.configurationPatchHandler)
+	routes.handle("POST", "/configuration/clear-all", roleAdmin, web.clearAllHandler)
+	routes.handlePagePrefix("/dashboard/", dashboardAssetHandler())
+	routes.handle("POST", "/failover", roleAdmin, web.failoverHandler)
+	routes.handle("POST", "/heartbeat", roleAdmin, web.heartbeatHandler)
+	routes.handle("GET", "/iperf", roleReadOnly, web.iperfHandler)
+	routes.handle("POST", "/iperf/start", roleAdmin, web.iperfStartHandler)
+	routes.handle("POST", "/iperf/stop", roleAdmin, web.iperfStopHandler)
+	routes.handle("GET", robotLogsPath, roleReadOnly, web.robotLogsHandler)
+	routes.handle("POST", "/match/lock", roleAdmin, web.matchLockHandler)
+	routes.handle("POST", "/match/unlock", roleAdmin, web.matchUnlockHandler)
+	routes.handle("GET", "/neighbors", roleReadOnly, web.neighborNetworksHandler)
+	routes.handle("GET", "/networks/rogue", roleReadOnly, web.rogueNetworksHandler)
+	routes.handle("GET", "/quiet-hours", roleReadOnly, web.quietHoursHandler)
+	routes.handle("POST", "/quiet-hours", roleAdmin, web.quietHoursUpdateHandler)
+	routes.handle("GET", "/schedule", roleReadOnly, web.eventScheduleHandler)
+	routes.handle("POST", "/schedule", roleAdmin, web.eventScheduleUpdateHandler)
+	routes.handleConfiguration("POST", "/standby/configuration", web.standbyConfigurationHandler)
+	routes.handle("GET", teamStatusPath, roleReadOnly, web.teamStatusHandler)
+	routes.handle("POST", "/stations/{station}/link-test", roleAdmin, web.linkTestHandler)
+	routes.handle("GET", "/system/network", roleReadOnly, web.managementNetworkHandler)
+	routes.handle("PUT", "/system/network", roleAdmin, web.managementNetworkUpdateHandler)
+	routes.handle("POST", "/system/network/confirm", roleAdmin, web.managementNetworkConfirmHandler)
+	routes.handle("GET", "/system/network/trunk", roleReadOnly, web.vlanTrunkHandler)
+	routes.handle("PUT", "/system/network/trunk", roleAdmin, web.vlanTrunkUpdateHandler)
+}
+
+// loadPersistedState restores radio state that the API persists across restarts.
+func (web *WebServer) loadPersistedState() {
+	web.loadQuietHoursSchedule()
+	web.loadEventSchedule()
+}
+
+// followProvisionedManagementAddress starts accepting connections on the management address requested at
+// provisioning, if any, once the change to it has been applied, so that it can be confirmed there.
+func (web *WebServer) followProvisionedManagementAddress(ipAddress string) {
+	if ipAddress != "" && web.listener != nil {
+		go web.serveOnNewManagementAddress(ipAddress)
+	}
+}
 
-// rootHandler redirects the root URL to the status page.
+// rootHandler serves the dashboard at the root URL.
 func (web *WebServer) rootHandler(w http.ResponseWriter, r *http.Request) {
-	http.Redirect(w, r, "/status", http.StatusFound)
+	web.dashboardPageHandler(w, r)
 }
@@ -33,8 +33,8 @@ func TestGetVlan100IpAddress(t *testing.T) {
 }
 
 func TestWeb_rootHandler(t *testing.T) {
-	var web WebServer
+	web := WebServer{radio: &radio.Radio{}}
 	recorder := web.getHttpResponse("/")
-	assert.Equal(t, 302, recorder.Code)
-	assert.Equal(t, "/status", recorder.Header().Get("Location"))
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "FRC Access Point Dashboard")
 }
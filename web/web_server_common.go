@@ -1,19 +1,29 @@
 package web
 
 import (
+	"errors"
 	"filippo.io/age"
 	"fmt"
-	"github.com/gorilla/mux"
 	"github.com/patfair/frc-radio-api/radio"
 	"log"
+	"net"
 	"net/http"
 	"os"
-	"strings"
+	"sync"
 )
 
 const (
-	// Path to the optional file containing the password for the API.
-	passwordFilePath = "/root/frc-radio-api-password.txt"
+	// Name under which the optional password for the API is kept in the secret storage backend.
+	passwordSecretName = "password"
+
+	// Name under which the optional private key for decrypting new firmware is kept in the secret storage backend.
+	firmwareDecryptionKeySecretName = "firmware-key"
+
+	// Path to the file in which the access point's quiet hours schedule is persisted, in JSON format.
+	quietHoursFilePath = "/root/frc-radio-api-quiet-hours.json"
+
+	// Path to the file in which the access point's event schedule is persisted, in JSON format.
+	eventScheduleFilePath = "/root/frc-radio-api-event-schedule.json"
 
 	// Interval between attempts to get the IP address of the radio on startup.
 	ipAddressPollIntervalSec = 3
@@ -21,63 +31,159 @@ const (
 
 // WebServer holds shared state across requests to the API.
 type WebServer struct {
-	// Password for authorizing requests to the API. If blank, no authorization is required.
+	// Password for authorizing requests to the API, in the form in which it is stored (i.e. possibly hashed). If blank,
+	// no authorization is required. Grants full access.
 	password string
 
+	// Additional named tokens granting role-based access to the API when a password is set.
+	tokens tokenStore
+
 	// Private key for decrypting new firmware. If nil, only unencrypted firmware can be uploaded.
 	firmwareDecryptionKey *age.X25519Identity
 
+	// Mutex guarding the password and firmware decryption key, which are replaced when the secrets are reloaded.
+	secretsMutex sync.RWMutex
+
 	// Device that the API provides access to.
 	radio *radio.Radio
+
+	// Limits and cross-origin policy for the HTTP server, fixed when the server is constructed.
+	httpSettings radio.HttpServerSettings
+
+	// Most recently marshaled status JSON.
+	statusCache statusCache
+
+	// Statistics on the configuration requests issued by each client.
+	requestOrigins requestOriginTracker
+
+	// Path to the file in which the quiet hours schedule is persisted. Only used by the access point.
+	quietHoursFilePath string
+
+	// Path to the file in which the event schedule is persisted. Only used by the access point.
+	eventScheduleFilePath string
+
+	// Listener on which the HTTP server accepts connections, passed on to the new process when the API is upgraded.
+	listener net.Listener
+
+	// Path to the running API binary, which is replaced when the API is upgraded.
+	apiBinaryPath string
+
+	// Path to the file through which in-memory state is carried over to the new process when the API is upgraded.
+	handoverStateFilePath string
 }
 
 // NewWebServer creates a new server instance.
 func NewWebServer(radio *radio.Radio) *WebServer {
-	return &WebServer{radio: radio}
+	apiBinaryPath, err := os.Executable()
+	if err != nil {
+		log.Printf("Unable to determine path of API binary; in-place upgrades will fail: %v", err)
+	}
+	return &WebServer{
+		radio:                 radio,
+		tokens:                tokenStore{filePath: tokensFilePath},
+		httpSettings:          radio.GetSettings().HttpServer,
+		quietHoursFilePath:    quietHoursFilePath,
+		eventScheduleFilePath: eventScheduleFilePath,
+		apiBinaryPath:         apiBinaryPath,
+		handoverStateFilePath: handoverStateFilePath,
+	}
 }
 
 // Run starts the HTTP server and blocks until the process terminates, serving requests.
 func (web *WebServer) Run() {
 	web.setUpSecrets()
+	web.loadPersistedState()
 
-	listenAddress := getListenAddress(web.radio)
-	log.Printf("Server listening on %s\n", listenAddress)
-	if err := http.ListenAndServe(listenAddress, web.newRouter()); err != nil {
+	listener, err := inheritedListener()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if listener != nil {
+		log.Printf("Server listening on %s, inherited from the previous API process\n", listener.Addr())
+		web.restoreHandoverState()
+	} else {
+		listenAddress := getListenAddress(web.radio)
+		log.Printf("Server listening on %s\n", listenAddress)
+		if listener, err = net.Listen("tcp", listenAddress); err != nil {
+			log.Fatal(err)
+		}
+	}
+	web.listener = listener
+	go web.serveTeamStatus()
+	server := web.newHttpServer(listener.Addr().String())
+	if err = server.Serve(web.limitConnections(listener)); err != nil {
 		log.Fatal(err)
 	}
 }
 
-// setUpSecrets reads the password and firmware decryption keys from their respective files, if they exist.
+// setUpSecrets reads the password and firmware decryption key from the configured secret storage backend, if they are
+// set, and hashes a plain text password in place if it should be hashed at rest.
 func (web *WebServer) setUpSecrets() {
-	passwordBytes, err := os.ReadFile(passwordFilePath)
+	secretSettings := web.radio.GetSettings().Secrets
+	passwordStore := secretSettings.Store(passwordSecretName)
+	password, err := passwordStore.Load()
 	if err != nil {
-		log.Printf("Error opening password file; authorization disabled: %v", err)
-	} else {
-		web.password = strings.TrimSpace(string(passwordBytes))
+		log.Printf("Error reading password from %s; authorization disabled: %v", passwordStore, err)
+	} else if password == "" {
+		log.Printf("No password set in %s; authorization disabled.", passwordStore)
+	} else if secretSettings.HashPasswordAtRest && !radio.IsHashedSecret(password) {
+		password = radio.HashSecret(password)
+		if err = passwordStore.Save(password); err != nil {
+			log.Printf("Error hashing password in %s; leaving it in plain text: %v", passwordStore, err)
+		}
+	}
+	web.setPassword(password)
+
+	if err = web.tokens.load(); err != nil {
+		log.Printf("Error loading tokens file; only the password will be accepted: %v", err)
 	}
 
-	privateKeyBytes, err := os.ReadFile(firmwareDecryptionKeyFilePath)
+	var firmwareDecryptionKey *age.X25519Identity
+	keyStore := secretSettings.Store(firmwareDecryptionKeySecretName)
+	privateKey, err := keyStore.Load()
 	if err != nil {
-		log.Printf("Error opening encryption key file; firmware decryption disabled: %v", err)
-	} else if len(privateKeyBytes) != 0 {
-		privateKey := strings.TrimSpace(string(privateKeyBytes))
-		web.firmwareDecryptionKey, err = age.ParseX25519Identity(privateKey)
+		log.Printf("Error reading encryption key from %s; firmware decryption disabled: %v", keyStore, err)
+	} else if privateKey != "" {
+		firmwareDecryptionKey, err = age.ParseX25519Identity(privateKey)
 		if err != nil {
 			log.Printf("Error parsing encryption key; firmware decryption disabled: %v", err)
+			firmwareDecryptionKey = nil
 		}
 	}
+	web.secretsMutex.Lock()
+	web.firmwareDecryptionKey = firmwareDecryptionKey
+	web.secretsMutex.Unlock()
+}
+
+// getPassword returns the API password in the form in which it is stored, or blank if none is set.
+func (web *WebServer) getPassword() string {
+	web.secretsMutex.RLock()
+	defer web.secretsMutex.RUnlock()
+	return web.password
+}
+
+// setPassword replaces the API password with the given one, in the form in which it is stored.
+func (web *WebServer) setPassword(password string) {
+	web.secretsMutex.Lock()
+	defer web.secretsMutex.Unlock()
+	web.password = password
+}
+
+// getFirmwareDecryptionKey returns the private key for decrypting new firmware, or nil if none is set.
+func (web *WebServer) getFirmwareDecryptionKey() *age.X25519Identity {
+	web.secretsMutex.RLock()
+	defer web.secretsMutex.RUnlock()
+	return web.firmwareDecryptionKey
 }
 
-// newRouter sets up the mapping between URLs and handlers.
-func (web *WebServer) newRouter() http.Handler {
-	router := mux.NewRouter()
-	router.HandleFunc("/", web.rootHandler).Methods("GET")
-	router.HandleFunc("/health", web.healthHandler).Methods("GET")
-	router.HandleFunc("/status", web.statusHandler).Methods("GET")
-	router.HandleFunc("/configuration", web.configurationHandler).Methods("POST")
-	router.HandleFunc("/firmware", web.firmwareHandler).Methods("POST")
-	addRoutes(router, web)
-	return router
+// ReloadSettings re-reads the API settings and secrets from their respective files and applies them without restarting
+// the server or touching the radio configuration.
+func (web *WebServer) ReloadSettings() error {
+	if err := web.radio.ReloadSettings(); err != nil {
+		return err
+	}
+	web.setUpSecrets()
+	return nil
 }
 
 // healthHandler returns a simple "OK" response to indicate that the server is running.
@@ -85,19 +191,49 @@ func (web *WebServer) healthHandler(w http.ResponseWriter, r *http.Request) {
 	_, _ = fmt.Fprintln(w, "OK")
 }
 
-// isAuthorized returns true if the request is authorized to access the API.
-func (web *WebServer) isAuthorized(r *http.Request) bool {
-	if web.password == "" {
+// isAuthorized returns true if the request is authorized to access an endpoint requiring the given role. The password
+// grants access to everything, while tokens grant access according to their role.
+func (web *WebServer) isAuthorized(r *http.Request, requiredRole tokenRole) bool {
+	apiPassword := web.getPassword()
+	if apiPassword == "" {
+		return true
+	}
+	password := bearerToken(r)
+	if radio.SecretMatches(apiPassword, password) {
 		return true
 	}
-	var password string
-	_, _ = fmt.Sscanf(r.Header.Get("Authorization"), "Bearer %s", &password)
-	return password == web.password
+	token, ok := web.tokens.lookup(password)
+	return ok && (token.Role == roleAdmin || token.Role == requiredRole)
+}
+
+// bearerToken returns the password or token presented in the request's Authorization header, if any.
+func bearerToken(r *http.Request) string {
+	var token string
+	_, _ = fmt.Sscanf(r.Header.Get("Authorization"), "Bearer %s", &token)
+	return token
+}
+
+// statusCodeForError returns the HTTP status code to respond with for the given error from the radio according to its
+// kind, or the given default if its kind doesn't call for a particular one.
+func statusCodeForError(err error, defaultStatusCode int) int {
+	switch {
+	case errors.Is(err, radio.ErrInvalidChannel):
+		return http.StatusBadRequest
+	case errors.Is(err, radio.ErrConfigurationRevisionMismatch):
+		return http.StatusConflict
+	case errors.Is(err, radio.ErrCommitFailed):
+		return http.StatusInternalServerError
+	case errors.Is(err, radio.ErrVerificationTimeout), errors.Is(err, radio.ErrShellTimeout):
+		return http.StatusGatewayTimeout
+	default:
+		return defaultStatusCode
+	}
 }
 
-// handleWebErr writes the given error out as plain text with the given status code.
-func handleWebErr(w http.ResponseWriter, err error, statusCode int) {
+// handleWebErr writes the given error out as plain text with the given status code and logs it along with the
+// correlation ID of the given request.
+func handleWebErr(w http.ResponseWriter, r *http.Request, err error, statusCode int) {
 	message := fmt.Sprintf("HTTP request error %d: %v", statusCode, err)
-	log.Println(message)
+	radio.LogWithCorrelationId(requestCorrelationId(r), "%s", message)
 	http.Error(w, message, statusCode)
 }
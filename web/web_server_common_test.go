@@ -1,20 +1,81 @@
 package web
 
 import (
+	"errors"
+	"filippo.io/age"
+	"fmt"
+	"github.com/patfair/frc-radio-api/radio"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
 func TestWeb_healthHandler(t *testing.T) {
-	var web WebServer
+	web := WebServer{radio: &radio.Radio{}}
 	recorder := web.getHttpResponse("/health")
 	assert.Equal(t, 200, recorder.Code)
 	assert.Equal(t, recorder.Body.String(), "OK\n")
 }
 
 func TestWebNotFound(t *testing.T) {
-	var web WebServer
+	web := WebServer{radio: &radio.Radio{}}
 	recorder := web.getHttpResponse("/foo")
 	assert.Equal(t, 404, recorder.Code)
 	assert.Contains(t, recorder.Body.String(), "404 page not found")
 }
+
+func TestWeb_setUpSecrets(t *testing.T) {
+	web := NewWebServer(radio.NewRadio())
+	settings := web.radio.GetSettings()
+	settings.Secrets.Directory = t.TempDir()
+	web.radio.SetSettings(settings)
+	web.tokens.filePath = filepath.Join(t.TempDir(), "tokens.json")
+	passwordFilePath := filepath.Join(web.radio.GetSettings().Secrets.Directory, "frc-radio-api-password.txt")
+	keyFilePath := filepath.Join(web.radio.GetSettings().Secrets.Directory, "frc-radio-api-firmware-key.txt")
+
+	// Authorization and firmware decryption are disabled if the secrets aren't set.
+	web.setUpSecrets()
+	assert.Equal(t, "", web.password)
+	assert.Nil(t, web.firmwareDecryptionKey)
+
+	identity, err := age.GenerateX25519Identity()
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(passwordFilePath, []byte("mypassword\n"), 0600))
+	assert.Nil(t, os.WriteFile(keyFilePath, []byte(identity.String()+"\n"), 0600))
+	web.setUpSecrets()
+	assert.Equal(t, "mypassword", web.password)
+	if assert.NotNil(t, web.firmwareDecryptionKey) {
+		assert.Equal(t, identity.String(), web.firmwareDecryptionKey.String())
+	}
+
+	// A plain text password is hashed in place if it should be hashed at rest.
+	settings = web.radio.GetSettings()
+	settings.Secrets.HashPasswordAtRest = true
+	web.radio.SetSettings(settings)
+	web.setUpSecrets()
+	assert.Equal(t, radio.HashSecret("mypassword"), web.password)
+	storedPassword, _ := os.ReadFile(passwordFilePath)
+	assert.Equal(t, radio.HashSecret("mypassword")+"\n", string(storedPassword))
+	recorder := web.getHttpResponseWithHeaders("/settings", map[string]string{"Authorization": "Bearer mypassword"})
+	assert.Equal(t, 200, recorder.Code)
+
+	// Secrets can be read from the environment instead.
+	settings = web.radio.GetSettings()
+	settings.Secrets.Backend = "ENVIRONMENT"
+	web.radio.SetSettings(settings)
+	t.Setenv("FRC_RADIO_API_PASSWORD", "envpassword")
+	t.Setenv("FRC_RADIO_API_FIRMWARE_KEY", "")
+	web.setUpSecrets()
+	assert.Equal(t, radio.HashSecret("envpassword"), web.password)
+	assert.Nil(t, web.firmwareDecryptionKey)
+}
+
+func TestStatusCodeForError(t *testing.T) {
+	assert.Equal(t, 400, statusCodeForError(fmt.Errorf("bad request: %w", radio.ErrInvalidChannel), 503))
+	assert.Equal(t, 409, statusCodeForError(radio.ErrConfigurationRevisionMismatch, 503))
+	assert.Equal(t, 500, statusCodeForError(fmt.Errorf("commit: %w", radio.ErrCommitFailed), 503))
+	assert.Equal(t, 504, statusCodeForError(radio.ErrVerificationTimeout, 503))
+	assert.Equal(t, 504, statusCodeForError(radio.ErrShellTimeout, 503))
+	assert.Equal(t, 503, statusCodeForError(errors.New("queue full"), 503))
+}
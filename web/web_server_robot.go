@@ -5,7 +5,6 @@ package web
 
 import (
 	"fmt"
-	"github.com/gorilla/mux"
 	"github.com/patfair/frc-radio-api/radio"
 	"net/http"
 )
@@ -18,12 +17,29 @@ func getListenAddress(r *radio.Radio) string {
 	return fmt.Sprintf(":%d", port)
 }
 
-// addRoutes adds additional route handlers to the router if needed.
-func addRoutes(router *mux.Router, web *WebServer) {
-	router.HandleFunc("/configuration", web.configurationPageHandler).Methods("GET")
+// addRoutes adds the routes specific to the robot radio to the given table.
+func addRoutes(routes *routeTable, web *WebServer) {
+	routes.handlePage("/configuration", roleAdmin, web.configurationPageHandler)
+	routes.handle("GET", "/indicators", roleReadOnly, web.statusIndicatorsHandler)
+	routes.handlePage("/kiosk", roleAdmin, web.kioskPageHandler)
+	routes.handleConfiguration("POST", "/kiosk/provision", web.kioskProvisionHandler)
 }
 
-// rootHandler redirects the root URL to the configuration page.
+// loadPersistedState restores radio state that the API persists across restarts. The robot radio has none.
+func (web *WebServer) loadPersistedState() {}
+
+// followProvisionedManagementAddress does nothing on the robot radio, which doesn't support changing its management
+// address at provisioning.
+func (web *WebServer) followProvisionedManagementAddress(ipAddress string) {}
+
+// serveTeamStatus does nothing on the robot radio, which has no team networks to report on.
+func (web *WebServer) serveTeamStatus() {}
+
+// rootHandler redirects the root URL to the configuration page, or to the provisioning page in kiosk mode.
 func (web *WebServer) rootHandler(w http.ResponseWriter, r *http.Request) {
-	http.Redirect(w, r, "/configuration", http.StatusFound)
+	if web.radio.GetSettings().KioskMode {
+		http.Redirect(w, r, web.externalPath("/kiosk"), http.StatusFound)
+		return
+	}
+	http.Redirect(w, r, web.externalPath("/configuration"), http.StatusFound)
 }
@@ -15,7 +15,7 @@ func TestGetListenAddress(t *testing.T) {
 }
 
 func TestWeb_rootHandler(t *testing.T) {
-	var web WebServer
+	web := WebServer{radio: &radio.Radio{}}
 	recorder := web.getHttpResponse("/")
 	assert.Equal(t, 302, recorder.Code)
 	assert.Equal(t, "/configuration", recorder.Header().Get("Location"))